@@ -12,6 +12,8 @@ class Config:
     )
     REDIS_URL: str = os.getenv("REDIS_URL", "redis://localhost:6379/0")
     INTERNAL_API_PORT: int = int(os.getenv("BOT_INTERNAL_PORT", "8081"))
+    # Verifies X-Internal-Signature on /internal/* calls; unset disables the check (dev only)
+    INTERNAL_API_SECRET: str = os.getenv("INTERNAL_API_SECRET", "")
     WEBHOOK_URL: str = os.getenv("WEBHOOK_URL", "")
     USE_WEBHOOK: bool = os.getenv("USE_WEBHOOK", "false").lower() == "true"
     USERBOT_INTERNAL_URL: str = os.getenv("USERBOT_INTERNAL_URL", "http://localhost:8082")
@@ -0,0 +1,59 @@
+import hashlib
+import hmac
+import logging
+import time
+
+from fastapi import HTTPException, Request
+
+from bot.config import config
+
+logger = logging.getLogger(__name__)
+
+SIGNATURE_HEADER = "X-Internal-Signature"
+# Same window as auth.InternalSignatureMaxAge in the backend
+MAX_AGE_SECONDS = 5 * 60
+
+if not config.INTERNAL_API_SECRET:
+    logger.error("INTERNAL_API_SECRET is not set, all internal API calls will be rejected")
+
+
+def sign(secret: str, ts: int, method: str, request_uri: str, body: bytes) -> str:
+    """X-Internal-Signature value, see auth.SignInternalRequest in the backend.
+
+    sign("secret", 1700000000, "GET", "/internal/channels/durov/admins", b"") ends with
+    v1=d906b09c4a19df11df6f2a6f343f8244a1e008da15aedb59bb9abc93a4a1cd2d
+    """
+    return f"t={ts},v1={_mac(secret, ts, method, request_uri, body)}"
+
+
+def _mac(secret: str, ts: int, method: str, request_uri: str, body: bytes) -> str:
+    payload = f"{ts}.{method.upper()}.{request_uri}.".encode() + body
+    return hmac.new(secret.encode(), payload, hashlib.sha256).hexdigest()
+
+
+async def verify_internal_signature(request: Request) -> None:
+    """FastAPI dependency: 401 unless the request carries a fresh, valid signature.
+
+    Fails closed: without INTERNAL_API_SECRET nothing can be verified, so every call is refused.
+    """
+    if not config.INTERNAL_API_SECRET:
+        raise HTTPException(status_code=503, detail="internal API secret is not configured")
+
+    header = request.headers.get(SIGNATURE_HEADER, "")
+    parts = dict(p.strip().split("=", 1) for p in header.split(",") if "=" in p)
+    try:
+        ts = int(parts.get("t", ""))
+    except ValueError:
+        raise HTTPException(status_code=401, detail="invalid internal signature")
+
+    # Sign the raw request target, exactly as the client sent it
+    request_uri = request.scope.get("raw_path", b"").decode("latin-1")
+    if request.scope.get("query_string"):
+        request_uri += "?" + request.scope["query_string"].decode("latin-1")
+    body = await request.body()
+
+    expected = _mac(config.INTERNAL_API_SECRET, ts, request.method, request_uri, body)
+    if not hmac.compare_digest(parts.get("v1", ""), expected):
+        raise HTTPException(status_code=401, detail="invalid internal signature")
+    if abs(time.time() - ts) > MAX_AGE_SECONDS:
+        raise HTTPException(status_code=401, detail="internal signature expired")
@@ -40,6 +40,20 @@ async def _delayed_post(bot: Bot, deal_id: str, delay: float):
 
 
 async def _send_post(bot: Bot, deal_id: str, chat_id: int, text: str, channel_username: str = ""):
+    # The backend retries when it doesn't get a response: a deal is posted at most once
+    existing = await db.pool.fetchrow(
+        "SELECT telegram_message_id, telegram_chat_id, post_url FROM deal_posts "
+        "WHERE deal_id = $1::uuid AND telegram_message_id IS NOT NULL",
+        deal_id,
+    )
+    if existing:
+        logger.info(f"Post for deal {deal_id} already sent: msg_id={existing['telegram_message_id']}")
+        return {
+            "message_id": existing["telegram_message_id"],
+            "chat_id": existing["telegram_chat_id"],
+            "post_url": existing["post_url"] or "",
+        }
+
     try:
         msg = await bot.send_message(chat_id=chat_id, text=text)
 
@@ -66,16 +80,7 @@ async def _send_post(bot: Bot, deal_id: str, chat_id: int, text: str, channel_us
             deal_id, msg.message_id, chat_id, post_url, content_hash,
         )
 
-        # Update deal status to posted
-        await db.pool.execute(
-            "UPDATE deals SET status = 'posted', updated_at = now() WHERE id = $1::uuid AND status IN ('scheduled', 'creative_approved')",
-            deal_id,
-        )
-        # Then to hold_verification
-        await db.pool.execute(
-            "UPDATE deals SET status = 'hold_verification', updated_at = now() WHERE id = $1::uuid AND status = 'posted'",
-            deal_id,
-        )
+        # Deal status transitions are the backend's: it moves the deal on from this response
 
         logger.info(f"Post sent for deal {deal_id}: msg_id={msg.message_id}")
         return {"message_id": msg.message_id, "chat_id": chat_id, "post_url": post_url}
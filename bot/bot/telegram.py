@@ -4,13 +4,14 @@ from datetime import datetime
 from typing import Optional
 
 from aiogram import Bot, Dispatcher
-from fastapi import FastAPI, HTTPException
+from fastapi import Depends, FastAPI, HTTPException
 from pydantic import BaseModel
 
 from bot.config import config
 from bot.db import db
 from bot.handlers import router
 from bot.permissions import get_channel_admins, check_admin
+from bot.security import verify_internal_signature
 from bot.tasks import schedule_post, send_notification
 
 logger = logging.getLogger(__name__)
@@ -69,7 +70,7 @@ async def health():
     return {"status": "ok"}
 
 
-@app.get("/internal/channels/{username}/admins")
+@app.get("/internal/channels/{username}/admins", dependencies=[Depends(verify_internal_signature)])
 async def api_get_admins(username: str):
     """Get channel admins via Bot API."""
     admins = await get_channel_admins(bot, username)
@@ -78,14 +79,14 @@ async def api_get_admins(username: str):
     return admins
 
 
-@app.get("/internal/channels/{username}/check_admin")
+@app.get("/internal/channels/{username}/check_admin", dependencies=[Depends(verify_internal_signature)])
 async def api_check_admin(username: str, telegram_user_id: int):
     """Check if a user is an admin with posting rights."""
     result = await check_admin(bot, username, telegram_user_id)
     return result
 
 
-@app.post("/internal/deals/{deal_id}/post")
+@app.post("/internal/deals/{deal_id}/post", dependencies=[Depends(verify_internal_signature)])
 async def api_post_to_deal(deal_id: str, req: PostRequest):
     """Post content to a channel for a deal."""
     try:
@@ -102,7 +103,7 @@ async def api_post_to_deal(deal_id: str, req: PostRequest):
         raise HTTPException(status_code=500, detail=str(e))
 
 
-@app.post("/internal/notify")
+@app.post("/internal/notify", dependencies=[Depends(verify_internal_signature)])
 async def api_notify(req: NotifyRequest):
     """Send notification to a user."""
     await send_notification(bot, req.telegram_user_id, req.text)
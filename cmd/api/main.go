@@ -2,19 +2,28 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/ads-marketplace/backend/internal/auth"
+	"github.com/ads-marketplace/backend/internal/clock"
 	"github.com/ads-marketplace/backend/internal/config"
 	"github.com/ads-marketplace/backend/internal/db"
 	"github.com/ads-marketplace/backend/internal/events"
 	apphttp "github.com/ads-marketplace/backend/internal/http"
 	"github.com/ads-marketplace/backend/internal/http/handlers"
+	"github.com/ads-marketplace/backend/internal/moderation"
 	"github.com/ads-marketplace/backend/internal/repositories"
 	"github.com/ads-marketplace/backend/internal/services"
+	"github.com/ads-marketplace/backend/internal/statsparser"
+	"github.com/ads-marketplace/backend/internal/ton"
 	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
@@ -29,7 +38,7 @@ func main() {
 	defer cancel()
 
 	// Database
-	pool, err := db.NewPostgresPool(ctx, cfg.PostgresDSN, log)
+	pool, err := db.NewPostgresPool(ctx, cfg.PostgresDSN, cfg.DBStatementTimeout, log)
 	if err != nil {
 		log.Fatal("failed to connect to postgres", zap.Error(err))
 	}
@@ -55,27 +64,50 @@ func main() {
 	auditRepo := repositories.NewAuditRepo(pool)
 	withdrawRepo := repositories.NewWithdrawRepo(pool)
 	walletRepo := repositories.NewWalletRepo(pool)
+	balanceRepo := repositories.NewBalanceRepo(pool)
+	ratingRepo := repositories.NewRatingRepo(pool)
+	blockRepo := repositories.NewBlockRepo(pool)
 	campaignRepo := repositories.NewCampaignRepo(pool)
+	offerRepo := repositories.NewOfferRepo(pool)
+	adminActionRepo := repositories.NewAdminActionRepo(pool)
+	webhookRepo := repositories.NewWebhookRepo(pool)
+	refreshTokenRepo := repositories.NewRefreshTokenRepo(pool)
 
 	// Events
 	publisher := events.NewRedisPublisher(rdb, log)
 	subscriber := events.NewRedisSubscriber(rdb, log)
+	userEventLog := events.NewRedisUserEventLog(rdb, int64(cfg.WSOutboxSize), cfg.WSOutboxTTL)
+	tokenDenylist := auth.NewRedisTokenDenylist(rdb)
 
 	// Services
-	botClient := services.NewBotClient(cfg.BotInternalURL, log)
-	dealService := services.NewDealService(dealRepo, channelRepo, escrowRepo, auditRepo, withdrawRepo, walletRepo, botClient, publisher, cfg, log)
-	channelService := services.NewChannelService(channelRepo, userRepo, auditRepo, botClient, cfg, log)
-	walletService := services.NewWalletService(walletRepo, auditRepo, cfg, log)
-	campaignService := services.NewCampaignService(campaignRepo, auditRepo, log)
+	clk := clock.Real{}
+	botClient := services.NewBotClient(cfg.BotInternalURL, cfg.InternalAPISecret, log)
+	moderator := moderation.NewModerator(cfg.ModerationBlockedKeywords, cfg.ModerationBlockedDomains, cfg.ModerationOnMatch, cfg.ModerationWebhookURL, log)
+	parser := statsparser.NewParser(cfg.TMEFetchTimeoutMS, cfg.TMEFetchMaxRetries, log)
+	dealService := services.NewDealService(dealRepo, channelRepo, campaignRepo, escrowRepo, auditRepo, withdrawRepo, walletRepo, ratingRepo, blockRepo, botClient, parser, nil, moderator, publisher, clk, cfg, log)
+	channelService := services.NewChannelService(channelRepo, userRepo, auditRepo, ratingRepo, botClient, publisher, rdb, cfg, log)
+	walletService := services.NewWalletService(walletRepo, auditRepo, rdb, newAccountKeyReader(ctx, cfg, log), clk, cfg, log)
+	userService := services.NewUserService(userRepo, dealRepo, walletRepo, auditRepo, blockRepo, log)
+	campaignService := services.NewCampaignService(campaignRepo, channelRepo, dealRepo, auditRepo, log)
+	offerService := services.NewOfferService(offerRepo, channelRepo, auditRepo, dealService, clk, cfg, log)
+	earningsService := services.NewEarningsService(balanceRepo, dealRepo, walletRepo, auditRepo, nil, cfg, log)
+	authService := services.NewAuthService(userRepo, refreshTokenRepo, tokenDenylist, cfg, log)
+	webhookService := services.NewWebhookService(webhookRepo, dealRepo, escrowRepo, cfg, log)
+	adminService := services.NewAdminService(dealService, channelRepo, adminActionRepo, auditRepo, rdb, cfg, log)
 
 	// Handlers
-	authHandler := handlers.NewAuthHandler(userRepo, cfg, log)
-	userHandler := handlers.NewUserHandler(userRepo, log)
+	authHandler := handlers.NewAuthHandler(userRepo, authService, cfg, log)
+	userHandler := handlers.NewUserHandler(userRepo, userService, log)
 	channelHandler := handlers.NewChannelHandler(channelService, log)
-	dealHandler := handlers.NewDealHandler(dealService, log)
+	dealHandler := handlers.NewDealHandler(dealService, campaignService, log)
 	walletHandler := handlers.NewWalletHandler(walletService, log)
 	campaignHandler := handlers.NewCampaignHandler(campaignService, log)
-	wsHub := handlers.NewWSHub(cfg, subscriber, log)
+	offerHandler := handlers.NewOfferHandler(offerService, log)
+	earningsHandler := handlers.NewEarningsHandler(earningsService, log)
+	webhookHandler := handlers.NewWebhookHandler(webhookService, log)
+	adminHandler := handlers.NewAdminHandler(adminService, log)
+	healthHandler := handlers.NewHealthHandler(readinessChecks(pool, rdb, botClient, cfg), cfg.HealthCheckTimeout, log)
+	wsHub := handlers.NewWSHub(cfg, subscriber, userEventLog, tokenDenylist, dealService.DealParticipants, log)
 
 	// Start WS hub
 	wsHub.Start(ctx)
@@ -91,7 +123,7 @@ func main() {
 		},
 	})
 
-	apphttp.SetupRouter(app, cfg, log, rdb, authHandler, userHandler, channelHandler, dealHandler, walletHandler, campaignHandler, wsHub)
+	apphttp.SetupRouter(app, cfg, log, rdb, authHandler, userHandler, channelHandler, dealHandler, walletHandler, campaignHandler, offerHandler, earningsHandler, webhookHandler, adminHandler, healthHandler, wsHub)
 
 	// Graceful shutdown
 	go func() {
@@ -109,3 +141,34 @@ func main() {
 		log.Fatal("server error", zap.Error(err))
 	}
 }
+
+// readinessChecks are the dependencies behind GET /health/ready. Postgres and Redis are
+// required; the bot and the TON indexer (payments) only degrade the instance.
+func readinessChecks(pool *pgxpool.Pool, rdb *redis.Client, botClient *services.BotClient, cfg *config.Config) []handlers.HealthCheck {
+	return []handlers.HealthCheck{
+		{Name: "postgres", Required: true, Check: pool.Ping},
+		{Name: "redis", Required: true, Check: func(ctx context.Context) error { return rdb.Ping(ctx).Err() }},
+		{Name: "bot", Check: botClient.Health},
+		{Name: "ton_indexer", Check: func(ctx context.Context) error {
+			hb, err := ton.ReadHeartbeat(ctx, rdb)
+			if err != nil {
+				return err
+			}
+			if !ton.CheckHeartbeat(hb, time.Now(), cfg.IndexerStaleAfter).Alive {
+				return errors.New("heartbeat is stale")
+			}
+			return nil
+		}},
+	}
+}
+
+// newAccountKeyReader connects to TON so wallet connects of deployed wallets are checked
+// against the chain. Without a connection ownership is proven by state_init alone.
+func newAccountKeyReader(ctx context.Context, cfg *config.Config, log *zap.Logger) ton.AccountKeyReader {
+	api, err := ton.Connect(ctx, cfg, log)
+	if err != nil {
+		log.Warn("failed to connect to TON network, wallet ownership is verified by state_init only", zap.Error(err))
+		return nil
+	}
+	return ton.NewAccountKeyReader(api)
+}
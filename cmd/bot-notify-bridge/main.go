@@ -57,21 +57,20 @@ func main() {
 }
 
 func forwardToBot(baseURL string, event events.Event, log *zap.Logger) {
-	// Extract notification data from event payload
-	telegramUserID, ok := event.Payload["telegram_user_id"]
-	if !ok {
+	var n events.Notification
+	if err := event.Decode(&n); err != nil {
+		log.Warn("malformed event payload", zap.String("type", event.Type), zap.Error(err))
 		return
 	}
-
-	text, _ := event.Payload["text"].(string)
-	if text == "" {
-		text = fmt.Sprintf("Event: %s", event.Type)
+	// No recipient: the event is for WS clients only.
+	if n.TelegramUserID == 0 {
+		return
+	}
+	if n.Text == "" {
+		n.Text = fmt.Sprintf("Event: %s", event.Type)
 	}
 
-	body, _ := json.Marshal(map[string]any{
-		"telegram_user_id": telegramUserID,
-		"text":             text,
-	})
+	body, _ := json.Marshal(n)
 
 	url := fmt.Sprintf("%s/internal/notify", strings.TrimRight(baseURL, "/"))
 	resp, err := http.Post(url, "application/json", strings.NewReader(string(body)))
@@ -3,9 +3,11 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -27,7 +29,7 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	pool, err := db.NewPostgresPool(ctx, cfg.PostgresDSN, log)
+	pool, err := db.NewPostgresPool(ctx, cfg.PostgresDSN, cfg.DBStatementTimeout, log)
 	if err != nil {
 		log.Fatal("failed to connect to postgres", zap.Error(err))
 	}
@@ -51,7 +53,21 @@ func main() {
 		log.Warn("userbot service is not available — falling back to t.me parser for all channels")
 	}
 
-	log.Info("stats fetcher started", zap.Duration("interval", cfg.StatsRefreshInterval))
+	log.Info("stats fetcher started",
+		zap.Duration("interval", cfg.StatsRefreshInterval),
+		zap.Int("concurrency", cfg.StatsConcurrency),
+	)
+
+	// Cancel from a separate goroutine so a signal also interrupts a refresh cycle in flight
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		log.Info("shutting down stats fetcher")
+		cancel()
+	}()
+
+	go runRefreshQueue(ctx, channelRepo, parser, userbotClient, rdb, cfg, log)
 
 	// Initial run
 	runStatsRefresh(ctx, channelRepo, parser, userbotClient, rdb, cfg, log)
@@ -59,17 +75,10 @@ func main() {
 	ticker := time.NewTicker(cfg.StatsRefreshInterval)
 	defer ticker.Stop()
 
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-
 	for {
 		select {
 		case <-ticker.C:
 			runStatsRefresh(ctx, channelRepo, parser, userbotClient, rdb, cfg, log)
-		case <-sigCh:
-			log.Info("shutting down stats fetcher")
-			cancel()
-			return
 		case <-ctx.Done():
 			return
 		}
@@ -96,68 +105,264 @@ func runStatsRefresh(
 	// Check userbot availability once per refresh cycle
 	userbotAvailable := userbotClient.IsAvailable(ctx)
 
+	workers := max(cfg.StatsConcurrency, 1)
+	jobs := make(chan models.Channel)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ch := range jobs {
+				if fetched, _ := refreshChannel(ctx, channelRepo, parser, userbotClient, rdb, ch, userbotAvailable, false, cfg, log); !fetched {
+					continue
+				}
+				// Small delay between requests to avoid rate limiting
+				select {
+				case <-time.After(2 * time.Second):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+dispatch:
 	for _, ch := range channels {
-		// Rate limit check
-		rlKey := fmt.Sprintf("rl:stats:%s", ch.Username)
-		if rdb.Exists(ctx, rlKey).Val() > 0 {
-			continue
+		select {
+		case jobs <- ch:
+		case <-ctx.Done():
+			break dispatch
 		}
-		rdb.Set(ctx, rlKey, "1", cfg.StatsRefreshInterval)
+	}
+	close(jobs)
+	wg.Wait()
+}
 
-		// Check cache
-		cacheKey := fmt.Sprintf("stats:%s", ch.Username)
-		if rdb.Exists(ctx, cacheKey).Val() > 0 {
+// runRefreshQueue serves forced refreshes (POST /channels/:id/stats/refresh) one at a time,
+// alongside the scheduled cycles, until ctx is cancelled.
+func runRefreshQueue(
+	ctx context.Context,
+	channelRepo *repositories.ChannelRepo,
+	parser *statsparser.Parser,
+	userbotClient *services.UserbotClient,
+	rdb *redis.Client,
+	cfg *config.Config,
+	log *zap.Logger,
+) {
+	for ctx.Err() == nil {
+		req, err := statsparser.NextRefresh(ctx, rdb, 5*time.Second)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error("failed to read stats refresh queue", zap.Error(err))
+				select {
+				case <-time.After(time.Second):
+				case <-ctx.Done():
+				}
+			}
 			continue
 		}
-
-		var snapshot *models.ChannelStatsSnapshot
-
-		// Try userbot first if available and channel has active userbot
-		if userbotAvailable && ch.UserbotStatus == "active" {
-			snapshot = tryUserbotStats(ctx, userbotClient, ch, log)
+		if req == nil {
+			continue
 		}
 
-		// Fallback to t.me parser
-		if snapshot == nil {
-			snapshot = tryParserStats(ctx, parser, ch, log)
+		ch, err := channelRepo.GetByID(ctx, req.ChannelID)
+		if err == nil {
+			_, err = refreshChannel(ctx, channelRepo, parser, userbotClient, rdb, *ch, userbotClient.IsAvailable(ctx), true, cfg, log)
+		}
+		if err := statsparser.CompleteRefresh(ctx, rdb, req.ID, err); err != nil {
+			log.Error("failed to report stats refresh", zap.String("channel_id", req.ChannelID.String()), zap.Error(err))
 		}
+	}
+}
 
-		if snapshot == nil {
-			continue
+// refreshChannel fetches and stores a fresh snapshot for one channel. It is called from
+// several workers at once, so everything it shares goes through Redis or the pgx pool.
+// force (an owner's refresh request) skips the rate limit and cache checks. Reports whether
+// an upstream fetch was made, so the caller knows to pace itself, and why it failed.
+func refreshChannel(
+	ctx context.Context,
+	channelRepo *repositories.ChannelRepo,
+	parser *statsparser.Parser,
+	userbotClient *services.UserbotClient,
+	rdb *redis.Client,
+	ch models.Channel,
+	userbotAvailable bool,
+	force bool,
+	cfg *config.Config,
+	log *zap.Logger,
+) (bool, error) {
+	// Rate limit check; SETNX so two workers can't both claim the same channel.
+	// A forced refresh takes the slot anyway so the schedule doesn't fetch again right after
+	rlKey := fmt.Sprintf("rl:stats:%s", ch.Username)
+	if force {
+		rdb.Set(ctx, rlKey, "1", cfg.StatsRefreshInterval)
+	} else if !rdb.SetNX(ctx, rlKey, "1", cfg.StatsRefreshInterval).Val() {
+		return false, nil
+	}
+
+	// Try userbot first if available and channel has active userbot;
+	// channels demoted to failed get an occasional re-probe
+	useUserbot := userbotAvailable && (ch.UserbotStatus == "active" ||
+		(ch.UserbotStatus == "failed" && userbotReprobeDue(ctx, rdb, ch, cfg)))
+
+	// Check cache — per source, so a parser snapshot doesn't hold back a channel that
+	// just got the userbot
+	source := "tme_parser"
+	if useUserbot {
+		source = "userbot"
+	}
+	if !force && rdb.Exists(ctx, statsCacheKey(source, ch.Username)).Val() > 0 {
+		return false, nil
+	}
+
+	var snapshot *models.ChannelStatsSnapshot
+	var fetchErr error
+
+	if useUserbot {
+		snapshot, fetchErr = tryUserbotStats(ctx, userbotClient, ch, log)
+		trackUserbotResult(ctx, channelRepo, rdb, ch, snapshot != nil, cfg, log)
+	}
+
+	// Fallback to t.me parser
+	if snapshot == nil {
+		snapshot, fetchErr = tryParserStats(ctx, parser, ch, log)
+	}
+
+	// Shutting down mid-fetch isn't the channel's fault
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	// t.me is throttling us, not failing on this channel: don't count it against the
+	// channel and free the rate-limit slot so the next cycle picks it up again
+	if snapshot == nil && errors.Is(fetchErr, statsparser.ErrRateLimited) {
+		rdb.Del(ctx, rlKey)
+		log.Warn("t.me rate limited, skipping channel", zap.String("channel", ch.Username))
+		return true, fetchErr
+	}
+
+	if snapshot == nil {
+		recordStatsFailure(ctx, channelRepo, ch, fetchErr, cfg, log)
+		if fetchErr == nil {
+			fetchErr = errNoStatsSource
 		}
+		return true, fetchErr
+	}
 
-		// Compute growth from previous snapshots
-		computeGrowth(ctx, channelRepo, snapshot, log)
+	// Compute growth from previous snapshots
+	computeGrowth(ctx, channelRepo, snapshot, log)
 
-		if err := channelRepo.InsertStatsSnapshot(ctx, snapshot); err != nil {
-			log.Error("failed to save stats snapshot", zap.String("channel", ch.Username), zap.Error(err))
-			continue
+	if err := channelRepo.InsertStatsSnapshot(ctx, snapshot); err != nil {
+		log.Error("failed to save stats snapshot", zap.String("channel", ch.Username), zap.Error(err))
+		return true, err
+	}
+	_ = channelRepo.ResetStatsFailures(ctx, ch.ID)
+
+	// Cache
+	cacheData, _ := json.Marshal(snapshot)
+	rdb.Set(ctx, statsCacheKey(snapshot.Source, ch.Username), string(cacheData), cfg.StatsRefreshInterval)
+
+	log.Info("stats updated",
+		zap.String("channel", ch.Username),
+		zap.String("source", snapshot.Source),
+		zap.Intp("subscribers", snapshot.Subscribers),
+		zap.Intp("avg_views", snapshot.AvgViews20),
+	)
+	return true, nil
+}
+
+var errNoStatsSource = errors.New("no stats source available")
+
+func statsCacheKey(source, username string) string {
+	return fmt.Sprintf("stats:%s:%s", source, username)
+}
+
+// recordStatsFailure bumps the channel's failure counter and pushes its next refresh
+// out with exponential backoff, so persistently failing channels stop eating the cycle.
+func recordStatsFailure(ctx context.Context, channelRepo *repositories.ChannelRepo, ch models.Channel, fetchErr error, cfg *config.Config, log *zap.Logger) {
+	errMsg := errNoStatsSource.Error()
+	if fetchErr != nil {
+		errMsg = fetchErr.Error()
+	}
+
+	failures, err := channelRepo.RecordStatsFailure(ctx, ch.ID, errMsg)
+	if err != nil {
+		log.Error("failed to record stats failure", zap.String("channel", ch.Username), zap.Error(err))
+		return
+	}
+
+	backoff := models.StatsRetryBackoff(cfg.StatsRefreshInterval, failures)
+	if err := channelRepo.SetStatsNextAttempt(ctx, ch.ID, time.Now().Add(backoff)); err != nil {
+		log.Error("failed to set stats next attempt", zap.String("channel", ch.Username), zap.Error(err))
+	}
+
+	log.Warn("stats refresh failed",
+		zap.String("channel", ch.Username),
+		zap.Int("consecutive_failures", failures),
+		zap.Duration("next_attempt_in", backoff),
+	)
+}
+
+// trackUserbotResult counts consecutive userbot failures per channel and keeps userbot_status
+// honest: after USERBOT_MAX_FAILURES the channel is flipped to failed (the userbot has likely
+// left it), so later cycles skip the userbot timeout and go straight to the parser.
+func trackUserbotResult(ctx context.Context, channelRepo *repositories.ChannelRepo, rdb *redis.Client, ch models.Channel, ok bool, cfg *config.Config, log *zap.Logger) {
+	failKey := fmt.Sprintf("stats:userbot_failures:%s", ch.ID)
+
+	failures := 0
+	if ok {
+		rdb.Del(ctx, failKey)
+	} else {
+		n, err := rdb.Incr(ctx, failKey).Result()
+		if err != nil {
+			return
 		}
+		rdb.Expire(ctx, failKey, 7*24*time.Hour)
+		failures = int(n)
+	}
 
-		// Cache
-		cacheData, _ := json.Marshal(snapshot)
-		rdb.Set(ctx, cacheKey, string(cacheData), cfg.StatsRefreshInterval)
+	next := models.NextUserbotStatus(ch.UserbotStatus, ok, failures, cfg.UserbotMaxFailures)
+	if next == ch.UserbotStatus {
+		return
+	}
+	if err := channelRepo.UpdateUserbotStatus(ctx, ch.ID, next); err != nil {
+		log.Error("failed to update userbot status", zap.String("channel", ch.Username), zap.Error(err))
+		return
+	}
+	if next == "failed" {
+		rdb.Del(ctx, failKey)
+		// Next re-probe only after the full interval
+		rdb.Set(ctx, userbotReprobeKey(ch), "1", cfg.UserbotReprobeInterval)
+	}
 
-		log.Info("stats updated",
-			zap.String("channel", ch.Username),
-			zap.String("source", snapshot.Source),
-			zap.Intp("subscribers", snapshot.Subscribers),
-			zap.Intp("avg_views", snapshot.AvgViews20),
-		)
+	log.Info("userbot status changed",
+		zap.String("channel", ch.Username),
+		zap.String("from", ch.UserbotStatus),
+		zap.String("to", next),
+		zap.Int("consecutive_failures", failures),
+	)
+}
 
-		// Small delay between requests to avoid rate limiting
-		time.Sleep(2 * time.Second)
+// userbotReprobeDue lets a failed channel through to the userbot at most once per USERBOT_REPROBE_HOURS.
+func userbotReprobeDue(ctx context.Context, rdb *redis.Client, ch models.Channel, cfg *config.Config) bool {
+	if cfg.UserbotReprobeInterval <= 0 {
+		return false
 	}
+	return rdb.SetNX(ctx, userbotReprobeKey(ch), "1", cfg.UserbotReprobeInterval).Val()
 }
 
-func tryUserbotStats(ctx context.Context, client *services.UserbotClient, ch models.Channel, log *zap.Logger) *models.ChannelStatsSnapshot {
+func userbotReprobeKey(ch models.Channel) string {
+	return fmt.Sprintf("stats:userbot_reprobe:%s", ch.ID)
+}
+
+func tryUserbotStats(ctx context.Context, client *services.UserbotClient, ch models.Channel, log *zap.Logger) (*models.ChannelStatsSnapshot, error) {
 	stats, err := client.GetStatsByUsername(ctx, ch.Username)
 	if err != nil {
 		log.Warn("userbot stats failed, will fallback to parser",
 			zap.String("channel", ch.Username),
 			zap.Error(err),
 		)
-		return nil
+		return nil, err
 	}
 
 	rawJSON, _ := json.Marshal(stats)
@@ -171,23 +376,25 @@ func tryUserbotStats(ctx context.Context, client *services.UserbotClient, ch mod
 		MembersOnline: stats.MembersOnline,
 		AdminsCount:   stats.AdminsCount,
 		PostsCount:    stats.PostsCount,
+		Growth7d:      stats.Growth7d,
+		Growth30d:     stats.Growth30d,
 		// GetBroadcastStats fields
 		ViewsPerPost:                stats.ViewsPerPost,
 		SharesPerPost:               stats.SharesPerPost,
 		EnabledNotificationsPercent: stats.EnabledNotificationsPercent,
 		ERPercent:                   stats.ERPercent,
 	}
-	return snapshot
+	return snapshot, nil
 }
 
-func tryParserStats(ctx context.Context, parser *statsparser.Parser, ch models.Channel, log *zap.Logger) *models.ChannelStatsSnapshot {
+func tryParserStats(ctx context.Context, parser *statsparser.Parser, ch models.Channel, log *zap.Logger) (*models.ChannelStatsSnapshot, error) {
 	stats, err := parser.FetchAndParse(ctx, ch.Username)
 	if err != nil {
 		log.Warn("t.me parser stats failed",
 			zap.String("channel", ch.Username),
 			zap.Error(err),
 		)
-		return nil
+		return nil, err
 	}
 
 	rawJSON, _ := json.Marshal(stats)
@@ -201,26 +408,43 @@ func tryParserStats(ctx context.Context, parser *statsparser.Parser, ch models.C
 		ChannelID:     ch.ID,
 		Subscribers:   stats.Subscribers,
 		VerifiedBadge: stats.VerifiedBadge,
+		ScamBadge:     stats.ScamBadge,
+		FakeBadge:     stats.FakeBadge,
 		AvgViews20:    stats.AvgViewsLast20,
 		LastPostID:    lastPostID,
 		RawJSON:       json.RawMessage(rawJSON),
 		Source:        "tme_parser",
+		PostFrequency: stats.PostsPerDay,
+		HasPinnedPost: &stats.HasPinnedPost,
 	}
-	return snapshot
+	return snapshot, nil
 }
 
+// computeGrowth fills Growth7d/Growth30d (unless the source already reported them) from the
+// stored snapshots nearest to 7 and 30 days ago; without enough history they stay nil.
 func computeGrowth(ctx context.Context, channelRepo *repositories.ChannelRepo, snapshot *models.ChannelStatsSnapshot, log *zap.Logger) {
 	if snapshot.Subscribers == nil {
 		return
 	}
 
-	// Get previous stats to compute growth
-	prev, err := channelRepo.GetLatestStats(ctx, snapshot.ChannelID)
-	if err != nil || prev == nil || prev.Subscribers == nil {
-		return
+	now := time.Now()
+	for _, g := range []struct {
+		window time.Duration
+		field  **int
+	}{
+		{models.GrowthWindow7d, &snapshot.Growth7d},
+		{models.GrowthWindow30d, &snapshot.Growth30d},
+	} {
+		if *g.field != nil {
+			continue
+		}
+		base, err := channelRepo.GetStatsSnapshotNearest(ctx, snapshot.ChannelID, now.Add(-g.window))
+		if err != nil {
+			if !errors.Is(err, repositories.ErrNotFound) {
+				log.Warn("failed to get baseline snapshot", zap.String("channel_id", snapshot.ChannelID.String()), zap.Error(err))
+			}
+			return
+		}
+		*g.field = models.SubscriberGrowth(snapshot.Subscribers, now, base, g.window)
 	}
-
-	// Simple growth: current - previous (snapshot-to-snapshot)
-	growth := *snapshot.Subscribers - *prev.Subscribers
-	snapshot.Growth7d = &growth
 }
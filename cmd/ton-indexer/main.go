@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
@@ -18,21 +19,20 @@ import (
 	"github.com/ads-marketplace/backend/internal/events"
 	"github.com/ads-marketplace/backend/internal/models"
 	"github.com/ads-marketplace/backend/internal/repositories"
+	"github.com/ads-marketplace/backend/internal/services"
+	tonpkg "github.com/ads-marketplace/backend/internal/ton"
 	"github.com/redis/go-redis/v9"
 	"github.com/xssnick/tonutils-go/address"
-	"github.com/xssnick/tonutils-go/liteclient"
 	"github.com/xssnick/tonutils-go/tlb"
 	"github.com/xssnick/tonutils-go/ton"
+	"github.com/xssnick/tonutils-go/ton/jetton"
 	"go.uber.org/zap"
 )
 
 const (
-	redisCursorLT  = "ton-indexer:cursor:lt"
-	redisCursorHash = "ton-indexer:cursor:hash"
-	redisProcessed = "ton-indexer:tx:"
-	processedTTL   = 7 * 24 * time.Hour
-	pollInterval   = 5 * time.Second
-	txBatchSize    = 100
+	processedTTL = 7 * 24 * time.Hour
+	pollInterval = 5 * time.Second
+	txBatchSize  = 100
 )
 
 func main() {
@@ -52,7 +52,7 @@ func main() {
 		log.Fatal("invalid TON_HOT_WALLET_ADDRESS", zap.String("addr", cfg.TONHotWalletAddress), zap.Error(err))
 	}
 
-	pool, err := db.NewPostgresPool(ctx, cfg.PostgresDSN, log)
+	pool, err := db.NewPostgresPool(ctx, cfg.PostgresDSN, cfg.DBStatementTimeout, log)
 	if err != nil {
 		log.Fatal("failed to connect to postgres", zap.Error(err))
 	}
@@ -68,7 +68,7 @@ func main() {
 	dealRepo := repositories.NewDealRepo(pool)
 	publisher := events.NewRedisPublisher(rdb, log)
 
-	tonAPI, err := connectToTON(ctx, cfg, log)
+	tonAPI, err := tonpkg.Connect(ctx, cfg, log)
 	if err != nil {
 		log.Fatal("failed to connect to TON network", zap.Error(err))
 	}
@@ -78,7 +78,21 @@ func main() {
 		zap.String("network", cfg.TONNetwork),
 	)
 
-	initCursor(ctx, tonAPI, hotWallet, rdb, log)
+	usdtWallet, err := resolveUSDTWallet(ctx, tonAPI, cfg.USDTJettonMaster, hotWallet, log)
+	if err != nil {
+		log.Fatal("failed to resolve USDT jetton wallet", zap.String("master", cfg.USDTJettonMaster), zap.Error(err))
+	}
+
+	walletKey := hotWallet.String()
+	cursors := tonpkg.NewCursorStore(
+		pgCursor{repo: repositories.NewIndexerCursorRepo(pool), wallet: walletKey},
+		tonpkg.NewRedisCursorCache(rdb),
+	)
+	if err := initCursor(ctx, tonAPI, hotWallet, cursors, log); err != nil {
+		log.Fatal("failed to initialize indexer cursor", zap.Error(err))
+	}
+
+	serveMetrics(cfg.IndexerMetricsPort, log)
 
 	ticker := time.NewTicker(pollInterval)
 	defer ticker.Stop()
@@ -89,8 +103,25 @@ func main() {
 	for {
 		select {
 		case <-ticker.C:
-			if err := pollAndProcess(ctx, tonAPI, hotWallet, escrowRepo, dealRepo, publisher, rdb, log); err != nil {
+			start := time.Now()
+			err := pollAndProcess(ctx, tonAPI, hotWallet, usdtWallet, cursors, escrowRepo, dealRepo, publisher, rdb, cfg.WebAppURL, cfg.IndexerReorgDepth, cfg.TONConfirmations, cfg.IndexerMatchGrace, log)
+			if err == nil {
+				err = matchPendingPayments(ctx, escrowRepo, dealRepo, publisher, cfg.WebAppURL, log)
+			}
+			metricPollDuration.Observe(time.Since(start).Seconds())
+			if err != nil {
+				metricPollErrors.Inc()
 				log.Error("poll cycle failed", zap.Error(err))
+				continue
+			}
+			// Heartbeat only after a successful cycle: a stale key means "not indexing", not just "no payments"
+			var cursorLT uint64
+			if c, err := cursors.Load(ctx); err == nil && c != nil {
+				cursorLT = c.LT
+			}
+			metricCursorLT.Set(float64(cursorLT))
+			if err := tonpkg.WriteHeartbeat(ctx, rdb, cursorLT); err != nil {
+				log.Warn("failed to write heartbeat", zap.Error(err))
 			}
 		case <-sigCh:
 			log.Info("shutting down TON indexer")
@@ -102,117 +133,122 @@ func main() {
 	}
 }
 
-// connectToTON establishes a connection to the TON network.
-// If LITE_SERVER_HOST + LITE_SERVER_KEY are set, connects to a specific lite server.
-// Otherwise, auto-discovers lite servers from the global TON config based on TON_NETWORK.
-func connectToTON(ctx context.Context, cfg *config.Config, log *zap.Logger) (ton.APIClientWrapped, error) {
-	client := liteclient.NewConnectionPool()
-
-	if cfg.LiteServerHost != "" && cfg.LiteServerKey != "" {
-		addr := fmt.Sprintf("%s:%d", cfg.LiteServerHost, cfg.LiteServerPort)
-		log.Info("connecting to lite server", zap.String("addr", addr))
-		if err := client.AddConnection(ctx, addr, cfg.LiteServerKey); err != nil {
-			return nil, fmt.Errorf("connect to lite server %s: %w", addr, err)
-		}
-	} else {
-		var configURL string
-		switch strings.ToLower(cfg.TONNetwork) {
-		case "mainnet":
-			configURL = "https://ton.org/global.config.json"
-		default:
-			configURL = "https://ton.org/testnet-global.config.json"
-		}
-		log.Info("connecting via global config", zap.String("url", configURL), zap.String("network", cfg.TONNetwork))
-		if err := client.AddConnectionsFromConfigUrl(ctx, configURL); err != nil {
-			return nil, fmt.Errorf("connect via config %s: %w", configURL, err)
-		}
+// resolveUSDTWallet returns the hot wallet's jetton wallet for the configured USDT master.
+// Jetton transfer notifications are only trusted when they come from this address:
+// anyone can deploy a jetton that sends the same notification. Returns nil if USDT is not configured.
+func resolveUSDTWallet(ctx context.Context, api ton.APIClientWrapped, master string, owner *address.Address, log *zap.Logger) (*address.Address, error) {
+	if master == "" {
+		return nil, nil
 	}
-
-	proofPolicy := ton.ProofCheckPolicyFast
-	if strings.ToLower(cfg.TONNetwork) == "mainnet" {
-		proofPolicy = ton.ProofCheckPolicySecure
+	masterAddr, err := address.ParseAddr(master)
+	if err != nil {
+		return nil, fmt.Errorf("parse USDT_JETTON_MASTER: %w", err)
 	}
-
-	api := ton.NewAPIClient(client, proofPolicy).WithRetry()
-	return api, nil
+	w, err := jetton.NewJettonMasterClient(api, masterAddr).GetJettonWallet(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("get jetton wallet: %w", err)
+	}
+	log.Info("USDT payments enabled",
+		zap.String("jetton_master", masterAddr.String()),
+		zap.String("jetton_wallet", w.Address().String()),
+	)
+	return w.Address(), nil
 }
 
-// initCursor sets the initial cursor position on first run.
+// initCursor restores the cursor on startup (Postgres first, see ton.CursorStore).
 // On first run, it stores the current account LastTxLT so that only
 // NEW transactions (arriving after startup) are processed.
-func initCursor(ctx context.Context, api ton.APIClientWrapped, addr *address.Address, rdb *redis.Client, log *zap.Logger) {
-	existing, _ := rdb.Get(ctx, redisCursorLT).Result()
-	if existing != "" {
-		log.Info("resuming from saved cursor", zap.String("lt", existing))
-		return
+func initCursor(ctx context.Context, api ton.APIClientWrapped, addr *address.Address, cursors *tonpkg.CursorStore, log *zap.Logger) error {
+	existing, err := cursors.Restore(ctx)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		log.Info("resuming from saved cursor", zap.Uint64("lt", existing.LT))
+		return nil
 	}
 
 	block, err := api.CurrentMasterchainInfo(ctx)
 	if err != nil {
 		log.Warn("failed to get master block for cursor init", zap.Error(err))
-		rdb.Set(ctx, redisCursorLT, "0", 0)
-		return
+		return cursors.Save(ctx, tonpkg.Cursor{})
 	}
 
 	account, err := api.GetAccount(ctx, block, addr)
 	if err != nil {
 		log.Warn("failed to get account for cursor init", zap.Error(err))
-		rdb.Set(ctx, redisCursorLT, "0", 0)
-		return
+		return cursors.Save(ctx, tonpkg.Cursor{})
 	}
 
 	if account == nil || !account.IsActive || account.LastTxLT == 0 {
 		log.Info("hot wallet not active yet, starting from LT=0")
-		rdb.Set(ctx, redisCursorLT, "0", 0)
-		return
+		return cursors.Save(ctx, tonpkg.Cursor{})
 	}
 
-	saveCursor(ctx, rdb, account.LastTxLT, account.LastTxHash)
+	if err := cursors.Save(ctx, tonpkg.Cursor{LT: account.LastTxLT, Hash: account.LastTxHash}); err != nil {
+		return err
+	}
 	log.Info("cursor initialized at current account state (skipping historical transactions)",
 		zap.Uint64("lt", account.LastTxLT),
 		zap.String("hash", hex.EncodeToString(account.LastTxHash)),
 	)
+	return nil
 }
 
-func loadCursorLT(ctx context.Context, rdb *redis.Client) uint64 {
-	val, err := rdb.Get(ctx, redisCursorLT).Result()
-	if err != nil || val == "" {
-		return 0
-	}
-	lt, _ := strconv.ParseUint(val, 10, 64)
-	return lt
+// pgCursor adapts IndexerCursorRepo to ton.CursorBackend for one hot wallet.
+type pgCursor struct {
+	repo   *repositories.IndexerCursorRepo
+	wallet string
 }
 
-func loadCursorHash(ctx context.Context, rdb *redis.Client) []byte {
-	val, err := rdb.Get(ctx, redisCursorHash).Result()
-	if err != nil || val == "" {
-		return nil
+func (p pgCursor) LoadCursor(ctx context.Context) (*tonpkg.Cursor, error) {
+	lt, hash, err := p.repo.Get(ctx, p.wallet)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
-	hash, _ := hex.DecodeString(val)
-	return hash
+	return &tonpkg.Cursor{LT: lt, Hash: hash}, nil
 }
 
-func saveCursor(ctx context.Context, rdb *redis.Client, lt uint64, hash []byte) {
-	rdb.Set(ctx, redisCursorLT, strconv.FormatUint(lt, 10), 0)
-	rdb.Set(ctx, redisCursorHash, hex.EncodeToString(hash), 0)
+func (p pgCursor) SaveCursor(ctx context.Context, c tonpkg.Cursor) error {
+	return p.repo.Save(ctx, p.wallet, c.LT, c.Hash)
 }
 
 // pollAndProcess runs a single poll cycle:
 // 1. Get the account's latest state
 // 2. Fetch all transactions newer than the cursor
-// 3. Process incoming TON transfers
-// 4. Update the cursor
+// 3. If the cursor transaction is no longer on chain (reorg), rescan reorgDepth transactions behind it
+// 4. Defer transactions not yet `confirmations` masterchain blocks deep
+// 5. Process incoming TON and USDT jetton transfers
+// 6. Update the cursor — up to the last processed transaction if one failed or the rest are unconfirmed
+//
+// Payments parked as pending are retried afterwards by matchPendingPayments.
 func pollAndProcess(
 	ctx context.Context,
 	api ton.APIClientWrapped,
 	addr *address.Address,
+	usdtWallet *address.Address,
+	cursors *tonpkg.CursorStore,
 	escrowRepo *repositories.EscrowRepo,
 	dealRepo *repositories.DealRepo,
 	publisher events.Publisher,
 	rdb *redis.Client,
+	webAppURL string,
+	reorgDepth int,
+	confirmations int,
+	matchGrace time.Duration,
 	log *zap.Logger,
 ) error {
-	cursorLT := loadCursorLT(ctx, rdb)
+	cursor, err := cursors.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("load cursor: %w", err)
+	}
+	var cursorLT uint64
+	if cursor != nil {
+		cursorLT = cursor.LT
+	}
 
 	block, err := api.CurrentMasterchainInfo(ctx)
 	if err != nil {
@@ -228,35 +264,102 @@ func pollAndProcess(
 		return nil
 	}
 
-	if account.LastTxLT <= cursorLT {
+	var newTxs []*tlb.Transaction
+	if account.LastTxLT > cursorLT {
+		newTxs, err = fetchNewTransactions(ctx, api, addr, account, cursorLT, 0)
+		if err != nil {
+			return fmt.Errorf("fetch transactions: %w", err)
+		}
+	}
+
+	if cursor != nil && !tonpkg.CursorOnChain(*cursor, tonpkg.TxLink{LT: account.LastTxLT, Hash: account.LastTxHash}, txLinks(newTxs)) {
+		metricReorgs.Inc()
+		log.Warn("reorg detected: cursor transaction is no longer on chain, rescanning",
+			zap.Uint64("cursor_lt", cursor.LT),
+			zap.String("cursor_hash", hex.EncodeToString(cursor.Hash)),
+			zap.Uint64("last_lt", account.LastTxLT),
+			zap.Int("depth", reorgDepth),
+		)
+		newTxs, err = rescanAfterReorg(ctx, api, addr, account, cursorLT, reorgDepth, escrowRepo, rdb, log)
+		if err != nil {
+			return fmt.Errorf("rescan after reorg: %w", err)
+		}
+	} else if account.LastTxLT <= cursorLT {
 		return nil
 	}
 
-	newTxs, err := fetchNewTransactions(ctx, api, addr, account, cursorLT)
-	if err != nil {
-		return fmt.Errorf("fetch transactions: %w", err)
+	last := tonpkg.Cursor{LT: account.LastTxLT, Hash: account.LastTxHash}
+	if confirmations > 0 && len(newTxs) > 0 {
+		confirmedLT, err := confirmedLastLT(ctx, api, addr, block, confirmations)
+		if err != nil {
+			return fmt.Errorf("get confirmed account state: %w", err)
+		}
+		n := tonpkg.ConfirmedCount(txLinks(newTxs), confirmedLT)
+		metricUnconfirmedTxs.Set(float64(len(newTxs) - n))
+		if n < len(newTxs) {
+			log.Debug("deferring unconfirmed transactions",
+				zap.Int("count", len(newTxs)-n),
+				zap.Uint64("confirmed_lt", confirmedLT),
+				zap.Int("confirmations", confirmations),
+			)
+			newTxs = newTxs[:n]
+			if n == 0 {
+				return nil
+			}
+			last = tonpkg.Cursor{LT: newTxs[n-1].LT, Hash: newTxs[n-1].Hash}
+		}
 	}
 
 	if len(newTxs) > 0 {
 		log.Info("found new transactions", zap.Int("count", len(newTxs)))
-		for _, tx := range newTxs {
-			processIncomingTx(ctx, tx, escrowRepo, dealRepo, publisher, rdb, log)
+		walletKey := addr.String()
+		for i, tx := range newTxs {
+			if err := processIncomingTx(ctx, tx, usdtWallet, cursors, walletKey, escrowRepo, dealRepo, publisher, rdb, webAppURL, matchGrace, log); err != nil {
+				// Stop before the failed tx so it is retried next cycle
+				if i > 0 {
+					prev := newTxs[i-1]
+					_ = cursors.Save(ctx, tonpkg.Cursor{LT: prev.LT, Hash: prev.Hash})
+				}
+				return fmt.Errorf("process tx (lt=%d): %w", tx.LT, err)
+			}
 		}
 	}
 
-	saveCursor(ctx, rdb, account.LastTxLT, account.LastTxHash)
-	return nil
+	return cursors.Save(ctx, last)
 }
 
-// fetchNewTransactions retrieves all transactions with LT > cursorLT.
-// ListTransactions returns results oldest-first; we paginate backwards
-// until we reach the cursor, then return in chronological order.
+// confirmedLastLT returns the hot wallet's last transaction LT as of the masterchain block
+// `confirmations` behind tip: every transaction up to it is at least that deep. Shard blocks
+// carry no depth of their own, so depth is measured by when the masterchain committed them.
+func confirmedLastLT(ctx context.Context, api ton.APIClientWrapped, addr *address.Address, tip *ton.BlockIDExt, confirmations int) (uint64, error) {
+	seqno, ok := tonpkg.ConfirmedSeqno(tip.SeqNo, confirmations)
+	if !ok {
+		return 0, nil
+	}
+	block, err := api.LookupBlock(ctx, tip.Workchain, tip.Shard, seqno)
+	if err != nil {
+		return 0, fmt.Errorf("lookup master block %d: %w", seqno, err)
+	}
+	account, err := api.GetAccount(ctx, block, addr)
+	if err != nil {
+		return 0, fmt.Errorf("get account: %w", err)
+	}
+	if account == nil {
+		return 0, nil
+	}
+	return account.LastTxLT, nil
+}
+
+// fetchNewTransactions retrieves all transactions with LT > cursorLT, plus up to `behind`
+// transactions at or before it (reorg rescan). ListTransactions returns results oldest-first;
+// we paginate backwards until we reach the cursor, then return in chronological order.
 func fetchNewTransactions(
 	ctx context.Context,
 	api ton.APIClientWrapped,
 	addr *address.Address,
 	account *tlb.Account,
 	cursorLT uint64,
+	behind int,
 ) ([]*tlb.Transaction, error) {
 	var allTxs []*tlb.Transaction
 
@@ -273,15 +376,20 @@ func fetchNewTransactions(
 		}
 
 		reachedCursor := false
-		for _, tx := range txs {
+		// Newest first, so the `behind` transactions kept are the ones right at the cursor
+		for i := len(txs) - 1; i >= 0; i-- {
+			tx := txs[i]
 			if tx.LT <= cursorLT {
 				reachedCursor = true
-				continue
+				if behind == 0 {
+					continue
+				}
+				behind--
 			}
 			allTxs = append(allTxs, tx)
 		}
 
-		if reachedCursor || len(txs) < txBatchSize {
+		if (reachedCursor && behind == 0) || len(txs) < txBatchSize {
 			break
 		}
 
@@ -300,204 +408,447 @@ func fetchNewTransactions(
 	return allTxs, nil
 }
 
-// processIncomingTx handles a single incoming TON transfer:
+// rescanAfterReorg refetches the transactions newer than the cursor plus depth transactions
+// behind it on the current chain. The window's idempotency keys are dropped so re-delivered
+// transactions are checked again (replacing ones have new keys anyway, but a legacy LT-only
+// key would hide them). Re-processing is safe, escrows are only funded
+// from 'awaiting' and unmatched payments are recorded once. Escrows funded in the window by
+// a transaction that is gone now are reported for manual review.
+func rescanAfterReorg(
+	ctx context.Context,
+	api ton.APIClientWrapped,
+	addr *address.Address,
+	account *tlb.Account,
+	cursorLT uint64,
+	depth int,
+	escrowRepo *repositories.EscrowRepo,
+	rdb *redis.Client,
+	log *zap.Logger,
+) ([]*tlb.Transaction, error) {
+	txs, err := fetchNewTransactions(ctx, api, addr, account, cursorLT, depth)
+	if err != nil {
+		return nil, err
+	}
+	if len(txs) == 0 {
+		return nil, nil
+	}
+
+	onChain := make(map[uint64]bool, len(txs))
+	for _, tx := range txs {
+		onChain[tx.LT] = true
+		if err := rdb.Del(ctx, tonpkg.ProcessedTxKey(tx.LT, tx.Hash), tonpkg.LegacyProcessedTxKey(tx.LT)).Err(); err != nil {
+			return nil, fmt.Errorf("drop idempotency key (lt=%d): %w", tx.LT, err)
+		}
+	}
+
+	fundings, err := escrowRepo.ListFundingLTsSince(ctx, txs[0].LT)
+	if err != nil {
+		return nil, fmt.Errorf("list escrow fundings: %w", err)
+	}
+	for dealID, lt := range fundings {
+		if !onChain[lt] {
+			metricOrphanedFundings.Inc()
+			log.Error("escrow funded by a transaction lost in the reorg, needs manual review",
+				zap.String("deal_id", dealID.String()),
+				zap.Uint64("funding_lt", lt),
+			)
+		}
+	}
+	return txs, nil
+}
+
+// txLinks returns the chain links of txs for the reorg check.
+func txLinks(txs []*tlb.Transaction) []tonpkg.TxLink {
+	links := make([]tonpkg.TxLink, len(txs))
+	for i, tx := range txs {
+		links[i] = tonpkg.TxLink{LT: tx.LT, Hash: tx.Hash, PrevLT: tx.PrevTxLT, PrevHash: tx.PrevTxHash}
+	}
+	return links
+}
+
+// processIncomingTx handles a single incoming TON or USDT jetton transfer:
 // extracts the memo, matches it to an escrow record, verifies the amount,
 // and updates escrow + deal status.
 func processIncomingTx(
 	ctx context.Context,
 	tx *tlb.Transaction,
+	usdtWallet *address.Address,
+	cursors *tonpkg.CursorStore,
+	walletKey string,
 	escrowRepo *repositories.EscrowRepo,
 	dealRepo *repositories.DealRepo,
 	publisher events.Publisher,
 	rdb *redis.Client,
+	webAppURL string,
+	matchGrace time.Duration,
 	log *zap.Logger,
-) {
+) error {
 	if tx.IO.In == nil {
-		return
+		return nil
 	}
 
 	inMsg, ok := tx.IO.In.Msg.(*tlb.InternalMessage)
 	if !ok || inMsg == nil {
-		return
+		return nil
 	}
 
 	if inMsg.Bounced {
-		return
+		return nil
 	}
 
-	if inMsg.Amount.Nano().Sign() <= 0 {
-		return
+	payment, ok := tonpkg.DecodeIncomingPayment(inMsg, usdtWallet)
+	if !ok {
+		return nil
 	}
 
-	comment := extractComment(inMsg)
+	comment := payment.Comment
 	if comment == "" {
+		metricTxsProcessed.WithLabelValues(resultNoMemo).Inc()
 		log.Debug("transfer without memo, skipping",
 			zap.Uint64("lt", tx.LT),
-			zap.String("from", inMsg.SrcAddr.String()),
-			zap.String("amount", inMsg.Amount.String()),
+			zap.String("from", payment.From),
+			zap.String("amount", payment.Display),
+			zap.String("currency", payment.Currency),
 		)
-		return
+		return nil
 	}
 
 	// Idempotency: skip if already processed
-	txKey := fmt.Sprintf("%s%d", redisProcessed, tx.LT)
-	if rdb.Exists(ctx, txKey).Val() > 0 {
-		return
+	// Legacy LT-only keys are honoured until they expire (processedTTL)
+	txKey := tonpkg.ProcessedTxKey(tx.LT, tx.Hash)
+	if rdb.Exists(ctx, txKey, tonpkg.LegacyProcessedTxKey(tx.LT)).Val() > 0 {
+		return nil
 	}
 
 	memo := strings.TrimSpace(comment)
 
 	log.Info("incoming payment detected",
 		zap.Uint64("lt", tx.LT),
-		zap.String("from", inMsg.SrcAddr.String()),
-		zap.String("amount", inMsg.Amount.String()),
+		zap.String("from", payment.From),
+		zap.String("amount", payment.Display),
+		zap.String("currency", payment.Currency),
 		zap.String("memo", memo),
 	)
 
 	escrow, err := escrowRepo.GetByMemo(ctx, memo)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return fmt.Errorf("get escrow by memo: %w", err)
+	}
 	if err != nil {
-		log.Debug("no escrow found for memo", zap.String("memo", memo))
+		if _, ok := tonpkg.ParseDealMemo(memo); ok {
+			// Likely paid from a cached payment page before the deal was accepted: the escrow
+			// may appear within seconds, so matchPendingPayments retries it for matchGrace
+			// before staff get to see it. The DB row carries the retry, the tx itself is done.
+			if err := escrowRepo.RecordPendingPayment(ctx, unmatchedPayment(tx, payment, memo), time.Now().Add(matchGrace)); err != nil {
+				return fmt.Errorf("record pending payment: %w", err)
+			}
+			metricTxsProcessed.WithLabelValues(resultPendingMatch).Inc()
+			log.Info("no escrow yet for deal memo, retrying for the grace window",
+				zap.String("memo", memo), zap.Uint64("lt", tx.LT), zap.Duration("grace", matchGrace))
+			rdb.Set(ctx, txKey, "pending_match", processedTTL)
+			return nil
+		}
+		// Keep the payment for the worker's reconciliation and for staff instead of dropping it
+		if err := recordUnmatched(ctx, escrowRepo, tx, payment, memo); err != nil {
+			return err
+		}
+		metricTxsProcessed.WithLabelValues(resultNoEscrow).Inc()
+		log.Info("no escrow found for memo, recorded as unmatched", zap.String("memo", memo), zap.Uint64("lt", tx.LT))
 		rdb.Set(ctx, txKey, "no_escrow", processedTTL)
-		return
+		return nil
 	}
 
-	if escrow.Status != models.EscrowStatusAwaiting {
-		log.Debug("escrow not in awaiting status",
-			zap.String("memo", memo),
+	if fundedBy(escrow, tx.LT) {
+		// Seen again after its idempotency key was dropped (e.g. on a reorg check)
+		log.Debug("escrow already funded by this transaction",
+			zap.String("deal_id", escrow.DealID.String()), zap.Uint64("lt", tx.LT))
+		rdb.Set(ctx, txKey, "funded", processedTTL)
+		return nil
+	}
+
+	if result := unmatchedResult(escrow, payment.Currency); result != "" {
+		// Can't fund this escrow: keep the payment for staff to refund or credit by hand
+		if err := recordUnmatched(ctx, escrowRepo, tx, payment, memo); err != nil {
+			return err
+		}
+		metricTxsProcessed.WithLabelValues(result).Inc()
+		log.Warn("payment can't fund the escrow, recorded as unmatched",
+			zap.String("result", result),
 			zap.String("deal_id", escrow.DealID.String()),
-			zap.String("status", escrow.Status),
+			zap.String("escrow_status", escrow.Status),
+			zap.String("received_currency", payment.Currency),
+			zap.String("escrow_currency", escrow.Currency),
+			zap.String("amount", payment.Display),
+			zap.String("memo", memo),
 		)
-		rdb.Set(ctx, txKey, "skip:"+escrow.Status, processedTTL)
-		return
+		rdb.Set(ctx, txKey, "unmatched:"+result, processedTTL)
+		return nil
 	}
 
 	// Verify payment amount
-	expectedNano, err := parseTONToNano(escrow.DepositExpectedTON)
+	expectedNano, err := tonpkg.ParseUnits(escrow.DepositExpectedTON, models.CurrencyDecimals(escrow.Currency))
 	if err != nil {
 		log.Error("invalid expected amount in escrow",
 			zap.String("deal_id", escrow.DealID.String()),
 			zap.String("expected_ton", escrow.DepositExpectedTON),
 			zap.Error(err),
 		)
-		return
+		return nil
 	}
 
-	receivedNano := inMsg.Amount.Nano()
-	if receivedNano.Cmp(expectedNano) < 0 {
+	if payment.Amount.Cmp(expectedNano) < 0 {
+		metricTxsProcessed.WithLabelValues(resultInsufficient).Inc()
 		log.Warn("insufficient payment — amount below expected",
 			zap.String("deal_id", escrow.DealID.String()),
-			zap.String("received", inMsg.Amount.String()),
+			zap.String("received", payment.Display),
 			zap.String("expected", escrow.DepositExpectedTON),
 			zap.String("memo", memo),
 		)
 		// Don't mark as processed: the user may send the remainder
-		return
+		return nil
+	}
+
+	// Anything above the expected amount is recorded and refunded by the worker
+	var overpaid string
+	excess := new(big.Int).Sub(payment.Amount, expectedNano)
+	if excess.Sign() > 0 {
+		overpaid = excess.String()
 	}
 
 	// Mark escrow funded
 	txRef := strconv.FormatUint(tx.LT, 10)
-	fromAddr := inMsg.SrcAddr.String()
+	fromAddr := payment.From
 
-	if err := escrowRepo.MarkFunded(ctx, escrow.DealID, txRef, fromAddr); err != nil {
+	err = escrowRepo.MarkFundedAtCursor(ctx, escrow.DealID, txRef, fromAddr, overpaid, walletKey, tx.LT, tx.Hash)
+	if errors.Is(err, repositories.ErrNotFound) {
+		// Expired (deal cancelled) after it was read above
+		if err := recordUnmatched(ctx, escrowRepo, tx, payment, memo); err != nil {
+			return err
+		}
+		metricTxsProcessed.WithLabelValues(resultNotAwaiting).Inc()
+		log.Warn("escrow stopped awaiting payment before it was funded, recorded as unmatched",
+			zap.String("deal_id", escrow.DealID.String()),
+			zap.String("memo", memo),
+		)
+		rdb.Set(ctx, txKey, "skip:not_awaiting", processedTTL)
+		return nil
+	}
+	if err != nil {
 		log.Error("failed to mark escrow funded",
 			zap.String("deal_id", escrow.DealID.String()),
 			zap.Error(err),
 		)
-		return
+		return fmt.Errorf("mark escrow funded: %w", err)
 	}
+	_ = cursors.Cache(ctx, tonpkg.Cursor{LT: tx.LT, Hash: tx.Hash})
 
 	// Advance deal status
-	if err := dealRepo.UpdateStatus(ctx, escrow.DealID, models.DealStatusFunded); err != nil {
+	err = dealRepo.UpdateStatusIf(ctx, escrow.DealID, models.DealStatusAwaitingPayment, models.DealStatusFunded)
+	if errors.Is(err, repositories.ErrStatusChanged) {
+		// Cancelled (or moved on) while the payment was in flight: the escrow stays funded
+		log.Warn("deal no longer awaiting payment, left as is",
+			zap.String("deal_id", escrow.DealID.String()),
+		)
+		return nil
+	}
+	if err != nil {
 		log.Error("failed to update deal status to funded",
 			zap.String("deal_id", escrow.DealID.String()),
 			zap.Error(err),
 		)
-		return
+		return nil
 	}
 
-	// Publish event for bot notifications / websocket
-	_ = publisher.Publish(ctx, "events:deal", events.Event{
-		Type: events.EventPaymentReceived,
-		Payload: map[string]any{
-			"deal_id":    escrow.DealID.String(),
-			"tx_lt":      tx.LT,
-			"amount_ton": inMsg.Amount.String(),
-			"from":       fromAddr,
-			"memo":       memo,
-		},
-	})
+	publishFunded(ctx, dealRepo, publisher, webAppURL, escrow, payment, tx.LT, memo, overpaid, log)
 
 	rdb.Set(ctx, txKey, "funded:"+escrow.DealID.String(), processedTTL)
+	metricTxsProcessed.WithLabelValues(resultFunded).Inc()
 
 	log.Info("payment processed — deal funded",
 		zap.String("deal_id", escrow.DealID.String()),
 		zap.Uint64("tx_lt", tx.LT),
-		zap.String("amount", inMsg.Amount.String()),
+		zap.String("amount", payment.Display),
+		zap.String("currency", payment.Currency),
 		zap.String("from", fromAddr),
 		zap.String("memo", memo),
 	)
+
+	return nil
 }
 
-// extractComment parses a text comment from an InternalMessage body.
-// TON text comments have opcode 0x00000000 followed by UTF-8 text.
-func extractComment(inMsg *tlb.InternalMessage) string {
-	body := inMsg.Body
-	if body == nil {
-		return ""
+// publishFunded publishes the payment_received event for bot notifications / websocket (the
+// payer gets a bot message) and, if overpaid (smallest units) is set, the overpayment event.
+func publishFunded(
+	ctx context.Context,
+	dealRepo *repositories.DealRepo,
+	publisher events.Publisher,
+	webAppURL string,
+	escrow *models.EscrowLedger,
+	payment *tonpkg.IncomingPayment,
+	txLT uint64,
+	memo string,
+	overpaid string,
+	log *zap.Logger,
+) {
+	var dealURL string
+	if webAppURL != "" {
+		dealURL = webAppURL + "/deals/" + escrow.DealID.String()
 	}
-
-	slice := body.BeginParse()
-	if slice.BitsLeft() < 32 {
-		return ""
+	advertiser, err := dealRepo.GetAdvertiserRecipient(ctx, escrow.DealID)
+	if err != nil {
+		log.Warn("failed to resolve advertiser for payment notification",
+			zap.String("deal_id", escrow.DealID.String()),
+			zap.Error(err),
+		)
 	}
-
-	op, err := slice.LoadUInt(32)
-	if err != nil || op != 0 {
-		return ""
+	_ = publisher.Publish(ctx, "events:deal", events.NewPaymentReceivedEvent(events.PaymentReceived{
+		DealID:               escrow.DealID.String(),
+		AdvertiserTelegramID: advertiser.NotifyTelegramID(),
+		AmountTON:            payment.Display,
+		Currency:             payment.Currency,
+		TxLT:                 txLT,
+		From:                 payment.From,
+		Memo:                 memo,
+		DealURL:              dealURL,
+		Language:             advertiser.LanguageCode,
+	}))
+
+	if overpaid == "" {
+		return
 	}
+	excess, _ := new(big.Int).SetString(overpaid, 10)
+	excessDisplay := tlb.MustFromNano(excess, models.CurrencyDecimals(escrow.Currency)).String()
+	log.Warn("overpayment — excess recorded for refund",
+		zap.String("deal_id", escrow.DealID.String()),
+		zap.String("received", payment.Display),
+		zap.String("expected", escrow.DepositExpectedTON),
+		zap.String("excess", excessDisplay),
+		zap.String("currency", payment.Currency),
+	)
+	_ = publisher.Publish(ctx, "events:deal", events.NewOverpaymentEvent(events.Overpayment{
+		DealID:               escrow.DealID.String(),
+		AdvertiserTelegramID: advertiser.NotifyTelegramID(),
+		Excess:               excessDisplay,
+		ExcessUnits:          overpaid,
+		Currency:             payment.Currency,
+		TxLT:                 txLT,
+		From:                 payment.From,
+		Language:             advertiser.LanguageCode,
+	}))
+}
 
-	remaining := slice.BitsLeft()
-	if remaining < 8 {
-		return ""
+// matchPendingPayments runs after each poll cycle: pending payments past their deadline are
+// escalated to unmatched, the rest are funded once their deal's escrow awaits them (the deal
+// was accepted after the payment landed). Payments the escrow can't take (wrong currency,
+// short amount) wait for the deadline and go to staff.
+func matchPendingPayments(
+	ctx context.Context,
+	escrowRepo *repositories.EscrowRepo,
+	dealRepo *repositories.DealRepo,
+	publisher events.Publisher,
+	webAppURL string,
+	log *zap.Logger,
+) error {
+	escalated, err := escrowRepo.EscalatePendingPayments(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("escalate pending payments: %w", err)
+	}
+	if escalated > 0 {
+		metricTxsProcessed.WithLabelValues(resultNoEscrow).Add(float64(escalated))
+		log.Info("no escrow appeared for pending payments, recorded as unmatched", zap.Int("count", escalated))
 	}
 
-	data, err := slice.LoadSlice(remaining)
+	payments, err := escrowRepo.FindUnmatchedPayments(ctx, models.UnmatchedPaymentStatusPending, 100, 0)
 	if err != nil {
-		return ""
+		return fmt.Errorf("list pending payments: %w", err)
 	}
+	for i := range payments {
+		p := &payments[i]
+		escrow, err := escrowRepo.GetByMemo(ctx, p.Memo)
+		if errors.Is(err, repositories.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("get escrow by memo: %w", err)
+		}
+		overpaid, ok := services.UnmatchedPaymentExcess(escrow, p)
+		if !ok {
+			continue
+		}
 
-	return strings.TrimSpace(string(data))
-}
+		err = escrowRepo.FundFromUnmatchedPayment(ctx, p.ID, escrow.DealID, overpaid)
+		if errors.Is(err, repositories.ErrNotFound) {
+			continue // matched or the escrow moved on concurrently
+		}
+		if err != nil {
+			return fmt.Errorf("fund escrow from pending payment: %w", err)
+		}
+		err = dealRepo.UpdateStatusIf(ctx, escrow.DealID, models.DealStatusAwaitingPayment, models.DealStatusFunded)
+		if errors.Is(err, repositories.ErrStatusChanged) {
+			log.Warn("deal no longer awaiting payment, left as is", zap.String("deal_id", escrow.DealID.String()))
+			continue
+		}
+		if err != nil {
+			log.Error("failed to update deal status to funded",
+				zap.String("deal_id", escrow.DealID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
 
-// parseTONToNano converts a decimal TON string (e.g. "5.5") to nanoTON (*big.Int).
-// 1 TON = 1_000_000_000 nanoTON.
-func parseTONToNano(tonStr string) (*big.Int, error) {
-	tonStr = strings.TrimSpace(tonStr)
-	if tonStr == "" {
-		return nil, fmt.Errorf("empty TON amount")
+		amount, _ := new(big.Int).SetString(p.Amount, 10)
+		payment := &tonpkg.IncomingPayment{
+			Currency: p.Currency,
+			Amount:   amount,
+			Display:  tlb.MustFromNano(amount, models.CurrencyDecimals(p.Currency)).String(),
+			From:     p.FromAddress,
+		}
+		publishFunded(ctx, dealRepo, publisher, webAppURL, escrow, payment, uint64(p.TxLT), p.Memo, overpaid, log)
+		metricTxsProcessed.WithLabelValues(resultFundedLate).Inc()
+		log.Info("pending payment matched — deal funded",
+			zap.String("deal_id", escrow.DealID.String()),
+			zap.Int64("tx_lt", p.TxLT),
+			zap.String("amount", payment.Display),
+			zap.String("currency", p.Currency),
+		)
 	}
+	return nil
+}
 
-	parts := strings.Split(tonStr, ".")
-	if len(parts) > 2 {
-		return nil, fmt.Errorf("invalid TON amount: %s", tonStr)
+// unmatchedPayment is the payment as stored when no escrow takes it (yet).
+func unmatchedPayment(tx *tlb.Transaction, payment *tonpkg.IncomingPayment, memo string) *models.UnmatchedPayment {
+	return &models.UnmatchedPayment{
+		TxLT:        int64(tx.LT),
+		TxHash:      hex.EncodeToString(tx.Hash),
+		FromAddress: payment.From,
+		Amount:      payment.Amount.String(),
+		Currency:    payment.Currency,
+		Memo:        memo,
 	}
+}
 
-	whole := parts[0]
-	frac := ""
-	if len(parts) == 2 {
-		frac = parts[1]
-	}
+// fundedBy reports whether the transaction at lt is the one the indexer funded escrow with.
+func fundedBy(escrow *models.EscrowLedger, lt uint64) bool {
+	return escrow.FundingTxHash != nil && *escrow.FundingTxHash == strconv.FormatUint(lt, 10)
+}
 
-	if len(frac) > 9 {
-		frac = frac[:9]
-	}
-	for len(frac) < 9 {
-		frac += "0"
-	}
+// unmatchedResult reports why a payment in currency can't fund escrow, as the metric result,
+// or "" if it can: the escrow is no longer awaiting (expired with its deal, or already funded,
+// released or refunded), or it awaits another currency.
+func unmatchedResult(escrow *models.EscrowLedger, currency string) string {
+	switch {
+	case escrow.Status != models.EscrowStatusAwaiting:
+		return resultNotAwaiting
+	case currency != escrow.Currency:
+		return resultCurrencyMismatch
+	}
+	return ""
+}
 
-	nano, ok := new(big.Int).SetString(whole+frac, 10)
-	if !ok {
-		return nil, fmt.Errorf("invalid TON amount: %s", tonStr)
+// recordUnmatched stores a payment no escrow can take, for the worker's reconciliation and staff.
+func recordUnmatched(ctx context.Context, escrowRepo *repositories.EscrowRepo, tx *tlb.Transaction, payment *tonpkg.IncomingPayment, memo string) error {
+	if err := escrowRepo.RecordUnmatchedPayment(ctx, unmatchedPayment(tx, payment, memo)); err != nil {
+		return fmt.Errorf("record unmatched payment: %w", err)
 	}
-	return nano, nil
+	return nil
 }
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ads-marketplace/backend/internal/models"
+)
+
+func TestUnmatchedResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		currency string
+		want     string
+	}{
+		{"awaiting, same currency", models.EscrowStatusAwaiting, models.EscrowCurrencyTON, ""},
+		{"TON to a USDT escrow", models.EscrowStatusAwaiting, models.EscrowCurrencyUSDT, resultCurrencyMismatch},
+		{"expired escrow", models.EscrowStatusExpired, models.EscrowCurrencyTON, resultNotAwaiting},
+		{"expired escrow, other currency", models.EscrowStatusExpired, models.EscrowCurrencyUSDT, resultNotAwaiting},
+		{"funded escrow", models.EscrowStatusFunded, models.EscrowCurrencyTON, resultNotAwaiting},
+		{"released escrow", models.EscrowStatusReleased, models.EscrowCurrencyTON, resultNotAwaiting},
+		{"refunded escrow", models.EscrowStatusRefunded, models.EscrowCurrencyTON, resultNotAwaiting},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			escrow := &models.EscrowLedger{Status: tt.status, Currency: models.EscrowCurrencyTON}
+			if got := unmatchedResult(escrow, tt.currency); got != tt.want {
+				t.Errorf("unmatchedResult(%s escrow, %s) = %q, want %q", tt.status, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFundedBy(t *testing.T) {
+	lt := "48000000000001"
+	other := "48000000000002"
+	if !fundedBy(&models.EscrowLedger{FundingTxHash: &lt}, 48000000000001) {
+		t.Error("escrow funded by the transaction not recognised")
+	}
+	if fundedBy(&models.EscrowLedger{FundingTxHash: &other}, 48000000000001) {
+		t.Error("second payment to a funded escrow taken for its funding transaction")
+	}
+	if fundedBy(&models.EscrowLedger{}, 48000000000001) {
+		t.Error("unfunded escrow taken as funded")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// Results of processIncomingTx, the `result` label of ton_indexer_txs_processed_total.
+const (
+	resultFunded           = "funded"
+	resultFundedLate       = "funded_late" // a pending payment whose escrow appeared in the grace window
+	resultInsufficient     = "insufficient"
+	resultNoEscrow         = "no_escrow"
+	resultPendingMatch     = "pending_match"
+	resultNoMemo           = "no_memo"
+	resultNotAwaiting      = "not_awaiting"
+	resultCurrencyMismatch = "currency_mismatch"
+)
+
+var (
+	metricTxsProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ton_indexer_txs_processed_total",
+		Help: "Incoming transfers handled by the indexer, by outcome.",
+	}, []string{"result"})
+	metricPollErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "ton_indexer_poll_errors_total",
+		Help: "Poll cycles that failed.",
+	})
+	metricCursorLT = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ton_indexer_cursor_lt",
+		Help: "Logical time of the last processed hot wallet transaction.",
+	})
+	metricReorgs = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "ton_indexer_reorgs_total",
+		Help: "Reorgs detected: the cursor transaction was no longer on the hot wallet chain.",
+	})
+	metricOrphanedFundings = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "ton_indexer_orphaned_fundings_total",
+		Help: "Escrows funded by a transaction that disappeared in a reorg; need manual review.",
+	})
+	metricUnconfirmedTxs = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ton_indexer_unconfirmed_txs",
+		Help: "Hot wallet transactions seen but not yet TON_CONFIRMATIONS masterchain blocks deep.",
+	})
+	metricPollDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "ton_indexer_poll_duration_seconds",
+		Help:    "Duration of one pollAndProcess cycle.",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	})
+)
+
+// serveMetrics exposes /metrics on the given port; 0 disables it.
+func serveMetrics(port int, log *zap.Logger) {
+	if port == 0 {
+		return
+	}
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(metricTxsProcessed, metricPollErrors, metricCursorLT, metricReorgs, metricOrphanedFundings, metricUnconfirmedTxs, metricPollDuration)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	addr := fmt.Sprintf(":%d", port)
+	go func() {
+		log.Info("metrics listening", zap.String("addr", addr))
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Error("metrics server stopped", zap.Error(err))
+		}
+	}()
+}
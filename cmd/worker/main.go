@@ -3,19 +3,24 @@ package main
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/ads-marketplace/backend/internal/clock"
 	"github.com/ads-marketplace/backend/internal/config"
 	"github.com/ads-marketplace/backend/internal/db"
 	"github.com/ads-marketplace/backend/internal/events"
 	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/ads-marketplace/backend/internal/moderation"
 	"github.com/ads-marketplace/backend/internal/repositories"
 	"github.com/ads-marketplace/backend/internal/services"
 	"github.com/ads-marketplace/backend/internal/statsparser"
+	"github.com/ads-marketplace/backend/internal/ton"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -24,10 +29,11 @@ func main() {
 	defer log.Sync()
 
 	cfg := config.Load()
+	cfg.Validate(log)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	pool, err := db.NewPostgresPool(ctx, cfg.PostgresDSN, log)
+	pool, err := db.NewPostgresPool(ctx, cfg.PostgresDSN, cfg.DBStatementTimeout, log)
 	if err != nil {
 		log.Fatal("failed to connect to postgres", zap.Error(err))
 	}
@@ -41,27 +47,57 @@ func main() {
 
 	// Repos
 	dealRepo := repositories.NewDealRepo(pool)
+	campaignRepo := repositories.NewCampaignRepo(pool)
 	channelRepo := repositories.NewChannelRepo(pool)
 	escrowRepo := repositories.NewEscrowRepo(pool)
 	auditRepo := repositories.NewAuditRepo(pool)
 	withdrawRepo := repositories.NewWithdrawRepo(pool)
 	walletRepo := repositories.NewWalletRepo(pool)
+	balanceRepo := repositories.NewBalanceRepo(pool)
+	ratingRepo := repositories.NewRatingRepo(pool)
+	blockRepo := repositories.NewBlockRepo(pool)
+	webhookRepo := repositories.NewWebhookRepo(pool)
 
 	// Services
+	clk := clock.Real{}
 	publisher := events.NewRedisPublisher(rdb, log)
-	botClient := services.NewBotClient(cfg.BotInternalURL, log)
-	dealService := services.NewDealService(dealRepo, channelRepo, escrowRepo, auditRepo, withdrawRepo, walletRepo, botClient, publisher, cfg, log)
+	botClient := services.NewBotClient(cfg.BotInternalURL, cfg.InternalAPISecret, log)
+	moderator := moderation.NewModerator(cfg.ModerationBlockedKeywords, cfg.ModerationBlockedDomains, cfg.ModerationOnMatch, cfg.ModerationWebhookURL, log)
+	sender := newHotWalletSender(ctx, cfg, log)
 	parser := statsparser.NewParser(cfg.TMEFetchTimeoutMS, cfg.TMEFetchMaxRetries, log)
+	dealService := services.NewDealService(dealRepo, channelRepo, campaignRepo, escrowRepo, auditRepo, withdrawRepo, walletRepo, ratingRepo, blockRepo, botClient, parser, sender, moderator, publisher, clk, cfg, log)
+	earningsService := services.NewEarningsService(balanceRepo, dealRepo, walletRepo, auditRepo, sender, cfg, log)
+	userbotClient := services.NewUserbotClient(cfg.UserbotInternalURL, log)
+	webhookService := services.NewWebhookService(webhookRepo, dealRepo, escrowRepo, cfg, log)
+	notificationService := services.NewNotificationService(dealRepo, publisher, cfg, log)
+
+	// Deal webhooks: terminal status changes are queued for the parties' registered URLs and
+	// POSTed from the outbox by webhookTicker. Status changes are also turned into bot notifications for the advertiser and owner.
+	subscriber := events.NewRedisSubscriber(rdb, log)
+	_ = subscriber.Subscribe(ctx, "events:deal", func(event events.Event) {
+		webhookService.HandleDealEvent(ctx, event)
+		notificationService.HandleDealEvent(ctx, event)
+	})
 
 	log.Info("worker started")
 
 	// Run jobs on tickers
-	timeoutTicker := time.NewTicker(2 * time.Minute)
-	holdTicker := time.NewTicker(1 * time.Minute)
-	postMonitorTicker := time.NewTicker(5 * time.Minute)
+	timeoutTicker := time.NewTicker(cfg.WorkerTimeoutInterval)
+	holdTicker := time.NewTicker(cfg.WorkerHoldInterval)
+	postMonitorTicker := time.NewTicker(cfg.PostMonitorInterval)
+	trustTicker := time.NewTicker(cfg.TrustScoreInterval)
+	payoutTicker := time.NewTicker(1 * time.Minute)
+	scheduledPostTicker := time.NewTicker(cfg.ScheduledPostInterval)
+	reconcileTicker := time.NewTicker(cfg.ReconcileInterval)
+	webhookTicker := time.NewTicker(cfg.WebhookPollInterval)
 	defer timeoutTicker.Stop()
 	defer holdTicker.Stop()
 	defer postMonitorTicker.Stop()
+	defer trustTicker.Stop()
+	defer payoutTicker.Stop()
+	defer scheduledPostTicker.Stop()
+	defer reconcileTicker.Stop()
+	defer webhookTicker.Stop()
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -69,11 +105,25 @@ func main() {
 	for {
 		select {
 		case <-timeoutTicker.C:
-			runDealTimeouts(ctx, dealRepo, dealService, cfg, log)
+			runDealTimeouts(ctx, dealRepo, dealService, clk, cfg, log)
 		case <-holdTicker.C:
-			runHoldRelease(ctx, dealRepo, dealService, log)
+			runHoldRelease(ctx, dealRepo, dealService, clk, cfg, log)
+		case <-scheduledPostTicker.C:
+			runScheduledPosts(ctx, dealRepo, dealService, clk, cfg, log)
+		case <-reconcileTicker.C:
+			runPaymentReconciliation(ctx, dealService, log)
+		case <-webhookTicker.C:
+			runWebhookDeliveries(ctx, webhookService, log)
 		case <-postMonitorTicker.C:
-			runPostMonitoring(ctx, dealRepo, channelRepo, parser, dealService, log)
+			runPostMonitoring(ctx, dealRepo, channelRepo, parser, userbotClient, dealService, clk, cfg, log)
+		case <-trustTicker.C:
+			runTrustScores(ctx, channelRepo, cfg, log)
+		case <-payoutTicker.C:
+			if sender != nil {
+				runWithdrawalPayouts(ctx, earningsService, log)
+				runOverpaymentRefunds(ctx, escrowRepo, dealService, log)
+				runEscrowRefunds(ctx, escrowRepo, dealService, log)
+			}
 		case <-sigCh:
 			log.Info("shutting down worker")
 			cancel()
@@ -84,15 +134,86 @@ func main() {
 	}
 }
 
-func runDealTimeouts(ctx context.Context, dealRepo *repositories.DealRepo, dealService *services.DealService, cfg *config.Config, log *zap.Logger) {
+// newHotWalletSender returns the sender for payouts and refunds, or nil (they stay pending)
+// when TON_HOT_WALLET_MNEMONIC is not set.
+func newHotWalletSender(ctx context.Context, cfg *config.Config, log *zap.Logger) services.TONSender {
+	if cfg.TONHotWalletMnemonic == "" {
+		log.Warn("TON_HOT_WALLET_MNEMONIC is not set, withdrawals and refunds will not be sent")
+		return nil
+	}
+	// Longest rendering: payout of a withdrawal
+	if _, err := ton.TransferComment(cfg.TONSendComment, "payout", "withdrawal", uuid.Nil.String()); err != nil {
+		log.Fatal("invalid TON_SEND_COMMENT_TEMPLATE", zap.Error(err))
+	}
+	api, err := ton.Connect(ctx, cfg, log)
+	if err != nil {
+		log.Fatal("failed to connect to TON network", zap.Error(err))
+	}
+	hotWallet, err := ton.NewHotWallet(api, cfg.TONHotWalletMnemonic, cfg.TONHotWalletAddress)
+	if err != nil {
+		log.Fatal("failed to load hot wallet", zap.Error(err))
+	}
+	return ton.NewLiteClient(hotWallet, cfg.TONSendRetries, cfg.TONSendRetryDelay)
+}
+
+func runWithdrawalPayouts(ctx context.Context, earningsService *services.EarningsService, log *zap.Logger) {
+	if err := earningsService.ProcessWithdrawals(ctx); err != nil {
+		log.Error("failed to process withdrawals", zap.Error(err))
+	}
+}
+
+func runPaymentReconciliation(ctx context.Context, dealService *services.DealService, log *zap.Logger) {
+	funded, err := dealService.ReconcileUnmatchedPayments(ctx, 100)
+	if err != nil {
+		log.Error("failed to reconcile unmatched payments", zap.Error(err))
+	}
+	if funded > 0 {
+		log.Info("funded deals from unmatched payments", zap.Int("count", funded))
+	}
+}
+
+func runWebhookDeliveries(ctx context.Context, webhookService *services.WebhookService, log *zap.Logger) {
+	if err := webhookService.ProcessDeliveries(ctx); err != nil {
+		log.Error("failed to process webhook deliveries", zap.Error(err))
+	}
+}
+
+func runOverpaymentRefunds(ctx context.Context, escrowRepo *repositories.EscrowRepo, dealService *services.DealService, log *zap.Logger) {
+	dealIDs, err := escrowRepo.ListUnrefundedOverpayments(ctx, 20)
+	if err != nil {
+		log.Error("failed to list overpayments", zap.Error(err))
+		return
+	}
+	for _, id := range dealIDs {
+		if err := dealService.RefundOverpayment(ctx, id); err != nil {
+			log.Error("failed to refund overpayment", zap.String("deal_id", id.String()), zap.Error(err))
+		}
+	}
+}
+
+func runEscrowRefunds(ctx context.Context, escrowRepo *repositories.EscrowRepo, dealService *services.DealService, log *zap.Logger) {
+	dealIDs, err := escrowRepo.ListPendingRefunds(ctx, 20)
+	if err != nil {
+		log.Error("failed to list pending escrow refunds", zap.Error(err))
+		return
+	}
+	for _, id := range dealIDs {
+		if err := dealService.RefundEscrow(ctx, id); err != nil {
+			log.Error("failed to refund escrow", zap.String("deal_id", id.String()), zap.Error(err))
+		}
+	}
+}
+
+func runDealTimeouts(ctx context.Context, dealRepo *repositories.DealRepo, dealService *services.DealService, clk clock.Clock, cfg *config.Config, log *zap.Logger) {
 	timeouts := map[string]int{
 		models.DealStatusSubmitted:         cfg.DealTimeoutSubmittedSeconds,
 		models.DealStatusAwaitingPayment:   cfg.DealTimeoutPaymentSeconds,
 		models.DealStatusCreativeSubmitted: cfg.DealTimeoutCreativeSeconds,
 	}
 
+	now := clk.Now()
 	for status, timeout := range timeouts {
-		deals, err := dealRepo.GetTimedOutDeals(ctx, status, timeout)
+		deals, err := dealRepo.GetTimedOutDeals(ctx, status, models.DealTimeoutCutoff(now, timeout))
 		if err != nil {
 			log.Error("failed to get timed out deals", zap.String("status", status), zap.Error(err))
 			continue
@@ -110,22 +231,72 @@ func runDealTimeouts(ctx context.Context, dealRepo *repositories.DealRepo, dealS
 	}
 }
 
-func runHoldRelease(ctx context.Context, dealRepo *repositories.DealRepo, dealService *services.DealService, log *zap.Logger) {
-	deals, err := dealRepo.GetPostedDealsInHold(ctx)
+// scheduledPostBatchSize caps the bot posts one run sends; the rest wait for the next tick.
+const scheduledPostBatchSize = 20
+
+func runScheduledPosts(ctx context.Context, dealRepo *repositories.DealRepo, dealService *services.DealService, clk clock.Clock, cfg *config.Config, log *zap.Logger) {
+	deals, err := dealRepo.GetDueScheduledDeals(ctx, clk.Now(), cfg.PostRetryBase, cfg.PostRetryMax, scheduledPostBatchSize)
+	if err != nil {
+		log.Error("failed to get due scheduled deals", zap.Error(err))
+		return
+	}
+	for _, deal := range deals {
+		log.Info("publishing scheduled post", zap.String("deal_id", deal.ID.String()))
+		if err := dealService.PublishScheduledPost(ctx, deal.ID); err != nil {
+			log.Error("failed to publish scheduled post", zap.String("deal_id", deal.ID.String()), zap.Error(err))
+		}
+	}
+}
+
+func runHoldRelease(ctx context.Context, dealRepo *repositories.DealRepo, dealService *services.DealService, clk clock.Clock, cfg *config.Config, log *zap.Logger) {
+	deals, err := dealRepo.GetPostedDealsInHold(ctx, clk.Now(), cfg.ReleaseRetryBase, cfg.ReleaseRetryMax)
 	if err != nil {
 		log.Error("failed to get deals for hold release", zap.Error(err))
 		return
 	}
+	stories, err := dealRepo.GetStoryDealsInHold(ctx, clk.Now(), cfg.ReleaseRetryBase, cfg.ReleaseRetryMax)
+	if err != nil {
+		log.Error("failed to get story deals for hold release", zap.Error(err))
+	}
+	deals = append(deals, stories...)
 
 	for _, deal := range deals {
 		log.Info("releasing funds for deal", zap.String("deal_id", deal.ID.String()))
-		if err := dealService.ReleaseFunds(ctx, deal.ID); err != nil {
-			log.Error("failed to release funds", zap.String("deal_id", deal.ID.String()), zap.Error(err))
+		err := dealService.ReleaseFunds(ctx, deal.ID)
+		// Status changed or frozen since the query — nothing to retry
+		if err == nil || errors.Is(err, services.ErrDealNotInHold) || errors.Is(err, services.ErrPayoutFrozen) {
+			continue
+		}
+		if err := dealService.RecordReleaseFailure(ctx, deal.ID, err); err != nil {
+			log.Error("failed to record release failure", zap.String("deal_id", deal.ID.String()), zap.Error(err))
+		}
+	}
+}
+
+// runTrustScores recomputes trust_score for every active channel.
+func runTrustScores(ctx context.Context, channelRepo *repositories.ChannelRepo, cfg *config.Config, log *zap.Logger) {
+	rows, err := channelRepo.ListTrustSignals(ctx)
+	if err != nil {
+		log.Error("failed to get trust signals", zap.Error(err))
+		return
+	}
+
+	weights := models.TrustWeights{
+		Verified:    cfg.TrustWeightVerified,
+		Deals:       cfg.TrustWeightDeals,
+		Rating:      cfg.TrustWeightRating,
+		ER:          cfg.TrustWeightER,
+		Consistency: cfg.TrustWeightConsistency,
+	}
+	for _, r := range rows {
+		if err := channelRepo.SetTrustScore(ctx, r.ChannelID, models.TrustScore(r.Signals, weights)); err != nil {
+			log.Error("failed to save trust score", zap.String("channel_id", r.ChannelID.String()), zap.Error(err))
 		}
 	}
+	log.Info("trust scores updated", zap.Int("channels", len(rows)))
 }
 
-func runPostMonitoring(ctx context.Context, dealRepo *repositories.DealRepo, channelRepo *repositories.ChannelRepo, parser *statsparser.Parser, dealService *services.DealService, log *zap.Logger) {
+func runPostMonitoring(ctx context.Context, dealRepo *repositories.DealRepo, channelRepo *repositories.ChannelRepo, parser *statsparser.Parser, userbotClient *services.UserbotClient, dealService *services.DealService, clk clock.Clock, cfg *config.Config, log *zap.Logger) {
 	// Get all deals in hold_verification
 	deals, err := dealRepo.List(ctx, repositories.DealFilter{
 		Status: strPtr(models.DealStatusHoldVerification),
@@ -136,6 +307,9 @@ func runPostMonitoring(ctx context.Context, dealRepo *repositories.DealRepo, cha
 		return
 	}
 
+	var due []*models.DealPost
+	var dueChannels []uuid.UUID
+	var checks []statsparser.PostCheck
 	for _, deal := range deals {
 		post, err := dealRepo.GetPost(ctx, deal.ID)
 		if err != nil {
@@ -144,53 +318,123 @@ func runPostMonitoring(ctx context.Context, dealRepo *repositories.DealRepo, cha
 		if post.IsDeleted {
 			continue
 		}
+		// Backoff: свежие посты проверяем часто, старые — реже
+		if !models.PostCheckDue(post.PostedAt, post.LastCheckedAt, clk.Now(), cfg.PostCheckMinInterval, cfg.PostCheckMaxInterval) {
+			continue
+		}
+		if post.TelegramMessageID == nil && post.PostURL == nil {
+			continue
+		}
+		// Стори сама исчезает через 24ч — после истечения её не проверяем
+		if post.StoryExpired(clk.Now()) {
+			continue
+		}
 
 		ch, err := channelRepo.GetByID(ctx, deal.ChannelID)
 		if err != nil {
 			continue
 		}
+		_ = dealRepo.MarkPostChecked(ctx, deal.ID, clk.Now())
+
+		// Stories aren't on the t.me web preview, only the userbot can see them
+		if post.StoryID != nil {
+			checkStory(ctx, dealRepo, userbotClient, dealService, ch.Username, post, clk, log)
+			continue
+		}
+
+		check := statsparser.PostCheck{Username: ch.Username}
+		if post.TelegramMessageID != nil {
+			check.MessageID = *post.TelegramMessageID
+		}
+		due = append(due, post)
+		dueChannels = append(dueChannels, deal.ChannelID)
+		checks = append(checks, check)
+	}
+
+	// Параллельно по каналам, но не чаще одного запроса в канал за PostMonitorChannelInterval
+	statsparser.CheckPosts(ctx, parser, checks, cfg.PostMonitorConcurrency, cfg.PostMonitorChannelInterval, func(i int, r statsparser.PostResult) {
+		post := due[i]
 
 		// Check via HTML parsing
 		if post.TelegramMessageID != nil {
-			text, exists, err := parser.FetchPostContent(ctx, ch.Username, *post.TelegramMessageID)
-			if err != nil {
-				log.Warn("failed to check post", zap.Error(err))
-				continue
+			if r.Err != nil {
+				log.Warn("failed to check post", zap.Error(r.Err))
+				return
 			}
 
-			if !exists {
+			if !r.Exists {
 				log.Warn("post deleted detected",
-					zap.String("deal_id", deal.ID.String()),
+					zap.String("deal_id", post.DealID.String()),
 					zap.Int64("message_id", *post.TelegramMessageID),
 				)
-				_ = dealRepo.UpdatePostFlags(ctx, deal.ID, true, false)
-				_ = dealService.RefundDeal(ctx, deal.ID)
-				continue
+				_ = dealRepo.UpdatePostFlags(ctx, post.DealID, true, false)
+				if err := dealService.FailHoldVerification(ctx, post.DealID, "post_deleted"); err != nil {
+					log.Error("failed to refund deal with deleted post", zap.String("deal_id", post.DealID.String()), zap.Error(err))
+				}
+				return
 			}
 
 			// Check for edits by comparing content hash
-			if post.ContentHash != nil && text != "" {
-				currentHash := sha256Hex(text)
+			if post.ContentHash != nil && r.Text != "" {
+				currentHash := sha256Hex(r.Text)
 				if currentHash != *post.ContentHash {
 					log.Warn("post edited detected",
-						zap.String("deal_id", deal.ID.String()),
+						zap.String("deal_id", post.DealID.String()),
 					)
-					_ = dealRepo.UpdatePostFlags(ctx, deal.ID, false, true)
-					// For MVP: notify but don't auto-refund on edits
+					_ = dealRepo.UpdatePostFlags(ctx, post.DealID, false, true)
+					// Refund only if the listing promised it; otherwise the edit is just flagged
+					listing, err := channelRepo.GetListing(ctx, dueChannels[i])
+					if err != nil || !listing.RefundOnEdit {
+						return
+					}
+					if err := dealService.FailHoldVerification(ctx, post.DealID, "post_edited"); err != nil {
+						log.Error("failed to refund deal with edited post", zap.String("deal_id", post.DealID.String()), zap.Error(err))
+					}
 				}
 			}
-		} else if post.PostURL != nil {
-			// Manual post — try to parse from URL
-			// Extract message ID from URL like https://t.me/username/123
-			// For now just check if page exists
-			_, exists, err := parser.FetchPostContent(ctx, ch.Username, 0)
-			if err != nil || !exists {
-				log.Warn("manual post might be deleted", zap.String("deal_id", deal.ID.String()))
-			}
+			return
+		}
+
+		// Manual post — try to parse from URL
+		// Extract message ID from URL like https://t.me/username/123
+		// For now just check if page exists
+		if r.Err != nil || !r.Exists {
+			log.Warn("manual post might be deleted", zap.String("deal_id", post.DealID.String()))
+		}
+	})
+}
+
+// checkStory asks the userbot whether a story that should still be live is up. Gone before
+// expiry counts as a deletion (refund); while it's up, its view count is recorded so the
+// hold can complete (see DealRepo.GetStoryDealsInHold).
+func checkStory(ctx context.Context, dealRepo *repositories.DealRepo, userbotClient *services.UserbotClient, dealService *services.DealService, username string, post *models.DealPost, clk clock.Clock, log *zap.Logger) {
+	story, err := userbotClient.GetStory(ctx, username, *post.StoryID)
+	if err != nil {
+		log.Warn("failed to check story", zap.String("deal_id", post.DealID.String()), zap.Error(err))
+		return
+	}
+
+	if !story.Exists {
+		if post.StoryExpired(clk.Now()) {
+			return
+		}
+		log.Warn("story deleted before expiry detected",
+			zap.String("deal_id", post.DealID.String()),
+			zap.Int64("story_id", *post.StoryID),
+		)
+		_ = dealRepo.UpdatePostFlags(ctx, post.DealID, true, false)
+		if err := dealService.FailHoldVerification(ctx, post.DealID, "story_deleted"); err != nil {
+			log.Error("failed to refund deal", zap.String("deal_id", post.DealID.String()), zap.Error(err))
 		}
+		return
+	}
 
-		time.Sleep(1 * time.Second) // rate limiting
+	// No count from the userbot still proves the story was live
+	views := 0
+	if story.Views != nil {
+		views = *story.Views
 	}
+	_ = dealRepo.SetStoryViews(ctx, post.DealID, views)
 }
 
 func strPtr(s string) *string {
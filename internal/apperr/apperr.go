@@ -0,0 +1,113 @@
+package apperr
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLang is used when Accept-Language names no supported language.
+const DefaultLang = "en"
+
+// Error is a user-facing error with a stable code. Clients should branch on Code;
+// Message is the English text, used for logs and as the fallback. Field optionally names
+// the request field at fault ("media_urls[2]").
+type Error struct {
+	Code    string
+	Message string
+	Field   string
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Is matches coded errors by code, so a WithField copy still matches its sentinel.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithField returns a copy of e about the given request field.
+func (e *Error) WithField(field string) *Error {
+	c := *e
+	c.Field = field
+	return &c
+}
+
+// New returns a coded error with its English catalog message.
+func New(code string) *Error {
+	msg, ok := catalogs[DefaultLang][code]
+	if !ok {
+		msg = code
+	}
+	return &Error{Code: code, Message: msg}
+}
+
+// CodeOf returns the code of a coded error in err's chain, or "".
+func CodeOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+// FieldOf returns the request field of a coded error in err's chain, or "".
+func FieldOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Field
+	}
+	return ""
+}
+
+// Localize returns the message for err in lang: catalog entry for its code,
+// then the English entry, then err.Error() for uncoded errors.
+func Localize(err error, lang string) string {
+	code := CodeOf(err)
+	if code != "" {
+		if msg, ok := catalogs[lang][code]; ok {
+			return msg
+		}
+		if msg, ok := catalogs[DefaultLang][code]; ok {
+			return msg
+		}
+	}
+	return err.Error()
+}
+
+// Lang picks the best supported language from an Accept-Language header
+// ("ru-RU,ru;q=0.9,en;q=0.8" → "ru"). Unsupported or empty → DefaultLang.
+func Lang(acceptLanguage string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var cands []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if i := strings.IndexByte(tag, '-'); i > 0 {
+			tag = tag[:i]
+		}
+		if _, ok := catalogs[tag]; !ok {
+			continue
+		}
+		q := 1.0
+		for _, f := range fields[1:] {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(f), "q="); ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			cands = append(cands, candidate{tag, q})
+		}
+	}
+	if len(cands) == 0 {
+		return DefaultLang
+	}
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].q > cands[j].q })
+	return cands[0].lang
+}
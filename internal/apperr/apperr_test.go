@@ -0,0 +1,99 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCatalogsCoverSameCodes(t *testing.T) {
+	for lang, msgs := range catalogs {
+		for code := range catalogs[DefaultLang] {
+			if msgs[code] == "" {
+				t.Errorf("%s: missing message for %q", lang, code)
+			}
+		}
+		for code := range msgs {
+			if _, ok := catalogs[DefaultLang][code]; !ok {
+				t.Errorf("%s: code %q is not in the %s catalog", lang, code, DefaultLang)
+			}
+		}
+	}
+}
+
+func TestLang(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", "en"},
+		{"ru", "ru"},
+		{"ru-RU,ru;q=0.9,en-US;q=0.8,en;q=0.7", "ru"},
+		{"en-US,en;q=0.9,ru;q=0.8", "en"},
+		{"de-DE,de;q=0.9,ru;q=0.5", "ru"},
+		{"en;q=0.5, ru;q=0.8", "ru"},
+		{"ru;q=0, en", "en"},
+		{"fr, de", "en"},
+		{"RU-ru", "ru"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := Lang(tt.header); got != tt.expected {
+				t.Errorf("Lang(%q) = %q, want %q", tt.header, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	coded := New(CodeOfferNotFound)
+	wrapped := fmt.Errorf("accept offer: %w", coded)
+	plain := errors.New("something odd")
+
+	tests := []struct {
+		name     string
+		err      error
+		lang     string
+		expected string
+		code     string
+	}{
+		{"english", coded, "en", "offer not found", CodeOfferNotFound},
+		{"russian", coded, "ru", "предложение не найдено", CodeOfferNotFound},
+		{"unknown lang falls back to english", coded, "de", "offer not found", CodeOfferNotFound},
+		{"wrapped keeps code", wrapped, "ru", "предложение не найдено", CodeOfferNotFound},
+		{"uncoded passes through", plain, "ru", "something odd", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Localize(tt.err, tt.lang); got != tt.expected {
+				t.Errorf("Localize() = %q, want %q", got, tt.expected)
+			}
+			if got := CodeOf(tt.err); got != tt.code {
+				t.Errorf("CodeOf() = %q, want %q", got, tt.code)
+			}
+		})
+	}
+}
+
+func TestWithField(t *testing.T) {
+	sentinel := New(CodeOfferNotFound)
+	err := fmt.Errorf("submit: %w", sentinel.WithField("media_urls[2]"))
+
+	if !errors.Is(err, sentinel) {
+		t.Error("errors.Is(WithField copy, sentinel) = false, want true")
+	}
+	if errors.Is(err, New(CodeRepostURLRequired)) {
+		t.Error("errors.Is matched a different code")
+	}
+	if got := FieldOf(err); got != "media_urls[2]" {
+		t.Errorf("FieldOf() = %q, want %q", got, "media_urls[2]")
+	}
+	if got := FieldOf(sentinel); got != "" {
+		t.Errorf("sentinel field = %q, want it untouched", got)
+	}
+	if got := CodeOf(err); got != CodeOfferNotFound {
+		t.Errorf("CodeOf() = %q, want %q", got, CodeOfferNotFound)
+	}
+}
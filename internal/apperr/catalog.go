@@ -0,0 +1,166 @@
+package apperr
+
+// Error codes. Stable: clients match on them, only add new ones.
+const (
+	CodeChannelExists          = "channel_exists"
+	CodeNotChannelMember       = "not_channel_member"
+	CodeRepostURLRequired      = "repost_url_required"
+	CodeSkipApprovalNotAllowed = "skip_approval_not_allowed"
+	CodeCreativeNotFound       = "creative_not_found"
+	CodeCreativeNotPending     = "creative_not_pending"
+	CodeDealNotInHold          = "deal_not_in_hold"
+	CodeWalletNotConnected     = "wallet_not_connected"
+	CodeWalletNotVerified      = "wallet_not_verified"
+	CodeWalletConnectLocked    = "wallet_connect_locked"
+	CodeTooManyAttempts        = "too_many_attempts"
+	CodeEscrowNotFound         = "escrow_not_found"
+	CodePaymentNotAwaited      = "payment_not_awaited"
+	CodeRefundRequestNotFound  = "refund_request_not_found"
+	CodeRefundRequestPending   = "refund_request_pending"
+	CodeCampaignNotFound       = "campaign_not_found"
+	CodeOfferNotFound          = "offer_not_found"
+	CodeOfferUnavailable       = "offer_unavailable"
+	CodeOfferToSelf            = "offer_to_self"
+	CodeValidUntilInPast       = "valid_until_in_past"
+	CodeScheduledAtInPast      = "scheduled_at_in_past"
+	CodePayoutFrozen           = "payout_frozen"
+	CodeDealNotDisputable      = "deal_not_disputable"
+	CodeDealNotDisputed        = "deal_not_disputed"
+	CodeNoPendingCounteroffer  = "no_pending_counteroffer"
+	CodeInvalidListingStatus   = "invalid_listing_status"
+	CodeListingNotActive       = "listing_not_active"
+	CodeNotChannelOwner        = "not_channel_owner"
+	CodeCannotRemoveOwner      = "cannot_remove_owner"
+	CodeStatsRefreshCooldown   = "stats_refresh_cooldown"
+	CodeStatsRefreshTimeout    = "stats_refresh_timeout"
+	CodeStatsRefreshFailed     = "stats_refresh_failed"
+	CodeWithdrawWalletNotOwned = "withdraw_wallet_not_owned"
+	CodeDealChanged            = "deal_changed"
+	CodeWebhookNotFound        = "webhook_not_found"
+	CodeInvalidWebhookURL      = "invalid_webhook_url"
+	CodeInvalidWebhookEvents   = "invalid_webhook_events"
+	CodeWebhookLimit           = "webhook_limit"
+	CodeInvalidRefreshToken    = "invalid_refresh_token"
+	CodePostChannelMismatch    = "post_channel_mismatch"
+	CodeTooManyMedia           = "too_many_media"
+	CodeInvalidMediaURL        = "invalid_media_url"
+	CodeTooManyButtons         = "too_many_buttons"
+	CodeInvalidButton          = "invalid_button"
+	CodeDealNotRatable         = "deal_not_ratable"
+	CodeDealAlreadyRated       = "deal_already_rated"
+	CodeInvalidRating          = "invalid_rating"
+	CodeUserBlocked            = "user_blocked"
+	CodeCannotBlockSelf        = "cannot_block_self"
+	CodeUnknownCurrency        = "unknown_currency"
+	CodeEscrowNotFunded        = "escrow_not_funded"
+)
+
+// catalogs: lang → code → message. Every language must cover every code (see tests).
+var catalogs = map[string]map[string]string{
+	"en": {
+		CodeChannelExists:          "channel already exists",
+		CodeNotChannelMember:       "user is not a member of this channel",
+		CodeRepostURLRequired:      "repost format requires repost_from_url",
+		CodeSkipApprovalNotAllowed: "this channel does not allow skipping creative approval",
+		CodeCreativeNotFound:       "creative not found",
+		CodeCreativeNotPending:     "creative is not pending review",
+		CodeDealNotInHold:          "deal is not in hold_verification",
+		CodeWalletNotConnected:     "no verified wallet connected — connect your wallet via TON Connect first",
+		CodeWalletNotVerified:      "connected wallet is not verified",
+		CodeWalletConnectLocked:    "too many failed wallet verifications — try again later",
+		CodeTooManyAttempts:        "too many attempts — slow down and try again in a minute",
+		CodeEscrowNotFound:         "escrow not found for deal",
+		CodePaymentNotAwaited:      "deal is not awaiting payment",
+		CodeRefundRequestNotFound:  "refund address request not found",
+		CodeRefundRequestPending:   "a refund address request is already pending for this deal",
+		CodeCampaignNotFound:       "campaign not found",
+		CodeOfferNotFound:          "offer not found",
+		CodeOfferUnavailable:       "offer is no longer available",
+		CodeOfferToSelf:            "cannot address an offer to yourself",
+		CodeValidUntilInPast:       "valid_until must be in the future",
+		CodeScheduledAtInPast:      "scheduled_at must be in the future",
+		CodePayoutFrozen:           "payouts for this deal are frozen pending investigation",
+		CodeDealNotDisputable:      "the post can only be disputed while the deal is posted or in hold verification",
+		CodeDealNotDisputed:        "deal is not under dispute",
+		CodeNoPendingCounteroffer:  "there is no pending counteroffer for this deal",
+		CodeInvalidListingStatus:   "Listing status must be draft, active or paused",
+		CodeListingNotActive:       "This channel is not accepting new deals right now",
+		CodeNotChannelOwner:        "only the channel owner can do this",
+		CodeCannotRemoveOwner:      "the channel owner cannot be removed",
+		CodeStatsRefreshCooldown:   "Stats were refreshed recently, try again in a few minutes",
+		CodeStatsRefreshTimeout:    "Stats refresh is taking longer than usual, check back shortly",
+		CodeStatsRefreshFailed:     "Could not fetch channel stats right now",
+		CodeWithdrawWalletNotOwned: "withdraw address must be one of your connected wallets",
+		CodeDealChanged:            "The deal was changed by someone else, reload it and try again",
+		CodeWebhookNotFound:        "Webhook not found",
+		CodeInvalidWebhookURL:      "Webhook URL must be a public https URL",
+		CodeInvalidWebhookEvents:   "Webhook events must be completed, refunded or cancelled",
+		CodeWebhookLimit:           "Too many webhooks registered",
+		CodeInvalidRefreshToken:    "Invalid or expired refresh token",
+		CodePostChannelMismatch:    "post link points to a different channel than the deal's",
+		CodeTooManyMedia:           "too many media files in the creative",
+		CodeInvalidMediaURL:        "media URL must be a valid http(s) link",
+		CodeTooManyButtons:         "too many buttons in the creative",
+		CodeInvalidButton:          "button needs a text and a valid http(s) or t.me link",
+		CodeDealNotRatable:         "only completed deals can be rated",
+		CodeDealAlreadyRated:       "you have already rated this deal",
+		CodeInvalidRating:          "stars must be between 1 and 5",
+		CodeUserBlocked:            "the advertiser and the channel have blocked each other",
+		CodeCannotBlockSelf:        "you cannot block yourself",
+		CodeUnknownCurrency:        "currency must be TON or USDT",
+		CodeEscrowNotFunded:        "escrow no longer holds the funds",
+	},
+	"ru": {
+		CodeChannelExists:          "канал уже зарегистрирован",
+		CodeNotChannelMember:       "пользователь не является участником этого канала",
+		CodeRepostURLRequired:      "для формата репоста нужна ссылка repost_from_url",
+		CodeSkipApprovalNotAllowed: "этот канал не разрешает пропускать согласование креатива",
+		CodeCreativeNotFound:       "креатив не найден",
+		CodeCreativeNotPending:     "креатив не ожидает проверки",
+		CodeDealNotInHold:          "сделка не находится на проверке публикации",
+		CodeWalletNotConnected:     "нет подтверждённого кошелька — сначала подключите кошелёк через TON Connect",
+		CodeWalletNotVerified:      "подключённый кошелёк не подтверждён",
+		CodeWalletConnectLocked:    "слишком много неудачных проверок кошелька — попробуйте позже",
+		CodeTooManyAttempts:        "слишком много попыток — подождите минуту и повторите",
+		CodeEscrowNotFound:         "эскроу для сделки не найден",
+		CodePaymentNotAwaited:      "сделка не ожидает оплаты",
+		CodeRefundRequestNotFound:  "запрос на адрес возврата не найден",
+		CodeRefundRequestPending:   "запрос на адрес возврата для этой сделки уже ожидает рассмотрения",
+		CodeCampaignNotFound:       "кампания не найдена",
+		CodeOfferNotFound:          "предложение не найдено",
+		CodeOfferUnavailable:       "предложение больше недоступно",
+		CodeOfferToSelf:            "нельзя отправить предложение самому себе",
+		CodeValidUntilInPast:       "valid_until должен быть в будущем",
+		CodeScheduledAtInPast:      "scheduled_at должен быть в будущем",
+		CodePayoutFrozen:           "выплата по сделке заморожена до окончания проверки",
+		CodeDealNotDisputable:      "оспорить размещение можно только пока сделка опубликована или на проверке холда",
+		CodeDealNotDisputed:        "по сделке нет открытого спора",
+		CodeNoPendingCounteroffer:  "по сделке нет ожидающего встречного предложения",
+		CodeInvalidListingStatus:   "Статус листинга должен быть draft, active или paused",
+		CodeListingNotActive:       "Канал сейчас не принимает новые сделки",
+		CodeNotChannelOwner:        "это может сделать только владелец канала",
+		CodeCannotRemoveOwner:      "владельца канала нельзя удалить",
+		CodeStatsRefreshCooldown:   "Статистика недавно обновлялась, попробуйте через несколько минут",
+		CodeStatsRefreshTimeout:    "Обновление статистики занимает больше времени, загляните чуть позже",
+		CodeStatsRefreshFailed:     "Не удалось получить статистику канала",
+		CodeWithdrawWalletNotOwned: "адрес для вывода должен быть одним из ваших подключённых кошельков",
+		CodeDealChanged:            "Сделку только что изменили, обновите её и повторите",
+		CodeWebhookNotFound:        "Вебхук не найден",
+		CodeInvalidWebhookURL:      "URL вебхука должен быть публичным https-адресом",
+		CodeInvalidWebhookEvents:   "События вебхука: completed, refunded или cancelled",
+		CodeWebhookLimit:           "Зарегистрировано слишком много вебхуков",
+		CodeInvalidRefreshToken:    "Недействительный или истёкший refresh-токен",
+		CodePostChannelMismatch:    "ссылка ведёт на пост другого канала, не канала сделки",
+		CodeTooManyMedia:           "слишком много медиафайлов в креативе",
+		CodeInvalidMediaURL:        "ссылка на медиа должна быть корректной http(s)-ссылкой",
+		CodeTooManyButtons:         "слишком много кнопок в креативе",
+		CodeInvalidButton:          "у кнопки должны быть текст и корректная http(s)- или t.me-ссылка",
+		CodeDealNotRatable:         "оценить можно только завершённую сделку",
+		CodeDealAlreadyRated:       "вы уже оценили эту сделку",
+		CodeInvalidRating:          "оценка должна быть от 1 до 5",
+		CodeUserBlocked:            "рекламодатель и канал заблокировали друг друга",
+		CodeCannotBlockSelf:        "нельзя заблокировать самого себя",
+		CodeUnknownCurrency:        "валюта должна быть TON или USDT",
+		CodeEscrowNotFunded:        "эскроу больше не удерживает средства",
+	},
+}
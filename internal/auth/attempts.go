@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// AttemptStore holds attempt counters and lock markers (Redis in production).
+type AttemptStore interface {
+	// Incr bumps key and returns the new value; a new key expires after window.
+	Incr(ctx context.Context, key string, window time.Duration) (int64, error)
+	// TTL is how long key lives on, 0 if it does not exist.
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	Set(ctx context.Context, key string, ttl time.Duration) error
+	Del(ctx context.Context, key string) error
+}
+
+// Allow is a fixed-window rate limit: at most limit calls per window for key, counted
+// under rl:<key>. Over the limit it returns how long until the window resets.
+// limit <= 0 disables it.
+func Allow(ctx context.Context, store AttemptStore, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	if limit <= 0 {
+		return true, 0, nil
+	}
+	key = "rl:" + key
+	n, err := store.Incr(ctx, key, window)
+	if err != nil {
+		return false, 0, err
+	}
+	if n <= int64(limit) {
+		return true, 0, nil
+	}
+	ttl, err := store.TTL(ctx, key)
+	if err != nil || ttl <= 0 {
+		// The expiry after the first hit was lost: restart the window instead of
+		// blocking the key forever.
+		_ = store.Set(ctx, key, window)
+		ttl = window
+	}
+	return false, ttl, nil
+}
+
+// Lockout locks a subject out for cooldown after maxFailures failures within cooldown.
+type Lockout struct {
+	store       AttemptStore
+	prefix      string
+	maxFailures int
+	cooldown    time.Duration
+}
+
+// NewLockout; maxFailures <= 0 disables locking.
+func NewLockout(store AttemptStore, prefix string, maxFailures int, cooldown time.Duration) *Lockout {
+	return &Lockout{store: store, prefix: prefix, maxFailures: maxFailures, cooldown: cooldown}
+}
+
+func (l *Lockout) failuresKey(subject string) string { return l.prefix + ":failures:" + subject }
+func (l *Lockout) lockKey(subject string) string     { return l.prefix + ":locked:" + subject }
+
+// Locked returns the remaining cool-down, 0 if the subject may try.
+func (l *Lockout) Locked(ctx context.Context, subject string) (time.Duration, error) {
+	if l.maxFailures <= 0 {
+		return 0, nil
+	}
+	return l.store.TTL(ctx, l.lockKey(subject))
+}
+
+// Fail records a failure and reports whether it triggered the lockout.
+func (l *Lockout) Fail(ctx context.Context, subject string) (bool, error) {
+	if l.maxFailures <= 0 {
+		return false, nil
+	}
+	n, err := l.store.Incr(ctx, l.failuresKey(subject), l.cooldown)
+	if err != nil {
+		return false, err
+	}
+	if n < int64(l.maxFailures) {
+		return false, nil
+	}
+	if err := l.store.Set(ctx, l.lockKey(subject), l.cooldown); err != nil {
+		return false, err
+	}
+	_ = l.store.Del(ctx, l.failuresKey(subject))
+	return true, nil
+}
+
+// Reset forgets failures after a success.
+func (l *Lockout) Reset(ctx context.Context, subject string) error {
+	return l.store.Del(ctx, l.failuresKey(subject))
+}
+
+// RedisAttemptStore implements AttemptStore on Redis.
+type RedisAttemptStore struct {
+	rdb *redis.Client
+}
+
+func NewRedisAttemptStore(rdb *redis.Client) *RedisAttemptStore {
+	return &RedisAttemptStore{rdb: rdb}
+}
+
+func (s *RedisAttemptStore) Incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+	n, err := s.rdb.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 {
+		s.rdb.Expire(ctx, key, window)
+	}
+	return n, nil
+}
+
+func (s *RedisAttemptStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	d, err := s.rdb.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 { // -2 missing, -1 no expiry (never set by us)
+		return 0, nil
+	}
+	return d, nil
+}
+
+func (s *RedisAttemptStore) Set(ctx context.Context, key string, ttl time.Duration) error {
+	return s.rdb.Set(ctx, key, 1, ttl).Err()
+}
+
+func (s *RedisAttemptStore) Del(ctx context.Context, key string) error {
+	return s.rdb.Del(ctx, key).Err()
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ads-marketplace/backend/internal/clock"
+)
+
+// memAttempts is an in-memory AttemptStore with expiry driven by a fake clock.
+type memAttempts struct {
+	clk     *clock.Fake
+	values  map[string]int64
+	expires map[string]time.Time
+}
+
+func newMemAttempts(clk *clock.Fake) *memAttempts {
+	return &memAttempts{clk: clk, values: map[string]int64{}, expires: map[string]time.Time{}}
+}
+
+func (m *memAttempts) live(key string) bool {
+	exp, ok := m.expires[key]
+	if ok && !m.clk.Now().Before(exp) {
+		delete(m.values, key)
+		delete(m.expires, key)
+		return false
+	}
+	_, ok = m.values[key]
+	return ok
+}
+
+func (m *memAttempts) Incr(_ context.Context, key string, window time.Duration) (int64, error) {
+	if !m.live(key) {
+		m.expires[key] = m.clk.Now().Add(window)
+	}
+	m.values[key]++
+	return m.values[key], nil
+}
+
+func (m *memAttempts) TTL(_ context.Context, key string) (time.Duration, error) {
+	if !m.live(key) {
+		return 0, nil
+	}
+	return m.expires[key].Sub(m.clk.Now()), nil
+}
+
+func (m *memAttempts) Set(_ context.Context, key string, ttl time.Duration) error {
+	m.values[key] = 1
+	m.expires[key] = m.clk.Now().Add(ttl)
+	return nil
+}
+
+func (m *memAttempts) Del(_ context.Context, key string) error {
+	delete(m.values, key)
+	delete(m.expires, key)
+	return nil
+}
+
+func TestLockout(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	l := NewLockout(newMemAttempts(clk), "wallet", 3, 15*time.Minute)
+	const user = "u1"
+
+	for i := 1; i <= 2; i++ {
+		locked, _ := l.Fail(ctx, user)
+		if locked {
+			t.Fatalf("locked after %d failures, want 3", i)
+		}
+	}
+	if d, _ := l.Locked(ctx, user); d != 0 {
+		t.Fatalf("Locked() = %v before reaching the threshold", d)
+	}
+
+	locked, _ := l.Fail(ctx, user)
+	if !locked {
+		t.Fatal("third failure did not lock")
+	}
+	if d, _ := l.Locked(ctx, user); d != 15*time.Minute {
+		t.Errorf("Locked() = %v, want 15m", d)
+	}
+	if d, _ := l.Locked(ctx, "u2"); d != 0 {
+		t.Errorf("other user locked: %v", d)
+	}
+
+	clk.Advance(15*time.Minute - time.Second)
+	if d, _ := l.Locked(ctx, user); d != time.Second {
+		t.Errorf("Locked() near the end = %v, want 1s", d)
+	}
+
+	clk.Advance(time.Second)
+	if d, _ := l.Locked(ctx, user); d != 0 {
+		t.Errorf("still locked after cool-down: %v", d)
+	}
+	// Counter started over: one failure does not re-lock
+	if locked, _ := l.Fail(ctx, user); locked {
+		t.Error("locked again on the first failure after cool-down")
+	}
+}
+
+func TestLockoutResetOnSuccess(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	l := NewLockout(newMemAttempts(clk), "wallet", 3, 15*time.Minute)
+
+	_, _ = l.Fail(ctx, "u1")
+	_, _ = l.Fail(ctx, "u1")
+	_ = l.Reset(ctx, "u1")
+	if locked, _ := l.Fail(ctx, "u1"); locked {
+		t.Error("failures before a success still counted")
+	}
+}
+
+func TestLockoutFailuresExpire(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	l := NewLockout(newMemAttempts(clk), "wallet", 3, 15*time.Minute)
+
+	_, _ = l.Fail(ctx, "u1")
+	_, _ = l.Fail(ctx, "u1")
+	clk.Advance(16 * time.Minute)
+	if locked, _ := l.Fail(ctx, "u1"); locked {
+		t.Error("failures outside the window still counted")
+	}
+}
+
+func TestAllow(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	store := newMemAttempts(clk)
+
+	for i := 1; i <= 3; i++ {
+		if ok, _, _ := Allow(ctx, store, "payload:u1", 3, time.Minute); !ok {
+			t.Fatalf("call %d rejected, limit is 3", i)
+		}
+	}
+	clk.Advance(20 * time.Second)
+	ok, retryAfter, _ := Allow(ctx, store, "payload:u1", 3, time.Minute)
+	if ok {
+		t.Error("4th call allowed")
+	}
+	if retryAfter != 40*time.Second {
+		t.Errorf("retryAfter = %v, want the rest of the window (40s)", retryAfter)
+	}
+	clk.Advance(40 * time.Second)
+	if ok, _, _ := Allow(ctx, store, "payload:u1", 3, time.Minute); !ok {
+		t.Error("rejected in a new window")
+	}
+	if ok, _, _ := Allow(ctx, store, "payload:u1", 0, time.Minute); !ok {
+		t.Error("limit 0 should disable the check")
+	}
+}
+
+func TestAllowRestartsWindowWithoutExpiry(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	store := newMemAttempts(clk)
+	// A counter whose expiry was never set, as after a failed EXPIRE in Redis.
+	store.values["rl:payload:u1"] = 5
+
+	ok, retryAfter, _ := Allow(ctx, store, "payload:u1", 3, time.Minute)
+	if ok || retryAfter != time.Minute {
+		t.Fatalf("Allow() = %v, %v; want rejected for a full window", ok, retryAfter)
+	}
+	clk.Advance(time.Minute)
+	if ok, _, _ := Allow(ctx, store, "payload:u1", 3, time.Minute); !ok {
+		t.Error("key stayed blocked after the restarted window")
+	}
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InternalSignatureHeader authenticates calls between the backend and the bot's internal API:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256>" keyed by INTERNAL_API_SECRET over
+// "<t>.<METHOD>.<request URI>.<body>". The timestamp is signed too, so a captured request
+// can't be replayed after InternalSignatureMaxAge, nor against another path.
+const InternalSignatureHeader = "X-Internal-Signature"
+
+// InternalSignatureMaxAge is how far a signature's timestamp may be from the bot's clock
+// (MAX_AGE_SECONDS in bot/bot/security.py, which verifies the signatures).
+const InternalSignatureMaxAge = 5 * time.Minute
+
+// SignInternalRequest returns the InternalSignatureHeader value for a request sent at ts.
+func SignInternalRequest(secret string, ts time.Time, method, requestURI string, body []byte) string {
+	t := strconv.FormatInt(ts.Unix(), 10)
+	return "t=" + t + ",v1=" + internalMAC(secret, t, method, requestURI, body)
+}
+
+func internalMAC(secret, t, method, requestURI string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t + "." + strings.ToUpper(method) + "." + requestURI + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,15 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignInternalRequestStable(t *testing.T) {
+	// Same vector as bot/bot/security.py, so both sides agree on the format
+	got := SignInternalRequest("secret", time.Unix(1700000000, 0), "get", "/internal/channels/durov/admins", nil)
+	want := "t=1700000000,v1=d906b09c4a19df11df6f2a6f343f8244a1e008da15aedb59bb9abc93a4a1cd2d"
+	if got != want {
+		t.Errorf("SignInternalRequest = %s, want %s", got, want)
+	}
+}
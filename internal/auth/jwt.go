@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -8,14 +10,21 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrTokenRevoked — токен отозван через logout.
+	ErrTokenRevoked = errors.New("token has been revoked")
+	// ErrTokenWithoutID — токен выпущен до появления jti; принимается только в legacy-режиме.
+	ErrTokenWithoutID = errors.New("token has no jti")
+)
+
 type Claims struct {
 	UserID         uuid.UUID `json:"user_id"`
 	TelegramUserID int64     `json:"telegram_user_id"`
 	jwt.RegisteredClaims
 }
 
-// GenerateJWT создаёт JWT с заданным временем жизни.
-// expiration — время жизни токена (например 24h). Если <= 0, используется 24h.
+// GenerateJWT создаёт JWT с заданным временем жизни и уникальным jti (для отзыва).
+// expiration — время жизни токена (например 15m). Если <= 0, используется 24h.
 func GenerateJWT(secret string, userID uuid.UUID, telegramUserID int64, expiration time.Duration) (string, error) {
 	if expiration <= 0 {
 		expiration = 24 * time.Hour
@@ -25,6 +34,7 @@ func GenerateJWT(secret string, userID uuid.UUID, telegramUserID int64, expirati
 		UserID:         userID,
 		TelegramUserID: telegramUserID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "ads-marketplace",
@@ -52,3 +62,25 @@ func ParseJWT(secret string, tokenStr string) (*Claims, error) {
 	}
 	return claims, nil
 }
+
+// ValidateAccessToken разбирает токен и проверяет, что он не отозван. Токены без jti
+// (выпущенные до refresh-токенов) принимаются только при allowLegacy.
+// Ошибка denylist пропускает проверку (fail open), как и остальные лимиты на Redis.
+func ValidateAccessToken(ctx context.Context, secret, tokenStr string, denylist TokenDenylist, allowLegacy bool) (*Claims, error) {
+	claims, err := ParseJWT(secret, tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	if claims.ID == "" {
+		if !allowLegacy {
+			return nil, ErrTokenWithoutID
+		}
+		return claims, nil
+	}
+	if denylist != nil {
+		if revoked, err := denylist.IsRevoked(ctx, claims.ID); err == nil && revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+	return claims, nil
+}
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+type memDenylist struct {
+	revoked map[string]bool
+	err     error
+}
+
+func (d *memDenylist) Revoke(_ context.Context, jti string, _ time.Time) error {
+	d.revoked[jti] = true
+	return nil
+}
+
+func (d *memDenylist) IsRevoked(_ context.Context, jti string) (bool, error) {
+	return d.revoked[jti], d.err
+}
+
+func TestGenerateJWTHasUniqueID(t *testing.T) {
+	userID := uuid.New()
+	a, err := GenerateJWT("secret", userID, 42, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := GenerateJWT("secret", userID, 42, time.Minute)
+
+	ca, err := ParseJWT("secret", a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb, _ := ParseJWT("secret", b)
+	if ca.ID == "" || ca.ID == cb.ID {
+		t.Errorf("jti must be set and unique, got %q and %q", ca.ID, cb.ID)
+	}
+	if ca.UserID != userID || ca.TelegramUserID != 42 {
+		t.Errorf("claims = %+v", ca)
+	}
+}
+
+func TestValidateAccessToken(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	token, _ := GenerateJWT("secret", userID, 1, time.Minute)
+	claims, _ := ParseJWT("secret", token)
+
+	// Token issued before jti existed
+	legacy, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte("secret"))
+
+	expired, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+		},
+	}).SignedString([]byte("secret"))
+
+	tests := []struct {
+		name        string
+		token       string
+		secret      string
+		denylist    *memDenylist
+		allowLegacy bool
+		wantErr     error // nil = valid; errAny = any error
+	}{
+		{"valid", token, "secret", &memDenylist{revoked: map[string]bool{}}, false, nil},
+		{"no denylist", token, "secret", nil, false, nil},
+		{"revoked", token, "secret", &memDenylist{revoked: map[string]bool{claims.ID: true}}, false, ErrTokenRevoked},
+		{"denylist down fails open", token, "secret", &memDenylist{revoked: map[string]bool{claims.ID: true}, err: errors.New("redis down")}, false, nil},
+		{"wrong secret", token, "other", nil, false, errAny},
+		{"expired", expired, "secret", nil, true, errAny},
+		{"legacy allowed", legacy, "secret", nil, true, nil},
+		{"legacy rejected", legacy, "secret", nil, false, ErrTokenWithoutID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dl TokenDenylist
+			if tt.denylist != nil {
+				dl = tt.denylist
+			}
+			got, err := ValidateAccessToken(ctx, tt.secret, tt.token, dl, tt.allowLegacy)
+			switch {
+			case tt.wantErr == nil:
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got.UserID != userID {
+					t.Errorf("user_id = %s, want %s", got.UserID, userID)
+				}
+			case tt.wantErr == errAny:
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+			default:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+var errAny = errors.New("any error")
+
+func TestHashRefreshToken(t *testing.T) {
+	a, err := NewRefreshToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewRefreshToken()
+	if a == b || len(a) < 43 {
+		t.Errorf("refresh tokens must be random and 256-bit, got %q and %q", a, b)
+	}
+	if HashRefreshToken(a) != HashRefreshToken(a) || HashRefreshToken(a) == HashRefreshToken(b) {
+		t.Error("hash must be deterministic and distinct per token")
+	}
+	// sha256("abc")
+	if got := HashRefreshToken("abc"); got != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("HashRefreshToken(abc) = %s", got)
+	}
+}
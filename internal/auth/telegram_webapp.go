@@ -24,6 +24,11 @@ const (
 //
 // maxAge — максимально допустимый возраст auth_date. Если <= 0, используется DefaultInitDataTTL.
 func ValidateTelegramWebAppData(initData string, botToken string, maxAge time.Duration) (url.Values, error) {
+	return ValidateTelegramWebAppDataAt(initData, botToken, maxAge, time.Now())
+}
+
+// ValidateTelegramWebAppDataAt — то же самое, но свежесть auth_date проверяется относительно now.
+func ValidateTelegramWebAppDataAt(initData string, botToken string, maxAge time.Duration, now time.Time) (url.Values, error) {
 	if maxAge <= 0 {
 		maxAge = DefaultInitDataTTL
 	}
@@ -48,11 +53,11 @@ func ValidateTelegramWebAppData(initData string, botToken string, maxAge time.Du
 		return nil, fmt.Errorf("auth_date is not a valid unix timestamp")
 	}
 	authDate := time.Unix(authDateUnix, 0)
-	if time.Since(authDate) > maxAge {
-		return nil, fmt.Errorf("initData expired: auth_date is %s old (max %s)", time.Since(authDate).Round(time.Second), maxAge)
+	if age := now.Sub(authDate); age > maxAge {
+		return nil, fmt.Errorf("initData expired: auth_date is %s old (max %s)", age.Round(time.Second), maxAge)
 	}
 	// Защита от auth_date из будущего (clock skew макс. 1 мин)
-	if authDate.After(time.Now().Add(1 * time.Minute)) {
+	if authDate.After(now.Add(1 * time.Minute)) {
 		return nil, fmt.Errorf("auth_date is in the future")
 	}
 
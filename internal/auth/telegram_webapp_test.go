@@ -11,6 +11,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/ads-marketplace/backend/internal/clock"
 )
 
 // helper: собирает initData с валидным hash и заданным auth_date
@@ -150,3 +152,33 @@ func TestHmacSHA256(t *testing.T) {
 		t.Error("hmacSHA256 result doesn't match expected")
 	}
 }
+
+func TestValidateTelegramWebAppDataAt_FreshnessBoundaries(t *testing.T) {
+	botToken := "test-bot-token-12345"
+	authDate := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	initData := buildInitData(botToken, authDate, nil)
+	maxAge := 5 * time.Minute
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		wantErr bool
+	}{
+		{"at auth_date", 0, false},
+		{"exactly max age", maxAge, false},
+		{"one second past max age", maxAge + time.Second, true},
+		{"our clock behind within skew", -time.Minute, false},
+		{"our clock behind beyond skew", -time.Minute - time.Second, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewFake(authDate)
+			clk.Advance(tt.advance)
+			_, err := ValidateTelegramWebAppDataAt(initData, botToken, maxAge, clk.Now())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRefreshToken возвращает случайный refresh-токен; в БД хранится только HashRefreshToken.
+func NewRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashRefreshToken — sha256 в hex. Токен случайный (256 бит), соль не нужна.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// TokenDenylist хранит jti отозванных access-токенов до их истечения (Redis в production).
+type TokenDenylist interface {
+	Revoke(ctx context.Context, jti string, until time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// RedisTokenDenylist implements TokenDenylist on Redis: jwt:revoked:<jti> lives until the
+// token would have expired anyway.
+type RedisTokenDenylist struct {
+	rdb *redis.Client
+}
+
+func NewRedisTokenDenylist(rdb *redis.Client) *RedisTokenDenylist {
+	return &RedisTokenDenylist{rdb: rdb}
+}
+
+func (d *RedisTokenDenylist) Revoke(ctx context.Context, jti string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil // already expired
+	}
+	return d.rdb.Set(ctx, "jwt:revoked:"+jti, 1, ttl).Err()
+}
+
+func (d *RedisTokenDenylist) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := d.rdb.Exists(ctx, "jwt:revoked:"+jti).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -2,13 +2,16 @@ package db
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
 
-func NewPostgresPool(ctx context.Context, dsn string, log *zap.Logger) (*pgxpool.Pool, error) {
+// NewPostgresPool creates the pool. A positive statementTimeout is set as the server-side
+// statement_timeout, so a runaway query is cancelled by Postgres even if the caller's ctx has no deadline.
+func NewPostgresPool(ctx context.Context, dsn string, statementTimeout time.Duration, log *zap.Logger) (*pgxpool.Pool, error) {
 	cfg, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, err
@@ -18,6 +21,9 @@ func NewPostgresPool(ctx context.Context, dsn string, log *zap.Logger) (*pgxpool
 	cfg.MinConns = 2
 	cfg.MaxConnLifetime = 30 * time.Minute
 	cfg.MaxConnIdleTime = 5 * time.Minute
+	if statementTimeout > 0 {
+		cfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(statementTimeout.Milliseconds(), 10)
+	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
@@ -29,6 +35,6 @@ func NewPostgresPool(ctx context.Context, dsn string, log *zap.Logger) (*pgxpool
 		return nil, err
 	}
 
-	log.Info("postgres pool created", zap.Int32("max_conns", cfg.MaxConns))
+	log.Info("postgres pool created", zap.Int32("max_conns", cfg.MaxConns), zap.Duration("statement_timeout", statementTimeout))
 	return pool, nil
 }
@@ -0,0 +1,31 @@
+package events
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ParticipantsFunc returns the users involved in a deal: the advertiser and the
+// members of the deal's channel.
+type ParticipantsFunc func(ctx context.Context, dealID uuid.UUID) ([]uuid.UUID, error)
+
+// Audience returns the users an event may be delivered to over WebSocket. Events about a
+// deal (deal_id) go to the deal's participants; other events go only to the user they
+// name (user_id). An event naming neither reaches nobody.
+func Audience(ctx context.Context, ev Event, participants ParticipantsFunc) ([]uuid.UUID, error) {
+	var subject struct {
+		DealID string `json:"deal_id"`
+		UserID string `json:"user_id"`
+	}
+	if err := ev.Decode(&subject); err != nil {
+		return nil, nil
+	}
+	if dealID, err := uuid.Parse(subject.DealID); err == nil {
+		return participants(ctx, dealID)
+	}
+	if userID, err := uuid.Parse(subject.UserID); err == nil {
+		return []uuid.UUID{userID}, nil
+	}
+	return nil, nil
+}
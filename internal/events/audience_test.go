@@ -0,0 +1,76 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAudience(t *testing.T) {
+	dealID := uuid.MustParse("6f1c2b9e-0d4a-4c1e-9a57-1f2e3d4c5b6a")
+	advertiser := uuid.MustParse("0a1b2c3d-0000-4000-8000-000000000001")
+	owner := uuid.MustParse("0a1b2c3d-0000-4000-8000-000000000002")
+	outsider := uuid.MustParse("0a1b2c3d-0000-4000-8000-000000000003")
+
+	participants := func(_ context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+		if id != dealID {
+			return nil, errors.New("unknown deal")
+		}
+		return []uuid.UUID{advertiser, owner}, nil
+	}
+
+	tests := []struct {
+		name     string
+		event    Event
+		expected []uuid.UUID
+	}{
+		{
+			name:     "deal event goes to the deal's participants",
+			event:    New(EventDealStatusChanged, DealStatusChangedPayload{DealID: dealID.String(), OldStatus: "funded", NewStatus: "approved"}),
+			expected: []uuid.UUID{advertiser, owner},
+		},
+		{
+			name:     "user event goes to the named user",
+			event:    New(EventManagerRemoved, ManagerRemovedPayload{ChannelID: uuid.NewString(), UserID: owner.String()}),
+			expected: []uuid.UUID{owner},
+		},
+		{
+			name:     "event naming nobody reaches nobody",
+			event:    New(EventBotNotification, Notification{TelegramUserID: 42, Text: "hi"}),
+			expected: nil,
+		},
+		{
+			name:     "malformed deal id reaches nobody",
+			event:    New(EventDealStatusChanged, DealStatusChangedPayload{DealID: "not-a-uuid"}),
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Audience(context.Background(), tt.event, participants)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+			for _, id := range got {
+				if id == outsider {
+					t.Errorf("outsider must not receive %s", tt.event.Type)
+				}
+			}
+		})
+	}
+}
+
+func TestAudienceLookupError(t *testing.T) {
+	failing := func(context.Context, uuid.UUID) ([]uuid.UUID, error) { return nil, errors.New("db down") }
+	ev := New(EventDealStatusChanged, DealStatusChangedPayload{DealID: uuid.NewString()})
+	if _, err := Audience(context.Background(), ev, failing); err == nil {
+		t.Fatal("expected lookup error to propagate")
+	}
+}
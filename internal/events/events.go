@@ -1,17 +1,63 @@
 package events
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+)
 
 // Event types
 const (
 	EventDealStatusChanged = "deal_status_changed"
 	EventBotNotification   = "bot_notification"
 	EventPaymentReceived   = "payment_received"
+	EventOverpayment       = "overpayment"
+	EventCounteroffer      = "counteroffer"
+	EventManagerRemoved    = "manager_removed"
+	EventReleaseFailed     = "release_failed"
+	EventRefunded          = "refunded"
 )
 
+// Event is the envelope published on the Redis streams and sent over WS. Payload holds
+// the JSON of the typed payload for Type (see New and Decode).
 type Event struct {
-	Type    string         `json:"type"`
-	Payload map[string]any `json:"payload"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// New wraps a typed payload into an Event. Payloads are plain structs of strings and
+// numbers, so marshalling can't fail.
+func New(eventType string, payload any) Event {
+	data, _ := json.Marshal(payload)
+	return Event{Type: eventType, Payload: data}
+}
+
+// Decode unmarshals the event payload into dst, typically the payload struct for e.Type.
+func (e Event) Decode(dst any) error {
+	if len(e.Payload) == 0 {
+		return nil
+	}
+	return json.Unmarshal(e.Payload, dst)
+}
+
+// Notification is the part of a payload the bot bridge delivers to Telegram. Events
+// without a telegram user id are WS-only.
+type Notification struct {
+	TelegramUserID int64  `json:"telegram_user_id,omitempty"`
+	Text           string `json:"text,omitempty"`
+}
+
+// DealStatusChangedPayload is the payload of EventDealStatusChanged.
+type DealStatusChangedPayload struct {
+	DealID    string `json:"deal_id"`
+	OldStatus string `json:"old_status"`
+	NewStatus string `json:"new_status"`
+}
+
+// ReleaseFailedPayload is the payload of EventReleaseFailed.
+type ReleaseFailedPayload struct {
+	DealID   string `json:"deal_id"`
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error"`
 }
 
 type Publisher interface {
@@ -0,0 +1,51 @@
+package events
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// assertPayload checks the event's wire JSON against the expected object, so the payload
+// shape stays what WS clients and the bot bridge already parse.
+func assertPayload(t *testing.T, ev Event, expected map[string]any) {
+	t.Helper()
+	var got map[string]any
+	if err := json.Unmarshal(ev.Payload, &got); err != nil {
+		t.Fatalf("payload is not a JSON object: %v", err)
+	}
+	data, _ := json.Marshal(expected)
+	var want map[string]any
+	_ = json.Unmarshal(data, &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Payload = %s, want %s", ev.Payload, data)
+	}
+}
+
+func TestEventDecode(t *testing.T) {
+	in := DealStatusChangedPayload{DealID: "d1", OldStatus: "funded", NewStatus: "creative_pending"}
+	data, err := json.Marshal(New(EventDealStatusChanged, in))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ev Event
+	if err := json.Unmarshal(data, &ev); err != nil {
+		t.Fatal(err)
+	}
+	var out DealStatusChangedPayload
+	if err := ev.Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != EventDealStatusChanged || out != in {
+		t.Errorf("round trip = %q %+v, want %q %+v", ev.Type, out, EventDealStatusChanged, in)
+	}
+
+	var n Notification
+	if err := New(EventRefunded, RefundedPayload{DealID: "d1", Notification: Notification{TelegramUserID: 42, Text: "hi"}}).Decode(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n.TelegramUserID != 42 || n.Text != "hi" {
+		t.Errorf("Notification = %+v, want telegram_user_id 42 and text", n)
+	}
+}
@@ -0,0 +1,31 @@
+package events
+
+// ManagerRemoved describes a manager losing access to a channel.
+type ManagerRemoved struct {
+	ChannelID       string
+	ChannelUsername string
+	UserID          string
+	// The removed manager; 0 if unknown — then only WS clients get the event.
+	TelegramID int64
+	Language   string // the manager's language_code for the bot text
+}
+
+// ManagerRemovedPayload is the payload of EventManagerRemoved.
+type ManagerRemovedPayload struct {
+	ChannelID string `json:"channel_id"`
+	UserID    string `json:"user_id"`
+	Notification
+}
+
+// NewManagerRemovedEvent builds EventManagerRemoved. With a telegram id set, the bot bridge
+// tells the removed manager.
+func NewManagerRemovedEvent(m ManagerRemoved) Event {
+	payload := ManagerRemovedPayload{ChannelID: m.ChannelID, UserID: m.UserID}
+	if m.TelegramID != 0 {
+		payload.Notification = Notification{
+			TelegramUserID: m.TelegramID,
+			Text:           Message(m.Language, MsgManagerRemoved, m.ChannelUsername),
+		}
+	}
+	return New(EventManagerRemoved, payload)
+}
@@ -0,0 +1,43 @@
+package events
+
+import "testing"
+
+func TestNewManagerRemovedEvent(t *testing.T) {
+	const channelID = "6f1c2b9e-0d4a-4c1e-9a57-1f2e3d4c5b6a"
+	const userID = "0a1b2c3d-0000-4000-8000-000000000001"
+
+	tests := []struct {
+		name     string
+		in       ManagerRemoved
+		expected map[string]any
+	}{
+		{
+			name: "notifies the removed manager",
+			in:   ManagerRemoved{ChannelID: channelID, ChannelUsername: "cryptonews", UserID: userID, TelegramID: 42},
+			expected: map[string]any{
+				"channel_id":       channelID,
+				"user_id":          userID,
+				"telegram_user_id": int64(42),
+				"text":             "You are no longer a manager of @cryptonews.",
+			},
+		},
+		{
+			name: "unknown telegram id, WS only",
+			in:   ManagerRemoved{ChannelID: channelID, ChannelUsername: "cryptonews", UserID: userID},
+			expected: map[string]any{
+				"channel_id": channelID,
+				"user_id":    userID,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev := NewManagerRemovedEvent(tt.in)
+			if ev.Type != EventManagerRemoved {
+				t.Errorf("Type = %q, want %q", ev.Type, EventManagerRemoved)
+			}
+			assertPayload(t, ev, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultLanguage is used for users without a language_code or with one we have no texts for.
+const DefaultLanguage = "en"
+
+// Message keys: event types, with a ".<variant>" suffix where an event has several texts.
+const (
+	MsgPaymentReceived       = EventPaymentReceived
+	MsgOverpaymentRefund     = EventOverpayment + ".refund"
+	MsgOverpaymentSupport    = EventOverpayment + ".support"
+	MsgRefunded              = EventRefunded
+	MsgCounterofferPending   = EventCounteroffer + ".pending"
+	MsgCounterofferAccepted  = EventCounteroffer + ".accepted"
+	MsgCounterofferRejected  = EventCounteroffer + ".rejected"
+	MsgManagerRemoved        = EventManagerRemoved
+	MsgDealStatusChangedBase = EventDealStatusChanged + "."
+)
+
+// messages holds the bot notification templates by key and language. Every key has an
+// English text; the other languages take the same arguments in the same order.
+var messages = map[string]map[string]string{
+	MsgPaymentReceived: {
+		"en": "Payment received: %s %s. Deal %s is funded.",
+		"ru": "Оплата получена: %s %s. Сделка %s оплачена.",
+		"uk": "Оплату отримано: %s %s. Угоду %s оплачено.",
+	},
+	MsgOverpaymentRefund: {
+		"en": "You sent %s %s more than required for deal %s. The excess will be refunded to the sending wallet.",
+		"ru": "Вы отправили на %s %s больше, чем нужно для сделки %s. Излишек вернётся на кошелёк отправителя.",
+		"uk": "Ви надіслали на %s %s більше, ніж потрібно для угоди %s. Надлишок повернеться на гаманець відправника.",
+	},
+	MsgOverpaymentSupport: {
+		"en": "You sent %s %s more than required for deal %s. Support will return the excess.",
+		"ru": "Вы отправили на %s %s больше, чем нужно для сделки %s. Поддержка вернёт излишек.",
+		"uk": "Ви надіслали на %s %s більше, ніж потрібно для угоди %s. Підтримка поверне надлишок.",
+	},
+	MsgRefunded: {
+		"en": "Deal %s was refunded: %s %s sent back to %s.",
+		"ru": "По сделке %s сделан возврат: %s %s отправлено обратно на %s.",
+		"uk": "За угодою %s зроблено повернення: %s %s надіслано назад на %s.",
+	},
+	MsgCounterofferPending: {
+		"en": "The channel proposed %s %s for deal %s. Accept or reject it in the app.",
+		"ru": "Канал предложил %s %s по сделке %s. Примите или отклоните предложение в приложении.",
+		"uk": "Канал запропонував %s %s за угодою %s. Прийміть або відхиліть пропозицію в застосунку.",
+	},
+	MsgCounterofferAccepted: {
+		"en": "The advertiser accepted your price of %s %s for deal %s.",
+		"ru": "Рекламодатель принял вашу цену %s %s по сделке %s.",
+		"uk": "Рекламодавець прийняв вашу ціну %s %s за угодою %s.",
+	},
+	MsgCounterofferRejected: {
+		"en": "The advertiser rejected your price of %s %s for deal %s.",
+		"ru": "Рекламодатель отклонил вашу цену %s %s по сделке %s.",
+		"uk": "Рекламодавець відхилив вашу ціну %s %s за угодою %s.",
+	},
+	MsgManagerRemoved: {
+		"en": "You are no longer a manager of @%s.",
+		"ru": "Вы больше не менеджер канала @%s.",
+		"uk": "Ви більше не менеджер каналу @%s.",
+	},
+
+	// Deal status changes; the only argument is the short deal id
+	MsgDealStatusChangedBase + "submitted": {
+		"en": "New ad request %s is waiting for your decision.",
+		"ru": "Новая заявка на рекламу %s ждёт вашего решения.",
+		"uk": "Нова заявка на рекламу %s чекає на ваше рішення.",
+	},
+	MsgDealStatusChangedBase + "accepted": {
+		"en": "Deal %s was accepted by the channel. Pay the escrow to continue.",
+		"ru": "Канал принял сделку %s. Оплатите эскроу, чтобы продолжить.",
+		"uk": "Канал прийняв угоду %s. Оплатіть ескроу, щоб продовжити.",
+	},
+	MsgDealStatusChangedBase + "rejected": {
+		"en": "Deal %s was rejected by the channel.",
+		"ru": "Канал отклонил сделку %s.",
+		"uk": "Канал відхилив угоду %s.",
+	},
+	MsgDealStatusChangedBase + "funded": {
+		"en": "Deal %s is funded. Submit the creative for approval.",
+		"ru": "Сделка %s оплачена. Отправьте креатив на согласование.",
+		"uk": "Угоду %s оплачено. Надішліть креатив на погодження.",
+	},
+	MsgDealStatusChangedBase + "creative_submitted": {
+		"en": "The creative for deal %s is ready for your review.",
+		"ru": "Креатив по сделке %s готов к проверке.",
+		"uk": "Креатив за угодою %s готовий до перевірки.",
+	},
+	MsgDealStatusChangedBase + "creative_changes_requested": {
+		"en": "The advertiser requested changes to the creative for deal %s.",
+		"ru": "Рекламодатель запросил правки креатива по сделке %s.",
+		"uk": "Рекламодавець попросив змінити креатив за угодою %s.",
+	},
+	MsgDealStatusChangedBase + "creative_approved": {
+		"en": "The creative for deal %s was approved. Schedule the post.",
+		"ru": "Креатив по сделке %s одобрен. Запланируйте публикацию.",
+		"uk": "Креатив за угодою %s схвалено. Заплануйте публікацію.",
+	},
+	MsgDealStatusChangedBase + "posted": {
+		"en": "The ad for deal %s was posted.",
+		"ru": "Реклама по сделке %s опубликована.",
+		"uk": "Рекламу за угодою %s опубліковано.",
+	},
+	MsgDealStatusChangedBase + "disputed": {
+		"en": "Deal %s is disputed; support will review it.",
+		"ru": "По сделке %s открыт спор; поддержка его рассмотрит.",
+		"uk": "За угодою %s відкрито спір; підтримка його розгляне.",
+	},
+	MsgDealStatusChangedBase + "completed": {
+		"en": "Deal %s is completed.",
+		"ru": "Сделка %s завершена.",
+		"uk": "Угоду %s завершено.",
+	},
+	MsgDealStatusChangedBase + "refunded": {
+		"en": "Deal %s was refunded to the advertiser.",
+		"ru": "Средства по сделке %s возвращены рекламодателю.",
+		"uk": "Кошти за угодою %s повернуто рекламодавцю.",
+	},
+	MsgDealStatusChangedBase + "cancelled": {
+		"en": "Deal %s was cancelled.",
+		"ru": "Сделка %s отменена.",
+		"uk": "Угоду %s скасовано.",
+	},
+}
+
+// Language maps a Telegram language_code ("ru", "pt-br", "UK") to a language of the
+// catalog, falling back to DefaultLanguage.
+func Language(code string) string {
+	code = strings.ToLower(code)
+	if i := strings.IndexByte(code, '-'); i > 0 {
+		code = code[:i]
+	}
+	if _, ok := messages[MsgPaymentReceived][code]; ok {
+		return code
+	}
+	return DefaultLanguage
+}
+
+// HasMessage reports whether the catalog has a text for key.
+func HasMessage(key string) bool {
+	_, ok := messages[key]
+	return ok
+}
+
+// Message renders the text for key in the user's language, falling back to English.
+func Message(languageCode, key string, args ...any) string {
+	texts := messages[key]
+	tmpl, ok := texts[Language(languageCode)]
+	if !ok {
+		tmpl = texts[DefaultLanguage]
+	}
+	return fmt.Sprintf(tmpl, args...)
+}
@@ -0,0 +1,63 @@
+package events
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLanguage(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{"ru", "ru"},
+		{"uk", "uk"},
+		{"en", "en"},
+		{"RU", "ru"},
+		{"uk-UA", "uk"},
+		{"pt-br", DefaultLanguage},
+		{"de", DefaultLanguage},
+		{"", DefaultLanguage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := Language(tt.code); got != tt.expected {
+				t.Errorf("Language(%q) = %q, want %q", tt.code, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMessageCatalog(t *testing.T) {
+	for key, texts := range messages {
+		en, ok := texts[DefaultLanguage]
+		if !ok {
+			t.Errorf("%s: no English text", key)
+			continue
+		}
+		for lang, text := range texts {
+			if strings.Count(text, "%s") != strings.Count(en, "%s") {
+				t.Errorf("%s/%s: %q takes other arguments than %q", key, lang, text, en)
+			}
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	if got, want := Message("ru", MsgManagerRemoved, "cryptonews"), "Вы больше не менеджер канала @cryptonews."; got != want {
+		t.Errorf("ru: got %q, want %q", got, want)
+	}
+	if got, want := Message("de", MsgManagerRemoved, "cryptonews"), "You are no longer a manager of @cryptonews."; got != want {
+		t.Errorf("fallback: got %q, want %q", got, want)
+	}
+
+	ev := NewRefundedEvent(Refunded{DealID: "6f1c2b9e-0d4a", AdvertiserTelegramID: 42, Amount: "5", To: "EQpayer", TxHash: "abc", Language: "uk"})
+	var n Notification
+	if err := ev.Decode(&n); err != nil {
+		t.Fatal(err)
+	}
+	if want := "За угодою 6f1c2b9e зроблено повернення: 5 TON надіслано назад на EQpayer."; n.Text != want {
+		t.Errorf("refund text = %q, want %q", n.Text, want)
+	}
+}
@@ -0,0 +1,49 @@
+package events
+
+// Counteroffer describes a price proposal on a submitted deal, or the advertiser's answer to it.
+type Counteroffer struct {
+	DealID         string
+	CounterofferID string
+	PriceTON       string // proposed price in Currency units
+	Currency       string // TON / USDT; empty means TON
+	Status         string // pending / accepted / rejected
+	// The other party: the advertiser for a new proposal, the channel owner for an answer.
+	// 0 if unknown — then only WS clients get the event.
+	NotifyTelegramID int64
+	NotifyLanguage   string // the other party's language_code for the bot text
+}
+
+// CounterofferPayload is the payload of EventCounteroffer.
+type CounterofferPayload struct {
+	DealID         string `json:"deal_id"`
+	CounterofferID string `json:"counteroffer_id"`
+	PriceTON       string `json:"price_ton"`
+	Currency       string `json:"currency,omitempty"`
+	Status         string `json:"status"`
+	Notification
+}
+
+// NewCounterofferEvent builds EventCounteroffer. With a telegram id set, the bot bridge
+// delivers `text` to the other party.
+func NewCounterofferEvent(c Counteroffer) Event {
+	payload := CounterofferPayload{
+		DealID:         c.DealID,
+		CounterofferID: c.CounterofferID,
+		PriceTON:       c.PriceTON,
+		Currency:       c.Currency,
+		Status:         c.Status,
+	}
+	if c.NotifyTelegramID != 0 {
+		currency := currencyOrTON(c.Currency)
+		key := MsgCounterofferPending
+		switch c.Status {
+		case "accepted":
+			key = MsgCounterofferAccepted
+		case "rejected":
+			key = MsgCounterofferRejected
+		}
+		text := Message(c.NotifyLanguage, key, c.PriceTON, currency, shortID(c.DealID))
+		payload.Notification = Notification{TelegramUserID: c.NotifyTelegramID, Text: text}
+	}
+	return New(EventCounteroffer, payload)
+}
@@ -0,0 +1,72 @@
+package events
+
+import "testing"
+
+func TestNewCounterofferEvent(t *testing.T) {
+	const dealID = "6f1c2b9e-0d4a-4c1e-9a57-1f2e3d4c5b6a"
+	const offerID = "0a1b2c3d-0000-4000-8000-000000000001"
+
+	tests := []struct {
+		name     string
+		in       Counteroffer
+		expected map[string]any
+	}{
+		{
+			name: "proposal to the advertiser",
+			in:   Counteroffer{DealID: dealID, CounterofferID: offerID, PriceTON: "12.5", Status: "pending", NotifyTelegramID: 42},
+			expected: map[string]any{
+				"deal_id":          dealID,
+				"counteroffer_id":  offerID,
+				"price_ton":        "12.5",
+				"status":           "pending",
+				"telegram_user_id": int64(42),
+				"text":             "The channel proposed 12.5 TON for deal 6f1c2b9e. Accept or reject it in the app.",
+			},
+		},
+		{
+			name: "accepted, jetton deal",
+			in:   Counteroffer{DealID: dealID, CounterofferID: offerID, PriceTON: "30", Currency: "USDT", Status: "accepted", NotifyTelegramID: 7},
+			expected: map[string]any{
+				"deal_id":          dealID,
+				"counteroffer_id":  offerID,
+				"price_ton":        "30",
+				"currency":         "USDT",
+				"status":           "accepted",
+				"telegram_user_id": int64(7),
+				"text":             "The advertiser accepted your price of 30 USDT for deal 6f1c2b9e.",
+			},
+		},
+		{
+			name: "rejected",
+			in:   Counteroffer{DealID: dealID, CounterofferID: offerID, PriceTON: "9", Status: "rejected", NotifyTelegramID: 7},
+			expected: map[string]any{
+				"deal_id":          dealID,
+				"counteroffer_id":  offerID,
+				"price_ton":        "9",
+				"status":           "rejected",
+				"telegram_user_id": int64(7),
+				"text":             "The advertiser rejected your price of 9 TON for deal 6f1c2b9e.",
+			},
+		},
+		{
+			name: "recipient unknown — no bot notification",
+			in:   Counteroffer{DealID: dealID, CounterofferID: offerID, PriceTON: "5", Status: "pending"},
+			expected: map[string]any{
+				"deal_id":         dealID,
+				"counteroffer_id": offerID,
+				"price_ton":       "5",
+				"status":          "pending",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev := NewCounterofferEvent(tt.in)
+			if ev.Type != EventCounteroffer {
+				t.Errorf("Type = %q, want %q", ev.Type, EventCounteroffer)
+			}
+			assertPayload(t, ev, tt.expected)
+		})
+	}
+}
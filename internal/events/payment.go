@@ -0,0 +1,152 @@
+package events
+
+import "strings"
+
+// PaymentReceived describes a funded escrow, used to notify the payer.
+type PaymentReceived struct {
+	DealID               string
+	AdvertiserTelegramID int64  // 0 if unknown — then only WS clients get the event
+	AmountTON            string // amount in Currency units, despite the name
+	Currency             string // TON / USDT; empty means TON
+	TxLT                 uint64
+	From                 string
+	Memo                 string
+	DealURL              string // optional link to the deal in the Mini App
+	Language             string // advertiser's language_code for the bot text
+}
+
+// PaymentReceivedPayload is the payload of EventPaymentReceived.
+type PaymentReceivedPayload struct {
+	DealID    string `json:"deal_id"`
+	TxLT      uint64 `json:"tx_lt"`
+	AmountTON string `json:"amount_ton"`
+	Currency  string `json:"currency,omitempty"`
+	From      string `json:"from"`
+	Memo      string `json:"memo"`
+	DealURL   string `json:"deal_url,omitempty"`
+	Notification
+}
+
+// NewPaymentReceivedEvent builds EventPaymentReceived. With a telegram id set, the bot
+// bridge delivers `text` to the advertiser.
+func NewPaymentReceivedEvent(p PaymentReceived) Event {
+	payload := PaymentReceivedPayload{
+		DealID:    p.DealID,
+		TxLT:      p.TxLT,
+		AmountTON: p.AmountTON,
+		Currency:  p.Currency,
+		From:      p.From,
+		Memo:      p.Memo,
+		DealURL:   p.DealURL,
+	}
+	if p.AdvertiserTelegramID != 0 {
+		text := Message(p.Language, MsgPaymentReceived, p.AmountTON, currencyOrTON(p.Currency), shortID(p.DealID))
+		if p.DealURL != "" {
+			text += "\n" + p.DealURL
+		}
+		payload.Notification = Notification{TelegramUserID: p.AdvertiserTelegramID, Text: text}
+	}
+	return New(EventPaymentReceived, payload)
+}
+
+// Overpayment describes the excess of a payment above the escrow's expected amount.
+type Overpayment struct {
+	DealID               string
+	AdvertiserTelegramID int64  // 0 if unknown — then only WS clients get the event
+	Excess               string // human-readable excess in Currency units
+	ExcessUnits          string // excess in the currency's smallest units (nanoTON for TON)
+	Currency             string // TON / USDT; empty means TON
+	TxLT                 uint64
+	From                 string
+	Language             string // advertiser's language_code for the bot text
+}
+
+// OverpaymentPayload is the payload of EventOverpayment.
+type OverpaymentPayload struct {
+	DealID      string `json:"deal_id"`
+	TxLT        uint64 `json:"tx_lt"`
+	Excess      string `json:"excess"`
+	ExcessUnits string `json:"excess_units"`
+	Currency    string `json:"currency,omitempty"`
+	From        string `json:"from"`
+	Notification
+}
+
+// NewOverpaymentEvent builds EventOverpayment. TON overpayments are refunded to the payer
+// automatically; jetton ones are returned by support.
+func NewOverpaymentEvent(p Overpayment) Event {
+	payload := OverpaymentPayload{
+		DealID:      p.DealID,
+		TxLT:        p.TxLT,
+		Excess:      p.Excess,
+		ExcessUnits: p.ExcessUnits,
+		Currency:    p.Currency,
+		From:        p.From,
+	}
+	if p.AdvertiserTelegramID != 0 {
+		currency := currencyOrTON(p.Currency)
+		key := MsgOverpaymentSupport
+		if currency == "TON" {
+			key = MsgOverpaymentRefund
+		}
+		text := Message(p.Language, key, p.Excess, currency, shortID(p.DealID))
+		payload.Notification = Notification{TelegramUserID: p.AdvertiserTelegramID, Text: text}
+	}
+	return New(EventOverpayment, payload)
+}
+
+// Refunded describes an escrow returned to the payer from the hot wallet.
+type Refunded struct {
+	DealID               string
+	AdvertiserTelegramID int64  // 0 if unknown — then only WS clients get the event
+	Amount               string // in Currency units
+	Currency             string // TON / USDT; empty means TON
+	To                   string
+	TxHash               string
+	Language             string // advertiser's language_code for the bot text
+}
+
+// RefundedPayload is the payload of EventRefunded.
+type RefundedPayload struct {
+	DealID   string `json:"deal_id"`
+	Amount   string `json:"amount"`
+	Currency string `json:"currency,omitempty"`
+	To       string `json:"to"`
+	TxHash   string `json:"tx_hash"`
+	Notification
+}
+
+// NewRefundedEvent builds EventRefunded. With a telegram id set, the bot bridge delivers
+// `text` to the advertiser.
+func NewRefundedEvent(r Refunded) Event {
+	payload := RefundedPayload{
+		DealID:   r.DealID,
+		Amount:   r.Amount,
+		Currency: r.Currency,
+		To:       r.To,
+		TxHash:   r.TxHash,
+	}
+	if r.AdvertiserTelegramID != 0 {
+		payload.Notification = Notification{
+			TelegramUserID: r.AdvertiserTelegramID,
+			Text:           Message(r.Language, MsgRefunded, shortID(r.DealID), r.Amount, currencyOrTON(r.Currency), r.To),
+		}
+	}
+	return New(EventRefunded, payload)
+}
+
+// currencyOrTON returns currency, defaulting to TON when empty.
+func currencyOrTON(currency string) string {
+	if currency == "" {
+		return "TON"
+	}
+	return currency
+}
+
+// shortID trims a UUID to its first block for human-readable messages.
+func shortID(id string) string {
+	if i := strings.IndexByte(id, '-'); i > 0 {
+		return id[:i]
+	}
+	return id
+}
@@ -0,0 +1,182 @@
+package events
+
+import "testing"
+
+func TestNewPaymentReceivedEvent(t *testing.T) {
+	const dealID = "6f1c2b9e-0d4a-4c1e-9a57-1f2e3d4c5b6a"
+
+	tests := []struct {
+		name     string
+		in       PaymentReceived
+		expected map[string]any
+	}{
+		{
+			name: "payer known, with link",
+			in: PaymentReceived{
+				DealID: dealID, AdvertiserTelegramID: 42, AmountTON: "5.5", TxLT: 100,
+				From: "EQpayer", Memo: "deal:" + dealID, DealURL: "https://app.example/deals/" + dealID,
+			},
+			expected: map[string]any{
+				"deal_id":          dealID,
+				"tx_lt":            uint64(100),
+				"amount_ton":       "5.5",
+				"from":             "EQpayer",
+				"memo":             "deal:" + dealID,
+				"deal_url":         "https://app.example/deals/" + dealID,
+				"telegram_user_id": int64(42),
+				"text":             "Payment received: 5.5 TON. Deal 6f1c2b9e is funded.\nhttps://app.example/deals/" + dealID,
+			},
+		},
+		{
+			name: "payer known, no link",
+			in:   PaymentReceived{DealID: dealID, AdvertiserTelegramID: 42, AmountTON: "1", TxLT: 7, From: "EQpayer", Memo: "m"},
+			expected: map[string]any{
+				"deal_id":          dealID,
+				"tx_lt":            uint64(7),
+				"amount_ton":       "1",
+				"from":             "EQpayer",
+				"memo":             "m",
+				"telegram_user_id": int64(42),
+				"text":             "Payment received: 1 TON. Deal 6f1c2b9e is funded.",
+			},
+		},
+		{
+			name: "jetton payment",
+			in:   PaymentReceived{DealID: dealID, AdvertiserTelegramID: 42, AmountTON: "12.5", Currency: "USDT", TxLT: 9, From: "EQpayer", Memo: "m"},
+			expected: map[string]any{
+				"deal_id":          dealID,
+				"tx_lt":            uint64(9),
+				"amount_ton":       "12.5",
+				"currency":         "USDT",
+				"from":             "EQpayer",
+				"memo":             "m",
+				"telegram_user_id": int64(42),
+				"text":             "Payment received: 12.5 USDT. Deal 6f1c2b9e is funded.",
+			},
+		},
+		{
+			name: "payer unknown — no bot notification",
+			in:   PaymentReceived{DealID: dealID, AmountTON: "1", TxLT: 7, From: "EQpayer", Memo: "m"},
+			expected: map[string]any{
+				"deal_id":    dealID,
+				"tx_lt":      uint64(7),
+				"amount_ton": "1",
+				"from":       "EQpayer",
+				"memo":       "m",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev := NewPaymentReceivedEvent(tt.in)
+			if ev.Type != EventPaymentReceived {
+				t.Errorf("Type = %q, want %q", ev.Type, EventPaymentReceived)
+			}
+			assertPayload(t, ev, tt.expected)
+		})
+	}
+}
+
+func TestNewOverpaymentEvent(t *testing.T) {
+	const dealID = "6f1c2b9e-0d4a-4c1e-9a57-1f2e3d4c5b6a"
+
+	tests := []struct {
+		name     string
+		in       Overpayment
+		expected map[string]any
+	}{
+		{
+			name: "TON, payer known",
+			in:   Overpayment{DealID: dealID, AdvertiserTelegramID: 42, Excess: "0.5", ExcessUnits: "500000000", TxLT: 100, From: "EQpayer"},
+			expected: map[string]any{
+				"deal_id":          dealID,
+				"tx_lt":            uint64(100),
+				"excess":           "0.5",
+				"excess_units":     "500000000",
+				"from":             "EQpayer",
+				"telegram_user_id": int64(42),
+				"text":             "You sent 0.5 TON more than required for deal 6f1c2b9e. The excess will be refunded to the sending wallet.",
+			},
+		},
+		{
+			name: "jetton",
+			in:   Overpayment{DealID: dealID, AdvertiserTelegramID: 42, Excess: "2", ExcessUnits: "2000000", Currency: "USDT", TxLT: 9, From: "EQpayer"},
+			expected: map[string]any{
+				"deal_id":          dealID,
+				"tx_lt":            uint64(9),
+				"excess":           "2",
+				"excess_units":     "2000000",
+				"currency":         "USDT",
+				"from":             "EQpayer",
+				"telegram_user_id": int64(42),
+				"text":             "You sent 2 USDT more than required for deal 6f1c2b9e. Support will return the excess.",
+			},
+		},
+		{
+			name: "payer unknown — no bot notification",
+			in:   Overpayment{DealID: dealID, Excess: "0.1", ExcessUnits: "100000000", TxLT: 7, From: "EQpayer"},
+			expected: map[string]any{
+				"deal_id":      dealID,
+				"tx_lt":        uint64(7),
+				"excess":       "0.1",
+				"excess_units": "100000000",
+				"from":         "EQpayer",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev := NewOverpaymentEvent(tt.in)
+			if ev.Type != EventOverpayment {
+				t.Errorf("Type = %q, want %q", ev.Type, EventOverpayment)
+			}
+			assertPayload(t, ev, tt.expected)
+		})
+	}
+}
+
+func TestNewRefundedEvent(t *testing.T) {
+	const dealID = "6f1c2b9e-0d4a-4c1e-9a57-1f2e3d4c5b6a"
+
+	tests := []struct {
+		name     string
+		in       Refunded
+		expected map[string]any
+	}{
+		{
+			name: "payer known",
+			in:   Refunded{DealID: dealID, AdvertiserTelegramID: 42, Amount: "5.5", To: "EQpayer", TxHash: "abc"},
+			expected: map[string]any{
+				"deal_id":          dealID,
+				"amount":           "5.5",
+				"to":               "EQpayer",
+				"tx_hash":          "abc",
+				"telegram_user_id": int64(42),
+				"text":             "Deal 6f1c2b9e was refunded: 5.5 TON sent back to EQpayer.",
+			},
+		},
+		{
+			name: "payer unknown — no bot notification",
+			in:   Refunded{DealID: dealID, Amount: "1", Currency: "TON", To: "EQpayer", TxHash: "abc"},
+			expected: map[string]any{
+				"deal_id":  dealID,
+				"amount":   "1",
+				"currency": "TON",
+				"to":       "EQpayer",
+				"tx_hash":  "abc",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev := NewRefundedEvent(tt.in)
+			if ev.Type != EventRefunded {
+				t.Errorf("Type = %q, want %q", ev.Type, EventRefunded)
+			}
+			assertPayload(t, ev, tt.expected)
+		})
+	}
+}
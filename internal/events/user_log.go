@@ -0,0 +1,149 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// EventResyncRequired is sent instead of a replay when the client's last_seq
+// is older than what the outbox still holds — the client must refetch state.
+const EventResyncRequired = "resync_required"
+
+// ResyncRequiredPayload is the payload of EventResyncRequired.
+type ResyncRequiredPayload struct {
+	LastSeq   int64 `json:"last_seq"`
+	OldestSeq int64 `json:"oldest_seq"`
+}
+
+// SequencedEvent is an event as delivered to one user over WS.
+// Seq is monotonic per user; clients drop anything with seq <= last seen.
+type SequencedEvent struct {
+	Seq int64 `json:"seq"`
+	Event
+}
+
+// UserEventLog assigns per-user sequence numbers and keeps a bounded outbox for replay.
+type UserEventLog interface {
+	Append(ctx context.Context, userID uuid.UUID, event Event) (SequencedEvent, error)
+	// Since returns logged events with seq > afterSeq in ascending order, and the oldest seq still retained.
+	Since(ctx context.Context, userID uuid.UUID, afterSeq int64) ([]SequencedEvent, int64, error)
+}
+
+// Replay sends events missed since lastSeq. If the outbox no longer covers the gap,
+// a single resync_required event carrying the head seq is sent instead.
+// Returns the last seq delivered.
+func Replay(ctx context.Context, log UserEventLog, userID uuid.UUID, lastSeq int64, send func(SequencedEvent) error) (int64, error) {
+	missed, oldest, err := log.Since(ctx, userID, lastSeq)
+	if err != nil {
+		return lastSeq, err
+	}
+
+	if oldest > lastSeq+1 {
+		// Carry the current head seq: after refetching state the client adopts it as last seen.
+		head := oldest - 1
+		if len(missed) > 0 {
+			head = missed[len(missed)-1].Seq
+		}
+		return head, send(SequencedEvent{Seq: head, Event: New(EventResyncRequired, ResyncRequiredPayload{
+			LastSeq:   lastSeq,
+			OldestSeq: oldest,
+		})})
+	}
+
+	for _, ev := range missed {
+		if ev.Seq <= lastSeq {
+			continue
+		}
+		if err := send(ev); err != nil {
+			return lastSeq, err
+		}
+		lastSeq = ev.Seq
+	}
+	return lastSeq, nil
+}
+
+// RedisUserEventLog stores the counter in ws:seq:<user> and the outbox in a
+// sorted set ws:outbox:<user> scored by seq, capped to maxLen entries.
+type RedisUserEventLog struct {
+	client *redis.Client
+	maxLen int64
+	ttl    time.Duration
+}
+
+func NewRedisUserEventLog(client *redis.Client, maxLen int64, ttl time.Duration) *RedisUserEventLog {
+	return &RedisUserEventLog{client: client, maxLen: maxLen, ttl: ttl}
+}
+
+func seqKey(userID uuid.UUID) string    { return "ws:seq:" + userID.String() }
+func outboxKey(userID uuid.UUID) string { return "ws:outbox:" + userID.String() }
+
+func (l *RedisUserEventLog) Append(ctx context.Context, userID uuid.UUID, event Event) (SequencedEvent, error) {
+	seq, err := l.client.Incr(ctx, seqKey(userID)).Result()
+	if err != nil {
+		return SequencedEvent{}, err
+	}
+
+	se := SequencedEvent{Seq: seq, Event: event}
+	data, err := json.Marshal(se)
+	if err != nil {
+		return SequencedEvent{}, err
+	}
+
+	key := outboxKey(userID)
+	pipe := l.client.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(seq), Member: data})
+	pipe.ZRemRangeByRank(ctx, key, 0, -l.maxLen-1)
+	pipe.Expire(ctx, key, l.ttl)
+	pipe.Expire(ctx, seqKey(userID), l.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return SequencedEvent{}, err
+	}
+	return se, nil
+}
+
+func (l *RedisUserEventLog) Since(ctx context.Context, userID uuid.UUID, afterSeq int64) ([]SequencedEvent, int64, error) {
+	key := outboxKey(userID)
+
+	oldest := afterSeq + 1
+	head, err := l.client.ZRangeWithScores(ctx, key, 0, 0).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(head) > 0 {
+		oldest = int64(head[0].Score)
+	} else {
+		// Empty outbox: if the counter moved past afterSeq, those events expired.
+		cur, err := l.client.Get(ctx, seqKey(userID)).Int64()
+		if err != nil && err != redis.Nil {
+			return nil, 0, err
+		}
+		if cur > afterSeq {
+			oldest = cur + 1
+		}
+		return nil, oldest, nil
+	}
+
+	raw, err := l.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(afterSeq, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]SequencedEvent, 0, len(raw))
+	for _, r := range raw {
+		var se SequencedEvent
+		if err := json.Unmarshal([]byte(r), &se); err != nil {
+			return nil, 0, fmt.Errorf("corrupt outbox entry: %w", err)
+		}
+		out = append(out, se)
+	}
+	return out, oldest, nil
+}
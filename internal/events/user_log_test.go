@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// memUserEventLog is an in-memory UserEventLog that keeps the last maxLen events.
+type memUserEventLog struct {
+	seq    int64
+	events []SequencedEvent
+	maxLen int
+}
+
+func (l *memUserEventLog) Append(_ context.Context, _ uuid.UUID, event Event) (SequencedEvent, error) {
+	l.seq++
+	se := SequencedEvent{Seq: l.seq, Event: event}
+	l.events = append(l.events, se)
+	if len(l.events) > l.maxLen {
+		l.events = l.events[len(l.events)-l.maxLen:]
+	}
+	return se, nil
+}
+
+func (l *memUserEventLog) Since(_ context.Context, _ uuid.UUID, afterSeq int64) ([]SequencedEvent, int64, error) {
+	if len(l.events) == 0 {
+		return nil, l.seq + 1, nil
+	}
+	var out []SequencedEvent
+	for _, e := range l.events {
+		if e.Seq > afterSeq {
+			out = append(out, e)
+		}
+	}
+	return out, l.events[0].Seq, nil
+}
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	tests := []struct {
+		name         string
+		appended     int
+		maxLen       int
+		lastSeq      int64
+		expectedSeqs []int64
+		expectResync bool
+		expectedLast int64
+	}{
+		{"nothing missed", 3, 10, 3, nil, false, 0},
+		{"replays missed in order", 5, 10, 2, []int64{3, 4, 5}, false, 0},
+		{"replays everything from zero", 3, 10, 0, []int64{1, 2, 3}, false, 0},
+		{"client ahead of server", 2, 10, 7, nil, false, 0},
+		{"gap trimmed from outbox", 10, 3, 2, nil, true, 10},
+		{"gap exactly at outbox edge", 10, 3, 7, []int64{8, 9, 10}, false, 0},
+		{"empty log", 0, 10, 0, nil, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &memUserEventLog{maxLen: tt.maxLen}
+			for i := 0; i < tt.appended; i++ {
+				_, _ = log.Append(ctx, userID, Event{Type: EventDealStatusChanged})
+			}
+
+			var got []SequencedEvent
+			last, err := Replay(ctx, log, userID, tt.lastSeq, func(e SequencedEvent) error {
+				got = append(got, e)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Replay() error = %v", err)
+			}
+
+			if tt.expectResync {
+				if len(got) != 1 || got[0].Type != EventResyncRequired {
+					t.Fatalf("Replay() = %+v, want single resync_required", got)
+				}
+				if got[0].Seq != tt.expectedLast || last != tt.expectedLast {
+					t.Errorf("Replay() resync seq = %d, last = %d, want %d", got[0].Seq, last, tt.expectedLast)
+				}
+				return
+			}
+
+			if len(got) != len(tt.expectedSeqs) {
+				t.Fatalf("Replay() delivered %d events, want %d", len(got), len(tt.expectedSeqs))
+			}
+			for i, e := range got {
+				if e.Seq != tt.expectedSeqs[i] {
+					t.Errorf("event %d seq = %d, want %d", i, e.Seq, tt.expectedSeqs[i])
+				}
+			}
+			if len(tt.expectedSeqs) > 0 && last != tt.expectedSeqs[len(tt.expectedSeqs)-1] {
+				t.Errorf("Replay() last = %d, want %d", last, tt.expectedSeqs[len(tt.expectedSeqs)-1])
+			}
+		})
+	}
+}
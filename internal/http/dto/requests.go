@@ -6,6 +6,21 @@ type AuthTelegramRequest struct {
 	InitData string `json:"init_data"`
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	All          bool   `json:"all"` // revoke every session of the user
+}
+
+// UpdateMeRequest — PATCH /me; omitted fields are left unchanged.
+type UpdateMeRequest struct {
+	LanguageCode         *string `json:"language_code,omitempty"` // one of GET /meta/languages
+	NotificationsEnabled *bool   `json:"notifications_enabled,omitempty"`
+}
+
 type CreateChannelRequest struct {
 	Username string `json:"username"`
 }
@@ -14,6 +29,14 @@ type AddManagerRequest struct {
 	TelegramUserID int64 `json:"telegram_user_id"`
 }
 
+type TransferOwnershipRequest struct {
+	TelegramUserID int64 `json:"telegram_user_id"` // the new owner, must be a channel admin
+}
+
+type SetListingStatusRequest struct {
+	Status string `json:"status"` // draft / active / paused
+}
+
 type UpdateListingRequest struct {
 	Status             *string  `json:"status,omitempty"`
 	PricingJSON        any      `json:"pricing_json,omitempty"` // legacy compatibility
@@ -22,6 +45,9 @@ type UpdateListingRequest struct {
 	PriceStoryTON      *string  `json:"price_story_ton,omitempty"`
 	FormatsEnabled     []string `json:"formats_enabled,omitempty"` // ["post","repost","story"]
 	MinLeadTimeMinutes *int     `json:"min_lead_time_minutes,omitempty"`
+	MinLeadPost        *int     `json:"min_lead_post_minutes,omitempty"`
+	MinLeadRepost      *int     `json:"min_lead_repost_minutes,omitempty"`
+	MinLeadStory       *int     `json:"min_lead_story_minutes,omitempty"`
 	Description        *string  `json:"description,omitempty"`
 	Category           *string  `json:"category,omitempty"`
 	Language           *string  `json:"language,omitempty"`
@@ -29,6 +55,14 @@ type UpdateListingRequest struct {
 	HoldHoursRepost    *int     `json:"hold_hours_repost,omitempty"`
 	HoldHoursStory     *int     `json:"hold_hours_story,omitempty"`
 	AutoAccept         *bool    `json:"auto_accept,omitempty"`
+	// Owner's opt-in to let advertisers skip creative approval on their deals
+	AllowSkipCreativeApproval *bool `json:"allow_skip_creative_approval,omitempty"`
+	// Refund the advertiser if the post is edited during the hold
+	RefundOnEdit *bool `json:"refund_on_edit,omitempty"`
+}
+
+type CompareChannelsRequest struct {
+	ChannelIDs []string `json:"channel_ids"`
 }
 
 type CreateDealRequest struct {
@@ -37,13 +71,36 @@ type CreateDealRequest struct {
 	Brief       *string    `json:"brief,omitempty"`
 	PriceTON    string     `json:"price_ton,omitempty"` // если пусто — берём из листинга
 	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	// Advertiser's opt-in; takes effect only if the listing allows skipping creative approval
+	SkipCreativeApproval bool `json:"skip_creative_approval,omitempty"`
+	// Own, non-archived campaign the deal is booked for
+	CampaignID *string `json:"campaign_id,omitempty"`
+}
+
+type DealStatusesRequest struct {
+	DealIDs []string `json:"deal_ids"`
+}
+
+type CreateOfferRequest struct {
+	AdvertiserUserID *string    `json:"advertiser_user_id,omitempty"` // пусто — публичный оффер
+	AdFormat         string     `json:"ad_format"`
+	PriceTON         string     `json:"price_ton"`
+	Brief            *string    `json:"brief,omitempty"`
+	ScheduledAt      *time.Time `json:"scheduled_at,omitempty"`
+	ValidUntil       time.Time  `json:"valid_until"`
 }
 
 type SubmitCreativeRequest struct {
-	Text          string   `json:"text"`
-	RepostFromURL *string  `json:"repost_from_url,omitempty"`
-	MediaURLs     []string `json:"media_urls,omitempty"`
-	ButtonsJSON   any      `json:"buttons_json,omitempty"`
+	Text          string           `json:"text"`
+	RepostFromURL *string          `json:"repost_from_url,omitempty"`
+	MediaURLs     []string         `json:"media_urls,omitempty"`
+	ButtonsJSON   []CreativeButton `json:"buttons_json,omitempty"`
+}
+
+// CreativeButton is an inline URL button under the post.
+type CreativeButton struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
 }
 
 type RequestCreativeChangesRequest struct {
@@ -58,6 +115,27 @@ type SetWithdrawWalletRequest struct {
 	WalletAddress string `json:"wallet_address"`
 }
 
+type CounterofferRequest struct {
+	PriceTON string `json:"price_ton"`
+}
+
+type RescheduleDealRequest struct {
+	ScheduledAt *time.Time `json:"scheduled_at"`
+}
+
+type OpenDisputeRequest struct {
+	Reason string `json:"reason"`
+}
+
+type BlockUserRequest struct {
+	UserID string `json:"user_id"`
+}
+
+type RateDealRequest struct {
+	Stars   int     `json:"stars"` // 1..5
+	Comment *string `json:"comment,omitempty"`
+}
+
 // Campaigns
 
 type CreateCampaignRequest struct {
@@ -76,3 +154,37 @@ type UpdateCampaignRequest struct {
 	PreferredDate  *time.Time `json:"preferred_date,omitempty"`
 	Status         string     `json:"status,omitempty"`
 }
+
+// Admin
+
+type CreativeReviewRequest struct {
+	Reason *string `json:"reason,omitempty"`
+}
+
+type RefundAddressReviewRequest struct {
+	Reason *string `json:"reason,omitempty"`
+}
+
+type PayoutFreezeRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ForceStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+type ResolveDisputeRequest struct {
+	Outcome string  `json:"outcome"` // release / refund
+	Reason  *string `json:"reason,omitempty"`
+}
+
+type MergeChannelsRequest struct {
+	KeepID      string `json:"keep_id"`
+	DuplicateID string `json:"duplicate_id"`
+}
+
+type ManualEscrowMatchRequest struct {
+	TxHash       string `json:"tx_hash"`
+	PayerAddress string `json:"payer_address"`
+}
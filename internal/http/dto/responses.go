@@ -1,18 +1,27 @@
 package dto
 
+import "time"
+
 type AuthResponse struct {
-	Token string `json:"token"`
-	User  any    `json:"user"`
+	Token        string    `json:"token"` // access token
+	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token"`
+	User         any       `json:"user"`
 }
 
 type ErrorResponse struct {
 	Error     string `json:"error"`
+	Code      string `json:"code,omitempty"`  // stable, see apperr; Error is localized
+	Field     string `json:"field,omitempty"` // request field at fault, e.g. "buttons_json[1].url"
 	RequestID string `json:"request_id,omitempty"`
 }
 
 type SuccessResponse struct {
-	OK   bool `json:"ok"`
-	Data any  `json:"data,omitempty"`
+	OK         bool   `json:"ok"`
+	Data       any    `json:"data,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"` // paged lists: pass back as ?cursor= for the next page
+	// Non-blocking issues with an accepted request, e.g. a campaign going over budget
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 type PaymentInfoResponse struct {
@@ -20,6 +29,7 @@ type PaymentInfoResponse struct {
 	WalletAddress string `json:"wallet_address"`
 	Memo          string `json:"memo"`
 	AmountTON     string `json:"amount_ton"`
+	Currency      string `json:"currency"`
 	Status        string `json:"status"`
 }
 
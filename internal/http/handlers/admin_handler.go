@@ -0,0 +1,405 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ads-marketplace/backend/internal/http/dto"
+	"github.com/ads-marketplace/backend/internal/middleware"
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/ads-marketplace/backend/internal/repositories"
+	"github.com/ads-marketplace/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type AdminHandler struct {
+	adminService *services.AdminService
+	log          *zap.Logger
+}
+
+func NewAdminHandler(adminService *services.AdminService, log *zap.Logger) *AdminHandler {
+	return &AdminHandler{adminService: adminService, log: log}
+}
+
+// ForceRelease — POST /admin/deals/:id/force-release
+func (h *AdminHandler) ForceRelease(c *fiber.Ctx) error {
+	return h.dealAction(c, models.AdminActionForceRelease, nil)
+}
+
+// ForceRefund — POST /admin/deals/:id/force-refund
+func (h *AdminHandler) ForceRefund(c *fiber.Ctx) error {
+	return h.dealAction(c, models.AdminActionForceRefund, nil)
+}
+
+// ForceStatus — POST /admin/deals/:id/force-status {"status": "...", "reason": "..."}
+func (h *AdminHandler) ForceStatus(c *fiber.Ctx) error {
+	var req dto.ForceStatusRequest
+	if err := c.BodyParser(&req); err != nil || req.Status == "" || strings.TrimSpace(req.Reason) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "status and reason are required"})
+	}
+	if !models.IsKnownDealStatus(req.Status) {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "unknown deal status"})
+	}
+	return h.dealAction(c, models.AdminActionForceStatus, map[string]any{
+		"status": req.Status,
+		"reason": strings.TrimSpace(req.Reason),
+	})
+}
+
+// ManualEscrowMatch — POST /admin/deals/:id/escrow/match
+func (h *AdminHandler) ManualEscrowMatch(c *fiber.Ctx) error {
+	var req dto.ManualEscrowMatchRequest
+	if err := c.BodyParser(&req); err != nil || req.TxHash == "" || req.PayerAddress == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "tx_hash and payer_address are required"})
+	}
+	return h.dealAction(c, models.AdminActionManualEscrowMatch, map[string]any{
+		"tx_hash":       req.TxHash,
+		"payer_address": req.PayerAddress,
+	})
+}
+
+// ResolveDispute — POST /admin/deals/:id/resolve-dispute
+func (h *AdminHandler) ResolveDispute(c *fiber.Ctx) error {
+	var req dto.ResolveDisputeRequest
+	if err := c.BodyParser(&req); err != nil ||
+		(req.Outcome != models.DisputeOutcomeRelease && req.Outcome != models.DisputeOutcomeRefund) {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "outcome must be release or refund"})
+	}
+	params := map[string]any{"outcome": req.Outcome}
+	if req.Reason != nil {
+		params["reason"] = *req.Reason
+	}
+	return h.dealAction(c, models.AdminActionResolveDispute, params)
+}
+
+// FreezePayout — POST /admin/deals/:id/freeze
+func (h *AdminHandler) FreezePayout(c *fiber.Ctx) error {
+	return h.setPayoutFrozen(c, true)
+}
+
+// UnfreezePayout — POST /admin/deals/:id/unfreeze
+func (h *AdminHandler) UnfreezePayout(c *fiber.Ctx) error {
+	return h.setPayoutFrozen(c, false)
+}
+
+func (h *AdminHandler) setPayoutFrozen(c *fiber.Ctx, frozen bool) error {
+	dealID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid deal id"})
+	}
+	var req dto.PayoutFreezeRequest
+	if err := c.BodyParser(&req); err != nil || req.Reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "reason is required"})
+	}
+
+	adminID := middleware.GetUserID(c)
+	if err := h.adminService.SetPayoutFrozen(c.Context(), adminID, dealID, frozen, req.Reason); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
+
+// dealAction executes the action or, under the two-person policy, returns 202 with the pending action.
+func (h *AdminHandler) dealAction(c *fiber.Ctx, action string, params map[string]any) error {
+	dealID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid deal id"})
+	}
+
+	adminID := middleware.GetUserID(c)
+	pending, err := h.adminService.RequestDealAction(c.Context(), adminID, action, dealID, params)
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+	if pending != nil {
+		return c.Status(fiber.StatusAccepted).JSON(dto.SuccessResponse{OK: true, Data: pending})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
+
+// ListPendingActions — GET /admin/actions
+func (h *AdminHandler) ListPendingActions(c *fiber.Ctx) error {
+	limit, offset := 50, 0
+	if v := c.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			limit = n
+		}
+	}
+	if v := c.Query("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			offset = n
+		}
+	}
+
+	actions, err := h.adminService.ListPendingActions(c.Context(), limit, offset)
+	if err != nil {
+		h.log.Error("list pending admin actions failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: actions})
+}
+
+// ApproveAction — POST /admin/actions/:id/approve (must be a different admin than the requester)
+func (h *AdminHandler) ApproveAction(c *fiber.Ctx) error {
+	actionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid action id"})
+	}
+
+	adminID := middleware.GetUserID(c)
+	action, err := h.adminService.ApproveAction(c.Context(), actionID, adminID)
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: action})
+}
+
+// MergeChannels — POST /admin/channels/merge
+func (h *AdminHandler) MergeChannels(c *fiber.Ctx) error {
+	var req dto.MergeChannelsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request"})
+	}
+	keepID, err := uuid.Parse(req.KeepID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid keep_id"})
+	}
+	dupID, err := uuid.Parse(req.DuplicateID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid duplicate_id"})
+	}
+
+	adminID := middleware.GetUserID(c)
+	res, err := h.adminService.MergeChannels(c.Context(), adminID, keepID, dupID)
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: res})
+}
+
+// ListStatsFailures — GET /admin/channels/stats-failures?min_failures=3
+func (h *AdminHandler) ListStatsFailures(c *fiber.Ctx) error {
+	minFailures, limit, offset := 3, 50, 0
+	if v := c.Query("min_failures"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			minFailures = n
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			limit = n
+		}
+	}
+	if v := c.Query("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			offset = n
+		}
+	}
+
+	failures, err := h.adminService.ListStatsFailures(c.Context(), minFailures, limit, offset)
+	if err != nil {
+		h.log.Error("list stats failures failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: failures})
+}
+
+// QueryAudit — GET /admin/audit?actor_user_id=&action=&entity_type=&entity_id=&from=&to=&limit=&offset=
+// action is a prefix; from/to are RFC 3339 times.
+func (h *AdminHandler) QueryAudit(c *fiber.Ctx) error {
+	f := repositories.AuditFilter{Limit: c.QueryInt("limit", 50), Offset: c.QueryInt("offset", 0)}
+	if v := c.Query("actor_user_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid actor_user_id"})
+		}
+		f.ActorUserID = &id
+	}
+	if v := c.Query("entity_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid entity_id"})
+		}
+		f.EntityID = &id
+	}
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid from, expected RFC 3339"})
+		}
+		f.From = &t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid to, expected RFC 3339"})
+		}
+		f.To = &t
+	}
+	if v := c.Query("action"); v != "" {
+		f.ActionPrefix = &v
+	}
+	if v := c.Query("entity_type"); v != "" {
+		f.EntityType = &v
+	}
+
+	page, err := h.adminService.QueryAudit(c.Context(), f)
+	if err != nil {
+		h.log.Error("query audit failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: page})
+}
+
+// ListCreativesForReview — GET /admin/creatives/review
+func (h *AdminHandler) ListCreativesForReview(c *fiber.Ctx) error {
+	limit, offset := 50, 0
+	if v := c.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			limit = n
+		}
+	}
+	if v := c.Query("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			offset = n
+		}
+	}
+
+	creatives, err := h.adminService.ListCreativesForReview(c.Context(), limit, offset)
+	if err != nil {
+		h.log.Error("list creatives for review failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: creatives})
+}
+
+// ApproveCreativeReview — POST /admin/creatives/:id/approve
+func (h *AdminHandler) ApproveCreativeReview(c *fiber.Ctx) error {
+	return h.resolveCreativeReview(c, true)
+}
+
+// RejectCreativeReview — POST /admin/creatives/:id/reject
+func (h *AdminHandler) RejectCreativeReview(c *fiber.Ctx) error {
+	return h.resolveCreativeReview(c, false)
+}
+
+func (h *AdminHandler) resolveCreativeReview(c *fiber.Ctx, approve bool) error {
+	creativeID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid creative id"})
+	}
+
+	var req dto.CreativeReviewRequest
+	_ = c.BodyParser(&req)
+
+	adminID := middleware.GetUserID(c)
+	if err := h.adminService.ResolveCreativeReview(c.Context(), creativeID, adminID, approve, req.Reason); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
+
+// ListRefundAddressRequests — GET /admin/refund-address-requests
+func (h *AdminHandler) ListRefundAddressRequests(c *fiber.Ctx) error {
+	limit, offset := 50, 0
+	if v := c.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			limit = n
+		}
+	}
+	if v := c.Query("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			offset = n
+		}
+	}
+
+	reqs, err := h.adminService.ListRefundAddressRequests(c.Context(), limit, offset)
+	if err != nil {
+		h.log.Error("list refund address requests failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: reqs})
+}
+
+// ListUnmatchedPayments — GET /admin/unmatched-payments?status=unmatched
+func (h *AdminHandler) ListUnmatchedPayments(c *fiber.Ctx) error {
+	status := c.Query("status")
+	switch status {
+	case "", models.UnmatchedPaymentStatusPending, models.UnmatchedPaymentStatusUnmatched, models.UnmatchedPaymentStatusMatched:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "status must be pending, unmatched or matched"})
+	}
+	limit, offset := 50, 0
+	if v := c.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			limit = n
+		}
+	}
+	if v := c.Query("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			offset = n
+		}
+	}
+
+	payments, err := h.adminService.ListUnmatchedPayments(c.Context(), status, limit, offset)
+	if err != nil {
+		h.log.Error("list unmatched payments failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: payments})
+}
+
+// ApproveRefundAddress — POST /admin/refund-address-requests/:id/approve
+func (h *AdminHandler) ApproveRefundAddress(c *fiber.Ctx) error {
+	return h.resolveRefundAddress(c, true)
+}
+
+// RejectRefundAddress — POST /admin/refund-address-requests/:id/reject
+func (h *AdminHandler) RejectRefundAddress(c *fiber.Ctx) error {
+	return h.resolveRefundAddress(c, false)
+}
+
+func (h *AdminHandler) resolveRefundAddress(c *fiber.Ctx, approve bool) error {
+	requestID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request id"})
+	}
+
+	var req dto.RefundAddressReviewRequest
+	_ = c.BodyParser(&req)
+
+	adminID := middleware.GetUserID(c)
+	resolved, err := h.adminService.ResolveRefundAddressRequest(c.Context(), requestID, adminID, approve, req.Reason)
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: resolved})
+}
+
+// IndexerHealth — GET /admin/health/indexer; 503 if the heartbeat is stale.
+func (h *AdminHandler) IndexerHealth(c *fiber.Ctx) error {
+	health, err := h.adminService.IndexerHealth(c.Context())
+	if err != nil {
+		h.log.Error("read indexer heartbeat failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+	if !health.Alive {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(dto.SuccessResponse{OK: false, Data: health})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: health})
+}
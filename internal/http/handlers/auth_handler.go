@@ -2,23 +2,27 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 
 	"github.com/ads-marketplace/backend/internal/auth"
 	"github.com/ads-marketplace/backend/internal/config"
 	"github.com/ads-marketplace/backend/internal/http/dto"
+	"github.com/ads-marketplace/backend/internal/middleware"
 	"github.com/ads-marketplace/backend/internal/repositories"
+	"github.com/ads-marketplace/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
 
 type AuthHandler struct {
-	userRepo *repositories.UserRepo
-	cfg      *config.Config
-	log      *zap.Logger
+	userRepo    *repositories.UserRepo
+	authService *services.AuthService
+	cfg         *config.Config
+	log         *zap.Logger
 }
 
-func NewAuthHandler(userRepo *repositories.UserRepo, cfg *config.Config, log *zap.Logger) *AuthHandler {
-	return &AuthHandler{userRepo: userRepo, cfg: cfg, log: log}
+func NewAuthHandler(userRepo *repositories.UserRepo, authService *services.AuthService, cfg *config.Config, log *zap.Logger) *AuthHandler {
+	return &AuthHandler{userRepo: userRepo, authService: authService, cfg: cfg, log: log}
 }
 
 func (h *AuthHandler) TelegramAuth(c *fiber.Ctx) error {
@@ -34,7 +38,7 @@ func (h *AuthHandler) TelegramAuth(c *fiber.Ctx) error {
 	vals, err := auth.ValidateTelegramWebAppData(req.InitData, h.cfg.WebAppSecret, h.cfg.InitDataMaxAge)
 	if err != nil {
 		h.log.Debug("telegram auth validation failed", zap.Error(err))
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusUnauthorized, err)
 	}
 
 	// Parse user from initData
@@ -48,12 +52,15 @@ func (h *AuthHandler) TelegramAuth(c *fiber.Ctx) error {
 		Username  string `json:"username"`
 		FirstName string `json:"first_name"`
 		LastName  string `json:"last_name"`
+		// IETF tag of the user's Telegram client, e.g. "en" or "pt-br"
+		LanguageCode string `json:"language_code"`
+		PhotoURL     string `json:"photo_url"`
 	}
 	if err := json.Unmarshal([]byte(userJSON), &tgUser); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid user data"})
 	}
 
-	var username, firstName, lastName *string
+	var username, firstName, lastName, languageCode, photoURL *string
 	if tgUser.Username != "" {
 		username = &tgUser.Username
 	}
@@ -63,21 +70,73 @@ func (h *AuthHandler) TelegramAuth(c *fiber.Ctx) error {
 	if tgUser.LastName != "" {
 		lastName = &tgUser.LastName
 	}
+	if tgUser.LanguageCode != "" {
+		languageCode = &tgUser.LanguageCode
+	}
+	if tgUser.PhotoURL != "" {
+		photoURL = &tgUser.PhotoURL
+	}
 
-	user, err := h.userRepo.UpsertByTelegramID(c.Context(), tgUser.ID, username, firstName, lastName)
+	user, err := h.userRepo.UpsertByTelegramID(c.Context(), tgUser.ID, username, firstName, lastName, languageCode, photoURL)
 	if err != nil {
 		h.log.Error("failed to upsert user", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal server error"})
 	}
 
-	token, err := auth.GenerateJWT(h.cfg.JWTSecret, user.ID, user.TelegramUserID, h.cfg.JWTExpiration)
+	tokens, err := h.authService.IssueTokens(c.Context(), user)
+	if err != nil {
+		h.log.Error("failed to issue tokens", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal server error"})
+	}
+
+	return c.JSON(dto.AuthResponse{
+		Token:        tokens.AccessToken,
+		ExpiresAt:    tokens.ExpiresAt,
+		RefreshToken: tokens.RefreshToken,
+		User:         user,
+	})
+}
+
+// Refresh — POST /auth/refresh {"refresh_token": "..."}: a new access token and a new
+// refresh token; the old one stops working.
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var req dto.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request body"})
+	}
+
+	tokens, user, err := h.authService.Refresh(c.Context(), req.RefreshToken)
+	if errors.Is(err, services.ErrInvalidRefreshToken) {
+		return errorJSON(c, fiber.StatusUnauthorized, err)
+	}
 	if err != nil {
-		h.log.Error("failed to generate jwt", zap.Error(err))
+		h.log.Error("failed to refresh tokens", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal server error"})
 	}
 
 	return c.JSON(dto.AuthResponse{
-		Token: token,
-		User:  user,
+		Token:        tokens.AccessToken,
+		ExpiresAt:    tokens.ExpiresAt,
+		RefreshToken: tokens.RefreshToken,
+		User:         user,
 	})
 }
+
+// Logout — POST /auth/logout {"refresh_token": "...", "all": false}: revokes the access
+// token of the request and the session of refresh_token (every session with all).
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var req dto.LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request body"})
+		}
+	}
+
+	userID := middleware.GetUserID(c)
+	if err := h.authService.Logout(c.Context(), userID, middleware.GetClaims(c), req.RefreshToken, req.All); err != nil {
+		h.log.Error("logout failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal server error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
 
 	"github.com/ads-marketplace/backend/internal/http/dto"
@@ -42,7 +44,7 @@ func (h *CampaignHandler) CreateCampaign(c *fiber.Ctx) error {
 
 	userID := middleware.GetUserID(c)
 	if err := h.campaignService.Create(c.Context(), userID, campaign); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusBadRequest, err)
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse{OK: true, Data: campaign})
@@ -56,9 +58,13 @@ func (h *CampaignHandler) GetCampaign(c *fiber.Ctx) error {
 
 	userID := middleware.GetUserID(c)
 	campaign, err := h.campaignService.GetByID(c.Context(), id, userID)
-	if err != nil {
+	if errors.Is(err, repositories.ErrNotFound) || errors.Is(err, services.ErrCampaignNotFound) {
 		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "campaign not found"})
 	}
+	if err != nil {
+		h.log.Error("get campaign failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
 
 	return c.JSON(dto.SuccessResponse{OK: true, Data: campaign})
 }
@@ -80,6 +86,7 @@ func (h *CampaignHandler) ListCampaigns(c *fiber.Ctx) error {
 			filter.Offset = n
 		}
 	}
+	filter.IncludeArchived = c.QueryBool("include_archived")
 
 	campaigns, err := h.campaignService.List(c.Context(), userID, filter)
 	if err != nil {
@@ -112,13 +119,14 @@ func (h *CampaignHandler) UpdateCampaign(c *fiber.Ctx) error {
 
 	userID := middleware.GetUserID(c)
 	if err := h.campaignService.Update(c.Context(), id, userID, campaign); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusBadRequest, err)
 	}
 
 	updated, _ := h.campaignService.GetByID(c.Context(), id, userID)
 	return c.JSON(dto.SuccessResponse{OK: true, Data: updated})
 }
 
+// DeleteCampaign — DELETE /campaigns/:id; archives the campaign (see GET /campaigns?include_archived=true).
 func (h *CampaignHandler) DeleteCampaign(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
@@ -127,8 +135,88 @@ func (h *CampaignHandler) DeleteCampaign(c *fiber.Ctx) error {
 
 	userID := middleware.GetUserID(c)
 	if err := h.campaignService.Delete(c.Context(), id, userID); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusBadRequest, err)
 	}
 
 	return c.JSON(dto.SuccessResponse{OK: true})
 }
+
+// ListCampaignDeals — GET /campaigns/:id/deals; the campaign's deals with its spend
+// against the budget.
+func (h *CampaignHandler) ListCampaignDeals(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid campaign id"})
+	}
+
+	filter := repositories.DealFilter{Limit: 20}
+	if v := c.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			filter.Limit = n
+		}
+	}
+	if v := c.Query("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			filter.Offset = n
+		}
+	}
+	if v := c.Query("cursor"); v != "" {
+		cursor, err := repositories.DecodeCursor(v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid cursor"})
+		}
+		filter.Cursor = cursor
+	}
+
+	userID := middleware.GetUserID(c)
+	spend, err := h.campaignService.GetCampaignSpend(c.Context(), id, userID)
+	if errors.Is(err, repositories.ErrNotFound) || errors.Is(err, services.ErrCampaignNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "campaign not found"})
+	}
+	if err != nil {
+		h.log.Error("get campaign spend failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+	page, err := h.campaignService.ListDeals(c.Context(), id, userID, filter)
+	if err != nil {
+		h.log.Error("list campaign deals failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	var warnings []string
+	if spend.OverBudget {
+		warnings = append(warnings, fmt.Sprintf("campaign budget exceeded: %s of %s booked", spend.SpentTON, spend.BudgetTON))
+	}
+	return c.JSON(dto.SuccessResponse{
+		OK:         true,
+		Data:       fiber.Map{"deals": page.Items, "spend": spend},
+		NextCursor: page.NextCursor,
+		Warnings:   warnings,
+	})
+}
+
+func (h *CampaignHandler) SuggestChannels(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid campaign id"})
+	}
+
+	limit := 10
+	if v := c.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			limit = n
+		}
+	}
+
+	userID := middleware.GetUserID(c)
+	suggestions, err := h.campaignService.SuggestChannels(c.Context(), id, userID, limit)
+	if errors.Is(err, repositories.ErrNotFound) || errors.Is(err, services.ErrCampaignNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "campaign not found"})
+	}
+	if err != nil {
+		h.log.Error("suggest channels failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: suggestions})
+}
@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/ads-marketplace/backend/internal/http/dto"
 	"github.com/ads-marketplace/backend/internal/middleware"
@@ -27,19 +32,42 @@ func (h *ChannelHandler) CreateChannel(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request"})
 	}
-	if req.Username == "" {
+	if repositories.NormalizeUsername(req.Username) == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "username is required"})
 	}
 
 	userID := middleware.GetUserID(c)
-	ch, err := h.channelService.CreateChannel(c.Context(), req.Username, userID)
+	ch, created, err := h.channelService.CreateChannel(c.Context(), req.Username, userID)
+	if errors.Is(err, services.ErrChannelExists) {
+		return errorJSON(c, fiber.StatusConflict, err)
+	}
 	if err != nil {
-		return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{Error: err.Error()})
+		h.log.Error("create channel failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+	if !created {
+		return c.JSON(dto.SuccessResponse{OK: true, Data: ch})
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse{OK: true, Data: ch})
 }
 
+// CheckUsername reports whether @username is already registered, before the owner tries to add it.
+func (h *ChannelHandler) CheckUsername(c *fiber.Ctx) error {
+	username := c.Query("username")
+	if repositories.NormalizeUsername(username) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "username is required"})
+	}
+
+	res, err := h.channelService.CheckUsername(c.Context(), username)
+	if err != nil {
+		h.log.Error("check channel username failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: res})
+}
+
 func (h *ChannelHandler) MyChannels(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
 	channels, err := h.channelService.GetMyChannels(c.Context(), userID)
@@ -58,13 +86,35 @@ func (h *ChannelHandler) GetChannel(c *fiber.Ctx) error {
 	}
 
 	ch, err := h.channelService.GetChannel(c.Context(), id)
-	if err != nil {
+	if errors.Is(err, repositories.ErrNotFound) {
 		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "channel not found"})
 	}
+	if err != nil {
+		h.log.Error("get channel failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
 
 	return c.JSON(dto.SuccessResponse{OK: true, Data: ch})
 }
 
+func (h *ChannelHandler) GetChannelRating(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid channel id"})
+	}
+
+	summary, err := h.channelService.GetChannelRating(c.Context(), id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "channel not found"})
+	}
+	if err != nil {
+		h.log.Error("get channel rating failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: summary})
+}
+
 func (h *ChannelHandler) SearchChannels(c *fiber.Ctx) error {
 	filter := repositories.ChannelFilter{
 		Limit:  20,
@@ -96,11 +146,18 @@ func (h *ChannelHandler) SearchChannels(c *fiber.Ctx) error {
 			filter.MinAvgViews = &n
 		}
 	}
+	if v := c.Query("q"); v != "" {
+		filter.Query = &v
+	}
 	if v := c.Query("status"); v != "" {
 		filter.Status = &v
 	}
 
 	channels, err := h.channelService.SearchChannels(c.Context(), filter)
+	if repositories.IsQueryTimeout(err) {
+		h.log.Warn("search channels timed out", zap.Error(err))
+		return c.Status(fiber.StatusServiceUnavailable).JSON(dto.ErrorResponse{Error: "search timed out, please retry"})
+	}
 	if err != nil {
 		h.log.Error("search channels failed", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
@@ -116,8 +173,12 @@ func (h *ChannelHandler) InviteBot(c *fiber.Ctx) error {
 	}
 
 	instructions, err := h.channelService.GetBotInviteLink(c.Context(), id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "channel not found"})
+	}
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: err.Error()})
+		h.log.Error("get bot invite link failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
 	}
 
 	return c.JSON(dto.BotInviteResponse{Instructions: instructions})
@@ -136,7 +197,57 @@ func (h *ChannelHandler) AddManager(c *fiber.Ctx) error {
 
 	actorID := middleware.GetUserID(c)
 	if err := h.channelService.AddManager(c.Context(), channelID, actorID, req.TelegramUserID); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
+
+// RemoveManager revokes a manager's access to the channel (owner only).
+func (h *ChannelHandler) RemoveManager(c *fiber.Ctx) error {
+	channelID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid channel id"})
+	}
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid user id"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	err = h.channelService.RemoveManager(c.Context(), channelID, actorID, userID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "manager not found"})
+	}
+	if errors.Is(err, services.ErrNotChannelOwner) {
+		return errorJSON(c, fiber.StatusForbidden, err)
+	}
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
+
+// TransferOwnership hands the channel to another admin; the caller becomes a manager.
+func (h *ChannelHandler) TransferOwnership(c *fiber.Ctx) error {
+	channelID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid channel id"})
+	}
+
+	var req dto.TransferOwnershipRequest
+	if err := c.BodyParser(&req); err != nil || req.TelegramUserID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	err = h.channelService.TransferOwnership(c.Context(), channelID, actorID, req.TelegramUserID)
+	if errors.Is(err, services.ErrNotChannelOwner) {
+		return errorJSON(c, fiber.StatusForbidden, err)
+	}
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
 	}
 
 	return c.JSON(dto.SuccessResponse{OK: true})
@@ -150,7 +261,7 @@ func (h *ChannelHandler) GetAdmins(c *fiber.Ctx) error {
 
 	admins, err := h.channelService.GetAdmins(c.Context(), channelID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusInternalServerError, err)
 	}
 
 	return c.JSON(dto.SuccessResponse{OK: true, Data: admins})
@@ -182,6 +293,10 @@ func (h *ChannelHandler) UpdateListing(c *fiber.Ctx) error {
 	if req.MinLeadTimeMinutes != nil {
 		listing.MinLeadTimeMinutes = *req.MinLeadTimeMinutes
 	}
+	// Lead time по формату (nil — fallback на min_lead_time_minutes)
+	listing.MinLeadPostMinutes = req.MinLeadPost
+	listing.MinLeadRepostMinutes = req.MinLeadRepost
+	listing.MinLeadStoryMinutes = req.MinLeadStory
 	listing.Description = req.Description
 	listing.Category = req.Category
 	listing.Language = req.Language
@@ -223,10 +338,43 @@ func (h *ChannelHandler) UpdateListing(c *fiber.Ctx) error {
 	if req.AutoAccept != nil {
 		listing.AutoAccept = *req.AutoAccept
 	}
+	if req.AllowSkipCreativeApproval != nil {
+		listing.AllowSkipCreativeApproval = *req.AllowSkipCreativeApproval
+	}
+	if req.RefundOnEdit != nil {
+		listing.RefundOnEdit = *req.RefundOnEdit
+	}
 
 	actorID := middleware.GetUserID(c)
 	if err := h.channelService.UpsertListing(c.Context(), channelID, actorID, listing); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: listing})
+}
+
+// SetListingStatus pauses or resumes a listing without changing its terms.
+func (h *ChannelHandler) SetListingStatus(c *fiber.Ctx) error {
+	channelID, err := uuid.Parse(c.Params("channelId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid channel id"})
+	}
+
+	var req dto.SetListingStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	listing, err := h.channelService.SetListingStatus(c.Context(), channelID, actorID, req.Status)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "listing not found"})
+	}
+	if errors.Is(err, services.ErrNotChannelMember) {
+		return errorJSON(c, fiber.StatusForbidden, err)
+	}
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
 	}
 
 	return c.JSON(dto.SuccessResponse{OK: true, Data: listing})
@@ -239,9 +387,13 @@ func (h *ChannelHandler) GetListing(c *fiber.Ctx) error {
 	}
 
 	listing, err := h.channelService.GetListing(c.Context(), channelID)
-	if err != nil {
+	if errors.Is(err, repositories.ErrNotFound) {
 		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "listing not found"})
 	}
+	if err != nil {
+		h.log.Error("get listing failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
 
 	return c.JSON(dto.SuccessResponse{OK: true, Data: listing})
 }
@@ -253,13 +405,132 @@ func (h *ChannelHandler) GetStats(c *fiber.Ctx) error {
 	}
 
 	stats, err := h.channelService.GetChannelStats(c.Context(), channelID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "stats not found"})
+	}
 	if err != nil {
+		h.log.Error("get channel stats failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: stats})
+}
+
+// RefreshStats — POST /channels/:id/stats/refresh: fetch stats now instead of waiting for
+// the next scheduled refresh (owner only, per-channel cooldown).
+func (h *ChannelHandler) RefreshStats(c *fiber.Ctx) error {
+	channelID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid channel id"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	stats, err := h.channelService.RefreshStats(c.Context(), channelID, actorID)
+	if errors.Is(err, services.ErrNotChannelOwner) {
+		return errorJSON(c, fiber.StatusForbidden, err)
+	}
+	if errors.Is(err, services.ErrStatsRefreshCooldown) {
+		return errorJSON(c, fiber.StatusTooManyRequests, err)
+	}
+	if errors.Is(err, services.ErrStatsRefreshTimeout) {
+		return errorJSON(c, fiber.StatusGatewayTimeout, err)
+	}
+	if errors.Is(err, services.ErrStatsRefreshFailed) {
+		return errorJSON(c, fiber.StatusBadGateway, err)
+	}
+	if errors.Is(err, repositories.ErrNotFound) {
 		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "stats not found"})
 	}
+	if err != nil {
+		h.log.Error("refresh channel stats failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
 
 	return c.JSON(dto.SuccessResponse{OK: true, Data: stats})
 }
 
+// GetStatsHistory — GET /channels/:id/stats/history?days=30: the snapshot series for charts
+// (fetched_at, subscribers, avg_views, er_percent, growth), oldest first. days is capped at 365.
+func (h *ChannelHandler) GetStatsHistory(c *fiber.Ctx) error {
+	channelID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid channel id"})
+	}
+	days := 30
+	if v := c.Query("days"); v != "" {
+		if days, err = strconv.Atoi(v); err != nil || days < 1 {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid days"})
+		}
+	}
+
+	points, err := h.channelService.GetStatsHistory(c.Context(), channelID, days)
+	if err != nil {
+		h.log.Error("get stats history failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: points})
+}
+
+const (
+	statsExportDefaultRange = 90 * 24 * time.Hour
+	statsExportTimeout      = 2 * time.Minute
+)
+
+// ExportStatsHistory — GET /channels/:id/stats/history/export?format=csv&from=&to= (members only).
+// from/to accept RFC3339 or YYYY-MM-DD; default is the last 90 days. The body is streamed.
+func (h *ChannelHandler) ExportStatsHistory(c *fiber.Ctx) error {
+	channelID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid channel id"})
+	}
+	if f := c.Query("format", "csv"); f != "csv" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "unsupported format, only csv"})
+	}
+
+	to := time.Now()
+	if v := c.Query("to"); v != "" {
+		if to, err = parseExportTime(v); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid to"})
+		}
+	}
+	from := to.Add(-statsExportDefaultRange)
+	if v := c.Query("from"); v != "" {
+		if from, err = parseExportTime(v); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid from"})
+		}
+	}
+	if !from.Before(to) {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "from must be before to"})
+	}
+
+	userID := middleware.GetUserID(c)
+	if err := h.channelService.AuthorizeStatsExport(c.Context(), channelID, userID); err != nil {
+		return errorJSON(c, fiber.StatusForbidden, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="stats-%s-%s-%s.csv"`,
+		channelID, from.Format("20060102"), to.Format("20060102")))
+
+	// The writer runs after the handler returns, so it can't use the request context
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithTimeout(context.Background(), statsExportTimeout)
+		defer cancel()
+		if err := h.channelService.WriteStatsHistoryCSV(ctx, channelID, from, to, w); err != nil {
+			h.log.Error("stats history export failed", zap.String("channel_id", channelID.String()), zap.Error(err))
+		}
+	})
+	return nil
+}
+
+func parseExportTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", v)
+}
+
 func (h *ChannelHandler) ExploreChannels(c *fiber.Ctx) error {
 	filter := repositories.ChannelFilter{
 		Limit:  20,
@@ -291,6 +562,9 @@ func (h *ChannelHandler) ExploreChannels(c *fiber.Ctx) error {
 			filter.MinAvgViews = &n
 		}
 	}
+	if v := c.Query("q"); v != "" {
+		filter.Query = &v
+	}
 	if v := c.Query("status"); v != "" {
 		filter.Status = &v
 	}
@@ -300,8 +574,27 @@ func (h *ChannelHandler) ExploreChannels(c *fiber.Ctx) error {
 	if v := c.Query("language"); v != "" {
 		filter.Language = &v
 	}
+	if v := c.Query("sort"); v != "" {
+		if !repositories.IsExploreSort(v) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid sort"})
+		}
+		filter.SortBy = v
+	}
+	switch v := c.Query("dir"); v {
+	case "", repositories.SortAsc, repositories.SortDesc:
+		filter.SortDir = v
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid dir"})
+	}
+	// Channels whose members and the viewer blocked each other are hidden
+	userID := middleware.GetUserID(c)
+	filter.ViewerID = &userID
 
 	channels, err := h.channelService.ExploreChannels(c.Context(), filter)
+	if repositories.IsQueryTimeout(err) {
+		h.log.Warn("explore channels timed out", zap.Error(err))
+		return c.Status(fiber.StatusServiceUnavailable).JSON(dto.ErrorResponse{Error: "search timed out, please retry"})
+	}
 	if err != nil {
 		h.log.Error("explore channels failed", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
@@ -309,3 +602,30 @@ func (h *ChannelHandler) ExploreChannels(c *fiber.Ctx) error {
 
 	return c.JSON(dto.SuccessResponse{OK: true, Data: channels})
 }
+
+// CompareChannels returns up to 5 channels side by side with stats and terms.
+func (h *ChannelHandler) CompareChannels(c *fiber.Ctx) error {
+	var req dto.CompareChannelsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.ChannelIDs))
+	for _, raw := range req.ChannelIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid channel id: " + raw})
+		}
+		ids = append(ids, id)
+	}
+
+	channels, err := h.channelService.CompareChannels(c.Context(), ids)
+	if repositories.IsQueryTimeout(err) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(dto.ErrorResponse{Error: "search timed out, please retry"})
+	}
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: channels})
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
 
 	"github.com/ads-marketplace/backend/internal/http/dto"
 	"github.com/ads-marketplace/backend/internal/middleware"
+	"github.com/ads-marketplace/backend/internal/models"
 	"github.com/ads-marketplace/backend/internal/repositories"
 	"github.com/ads-marketplace/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
@@ -13,12 +16,13 @@ import (
 )
 
 type DealHandler struct {
-	dealService *services.DealService
-	log         *zap.Logger
+	dealService     *services.DealService
+	campaignService *services.CampaignService
+	log             *zap.Logger
 }
 
-func NewDealHandler(dealService *services.DealService, log *zap.Logger) *DealHandler {
-	return &DealHandler{dealService: dealService, log: log}
+func NewDealHandler(dealService *services.DealService, campaignService *services.CampaignService, log *zap.Logger) *DealHandler {
+	return &DealHandler{dealService: dealService, campaignService: campaignService, log: log}
 }
 
 func (h *DealHandler) CreateDeal(c *fiber.Ctx) error {
@@ -37,13 +41,57 @@ func (h *DealHandler) CreateDeal(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "ad_format is required (post, repost, story)"})
 	}
 
+	var campaignID *uuid.UUID
+	if req.CampaignID != nil {
+		id, err := uuid.Parse(*req.CampaignID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid campaign_id"})
+		}
+		campaignID = &id
+	}
+
 	actorID := middleware.GetUserID(c)
-	deal, err := h.dealService.CreateDeal(c.Context(), actorID, channelID, req.AdFormat, req.Brief, req.PriceTON, req.ScheduledAt)
+	deal, err := h.dealService.CreateDeal(c.Context(), actorID, channelID, req.AdFormat, req.Brief, req.PriceTON, req.ScheduledAt, req.SkipCreativeApproval, campaignID)
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	// Going over the campaign budget is allowed, but the advertiser should know
+	var warnings []string
+	if campaignID != nil {
+		spend, err := h.campaignService.GetCampaignSpend(c.Context(), *campaignID, actorID)
+		if err != nil {
+			h.log.Warn("failed to check campaign budget", zap.String("campaign_id", campaignID.String()), zap.Error(err))
+		} else if spend.OverBudget {
+			warnings = append(warnings, fmt.Sprintf("campaign budget exceeded: %s of %s booked", spend.SpentTON, spend.BudgetTON))
+		}
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse{OK: true, Data: deal, Warnings: warnings})
+}
+
+// GetChannelAvailability returns the next free posting slot for a channel.
+func (h *DealHandler) GetChannelAvailability(c *fiber.Ctx) error {
+	channelID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid channel id"})
+	}
+
+	adFormat := c.Query("format", models.AdFormatPost)
+	if !models.IsValidAdFormat(adFormat) {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid format (allowed: post, repost, story)"})
+	}
+
+	availability, err := h.dealService.GetChannelAvailability(c.Context(), channelID, adFormat)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "listing not found"})
+	}
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: err.Error()})
+		h.log.Error("get channel availability failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse{OK: true, Data: deal})
+	return c.JSON(dto.SuccessResponse{OK: true, Data: availability})
 }
 
 func (h *DealHandler) GetDeal(c *fiber.Ctx) error {
@@ -53,13 +101,42 @@ func (h *DealHandler) GetDeal(c *fiber.Ctx) error {
 	}
 
 	deal, err := h.dealService.GetDeal(c.Context(), id)
-	if err != nil {
+	if errors.Is(err, repositories.ErrNotFound) {
 		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "deal not found"})
 	}
+	if err != nil {
+		h.log.Error("get deal failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
 
 	return c.JSON(dto.SuccessResponse{OK: true, Data: deal})
 }
 
+// GetDealStatuses — POST /deals/status: {deal_id: status} for the caller's deals among deal_ids.
+func (h *DealHandler) GetDealStatuses(c *fiber.Ctx) error {
+	var req dto.DealStatusesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request"})
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.DealIDs))
+	for _, raw := range req.DealIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid deal id: " + raw})
+		}
+		ids = append(ids, id)
+	}
+
+	userID := middleware.GetUserID(c)
+	statuses, err := h.dealService.GetDealStatuses(c.Context(), userID, ids)
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: statuses})
+}
+
 func (h *DealHandler) ListDeals(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
 	filter := repositories.DealFilter{
@@ -77,6 +154,13 @@ func (h *DealHandler) ListDeals(c *fiber.Ctx) error {
 			filter.Offset = n
 		}
 	}
+	if v := c.Query("cursor"); v != "" {
+		cursor, err := repositories.DecodeCursor(v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid cursor"})
+		}
+		filter.Cursor = cursor
+	}
 	if v := c.Query("status"); v != "" {
 		filter.Status = &v
 	}
@@ -92,13 +176,13 @@ func (h *DealHandler) ListDeals(c *fiber.Ctx) error {
 		filter.AdvertiserUserID = &userID
 	}
 
-	deals, err := h.dealService.ListDeals(c.Context(), filter)
+	page, err := h.dealService.ListDeals(c.Context(), filter)
 	if err != nil {
 		h.log.Error("list deals failed", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
 	}
 
-	return c.JSON(dto.SuccessResponse{OK: true, Data: deals})
+	return c.JSON(dto.SuccessResponse{OK: true, Data: page.Items, NextCursor: page.NextCursor})
 }
 
 func (h *DealHandler) AcceptDeal(c *fiber.Ctx) error {
@@ -109,7 +193,74 @@ func (h *DealHandler) AcceptDeal(c *fiber.Ctx) error {
 
 	actorID := middleware.GetUserID(c)
 	if err := h.dealService.AcceptDeal(c.Context(), dealID, actorID); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
+
+// Counteroffer — POST /deals/:id/counteroffer
+// The channel owner/manager proposes another price; the deal stays submitted.
+func (h *DealHandler) Counteroffer(c *fiber.Ctx) error {
+	dealID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid deal id"})
+	}
+	var req dto.CounterofferRequest
+	if err := c.BodyParser(&req); err != nil || req.PriceTON == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "price_ton is required"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	offer, err := h.dealService.Counteroffer(c.Context(), dealID, actorID, req.PriceTON)
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse{OK: true, Data: offer})
+}
+
+// ListCounteroffers — GET /deals/:id/counteroffers
+func (h *DealHandler) ListCounteroffers(c *fiber.Ctx) error {
+	dealID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid deal id"})
+	}
+
+	offers, err := h.dealService.ListCounteroffers(c.Context(), dealID)
+	if err != nil {
+		h.log.Error("list counteroffers failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: offers})
+}
+
+// AcceptCounteroffer — POST /deals/:id/counteroffer/accept
+func (h *DealHandler) AcceptCounteroffer(c *fiber.Ctx) error {
+	dealID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid deal id"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	if err := h.dealService.AcceptCounteroffer(c.Context(), dealID, actorID); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
+
+// RejectCounteroffer — POST /deals/:id/counteroffer/reject
+func (h *DealHandler) RejectCounteroffer(c *fiber.Ctx) error {
+	dealID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid deal id"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	if err := h.dealService.RejectCounteroffer(c.Context(), dealID, actorID); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
 	}
 
 	return c.JSON(dto.SuccessResponse{OK: true})
@@ -123,7 +274,7 @@ func (h *DealHandler) RejectDeal(c *fiber.Ctx) error {
 
 	actorID := middleware.GetUserID(c)
 	if err := h.dealService.RejectDeal(c.Context(), dealID, actorID); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusBadRequest, err)
 	}
 
 	return c.JSON(dto.SuccessResponse{OK: true})
@@ -137,7 +288,7 @@ func (h *DealHandler) CancelDeal(c *fiber.Ctx) error {
 
 	actorID := middleware.GetUserID(c)
 	if err := h.dealService.CancelDeal(c.Context(), dealID, actorID); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusBadRequest, err)
 	}
 
 	return c.JSON(dto.SuccessResponse{OK: true})
@@ -150,7 +301,10 @@ func (h *DealHandler) SubmitCreative(c *fiber.Ctx) error {
 	}
 
 	var req dto.SubmitCreativeRequest
-	if err := c.BodyParser(&req); err != nil || req.Text == "" {
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request body"})
+	}
+	if req.Text == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "text is required"})
 	}
 
@@ -158,12 +312,14 @@ func (h *DealHandler) SubmitCreative(c *fiber.Ctx) error {
 		Text:          req.Text,
 		RepostFromURL: req.RepostFromURL,
 		MediaURLs:     req.MediaURLs,
-		ButtonsJSON:   req.ButtonsJSON,
+	}
+	for _, b := range req.ButtonsJSON {
+		input.Buttons = append(input.Buttons, models.CreativeButton{Text: b.Text, URL: b.URL})
 	}
 
 	actorID := middleware.GetUserID(c)
 	if err := h.dealService.SubmitCreative(c.Context(), dealID, actorID, input); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusBadRequest, err)
 	}
 
 	return c.JSON(dto.SuccessResponse{OK: true})
@@ -177,7 +333,7 @@ func (h *DealHandler) ApproveCreative(c *fiber.Ctx) error {
 
 	actorID := middleware.GetUserID(c)
 	if err := h.dealService.ApproveCreative(c.Context(), dealID, actorID); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusBadRequest, err)
 	}
 
 	return c.JSON(dto.SuccessResponse{OK: true})
@@ -194,7 +350,7 @@ func (h *DealHandler) RequestCreativeChanges(c *fiber.Ctx) error {
 
 	actorID := middleware.GetUserID(c)
 	if err := h.dealService.RequestCreativeChanges(c.Context(), dealID, actorID, req.Feedback); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusBadRequest, err)
 	}
 
 	return c.JSON(dto.SuccessResponse{OK: true})
@@ -207,13 +363,107 @@ func (h *DealHandler) GetCreative(c *fiber.Ctx) error {
 	}
 
 	creative, err := h.dealService.GetLatestCreative(c.Context(), dealID)
-	if err != nil {
+	if errors.Is(err, repositories.ErrNotFound) {
 		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "creative not found"})
 	}
+	if err != nil {
+		h.log.Error("get creative failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
 
 	return c.JSON(dto.SuccessResponse{OK: true, Data: creative})
 }
 
+// GetCreatives — GET /deals/:id/creatives: all creative versions, oldest first.
+func (h *DealHandler) GetCreatives(c *fiber.Ctx) error {
+	dealID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid deal id"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	creatives, err := h.dealService.GetCreatives(c.Context(), dealID, actorID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "deal not found"})
+	}
+	if errors.Is(err, services.ErrNotChannelMember) {
+		return errorJSON(c, fiber.StatusForbidden, err)
+	}
+	if err != nil {
+		h.log.Error("list creatives failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: creatives})
+}
+
+// RateDeal lets either side rate the other once the deal is completed.
+func (h *DealHandler) RateDeal(c *fiber.Ctx) error {
+	dealID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid deal id"})
+	}
+	var req dto.RateDealRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request body"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	rating, err := h.dealService.RateDeal(c.Context(), dealID, actorID, req.Stars, req.Comment)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "deal not found"})
+	}
+	if errors.Is(err, services.ErrNotChannelMember) {
+		return errorJSON(c, fiber.StatusForbidden, err)
+	}
+	if errors.Is(err, services.ErrDealAlreadyRated) {
+		return errorJSON(c, fiber.StatusConflict, err)
+	}
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse{OK: true, Data: rating})
+}
+
+func (h *DealHandler) GetDealRatings(c *fiber.Ctx) error {
+	dealID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid deal id"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	ratings, err := h.dealService.GetDealRatings(c.Context(), dealID, actorID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "deal not found"})
+	}
+	if errors.Is(err, services.ErrNotChannelMember) {
+		return errorJSON(c, fiber.StatusForbidden, err)
+	}
+	if err != nil {
+		h.log.Error("list deal ratings failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: ratings})
+}
+
+// GetAdvertiserRating shows how channel owners rated the user's past deals.
+func (h *DealHandler) GetAdvertiserRating(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid user id"})
+	}
+
+	summary, err := h.dealService.GetAdvertiserRating(c.Context(), userID)
+	if err != nil {
+		h.log.Error("get advertiser rating failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: summary})
+}
+
 func (h *DealHandler) GetDealEvents(c *fiber.Ctx) error {
 	dealID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
@@ -242,7 +492,22 @@ func (h *DealHandler) MarkManualPost(c *fiber.Ctx) error {
 
 	actorID := middleware.GetUserID(c)
 	if err := h.dealService.MarkManualPost(c.Context(), dealID, actorID, req.PostURL); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
+
+// SchedulePost — POST /deals/:id/post/schedule: the bot publishes the approved creative at scheduled_at.
+func (h *DealHandler) SchedulePost(c *fiber.Ctx) error {
+	dealID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid deal id"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	if err := h.dealService.SchedulePost(c.Context(), dealID, actorID); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
 	}
 
 	return c.JSON(dto.SuccessResponse{OK: true})
@@ -261,7 +526,78 @@ func (h *DealHandler) SetWithdrawWallet(c *fiber.Ctx) error {
 
 	actorID := middleware.GetUserID(c)
 	if err := h.dealService.SetWithdrawWallet(c.Context(), dealID, actorID, req.WalletAddress); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
+
+// RequestRefundAddress — POST /deals/:id/refund-address
+// Requests refund to the advertiser's connected (TON Proof verified) wallet; needs admin approval.
+func (h *DealHandler) RequestRefundAddress(c *fiber.Ctx) error {
+	dealID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid deal id"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	req, err := h.dealService.RequestRefundAddress(c.Context(), dealID, actorID)
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(dto.SuccessResponse{OK: true, Data: req})
+}
+
+// RescheduleDeal — POST /deals/:id/reschedule
+func (h *DealHandler) RescheduleDeal(c *fiber.Ctx) error {
+	dealID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid deal id"})
+	}
+	var req dto.RescheduleDealRequest
+	if err := c.BodyParser(&req); err != nil || req.ScheduledAt == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "scheduled_at is required"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	if err := h.dealService.RescheduleDeal(c.Context(), dealID, actorID, *req.ScheduledAt); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
+
+// OpenDispute — POST /deals/:id/dispute
+// The advertiser contests the live post; the payout waits until an admin resolves it.
+func (h *DealHandler) OpenDispute(c *fiber.Ctx) error {
+	dealID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid deal id"})
+	}
+	var req dto.OpenDisputeRequest
+	if err := c.BodyParser(&req); err != nil || req.Reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "reason is required"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	if err := h.dealService.OpenDispute(c.Context(), dealID, actorID, req.Reason); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
+
+// ResendPaymentInstructions pushes the payment details to the advertiser via the bot.
+func (h *DealHandler) ResendPaymentInstructions(c *fiber.Ctx) error {
+	dealID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid deal id"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	if err := h.dealService.ResendPaymentInstructions(c.Context(), dealID, actorID); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
 	}
 
 	return c.JSON(dto.SuccessResponse{OK: true})
@@ -274,15 +610,20 @@ func (h *DealHandler) GetPaymentInfo(c *fiber.Ctx) error {
 	}
 
 	escrow, err := h.dealService.GetPaymentInfo(c.Context(), dealID)
-	if err != nil {
+	if errors.Is(err, repositories.ErrNotFound) {
 		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "payment info not found"})
 	}
+	if err != nil {
+		h.log.Error("get payment info failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
 
 	return c.JSON(dto.PaymentInfoResponse{
 		DealID:        dealID.String(),
 		WalletAddress: escrow.DepositAddress,
 		Memo:          escrow.DepositMemo,
 		AmountTON:     escrow.DepositExpectedTON,
+		Currency:      escrow.Currency,
 		Status:        escrow.Status,
 	})
 }
@@ -295,7 +636,7 @@ func (h *DealHandler) SubmitDeal(c *fiber.Ctx) error {
 
 	actorID := middleware.GetUserID(c)
 	if err := h.dealService.SubmitDeal(c.Context(), dealID, actorID); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusBadRequest, err)
 	}
 
 	return c.JSON(dto.SuccessResponse{OK: true})
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ads-marketplace/backend/internal/http/dto"
+	"github.com/ads-marketplace/backend/internal/middleware"
+	"github.com/ads-marketplace/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type EarningsHandler struct {
+	earningsService *services.EarningsService
+	log             *zap.Logger
+}
+
+func NewEarningsHandler(earningsService *services.EarningsService, log *zap.Logger) *EarningsHandler {
+	return &EarningsHandler{earningsService: earningsService, log: log}
+}
+
+// GetEarnings — GET /me/earnings?currency=USDT (TON by default)
+func (h *EarningsHandler) GetEarnings(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	earnings, err := h.earningsService.GetEarnings(c.Context(), userID, c.Query("currency"))
+	if errors.Is(err, services.ErrUnknownCurrency) {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+	if err != nil {
+		h.log.Error("get earnings failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: earnings})
+}
+
+// GetBalance — GET /me/balance?currency=USDT (TON by default)
+func (h *EarningsHandler) GetBalance(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	balance, err := h.earningsService.GetBalance(c.Context(), userID, c.Query("currency"))
+	if errors.Is(err, services.ErrUnknownCurrency) {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+	if err != nil {
+		h.log.Error("get balance failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: balance})
+}
+
+// RequestWithdrawal — POST /me/withdrawals {"amount_ton": "1.5", "currency": "USDT"}
+// (omit amount_ton for the whole balance, currency for TON)
+func (h *EarningsHandler) RequestWithdrawal(c *fiber.Ctx) error {
+	var req struct {
+		AmountTON string `json:"amount_ton"`
+		Currency  string `json:"currency"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request body"})
+		}
+	}
+
+	userID := middleware.GetUserID(c)
+	w, err := h.earningsService.RequestWithdrawal(c.Context(), userID, req.AmountTON, req.Currency)
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse{OK: true, Data: w})
+}
+
+// ListWithdrawals — GET /me/withdrawals
+func (h *EarningsHandler) ListWithdrawals(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	list, err := h.earningsService.ListWithdrawals(c.Context(), userID, c.QueryInt("limit", 50))
+	if err != nil {
+		h.log.Error("list withdrawals failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: list})
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ads-marketplace/backend/internal/apperr"
+	"github.com/ads-marketplace/backend/internal/http/dto"
+	"github.com/ads-marketplace/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// errorJSON writes a service error: the message is localized per Accept-Language,
+// coded errors also carry their stable code for programmatic use. A deal changed by a
+// concurrent request is always a 409, whatever status the caller picked.
+func errorJSON(c *fiber.Ctx, status int, err error) error {
+	if errors.Is(err, services.ErrDealChanged) {
+		status = fiber.StatusConflict
+	}
+	return c.Status(status).JSON(dto.ErrorResponse{
+		Error: apperr.Localize(err, apperr.Lang(c.Get(fiber.HeaderAcceptLanguage))),
+		Code:  apperr.CodeOf(err),
+		Field: apperr.FieldOf(err),
+	})
+}
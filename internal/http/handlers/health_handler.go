@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// HealthCheck probes one dependency of the API; Check returns nil when it is reachable.
+type HealthCheck struct {
+	Name     string
+	Required bool // a failing required dependency takes the instance out of rotation (503)
+	Check    func(ctx context.Context) error
+}
+
+type HealthHandler struct {
+	checks  []HealthCheck
+	timeout time.Duration
+	log     *zap.Logger
+}
+
+func NewHealthHandler(checks []HealthCheck, timeout time.Duration, log *zap.Logger) *HealthHandler {
+	return &HealthHandler{checks: checks, timeout: timeout, log: log}
+}
+
+// Live — GET /health; cheap liveness probe, touches no dependency.
+func (h *HealthHandler) Live(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+// Ready — GET /health/ready; runs every check in parallel under one short deadline.
+// 503 "unavailable" if a required dependency fails, "degraded" if only optional ones do.
+func (h *HealthHandler) Ready(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), h.timeout)
+	defer cancel()
+
+	errs := make([]error, len(h.checks))
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = check.Check(ctx)
+		}()
+	}
+	wg.Wait()
+
+	status := "ok"
+	results := make(fiber.Map, len(h.checks))
+	for i, check := range h.checks {
+		if errs[i] == nil {
+			results[check.Name] = "ok"
+			continue
+		}
+		results[check.Name] = errs[i].Error()
+		h.log.Warn("readiness check failed", zap.String("dependency", check.Name), zap.Error(errs[i]))
+		if check.Required {
+			status = "unavailable"
+		} else if status == "ok" {
+			status = "degraded"
+		}
+	}
+
+	code := fiber.StatusOK
+	if status == "unavailable" {
+		code = fiber.StatusServiceUnavailable
+	}
+	return c.Status(code).JSON(fiber.Map{"status": status, "checks": results})
+}
@@ -67,6 +67,20 @@ var predefinedLanguages = []MetaLanguage{
 	{ID: "other", Label: "Other"},
 }
 
+// isPredefinedLanguage reports whether id is a language of predefinedLanguages; "other"
+// is a listing filter, not a language.
+func isPredefinedLanguage(id string) bool {
+	if id == "other" {
+		return false
+	}
+	for _, l := range predefinedLanguages {
+		if l.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
 func (h *MetaHandler) GetCategories(c *fiber.Ctx) error {
 	return c.JSON(dto.SuccessResponse{OK: true, Data: predefinedCategories})
 }
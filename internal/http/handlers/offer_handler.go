@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/ads-marketplace/backend/internal/http/dto"
+	"github.com/ads-marketplace/backend/internal/middleware"
+	"github.com/ads-marketplace/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type OfferHandler struct {
+	offerService *services.OfferService
+	log          *zap.Logger
+}
+
+func NewOfferHandler(offerService *services.OfferService, log *zap.Logger) *OfferHandler {
+	return &OfferHandler{offerService: offerService, log: log}
+}
+
+// CreateOffer — POST /channels/:id/offers (channel owner/manager)
+func (h *OfferHandler) CreateOffer(c *fiber.Ctx) error {
+	channelID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid channel id"})
+	}
+
+	var req dto.CreateOfferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request"})
+	}
+	if req.AdFormat == "" || req.PriceTON == "" || req.ValidUntil.IsZero() {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "ad_format, price_ton and valid_until are required"})
+	}
+
+	input := services.CreateOfferInput{
+		ChannelID:   channelID,
+		AdFormat:    req.AdFormat,
+		PriceTON:    req.PriceTON,
+		Brief:       req.Brief,
+		ScheduledAt: req.ScheduledAt,
+		ValidUntil:  req.ValidUntil,
+	}
+	if req.AdvertiserUserID != nil && *req.AdvertiserUserID != "" {
+		advertiserID, err := uuid.Parse(*req.AdvertiserUserID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid advertiser_user_id"})
+		}
+		input.AdvertiserUserID = &advertiserID
+	}
+
+	actorID := middleware.GetUserID(c)
+	offer, err := h.offerService.CreateOffer(c.Context(), actorID, input)
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse{OK: true, Data: offer})
+}
+
+// ListChannelOffers — GET /channels/:id/offers (channel owner/manager)
+func (h *OfferHandler) ListChannelOffers(c *fiber.Ctx) error {
+	channelID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid channel id"})
+	}
+	limit, offset := 50, 0
+	if v := c.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			limit = n
+		}
+	}
+	if v := c.Query("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			offset = n
+		}
+	}
+
+	actorID := middleware.GetUserID(c)
+	offers, err := h.offerService.ListChannelOffers(c.Context(), channelID, actorID, limit, offset)
+	if err != nil {
+		return errorJSON(c, fiber.StatusForbidden, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: offers})
+}
+
+// ListOffers — GET /offers; open offers addressed to the current user or public.
+func (h *OfferHandler) ListOffers(c *fiber.Ctx) error {
+	limit, offset := 50, 0
+	if v := c.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			limit = n
+		}
+	}
+	if v := c.Query("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			offset = n
+		}
+	}
+
+	userID := middleware.GetUserID(c)
+	offers, err := h.offerService.ListAvailableOffers(c.Context(), userID, limit, offset)
+	if err != nil {
+		h.log.Error("list offers failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: offers})
+}
+
+// AcceptOffer — POST /offers/:id/accept; creates a deal awaiting payment.
+func (h *OfferHandler) AcceptOffer(c *fiber.Ctx) error {
+	offerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid offer id"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	deal, err := h.offerService.AcceptOffer(c.Context(), offerID, actorID)
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse{OK: true, Data: deal})
+}
+
+// CancelOffer — POST /offers/:id/cancel (channel owner/manager)
+func (h *OfferHandler) CancelOffer(c *fiber.Ctx) error {
+	offerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid offer id"})
+	}
+
+	actorID := middleware.GetUserID(c)
+	if err := h.offerService.CancelOffer(c.Context(), offerID, actorID); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
@@ -1,28 +1,63 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/ads-marketplace/backend/internal/http/dto"
 	"github.com/ads-marketplace/backend/internal/middleware"
 	"github.com/ads-marketplace/backend/internal/repositories"
+	"github.com/ads-marketplace/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type UserHandler struct {
-	userRepo *repositories.UserRepo
-	log      *zap.Logger
+	userRepo    *repositories.UserRepo
+	userService *services.UserService
+	log         *zap.Logger
 }
 
-func NewUserHandler(userRepo *repositories.UserRepo, log *zap.Logger) *UserHandler {
-	return &UserHandler{userRepo: userRepo, log: log}
+func NewUserHandler(userRepo *repositories.UserRepo, userService *services.UserService, log *zap.Logger) *UserHandler {
+	return &UserHandler{userRepo: userRepo, userService: userService, log: log}
 }
 
 func (h *UserHandler) GetMe(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
 	user, err := h.userRepo.GetByID(c.Context(), userID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "user not found"})
+	}
 	if err != nil {
+		h.log.Error("get user failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+	return c.JSON(dto.SuccessResponse{OK: true, Data: user})
+}
+
+// UpdateMe — PATCH /me; sets the notification language and opt-out.
+func (h *UserHandler) UpdateMe(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	var req dto.UpdateMeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request"})
+	}
+	if req.LanguageCode == nil && req.NotificationsEnabled == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "nothing to update"})
+	}
+	if req.LanguageCode != nil && !isPredefinedLanguage(*req.LanguageCode) {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "unsupported language_code, see /meta/languages"})
+	}
+
+	user, err := h.userService.UpdateProfile(c.Context(), userID, req.LanguageCode, req.NotificationsEnabled)
+	if errors.Is(err, repositories.ErrNotFound) {
 		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "user not found"})
 	}
+	if err != nil {
+		h.log.Error("update profile failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
 	return c.JSON(dto.SuccessResponse{OK: true, Data: user})
 }
 
@@ -33,3 +68,66 @@ func (h *UserHandler) Ping(c *fiber.Ctx) error {
 	}
 	return c.JSON(dto.SuccessResponse{OK: true})
 }
+
+// DeleteMe — DELETE /me; anonymizes the account and redacts it from the audit log.
+func (h *UserHandler) DeleteMe(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if err := h.userService.DeleteAccount(c.Context(), userID); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
+
+func (h *UserHandler) ListBlocks(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	blocks, err := h.userService.ListBlocks(c.Context(), userID)
+	if err != nil {
+		h.log.Error("list blocks failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+	return c.JSON(dto.SuccessResponse{OK: true, Data: blocks})
+}
+
+// BlockUser — POST /me/blocks; refuses future deals with the user.
+func (h *UserHandler) BlockUser(c *fiber.Ctx) error {
+	var req dto.BlockUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request"})
+	}
+	targetID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid user_id"})
+	}
+
+	userID := middleware.GetUserID(c)
+	err = h.userService.BlockUser(c.Context(), userID, targetID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "user not found"})
+	}
+	if errors.Is(err, services.ErrCannotBlockSelf) {
+		return errorJSON(c, fiber.StatusBadRequest, err)
+	}
+	if err != nil {
+		h.log.Error("block user failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
+
+func (h *UserHandler) UnblockUser(c *fiber.Ctx) error {
+	targetID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid user id"})
+	}
+
+	userID := middleware.GetUserID(c)
+	err = h.userService.UnblockUser(c.Context(), userID, targetID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "block not found"})
+	}
+	if err != nil {
+		h.log.Error("unblock user failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/ads-marketplace/backend/internal/http/dto"
 	"github.com/ads-marketplace/backend/internal/middleware"
+	"github.com/ads-marketplace/backend/internal/repositories"
 	"github.com/ads-marketplace/backend/internal/services"
 	"github.com/ads-marketplace/backend/internal/ton"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -23,6 +27,9 @@ func NewWalletHandler(walletService *services.WalletService, log *zap.Logger) *W
 func (h *WalletHandler) GeneratePayload(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
 	payload, err := h.walletService.GeneratePayload(c.Context(), &userID)
+	if errors.Is(err, services.ErrTooManyAttempts) {
+		return errorJSON(c, fiber.StatusTooManyRequests, err)
+	}
 	if err != nil {
 		h.log.Error("failed to generate proof payload", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
@@ -39,6 +46,8 @@ func (h *WalletHandler) ConnectWallet(c *fiber.Ctx) error {
 		Network         string    `json:"network"`
 		PublicKey       string    `json:"public_key"`
 		Proof           ton.Proof `json:"proof"`
+		StateInit       string    `json:"state_init"`
+		Label           string    `json:"label"`
 	}
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request body"})
@@ -47,9 +56,6 @@ func (h *WalletHandler) ConnectWallet(c *fiber.Ctx) error {
 	if req.Address == "" || req.PublicKey == "" || req.Proof.Signature == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "address, public_key, and proof.signature are required"})
 	}
-	if req.AddressFriendly == "" {
-		req.AddressFriendly = req.Address
-	}
 	if req.Network == "" {
 		req.Network = "mainnet"
 	}
@@ -61,10 +67,15 @@ func (h *WalletHandler) ConnectWallet(c *fiber.Ctx) error {
 		Network:         req.Network,
 		PublicKey:       req.PublicKey,
 		Proof:           req.Proof,
+		StateInit:       req.StateInit,
+		Label:           req.Label,
 	})
+	if errors.Is(err, services.ErrTooManyAttempts) || errors.Is(err, services.ErrWalletConnectLocked) {
+		return errorJSON(c, fiber.StatusTooManyRequests, err)
+	}
 	if err != nil {
 		h.log.Debug("wallet connect failed", zap.Error(err))
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: err.Error()})
+		return errorJSON(c, fiber.StatusBadRequest, err)
 	}
 
 	return c.JSON(dto.SuccessResponse{OK: true, Data: wallet})
@@ -80,13 +91,69 @@ func (h *WalletHandler) DisconnectWallet(c *fiber.Ctx) error {
 	return c.JSON(dto.SuccessResponse{OK: true})
 }
 
-// GetWallet возвращает активный кошелёк.
+// ListWallets возвращает все подключённые кошельки, основной первым.
+// GET /me/wallets
+func (h *WalletHandler) ListWallets(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	wallets, err := h.walletService.ListWallets(c.Context(), userID)
+	if err != nil {
+		h.log.Error("list wallets failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+	return c.JSON(dto.SuccessResponse{OK: true, Data: wallets})
+}
+
+// SetPrimaryWallet делает кошелёк основным.
+// POST /me/wallets/:id/primary
+func (h *WalletHandler) SetPrimaryWallet(c *fiber.Ctx) error {
+	walletID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid wallet id"})
+	}
+
+	userID := middleware.GetUserID(c)
+	wallet, err := h.walletService.SetPrimaryWallet(c.Context(), userID, walletID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "wallet not found"})
+	}
+	if err != nil {
+		h.log.Error("set primary wallet failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+	return c.JSON(dto.SuccessResponse{OK: true, Data: wallet})
+}
+
+// DisconnectWalletByID отключает один кошелёк.
+// DELETE /me/wallets/:id
+func (h *WalletHandler) DisconnectWalletByID(c *fiber.Ctx) error {
+	walletID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid wallet id"})
+	}
+
+	userID := middleware.GetUserID(c)
+	err = h.walletService.DisconnectWalletByID(c.Context(), userID, walletID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "wallet not found"})
+	}
+	if err != nil {
+		h.log.Error("disconnect wallet failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "failed to disconnect wallet"})
+	}
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
+
+// GetWallet возвращает основной кошелёк.
 // GET /me/wallet
 func (h *WalletHandler) GetWallet(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
 	wallet, err := h.walletService.GetActiveWallet(c.Context(), userID)
-	if err != nil {
+	if errors.Is(err, repositories.ErrNotFound) {
 		return c.JSON(dto.SuccessResponse{OK: true, Data: nil})
 	}
+	if err != nil {
+		h.log.Error("get wallet failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
 	return c.JSON(dto.SuccessResponse{OK: true, Data: wallet})
 }
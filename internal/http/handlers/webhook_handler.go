@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ads-marketplace/backend/internal/http/dto"
+	"github.com/ads-marketplace/backend/internal/middleware"
+	"github.com/ads-marketplace/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+	log            *zap.Logger
+}
+
+func NewWebhookHandler(webhookService *services.WebhookService, log *zap.Logger) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService, log: log}
+}
+
+// Register — POST /me/webhooks {"url": "https://...", "events": ["completed", "refunded", "cancelled"]}
+func (h *WebhookHandler) Register(c *fiber.Ctx) error {
+	var req struct {
+		URL    string   `json:"url"`
+		Events []string `json:"events"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid request body"})
+	}
+
+	userID := middleware.GetUserID(c)
+	w, err := h.webhookService.Register(c.Context(), userID, req.URL, req.Events)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidWebhookURL) || errors.Is(err, services.ErrInvalidWebhookEvents) ||
+			errors.Is(err, services.ErrWebhookLimit) {
+			return errorJSON(c, fiber.StatusBadRequest, err)
+		}
+		h.log.Error("register webhook failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse{OK: true, Data: w})
+}
+
+// List — GET /me/webhooks
+func (h *WebhookHandler) List(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	list, err := h.webhookService.List(c.Context(), userID)
+	if err != nil {
+		h.log.Error("list webhooks failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: list})
+}
+
+// Delete — DELETE /me/webhooks/:id
+func (h *WebhookHandler) Delete(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid webhook id"})
+	}
+
+	userID := middleware.GetUserID(c)
+	err = h.webhookService.Delete(c.Context(), userID, id)
+	if errors.Is(err, services.ErrWebhookNotFound) {
+		return errorJSON(c, fiber.StatusNotFound, err)
+	}
+	if err != nil {
+		h.log.Error("delete webhook failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true})
+}
+
+// ListDeliveries — GET /me/webhooks/:id/deliveries
+func (h *WebhookHandler) ListDeliveries(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "invalid webhook id"})
+	}
+
+	userID := middleware.GetUserID(c)
+	list, err := h.webhookService.ListDeliveries(c.Context(), userID, id, c.QueryInt("limit", 50))
+	if err != nil {
+		h.log.Error("list webhook deliveries failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "internal error"})
+	}
+
+	return c.JSON(dto.SuccessResponse{OK: true, Data: list})
+}
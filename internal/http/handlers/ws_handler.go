@@ -3,7 +3,10 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ads-marketplace/backend/internal/auth"
 	"github.com/ads-marketplace/backend/internal/config"
@@ -14,20 +17,77 @@ import (
 	"go.uber.org/zap"
 )
 
+// Keepalive: the server pings every wsPingPeriod and drops a connection that sends nothing,
+// not even a pong, for wsPongWait. Writes that take longer than wsWriteWait fail.
+const (
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+	wsWriteWait  = 10 * time.Second
+)
+
+// Close codes of a connection whose token is no longer valid: the client reconnects
+// with a fresh token.
+const (
+	wsCloseTokenInvalid = 4001
+	wsCloseTokenExpired = 4002
+)
+
 type WSHub struct {
-	cfg         *config.Config
-	subscriber  events.Subscriber
-	log         *zap.Logger
-	mu          sync.RWMutex
-	connections map[uuid.UUID][]*websocket.Conn
+	cfg        *config.Config
+	subscriber events.Subscriber
+	eventLog   events.UserEventLog
+	denylist   auth.TokenDenylist
+	// participants resolves who may see a deal's events.
+	participants events.ParticipantsFunc
+	log          *zap.Logger
+	mu           sync.RWMutex
+	connections  map[uuid.UUID][]*websocket.Conn
+	// A user's lock serialises sequencing+delivery with replay-on-connect so a
+	// reconnecting client can't miss an event between replay and registration,
+	// and keeps writes to the user's connections from interleaving. Per user, so
+	// a slow client only delays its own events.
+	locksMu   sync.Mutex
+	userLocks map[uuid.UUID]*userLock
+}
+
+// userLock is a user's delivery lock, dropped once nobody holds or waits for it.
+type userLock struct {
+	mu   sync.Mutex
+	refs int
 }
 
-func NewWSHub(cfg *config.Config, subscriber events.Subscriber, log *zap.Logger) *WSHub {
+func NewWSHub(cfg *config.Config, subscriber events.Subscriber, eventLog events.UserEventLog, denylist auth.TokenDenylist, participants events.ParticipantsFunc, log *zap.Logger) *WSHub {
 	return &WSHub{
-		cfg:         cfg,
-		subscriber:  subscriber,
-		log:         log,
-		connections: make(map[uuid.UUID][]*websocket.Conn),
+		cfg:          cfg,
+		subscriber:   subscriber,
+		eventLog:     eventLog,
+		denylist:     denylist,
+		participants: participants,
+		log:          log,
+		connections:  make(map[uuid.UUID][]*websocket.Conn),
+		userLocks:    make(map[uuid.UUID]*userLock),
+	}
+}
+
+// lockUser takes the user's delivery lock and returns its unlock.
+func (h *WSHub) lockUser(userID uuid.UUID) func() {
+	h.locksMu.Lock()
+	l := h.userLocks[userID]
+	if l == nil {
+		l = &userLock{}
+		h.userLocks[userID] = l
+	}
+	l.refs++
+	h.locksMu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		h.locksMu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(h.userLocks, userID)
+		}
+		h.locksMu.Unlock()
 	}
 }
 
@@ -37,36 +97,72 @@ func (h *WSHub) Start(ctx context.Context) {
 	})
 }
 
+// broadcast delivers an event to its audience (see events.Audience); everyone else never
+// sees it. Offline users get it in their outbox too, replayed when they reconnect with last_seq.
 func (h *WSHub) broadcast(event events.Event) {
-	data, err := json.Marshal(event)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	audience, err := events.Audience(ctx, event, h.participants)
+	cancel()
 	if err != nil {
+		h.log.Warn("failed to resolve ws event audience", zap.String("type", event.Type), zap.Error(err))
 		return
 	}
 
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-
-	for _, conns := range h.connections {
-		for _, conn := range conns {
-			_ = conn.WriteMessage(websocket.TextMessage, data)
-		}
+	for _, userID := range audience {
+		h.SendToUser(userID, event)
 	}
 }
 
+// SendToUser assigns the next per-user sequence number, stores the event in the
+// user's outbox and writes it to all of the user's connections, if any.
 func (h *WSHub) SendToUser(userID uuid.UUID, event events.Event) {
-	data, err := json.Marshal(event)
+	unlock := h.lockUser(userID)
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	seqEvent, err := h.eventLog.Append(ctx, userID, event)
+	if err != nil {
+		h.log.Error("failed to sequence ws event", zap.String("user_id", userID.String()), zap.Error(err))
+		return
+	}
+
+	data, err := json.Marshal(seqEvent)
 	if err != nil {
 		return
 	}
 
 	h.mu.RLock()
-	defer h.mu.RUnlock()
+	conns := append([]*websocket.Conn(nil), h.connections[userID]...)
+	h.mu.RUnlock()
 
-	for _, conn := range h.connections[userID] {
+	for _, conn := range conns {
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
 		_ = conn.WriteMessage(websocket.TextMessage, data)
 	}
 }
 
+// closeWS sends the client a final error and closes the connection. Data frames are only
+// written under the user's lock, so the error can't interleave with an event.
+func (h *WSHub) closeWS(userID uuid.UUID, conn *websocket.Conn, code int, reason string) {
+	data, _ := json.Marshal(map[string]string{"error": reason})
+	unlock := h.lockUser(userID)
+	_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	_ = conn.WriteMessage(websocket.TextMessage, data)
+	unlock()
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteWait))
+	conn.Close()
+}
+
+// tokenExpiry returns the token's expiry in Unix nanoseconds, 0 if it has none.
+func tokenExpiry(claims *auth.Claims) int64 {
+	if claims.ExpiresAt == nil {
+		return 0
+	}
+	return claims.ExpiresAt.UnixNano()
+}
+
 // WSUpgradeMiddleware checks for websocket upgrade
 func WSUpgradeMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -86,7 +182,7 @@ func (h *WSHub) HandleWS(conn *websocket.Conn) {
 		return
 	}
 
-	claims, err := auth.ParseJWT(h.cfg.JWTSecret, tokenStr)
+	claims, err := auth.ValidateAccessToken(context.Background(), h.cfg.JWTSecret, tokenStr, h.denylist, h.cfg.JWTLegacyTokens)
 	if err != nil {
 		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"error":"invalid token"}`))
 		conn.Close()
@@ -95,12 +191,42 @@ func (h *WSHub) HandleWS(conn *websocket.Conn) {
 
 	userID := claims.UserID
 
-	// Register
+	// Replay missed events (if the client sent last_seq), then register — under the
+	// user's lock so nothing published in between is lost or delivered twice.
+	unlock := h.lockUser(userID)
+	if v := conn.Query("last_seq"); v != "" {
+		lastSeq, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || lastSeq < 0 {
+			unlock()
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"error":"invalid last_seq"}`))
+			conn.Close()
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, err = events.Replay(ctx, h.eventLog, userID, lastSeq, func(e events.SequencedEvent) error {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			return conn.WriteMessage(websocket.TextMessage, data)
+		})
+		cancel()
+		if err != nil {
+			unlock()
+			h.log.Warn("ws replay failed", zap.String("user_id", userID.String()), zap.Error(err))
+			conn.Close()
+			return
+		}
+	}
+
 	h.mu.Lock()
 	h.connections[userID] = append(h.connections[userID], conn)
 	h.mu.Unlock()
+	unlock()
 
+	done := make(chan struct{})
 	defer func() {
+		close(done)
 		h.mu.Lock()
 		conns := h.connections[userID]
 		for i, c := range conns {
@@ -116,11 +242,64 @@ func (h *WSHub) HandleWS(conn *websocket.Conn) {
 		conn.Close()
 	}()
 
-	// Read loop (keep alive / pings)
+	// The token is checked at connect only; the client refreshes it before expiry with
+	// {"type":"auth","token":"..."} or the connection is closed once it expires
+	var expiry atomic.Int64
+	expiry.Store(tokenExpiry(claims))
+
+	go func() {
+		ticker := time.NewTicker(wsPingPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				if exp := expiry.Load(); exp != 0 && now.UnixNano() > exp {
+					h.closeWS(userID, conn, wsCloseTokenExpired, "token expired")
+					return
+				}
+				// WriteControl is safe next to the hub's writes; a failed ping ends the read loop
+				if err := conn.WriteControl(websocket.PingMessage, nil, now.Add(wsWriteWait)); err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
 	for {
-		_, _, err := conn.ReadMessage()
+		_, msg, err := conn.ReadMessage()
 		if err != nil {
 			break
 		}
+		_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+		var in struct {
+			Type  string `json:"type"`
+			Token string `json:"token"`
+		}
+		if json.Unmarshal(msg, &in) != nil || in.Type != "auth" {
+			continue
+		}
+		if exp := expiry.Load(); exp != 0 && time.Now().UnixNano() > exp {
+			h.closeWS(userID, conn, wsCloseTokenExpired, "token expired")
+			break
+		}
+		newClaims, err := auth.ValidateAccessToken(context.Background(), h.cfg.JWTSecret, in.Token, h.denylist, h.cfg.JWTLegacyTokens)
+		if err != nil || newClaims.UserID != userID {
+			h.closeWS(userID, conn, wsCloseTokenInvalid, "invalid token")
+			break
+		}
+		expiry.Store(tokenExpiry(newClaims))
+
+		unlock := h.lockUser(userID)
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"auth_ok"}`))
+		unlock()
 	}
 }
@@ -3,6 +3,7 @@ package http
 import (
 	"time"
 
+	"github.com/ads-marketplace/backend/internal/auth"
 	"github.com/ads-marketplace/backend/internal/config"
 	"github.com/ads-marketplace/backend/internal/http/handlers"
 	"github.com/ads-marketplace/backend/internal/middleware"
@@ -25,88 +26,178 @@ func SetupRouter(
 	dealHandler *handlers.DealHandler,
 	walletHandler *handlers.WalletHandler,
 	campaignHandler *handlers.CampaignHandler,
+	offerHandler *handlers.OfferHandler,
+	earningsHandler *handlers.EarningsHandler,
+	webhookHandler *handlers.WebhookHandler,
+	adminHandler *handlers.AdminHandler,
+	healthHandler *handlers.HealthHandler,
 	wsHub *handlers.WSHub,
 ) {
 	// Global middleware
 	app.Use(recover.New())
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization, X-Request-ID",
+		AllowOrigins:  "*",
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, X-Request-ID, Idempotency-Key",
+		ExposeHeaders: "Retry-After, Idempotent-Replayed",
 	}))
 	app.Use(middleware.RequestIDMiddleware())
 	app.Use(middleware.LoggerMiddleware(log))
 
-	// Health check
-	app.Get("/health", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{"status": "ok"})
-	})
+	// Health: liveness, and readiness with dependency checks
+	app.Get("/health", healthHandler.Live)
+	app.Get("/health/ready", healthHandler.Ready)
 
 	api := app.Group("/api/v1")
 
-	// Auth (public)
-	api.Post("/auth/telegram", authHandler.TelegramAuth)
+	// Public endpoints are limited per IP, protected ones per user (many Mini App users
+	// share Telegram's proxy IPs)
+	publicLimit := middleware.RateLimit(rdb, "public", cfg.RateLimitPublic, time.Minute, middleware.KeyByIP)
 
-	// Rate-limited public endpoints
-	api.Use(middleware.RateLimitMiddleware(rdb, 100, time.Minute))
+	// Auth (public)
+	api.Post("/auth/telegram", publicLimit, authHandler.TelegramAuth)
+	api.Post("/auth/refresh", publicLimit, authHandler.Refresh)
 
 	// Meta (public, no auth required)
 	metaHandler := handlers.NewMetaHandler()
-	api.Get("/meta/categories", metaHandler.GetCategories)
-	api.Get("/meta/languages", metaHandler.GetLanguages)
+	api.Get("/meta/categories", publicLimit, metaHandler.GetCategories)
+	api.Get("/meta/languages", publicLimit, metaHandler.GetLanguages)
 
 	// Protected endpoints
-	protected := api.Group("", middleware.AuthMiddleware(cfg, log))
+	protected := api.Group("", middleware.AuthMiddleware(cfg, auth.NewRedisTokenDenylist(rdb), log),
+		middleware.RateLimit(rdb, "user", cfg.RateLimitUser, time.Minute, middleware.KeyByUser))
+
+	// Idempotency-Key replay for POSTs that create something
+	idempotent := middleware.IdempotencyMiddleware(rdb, time.Hour)
+
+	protected.Post("/auth/logout", authHandler.Logout)
 
 	// User
 	protected.Get("/me", userHandler.GetMe)
+	protected.Patch("/me", userHandler.UpdateMe)
 	protected.Post("/me/ping", userHandler.Ping)
+	protected.Delete("/me", userHandler.DeleteMe)
+	protected.Get("/me/blocks", userHandler.ListBlocks)
+	protected.Post("/me/blocks", userHandler.BlockUser)
+	protected.Delete("/me/blocks/:userId", userHandler.UnblockUser)
 
 	// Wallet (TON Connect + Proof)
 	protected.Post("/me/wallet/proof-payload", walletHandler.GeneratePayload)
-	protected.Post("/me/wallet/connect", walletHandler.ConnectWallet)
+	protected.Post("/me/wallet/connect", idempotent, walletHandler.ConnectWallet)
 	protected.Delete("/me/wallet", walletHandler.DisconnectWallet)
 	protected.Get("/me/wallet", walletHandler.GetWallet)
+	protected.Get("/me/wallets", walletHandler.ListWallets)
+	protected.Post("/me/wallets/:id/primary", walletHandler.SetPrimaryWallet)
+	protected.Delete("/me/wallets/:id", walletHandler.DisconnectWalletByID)
+
+	// Earnings (owner balance, batched payouts)
+	protected.Get("/me/earnings", earningsHandler.GetEarnings)
+	protected.Get("/me/balance", earningsHandler.GetBalance)
+	protected.Get("/me/withdrawals", earningsHandler.ListWithdrawals)
+	protected.Post("/me/withdrawals", earningsHandler.RequestWithdrawal)
+
+	// Deal webhooks
+	protected.Get("/me/webhooks", webhookHandler.List)
+	protected.Post("/me/webhooks", idempotent, webhookHandler.Register)
+	protected.Delete("/me/webhooks/:id", webhookHandler.Delete)
+	protected.Get("/me/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
 
 	// Channels
 	protected.Post("/channels", channelHandler.CreateChannel)
 	protected.Get("/channels/my", channelHandler.MyChannels)
+	// Tighter limit: the check is cheap to call and would otherwise allow enumerating the catalogue
+	protected.Get("/channels/check", middleware.RateLimit(rdb, "channel-check", 20, time.Minute, middleware.KeyByUser), channelHandler.CheckUsername)
 	protected.Get("/channels", channelHandler.SearchChannels)
 	protected.Get("/channels/:id", channelHandler.GetChannel)
 	protected.Get("/channels/:id/stats", channelHandler.GetStats)
+	protected.Get("/channels/:id/rating", channelHandler.GetChannelRating)
+	protected.Get("/channels/:id/stats/history", channelHandler.GetStatsHistory)
+	protected.Post("/channels/:id/stats/refresh", channelHandler.RefreshStats)
+	protected.Get("/channels/:id/stats/history/export", middleware.RateLimit(rdb, "stats-export", 5, time.Minute, middleware.KeyByUserPath), channelHandler.ExportStatsHistory)
 	protected.Post("/channels/:id/invite-bot", channelHandler.InviteBot)
 	protected.Post("/channels/:id/managers", channelHandler.AddManager)
+	protected.Delete("/channels/:id/managers/:userId", channelHandler.RemoveManager)
+	protected.Post("/channels/:id/transfer-ownership", channelHandler.TransferOwnership)
 	protected.Get("/channels/:id/admins", channelHandler.GetAdmins)
+	protected.Get("/channels/:id/availability", dealHandler.GetChannelAvailability)
 
 	// Explore (enriched channels with stats + listing)
-	protected.Get("/explore/channels", channelHandler.ExploreChannels)
+	protected.Get("/explore/channels", middleware.RateLimit(rdb, "explore", cfg.RateLimitExplore, time.Minute, middleware.KeyByUser), channelHandler.ExploreChannels)
+	protected.Post("/explore/compare", channelHandler.CompareChannels)
 
 	// Listings
 	protected.Put("/listings/:channelId", channelHandler.UpdateListing)
 	protected.Get("/listings/:channelId", channelHandler.GetListing)
+	protected.Post("/listings/:channelId/status", channelHandler.SetListingStatus)
 
 	// Campaigns
-	protected.Post("/campaigns", campaignHandler.CreateCampaign)
+	protected.Post("/campaigns", idempotent, campaignHandler.CreateCampaign)
 	protected.Get("/campaigns", campaignHandler.ListCampaigns)
 	protected.Get("/campaigns/:id", campaignHandler.GetCampaign)
+	protected.Get("/campaigns/:id/deals", campaignHandler.ListCampaignDeals)
+	protected.Get("/campaigns/:id/suggestions", campaignHandler.SuggestChannels)
 	protected.Put("/campaigns/:id", campaignHandler.UpdateCampaign)
 	protected.Delete("/campaigns/:id", campaignHandler.DeleteCampaign)
 
 	// Deals
-	protected.Post("/deals", dealHandler.CreateDeal)
+	protected.Post("/deals", middleware.RateLimit(rdb, "create-deal", cfg.RateLimitCreateDeal, time.Minute, middleware.KeyByUser), idempotent, dealHandler.CreateDeal)
 	protected.Get("/deals", dealHandler.ListDeals)
+	protected.Post("/deals/status", dealHandler.GetDealStatuses)
 	protected.Get("/deals/:id", dealHandler.GetDeal)
 	protected.Post("/deals/:id/submit", dealHandler.SubmitDeal)
 	protected.Post("/deals/:id/accept", dealHandler.AcceptDeal)
 	protected.Post("/deals/:id/reject", dealHandler.RejectDeal)
+	protected.Post("/deals/:id/counteroffer", dealHandler.Counteroffer)
+	protected.Get("/deals/:id/counteroffers", dealHandler.ListCounteroffers)
+	protected.Post("/deals/:id/counteroffer/accept", dealHandler.AcceptCounteroffer)
+	protected.Post("/deals/:id/counteroffer/reject", dealHandler.RejectCounteroffer)
 	protected.Post("/deals/:id/cancel", dealHandler.CancelDeal)
 	protected.Get("/deals/:id/creative", dealHandler.GetCreative)
-	protected.Post("/deals/:id/creative", dealHandler.SubmitCreative)
+	protected.Get("/deals/:id/creatives", dealHandler.GetCreatives)
+	protected.Post("/deals/:id/creative", idempotent, dealHandler.SubmitCreative)
 	protected.Post("/deals/:id/creative/approve", dealHandler.ApproveCreative)
 	protected.Post("/deals/:id/creative/request-changes", dealHandler.RequestCreativeChanges)
 	protected.Get("/deals/:id/events", dealHandler.GetDealEvents)
 	protected.Post("/deals/:id/post/mark-manual", dealHandler.MarkManualPost)
+	protected.Post("/deals/:id/post/schedule", dealHandler.SchedulePost)
 	protected.Post("/deals/:id/finance/set-withdraw-wallet", dealHandler.SetWithdrawWallet)
 	protected.Get("/deals/:id/payment", dealHandler.GetPaymentInfo)
+	protected.Post("/deals/:id/payment/resend", middleware.RateLimit(rdb, "payment-resend", 3, 10*time.Minute, middleware.KeyByUserPath), dealHandler.ResendPaymentInstructions)
+	protected.Post("/deals/:id/refund-address", dealHandler.RequestRefundAddress)
+	protected.Post("/deals/:id/reschedule", dealHandler.RescheduleDeal)
+	protected.Post("/deals/:id/dispute", dealHandler.OpenDispute)
+	protected.Post("/deals/:id/rating", dealHandler.RateDeal)
+	protected.Get("/deals/:id/ratings", dealHandler.GetDealRatings)
+	protected.Get("/advertisers/:id/rating", dealHandler.GetAdvertiserRating)
+
+	// Offers (owner-initiated deals)
+	protected.Post("/channels/:id/offers", offerHandler.CreateOffer)
+	protected.Get("/channels/:id/offers", offerHandler.ListChannelOffers)
+	protected.Get("/offers", offerHandler.ListOffers)
+	protected.Post("/offers/:id/accept", offerHandler.AcceptOffer)
+	protected.Post("/offers/:id/cancel", offerHandler.CancelOffer)
+
+	// Admin
+	admin := protected.Group("/admin", middleware.AdminMiddleware(cfg))
+	admin.Post("/deals/:id/force-release", adminHandler.ForceRelease)
+	admin.Post("/deals/:id/force-refund", adminHandler.ForceRefund)
+	admin.Post("/deals/:id/force-status", adminHandler.ForceStatus)
+	admin.Post("/deals/:id/escrow/match", adminHandler.ManualEscrowMatch)
+	admin.Post("/deals/:id/freeze", adminHandler.FreezePayout)
+	admin.Post("/deals/:id/unfreeze", adminHandler.UnfreezePayout)
+	admin.Post("/deals/:id/resolve-dispute", adminHandler.ResolveDispute)
+	admin.Get("/actions", adminHandler.ListPendingActions)
+	admin.Post("/actions/:id/approve", adminHandler.ApproveAction)
+	admin.Get("/channels/stats-failures", adminHandler.ListStatsFailures)
+	admin.Post("/channels/merge", adminHandler.MergeChannels)
+	admin.Get("/health/indexer", adminHandler.IndexerHealth)
+	admin.Get("/audit", adminHandler.QueryAudit)
+	admin.Get("/creatives/review", adminHandler.ListCreativesForReview)
+	admin.Post("/creatives/:id/approve", adminHandler.ApproveCreativeReview)
+	admin.Post("/creatives/:id/reject", adminHandler.RejectCreativeReview)
+	admin.Get("/refund-address-requests", adminHandler.ListRefundAddressRequests)
+	admin.Post("/refund-address-requests/:id/approve", adminHandler.ApproveRefundAddress)
+	admin.Post("/refund-address-requests/:id/reject", adminHandler.RejectRefundAddress)
+	admin.Get("/unmatched-payments", adminHandler.ListUnmatchedPayments)
 
 	// WebSocket
 	app.Use("/ws", handlers.WSUpgradeMiddleware())
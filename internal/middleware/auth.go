@@ -13,9 +13,11 @@ import (
 const (
 	CtxUserID         = "user_id"
 	CtxTelegramUserID = "telegram_user_id"
+	CtxClaims         = "jwt_claims"
 )
 
-func AuthMiddleware(cfg *config.Config, log *zap.Logger) fiber.Handler {
+// AuthMiddleware requires a valid, not revoked access token (see auth.ValidateAccessToken).
+func AuthMiddleware(cfg *config.Config, denylist auth.TokenDenylist, log *zap.Logger) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
@@ -27,7 +29,7 @@ func AuthMiddleware(cfg *config.Config, log *zap.Logger) fiber.Handler {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid authorization format"})
 		}
 
-		claims, err := auth.ParseJWT(cfg.JWTSecret, tokenStr)
+		claims, err := auth.ValidateAccessToken(c.Context(), cfg.JWTSecret, tokenStr, denylist, cfg.JWTLegacyTokens)
 		if err != nil {
 			log.Debug("jwt parse error", zap.Error(err))
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid or expired token"})
@@ -35,6 +37,7 @@ func AuthMiddleware(cfg *config.Config, log *zap.Logger) fiber.Handler {
 
 		c.Locals(CtxUserID, claims.UserID)
 		c.Locals(CtxTelegramUserID, claims.TelegramUserID)
+		c.Locals(CtxClaims, claims)
 
 		return c.Next()
 	}
@@ -50,6 +53,12 @@ func GetTelegramUserID(c *fiber.Ctx) int64 {
 	return id
 }
 
+// GetClaims returns the access token claims (jti, expiry) of the request.
+func GetClaims(c *fiber.Ctx) *auth.Claims {
+	claims, _ := c.Locals(CtxClaims).(*auth.Claims)
+	return claims
+}
+
 // AdminMiddleware requires admin telegram IDs
 func AdminMiddleware(cfg *config.Config) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// idempotencyLockTTL bounds how long a crashed request keeps its key locked
+	idempotencyLockTTL = 30 * time.Second
+)
+
+// idempotentResponse is what's stored for a key: enough to replay the first response.
+type idempotentResponse struct {
+	Route       string `json:"route"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// IdempotencyMiddleware replays the first successful (2xx) response of a request carrying an
+// Idempotency-Key header, so a client retrying a POST on a flaky connection doesn't create
+// the same thing twice. Keys are scoped per user and kept for ttl; a key reused on another
+// path is rejected. Requests without the header pass through. Redis errors fail open.
+// Must run after AuthMiddleware.
+func IdempotencyMiddleware(rdb *redis.Client, ttl time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		idemKey := c.Get(IdempotencyKeyHeader)
+		if idemKey == "" {
+			return c.Next()
+		}
+		if len(idemKey) > maxIdempotencyKeyLength {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength),
+			})
+		}
+
+		ctx := context.Background()
+		route := c.Method() + " " + c.Path()
+		key := fmt.Sprintf("idem:%s:%s", GetUserID(c), idemKey)
+
+		if replayed, err := replayIdempotent(ctx, rdb, c, key, route); err != nil || replayed {
+			return err
+		}
+
+		// Only one request per key at a time: a retry racing the original must not run twice
+		lockKey := key + ":lock"
+		locked, err := rdb.SetNX(ctx, lockKey, 1, idempotencyLockTTL).Result()
+		if err != nil {
+			return c.Next() // fail open
+		}
+		if !locked {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "a request with this Idempotency-Key is already in progress",
+			})
+		}
+		defer rdb.Del(ctx, lockKey)
+
+		// The original may have finished between the first lookup and taking the lock
+		if replayed, err := replayIdempotent(ctx, rdb, c, key, route); err != nil || replayed {
+			return err
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status < 200 || status >= 300 {
+			return nil
+		}
+		data, _ := json.Marshal(idempotentResponse{
+			Route:       route,
+			Status:      status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        append([]byte(nil), c.Response().Body()...),
+		})
+		rdb.Set(ctx, key, data, ttl)
+		return nil
+	}
+}
+
+// replayIdempotent writes the stored response for key, if any. Returns true if it did.
+func replayIdempotent(ctx context.Context, rdb *redis.Client, c *fiber.Ctx, key, route string) (bool, error) {
+	data, err := rdb.Get(ctx, key).Bytes()
+	if err != nil {
+		return false, nil // redis.Nil or fail open
+	}
+	var stored idempotentResponse
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return false, nil
+	}
+	if stored.Route != route {
+		return true, c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": fmt.Sprintf("%s was already used for %s", IdempotencyKeyHeader, stored.Route),
+		})
+	}
+
+	c.Set(IdempotencyReplayedHeader, "true")
+	if stored.ContentType != "" {
+		c.Set(fiber.HeaderContentType, stored.ContentType)
+	}
+	return true, c.Status(stored.Status).Send(stored.Body)
+}
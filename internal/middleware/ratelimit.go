@@ -2,28 +2,49 @@ package middleware
 
 import (
 	"context"
-	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/ads-marketplace/backend/internal/auth"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
-func RateLimitMiddleware(rdb *redis.Client, limit int, window time.Duration) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		key := fmt.Sprintf("rl:%s:%s", c.Path(), c.IP())
+// RateLimitKey picks who a request is counted against.
+type RateLimitKey func(c *fiber.Ctx) string
+
+// KeyByIP counts per client IP. Only for public routes: Telegram WebView traffic shares
+// proxy IPs across many users.
+func KeyByIP(c *fiber.Ctx) string {
+	return "ip:" + c.IP()
+}
+
+// KeyByUser counts per authenticated user, falling back to the IP before AuthMiddleware.
+func KeyByUser(c *fiber.Ctx) string {
+	if id := GetUserID(c); id != uuid.Nil {
+		return "user:" + id.String()
+	}
+	return KeyByIP(c)
+}
 
-		ctx := context.Background()
-		count, err := rdb.Incr(ctx, key).Result()
+// KeyByUserPath counts per user and request path, i.e. per resource (a deal, a channel).
+func KeyByUserPath(c *fiber.Ctx) string {
+	return KeyByUser(c) + ":" + c.Path()
+}
+
+// RateLimit allows limit requests per window for each key within scope (auth.Allow's fixed
+// window counter in Redis under rl:<scope>:<key>). Over the limit it answers 429 with
+// Retry-After. Redis errors fail open.
+func RateLimit(rdb *redis.Client, scope string, limit int, window time.Duration, key RateLimitKey) fiber.Handler {
+	store := auth.NewRedisAttemptStore(rdb)
+	return func(c *fiber.Ctx) error {
+		ok, retryAfter, err := auth.Allow(context.Background(), store, scope+":"+key(c), limit, window)
 		if err != nil {
 			return c.Next() // fail open
 		}
-
-		if count == 1 {
-			rdb.Expire(ctx, key, window)
-		}
-
-		if count > int64(limit) {
+		if !ok {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int((retryAfter+time.Second-1)/time.Second)))
 			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
 				"error": "rate limit exceeded",
 			})
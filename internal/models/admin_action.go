@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// High-risk admin actions (fund movements)
+const (
+	AdminActionForceRelease      = "force_release"
+	AdminActionForceRefund       = "force_refund"
+	AdminActionManualEscrowMatch = "manual_escrow_match"
+	AdminActionResolveDispute    = "resolve_dispute"
+	AdminActionForceStatus       = "force_status" // any status, bypassing the transition rules
+)
+
+const (
+	AdminActionStatusPending  = "pending"
+	AdminActionStatusExecuted = "executed"
+	AdminActionStatusFailed   = "failed"
+)
+
+// PendingAdminAction is a risky admin operation waiting for a second admin's approval.
+type PendingAdminAction struct {
+	ID          uuid.UUID      `json:"id"`
+	Action      string         `json:"action"`
+	EntityType  string         `json:"entity_type"`
+	EntityID    uuid.UUID      `json:"entity_id"`
+	Params      map[string]any `json:"params,omitempty"`
+	Status      string         `json:"status"`
+	RequestedBy uuid.UUID      `json:"requested_by"`
+	ApprovedBy  *uuid.UUID     `json:"approved_by,omitempty"`
+	Error       *string        `json:"error,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	ApprovedAt  *time.Time     `json:"approved_at,omitempty"`
+}
@@ -16,3 +16,58 @@ type AuditLog struct {
 	Meta        any        `json:"meta,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 }
+
+// RedactedValue replaces personal data removed from audit entries.
+const RedactedValue = "[redacted]"
+
+// auditPIIKeys are meta keys that identify a person and are dropped on account deletion.
+// Deal/channel/escrow ids, amounts and on-chain data are retained for financial integrity.
+var auditPIIKeys = map[string]bool{
+	"telegram_user_id": true,
+	"telegram_id":      true,
+	"username":         true,
+	"first_name":       true,
+	"last_name":        true,
+}
+
+// RedactAuditMeta removes a user's PII from decoded audit meta: known PII keys are replaced,
+// and any value equal to the user's UUID or telegram id is pseudonymized. Reports whether
+// anything changed.
+func RedactAuditMeta(meta any, userID uuid.UUID, telegramID int64) (any, bool) {
+	switch v := meta.(type) {
+	case map[string]any:
+		changed := false
+		for k, val := range v {
+			if auditPIIKeys[k] {
+				if val != nil && val != RedactedValue {
+					v[k] = RedactedValue
+					changed = true
+				}
+				continue
+			}
+			if redacted, ok := RedactAuditMeta(val, userID, telegramID); ok {
+				v[k] = redacted
+				changed = true
+			}
+		}
+		return v, changed
+	case []any:
+		changed := false
+		for i, val := range v {
+			if redacted, ok := RedactAuditMeta(val, userID, telegramID); ok {
+				v[i] = redacted
+				changed = true
+			}
+		}
+		return v, changed
+	case string:
+		if v == userID.String() {
+			return RedactedValue, true
+		}
+	case float64:
+		if telegramID != 0 && v == float64(telegramID) {
+			return RedactedValue, true
+		}
+	}
+	return meta, false
+}
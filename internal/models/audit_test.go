@@ -0,0 +1,65 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRedactAuditMeta(t *testing.T) {
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	otherID := "22222222-2222-2222-2222-222222222222"
+	const tgID = 123456789
+
+	tests := []struct {
+		name     string
+		meta     any
+		expected any
+		changed  bool
+	}{
+		{"nil meta", nil, nil, false},
+		{
+			"unrelated entry untouched",
+			map[string]any{"deal_id": otherID, "price_ton": "10"},
+			map[string]any{"deal_id": otherID, "price_ton": "10"},
+			false,
+		},
+		{
+			"pii keys redacted",
+			map[string]any{"username": "alice", "telegram_user_id": float64(tgID), "deal_id": otherID},
+			map[string]any{"username": RedactedValue, "telegram_user_id": RedactedValue, "deal_id": otherID},
+			true,
+		},
+		{
+			"user id and telegram id values pseudonymized",
+			map[string]any{"requested_by": userID.String(), "approved_by": otherID, "chat": float64(tgID)},
+			map[string]any{"requested_by": RedactedValue, "approved_by": otherID, "chat": RedactedValue},
+			true,
+		},
+		{
+			"nested values",
+			map[string]any{"params": map[string]any{"members": []any{userID.String(), otherID}}},
+			map[string]any{"params": map[string]any{"members": []any{RedactedValue, otherID}}},
+			true,
+		},
+		{
+			"already redacted",
+			map[string]any{"username": RedactedValue},
+			map[string]any{"username": RedactedValue},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, changed := RedactAuditMeta(tt.meta, userID, tgID)
+			if changed != tt.changed {
+				t.Errorf("changed = %v, want %v", changed, tt.changed)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("RedactAuditMeta() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
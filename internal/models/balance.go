@@ -0,0 +1,63 @@
+package models
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WithdrawalStatusPending = "pending"
+	WithdrawalStatusSending = "sending" // claimed by the worker, transfer in flight
+	WithdrawalStatusSent    = "sent"
+	WithdrawalStatusFailed  = "failed"
+)
+
+// Withdrawal pays out the owner's accumulated balance in one transfer: native TON, or a
+// jetton transfer for USDT.
+type Withdrawal struct {
+	ID            uuid.UUID `json:"id"`
+	UserID        uuid.UUID `json:"user_id"`
+	AmountTON     string    `json:"amount_ton"`
+	Currency      string    `json:"currency"` // TON / USDT, the balance it was withdrawn from
+	WalletAddress string    `json:"wallet_address"`
+	Status        string    `json:"status"`
+	TxHash        *string   `json:"tx_hash,omitempty"`
+	TxComment     *string   `json:"tx_comment,omitempty"` // comment sent with the payout transfer
+	FailureReason *string   `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Earnings is the owner's balance view: credited completed deals minus withdrawals.
+type Earnings struct {
+	BalanceTON   string `json:"balance_ton"`
+	MinPayoutTON string `json:"min_payout_ton"`
+	CanWithdraw  bool   `json:"can_withdraw"`
+	Currency     string `json:"currency"`
+}
+
+// Balance sums up the owner's earnings in one currency: what completed deals credited, what was
+// withdrawn and what's left, plus deals on their channels that are funded but not completed.
+type Balance struct {
+	TotalEarned string `json:"total_earned"`
+	Withdrawn   string `json:"withdrawn"`
+	Available   string `json:"available"`
+	Pending     string `json:"pending"`
+	Currency    string `json:"currency"`
+}
+
+// BalanceExceedsMinPayout reports whether a withdrawal is allowed: the balance must be
+// strictly above the threshold. Unparsable amounts never allow a withdrawal.
+func BalanceExceedsMinPayout(balanceTON, minPayoutTON string) bool {
+	balance, ok := new(big.Rat).SetString(balanceTON)
+	if !ok || balance.Sign() <= 0 {
+		return false
+	}
+	min, ok := new(big.Rat).SetString(minPayoutTON)
+	if !ok {
+		return false
+	}
+	return balance.Cmp(min) > 0
+}
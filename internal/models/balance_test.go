@@ -0,0 +1,29 @@
+package models
+
+import "testing"
+
+func TestBalanceExceedsMinPayout(t *testing.T) {
+	tests := []struct {
+		name      string
+		balance   string
+		minPayout string
+		expected  bool
+	}{
+		{"above threshold", "1.5", "1", true},
+		{"equal to threshold", "1.000000000", "1", false},
+		{"below threshold", "0.25", "1", false},
+		{"zero threshold, positive balance", "0.000000001", "0", true},
+		{"zero balance", "0", "0", false},
+		{"negative balance", "-1", "0", false},
+		{"invalid balance", "abc", "1", false},
+		{"invalid threshold", "5", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BalanceExceedsMinPayout(tt.balance, tt.minPayout); got != tt.expected {
+				t.Errorf("BalanceExceedsMinPayout(%q, %q) = %v, want %v", tt.balance, tt.minPayout, got, tt.expected)
+			}
+		})
+	}
+}
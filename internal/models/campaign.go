@@ -6,6 +6,12 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	CampaignStatusActive = "active"
+	// Archived campaigns are "deleted" by the advertiser: hidden from lists by default
+	CampaignStatusArchived = "archived"
+)
+
 type Campaign struct {
 	ID               uuid.UUID  `json:"id"`
 	AdvertiserUserID uuid.UUID  `json:"advertiser_user_id"`
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -38,6 +39,21 @@ const (
 
 var AllAdFormats = []string{AdFormatPost, AdFormatRepost, AdFormatStory}
 
+// Listing statuses. Only active listings show up in search/explore and accept new deals.
+const (
+	ListingStatusDraft  = "draft"
+	ListingStatusActive = "active"
+	ListingStatusPaused = "paused"
+)
+
+func IsValidListingStatus(s string) bool {
+	switch s {
+	case ListingStatusDraft, ListingStatusActive, ListingStatusPaused:
+		return true
+	}
+	return false
+}
+
 func IsValidAdFormat(f string) bool {
 	for _, af := range AllAdFormats {
 		if af == f {
@@ -58,6 +74,10 @@ type ChannelListing struct {
 	PriceStoryTON      *string   `json:"price_story_ton,omitempty"`
 	FormatsEnabled     []string  `json:"formats_enabled"` // ["post", "repost", "story"]
 	MinLeadTimeMinutes int       `json:"min_lead_time_minutes"`
+	// Lead time по формату (минуты); nil — берём MinLeadTimeMinutes
+	MinLeadPostMinutes   *int `json:"min_lead_post_minutes,omitempty"`
+	MinLeadRepostMinutes *int `json:"min_lead_repost_minutes,omitempty"`
+	MinLeadStoryMinutes  *int `json:"min_lead_story_minutes,omitempty"`
 	Description        *string   `json:"description,omitempty"`
 	Category           *string   `json:"category,omitempty"`
 	Language           *string   `json:"language,omitempty"`
@@ -66,10 +86,20 @@ type ChannelListing struct {
 	HoldHoursRepost    int       `json:"hold_hours_repost"`
 	HoldHoursStory     int       `json:"hold_hours_story"`
 	AutoAccept         bool      `json:"auto_accept"`
+	// Owner's half of the creative-approval opt-out; the advertiser opts in per deal
+	AllowSkipCreativeApproval bool      `json:"allow_skip_creative_approval"`
+	// Edited post during the hold → refund (otherwise the edit is only flagged)
+	RefundOnEdit       bool      `json:"refund_on_edit"`
 	CreatedAt          time.Time `json:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at"`
 }
 
+// AcceptsDeals reports whether advertisers can open new deals against the listing;
+// draft and paused listings are not live.
+func (l *ChannelListing) AcceptsDeals() bool {
+	return l.Status == ListingStatusActive
+}
+
 // GetPriceForFormat возвращает цену для указанного формата.
 func (l *ChannelListing) GetPriceForFormat(format string) *string {
 	switch format {
@@ -98,6 +128,29 @@ func (l *ChannelListing) GetHoldHoursForFormat(format string) int {
 	}
 }
 
+// GetMinLeadForFormat возвращает минимальный lead time (в минутах) для формата,
+// с fallback на общий MinLeadTimeMinutes.
+func (l *ChannelListing) GetMinLeadForFormat(format string) int {
+	var v *int
+	switch format {
+	case AdFormatPost:
+		v = l.MinLeadPostMinutes
+	case AdFormatRepost:
+		v = l.MinLeadRepostMinutes
+	case AdFormatStory:
+		v = l.MinLeadStoryMinutes
+	}
+	if v == nil {
+		return l.MinLeadTimeMinutes
+	}
+	return *v
+}
+
+// EarliestScheduleAt is the earliest posting time the listing accepts for the format at now.
+func (l *ChannelListing) EarliestScheduleAt(format string, now time.Time) time.Time {
+	return now.Add(time.Duration(l.GetMinLeadForFormat(format)) * time.Minute)
+}
+
 // IsFormatEnabled проверяет, включён ли формат.
 func (l *ChannelListing) IsFormatEnabled(format string) bool {
 	for _, f := range l.FormatsEnabled {
@@ -114,6 +167,8 @@ type ChannelStatsSnapshot struct {
 	FetchedAt     time.Time `json:"fetched_at"`
 	Subscribers   *int      `json:"subscribers,omitempty"`
 	VerifiedBadge bool      `json:"verified_badge"`
+	ScamBadge     bool      `json:"scam_badge"`
+	FakeBadge     bool      `json:"fake_badge"`
 	AvgViews20    *int      `json:"avg_views_20,omitempty"`
 	LastPostID    *int64    `json:"last_post_id,omitempty"`
 	PremiumCount  *int      `json:"premium_count,omitempty"`
@@ -130,4 +185,101 @@ type ChannelStatsSnapshot struct {
 	SharesPerPost               *float64 `json:"shares_per_post,omitempty"`
 	EnabledNotificationsPercent *float64 `json:"enabled_notifications_percent,omitempty"`
 	ERPercent                   *float64 `json:"er_percent,omitempty"`
+	// Derived from dated posts on the t.me page
+	PostFrequency *float64 `json:"post_frequency,omitempty"` // posts per day
+	HasPinnedPost *bool    `json:"has_pinned_post,omitempty"`
+}
+
+// StatsHistoryPoint is one snapshot row of a channel's stats time series.
+type StatsHistoryPoint struct {
+	FetchedAt   time.Time `json:"fetched_at"`
+	Subscribers *int      `json:"subscribers,omitempty"`
+	AvgViews    *int      `json:"avg_views,omitempty"`
+	ERPercent   *float64  `json:"er_percent,omitempty"`
+	Growth      *int      `json:"growth,omitempty"`
+}
+
+// StatsHistoryCSVHeader is the column order of the stats history CSV export.
+var StatsHistoryCSVHeader = []string{"fetched_at", "subscribers", "avg_views", "er_percent", "growth"}
+
+// CSVRecord renders the point in StatsHistoryCSVHeader order; missing values are empty cells.
+func (p StatsHistoryPoint) CSVRecord() []string {
+	intCell := func(v *int) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.Itoa(*v)
+	}
+	er := ""
+	if p.ERPercent != nil {
+		er = strconv.FormatFloat(*p.ERPercent, 'f', -1, 64)
+	}
+	return []string{
+		p.FetchedAt.UTC().Format(time.RFC3339),
+		intCell(p.Subscribers),
+		intCell(p.AvgViews),
+		er,
+		intCell(p.Growth),
+	}
+}
+
+// ChannelStatsFailure tracks consecutive stats refresh failures for a channel.
+type ChannelStatsFailure struct {
+	ChannelID           uuid.UUID  `json:"channel_id"`
+	Username            string     `json:"username"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           *string    `json:"last_error,omitempty"`
+	LastFailedAt        *time.Time `json:"last_failed_at,omitempty"`
+	NextAttemptAt       *time.Time `json:"next_attempt_at,omitempty"`
+}
+
+// Subscriber growth windows reported on stats snapshots.
+const (
+	GrowthWindow7d  = 7 * 24 * time.Hour
+	GrowthWindow30d = 30 * 24 * time.Hour
+)
+
+// SubscriberGrowth returns the subscriber change over window, measured against base — the
+// stored snapshot nearest to now-window. It is nil when base is missing or its age is off
+// the window by more than a seventh (not enough history yet, or a gap), so a few hours of
+// growth never gets reported as a week.
+func SubscriberGrowth(current *int, now time.Time, base *StatsHistoryPoint, window time.Duration) *int {
+	if current == nil || base == nil || base.Subscribers == nil {
+		return nil
+	}
+	age := now.Sub(base.FetchedAt)
+	if diff := age - window; diff > window/7 || diff < -window/7 {
+		return nil
+	}
+	growth := *current - *base.Subscribers
+	return &growth
+}
+
+// maxStatsBackoffFactor caps the refresh backoff at 16× the base interval.
+const maxStatsBackoffFactor = 16
+
+// StatsRetryBackoff returns how long to wait before the next refresh attempt after
+// `failures` consecutive failures: base, 2×base, 4×base … up to 16×base.
+func StatsRetryBackoff(base time.Duration, failures int) time.Duration {
+	if failures <= 1 {
+		return base
+	}
+	factor := 1
+	for i := 1; i < failures && factor < maxStatsBackoffFactor; i++ {
+		factor *= 2
+	}
+	return base * time.Duration(factor)
+}
+
+// NextUserbotStatus decides the stored userbot_status after a userbot stats attempt.
+// Success (re)activates the userbot; an active one is demoted to failed after
+// maxFailures consecutive errors so later cycles go straight to the t.me parser.
+func NextUserbotStatus(current string, ok bool, consecutiveFailures, maxFailures int) string {
+	if ok {
+		return "active"
+	}
+	if current == "active" && maxFailures > 0 && consecutiveFailures >= maxFailures {
+		return "failed"
+	}
+	return current
 }
@@ -0,0 +1,211 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestGetMinLeadForFormat(t *testing.T) {
+	listing := ChannelListing{
+		MinLeadTimeMinutes:  60,
+		MinLeadPostMinutes:  intPtr(360),
+		MinLeadStoryMinutes: intPtr(0),
+	}
+
+	tests := []struct {
+		format   string
+		expected int
+	}{
+		{AdFormatPost, 360},
+		{AdFormatRepost, 60}, // not set — falls back to the single value
+		{AdFormatStory, 0},   // explicit zero is respected
+		{"unknown", 60},
+	}
+
+	for _, tt := range tests {
+		if got := listing.GetMinLeadForFormat(tt.format); got != tt.expected {
+			t.Errorf("GetMinLeadForFormat(%q) = %d, want %d", tt.format, got, tt.expected)
+		}
+	}
+}
+
+func TestEarliestScheduleAt(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	listing := ChannelListing{MinLeadTimeMinutes: 90, MinLeadStoryMinutes: intPtr(0)}
+
+	if got, want := listing.EarliestScheduleAt(AdFormatPost, now), now.Add(90*time.Minute); !got.Equal(want) {
+		t.Errorf("post: got %s, want %s", got, want)
+	}
+	if got := listing.EarliestScheduleAt(AdFormatStory, now); !got.Equal(now) {
+		t.Errorf("story with zero lead: got %s, want %s", got, now)
+	}
+}
+
+func TestStatsRetryBackoff(t *testing.T) {
+	base := 6 * time.Hour
+
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{0, base},
+		{1, base},
+		{2, 2 * base},
+		{3, 4 * base},
+		{5, 16 * base},
+		{50, 16 * base}, // capped
+	}
+
+	for _, tt := range tests {
+		if got := StatsRetryBackoff(base, tt.failures); got != tt.expected {
+			t.Errorf("StatsRetryBackoff(%d) = %v, want %v", tt.failures, got, tt.expected)
+		}
+	}
+}
+
+func TestNextUserbotStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     string
+		ok          bool
+		failures    int
+		maxFailures int
+		expected    string
+	}{
+		{"success keeps active", "active", true, 0, 3, "active"},
+		{"first failure stays active", "active", false, 1, 3, "active"},
+		{"below threshold stays active", "active", false, 2, 3, "active"},
+		{"flips after N failures", "active", false, 3, 3, "failed"},
+		{"beyond threshold", "active", false, 7, 3, "failed"},
+		{"threshold disabled", "active", false, 10, 0, "active"},
+		{"re-probe success reactivates", "failed", true, 0, 3, "active"},
+		{"re-probe failure stays failed", "failed", false, 1, 3, "failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NextUserbotStatus(tt.current, tt.ok, tt.failures, tt.maxFailures); got != tt.expected {
+				t.Errorf("NextUserbotStatus(%q, %v, %d, %d) = %q, want %q",
+					tt.current, tt.ok, tt.failures, tt.maxFailures, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStatsHistoryPointCSVRecord(t *testing.T) {
+	subs, views, growth := 1200, 340, -15
+	er := 4.25
+	at := time.Date(2025, 3, 1, 12, 30, 0, 0, time.FixedZone("MSK", 3*3600))
+
+	tests := []struct {
+		name     string
+		point    StatsHistoryPoint
+		expected []string
+	}{
+		{
+			name:     "all values",
+			point:    StatsHistoryPoint{FetchedAt: at, Subscribers: &subs, AvgViews: &views, ERPercent: &er, Growth: &growth},
+			expected: []string{"2025-03-01T09:30:00Z", "1200", "340", "4.25", "-15"},
+		},
+		{
+			name:     "missing values are empty cells",
+			point:    StatsHistoryPoint{FetchedAt: at, Subscribers: &subs},
+			expected: []string{"2025-03-01T09:30:00Z", "1200", "", "", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.point.CSVRecord()
+			if len(got) != len(StatsHistoryCSVHeader) {
+				t.Fatalf("got %d columns, header has %d", len(got), len(StatsHistoryCSVHeader))
+			}
+			for i := range tt.expected {
+				if got[i] != tt.expected[i] {
+					t.Errorf("column %s = %q, want %q", StatsHistoryCSVHeader[i], got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIsValidListingStatus(t *testing.T) {
+	tests := []struct {
+		status   string
+		expected bool
+	}{
+		{ListingStatusDraft, true},
+		{ListingStatusActive, true},
+		{ListingStatusPaused, true},
+		{"", false},
+		{"archived", false},
+		{"Active", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsValidListingStatus(tt.status); got != tt.expected {
+			t.Errorf("IsValidListingStatus(%q) = %v, want %v", tt.status, got, tt.expected)
+		}
+	}
+}
+
+func TestListingAcceptsDeals(t *testing.T) {
+	tests := []struct {
+		status   string
+		expected bool
+	}{
+		{ListingStatusDraft, false},
+		{ListingStatusPaused, false},
+		{ListingStatusActive, true},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		l := ChannelListing{Status: tt.status}
+		if got := l.AcceptsDeals(); got != tt.expected {
+			t.Errorf("AcceptsDeals() with status %q = %v, want %v", tt.status, got, tt.expected)
+		}
+	}
+}
+
+func TestSubscriberGrowth(t *testing.T) {
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	current := 1500
+	point := func(age time.Duration, subs int) *StatsHistoryPoint {
+		return &StatsHistoryPoint{FetchedAt: now.Add(-age), Subscribers: &subs}
+	}
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name     string
+		current  *int
+		base     *StatsHistoryPoint
+		window   time.Duration
+		expected *int
+	}{
+		{"7d exact", &current, point(7*day, 1200), GrowthWindow7d, intPtr(300)},
+		{"7d within tolerance", &current, point(7*day+12*time.Hour, 1600), GrowthWindow7d, intPtr(-100)},
+		{"not enough history: newest baseline is 6h old", &current, point(6*time.Hour, 1490), GrowthWindow7d, nil},
+		{"not enough history for 30d", &current, point(8*day, 1000), GrowthWindow30d, nil},
+		{"30d with a slightly late snapshot", &current, point(29*day, 900), GrowthWindow30d, intPtr(600)},
+		{"baseline far older than the window", &current, point(20*day, 1000), GrowthWindow7d, nil},
+		{"no baseline", &current, nil, GrowthWindow7d, nil},
+		{"no current count", nil, point(7*day, 1200), GrowthWindow7d, nil},
+		{"baseline without subscribers", &current, &StatsHistoryPoint{FetchedAt: now.Add(-7 * day)}, GrowthWindow7d, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SubscriberGrowth(tt.current, now, tt.base, tt.window)
+			switch {
+			case got == nil && tt.expected == nil:
+			case got == nil || tt.expected == nil:
+				t.Errorf("SubscriberGrowth() = %v, want %v", got, tt.expected)
+			case *got != *tt.expected:
+				t.Errorf("SubscriberGrowth() = %d, want %d", *got, *tt.expected)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package models
 
 import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,6 +26,8 @@ const (
 	DealStatusPosted                  = "posted"
 	DealStatusHoldVerification        = "hold_verification"
 	DealStatusHoldVerificationFailed  = "hold_verification_failed"
+	DealStatusDisputed                = "disputed"
+	DealStatusReleaseFailed           = "release_failed"
 	DealStatusCompleted               = "completed"
 	DealStatusRefunded                = "refunded"
 	DealStatusCancelled               = "cancelled"
@@ -40,14 +46,169 @@ var ValidDealTransitions = map[string][]string{
 	DealStatusCreativeChangesRequested: {DealStatusCreativeSubmitted, DealStatusCancelled},
 	DealStatusCreativeApproved:         {DealStatusScheduled, DealStatusPosted},
 	DealStatusScheduled:                {DealStatusPosted, DealStatusCancelled},
-	DealStatusPosted:                   {DealStatusHoldVerification},
-	DealStatusHoldVerification:         {DealStatusCompleted, DealStatusHoldVerificationFailed},
+	DealStatusPosted:                   {DealStatusHoldVerification, DealStatusDisputed},
+	DealStatusHoldVerification:         {DealStatusCompleted, DealStatusHoldVerificationFailed, DealStatusDisputed, DealStatusReleaseFailed},
 	DealStatusHoldVerificationFailed:   {DealStatusRefunded},
+	DealStatusDisputed:                 {DealStatusCompleted, DealStatusRefunded}, // resolved by an admin
+	DealStatusReleaseFailed:            {DealStatusCompleted, DealStatusRefunded}, // admin force-release / force-refund
 	DealStatusCompleted:                {},
 	DealStatusRefunded:                 {},
 	DealStatusCancelled:                {DealStatusRefunded},
 }
 
+// DealStatusesSettled are statuses with nothing left to post; funds may still be held if
+// escrow is funded (e.g. cancelled awaiting refund), so callers must check escrow too.
+var DealStatusesSettled = []string{
+	DealStatusDraft,
+	DealStatusRejected,
+	DealStatusCancelled,
+	DealStatusRefunded,
+	DealStatusCompleted,
+}
+
+// DealStatusesPaid are statuses a deal only reaches once its escrow is funded: from them the
+// funds are released to the owner or refunded to the advertiser.
+var DealStatusesPaid = []string{
+	DealStatusFunded,
+	DealStatusCreativePending,
+	DealStatusCreativeSubmitted,
+	DealStatusCreativeChangesRequested,
+	DealStatusCreativeApproved,
+	DealStatusScheduled,
+	DealStatusPosted,
+	DealStatusHoldVerification,
+	DealStatusHoldVerificationFailed,
+	DealStatusDisputed,
+	DealStatusReleaseFailed,
+}
+
+// CreativeSubmitPath returns the statuses a deal walks through once a creative clears
+// moderation: creative_submitted, then straight on to creative_approved when both
+// parties opted out of the approval loop.
+func CreativeSubmitPath(skipApproval bool) []string {
+	if skipApproval {
+		return []string{DealStatusCreativeSubmitted, DealStatusCreativeApproved}
+	}
+	return []string{DealStatusCreativeSubmitted}
+}
+
+// SubmitPath returns the statuses a draft walks through when the advertiser submits it:
+// submitted, then straight on to accepted when the listing auto-accepts deals.
+func SubmitPath(autoAccept bool) []string {
+	if autoAccept {
+		return []string{DealStatusSubmitted, DealStatusAccepted}
+	}
+	return []string{DealStatusSubmitted}
+}
+
+// DealTimeoutCutoff is the updated_at before which a deal in a status with the given
+// timeout counts as timed out. A deal updated exactly at the cutoff is not timed out yet.
+func DealTimeoutCutoff(now time.Time, timeoutSeconds int) time.Time {
+	return now.Add(-time.Duration(timeoutSeconds) * time.Second)
+}
+
+// PostCheckInterval is how long to wait between checks of a post that has been live
+// for sincePosted: an eighth of its age, clamped to [minInterval, maxInterval].
+// Свежие посты (когда удаления наиболее вероятны) проверяются часто, под конец холда — редко.
+func PostCheckInterval(sincePosted, minInterval, maxInterval time.Duration) time.Duration {
+	interval := sincePosted / 8
+	if interval < minInterval {
+		return minInterval
+	}
+	if interval > maxInterval {
+		return maxInterval
+	}
+	return interval
+}
+
+// ReleaseRetryDelay is how long the hold-release job waits after the n-th failed release
+// attempt: base doubling per attempt, capped at max. Mirrors the backoff in
+// DealRepo.GetPostedDealsInHold.
+func ReleaseRetryDelay(attempts int, base, max time.Duration) time.Duration {
+	if attempts <= 0 {
+		return 0
+	}
+	d := base
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= max {
+			return max
+		}
+	}
+	if d > max {
+		return max
+	}
+	return d
+}
+
+// PostCheckDue reports whether a posted deal should be checked at now.
+// A post that was never checked (or has no posted_at) is always due.
+func PostCheckDue(postedAt, lastCheckedAt *time.Time, now time.Time, minInterval, maxInterval time.Duration) bool {
+	if postedAt == nil || lastCheckedAt == nil {
+		return true
+	}
+	interval := PostCheckInterval(now.Sub(*postedAt), minInterval, maxInterval)
+	return !now.Before(lastCheckedAt.Add(interval))
+}
+
+// StoryLifetime is how long Telegram keeps a story up; disappearing after that is expected.
+const StoryLifetime = 24 * time.Hour
+
+var storyURLRe = regexp.MustCompile(`^https?://t\.me/[A-Za-z0-9_]+/s/(\d+)/?$`)
+
+// ParseStoryURL extracts the story id from a link like https://t.me/username/s/123.
+func ParseStoryURL(u string) (int64, bool) {
+	m := storyURLRe.FindStringSubmatch(u)
+	if m == nil {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+var postURLRe = regexp.MustCompile(`^https?://t\.me/([A-Za-z0-9_]+)/(\d+)/?(\?.*)?$`)
+
+// ParsePostURL extracts the channel username and message id from a link like
+// https://t.me/username/123 (a ?single or similar query is allowed).
+func ParsePostURL(u string) (string, int64, bool) {
+	m := postURLRe.FindStringSubmatch(u)
+	if m == nil {
+		return "", 0, false
+	}
+	id, err := strconv.ParseInt(m[2], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return m[1], id, true
+}
+
+// StoryExpired reports whether the post is a story past its natural 24h lifetime, so it
+// being gone is not a deletion.
+func (p *DealPost) StoryExpired(now time.Time) bool {
+	return p.StoryExpiresAt != nil && !now.Before(*p.StoryExpiresAt)
+}
+
+// Dispute outcomes chosen by the admin resolving a disputed deal.
+const (
+	DisputeOutcomeRelease = "release"
+	DisputeOutcomeRefund  = "refund"
+)
+
+// AutoReleasable reports whether the hold-release job may release the deal's funds:
+// it must be in hold_verification and not frozen by support.
+func (d *Deal) AutoReleasable() bool {
+	return d.Status == DealStatusHoldVerification && !d.PayoutFrozen
+}
+
+// IsKnownDealStatus reports whether s is a deal status at all (terminal ones included).
+func IsKnownDealStatus(s string) bool {
+	_, ok := ValidDealTransitions[s]
+	return ok
+}
+
 func IsValidTransition(from, to string) bool {
 	allowed, ok := ValidDealTransitions[from]
 	if !ok {
@@ -70,10 +231,16 @@ type Deal struct {
 	Brief             *string    `json:"brief,omitempty"`
 	ScheduledAt       *time.Time `json:"scheduled_at,omitempty"`
 	PriceTON          string     `json:"price_ton"` // numeric as string
+	Currency          string     `json:"currency"`  // not stored; set by the service
 	PlatformFeeBPS    int        `json:"platform_fee_bps"`
 	HoldPeriodSeconds int        `json:"hold_period_seconds"`
-	CreatedAt         time.Time  `json:"created_at"`
-	UpdatedAt         time.Time  `json:"updated_at"`
+	// Both parties opted out of creative approval: a creative that clears moderation is auto-approved
+	SkipCreativeApproval bool      `json:"skip_creative_approval"`
+	// Frozen by support pending investigation: stays in hold_verification, never auto-released
+	PayoutFrozen bool       `json:"payout_frozen"`
+	CampaignID   *uuid.UUID `json:"campaign_id,omitempty"` // campaign the deal fulfils, if any
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
 }
 
 // DealWithChannel embeds Deal and adds channel info to avoid N+1 queries.
@@ -83,6 +250,25 @@ type DealWithChannel struct {
 	ChannelUsername *string `json:"channel_username,omitempty"`
 }
 
+// Counteroffer statuses
+const (
+	CounterofferStatusPending    = "pending"
+	CounterofferStatusAccepted   = "accepted"
+	CounterofferStatusRejected   = "rejected"
+	CounterofferStatusSuperseded = "superseded"
+)
+
+// DealCounteroffer is a price the channel side proposes for a submitted deal.
+type DealCounteroffer struct {
+	ID         uuid.UUID  `json:"id"`
+	DealID     uuid.UUID  `json:"deal_id"`
+	ProposedBy uuid.UUID  `json:"proposed_by"`
+	PriceTON   string     `json:"price_ton"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
 type DealCreative struct {
 	ID                      uuid.UUID `json:"id"`
 	DealID                  uuid.UUID `json:"deal_id"`
@@ -96,9 +282,33 @@ type DealCreative struct {
 	RepostFromURL           *string   `json:"repost_from_url,omitempty"`
 	MediaURLs               any       `json:"media_urls,omitempty"`  // []string
 	ButtonsJSON             any       `json:"buttons_json,omitempty"` // [{text, url}]
+	ModerationReason        *string   `json:"moderation_reason,omitempty"`
 	CreatedAt               time.Time `json:"created_at"`
 }
 
+// Limits on a creative's attachments: an album holds at most 10 media and an inline
+// keyboard gets unwieldy past 8 buttons.
+const (
+	MaxCreativeMedia   = 10
+	MaxCreativeButtons = 8
+)
+
+// CreativeButton is an inline URL button under the post.
+type CreativeButton struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// ValidLinkURL reports whether u is an absolute http(s) URL with a host (t.me links
+// included); anything else, like javascript: or tg:, is rejected.
+func ValidLinkURL(u string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(u))
+	if err != nil || parsed.Host == "" || parsed.User != nil {
+		return false
+	}
+	return parsed.Scheme == "https" || parsed.Scheme == "http"
+}
+
 type DealPost struct {
 	ID                uuid.UUID  `json:"id"`
 	DealID            uuid.UUID  `json:"deal_id"`
@@ -112,4 +322,6 @@ type DealPost struct {
 	IsEdited          bool       `json:"is_edited"`
 	AdFormat          *string    `json:"ad_format,omitempty"`
 	StoryExpiresAt    *time.Time `json:"story_expires_at,omitempty"`
+	StoryID           *int64     `json:"story_id,omitempty"`
+	StoryViews        *int       `json:"story_views,omitempty"` // last count reported by the userbot
 }
@@ -11,12 +11,33 @@ const (
 	EscrowStatusFunded   = "funded"
 	EscrowStatusReleased = "released"
 	EscrowStatusRefunded = "refunded"
+	EscrowStatusExpired  = "expired" // deal cancelled before payment; late payments are not credited
 )
 
+// Escrow currencies. USDT is paid as a jetton transfer to the hot wallet.
+const (
+	EscrowCurrencyTON  = "TON"
+	EscrowCurrencyUSDT = "USDT"
+)
+
+// CurrencyDecimals returns the number of decimals of the currency's smallest unit
+// (nanoTON for TON, 6 for the USDT jetton). Unknown currencies return -1.
+func CurrencyDecimals(currency string) int {
+	switch currency {
+	case EscrowCurrencyTON:
+		return 9
+	case EscrowCurrencyUSDT:
+		return 6
+	default:
+		return -1
+	}
+}
+
 type EscrowLedger struct {
 	ID                 uuid.UUID  `json:"id"`
 	DealID             uuid.UUID  `json:"deal_id"`
 	DepositExpectedTON string     `json:"deposit_expected_ton"`
+	Currency           string     `json:"currency"` // TON / USDT
 	DepositAddress     string     `json:"deposit_address"`
 	DepositMemo        string     `json:"deposit_memo"`
 	FundedAt           *time.Time `json:"funded_at,omitempty"`
@@ -26,5 +47,78 @@ type EscrowLedger struct {
 	ReleaseTxHash      *string    `json:"release_tx_hash,omitempty"`
 	RefundedAt         *time.Time `json:"refunded_at,omitempty"`
 	RefundTxHash       *string    `json:"refund_tx_hash,omitempty"`
+	RefundAddress      *string    `json:"refund_address,omitempty"`
+	OverpaidNano       *string    `json:"overpaid_nano,omitempty"` // excess over the expected amount, smallest units
+	RefundStatus       *string    `json:"refund_status,omitempty"` // pending / sending / sent / failed, nil if no refund
 	Status             string     `json:"status"`
 }
+
+// RefundDestination returns the admin-approved refund address, or the payer address by default.
+func (e *EscrowLedger) RefundDestination() string {
+	if e.RefundAddress != nil && *e.RefundAddress != "" {
+		return *e.RefundAddress
+	}
+	if e.PayerAddress != nil {
+		return *e.PayerAddress
+	}
+	return ""
+}
+
+// Escrow refund progress: queued, claimed for sending, sent (escrow refunded), or failed
+// after the claim (the transfer may still land, support checks the chain).
+const (
+	EscrowRefundPending = "pending"
+	EscrowRefundSending = "sending"
+	EscrowRefundSent    = "sent"
+	EscrowRefundFailed  = "failed"
+)
+
+const (
+	RefundAddressStatusPending  = "pending"
+	RefundAddressStatusApproved = "approved"
+	RefundAddressStatusRejected = "rejected"
+)
+
+// RefundAddressRequest is an advertiser's request to refund escrow to a wallet other than
+// the payer. The address must be the requester's TON Proof verified wallet; an admin approves it.
+type RefundAddressRequest struct {
+	ID          uuid.UUID  `json:"id"`
+	DealID      uuid.UUID  `json:"deal_id"`
+	RequestedBy uuid.UUID  `json:"requested_by"`
+	WalletID    uuid.UUID  `json:"wallet_id"`
+	Address     string     `json:"address"`
+	Status      string     `json:"status"`
+	ReviewedBy  *uuid.UUID `json:"reviewed_by,omitempty"`
+	Reason      *string    `json:"reason,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+}
+
+const (
+	// Pending payments carry a deal memo whose escrow doesn't exist yet; the indexer
+	// retries them until MatchDeadline, then they become unmatched
+	UnmatchedPaymentStatusPending   = "pending"
+	UnmatchedPaymentStatusUnmatched = "unmatched"
+	UnmatchedPaymentStatusMatched   = "matched"
+)
+
+// UnmatchedPayment is an incoming transfer whose memo matched no escrow when the indexer saw it.
+// The indexer (pending) and then the worker (unmatched) retry the match, the escrow may be created
+// right after the payment; staff credit the rest manually via the escrow match endpoint with TxLT
+// as the tx reference.
+type UnmatchedPayment struct {
+	ID          uuid.UUID  `json:"id"`
+	TxLT        int64      `json:"tx_lt"`
+	TxHash      string     `json:"tx_hash"`
+	FromAddress string     `json:"from_address"`
+	Amount      string     `json:"amount"` // smallest units of Currency
+	Currency    string     `json:"currency"`
+	Memo        string     `json:"memo"`
+	Status      string     `json:"status"`
+	DealID      *uuid.UUID `json:"deal_id,omitempty"`
+	MatchedBy   *uuid.UUID `json:"matched_by,omitempty"` // nil when matched by the worker
+	CreatedAt   time.Time  `json:"created_at"`
+	MatchedAt   *time.Time `json:"matched_at,omitempty"`
+	// Pending only: when the indexer gives up and escalates it to unmatched
+	MatchDeadline *time.Time `json:"match_deadline,omitempty"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "testing"
+
+func TestRefundDestination(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name     string
+		escrow   EscrowLedger
+		expected string
+	}{
+		{"defaults to payer", EscrowLedger{PayerAddress: str("EQpayer")}, "EQpayer"},
+		{"approved override", EscrowLedger{PayerAddress: str("EQpayer"), RefundAddress: str("UQalt")}, "UQalt"},
+		{"empty override ignored", EscrowLedger{PayerAddress: str("EQpayer"), RefundAddress: str("")}, "EQpayer"},
+		{"not funded yet", EscrowLedger{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.escrow.RefundDestination(); got != tt.expected {
+				t.Errorf("RefundDestination() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	OfferStatusOpen      = "open"
+	OfferStatusAccepted  = "accepted"
+	OfferStatusCancelled = "cancelled"
+)
+
+// DealOffer is an owner-initiated slot at fixed terms. Public offers (no AdvertiserUserID)
+// can be accepted by any advertiser; accepting instantiates a deal with these terms.
+type DealOffer struct {
+	ID               uuid.UUID  `json:"id"`
+	ChannelID        uuid.UUID  `json:"channel_id"`
+	CreatedBy        uuid.UUID  `json:"created_by"`
+	AdvertiserUserID *uuid.UUID `json:"advertiser_user_id,omitempty"`
+	AdFormat         string     `json:"ad_format"`
+	PriceTON         string     `json:"price_ton"`
+	Brief            *string    `json:"brief,omitempty"`
+	ScheduledAt      *time.Time `json:"scheduled_at,omitempty"`
+	ValidUntil       time.Time  `json:"valid_until"`
+	Status           string     `json:"status"`
+	DealID           *uuid.UUID `json:"deal_id,omitempty"`
+	AcceptedBy       *uuid.UUID `json:"accepted_by,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	AcceptedAt       *time.Time `json:"accepted_at,omitempty"`
+}
+
+// CanBeAcceptedBy checks that the offer is open, not expired and addressed to the user.
+func (o *DealOffer) CanBeAcceptedBy(userID uuid.UUID, now time.Time) error {
+	if o.Status != OfferStatusOpen {
+		return fmt.Errorf("offer is %s", o.Status)
+	}
+	if !now.Before(o.ValidUntil) {
+		return fmt.Errorf("offer expired at %s", o.ValidUntil.Format(time.RFC3339))
+	}
+	if o.AdvertiserUserID != nil && *o.AdvertiserUserID != userID {
+		return fmt.Errorf("offer is addressed to another advertiser")
+	}
+	if o.CreatedBy == userID {
+		return fmt.Errorf("cannot accept your own offer")
+	}
+	return nil
+}
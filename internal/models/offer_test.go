@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDealOfferCanBeAcceptedBy(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	owner := uuid.New()
+	advertiser := uuid.New()
+	other := uuid.New()
+
+	tests := []struct {
+		name    string
+		offer   DealOffer
+		user    uuid.UUID
+		wantErr bool
+	}{
+		{"public offer", DealOffer{CreatedBy: owner, Status: OfferStatusOpen, ValidUntil: now.Add(time.Hour)}, other, false},
+		{"targeted to user", DealOffer{CreatedBy: owner, AdvertiserUserID: &advertiser, Status: OfferStatusOpen, ValidUntil: now.Add(time.Hour)}, advertiser, false},
+		{"targeted to someone else", DealOffer{CreatedBy: owner, AdvertiserUserID: &advertiser, Status: OfferStatusOpen, ValidUntil: now.Add(time.Hour)}, other, true},
+		{"expired", DealOffer{CreatedBy: owner, Status: OfferStatusOpen, ValidUntil: now.Add(-time.Minute)}, other, true},
+		{"expires exactly now", DealOffer{CreatedBy: owner, Status: OfferStatusOpen, ValidUntil: now}, other, true},
+		{"already accepted", DealOffer{CreatedBy: owner, Status: OfferStatusAccepted, ValidUntil: now.Add(time.Hour)}, other, true},
+		{"cancelled", DealOffer{CreatedBy: owner, Status: OfferStatusCancelled, ValidUntil: now.Add(time.Hour)}, other, true},
+		{"own offer", DealOffer{CreatedBy: owner, Status: OfferStatusOpen, ValidUntil: now.Add(time.Hour)}, owner, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.offer.CanBeAcceptedBy(tt.user, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CanBeAcceptedBy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Who a deal rating is about: the advertiser rates the channel, the channel owner
+// rates the advertiser.
+const (
+	RateeChannel    = "channel"
+	RateeAdvertiser = "advertiser"
+)
+
+const (
+	MinRatingStars = 1
+	MaxRatingStars = 5
+	// MaxRatingCommentLen caps the free-text comment, in runes.
+	MaxRatingCommentLen = 1000
+)
+
+// DealRating is one side's rating of the other after a completed deal.
+type DealRating struct {
+	ID          uuid.UUID `json:"id"`
+	DealID      uuid.UUID `json:"deal_id"`
+	RaterUserID uuid.UUID `json:"rater_user_id"`
+	RateeType   string    `json:"ratee_type"`
+	Stars       int       `json:"stars"`
+	Comment     *string   `json:"comment,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RatingSummary aggregates the ratings of a channel or an advertiser. Average is nil
+// until the first rating.
+type RatingSummary struct {
+	Average *float64 `json:"average,omitempty"` // 1..5, 2 decimals
+	Count   int      `json:"count"`
+}
+
+// ValidRatingStars reports whether stars is within MinRatingStars..MaxRatingStars.
+func ValidRatingStars(stars int) bool {
+	return stars >= MinRatingStars && stars <= MaxRatingStars
+}
@@ -0,0 +1,22 @@
+package models
+
+import "testing"
+
+func TestValidRatingStars(t *testing.T) {
+	tests := []struct {
+		stars int
+		want  bool
+	}{
+		{0, false},
+		{1, true},
+		{3, true},
+		{5, true},
+		{6, false},
+		{-1, false},
+	}
+	for _, tt := range tests {
+		if got := ValidRatingStars(tt.stars); got != tt.want {
+			t.Errorf("ValidRatingStars(%d) = %v, want %v", tt.stars, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// Statuses in which a deal no longer holds its scheduled slot.
+// Drafts don't reserve anything until submitted.
+var slotFreeStatuses = map[string]bool{
+	DealStatusDraft:                  true,
+	DealStatusRejected:               true,
+	DealStatusCancelled:              true,
+	DealStatusRefunded:               true,
+	DealStatusHoldVerificationFailed: true,
+}
+
+// SlotStatusesFree returns the statuses that don't occupy a slot (for SQL filters).
+func SlotStatusesFree() []string {
+	out := make([]string, 0, len(slotFreeStatuses))
+	for s := range slotFreeStatuses {
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// SlotConflicts reports whether a post at `at` would fall within `slot` of any taken time.
+func SlotConflicts(at time.Time, taken []time.Time, slot time.Duration) bool {
+	for _, t := range taken {
+		d := at.Sub(t)
+		if d < 0 {
+			d = -d
+		}
+		if d < slot {
+			return true
+		}
+	}
+	return false
+}
+
+// NextAvailableSlot returns the earliest time >= earliest that doesn't conflict with taken slots.
+func NextAvailableSlot(earliest time.Time, taken []time.Time, slot time.Duration) time.Time {
+	sorted := make([]time.Time, len(taken))
+	copy(sorted, taken)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	candidate := earliest
+	for _, t := range sorted {
+		if candidate.Sub(t) >= slot {
+			continue // taken slot is well before the candidate
+		}
+		if t.Sub(candidate) >= slot {
+			break // gap before this taken slot is wide enough
+		}
+		candidate = t.Add(slot)
+	}
+	return candidate
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextAvailableSlot(t *testing.T) {
+	base := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	at := func(h float64) time.Time { return base.Add(time.Duration(h * float64(time.Hour))) }
+	slot := time.Hour
+
+	tests := []struct {
+		name     string
+		earliest time.Time
+		taken    []time.Time
+		expected time.Time
+	}{
+		{"no taken slots", at(0), nil, at(0)},
+		{"taken well after", at(0), []time.Time{at(3)}, at(0)},
+		{"taken well before", at(5), []time.Time{at(1)}, at(5)},
+		{"exactly at taken", at(0), []time.Time{at(0)}, at(1)},
+		{"overlaps from before", at(0), []time.Time{at(-0.5)}, at(0.5)},
+		{"chain of taken slots", at(0), []time.Time{at(2), at(0.5), at(1.5)}, at(2 + 1)},
+		{"gap between taken slots", at(0), []time.Time{at(0), at(3)}, at(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NextAvailableSlot(tt.earliest, tt.taken, slot)
+			if !result.Equal(tt.expected) {
+				t.Errorf("NextAvailableSlot() = %v, want %v", result, tt.expected)
+			}
+			if SlotConflicts(result, tt.taken, slot) {
+				t.Errorf("NextAvailableSlot() = %v conflicts with taken slots", result)
+			}
+		})
+	}
+}
+
+func TestSlotConflicts(t *testing.T) {
+	base := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	taken := []time.Time{base}
+
+	tests := []struct {
+		at       time.Time
+		expected bool
+	}{
+		{base, true},
+		{base.Add(59 * time.Minute), true},
+		{base.Add(-59 * time.Minute), true},
+		{base.Add(time.Hour), false},
+		{base.Add(-time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		if got := SlotConflicts(tt.at, taken, time.Hour); got != tt.expected {
+			t.Errorf("SlotConflicts(%v) = %v, want %v", tt.at, got, tt.expected)
+		}
+	}
+}
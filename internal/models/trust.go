@@ -0,0 +1,100 @@
+package models
+
+import "math"
+
+// TrustSignals are the per-channel inputs of the trust score. Nil means "no data":
+// the component is left out and the remaining weights are renormalized.
+type TrustSignals struct {
+	VerifiedBadge  bool
+	Flagged        bool // scam/fake label — zeroes the score
+	CompletedDeals int
+	AvgRating      *float64 // 1..5
+	ERPercent      *float64
+	Subscribers    *int
+	AvgViews       *int
+}
+
+// TrustWeights are the relative weights of the score components (config-driven).
+type TrustWeights struct {
+	Verified    int
+	Deals       int
+	Rating      int
+	ER          int
+	Consistency int
+}
+
+// Saturation points: a component reaches 1.0 at these values.
+const (
+	TrustDealsSaturation = 10   // completed deals
+	TrustERSaturation    = 10.0 // ER, %
+	// avg_views/subscribers outside this band looks like a dead audience (below)
+	// or bought views (above)
+	TrustMinReach = 0.05
+	TrustMaxReach = 0.6
+)
+
+// TrustScore combines the signals into 0..100:
+//
+//	score = 100 × Σ wᵢ·cᵢ / Σ wᵢ   over components with data, each cᵢ ∈ [0,1]
+//
+//	verified    = 1 with the Telegram verified badge, else 0
+//	deals       = min(completed / TrustDealsSaturation, 1)
+//	rating      = (avg − 1) / 4
+//	er          = min(er% / TrustERSaturation, 1)
+//	consistency = 1 if reach = views/subs ∈ [TrustMinReach, TrustMaxReach],
+//	              else reach/TrustMinReach below the band, TrustMaxReach/reach above it
+//
+// rounded to 2 decimals. A flagged channel scores 0 regardless of the rest.
+func TrustScore(s TrustSignals, w TrustWeights) float64 {
+	if s.Flagged {
+		return 0
+	}
+
+	var sum, total float64
+	add := func(weight int, c float64) {
+		if weight <= 0 {
+			return
+		}
+		sum += float64(weight) * clamp01(c)
+		total += float64(weight)
+	}
+
+	verified := 0.0
+	if s.VerifiedBadge {
+		verified = 1
+	}
+	add(w.Verified, verified)
+	add(w.Deals, float64(s.CompletedDeals)/TrustDealsSaturation)
+	if s.AvgRating != nil {
+		add(w.Rating, (*s.AvgRating-1)/4)
+	}
+	if s.ERPercent != nil {
+		add(w.ER, *s.ERPercent/TrustERSaturation)
+	}
+	if s.Subscribers != nil && s.AvgViews != nil && *s.Subscribers > 0 {
+		reach := float64(*s.AvgViews) / float64(*s.Subscribers)
+		switch {
+		case reach < TrustMinReach:
+			add(w.Consistency, reach/TrustMinReach)
+		case reach > TrustMaxReach:
+			add(w.Consistency, TrustMaxReach/reach)
+		default:
+			add(w.Consistency, 1)
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return math.Round(10000*sum/total) / 100
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
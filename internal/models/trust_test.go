@@ -0,0 +1,67 @@
+package models
+
+import "testing"
+
+func TestTrustScore(t *testing.T) {
+	w := TrustWeights{Verified: 15, Deals: 25, Rating: 25, ER: 15, Consistency: 20}
+	f := func(v float64) *float64 { return &v }
+	i := func(v int) *int { return &v }
+
+	tests := []struct {
+		name     string
+		signals  TrustSignals
+		expected float64
+	}{
+		{"no data at all", TrustSignals{}, 0},
+		{
+			"everything maxed",
+			TrustSignals{VerifiedBadge: true, CompletedDeals: 12, AvgRating: f(5), ERPercent: f(15), Subscribers: i(10000), AvgViews: i(3000)},
+			100,
+		},
+		{
+			"flagged overrides",
+			TrustSignals{VerifiedBadge: true, Flagged: true, CompletedDeals: 12, AvgRating: f(5), ERPercent: f(15), Subscribers: i(10000), AvgViews: i(3000)},
+			0,
+		},
+		{
+			// rating missing → weights renormalized over 15+25+15+20 = 75
+			// deals 5/10·25 = 12.5, er 5/10·15 = 7.5, consistency 20 → 40/75
+			"no rating, half deals and ER",
+			TrustSignals{CompletedDeals: 5, ERPercent: f(5), Subscribers: i(10000), AvgViews: i(1000)},
+			53.33,
+		},
+		{
+			// reach 0.01 → 0.01/0.05 = 0.2 consistency → (15 + 0 + 4) / (15+25+20)
+			"dead audience",
+			TrustSignals{VerifiedBadge: true, Subscribers: i(10000), AvgViews: i(100)},
+			31.67,
+		},
+		{
+			// reach 1.2 → 0.6/1.2 = 0.5 → 10/(15+25+20)
+			"views exceed subscribers",
+			TrustSignals{Subscribers: i(1000), AvgViews: i(1200)},
+			16.67,
+		},
+		{
+			// rating 3 → 0.5·25 = 12.5 over verified+deals+rating = 65
+			"average rating only",
+			TrustSignals{AvgRating: f(3)},
+			19.23,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TrustScore(tt.signals, w); got != tt.expected {
+				t.Errorf("TrustScore() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTrustScoreZeroWeightsIgnored(t *testing.T) {
+	w := TrustWeights{Verified: 1}
+	if got := TrustScore(TrustSignals{VerifiedBadge: true, CompletedDeals: 0}, w); got != 100 {
+		t.Errorf("TrustScore() = %v, want 100", got)
+	}
+}
@@ -12,6 +12,33 @@ type User struct {
 	Username       *string   `json:"username,omitempty"`
 	FirstName      *string   `json:"first_name,omitempty"`
 	LastName       *string   `json:"last_name,omitempty"`
-	CreatedAt      time.Time `json:"created_at"`
-	LastActiveAt   time.Time `json:"last_active_at"`
+	LanguageCode   *string   `json:"language_code,omitempty"`
+	PhotoURL       *string   `json:"photo_url,omitempty"`
+	// Bot notifications about deals; replies the user asked for (e.g. payment instructions) are always sent
+	NotificationsEnabled bool      `json:"notifications_enabled"`
+	CreatedAt            time.Time `json:"created_at"`
+	LastActiveAt         time.Time `json:"last_active_at"`
+}
+
+// Recipient is the Telegram user a bot notification goes to, with their language_code
+// (empty if unknown).
+type Recipient struct {
+	TelegramID           int64
+	LanguageCode         string
+	NotificationsEnabled bool
+}
+
+// NotifyTelegramID is the id to send an unsolicited notification to: 0 if the user opted out.
+func (r Recipient) NotifyTelegramID() int64 {
+	if !r.NotificationsEnabled {
+		return 0
+	}
+	return r.TelegramID
+}
+
+// UserBlock is a hard block: the blocker refuses deals with the blocked user.
+type UserBlock struct {
+	BlockerID uuid.UUID `json:"blocker_id"`
+	BlockedID uuid.UUID `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
 }
@@ -21,6 +21,21 @@ type UserWallet struct {
 	ConnectedAt     time.Time  `json:"connected_at"`
 	DisconnectedAt  *time.Time `json:"disconnected_at,omitempty"`
 	IsActive        bool       `json:"is_active"`
+	IsPrimary       bool       `json:"is_primary"`
+	Label           *string    `json:"label,omitempty"` // e.g. "deposit", "withdraw"
+}
+
+// MaxWalletLabelLength caps UserWallet.Label.
+const MaxWalletLabelLength = 64
+
+// FindWallet returns the wallet among ws whose raw or user-friendly address is address, or nil.
+func FindWallet(ws []UserWallet, address string) *UserWallet {
+	for i := range ws {
+		if address != "" && (ws[i].Address == address || ws[i].AddressFriendly == address) {
+			return &ws[i]
+		}
+	}
+	return nil
 }
 
 type TonProofPayload struct {
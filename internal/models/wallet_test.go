@@ -0,0 +1,43 @@
+package models
+
+import "testing"
+
+func TestFindWallet(t *testing.T) {
+	wallets := []UserWallet{
+		{Address: "0:aaa", AddressFriendly: "UQdeposit", Verified: true, IsPrimary: true},
+		{Address: "0:bbb", AddressFriendly: "UQwithdraw", Verified: true},
+		{Address: "0:ccc", AddressFriendly: "UQunverified"},
+	}
+
+	tests := []struct {
+		name    string
+		address string
+		want    string // raw address of the match, "" for none
+	}{
+		{"primary by friendly", "UQdeposit", "0:aaa"},
+		{"second verified by friendly", "UQwithdraw", "0:bbb"},
+		{"second verified by raw", "0:bbb", "0:bbb"},
+		{"unverified still found", "UQunverified", "0:ccc"},
+		{"foreign address", "UQsomeoneelse", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindWallet(wallets, tt.address)
+			if tt.want == "" {
+				if got != nil {
+					t.Errorf("FindWallet(%q) = %s, want nil", tt.address, got.Address)
+				}
+				return
+			}
+			if got == nil || got.Address != tt.want {
+				t.Errorf("FindWallet(%q) = %v, want %s", tt.address, got, tt.want)
+			}
+		})
+	}
+
+	if FindWallet(nil, "UQdeposit") != nil {
+		t.Error("FindWallet(nil) should be nil")
+	}
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook is a URL a user registered to be called when one of their deals reaches a
+// terminal state. The secret is shown once, on registration.
+type Webhook struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is one attempt to deliver an event, kept for debugging integrations.
+type WebhookDelivery struct {
+	ID         uuid.UUID `json:"id"`
+	WebhookID  uuid.UUID `json:"webhook_id"`
+	DealID     uuid.UUID `json:"deal_id"`
+	Event      string    `json:"event"`
+	Attempt    int       `json:"attempt"`
+	StatusCode *int      `json:"status_code,omitempty"`
+	Error      *string   `json:"error,omitempty"`
+	Delivered  bool      `json:"delivered"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// QueuedWebhookDelivery is an event in the webhook outbox, waiting for its next attempt.
+type QueuedWebhookDelivery struct {
+	ID       uuid.UUID
+	Webhook  Webhook
+	DealID   uuid.UUID
+	Event    string
+	Payload  []byte
+	Attempts int // attempts made so far
+}
+
+// WebhookEvents are the deal statuses a webhook can subscribe to.
+var WebhookEvents = []string{
+	DealStatusCompleted,
+	DealStatusRefunded,
+	DealStatusCancelled,
+}
+
+// NormalizeWebhookEvents lowercases and dedupes the requested events; none means all of
+// WebhookEvents. Returns false if any event is unknown.
+func NormalizeWebhookEvents(events []string) ([]string, bool) {
+	if len(events) == 0 {
+		return slices.Clone(WebhookEvents), true
+	}
+	var out []string
+	for _, e := range events {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if !slices.Contains(WebhookEvents, e) {
+			return nil, false
+		}
+		if !slices.Contains(out, e) {
+			out = append(out, e)
+		}
+	}
+	return out, true
+}
+
+// ValidWebhookURL reports whether u can be registered: an absolute https URL whose host
+// is not localhost or a private/loopback IP literal. Hostnames resolving to private
+// addresses are refused when dialing.
+func ValidWebhookURL(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Scheme != "https" || parsed.User != nil {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil && !PublicIP(ip) {
+		return false
+	}
+	return true
+}
+
+// PublicIP reports whether ip is routable on the internet, i.e. safe to call out to.
+func PublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsMulticast())
+}
@@ -0,0 +1,149 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Decisions
+const (
+	DecisionAllow  = "allow"
+	DecisionReview = "review"
+	DecisionReject = "reject"
+)
+
+type Result struct {
+	Decision string `json:"decision"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Content is what gets checked: creative text plus every URL it links to (buttons, media, repost).
+type Content struct {
+	Text string   `json:"text"`
+	URLs []string `json:"urls"`
+}
+
+// Moderator checks creatives against a config-driven keyword/domain blocklist and,
+// if configured, an external moderation webhook.
+type Moderator struct {
+	keywords   []string
+	domains    []string
+	onMatch    string // review or reject
+	webhookURL string
+	httpClient *http.Client
+	log        *zap.Logger
+}
+
+func NewModerator(keywords, domains []string, onMatch, webhookURL string, log *zap.Logger) *Moderator {
+	if onMatch != DecisionReject {
+		onMatch = DecisionReview
+	}
+	lower := func(in []string) []string {
+		out := make([]string, 0, len(in))
+		for _, s := range in {
+			if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return &Moderator{
+		keywords:   lower(keywords),
+		domains:    lower(domains),
+		onMatch:    onMatch,
+		webhookURL: webhookURL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		log:        log,
+	}
+}
+
+// Check returns the strictest decision of the blocklist and the webhook.
+// A webhook failure sends the creative to manual review rather than letting it through.
+func (m *Moderator) Check(ctx context.Context, c Content) Result {
+	if reason, ok := MatchBlocklist(c, m.keywords, m.domains); ok {
+		return Result{Decision: m.onMatch, Reason: reason}
+	}
+
+	if m.webhookURL == "" {
+		return Result{Decision: DecisionAllow}
+	}
+
+	res, err := m.callWebhook(ctx, c)
+	if err != nil {
+		m.log.Warn("moderation webhook failed, sending to review", zap.Error(err))
+		return Result{Decision: DecisionReview, Reason: "moderation service unavailable"}
+	}
+	return res
+}
+
+func (m *Moderator) callWebhook(ctx context.Context, c Content) (Result, error) {
+	body, _ := json.Marshal(c)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("moderation webhook returned %d", resp.StatusCode)
+	}
+
+	var res Result
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return Result{}, err
+	}
+	switch res.Decision {
+	case DecisionAllow, DecisionReview, DecisionReject:
+		return res, nil
+	default:
+		return Result{}, fmt.Errorf("unknown moderation decision %q", res.Decision)
+	}
+}
+
+var urlRE = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"']+`)
+
+// ExtractURLs returns the http(s) links found in text.
+func ExtractURLs(text string) []string {
+	return urlRE.FindAllString(text, -1)
+}
+
+// MatchBlocklist reports the first blocked keyword (case-insensitive substring of the text)
+// or blocked domain (exact host or any subdomain of it) found in the content.
+func MatchBlocklist(c Content, keywords, domains []string) (string, bool) {
+	text := strings.ToLower(c.Text)
+	for _, kw := range keywords {
+		if strings.Contains(text, kw) {
+			return fmt.Sprintf("blocked keyword %q", kw), true
+		}
+	}
+
+	urls := append(ExtractURLs(c.Text), c.URLs...)
+	for _, raw := range urls {
+		u, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil || u.Host == "" {
+			continue
+		}
+		host := strings.ToLower(u.Hostname())
+		for _, d := range domains {
+			if host == d || strings.HasSuffix(host, "."+d) {
+				return fmt.Sprintf("blocked domain %q", d), true
+			}
+		}
+	}
+	return "", false
+}
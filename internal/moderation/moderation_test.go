@@ -0,0 +1,40 @@
+package moderation
+
+import "testing"
+
+func TestMatchBlocklist(t *testing.T) {
+	keywords := []string{"casino", "free crypto"}
+	domains := []string{"scam.io", "bad.example.com"}
+
+	tests := []struct {
+		name    string
+		content Content
+		blocked bool
+	}{
+		{"clean", Content{Text: "Subscribe to our tech channel", URLs: []string{"https://t.me/tech"}}, false},
+		{"keyword case-insensitive", Content{Text: "Best CASINO bonuses"}, true},
+		{"multi-word keyword", Content{Text: "Get FREE Crypto now"}, true},
+		{"domain in text", Content{Text: "visit https://scam.io/offer today"}, true},
+		{"subdomain in button", Content{URLs: []string{"https://promo.scam.io"}}, true},
+		{"lookalike domain not matched", Content{URLs: []string{"https://notscam.io"}}, false},
+		{"nested blocked domain", Content{URLs: []string{"http://x.bad.example.com/a"}}, true},
+		{"parent of blocked domain allowed", Content{URLs: []string{"https://example.com"}}, false},
+		{"invalid url ignored", Content{URLs: []string{"::::"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, blocked := MatchBlocklist(tt.content, keywords, domains)
+			if blocked != tt.blocked {
+				t.Errorf("MatchBlocklist() = %v (%s), want %v", blocked, reason, tt.blocked)
+			}
+		})
+	}
+}
+
+func TestExtractURLs(t *testing.T) {
+	urls := ExtractURLs(`Join https://t.me/chan and http://example.com/path?q=1 now`)
+	if len(urls) != 2 || urls[0] != "https://t.me/chan" || urls[1] != "http://example.com/path?q=1" {
+		t.Errorf("ExtractURLs() = %v", urls)
+	}
+}
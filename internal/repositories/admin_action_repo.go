@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AdminActionRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewAdminActionRepo(pool *pgxpool.Pool) *AdminActionRepo {
+	return &AdminActionRepo{pool: pool}
+}
+
+func (r *AdminActionRepo) Create(ctx context.Context, a *models.PendingAdminAction) error {
+	paramsBytes, _ := json.Marshal(a.Params)
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO pending_admin_actions (action, entity_type, entity_id, params, status, requested_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, a.Action, a.EntityType, a.EntityID, paramsBytes, a.Status, a.RequestedBy).Scan(&a.ID, &a.CreatedAt)
+}
+
+func (r *AdminActionRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.PendingAdminAction, error) {
+	var a models.PendingAdminAction
+	var paramsBytes []byte
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, action, entity_type, entity_id, params, status, requested_by, approved_by, error, created_at, approved_at
+		FROM pending_admin_actions WHERE id = $1
+	`, id).Scan(&a.ID, &a.Action, &a.EntityType, &a.EntityID, &paramsBytes, &a.Status,
+		&a.RequestedBy, &a.ApprovedBy, &a.Error, &a.CreatedAt, &a.ApprovedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	_ = json.Unmarshal(paramsBytes, &a.Params)
+	return &a, nil
+}
+
+func (r *AdminActionRepo) ListByStatus(ctx context.Context, status string, limit, offset int) ([]models.PendingAdminAction, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, action, entity_type, entity_id, params, status, requested_by, approved_by, error, created_at, approved_at
+		FROM pending_admin_actions WHERE status = $1
+		ORDER BY created_at DESC LIMIT $2 OFFSET $3
+	`, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []models.PendingAdminAction
+	for rows.Next() {
+		var a models.PendingAdminAction
+		var paramsBytes []byte
+		if err := rows.Scan(&a.ID, &a.Action, &a.EntityType, &a.EntityID, &paramsBytes, &a.Status,
+			&a.RequestedBy, &a.ApprovedBy, &a.Error, &a.CreatedAt, &a.ApprovedAt); err != nil {
+			return nil, err
+		}
+		_ = json.Unmarshal(paramsBytes, &a.Params)
+		actions = append(actions, a)
+	}
+	return actions, nil
+}
+
+// Claim atomically records the approver on a pending action so that only one
+// admin can execute it. Fails with ErrNotFound if the action was already
+// claimed, is not pending, or the approver is the requester.
+func (r *AdminActionRepo) Claim(ctx context.Context, id, approvedBy uuid.UUID) error {
+	var claimedID uuid.UUID
+	err := r.pool.QueryRow(ctx, `
+		UPDATE pending_admin_actions
+		SET approved_by = $1, approved_at = now()
+		WHERE id = $2 AND status = 'pending' AND approved_by IS NULL AND requested_by <> $1
+		RETURNING id
+	`, approvedBy, id).Scan(&claimedID)
+	return notFound(err)
+}
+
+func (r *AdminActionRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string, errMsg *string) error {
+	_, err := r.pool.Exec(ctx, `UPDATE pending_admin_actions SET status = $1, error = $2 WHERE id = $3`, status, errMsg, id)
+	return err
+}
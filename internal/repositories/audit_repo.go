@@ -2,9 +2,13 @@ package repositories
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/ads-marketplace/backend/internal/models"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -36,8 +40,11 @@ func (r *AuditRepo) GetByEntity(ctx context.Context, entityType string, entityID
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return collectAuditLogs(rows)
+}
 
+func collectAuditLogs(rows pgx.Rows) ([]models.AuditLog, error) {
+	defer rows.Close()
 	var logs []models.AuditLog
 	for rows.Next() {
 		var l models.AuditLog
@@ -46,5 +53,162 @@ func (r *AuditRepo) GetByEntity(ctx context.Context, entityType string, entityID
 		}
 		logs = append(logs, l)
 	}
-	return logs, nil
+	return logs, rows.Err()
+}
+
+// AuditFilter narrows an audit trail query; nil fields don't filter. From is inclusive,
+// To exclusive.
+type AuditFilter struct {
+	ActorUserID  *uuid.UUID
+	ActionPrefix *string // "wallet_" matches wallet_connected, wallet_disconnected, ...
+	EntityType   *string
+	EntityID     *uuid.UUID
+	From         *time.Time
+	To           *time.Time
+	Limit        int
+	Offset       int
+}
+
+// AuditPage is one page of an audit query with the number of entries matching the filter.
+type AuditPage struct {
+	Items []models.AuditLog `json:"items"`
+	Total int               `json:"total"`
+}
+
+// auditWhere builds the WHERE clause (empty if f doesn't filter) and its args for f.
+func auditWhere(f AuditFilter) (string, []any) {
+	var where []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if f.ActorUserID != nil {
+		add("actor_user_id = $%d", *f.ActorUserID)
+	}
+	if f.ActionPrefix != nil && *f.ActionPrefix != "" {
+		add(`action LIKE $%d`, likePrefix(*f.ActionPrefix))
+	}
+	if f.EntityType != nil {
+		add("entity_type = $%d", *f.EntityType)
+	}
+	if f.EntityID != nil {
+		add("entity_id = $%d", *f.EntityID)
+	}
+	if f.From != nil {
+		add("created_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("created_at < $%d", *f.To)
+	}
+	if len(where) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(where, " AND "), args
+}
+
+// Query returns the audit entries matching f, newest first, with their total count.
+func (r *AuditRepo) Query(ctx context.Context, f AuditFilter) (*AuditPage, error) {
+	where, args := auditWhere(f)
+
+	page := &AuditPage{Items: []models.AuditLog{}}
+	if err := r.pool.QueryRow(ctx, `SELECT count(*) FROM audit_log`+where, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	limit := f.Limit
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	n := len(args)
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, actor_user_id, actor_type, action, entity_type, entity_id, meta, created_at
+		FROM audit_log`+where+fmt.Sprintf(`
+		ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d`, n+1, n+2),
+		append(args, limit, f.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := collectAuditLogs(rows)
+	if err != nil {
+		return nil, err
+	}
+	if logs != nil {
+		page.Items = logs
+	}
+	return page, nil
+}
+
+// RedactUser pseudonymizes a user's audit trail for account deletion: the actor is nulled,
+// entries about the user entity lose the id, and PII in meta is redacted (models.RedactAuditMeta).
+// Action records and deal/escrow linkage are kept. Must run before the users row is anonymized,
+// since the telegram id is read from it. Returns the number of entries changed.
+func (r *AuditRepo) RedactUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var telegramID int64
+	if err := tx.QueryRow(ctx, `SELECT COALESCE(telegram_user_id, 0) FROM users WHERE id = $1`, userID).Scan(&telegramID); err != nil {
+		return 0, notFound(err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, actor_user_id, entity_type, entity_id, meta
+		FROM audit_log
+		WHERE actor_user_id = $1
+		   OR (entity_type = 'user' AND entity_id = $1)
+		   OR meta::text LIKE '%' || $1::text || '%'
+		   OR ($2::bigint <> 0 AND meta::text LIKE '%' || $2::bigint::text || '%')
+		FOR UPDATE
+	`, userID, telegramID)
+	if err != nil {
+		return 0, err
+	}
+
+	type redaction struct {
+		id       uuid.UUID
+		entityID *uuid.UUID
+		meta     any
+	}
+	var updates []redaction
+	for rows.Next() {
+		var l models.AuditLog
+		if err := rows.Scan(&l.ID, &l.ActorUserID, &l.EntityType, &l.EntityID, &l.Meta); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		changed := l.ActorUserID != nil && *l.ActorUserID == userID
+		if l.EntityType == "user" && l.EntityID != nil && *l.EntityID == userID {
+			l.EntityID = nil
+			changed = true
+		}
+		meta, metaChanged := models.RedactAuditMeta(l.Meta, userID, telegramID)
+		if !changed && !metaChanged {
+			continue // LIKE matched a substring of an unrelated value
+		}
+		updates = append(updates, redaction{id: l.ID, entityID: l.EntityID, meta: meta})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, u := range updates {
+		if _, err := tx.Exec(ctx, `
+			UPDATE audit_log
+			SET actor_user_id = CASE WHEN actor_user_id = $1 THEN NULL ELSE actor_user_id END,
+			    entity_id = $2, meta = $3
+			WHERE id = $4
+		`, userID, u.entityID, u.meta, u.id); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(updates), nil
 }
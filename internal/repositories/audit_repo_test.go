@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestAuditWhere(t *testing.T) {
+	actor := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	prefix := "wallet_"
+	pct := "100%"
+	entity := "deal"
+
+	tests := []struct {
+		name      string
+		filter    AuditFilter
+		wantWhere string
+		wantArgs  []any
+	}{
+		{"no filter", AuditFilter{}, "", nil},
+		{"action prefix escapes LIKE wildcards", AuditFilter{ActionPrefix: &prefix}, " WHERE action LIKE $1", []any{`wallet\_%`}},
+		{"literal percent", AuditFilter{ActionPrefix: &pct}, " WHERE action LIKE $1", []any{`100\%%`}},
+		{
+			"all filters in order",
+			AuditFilter{ActorUserID: &actor, EntityType: &entity, From: &from, To: &to},
+			" WHERE actor_user_id = $1 AND entity_type = $2 AND created_at >= $3 AND created_at < $4",
+			[]any{actor, entity, from, to},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := auditWhere(tt.filter)
+			if where != tt.wantWhere {
+				t.Errorf("where = %q, want %q", where, tt.wantWhere)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type BalanceRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewBalanceRepo(pool *pgxpool.Pool) *BalanceRepo {
+	return &BalanceRepo{pool: pool}
+}
+
+const withdrawalColumns = `id, user_id, amount_ton::text, currency, wallet_address, status, tx_hash, tx_comment, failure_reason, created_at, updated_at`
+
+// GetBalance returns the user's balance in currency.
+func (r *BalanceRepo) GetBalance(ctx context.Context, userID uuid.UUID, currency string) (string, error) {
+	var balance string
+	err := r.pool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount_ton), 0)::text FROM balance_ledger WHERE user_id = $1 AND currency = $2
+	`, userID, currency).Scan(&balance)
+	return balance, err
+}
+
+// GetLedgerTotals returns what the user's balance in currency was credited (completed deals)
+// and debited (withdrawals) in total.
+func (r *BalanceRepo) GetLedgerTotals(ctx context.Context, userID uuid.UUID, currency string) (credited, debited string, err error) {
+	err = r.pool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount_ton) FILTER (WHERE amount_ton > 0), 0)::text,
+		       COALESCE(-SUM(amount_ton) FILTER (WHERE amount_ton < 0), 0)::text
+		FROM balance_ledger WHERE user_id = $1 AND currency = $2
+	`, userID, currency).Scan(&credited, &debited)
+	return credited, debited, err
+}
+
+// Withdraw moves amount ("" for the whole balance) of the user's currency balance into a
+// pending withdrawal and debits the ledger, in one transaction serialized per user. Fails
+// with ErrNotFound if the amount does not exceed minPayout or is more than the balance.
+func (r *BalanceRepo) Withdraw(ctx context.Context, userID uuid.UUID, walletAddress, amount, minPayout, currency string) (*models.Withdrawal, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Lock the user row so two concurrent requests can't both withdraw the same balance
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, userID); err != nil {
+		return nil, err
+	}
+
+	var w models.Withdrawal
+	err = tx.QueryRow(ctx, `
+		INSERT INTO withdrawals (user_id, amount_ton, currency, wallet_address)
+		SELECT $1, a.amount, $5, $2
+		FROM (SELECT COALESCE(SUM(amount_ton), 0) AS balance FROM balance_ledger WHERE user_id = $1 AND currency = $5) b,
+			LATERAL (SELECT COALESCE(NULLIF($4, '')::numeric, b.balance) AS amount) a
+		WHERE a.amount > $3::numeric AND a.amount <= b.balance
+		RETURNING `+withdrawalColumns+`
+	`, userID, walletAddress, minPayout, amount, currency).Scan(&w.ID, &w.UserID, &w.AmountTON, &w.Currency, &w.WalletAddress,
+		&w.Status, &w.TxHash, &w.TxComment, &w.FailureReason, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO balance_ledger (user_id, amount_ton, currency, withdrawal_id)
+		VALUES ($1, -$2::numeric, $3, $4)
+	`, userID, w.AmountTON, w.Currency, w.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// ClaimPendingWithdrawals moves up to limit pending withdrawals to 'sending' and returns them,
+// oldest first. SKIP LOCKED lets two workers run without claiming the same withdrawal.
+func (r *BalanceRepo) ClaimPendingWithdrawals(ctx context.Context, limit int) ([]models.Withdrawal, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE withdrawals SET status = 'sending', updated_at = now()
+		WHERE id IN (
+			SELECT id FROM withdrawals WHERE status = 'pending'
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+withdrawalColumns+`
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.Withdrawal
+	for rows.Next() {
+		var w models.Withdrawal
+		if err := rows.Scan(&w.ID, &w.UserID, &w.AmountTON, &w.Currency, &w.WalletAddress,
+			&w.Status, &w.TxHash, &w.TxComment, &w.FailureReason, &w.CreatedAt, &w.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, w)
+	}
+	return list, rows.Err()
+}
+
+// SetWithdrawalComment records the comment of a claimed withdrawal's transfer before it is sent.
+func (r *BalanceRepo) SetWithdrawalComment(ctx context.Context, id uuid.UUID, comment string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE withdrawals SET tx_comment = $2, updated_at = now()
+		WHERE id = $1 AND status = 'sending'
+	`, id, comment)
+	return err
+}
+
+func (r *BalanceRepo) MarkWithdrawalSent(ctx context.Context, id uuid.UUID, txHash string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE withdrawals SET status = 'sent', tx_hash = $2, updated_at = now()
+		WHERE id = $1 AND status = 'sending'
+	`, id, txHash)
+	return err
+}
+
+// MarkWithdrawalFailed leaves the balance debited: the transfer may still land,
+// so support re-credits it only after checking the chain.
+func (r *BalanceRepo) MarkWithdrawalFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE withdrawals SET status = 'failed', failure_reason = $2, updated_at = now()
+		WHERE id = $1 AND status = 'sending'
+	`, id, reason)
+	return err
+}
+
+// ListWithdrawals returns the user's withdrawals, newest first.
+func (r *BalanceRepo) ListWithdrawals(ctx context.Context, userID uuid.UUID, limit int) ([]models.Withdrawal, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+withdrawalColumns+`
+		FROM withdrawals WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.Withdrawal
+	for rows.Next() {
+		var w models.Withdrawal
+		if err := rows.Scan(&w.ID, &w.UserID, &w.AmountTON, &w.Currency, &w.WalletAddress,
+			&w.Status, &w.TxHash, &w.TxComment, &w.FailureReason, &w.CreatedAt, &w.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, w)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type BlockRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewBlockRepo(pool *pgxpool.Pool) *BlockRepo {
+	return &BlockRepo{pool: pool}
+}
+
+// Block records that blocker refuses blocked; blocking twice is a no-op.
+func (r *BlockRepo) Block(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO user_blocks (blocker_id, blocked_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, blockerID, blockedID)
+	return err
+}
+
+// Unblock lifts a block; ErrNotFound if there was none.
+func (r *BlockRepo) Unblock(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`, blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *BlockRepo) ListByBlocker(ctx context.Context, blockerID uuid.UUID) ([]models.UserBlock, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT blocker_id, blocked_id, created_at FROM user_blocks
+		WHERE blocker_id = $1 ORDER BY created_at DESC
+	`, blockerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.UserBlock
+	for rows.Next() {
+		var b models.UserBlock
+		if err := rows.Scan(&b.BlockerID, &b.BlockedID, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, b)
+	}
+	return list, rows.Err()
+}
+
+// ChannelBlocked reports whether any member of the channel and the user have blocked
+// each other, in either direction.
+func (r *BlockRepo) ChannelBlocked(ctx context.Context, channelID, userID uuid.UUID) (bool, error) {
+	var blocked bool
+	err := r.pool.QueryRow(ctx, `SELECT `+channelBlockedSQL("$1", "$2"), channelID, userID).Scan(&blocked)
+	return blocked, err
+}
+
+// channelBlockedSQL is a boolean expression: a member of the channel and the user have
+// blocked each other. Each direction hits one of the user_blocks indexes.
+func channelBlockedSQL(channelID, userID string) string {
+	return fmt.Sprintf(`(EXISTS (
+			SELECT 1 FROM channel_members bm JOIN user_blocks ub ON ub.blocker_id = bm.user_id
+			WHERE bm.channel_id = %[1]s AND ub.blocked_id = %[2]s
+		) OR EXISTS (
+			SELECT 1 FROM channel_members bm JOIN user_blocks ub ON ub.blocked_id = bm.user_id
+			WHERE bm.channel_id = %[1]s AND ub.blocker_id = %[2]s
+		))`, channelID, userID)
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ads-marketplace/backend/internal/models"
+)
+
+// TestChannelBlockedBothWays blocks an advertiser by a channel member and the other way
+// round, and expects the channel to count as blocked either way; set TEST_POSTGRES_DSN
+// to enable.
+func TestChannelBlockedBothWays(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+
+	owner := testUser(t, pool)
+	advertiser := testUser(t, pool)
+	channels := NewChannelRepo(pool)
+	ch := testChannel(t, pool, owner, "block")
+	if err := channels.AddMember(ctx, &models.ChannelMember{ChannelID: ch.ID, UserID: owner.ID, Role: "owner"}); err != nil {
+		t.Fatalf("add member: %v", err)
+	}
+
+	repo := NewBlockRepo(pool)
+	assertBlocked := func(step string, want bool) {
+		t.Helper()
+		got, err := repo.ChannelBlocked(ctx, ch.ID, advertiser.ID)
+		if err != nil {
+			t.Fatalf("%s: ChannelBlocked: %v", step, err)
+		}
+		if got != want {
+			t.Errorf("%s: ChannelBlocked = %v, want %v", step, got, want)
+		}
+	}
+
+	assertBlocked("no blocks", false)
+
+	if err := repo.Block(ctx, owner.ID, advertiser.ID); err != nil {
+		t.Fatalf("owner blocks: %v", err)
+	}
+	if err := repo.Block(ctx, owner.ID, advertiser.ID); err != nil {
+		t.Errorf("blocking twice: %v", err)
+	}
+	assertBlocked("owner blocked advertiser", true)
+
+	if err := repo.Unblock(ctx, owner.ID, advertiser.ID); err != nil {
+		t.Fatalf("unblock: %v", err)
+	}
+	if err := repo.Unblock(ctx, owner.ID, advertiser.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second unblock = %v, want ErrNotFound", err)
+	}
+	assertBlocked("unblocked", false)
+
+	if err := repo.Block(ctx, advertiser.ID, owner.ID); err != nil {
+		t.Fatalf("advertiser blocks: %v", err)
+	}
+	assertBlocked("advertiser blocked owner", true)
+}
@@ -37,7 +37,7 @@ func (r *CampaignRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Campa
 		&c.KeyMessages, &c.BudgetTON, &c.PreferredDate, &c.Status,
 		&c.CreatedAt, &c.UpdatedAt)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &c, nil
 }
@@ -52,6 +52,22 @@ func (r *CampaignRepo) Update(ctx context.Context, c *models.Campaign) error {
 	return err
 }
 
+// Archive soft-deletes a campaign: the row stays for history and references.
+func (r *CampaignRepo) Archive(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE campaigns SET status = 'archived', updated_at = now()
+		WHERE id = $1 AND status <> 'archived'
+	`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes the campaign row for good. Not exposed over the API — campaigns are archived.
 func (r *CampaignRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	_, err := r.pool.Exec(ctx, `DELETE FROM campaigns WHERE id = $1`, id)
 	return err
@@ -60,6 +76,7 @@ func (r *CampaignRepo) Delete(ctx context.Context, id uuid.UUID) error {
 type CampaignFilter struct {
 	AdvertiserUserID *uuid.UUID
 	Status           *string
+	IncludeArchived  bool // archived campaigns are skipped unless asked for (or filtered by Status)
 	Limit            int
 	Offset           int
 }
@@ -83,6 +100,8 @@ func (r *CampaignRepo) List(ctx context.Context, f CampaignFilter) ([]models.Cam
 		where = append(where, fmt.Sprintf("status = $%d", argIdx))
 		args = append(args, *f.Status)
 		argIdx++
+	} else if !f.IncludeArchived {
+		where = append(where, "status <> 'archived'")
 	}
 
 	if len(where) > 0 {
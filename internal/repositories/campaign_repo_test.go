@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ads-marketplace/backend/internal/models"
+)
+
+// TestArchivedCampaignHidden archives a campaign and expects it kept but left out of the
+// default list; set TEST_POSTGRES_DSN to enable.
+func TestArchivedCampaignHidden(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+
+	user := testUser(t, pool)
+	repo := NewCampaignRepo(pool)
+	c := &models.Campaign{AdvertiserUserID: user.ID, Title: "t", TargetAudience: "a", BudgetTON: "10", Status: models.CampaignStatusActive}
+	if err := repo.Create(ctx, c); err != nil {
+		t.Fatalf("create campaign: %v", err)
+	}
+
+	if err := repo.Archive(ctx, c.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if err := repo.Archive(ctx, c.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second archive = %v, want ErrNotFound", err)
+	}
+	got, err := repo.GetByID(ctx, c.ID)
+	if err != nil || got.Status != models.CampaignStatusArchived {
+		t.Fatalf("archived campaign = %+v, %v", got, err)
+	}
+
+	list, err := repo.List(ctx, CampaignFilter{AdvertiserUserID: &user.ID})
+	if err != nil || len(list) != 0 {
+		t.Errorf("default list = %d campaigns, %v; want none", len(list), err)
+	}
+	list, err = repo.List(ctx, CampaignFilter{AdvertiserUserID: &user.ID, IncludeArchived: true})
+	if err != nil || len(list) != 1 {
+		t.Errorf("list with archived = %d campaigns, %v; want 1", len(list), err)
+	}
+}
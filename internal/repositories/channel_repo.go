@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ads-marketplace/backend/internal/models"
 	"github.com/google/uuid"
@@ -52,7 +53,7 @@ func (r *ChannelRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Channe
 	`, id).Scan(&ch.ID, &ch.TelegramChatID, &ch.Username, &ch.Title, &ch.AddedByUserID,
 		&ch.BotStatus, &ch.UserbotStatus, &ch.BotAddedAt, &ch.BotRemovedAt, &ch.CreatedAt, &ch.UpdatedAt)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &ch, nil
 }
@@ -66,7 +67,7 @@ func (r *ChannelRepo) GetByUsername(ctx context.Context, username string) (*mode
 	`, username).Scan(&ch.ID, &ch.TelegramChatID, &ch.Username, &ch.Title, &ch.AddedByUserID,
 		&ch.BotStatus, &ch.UserbotStatus, &ch.BotAddedAt, &ch.BotRemovedAt, &ch.CreatedAt, &ch.UpdatedAt)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &ch, nil
 }
@@ -100,10 +101,31 @@ type ChannelFilter struct {
 	Category       *string
 	Language       *string
 	Status         *string // listing status
+	Query          *string // keyword, matched against title, username and listing description
+	IDs            []uuid.UUID
+	ViewerID       *uuid.UUID // explore only: hide channels blocked for/by this user
+	SortBy         string     // explore only: one of the ExploreSort* keys, "" = newest
+	SortDir        string     // SortAsc / SortDesc, "" = desc
 	Limit          int
 	Offset         int
 }
 
+// likePattern turns a user keyword into an ILIKE "contains" pattern, escaping the wildcards.
+func likePattern(q string) string {
+	return "%" + likePrefix(q)
+}
+
+// likePrefix turns q into a LIKE "starts with" pattern, escaping the wildcards.
+func likePrefix(q string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.TrimSpace(q)) + "%"
+}
+
+// keywordClause matches the pattern at $argIdx against the channel title, username and
+// listing description (trigram-indexed, see 027_channel_search_trgm).
+func keywordClause(argIdx int) string {
+	return fmt.Sprintf(" AND (c.title ILIKE $%[1]d OR c.username ILIKE $%[1]d OR cl.description ILIKE $%[1]d)", argIdx)
+}
+
 func (r *ChannelRepo) Search(ctx context.Context, f ChannelFilter) ([]models.Channel, error) {
 	query := `
 		SELECT c.id, c.telegram_chat_id, c.username, c.title, c.added_by_user_id, c.bot_status, c.userbot_status,
@@ -144,6 +166,11 @@ func (r *ChannelRepo) Search(ctx context.Context, f ChannelFilter) ([]models.Cha
 		args = append(args, *f.MinAvgViews)
 		argIdx++
 	}
+	if f.Query != nil && strings.TrimSpace(*f.Query) != "" {
+		query += keywordClause(argIdx)
+		args = append(args, likePattern(*f.Query))
+		argIdx++
+	}
 
 	limit := f.Limit
 	if limit <= 0 || limit > 100 {
@@ -184,8 +211,10 @@ func (r *ChannelRepo) GetActiveChannelsWithRecentUsers(ctx context.Context) ([]m
 		FROM channels c
 		JOIN channel_members cm ON cm.channel_id = c.id
 		JOIN users u ON u.id = cm.user_id
+		LEFT JOIN channel_stats_failures sf ON sf.channel_id = c.id
 		WHERE c.bot_status = 'active'
 		  AND u.last_active_at > now() - interval '48 hours'
+		  AND (sf.next_attempt_at IS NULL OR sf.next_attempt_at <= now())
 	`)
 	if err != nil {
 		return nil, err
@@ -209,6 +238,54 @@ func scanChannels(rows pgx.Rows) ([]models.Channel, error) {
 
 // ---- Explore (enriched channels) ----
 
+// Explore sort keys (?sort=). Each maps to a fixed column in exploreSortColumns so user input
+// never reaches the SQL text.
+const (
+	ExploreSortCreatedAt   = "created_at"
+	ExploreSortSubscribers = "subscribers"
+	ExploreSortAvgViews    = "avg_views"
+	ExploreSortER          = "er"
+	ExploreSortPrice       = "price"
+	ExploreSortTrust       = "trust"
+)
+
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+var exploreSortColumns = map[string]string{
+	ExploreSortCreatedAt:   "c.created_at",
+	ExploreSortSubscribers: "ss.subscribers",
+	ExploreSortAvgViews:    "ss.avg_views_20",
+	ExploreSortER:          "ss.er_percent",
+	ExploreSortPrice:       "cl.price_post_ton::numeric",
+	ExploreSortTrust:       "c.trust_score",
+}
+
+// IsExploreSort reports whether key is a supported explore sort key.
+func IsExploreSort(key string) bool {
+	_, ok := exploreSortColumns[key]
+	return ok
+}
+
+// exploreOrderBy builds the ORDER BY expression for SearchExplore. Channels without stats
+// or price always go last; ties fall back to newest first.
+func exploreOrderBy(sortBy, sortDir string) string {
+	col, ok := exploreSortColumns[sortBy]
+	if !ok {
+		col = exploreSortColumns[ExploreSortCreatedAt]
+	}
+	dir := "DESC"
+	if sortDir == SortAsc {
+		dir = "ASC"
+	}
+	if col == exploreSortColumns[ExploreSortCreatedAt] {
+		return "c.created_at " + dir
+	}
+	return col + " " + dir + " NULLS LAST, c.created_at DESC"
+}
+
 type ExploreChannelRow struct {
 	ID             uuid.UUID
 	Username       string
@@ -217,6 +294,7 @@ type ExploreChannelRow struct {
 	Subscribers    *int
 	AvgViews       *int
 	ERPercent      *float64
+	PostFrequency  *float64
 	ListingStatus  *string
 	PricePostTON   *string
 	PriceRepostTON *string
@@ -224,21 +302,42 @@ type ExploreChannelRow struct {
 	Description    *string
 	Category       *string
 	Language       *string
+	TrustScore     *float64
+	ScamBadge      bool
+	FakeBadge      bool
+	RefundOnEdit   bool
+	// From the latest stats snapshot (t.me page or userbot)
+	ChannelDescription *string
+	PhotoURL           *string
+	// Advertisers' ratings of completed deals
+	RatingAvg   *float64
+	RatingCount int
 }
 
 func (r *ChannelRepo) SearchExplore(ctx context.Context, f ChannelFilter) ([]ExploreChannelRow, error) {
 	query := `
 		SELECT c.id, c.username, c.title, c.bot_status,
-		       ss.subscribers, ss.avg_views_20, ss.er_percent,
+		       ss.subscribers, ss.avg_views_20, ss.er_percent, ss.post_frequency,
 		       cl.status AS listing_status,
 		       cl.price_post_ton, cl.price_repost_ton, cl.price_story_ton, cl.description,
-		       cl.category, cl.language
+		       cl.category, cl.language, c.trust_score,
+		       COALESCE(ss.scam_badge, false), COALESCE(ss.fake_badge, false),
+		       ss.channel_description, ss.photo_url, COALESCE(cl.refund_on_edit, false),
+		       rt.avg_stars, rt.ratings
 		FROM channels c
 		LEFT JOIN channel_listings cl ON cl.channel_id = c.id
 		LEFT JOIN LATERAL (
-			SELECT subscribers, avg_views_20, er_percent FROM channel_stats_snapshots
+			SELECT subscribers, avg_views_20, er_percent, post_frequency, scam_badge, fake_badge,
+			       NULLIF(raw_json->>'description', '') AS channel_description,
+			       raw_json->>'photo_url' AS photo_url
+			FROM channel_stats_snapshots
 			WHERE channel_id = c.id ORDER BY fetched_at DESC LIMIT 1
 		) ss ON true
+		LEFT JOIN LATERAL (
+			SELECT ROUND(AVG(dr.stars), 2)::float8 AS avg_stars, COUNT(*) AS ratings
+			FROM deal_ratings dr JOIN deals d ON d.id = dr.deal_id
+			WHERE d.channel_id = c.id AND dr.ratee_type = 'channel'
+		) rt ON true
 		WHERE c.bot_status = 'active'
 	`
 	args := []any{}
@@ -268,6 +367,11 @@ func (r *ChannelRepo) SearchExplore(ctx context.Context, f ChannelFilter) ([]Exp
 		args = append(args, *f.MinAvgViews)
 		argIdx++
 	}
+	if f.Query != nil && strings.TrimSpace(*f.Query) != "" {
+		query += keywordClause(argIdx)
+		args = append(args, likePattern(*f.Query))
+		argIdx++
+	}
 	if f.Category != nil {
 		query += fmt.Sprintf(" AND cl.category = $%d", argIdx)
 		args = append(args, *f.Category)
@@ -278,12 +382,28 @@ func (r *ChannelRepo) SearchExplore(ctx context.Context, f ChannelFilter) ([]Exp
 		args = append(args, *f.Language)
 		argIdx++
 	}
+	if f.MaxPriceTON != nil {
+		// Cheapest listed format fits; LEAST skips formats without a price
+		query += fmt.Sprintf(" AND LEAST(cl.price_post_ton, cl.price_repost_ton, cl.price_story_ton) <= $%d::numeric", argIdx)
+		args = append(args, *f.MaxPriceTON)
+		argIdx++
+	}
+	if len(f.IDs) > 0 {
+		query += fmt.Sprintf(" AND c.id = ANY($%d)", argIdx)
+		args = append(args, f.IDs)
+		argIdx++
+	}
+	if f.ViewerID != nil {
+		query += " AND NOT " + channelBlockedSQL("c.id", fmt.Sprintf("$%d", argIdx))
+		args = append(args, *f.ViewerID)
+		argIdx++
+	}
 
 	limit := f.Limit
 	if limit <= 0 || limit > 100 {
 		limit = 20
 	}
-	query += fmt.Sprintf(" ORDER BY c.created_at DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", exploreOrderBy(f.SortBy, f.SortDir), argIdx, argIdx+1)
 	args = append(args, limit, f.Offset)
 
 	rows, err := r.pool.Query(ctx, query, args...)
@@ -296,9 +416,11 @@ func (r *ChannelRepo) SearchExplore(ctx context.Context, f ChannelFilter) ([]Exp
 	for rows.Next() {
 		var row ExploreChannelRow
 		if err := rows.Scan(&row.ID, &row.Username, &row.Title, &row.BotStatus,
-			&row.Subscribers, &row.AvgViews, &row.ERPercent,
+			&row.Subscribers, &row.AvgViews, &row.ERPercent, &row.PostFrequency,
 			&row.ListingStatus, &row.PricePostTON, &row.PriceRepostTON, &row.PriceStoryTON, &row.Description,
-			&row.Category, &row.Language,
+			&row.Category, &row.Language, &row.TrustScore,
+			&row.ScamBadge, &row.FakeBadge, &row.ChannelDescription, &row.PhotoURL, &row.RefundOnEdit,
+			&row.RatingAvg, &row.RatingCount,
 		); err != nil {
 			return nil, err
 		}
@@ -307,6 +429,54 @@ func (r *ChannelRepo) SearchExplore(ctx context.Context, f ChannelFilter) ([]Exp
 	return results, nil
 }
 
+// ---- Trust score ----
+
+// TrustSignalsRow is a channel with the inputs of its trust score.
+type TrustSignalsRow struct {
+	ChannelID uuid.UUID
+	Signals   models.TrustSignals
+}
+
+// ListTrustSignals returns trust score inputs of all channels with the bot active:
+// the latest stats snapshot, the number of completed deals and their average rating.
+func (r *ChannelRepo) ListTrustSignals(ctx context.Context) ([]TrustSignalsRow, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT c.id, COALESCE(ss.verified_badge, false), COALESCE(ss.scam_badge OR ss.fake_badge, false),
+		       ss.subscribers, ss.avg_views_20, ss.er_percent,
+		       (SELECT COUNT(*) FROM deals d WHERE d.channel_id = c.id AND d.status = $1),
+		       (SELECT AVG(dr.stars)::float8 FROM deal_ratings dr JOIN deals d ON d.id = dr.deal_id
+		        WHERE d.channel_id = c.id AND dr.ratee_type = 'channel')
+		FROM channels c
+		LEFT JOIN LATERAL (
+			SELECT verified_badge, scam_badge, fake_badge, subscribers, avg_views_20, er_percent FROM channel_stats_snapshots
+			WHERE channel_id = c.id ORDER BY fetched_at DESC LIMIT 1
+		) ss ON true
+		WHERE c.bot_status = 'active'
+	`, models.DealStatusCompleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []TrustSignalsRow
+	for rows.Next() {
+		var row TrustSignalsRow
+		s := &row.Signals
+		if err := rows.Scan(&row.ChannelID, &s.VerifiedBadge, &s.Flagged, &s.Subscribers, &s.AvgViews, &s.ERPercent, &s.CompletedDeals, &s.AvgRating); err != nil {
+			return nil, err
+		}
+		results = append(results, row)
+	}
+	return results, rows.Err()
+}
+
+func (r *ChannelRepo) SetTrustScore(ctx context.Context, channelID uuid.UUID, score float64) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE channels SET trust_score = $1, trust_score_updated_at = now() WHERE id = $2
+	`, score, channelID)
+	return err
+}
+
 // ---- Channel Members ----
 
 func (r *ChannelRepo) AddMember(ctx context.Context, m *models.ChannelMember) error {
@@ -353,11 +523,59 @@ func (r *ChannelRepo) GetMemberByUserAndChannel(ctx context.Context, channelID,
 		FROM channel_members WHERE channel_id = $1 AND user_id = $2
 	`, channelID, userID).Scan(&m.ID, &m.ChannelID, &m.UserID, &m.Role, &m.CanPost, &m.LastAdminCheckAt)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &m, nil
 }
 
+// RemoveMember deletes a membership row. Fails with ErrNotFound if the user is not a member.
+func (r *ChannelRepo) RemoveMember(ctx context.Context, channelID, userID uuid.UUID) error {
+	var id uuid.UUID
+	err := r.pool.QueryRow(ctx, `
+		DELETE FROM channel_members WHERE channel_id = $1 AND user_id = $2
+		RETURNING id
+	`, channelID, userID).Scan(&id)
+	return notFound(err)
+}
+
+// TransferOwnership demotes fromUserID to manager and makes toUserID the owner, in one
+// transaction. The channel's withdraw wallet belonged to the previous owner, so it is
+// dropped and the new owner has to set their own. Fails with ErrNotFound if fromUserID
+// is not the owner.
+func (r *ChannelRepo) TransferOwnership(ctx context.Context, channelID, fromUserID, toUserID uuid.UUID, toCanPost bool) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var ownerRowID uuid.UUID
+	if err := tx.QueryRow(ctx, `
+		SELECT id FROM channel_members
+		WHERE channel_id = $1 AND user_id = $2 AND role = 'owner'
+		FOR UPDATE
+	`, channelID, fromUserID).Scan(&ownerRowID); err != nil {
+		return notFound(err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE channel_members SET role = 'manager' WHERE id = $1`, ownerRowID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO channel_members (channel_id, user_id, role, can_post, last_admin_check_at)
+		VALUES ($1, $2, 'owner', $3, now())
+		ON CONFLICT (channel_id, user_id) DO UPDATE SET
+			role = 'owner', can_post = EXCLUDED.can_post, last_admin_check_at = now()
+	`, channelID, toUserID, toCanPost); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM withdraw_wallets WHERE channel_id = $1`, channelID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
 // ---- Listings ----
 
 func (r *ChannelRepo) UpsertListing(ctx context.Context, l *models.ChannelListing) error {
@@ -370,8 +588,10 @@ func (r *ChannelRepo) UpsertListing(ctx context.Context, l *models.ChannelListin
 			channel_id, status, pricing_json, min_lead_time_minutes, description,
 			category, language,
 			price_post_ton, price_repost_ton, price_story_ton, formats_enabled,
-			hold_hours_post, hold_hours_repost, hold_hours_story, auto_accept
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			hold_hours_post, hold_hours_repost, hold_hours_story, auto_accept,
+			min_lead_post_minutes, min_lead_repost_minutes, min_lead_story_minutes,
+			allow_skip_creative_approval, refund_on_edit
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
 		ON CONFLICT (channel_id) DO UPDATE SET
 			status = EXCLUDED.status,
 			pricing_json = EXCLUDED.pricing_json,
@@ -387,12 +607,19 @@ func (r *ChannelRepo) UpsertListing(ctx context.Context, l *models.ChannelListin
 			hold_hours_repost = EXCLUDED.hold_hours_repost,
 			hold_hours_story = EXCLUDED.hold_hours_story,
 			auto_accept = EXCLUDED.auto_accept,
+			min_lead_post_minutes = EXCLUDED.min_lead_post_minutes,
+			min_lead_repost_minutes = EXCLUDED.min_lead_repost_minutes,
+			min_lead_story_minutes = EXCLUDED.min_lead_story_minutes,
+			allow_skip_creative_approval = EXCLUDED.allow_skip_creative_approval,
+			refund_on_edit = EXCLUDED.refund_on_edit,
 			updated_at = now()
 		RETURNING id, created_at, updated_at
 	`, l.ChannelID, l.Status, pricingBytes, l.MinLeadTimeMinutes, l.Description,
 		l.Category, l.Language,
 		l.PricePostTON, l.PriceRepostTON, l.PriceStoryTON, l.FormatsEnabled,
 		l.HoldHoursPost, l.HoldHoursRepost, l.HoldHoursStory, l.AutoAccept,
+		l.MinLeadPostMinutes, l.MinLeadRepostMinutes, l.MinLeadStoryMinutes,
+		l.AllowSkipCreativeApproval, l.RefundOnEdit,
 	).Scan(&l.ID, &l.CreatedAt, &l.UpdatedAt)
 }
 
@@ -404,22 +631,36 @@ func (r *ChannelRepo) GetListing(ctx context.Context, channelID uuid.UUID) (*mod
 		       category, language,
 		       price_post_ton, price_repost_ton, price_story_ton, formats_enabled,
 		       hold_hours_post, hold_hours_repost, hold_hours_story, auto_accept,
-		       created_at, updated_at
+		       min_lead_post_minutes, min_lead_repost_minutes, min_lead_story_minutes,
+		       allow_skip_creative_approval, refund_on_edit, created_at, updated_at
 		FROM channel_listings WHERE channel_id = $1
 	`, channelID).Scan(
 		&l.ID, &l.ChannelID, &l.Status, &pricingBytes, &l.MinLeadTimeMinutes, &l.Description,
 		&l.Category, &l.Language,
 		&l.PricePostTON, &l.PriceRepostTON, &l.PriceStoryTON, &l.FormatsEnabled,
 		&l.HoldHoursPost, &l.HoldHoursRepost, &l.HoldHoursStory, &l.AutoAccept,
-		&l.CreatedAt, &l.UpdatedAt,
+		&l.MinLeadPostMinutes, &l.MinLeadRepostMinutes, &l.MinLeadStoryMinutes,
+		&l.AllowSkipCreativeApproval, &l.RefundOnEdit, &l.CreatedAt, &l.UpdatedAt,
 	)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	_ = json.Unmarshal(pricingBytes, &l.PricingJSON)
 	return &l, nil
 }
 
+// SetListingStatus updates only the listing status, keeping pricing and terms intact.
+// Fails with ErrNotFound if the channel has no listing.
+func (r *ChannelRepo) SetListingStatus(ctx context.Context, channelID uuid.UUID, status string) error {
+	var id uuid.UUID
+	err := r.pool.QueryRow(ctx, `
+		UPDATE channel_listings SET status = $2, updated_at = NOW()
+		WHERE channel_id = $1
+		RETURNING id
+	`, channelID, status).Scan(&id)
+	return notFound(err)
+}
+
 // ---- Stats ----
 
 func (r *ChannelRepo) InsertStatsSnapshot(ctx context.Context, s *models.ChannelStatsSnapshot) error {
@@ -430,12 +671,14 @@ func (r *ChannelRepo) InsertStatsSnapshot(ctx context.Context, s *models.Channel
 	return r.pool.QueryRow(ctx, `
 		INSERT INTO channel_stats_snapshots (channel_id, subscribers, verified_badge, avg_views_20, last_post_id, raw_json, premium_count,
 		                                     source, members_online, admins_count, growth_7d, growth_30d, posts_count,
-		                                     views_per_post, shares_per_post, enabled_notifications_percent, er_percent)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		                                     views_per_post, shares_per_post, enabled_notifications_percent, er_percent,
+		                                     post_frequency, has_pinned_post, scam_badge, fake_badge)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
 		RETURNING id, fetched_at
 	`, s.ChannelID, s.Subscribers, s.VerifiedBadge, s.AvgViews20, s.LastPostID, rawBytes, s.PremiumCount,
 		s.Source, s.MembersOnline, s.AdminsCount, s.Growth7d, s.Growth30d, s.PostsCount,
-		s.ViewsPerPost, s.SharesPerPost, s.EnabledNotificationsPercent, s.ERPercent).Scan(&s.ID, &s.FetchedAt)
+		s.ViewsPerPost, s.SharesPerPost, s.EnabledNotificationsPercent, s.ERPercent,
+		s.PostFrequency, s.HasPinnedPost, s.ScamBadge, s.FakeBadge).Scan(&s.ID, &s.FetchedAt)
 }
 
 func (r *ChannelRepo) GetLatestStats(ctx context.Context, channelID uuid.UUID) (*models.ChannelStatsSnapshot, error) {
@@ -444,18 +687,155 @@ func (r *ChannelRepo) GetLatestStats(ctx context.Context, channelID uuid.UUID) (
 	err := r.pool.QueryRow(ctx, `
 		SELECT id, channel_id, fetched_at, subscribers, verified_badge, avg_views_20, last_post_id, raw_json, premium_count,
 		       source, members_online, admins_count, growth_7d, growth_30d, posts_count,
-		       views_per_post, shares_per_post, enabled_notifications_percent, er_percent
+		       views_per_post, shares_per_post, enabled_notifications_percent, er_percent,
+		       post_frequency, has_pinned_post, scam_badge, fake_badge
 		FROM channel_stats_snapshots WHERE channel_id = $1 ORDER BY fetched_at DESC LIMIT 1
 	`, channelID).Scan(&s.ID, &s.ChannelID, &s.FetchedAt, &s.Subscribers, &s.VerifiedBadge, &s.AvgViews20, &s.LastPostID, &rawBytes, &s.PremiumCount,
 		&s.Source, &s.MembersOnline, &s.AdminsCount, &s.Growth7d, &s.Growth30d, &s.PostsCount,
-		&s.ViewsPerPost, &s.SharesPerPost, &s.EnabledNotificationsPercent, &s.ERPercent)
+		&s.ViewsPerPost, &s.SharesPerPost, &s.EnabledNotificationsPercent, &s.ERPercent,
+		&s.PostFrequency, &s.HasPinnedPost, &s.ScamBadge, &s.FakeBadge)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	_ = json.Unmarshal(rawBytes, &s.RawJSON)
 	return &s, nil
 }
 
+// GetStatsSnapshotNearest returns the channel's snapshot fetched closest to target.
+// Fails with ErrNotFound if the channel has no snapshots.
+func (r *ChannelRepo) GetStatsSnapshotNearest(ctx context.Context, channelID uuid.UUID, target time.Time) (*models.StatsHistoryPoint, error) {
+	// Nearest on either side: the latest one before target and the earliest one after
+	var p models.StatsHistoryPoint
+	err := r.pool.QueryRow(ctx, `
+		SELECT fetched_at, subscribers, avg_views_20, er_percent, growth_7d FROM (
+			(SELECT fetched_at, subscribers, avg_views_20, er_percent, growth_7d
+			 FROM channel_stats_snapshots WHERE channel_id = $1 AND fetched_at <= $2
+			 ORDER BY fetched_at DESC LIMIT 1)
+			UNION ALL
+			(SELECT fetched_at, subscribers, avg_views_20, er_percent, growth_7d
+			 FROM channel_stats_snapshots WHERE channel_id = $1 AND fetched_at > $2
+			 ORDER BY fetched_at LIMIT 1)
+		) around
+		ORDER BY abs(extract(epoch FROM fetched_at - $2))
+		LIMIT 1
+	`, channelID, target).Scan(&p.FetchedAt, &p.Subscribers, &p.AvgViews, &p.ERPercent, &p.Growth)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &p, nil
+}
+
+// GetStatsHistory returns up to limit of the channel's most recent snapshots fetched at or
+// after since, oldest-first.
+func (r *ChannelRepo) GetStatsHistory(ctx context.Context, channelID uuid.UUID, since time.Time, limit int) ([]models.StatsHistoryPoint, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT fetched_at, subscribers, avg_views_20, er_percent, growth_7d FROM (
+			SELECT fetched_at, subscribers, avg_views_20, er_percent, growth_7d
+			FROM channel_stats_snapshots
+			WHERE channel_id = $1 AND fetched_at >= $2
+			ORDER BY fetched_at DESC
+			LIMIT $3
+		) recent
+		ORDER BY fetched_at
+	`, channelID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []models.StatsHistoryPoint{}
+	for rows.Next() {
+		var p models.StatsHistoryPoint
+		if err := rows.Scan(&p.FetchedAt, &p.Subscribers, &p.AvgViews, &p.ERPercent, &p.Growth); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// EachStatsHistory walks the channel's snapshots in [from, to) oldest-first, calling fn per row
+// without loading the whole series into memory.
+func (r *ChannelRepo) EachStatsHistory(ctx context.Context, channelID uuid.UUID, from, to time.Time, fn func(models.StatsHistoryPoint) error) error {
+	rows, err := r.pool.Query(ctx, `
+		SELECT fetched_at, subscribers, avg_views_20, er_percent, growth_7d
+		FROM channel_stats_snapshots
+		WHERE channel_id = $1 AND fetched_at >= $2 AND fetched_at < $3
+		ORDER BY fetched_at
+	`, channelID, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.StatsHistoryPoint
+		if err := rows.Scan(&p.FetchedAt, &p.Subscribers, &p.AvgViews, &p.ERPercent, &p.Growth); err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ---- Stats failures ----
+
+// RecordStatsFailure increments the channel's consecutive failure counter and returns the new value.
+func (r *ChannelRepo) RecordStatsFailure(ctx context.Context, channelID uuid.UUID, errMsg string) (int, error) {
+	var failures int
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO channel_stats_failures (channel_id, consecutive_failures, last_error, last_failed_at)
+		VALUES ($1, 1, $2, now())
+		ON CONFLICT (channel_id) DO UPDATE SET
+			consecutive_failures = channel_stats_failures.consecutive_failures + 1,
+			last_error = EXCLUDED.last_error,
+			last_failed_at = now()
+		RETURNING consecutive_failures
+	`, channelID, errMsg).Scan(&failures)
+	return failures, err
+}
+
+func (r *ChannelRepo) SetStatsNextAttempt(ctx context.Context, channelID uuid.UUID, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE channel_stats_failures SET next_attempt_at = $1 WHERE channel_id = $2`, at, channelID)
+	return err
+}
+
+// ResetStatsFailures clears the failure counter after a successful refresh.
+func (r *ChannelRepo) ResetStatsFailures(ctx context.Context, channelID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM channel_stats_failures WHERE channel_id = $1`, channelID)
+	return err
+}
+
+func (r *ChannelRepo) ListStatsFailures(ctx context.Context, minFailures, limit, offset int) ([]models.ChannelStatsFailure, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT sf.channel_id, c.username, sf.consecutive_failures, sf.last_error, sf.last_failed_at, sf.next_attempt_at
+		FROM channel_stats_failures sf
+		JOIN channels c ON c.id = sf.channel_id
+		WHERE sf.consecutive_failures >= $1
+		ORDER BY sf.consecutive_failures DESC, sf.last_failed_at DESC
+		LIMIT $2 OFFSET $3
+	`, minFailures, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var failures []models.ChannelStatsFailure
+	for rows.Next() {
+		var f models.ChannelStatsFailure
+		if err := rows.Scan(&f.ChannelID, &f.Username, &f.ConsecutiveFailures, &f.LastError, &f.LastFailedAt, &f.NextAttemptAt); err != nil {
+			return nil, err
+		}
+		failures = append(failures, f)
+	}
+	return failures, nil
+}
+
 // --- helper to normalise username ---
 func NormalizeUsername(u string) string {
 	u = strings.TrimPrefix(u, "@")
@@ -463,3 +843,110 @@ func NormalizeUsername(u string) string {
 	u = strings.TrimPrefix(u, "http://t.me/")
 	return strings.ToLower(strings.TrimSpace(u))
 }
+
+// ChannelMergeResult reports what MergeChannels moved to the surviving channel.
+type ChannelMergeResult struct {
+	KeepID              uuid.UUID `json:"keep_id"`
+	DuplicateID         uuid.UUID `json:"duplicate_id"`
+	DealsMoved          int64     `json:"deals_moved"`
+	OffersMoved         int64     `json:"offers_moved"`
+	SnapshotsMoved      int64     `json:"snapshots_moved"`
+	MembersMoved        int64     `json:"members_moved"`
+	ListingMoved        bool      `json:"listing_moved"`
+	WithdrawWalletMoved bool      `json:"withdraw_wallet_moved"`
+}
+
+// MergeChannels reassigns everything that belongs to dupID onto keepID and deletes dupID,
+// in one transaction. The surviving listing/withdraw wallet/members win on conflict;
+// the duplicate's are only moved where keepID has none. A channel has one owner: the
+// duplicate's owner joins as a manager unless keepID has no owner.
+func (r *ChannelRepo) MergeChannels(ctx context.Context, keepID, dupID uuid.UUID) (*ChannelMergeResult, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Lock both rows so concurrent deal creation / bot updates wait for the merge
+	var dupChatID *int64
+	var dupTitle *string
+	if err := tx.QueryRow(ctx, `
+		SELECT telegram_chat_id, title FROM channels WHERE id = $1 FOR UPDATE
+	`, dupID).Scan(&dupChatID, &dupTitle); err != nil {
+		return nil, notFound(err)
+	}
+	var lockedID uuid.UUID
+	if err := tx.QueryRow(ctx, `SELECT id FROM channels WHERE id = $1 FOR UPDATE`, keepID).Scan(&lockedID); err != nil {
+		return nil, notFound(err)
+	}
+
+	res := &ChannelMergeResult{KeepID: keepID, DuplicateID: dupID}
+
+	tag, err := tx.Exec(ctx, `UPDATE deals SET channel_id = $1, updated_at = now() WHERE channel_id = $2`, keepID, dupID)
+	if err != nil {
+		return nil, err
+	}
+	res.DealsMoved = tag.RowsAffected()
+
+	if tag, err = tx.Exec(ctx, `UPDATE deal_offers SET channel_id = $1 WHERE channel_id = $2`, keepID, dupID); err != nil {
+		return nil, err
+	}
+	res.OffersMoved = tag.RowsAffected()
+
+	if tag, err = tx.Exec(ctx, `UPDATE channel_stats_snapshots SET channel_id = $1 WHERE channel_id = $2`, keepID, dupID); err != nil {
+		return nil, err
+	}
+	res.SnapshotsMoved = tag.RowsAffected()
+
+	if tag, err = tx.Exec(ctx, `
+		INSERT INTO channel_members (channel_id, user_id, role, can_post, last_admin_check_at)
+		SELECT $1, user_id,
+		       CASE WHEN role = 'owner' AND EXISTS (
+		           SELECT 1 FROM channel_members WHERE channel_id = $1 AND role = 'owner'
+		       ) THEN 'manager' ELSE role END,
+		       can_post, last_admin_check_at
+		FROM channel_members WHERE channel_id = $2
+		ON CONFLICT (channel_id, user_id) DO NOTHING
+	`, keepID, dupID); err != nil {
+		return nil, err
+	}
+	res.MembersMoved = tag.RowsAffected()
+
+	if tag, err = tx.Exec(ctx, `
+		UPDATE channel_listings SET channel_id = $1, updated_at = now()
+		WHERE channel_id = $2 AND NOT EXISTS (SELECT 1 FROM channel_listings WHERE channel_id = $1)
+	`, keepID, dupID); err != nil {
+		return nil, err
+	}
+	res.ListingMoved = tag.RowsAffected() > 0
+
+	if tag, err = tx.Exec(ctx, `
+		UPDATE withdraw_wallets SET channel_id = $1, updated_at = now()
+		WHERE channel_id = $2 AND NOT EXISTS (SELECT 1 FROM withdraw_wallets WHERE channel_id = $1)
+	`, keepID, dupID); err != nil {
+		return nil, err
+	}
+	res.WithdrawWalletMoved = tag.RowsAffected() > 0
+
+	// telegram_chat_id is UNIQUE — free it on the duplicate before carrying it over
+	if _, err := tx.Exec(ctx, `UPDATE channels SET telegram_chat_id = NULL WHERE id = $1`, dupID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE channels SET telegram_chat_id = COALESCE(telegram_chat_id, $1),
+		       title = COALESCE(title, $2), updated_at = now()
+		WHERE id = $3
+	`, dupChatID, dupTitle, keepID); err != nil {
+		return nil, err
+	}
+
+	// Remaining members/listing/wallet/stats failures of the duplicate go with ON DELETE CASCADE
+	if _, err := tx.Exec(ctx, `DELETE FROM channels WHERE id = $1`, dupID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ads-marketplace/backend/internal/models"
+)
+
+func TestLikePattern(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"crypto signals", `%crypto signals%`},
+		{"  news ", `%news%`},
+		{"100%", `%100\%%`},
+		{"my_channel", `%my\_channel%`},
+		{`a\b`, `%a\\b%`},
+	}
+
+	for _, tt := range tests {
+		if got := likePattern(tt.in); got != tt.expected {
+			t.Errorf("likePattern(%q) = %q, want %q", tt.in, got, tt.expected)
+		}
+	}
+}
+
+func TestExploreOrderBy(t *testing.T) {
+	tests := []struct {
+		sortBy, sortDir string
+		expected        string
+	}{
+		{"", "", "c.created_at DESC"},
+		{ExploreSortCreatedAt, SortAsc, "c.created_at ASC"},
+		{ExploreSortSubscribers, "", "ss.subscribers DESC NULLS LAST, c.created_at DESC"},
+		{ExploreSortPrice, SortAsc, "cl.price_post_ton::numeric ASC NULLS LAST, c.created_at DESC"},
+		{ExploreSortTrust, SortDesc, "c.trust_score DESC NULLS LAST, c.created_at DESC"},
+		{"subscribers; DROP TABLE channels", "", "c.created_at DESC"},
+		{ExploreSortER, "sideways", "ss.er_percent DESC NULLS LAST, c.created_at DESC"},
+	}
+
+	for _, tt := range tests {
+		if got := exploreOrderBy(tt.sortBy, tt.sortDir); got != tt.expected {
+			t.Errorf("exploreOrderBy(%q, %q) = %q, want %q", tt.sortBy, tt.sortDir, got, tt.expected)
+		}
+	}
+}
+
+// TestMergeChannels merges a duplicate with its own owner, manager, listing and deal into a
+// channel that already has an owner, and expects everything moved with a single owner left;
+// set TEST_POSTGRES_DSN to enable.
+func TestMergeChannels(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+
+	owner, dupOwner, manager := testUser(t, pool), testUser(t, pool), testUser(t, pool)
+	repo := NewChannelRepo(pool)
+	keep := testChannel(t, pool, owner, "keep")
+	dup := testChannel(t, pool, dupOwner, "dup")
+	for _, m := range []*models.ChannelMember{
+		{ChannelID: keep.ID, UserID: owner.ID, Role: "owner", CanPost: true},
+		{ChannelID: dup.ID, UserID: dupOwner.ID, Role: "owner", CanPost: true},
+		{ChannelID: dup.ID, UserID: manager.ID, Role: "manager"},
+	} {
+		if err := repo.AddMember(ctx, m); err != nil {
+			t.Fatalf("add member: %v", err)
+		}
+	}
+	if err := repo.UpsertListing(ctx, &models.ChannelListing{ChannelID: dup.ID, Status: "draft", FormatsEnabled: []string{models.AdFormatPost}}); err != nil {
+		t.Fatalf("create listing: %v", err)
+	}
+	deals := NewDealRepo(pool)
+	deal := testDeal(t, pool, dup, manager, models.DealStatusSubmitted)
+
+	res, err := repo.MergeChannels(ctx, keep.ID, dup.ID)
+	if err != nil {
+		t.Fatalf("MergeChannels: %v", err)
+	}
+	if res.DealsMoved != 1 || res.MembersMoved != 2 || !res.ListingMoved {
+		t.Errorf("result = %+v, want 1 deal, 2 members and the listing moved", res)
+	}
+
+	members, err := repo.GetMembers(ctx, keep.ID)
+	if err != nil {
+		t.Fatalf("GetMembers: %v", err)
+	}
+	roles := map[string]string{}
+	owners := 0
+	for _, m := range members {
+		roles[m.UserID.String()] = m.Role
+		if m.Role == "owner" {
+			owners++
+		}
+	}
+	if owners != 1 || roles[owner.ID.String()] != "owner" {
+		t.Errorf("roles = %v, want %s as the only owner", roles, owner.ID)
+	}
+	if roles[dupOwner.ID.String()] != "manager" || roles[manager.ID.String()] != "manager" {
+		t.Errorf("roles = %v, want the duplicate's owner and manager as managers", roles)
+	}
+
+	if _, err := repo.GetListing(ctx, keep.ID); err != nil {
+		t.Errorf("GetListing(keep): %v", err)
+	}
+	got, err := deals.GetByID(ctx, deal.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.ChannelID != keep.ID {
+		t.Errorf("deal channel = %s, want %s", got.ChannelID, keep.ID)
+	}
+	if _, err := repo.GetByID(ctx, dup.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetByID(dup) = %v, want ErrNotFound", err)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/ads-marketplace/backend/internal/models"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -20,10 +22,12 @@ func NewDealRepo(pool *pgxpool.Pool) *DealRepo {
 
 func (r *DealRepo) Create(ctx context.Context, d *models.Deal) error {
 	return r.pool.QueryRow(ctx, `
-		INSERT INTO deals (channel_id, advertiser_user_id, status, ad_format, brief, scheduled_at, price_ton, platform_fee_bps, hold_period_seconds)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO deals (channel_id, advertiser_user_id, status, ad_format, brief, scheduled_at, price_ton, platform_fee_bps, hold_period_seconds,
+		                   skip_creative_approval, campaign_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id, created_at, updated_at
 	`, d.ChannelID, d.AdvertiserUserID, d.Status, d.AdFormat, d.Brief, d.ScheduledAt, d.PriceTON, d.PlatformFeeBPS, d.HoldPeriodSeconds,
+		d.SkipCreativeApproval, d.CampaignID,
 	).Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt)
 }
 
@@ -31,12 +35,12 @@ func (r *DealRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Deal, err
 	var d models.Deal
 	err := r.pool.QueryRow(ctx, `
 		SELECT id, channel_id, advertiser_user_id, status, ad_format, brief, scheduled_at,
-		       price_ton, platform_fee_bps, hold_period_seconds, created_at, updated_at
+		       price_ton, platform_fee_bps, hold_period_seconds, skip_creative_approval, payout_frozen, campaign_id, created_at, updated_at
 		FROM deals WHERE id = $1
 	`, id).Scan(&d.ID, &d.ChannelID, &d.AdvertiserUserID, &d.Status, &d.AdFormat, &d.Brief, &d.ScheduledAt,
-		&d.PriceTON, &d.PlatformFeeBPS, &d.HoldPeriodSeconds, &d.CreatedAt, &d.UpdatedAt)
+		&d.PriceTON, &d.PlatformFeeBPS, &d.HoldPeriodSeconds, &d.SkipCreativeApproval, &d.PayoutFrozen, &d.CampaignID, &d.CreatedAt, &d.UpdatedAt)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &d, nil
 }
@@ -45,24 +49,26 @@ func (r *DealRepo) GetByIDWithChannel(ctx context.Context, id uuid.UUID) (*model
 	var d models.DealWithChannel
 	err := r.pool.QueryRow(ctx, `
 		SELECT d.id, d.channel_id, d.advertiser_user_id, d.status, d.ad_format, d.brief, d.scheduled_at,
-		       d.price_ton, d.platform_fee_bps, d.hold_period_seconds, d.created_at, d.updated_at,
+		       d.price_ton, d.platform_fee_bps, d.hold_period_seconds, d.skip_creative_approval, d.payout_frozen, d.campaign_id, d.created_at, d.updated_at,
 		       c.title, c.username
 		FROM deals d
 		JOIN channels c ON c.id = d.channel_id
 		WHERE d.id = $1
 	`, id).Scan(&d.ID, &d.ChannelID, &d.AdvertiserUserID, &d.Status, &d.AdFormat, &d.Brief, &d.ScheduledAt,
-		&d.PriceTON, &d.PlatformFeeBPS, &d.HoldPeriodSeconds, &d.CreatedAt, &d.UpdatedAt,
+		&d.PriceTON, &d.PlatformFeeBPS, &d.HoldPeriodSeconds, &d.SkipCreativeApproval, &d.PayoutFrozen, &d.CampaignID, &d.CreatedAt, &d.UpdatedAt,
 		&d.ChannelTitle, &d.ChannelUsername)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &d, nil
 }
 
-func (r *DealRepo) ListWithChannel(ctx context.Context, f DealFilter) ([]models.DealWithChannel, error) {
+// ListWithChannel returns a page of deals, newest first. NextCursor is set whenever another
+// page exists, in offset mode too, so clients can switch to cursors mid-list.
+func (r *DealRepo) ListWithChannel(ctx context.Context, f DealFilter) (*Page[models.DealWithChannel], error) {
 	query := `
 		SELECT d.id, d.channel_id, d.advertiser_user_id, d.status, d.ad_format, d.brief, d.scheduled_at,
-		       d.price_ton, d.platform_fee_bps, d.hold_period_seconds, d.created_at, d.updated_at,
+		       d.price_ton, d.platform_fee_bps, d.hold_period_seconds, d.skip_creative_approval, d.payout_frozen, d.campaign_id, d.created_at, d.updated_at,
 		       c.title, c.username
 		FROM deals d
 		JOIN channels c ON c.id = d.channel_id
@@ -92,6 +98,18 @@ func (r *DealRepo) ListWithChannel(ctx context.Context, f DealFilter) ([]models.
 		args = append(args, *f.Status)
 		argIdx++
 	}
+	if f.CampaignID != nil {
+		where = append(where, fmt.Sprintf("d.campaign_id = $%d", argIdx))
+		args = append(args, *f.CampaignID)
+		argIdx++
+	}
+	offset := f.Offset
+	if f.Cursor != nil {
+		where = append(where, keysetClause("d.created_at", "d.id", argIdx))
+		args = append(args, f.Cursor.CreatedAt, f.Cursor.ID)
+		argIdx += 2
+		offset = 0
+	}
 
 	if len(where) > 0 {
 		query += " WHERE "
@@ -107,8 +125,9 @@ func (r *DealRepo) ListWithChannel(ctx context.Context, f DealFilter) ([]models.
 	if limit <= 0 || limit > 100 {
 		limit = 20
 	}
-	query += fmt.Sprintf(" ORDER BY d.created_at DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
-	args = append(args, limit, f.Offset)
+	// One extra row tells whether there is a next page
+	query += fmt.Sprintf(" ORDER BY d.created_at DESC, d.id DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	args = append(args, limit+1, offset)
 
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
@@ -120,18 +139,108 @@ func (r *DealRepo) ListWithChannel(ctx context.Context, f DealFilter) ([]models.
 	for rows.Next() {
 		var d models.DealWithChannel
 		if err := rows.Scan(&d.ID, &d.ChannelID, &d.AdvertiserUserID, &d.Status, &d.AdFormat, &d.Brief, &d.ScheduledAt,
-			&d.PriceTON, &d.PlatformFeeBPS, &d.HoldPeriodSeconds, &d.CreatedAt, &d.UpdatedAt,
+			&d.PriceTON, &d.PlatformFeeBPS, &d.HoldPeriodSeconds, &d.SkipCreativeApproval, &d.PayoutFrozen, &d.CampaignID, &d.CreatedAt, &d.UpdatedAt,
 			&d.ChannelTitle, &d.ChannelUsername); err != nil {
 			return nil, err
 		}
 		deals = append(deals, d)
 	}
-	return deals, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return newPage(deals, limit, func(d models.DealWithChannel) PageCursor {
+		return PageCursor{CreatedAt: d.CreatedAt, ID: d.ID}
+	}), nil
 }
 
-func (r *DealRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
-	_, err := r.pool.Exec(ctx, `UPDATE deals SET status = $1, updated_at = now() WHERE id = $2`, status, id)
-	return err
+// GetAdvertiserTelegramID resolves the deal's advertiser to a telegram id for notifications.
+func (r *DealRepo) GetAdvertiserTelegramID(ctx context.Context, dealID uuid.UUID) (int64, error) {
+	rcpt, err := r.GetAdvertiserRecipient(ctx, dealID)
+	return rcpt.TelegramID, err
+}
+
+// GetAdvertiserRecipient returns the Telegram id, language and opt-out of the deal's advertiser.
+func (r *DealRepo) GetAdvertiserRecipient(ctx context.Context, dealID uuid.UUID) (models.Recipient, error) {
+	return r.scanRecipient(r.pool.QueryRow(ctx, `
+		SELECT u.telegram_user_id, u.language_code, u.notifications_enabled FROM deals d
+		JOIN users u ON u.id = d.advertiser_user_id
+		WHERE d.id = $1
+	`, dealID))
+}
+
+// GetChannelOwnerTelegramID returns the Telegram id of the owner of the deal's channel.
+func (r *DealRepo) GetChannelOwnerTelegramID(ctx context.Context, dealID uuid.UUID) (int64, error) {
+	rcpt, err := r.GetChannelOwnerRecipient(ctx, dealID)
+	return rcpt.TelegramID, err
+}
+
+// GetChannelOwnerRecipient returns the Telegram id, language and opt-out of the owner of the deal's channel.
+func (r *DealRepo) GetChannelOwnerRecipient(ctx context.Context, dealID uuid.UUID) (models.Recipient, error) {
+	return r.scanRecipient(r.pool.QueryRow(ctx, `
+		SELECT u.telegram_user_id, u.language_code, u.notifications_enabled FROM deals d
+		JOIN channel_members m ON m.channel_id = d.channel_id AND m.role = 'owner'
+		JOIN users u ON u.id = m.user_id
+		WHERE d.id = $1
+		LIMIT 1
+	`, dealID))
+}
+
+func (r *DealRepo) scanRecipient(row pgx.Row) (models.Recipient, error) {
+	var telegramID *int64
+	var lang *string
+	var enabled bool
+	err := row.Scan(&telegramID, &lang, &enabled)
+	if err != nil || telegramID == nil {
+		return models.Recipient{}, notFound(err)
+	}
+	rcpt := models.Recipient{TelegramID: *telegramID, NotificationsEnabled: enabled}
+	if lang != nil {
+		rcpt.LanguageCode = *lang
+	}
+	return rcpt, nil
+}
+
+// GetStatusesForUser returns statuses of the given deals that userID may see — as the advertiser
+// or a member of the deal's channel. Other ids are silently left out.
+func (r *DealRepo) GetStatusesForUser(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT d.id, d.status FROM deals d
+		WHERE d.id = ANY($1)
+		  AND (d.advertiser_user_id = $2
+		       OR EXISTS (SELECT 1 FROM channel_members cm WHERE cm.channel_id = d.channel_id AND cm.user_id = $2))
+	`, ids, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statuses := make(map[uuid.UUID]string, len(ids))
+	for rows.Next() {
+		var id uuid.UUID
+		var status string
+		if err := rows.Scan(&id, &status); err != nil {
+			return nil, err
+		}
+		statuses[id] = status
+	}
+	return statuses, rows.Err()
+}
+
+// UpdateStatusIf moves the deal from expectedStatus to newStatus. Returns ErrStatusChanged
+// if the deal is no longer in expectedStatus, so of two concurrent transitions validated
+// against the same status only one wins.
+func (r *DealRepo) UpdateStatusIf(ctx context.Context, id uuid.UUID, expectedStatus, newStatus string) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE deals SET status = $1, updated_at = now()
+		WHERE id = $2 AND status = $3
+	`, newStatus, id, expectedStatus)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrStatusChanged
+	}
+	return nil
 }
 
 func (r *DealRepo) UpdateScheduledAt(ctx context.Context, id uuid.UUID, d *models.Deal) error {
@@ -139,19 +248,73 @@ func (r *DealRepo) UpdateScheduledAt(ctx context.Context, id uuid.UUID, d *model
 	return err
 }
 
+// ListTakenSlots returns scheduled_at of the channel's deals that still hold a slot and
+// are scheduled at or after `from`. excludeDealID skips the deal being (re)scheduled.
+func (r *DealRepo) ListTakenSlots(ctx context.Context, channelID uuid.UUID, from time.Time, excludeDealID *uuid.UUID) ([]time.Time, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT scheduled_at FROM deals
+		WHERE channel_id = $1
+		  AND scheduled_at IS NOT NULL
+		  AND scheduled_at >= $2
+		  AND status <> ALL($3)
+		  AND ($4::uuid IS NULL OR id <> $4)
+		ORDER BY scheduled_at
+	`, channelID, from, models.SlotStatusesFree(), excludeDealID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slots []time.Time
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		slots = append(slots, t)
+	}
+	return slots, nil
+}
+
 type DealFilter struct {
 	ChannelID        *uuid.UUID
 	AdvertiserUserID *uuid.UUID
 	OwnerUserID      *uuid.UUID // through channel_members
 	Status           *string
+	CampaignID       *uuid.UUID
 	Limit            int
-	Offset           int
+	Offset           int         // legacy; ignored when Cursor is set
+	Cursor           *PageCursor // preferred: continue after this (created_at, id)
+}
+
+// SumCampaignPrices totals the prices of a campaign's deals, leaving out ones that fell
+// through (rejected, cancelled, refunded).
+func (r *DealRepo) SumCampaignPrices(ctx context.Context, campaignID uuid.UUID) (total string, count int, err error) {
+	err = r.pool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(price_ton), 0)::text, COUNT(*) FROM deals
+		WHERE campaign_id = $1 AND status NOT IN ('rejected', 'cancelled', 'refunded')
+	`, campaignID).Scan(&total, &count)
+	return total, count, err
+}
+
+// CountOpenDealsForUser counts deals the user is party to (as advertiser or channel member)
+// that are still in progress or have funds held in escrow.
+func (r *DealRepo) CountOpenDealsForUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	var n int
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM deals d
+		LEFT JOIN escrow_ledger e ON e.deal_id = d.id
+		WHERE (d.advertiser_user_id = $1
+		       OR d.channel_id IN (SELECT channel_id FROM channel_members WHERE user_id = $1))
+		  AND (d.status <> ALL($2) OR e.status = 'funded')
+	`, userID, models.DealStatusesSettled).Scan(&n)
+	return n, err
 }
 
 func (r *DealRepo) List(ctx context.Context, f DealFilter) ([]models.Deal, error) {
 	query := `
 		SELECT d.id, d.channel_id, d.advertiser_user_id, d.status, d.ad_format, d.brief, d.scheduled_at,
-		       d.price_ton, d.platform_fee_bps, d.hold_period_seconds, d.created_at, d.updated_at
+		       d.price_ton, d.platform_fee_bps, d.hold_period_seconds, d.skip_creative_approval, d.payout_frozen, d.campaign_id, d.created_at, d.updated_at
 		FROM deals d
 	`
 	args := []any{}
@@ -207,7 +370,7 @@ func (r *DealRepo) List(ctx context.Context, f DealFilter) ([]models.Deal, error
 	for rows.Next() {
 		var d models.Deal
 		if err := rows.Scan(&d.ID, &d.ChannelID, &d.AdvertiserUserID, &d.Status, &d.AdFormat, &d.Brief, &d.ScheduledAt,
-			&d.PriceTON, &d.PlatformFeeBPS, &d.HoldPeriodSeconds, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			&d.PriceTON, &d.PlatformFeeBPS, &d.HoldPeriodSeconds, &d.SkipCreativeApproval, &d.PayoutFrozen, &d.CampaignID, &d.CreatedAt, &d.UpdatedAt); err != nil {
 			return nil, err
 		}
 		deals = append(deals, d)
@@ -215,13 +378,14 @@ func (r *DealRepo) List(ctx context.Context, f DealFilter) ([]models.Deal, error
 	return deals, nil
 }
 
-func (r *DealRepo) GetTimedOutDeals(ctx context.Context, status string, timeoutSeconds int) ([]models.Deal, error) {
+// GetTimedOutDeals returns deals stuck in status since before cutoff (see models.DealTimeoutCutoff).
+func (r *DealRepo) GetTimedOutDeals(ctx context.Context, status string, cutoff time.Time) ([]models.Deal, error) {
 	rows, err := r.pool.Query(ctx, `
 		SELECT id, channel_id, advertiser_user_id, status, ad_format, brief, scheduled_at,
-		       price_ton, platform_fee_bps, hold_period_seconds, created_at, updated_at
+		       price_ton, platform_fee_bps, hold_period_seconds, skip_creative_approval, payout_frozen, campaign_id, created_at, updated_at
 		FROM deals
-		WHERE status = $1 AND updated_at < now() - ($2 || ' seconds')::interval
-	`, status, fmt.Sprintf("%d", timeoutSeconds))
+		WHERE status = $1 AND updated_at < $2
+	`, status, cutoff)
 	if err != nil {
 		return nil, err
 	}
@@ -231,7 +395,7 @@ func (r *DealRepo) GetTimedOutDeals(ctx context.Context, status string, timeoutS
 	for rows.Next() {
 		var d models.Deal
 		if err := rows.Scan(&d.ID, &d.ChannelID, &d.AdvertiserUserID, &d.Status, &d.AdFormat, &d.Brief, &d.ScheduledAt,
-			&d.PriceTON, &d.PlatformFeeBPS, &d.HoldPeriodSeconds, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			&d.PriceTON, &d.PlatformFeeBPS, &d.HoldPeriodSeconds, &d.SkipCreativeApproval, &d.PayoutFrozen, &d.CampaignID, &d.CreatedAt, &d.UpdatedAt); err != nil {
 			return nil, err
 		}
 		deals = append(deals, d)
@@ -239,17 +403,105 @@ func (r *DealRepo) GetTimedOutDeals(ctx context.Context, status string, timeoutS
 	return deals, nil
 }
 
-func (r *DealRepo) GetPostedDealsInHold(ctx context.Context) ([]models.Deal, error) {
+// SetPayoutFrozen sets the admin freeze on a deal's automatic release.
+func (r *DealRepo) SetPayoutFrozen(ctx context.Context, id uuid.UUID, frozen bool) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE deals SET payout_frozen = $2, updated_at = now() WHERE id = $1
+	`, id, frozen)
+	return err
+}
+
+// releaseBackoffClause skips deals still backing off after a failed release attempt.
+// Expects now at $1, base and max backoff in seconds at $2 and $3, and escrow_ledger as el.
+const releaseBackoffClause = `(el.last_release_attempt_at IS NULL OR el.last_release_attempt_at +
+		make_interval(secs => LEAST($2 * power(2, el.release_attempts - 1), $3)) <= $1)`
+
+// GetPostedDealsInHold returns non-story deals whose hold has elapsed with the post intact.
+// Deals whose last release attempt failed are skipped until their backoff (retryBase
+// doubling per attempt, capped at retryMax, see models.ReleaseRetryDelay) has passed.
+func (r *DealRepo) GetPostedDealsInHold(ctx context.Context, now time.Time, retryBase, retryMax time.Duration) ([]models.Deal, error) {
 	rows, err := r.pool.Query(ctx, `
 		SELECT d.id, d.channel_id, d.advertiser_user_id, d.status, d.ad_format, d.brief, d.scheduled_at,
-		       d.price_ton, d.platform_fee_bps, d.hold_period_seconds, d.created_at, d.updated_at
+		       d.price_ton, d.platform_fee_bps, d.hold_period_seconds, d.skip_creative_approval, d.payout_frozen, d.campaign_id, d.created_at, d.updated_at
 		FROM deals d
 		JOIN deal_posts dp ON dp.deal_id = d.id
+		LEFT JOIN escrow_ledger el ON el.deal_id = d.id
 		WHERE d.status = 'hold_verification'
-		  AND dp.posted_at + (d.hold_period_seconds || ' seconds')::interval < now()
+		  AND d.ad_format <> 'story'
+		  AND dp.posted_at + (d.hold_period_seconds || ' seconds')::interval < $1
 		  AND dp.is_deleted = false
 		  AND dp.is_edited = false
-	`)
+		  AND d.payout_frozen = false
+		  AND `+releaseBackoffClause+`
+	`, now, retryBase.Seconds(), retryMax.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deals []models.Deal
+	for rows.Next() {
+		var d models.Deal
+		if err := rows.Scan(&d.ID, &d.ChannelID, &d.AdvertiserUserID, &d.Status, &d.AdFormat, &d.Brief, &d.ScheduledAt,
+			&d.PriceTON, &d.PlatformFeeBPS, &d.HoldPeriodSeconds, &d.SkipCreativeApproval, &d.PayoutFrozen, &d.CampaignID, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, err
+		}
+		deals = append(deals, d)
+	}
+	return deals, nil
+}
+
+// GetStoryDealsInHold mirrors GetPostedDealsInHold for stories: a story's hold ends at
+// the hold period or when the story expires on its own, whichever comes first, and only
+// once the userbot has seen it live (story_views is set).
+func (r *DealRepo) GetStoryDealsInHold(ctx context.Context, now time.Time, retryBase, retryMax time.Duration) ([]models.Deal, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT d.id, d.channel_id, d.advertiser_user_id, d.status, d.ad_format, d.brief, d.scheduled_at,
+		       d.price_ton, d.platform_fee_bps, d.hold_period_seconds, d.skip_creative_approval, d.payout_frozen, d.campaign_id, d.created_at, d.updated_at
+		FROM deals d
+		JOIN deal_posts dp ON dp.deal_id = d.id
+		LEFT JOIN escrow_ledger el ON el.deal_id = d.id
+		WHERE d.status = 'hold_verification'
+		  AND d.ad_format = 'story'
+		  AND LEAST(dp.posted_at + (d.hold_period_seconds || ' seconds')::interval,
+		            COALESCE(dp.story_expires_at, 'infinity')) < $1
+		  AND dp.story_views IS NOT NULL
+		  AND dp.is_deleted = false
+		  AND d.payout_frozen = false
+		  AND `+releaseBackoffClause+`
+	`, now, retryBase.Seconds(), retryMax.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deals []models.Deal
+	for rows.Next() {
+		var d models.Deal
+		if err := rows.Scan(&d.ID, &d.ChannelID, &d.AdvertiserUserID, &d.Status, &d.AdFormat, &d.Brief, &d.ScheduledAt,
+			&d.PriceTON, &d.PlatformFeeBPS, &d.HoldPeriodSeconds, &d.SkipCreativeApproval, &d.PayoutFrozen, &d.CampaignID, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, err
+		}
+		deals = append(deals, d)
+	}
+	return deals, nil
+}
+
+// GetDueScheduledDeals returns scheduled deals whose posting time has come, oldest first.
+// Deals whose last bot post attempt failed are skipped until their backoff (retryBase
+// doubling per attempt, capped at retryMax) has passed.
+func (r *DealRepo) GetDueScheduledDeals(ctx context.Context, now time.Time, retryBase, retryMax time.Duration, limit int) ([]models.Deal, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, channel_id, advertiser_user_id, status, ad_format, brief, scheduled_at,
+		       price_ton, platform_fee_bps, hold_period_seconds, skip_creative_approval, payout_frozen, campaign_id, created_at, updated_at
+		FROM deals
+		WHERE status = 'scheduled'
+		  AND scheduled_at <= $1
+		  AND (last_post_attempt_at IS NULL OR last_post_attempt_at +
+		       make_interval(secs => LEAST($2 * power(2, post_attempts - 1), $3)) <= $1)
+		ORDER BY scheduled_at
+		LIMIT $4
+	`, now, retryBase.Seconds(), retryMax.Seconds(), limit)
 	if err != nil {
 		return nil, err
 	}
@@ -259,7 +511,7 @@ func (r *DealRepo) GetPostedDealsInHold(ctx context.Context) ([]models.Deal, err
 	for rows.Next() {
 		var d models.Deal
 		if err := rows.Scan(&d.ID, &d.ChannelID, &d.AdvertiserUserID, &d.Status, &d.AdFormat, &d.Brief, &d.ScheduledAt,
-			&d.PriceTON, &d.PlatformFeeBPS, &d.HoldPeriodSeconds, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			&d.PriceTON, &d.PlatformFeeBPS, &d.HoldPeriodSeconds, &d.SkipCreativeApproval, &d.PayoutFrozen, &d.CampaignID, &d.CreatedAt, &d.UpdatedAt); err != nil {
 			return nil, err
 		}
 		deals = append(deals, d)
@@ -267,6 +519,123 @@ func (r *DealRepo) GetPostedDealsInHold(ctx context.Context) ([]models.Deal, err
 	return deals, nil
 }
 
+// RecordPostAttempt counts a failed bot post attempt and returns the attempts so far.
+func (r *DealRepo) RecordPostAttempt(ctx context.Context, dealID uuid.UUID, at time.Time, cause string) (int, error) {
+	var attempts int
+	err := r.pool.QueryRow(ctx, `
+		UPDATE deals SET post_attempts = post_attempts + 1, last_post_attempt_at = $2, last_post_error = $3
+		WHERE id = $1
+		RETURNING post_attempts
+	`, dealID, at, cause).Scan(&attempts)
+	return attempts, notFound(err)
+}
+
+// ---- Counteroffers ----
+
+// CreateCounteroffer stores a new pending proposal, superseding the deal's previous pending one.
+func (r *DealRepo) CreateCounteroffer(ctx context.Context, c *models.DealCounteroffer) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE deal_counteroffers SET status = 'superseded', resolved_at = now()
+		WHERE deal_id = $1 AND status = 'pending'
+	`, c.DealID); err != nil {
+		return err
+	}
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO deal_counteroffers (deal_id, proposed_by, price_ton)
+		VALUES ($1, $2, $3::numeric)
+		RETURNING id, price_ton::text, status, created_at
+	`, c.DealID, c.ProposedBy, c.PriceTON).Scan(&c.ID, &c.PriceTON, &c.Status, &c.CreatedAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+const counterofferColumns = `id, deal_id, proposed_by, price_ton::text, status, created_at, resolved_at`
+
+func scanCounteroffer(row pgx.Row) (*models.DealCounteroffer, error) {
+	var c models.DealCounteroffer
+	if err := row.Scan(&c.ID, &c.DealID, &c.ProposedBy, &c.PriceTON, &c.Status, &c.CreatedAt, &c.ResolvedAt); err != nil {
+		return nil, notFound(err)
+	}
+	return &c, nil
+}
+
+func (r *DealRepo) GetPendingCounteroffer(ctx context.Context, dealID uuid.UUID) (*models.DealCounteroffer, error) {
+	return scanCounteroffer(r.pool.QueryRow(ctx, `
+		SELECT `+counterofferColumns+` FROM deal_counteroffers WHERE deal_id = $1 AND status = 'pending'
+	`, dealID))
+}
+
+// ListCounteroffers returns the deal's negotiation history, oldest first.
+func (r *DealRepo) ListCounteroffers(ctx context.Context, dealID uuid.UUID) ([]models.DealCounteroffer, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+counterofferColumns+` FROM deal_counteroffers WHERE deal_id = $1 ORDER BY created_at
+	`, dealID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.DealCounteroffer
+	for rows.Next() {
+		c, err := scanCounteroffer(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *c)
+	}
+	return out, rows.Err()
+}
+
+// AcceptCounteroffer marks a pending proposal accepted and sets the deal's price to it, in one
+// transaction. Fails with ErrNotFound if the proposal is no longer pending, and with
+// ErrStatusChanged if the deal is no longer submitted (e.g. cancelled meanwhile).
+func (r *DealRepo) AcceptCounteroffer(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var dealID uuid.UUID
+	var price string
+	err = tx.QueryRow(ctx, `
+		UPDATE deal_counteroffers SET status = 'accepted', resolved_at = now()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING deal_id, price_ton::text
+	`, id).Scan(&dealID, &price)
+	if err != nil {
+		return notFound(err)
+	}
+	tag, err := tx.Exec(ctx, `
+		UPDATE deals SET price_ton = $1::numeric, updated_at = now() WHERE id = $2 AND status = 'submitted'
+	`, price, dealID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrStatusChanged
+	}
+	return tx.Commit(ctx)
+}
+
+// RejectCounteroffer marks a pending proposal rejected. Fails with ErrNotFound if it is no longer pending.
+func (r *DealRepo) RejectCounteroffer(ctx context.Context, id uuid.UUID) error {
+	var rejectedID uuid.UUID
+	err := r.pool.QueryRow(ctx, `
+		UPDATE deal_counteroffers SET status = 'rejected', resolved_at = now()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING id
+	`, id).Scan(&rejectedID)
+	return notFound(err)
+}
+
 // ---- Creatives ----
 
 func (r *DealRepo) CreateCreative(ctx context.Context, c *models.DealCreative) error {
@@ -274,31 +643,95 @@ func (r *DealRepo) CreateCreative(ctx context.Context, c *models.DealCreative) e
 	buttonsBytes, _ := json.Marshal(c.ButtonsJSON)
 	return r.pool.QueryRow(ctx, `
 		INSERT INTO deal_creatives (deal_id, version, owner_composed_text, advertiser_materials_text, status,
-		                            repost_from_chat_id, repost_from_msg_id, repost_from_url, media_urls, buttons_json)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		                            repost_from_chat_id, repost_from_msg_id, repost_from_url, media_urls, buttons_json,
+		                            moderation_reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id, created_at
 	`, c.DealID, c.Version, c.OwnerComposedText, c.AdvertiserMaterialsText, c.Status,
 		c.RepostFromChatID, c.RepostFromMsgID, c.RepostFromURL, mediaBytes, buttonsBytes,
+		c.ModerationReason,
 	).Scan(&c.ID, &c.CreatedAt)
 }
 
-func (r *DealRepo) GetLatestCreative(ctx context.Context, dealID uuid.UUID) (*models.DealCreative, error) {
+const creativeColumns = `id, deal_id, version, owner_composed_text, advertiser_materials_text, status,
+		       repost_from_chat_id, repost_from_msg_id, repost_from_url, media_urls, buttons_json, moderation_reason, created_at`
+
+func scanCreative(row pgx.Row) (*models.DealCreative, error) {
 	var c models.DealCreative
 	var mediaBytes, buttonsBytes []byte
-	err := r.pool.QueryRow(ctx, `
-		SELECT id, deal_id, version, owner_composed_text, advertiser_materials_text, status,
-		       repost_from_chat_id, repost_from_msg_id, repost_from_url, media_urls, buttons_json, created_at
-		FROM deal_creatives WHERE deal_id = $1 ORDER BY version DESC LIMIT 1
-	`, dealID).Scan(&c.ID, &c.DealID, &c.Version, &c.OwnerComposedText, &c.AdvertiserMaterialsText, &c.Status,
-		&c.RepostFromChatID, &c.RepostFromMsgID, &c.RepostFromURL, &mediaBytes, &buttonsBytes, &c.CreatedAt)
-	if err != nil {
-		return nil, err
+	if err := row.Scan(&c.ID, &c.DealID, &c.Version, &c.OwnerComposedText, &c.AdvertiserMaterialsText, &c.Status,
+		&c.RepostFromChatID, &c.RepostFromMsgID, &c.RepostFromURL, &mediaBytes, &buttonsBytes, &c.ModerationReason, &c.CreatedAt); err != nil {
+		return nil, notFound(err)
 	}
 	_ = json.Unmarshal(mediaBytes, &c.MediaURLs)
 	_ = json.Unmarshal(buttonsBytes, &c.ButtonsJSON)
 	return &c, nil
 }
 
+func (r *DealRepo) GetLatestCreative(ctx context.Context, dealID uuid.UUID) (*models.DealCreative, error) {
+	return scanCreative(r.pool.QueryRow(ctx, `
+		SELECT `+creativeColumns+`
+		FROM deal_creatives WHERE deal_id = $1 ORDER BY version DESC LIMIT 1
+	`, dealID))
+}
+
+// ListCreatives returns every version of the deal's creative, oldest first.
+func (r *DealRepo) ListCreatives(ctx context.Context, dealID uuid.UUID) ([]models.DealCreative, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+creativeColumns+`
+		FROM deal_creatives WHERE deal_id = $1 ORDER BY version ASC
+	`, dealID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var creatives []models.DealCreative
+	for rows.Next() {
+		c, err := scanCreative(rows)
+		if err != nil {
+			return nil, err
+		}
+		creatives = append(creatives, *c)
+	}
+	return creatives, rows.Err()
+}
+
+func (r *DealRepo) GetCreativeByID(ctx context.Context, id uuid.UUID) (*models.DealCreative, error) {
+	return scanCreative(r.pool.QueryRow(ctx, `SELECT `+creativeColumns+` FROM deal_creatives WHERE id = $1`, id))
+}
+
+// ListCreativesByStatus returns creatives in the given status, oldest first (review queue order).
+func (r *DealRepo) ListCreativesByStatus(ctx context.Context, status string, limit, offset int) ([]models.DealCreative, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+creativeColumns+`
+		FROM deal_creatives WHERE status = $1
+		ORDER BY created_at ASC LIMIT $2 OFFSET $3
+	`, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var creatives []models.DealCreative
+	for rows.Next() {
+		c, err := scanCreative(rows)
+		if err != nil {
+			return nil, err
+		}
+		creatives = append(creatives, *c)
+	}
+	return creatives, nil
+}
+
+func (r *DealRepo) UpdateCreativeModeration(ctx context.Context, id uuid.UUID, status string, reason *string) error {
+	_, err := r.pool.Exec(ctx, `UPDATE deal_creatives SET status = $1, moderation_reason = $2 WHERE id = $3`, status, reason, id)
+	return err
+}
+
 func (r *DealRepo) UpdateCreativeStatus(ctx context.Context, id uuid.UUID, status string) error {
 	_, err := r.pool.Exec(ctx, `UPDATE deal_creatives SET status = $1 WHERE id = $2`, status, id)
 	return err
@@ -317,35 +750,84 @@ func (r *DealRepo) GetCreativeMaxVersion(ctx context.Context, dealID uuid.UUID)
 
 func (r *DealRepo) UpsertPost(ctx context.Context, p *models.DealPost) error {
 	return r.pool.QueryRow(ctx, `
-		INSERT INTO deal_posts (deal_id, telegram_message_id, telegram_chat_id, post_url, content_hash, posted_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO deal_posts (deal_id, telegram_message_id, telegram_chat_id, post_url, content_hash, posted_at,
+		                        ad_format, story_id, story_expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		ON CONFLICT (deal_id) DO UPDATE SET
 			telegram_message_id = COALESCE(EXCLUDED.telegram_message_id, deal_posts.telegram_message_id),
 			telegram_chat_id = COALESCE(EXCLUDED.telegram_chat_id, deal_posts.telegram_chat_id),
 			post_url = COALESCE(EXCLUDED.post_url, deal_posts.post_url),
 			content_hash = COALESCE(EXCLUDED.content_hash, deal_posts.content_hash),
-			posted_at = COALESCE(EXCLUDED.posted_at, deal_posts.posted_at)
+			posted_at = COALESCE(EXCLUDED.posted_at, deal_posts.posted_at),
+			ad_format = COALESCE(EXCLUDED.ad_format, deal_posts.ad_format),
+			story_id = COALESCE(EXCLUDED.story_id, deal_posts.story_id),
+			story_expires_at = COALESCE(EXCLUDED.story_expires_at, deal_posts.story_expires_at)
 		RETURNING id
-	`, p.DealID, p.TelegramMessageID, p.TelegramChatID, p.PostURL, p.ContentHash, p.PostedAt).Scan(&p.ID)
+	`, p.DealID, p.TelegramMessageID, p.TelegramChatID, p.PostURL, p.ContentHash, p.PostedAt,
+		p.AdFormat, p.StoryID, p.StoryExpiresAt).Scan(&p.ID)
 }
 
 func (r *DealRepo) GetPost(ctx context.Context, dealID uuid.UUID) (*models.DealPost, error) {
 	var p models.DealPost
 	err := r.pool.QueryRow(ctx, `
 		SELECT id, deal_id, telegram_message_id, telegram_chat_id, post_url, content_hash,
-		       posted_at, last_checked_at, is_deleted, is_edited
+		       posted_at, last_checked_at, is_deleted, is_edited,
+		       ad_format, story_expires_at, story_id, story_views
 		FROM deal_posts WHERE deal_id = $1
 	`, dealID).Scan(&p.ID, &p.DealID, &p.TelegramMessageID, &p.TelegramChatID, &p.PostURL, &p.ContentHash,
-		&p.PostedAt, &p.LastCheckedAt, &p.IsDeleted, &p.IsEdited)
+		&p.PostedAt, &p.LastCheckedAt, &p.IsDeleted, &p.IsEdited,
+		&p.AdFormat, &p.StoryExpiresAt, &p.StoryID, &p.StoryViews)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &p, nil
 }
 
+// MarkPostChecked records that the post monitor looked at the deal's post at the given time.
+func (r *DealRepo) MarkPostChecked(ctx context.Context, dealID uuid.UUID, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE deal_posts SET last_checked_at = $1 WHERE deal_id = $2`, at, dealID)
+	return err
+}
+
+// SetStoryViews stores the view count the userbot reported for a live story.
+func (r *DealRepo) SetStoryViews(ctx context.Context, dealID uuid.UUID, views int) error {
+	_, err := r.pool.Exec(ctx, `UPDATE deal_posts SET story_views = $1 WHERE deal_id = $2`, views, dealID)
+	return err
+}
+
 func (r *DealRepo) UpdatePostFlags(ctx context.Context, dealID uuid.UUID, isDeleted, isEdited bool) error {
 	_, err := r.pool.Exec(ctx, `
 		UPDATE deal_posts SET is_deleted = $1, is_edited = $2, last_checked_at = now() WHERE deal_id = $3
 	`, isDeleted, isEdited, dealID)
 	return err
 }
+
+// GetOwnerEarnings sums the owner's net payout of deals paid in currency on channels the user
+// owns, by escrow status. Released escrows count release_amount_ton; funded ones the price minus
+// the platform fee, rounded down to nanoTON like CalculateRelease.
+func (r *DealRepo) GetOwnerEarnings(ctx context.Context, userID uuid.UUID, currency string) (map[string]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT e.status,
+		       SUM(COALESCE(e.release_amount_ton,
+		                    trunc(d.price_ton * (10000 - d.platform_fee_bps) / 10000, 9)))::text
+		FROM escrow_ledger e
+		JOIN deals d ON d.id = e.deal_id
+		JOIN channel_members cm ON cm.channel_id = d.channel_id AND cm.role = 'owner'
+		WHERE cm.user_id = $1 AND e.currency = $2
+		GROUP BY e.status
+	`, userID, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	earnings := make(map[string]string)
+	for rows.Next() {
+		var status, amount string
+		if err := rows.Scan(&status, &amount); err != nil {
+			return nil, err
+		}
+		earnings[status] = amount
+	}
+	return earnings, rows.Err()
+}
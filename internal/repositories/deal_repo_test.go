@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ads-marketplace/backend/internal/models"
+)
+
+// TestUpdateStatusIfRace runs two transitions from the same status concurrently against a
+// real database and expects exactly one to win; set TEST_POSTGRES_DSN to enable.
+func TestUpdateStatusIfRace(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+
+	user := testUser(t, pool)
+	ch := testChannel(t, pool, user, "race")
+	repo := NewDealRepo(pool)
+	deal := testDeal(t, pool, ch, user, models.DealStatusSubmitted)
+
+	// Owner accepts while the advertiser cancels: both read "submitted"
+	targets := []string{models.DealStatusAccepted, models.DealStatusCancelled}
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, status := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = repo.UpdateStatusIf(ctx, deal.ID, models.DealStatusSubmitted, status)
+		}()
+	}
+	wg.Wait()
+
+	var won string
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			if won != "" {
+				t.Fatalf("both transitions won")
+			}
+			won = targets[i]
+		case errors.Is(err, ErrStatusChanged):
+		default:
+			t.Fatalf("UpdateStatusIf(%s): %v", targets[i], err)
+		}
+	}
+	if won == "" {
+		t.Fatal("neither transition won")
+	}
+
+	got, err := repo.GetByID(ctx, deal.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Status != won {
+		t.Errorf("status = %s, want the winner %s", got.Status, won)
+	}
+}
+
+// TestListCreatives stores two creative versions and expects both back in version order
+// with media and buttons decoded; set TEST_POSTGRES_DSN to enable.
+func TestListCreatives(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+
+	user := testUser(t, pool)
+	ch := testChannel(t, pool, user, "creatives")
+	repo := NewDealRepo(pool)
+	deal := testDeal(t, pool, ch, user, models.DealStatusCreativeSubmitted)
+
+	for v, status := range []string{"changes_requested", "pending"} {
+		text := fmt.Sprintf("draft %d", v+1)
+		err := repo.CreateCreative(ctx, &models.DealCreative{
+			DealID: deal.ID, Version: v + 1, OwnerComposedText: &text, Status: status,
+			MediaURLs:   []string{fmt.Sprintf("https://example.com/%d.jpg", v+1)},
+			ButtonsJSON: []map[string]string{{"text": "Open", "url": "https://example.com"}},
+		})
+		if err != nil {
+			t.Fatalf("create creative v%d: %v", v+1, err)
+		}
+	}
+
+	list, err := repo.ListCreatives(ctx, deal.ID)
+	if err != nil {
+		t.Fatalf("ListCreatives: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("got %d creatives, want 2", len(list))
+	}
+	for i, c := range list {
+		if c.Version != i+1 {
+			t.Errorf("list[%d].Version = %d, want %d", i, c.Version, i+1)
+		}
+		media, ok := c.MediaURLs.([]any)
+		if !ok || len(media) != 1 || media[0] != fmt.Sprintf("https://example.com/%d.jpg", i+1) {
+			t.Errorf("list[%d].MediaURLs = %v", i, c.MediaURLs)
+		}
+		if buttons, ok := c.ButtonsJSON.([]any); !ok || len(buttons) != 1 {
+			t.Errorf("list[%d].ButtonsJSON = %v", i, c.ButtonsJSON)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrNotFound is returned when a lookup matches no row. Callers branch on it with
+// errors.Is instead of depending on the pgx driver.
+var ErrNotFound = errors.New("not found")
+
+// ErrStatusChanged is returned by conditional status updates when the row is no longer in
+// the status the caller read: someone else changed it in the meantime.
+var ErrStatusChanged = errors.New("status changed concurrently")
+
+// ErrTokenReused is returned when an already rotated refresh token is presented again:
+// it was likely stolen, so its whole family has been revoked.
+var ErrTokenReused = errors.New("refresh token reused")
+
+const (
+	pgUniqueViolation = "23505" // unique_violation
+	pgQueryCanceled   = "57014" // query_canceled, raised by statement_timeout
+)
+
+// IsUniqueViolation reports whether err is a Postgres unique constraint violation.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
+
+// IsQueryTimeout reports whether a query was cut off by the context deadline or by
+// Postgres statement_timeout.
+func IsQueryTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgQueryCanceled
+}
+
+// notFound translates pgx.ErrNoRows into ErrNotFound; other errors pass through.
+func notFound(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
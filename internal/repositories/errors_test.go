@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	duplicate := &pgconn.PgError{Code: "23505", ConstraintName: "channels_username_key"}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"duplicate username", duplicate, true},
+		{"wrapped duplicate", fmt.Errorf("create channel: %w", duplicate), true},
+		{"other pg error", &pgconn.PgError{Code: "23503"}, false},
+		{"no rows", pgx.ErrNoRows, false},
+		{"plain error", errors.New("23505"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsUniqueViolation(tt.err); got != tt.expected {
+				t.Errorf("IsUniqueViolation(%v) = %v, want %v", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	dbErr := &pgconn.PgError{Code: "57014"}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{"nil", nil, nil},
+		{"no rows", pgx.ErrNoRows, ErrNotFound},
+		{"wrapped no rows", fmt.Errorf("scan: %w", pgx.ErrNoRows), ErrNotFound},
+		{"db error passes through", dbErr, dbErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notFound(tt.err); got != tt.expected {
+				t.Errorf("notFound(%v) = %v, want %v", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsQueryTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"context deadline", context.DeadlineExceeded, true},
+		{"wrapped deadline", fmt.Errorf("explore: %w", context.DeadlineExceeded), true},
+		{"statement_timeout", &pgconn.PgError{Code: "57014", Message: "canceling statement due to statement timeout"}, true},
+		{"cancelled by client", context.Canceled, false},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsQueryTimeout(tt.err); got != tt.expected {
+				t.Errorf("IsQueryTimeout(%v) = %v, want %v", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestQueryTimeoutSlowQuery runs pg_sleep against a real database; set TEST_POSTGRES_DSN to enable.
+func TestQueryTimeoutSlowQuery(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("statement_timeout", func(t *testing.T) {
+		pool := testPoolWithTimeout(t, 50*time.Millisecond)
+
+		_, err := pool.Exec(ctx, `SELECT pg_sleep(2)`)
+		if !IsQueryTimeout(err) {
+			t.Errorf("expected query timeout, got %v", err)
+		}
+	})
+
+	t.Run("context deadline", func(t *testing.T) {
+		pool := testPool(t)
+
+		qctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err := pool.Exec(qctx, `SELECT pg_sleep(2)`)
+		if !IsQueryTimeout(err) {
+			t.Errorf("expected query timeout, got %v", err)
+		}
+	})
+}
+
+// TestLookupNotFound checks that repos translate a missing row into ErrNotFound;
+// set TEST_POSTGRES_DSN to enable.
+func TestLookupNotFound(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+
+	missing := uuid.New()
+	lookups := []struct {
+		name string
+		call func() error
+	}{
+		{"user", func() error { _, err := NewUserRepo(pool).GetByID(ctx, missing); return err }},
+		{"deal", func() error { _, err := NewDealRepo(pool).GetByID(ctx, missing); return err }},
+		{"escrow", func() error { _, err := NewEscrowRepo(pool).GetByDealID(ctx, missing); return err }},
+		{"offer claim", func() error { return NewOfferRepo(pool).Claim(ctx, missing, missing) }},
+	}
+
+	for _, l := range lookups {
+		t.Run(l.name, func(t *testing.T) {
+			if err := l.call(); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
@@ -2,9 +2,12 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/ads-marketplace/backend/internal/models"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -18,26 +21,26 @@ func NewEscrowRepo(pool *pgxpool.Pool) *EscrowRepo {
 
 func (r *EscrowRepo) Create(ctx context.Context, e *models.EscrowLedger) error {
 	return r.pool.QueryRow(ctx, `
-		INSERT INTO escrow_ledger (deal_id, deposit_expected_ton, deposit_address, deposit_memo, status)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO escrow_ledger (deal_id, deposit_expected_ton, currency, deposit_address, deposit_memo, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id
-	`, e.DealID, e.DepositExpectedTON, e.DepositAddress, e.DepositMemo, e.Status).Scan(&e.ID)
+	`, e.DealID, e.DepositExpectedTON, e.Currency, e.DepositAddress, e.DepositMemo, e.Status).Scan(&e.ID)
 }
 
 func (r *EscrowRepo) GetByDealID(ctx context.Context, dealID uuid.UUID) (*models.EscrowLedger, error) {
 	var e models.EscrowLedger
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, deal_id, deposit_expected_ton, deposit_address, deposit_memo,
+		SELECT id, deal_id, deposit_expected_ton, currency, deposit_address, deposit_memo,
 		       funded_at, funding_tx_hash, payer_address,
 		       release_amount_ton, release_tx_hash,
-		       refunded_at, refund_tx_hash, status
+		       refunded_at, refund_tx_hash, refund_address, overpaid_nano::text, refund_status, status
 		FROM escrow_ledger WHERE deal_id = $1
-	`, dealID).Scan(&e.ID, &e.DealID, &e.DepositExpectedTON, &e.DepositAddress, &e.DepositMemo,
+	`, dealID).Scan(&e.ID, &e.DealID, &e.DepositExpectedTON, &e.Currency, &e.DepositAddress, &e.DepositMemo,
 		&e.FundedAt, &e.FundingTxHash, &e.PayerAddress,
 		&e.ReleaseAmountTON, &e.ReleaseTxHash,
-		&e.RefundedAt, &e.RefundTxHash, &e.Status)
+		&e.RefundedAt, &e.RefundTxHash, &e.RefundAddress, &e.OverpaidNano, &e.RefundStatus, &e.Status)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &e, nil
 }
@@ -45,17 +48,17 @@ func (r *EscrowRepo) GetByDealID(ctx context.Context, dealID uuid.UUID) (*models
 func (r *EscrowRepo) GetByMemo(ctx context.Context, memo string) (*models.EscrowLedger, error) {
 	var e models.EscrowLedger
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, deal_id, deposit_expected_ton, deposit_address, deposit_memo,
+		SELECT id, deal_id, deposit_expected_ton, currency, deposit_address, deposit_memo,
 		       funded_at, funding_tx_hash, payer_address,
 		       release_amount_ton, release_tx_hash,
-		       refunded_at, refund_tx_hash, status
+		       refunded_at, refund_tx_hash, refund_address, overpaid_nano::text, refund_status, status
 		FROM escrow_ledger WHERE deposit_memo = $1
-	`, memo).Scan(&e.ID, &e.DealID, &e.DepositExpectedTON, &e.DepositAddress, &e.DepositMemo,
+	`, memo).Scan(&e.ID, &e.DealID, &e.DepositExpectedTON, &e.Currency, &e.DepositAddress, &e.DepositMemo,
 		&e.FundedAt, &e.FundingTxHash, &e.PayerAddress,
 		&e.ReleaseAmountTON, &e.ReleaseTxHash,
-		&e.RefundedAt, &e.RefundTxHash, &e.Status)
+		&e.RefundedAt, &e.RefundTxHash, &e.RefundAddress, &e.OverpaidNano, &e.RefundStatus, &e.Status)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &e, nil
 }
@@ -68,18 +71,419 @@ func (r *EscrowRepo) MarkFunded(ctx context.Context, dealID uuid.UUID, txHash, p
 	return err
 }
 
-func (r *EscrowRepo) MarkReleased(ctx context.Context, dealID uuid.UUID, amount, txHash string) error {
+// MarkFundedAtCursor funds the escrow and advances the indexer cursor of wallet to the
+// funding transaction in one DB transaction. Fails with ErrNotFound, leaving the cursor as is,
+// if the escrow is no longer awaiting (e.g. expired since it was read).
+func (r *EscrowRepo) MarkFundedAtCursor(ctx context.Context, dealID uuid.UUID, txHash, payerAddr, overpaidNano, wallet string, lt uint64, hash []byte) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE escrow_ledger SET status = 'funded', funded_at = now(), funding_tx_hash = $1, payer_address = $2,
+		       overpaid_nano = NULLIF($4, '')::numeric, funding_lt = $5
+		WHERE deal_id = $3 AND status = 'awaiting'
+	`, txHash, payerAddr, dealID, overpaidNano, int64(lt))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.Exec(ctx, upsertIndexerCursorSQL, wallet, int64(lt), hash); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ListFundingLTsSince maps deals to the LT of the indexed transaction that funded their
+// escrow, for funding transactions at or after lt.
+func (r *EscrowRepo) ListFundingLTsSince(ctx context.Context, lt uint64) (map[uuid.UUID]uint64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT deal_id, funding_lt FROM escrow_ledger WHERE funding_lt >= $1
+	`, int64(lt))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fundings := make(map[uuid.UUID]uint64)
+	for rows.Next() {
+		var dealID uuid.UUID
+		var fundingLT int64
+		if err := rows.Scan(&dealID, &fundingLT); err != nil {
+			return nil, err
+		}
+		fundings[dealID] = uint64(fundingLT)
+	}
+	return fundings, rows.Err()
+}
+
+// ListUnrefundedOverpayments returns deals whose TON funding payment exceeded the expected
+// amount and whose excess has not been claimed for refund yet.
+func (r *EscrowRepo) ListUnrefundedOverpayments(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT e.deal_id FROM escrow_ledger e
+		WHERE e.overpaid_nano IS NOT NULL AND e.currency = 'TON'
+		  AND NOT EXISTS (SELECT 1 FROM overpayment_refunds o WHERE o.deal_id = e.deal_id)
+		ORDER BY e.funded_at
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ClaimOverpaymentRefund records a refund of the overpayment funded by fundingLT as 'sending'.
+// Returns false if that overpayment was already claimed: the caller must not send again.
+func (r *EscrowRepo) ClaimOverpaymentRefund(ctx context.Context, fundingLT uint64, dealID uuid.UUID, amountNano, toAddress, comment string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO overpayment_refunds (funding_lt, deal_id, amount_nano, to_address, tx_comment)
+		VALUES ($1, $2, $3::numeric, $4, $5)
+		ON CONFLICT (funding_lt) DO NOTHING
+	`, int64(fundingLT), dealID, amountNano, toAddress, comment)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *EscrowRepo) MarkOverpaymentRefundSent(ctx context.Context, fundingLT uint64, txHash string) error {
 	_, err := r.pool.Exec(ctx, `
+		UPDATE overpayment_refunds SET status = 'sent', tx_hash = $2, updated_at = now()
+		WHERE funding_lt = $1 AND status = 'sending'
+	`, int64(fundingLT), txHash)
+	return err
+}
+
+// MarkOverpaymentRefundFailed keeps the claim: the transfer may still land, so support
+// re-sends only after checking the chain.
+func (r *EscrowRepo) MarkOverpaymentRefundFailed(ctx context.Context, fundingLT uint64) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE overpayment_refunds SET status = 'failed', updated_at = now()
+		WHERE funding_lt = $1 AND status = 'sending'
+	`, int64(fundingLT))
+	return err
+}
+
+// MarkExpired expires the deal's escrow if it is still awaiting payment. A missing or already
+// funded escrow is left alone.
+func (r *EscrowRepo) MarkExpired(ctx context.Context, dealID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE escrow_ledger SET status = 'expired' WHERE deal_id = $1 AND status = 'awaiting'
+	`, dealID)
+	return err
+}
+
+// MarkReleased releases the deal's escrow and credits amount, in the escrow's currency, to
+// the owner's balance in one DB transaction, so the credit can't outlive the funds. Fails
+// with ErrStatusChanged, crediting nothing, unless the escrow is funded with no refund queued.
+func (r *EscrowRepo) MarkReleased(ctx context.Context, dealID, ownerID uuid.UUID, amount, txHash string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var currency string
+	err = tx.QueryRow(ctx, `
 		UPDATE escrow_ledger SET status = 'released', release_amount_ton = $1, release_tx_hash = $2
-		WHERE deal_id = $3 AND status = 'funded'
-	`, amount, txHash, dealID)
+		WHERE deal_id = $3 AND status = 'funded' AND refund_status IS NULL
+		RETURNING currency
+	`, amount, txHash, dealID).Scan(&currency)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrStatusChanged
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO balance_ledger (user_id, amount_ton, currency, deal_id)
+		VALUES ($1, $2, $3, $4)
+	`, ownerID, amount, currency, dealID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// RecordReleaseAttempt counts a failed release of the deal's escrow and returns the total
+// so far. Fails with ErrNotFound if the deal has no escrow.
+func (r *EscrowRepo) RecordReleaseAttempt(ctx context.Context, dealID uuid.UUID, at time.Time) (int, error) {
+	var attempts int
+	err := r.pool.QueryRow(ctx, `
+		UPDATE escrow_ledger SET release_attempts = release_attempts + 1, last_release_attempt_at = $2
+		WHERE deal_id = $1
+		RETURNING release_attempts
+	`, dealID, at).Scan(&attempts)
+	return attempts, notFound(err)
+}
+
+// QueueRefund marks the deal's funded escrow for refund to the payer. No-op if it is not
+// funded or a refund is already queued. Callers queue before moving the deal to refunded;
+// the refund is only claimed once the deal is refunded.
+func (r *EscrowRepo) QueueRefund(ctx context.Context, dealID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE escrow_ledger SET refund_status = 'pending'
+		WHERE deal_id = $1 AND status = 'funded' AND refund_status IS NULL
+	`, dealID)
 	return err
 }
 
+// ListPendingRefunds returns refunded deals whose TON escrow is queued for refund.
+func (r *EscrowRepo) ListPendingRefunds(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT e.deal_id FROM escrow_ledger e
+		JOIN deals d ON d.id = e.deal_id AND d.status = 'refunded'
+		WHERE e.refund_status = 'pending' AND e.status = 'funded' AND e.currency = 'TON'
+		ORDER BY e.funded_at
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ClaimRefund moves a queued refund to 'sending'. Returns false if it was not queued (already
+// claimed, sent or failed) or the deal is not refunded yet: the caller must not send.
+func (r *EscrowRepo) ClaimRefund(ctx context.Context, dealID uuid.UUID) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE escrow_ledger SET refund_status = 'sending'
+		WHERE deal_id = $1 AND status = 'funded' AND refund_status = 'pending'
+		  AND EXISTS (SELECT 1 FROM deals WHERE id = $1 AND status = 'refunded')
+	`, dealID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// MarkRefunded records the sent refund transfer of a claimed escrow.
 func (r *EscrowRepo) MarkRefunded(ctx context.Context, dealID uuid.UUID, txHash string) error {
 	_, err := r.pool.Exec(ctx, `
-		UPDATE escrow_ledger SET status = 'refunded', refunded_at = now(), refund_tx_hash = $1
-		WHERE deal_id = $2
+		UPDATE escrow_ledger SET status = 'refunded', refunded_at = now(), refund_tx_hash = $1, refund_status = 'sent'
+		WHERE deal_id = $2 AND status = 'funded' AND refund_status = 'sending'
 	`, txHash, dealID)
 	return err
 }
+
+// MarkRefundFailed keeps the claim: the transfer may still land, so support re-sends only
+// after checking the chain.
+func (r *EscrowRepo) MarkRefundFailed(ctx context.Context, dealID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE escrow_ledger SET refund_status = 'failed'
+		WHERE deal_id = $1 AND refund_status = 'sending'
+	`, dealID)
+	return err
+}
+
+// SetRefundAddress stores an approved refund destination override; only while funds are
+// still held, otherwise ErrStatusChanged.
+func (r *EscrowRepo) SetRefundAddress(ctx context.Context, dealID uuid.UUID, address string) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE escrow_ledger SET refund_address = $1
+		WHERE deal_id = $2 AND status = 'funded'
+	`, address, dealID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrStatusChanged
+	}
+	return nil
+}
+
+func (r *EscrowRepo) CreateRefundAddressRequest(ctx context.Context, req *models.RefundAddressRequest) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO refund_address_requests (deal_id, requested_by, wallet_id, address, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, req.DealID, req.RequestedBy, req.WalletID, req.Address, req.Status).Scan(&req.ID, &req.CreatedAt)
+}
+
+const refundAddressRequestColumns = `id, deal_id, requested_by, wallet_id, address, status, reviewed_by, reason, created_at, reviewed_at`
+
+func scanRefundAddressRequest(row pgx.Row) (*models.RefundAddressRequest, error) {
+	var req models.RefundAddressRequest
+	err := row.Scan(&req.ID, &req.DealID, &req.RequestedBy, &req.WalletID, &req.Address, &req.Status,
+		&req.ReviewedBy, &req.Reason, &req.CreatedAt, &req.ReviewedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &req, nil
+}
+
+func (r *EscrowRepo) GetRefundAddressRequest(ctx context.Context, id uuid.UUID) (*models.RefundAddressRequest, error) {
+	return scanRefundAddressRequest(r.pool.QueryRow(ctx,
+		`SELECT `+refundAddressRequestColumns+` FROM refund_address_requests WHERE id = $1`, id))
+}
+
+func (r *EscrowRepo) ListRefundAddressRequests(ctx context.Context, status string, limit, offset int) ([]models.RefundAddressRequest, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+refundAddressRequestColumns+` FROM refund_address_requests
+		WHERE status = $1
+		ORDER BY created_at DESC LIMIT $2 OFFSET $3
+	`, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reqs []models.RefundAddressRequest
+	for rows.Next() {
+		req, err := scanRefundAddressRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, *req)
+	}
+	return reqs, nil
+}
+
+// ResolveRefundAddressRequest atomically moves a pending request to approved/rejected.
+// Fails with ErrNotFound if it was already resolved or the reviewer is the requester.
+func (r *EscrowRepo) ResolveRefundAddressRequest(ctx context.Context, id, reviewedBy uuid.UUID, status string, reason *string) error {
+	var resolvedID uuid.UUID
+	err := r.pool.QueryRow(ctx, `
+		UPDATE refund_address_requests
+		SET status = $1, reviewed_by = $2, reason = $3, reviewed_at = now()
+		WHERE id = $4 AND status = 'pending' AND requested_by <> $2
+		RETURNING id
+	`, status, reviewedBy, reason, id).Scan(&resolvedID)
+	return notFound(err)
+}
+
+// RecordUnmatchedPayment stores a payment that matched no escrow. Re-recording the same
+// transaction (indexer retry) is a no-op.
+func (r *EscrowRepo) RecordUnmatchedPayment(ctx context.Context, p *models.UnmatchedPayment) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO unmatched_payments (tx_lt, tx_hash, from_address, amount, currency, memo)
+		VALUES ($1, $2, $3, $4::numeric, $5, $6)
+		ON CONFLICT (tx_lt) DO NOTHING
+	`, p.TxLT, p.TxHash, p.FromAddress, p.Amount, p.Currency, p.Memo)
+	return err
+}
+
+// RecordPendingPayment parks a payment whose deal memo has no escrow yet, to be retried by the
+// indexer until deadline. Re-recording the same transaction is a no-op.
+func (r *EscrowRepo) RecordPendingPayment(ctx context.Context, p *models.UnmatchedPayment, deadline time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO unmatched_payments (tx_lt, tx_hash, from_address, amount, currency, memo, status, match_deadline)
+		VALUES ($1, $2, $3, $4::numeric, $5, $6, 'pending', $7)
+		ON CONFLICT (tx_lt) DO NOTHING
+	`, p.TxLT, p.TxHash, p.FromAddress, p.Amount, p.Currency, p.Memo, deadline)
+	return err
+}
+
+// EscalatePendingPayments turns pending payments past their deadline into unmatched ones, for
+// the worker's reconciliation and staff. Returns how many were escalated.
+func (r *EscrowRepo) EscalatePendingPayments(ctx context.Context, now time.Time) (int, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE unmatched_payments SET status = 'unmatched'
+		WHERE status = 'pending' AND match_deadline <= $1
+	`, now)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+const unmatchedPaymentColumns = `id, tx_lt, tx_hash, from_address, amount::text, currency, memo, status, deal_id, matched_by, created_at, matched_at, match_deadline`
+
+// FindUnmatchedPayments lists payments in the given status, oldest first.
+func (r *EscrowRepo) FindUnmatchedPayments(ctx context.Context, status string, limit, offset int) ([]models.UnmatchedPayment, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+unmatchedPaymentColumns+` FROM unmatched_payments
+		WHERE status = $1
+		ORDER BY created_at LIMIT $2 OFFSET $3
+	`, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []models.UnmatchedPayment
+	for rows.Next() {
+		var p models.UnmatchedPayment
+		if err := rows.Scan(&p.ID, &p.TxLT, &p.TxHash, &p.FromAddress, &p.Amount, &p.Currency, &p.Memo,
+			&p.Status, &p.DealID, &p.MatchedBy, &p.CreatedAt, &p.MatchedAt, &p.MatchDeadline); err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
+
+// FundFromUnmatchedPayment funds the deal's awaiting escrow from a pending or unmatched payment
+// and marks the payment matched in one DB transaction. The funding tx reference is the payment's
+// LT, as for payments the indexer matches itself. Fails with ErrNotFound if the payment is already
+// matched or the escrow is no longer awaiting.
+func (r *EscrowRepo) FundFromUnmatchedPayment(ctx context.Context, paymentID, dealID uuid.UUID, overpaidNano string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var txLT int64
+	var txRef, payer string
+	err = tx.QueryRow(ctx, `
+		UPDATE unmatched_payments SET status = 'matched', deal_id = $2, matched_at = now()
+		WHERE id = $1 AND status IN ('pending', 'unmatched')
+		RETURNING tx_lt, tx_lt::text, from_address
+	`, paymentID, dealID).Scan(&txLT, &txRef, &payer)
+	if err != nil {
+		return notFound(err)
+	}
+	tag, err := tx.Exec(ctx, `
+		UPDATE escrow_ledger SET status = 'funded', funded_at = now(), funding_tx_hash = $1, payer_address = $2,
+		       overpaid_nano = NULLIF($4, '')::numeric, funding_lt = $5
+		WHERE deal_id = $3 AND status = 'awaiting'
+	`, txRef, payer, dealID, overpaidNano, txLT)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return tx.Commit(ctx)
+}
+
+// MarkUnmatchedPaymentMatched closes the unmatched payment with the given funding tx reference
+// (its LT) after staff credited it to a deal by hand. Unknown references are ignored.
+func (r *EscrowRepo) MarkUnmatchedPaymentMatched(ctx context.Context, txRef string, dealID, matchedBy uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE unmatched_payments SET status = 'matched', deal_id = $2, matched_by = $3, matched_at = now()
+		WHERE tx_lt::text = $1 AND status IN ('pending', 'unmatched')
+	`, txRef, dealID, matchedBy)
+	return err
+}
@@ -0,0 +1,290 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/ads-marketplace/backend/internal/ton"
+	"github.com/google/uuid"
+)
+
+// TestExpiredEscrowNotFunded cancels an awaiting deal, expires its escrow and expects a late
+// payment to be refused, both from the indexer and from unmatched-payment reconciliation;
+// set TEST_POSTGRES_DSN to enable.
+func TestExpiredEscrowNotFunded(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+
+	user := testUser(t, pool)
+	ch := testChannel(t, pool, user, "expired")
+	dealRepo := NewDealRepo(pool)
+	deal := testDeal(t, pool, ch, user, models.DealStatusAwaitingPayment)
+	repo := NewEscrowRepo(pool)
+	memo := fmt.Sprintf("deal-%d", rand.Int64N(1<<40))
+	escrow := &models.EscrowLedger{
+		DealID: deal.ID, DepositExpectedTON: "1", Currency: models.EscrowCurrencyTON,
+		DepositAddress: "EQtest", DepositMemo: memo, Status: models.EscrowStatusAwaiting,
+	}
+	if err := repo.Create(ctx, escrow); err != nil {
+		t.Fatalf("create escrow: %v", err)
+	}
+
+	// Payment timeout: the deal is cancelled and its escrow expires
+	if err := dealRepo.UpdateStatusIf(ctx, deal.ID, models.DealStatusAwaitingPayment, models.DealStatusCancelled); err != nil {
+		t.Fatalf("cancel deal: %v", err)
+	}
+	if err := repo.MarkExpired(ctx, deal.ID); err != nil {
+		t.Fatalf("MarkExpired: %v", err)
+	}
+
+	// The indexer read the escrow before it expired and now tries to fund it
+	lt := uint64(rand.Int64N(1 << 50))
+	err := repo.MarkFundedAtCursor(ctx, deal.ID, fmt.Sprint(lt), "EQpayer", "", "test-wallet", lt, []byte{1})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("MarkFundedAtCursor = %v, want ErrNotFound", err)
+	}
+
+	// The payment lands in unmatched_payments; crediting it to the dead deal is refused too
+	if err := repo.RecordUnmatchedPayment(ctx, &models.UnmatchedPayment{
+		TxLT: int64(lt), TxHash: "00", FromAddress: "EQpayer", Amount: "1000000000",
+		Currency: models.EscrowCurrencyTON, Memo: memo,
+	}); err != nil {
+		t.Fatalf("RecordUnmatchedPayment: %v", err)
+	}
+	var paymentID uuid.UUID
+	if err := pool.QueryRow(ctx, `SELECT id FROM unmatched_payments WHERE tx_lt = $1 AND status = 'unmatched'`, int64(lt)).Scan(&paymentID); err != nil {
+		t.Fatalf("find unmatched payment: %v", err)
+	}
+	if err := repo.FundFromUnmatchedPayment(ctx, paymentID, deal.ID, ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("FundFromUnmatchedPayment = %v, want ErrNotFound", err)
+	}
+
+	got, err := repo.GetByDealID(ctx, deal.ID)
+	if err != nil {
+		t.Fatalf("GetByDealID: %v", err)
+	}
+	if got.Status != models.EscrowStatusExpired || got.FundingTxHash != nil {
+		t.Errorf("escrow status = %s, funding tx = %v; want expired and unfunded", got.Status, got.FundingTxHash)
+	}
+}
+
+// TestPaymentBeforeEscrow records a payment that names a deal before its escrow exists,
+// keeps it pending through the grace window and credits it once the escrow opens; a
+// pending payment past its deadline is escalated to unmatched. Set TEST_POSTGRES_DSN to enable.
+func TestPaymentBeforeEscrow(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+
+	user := testUser(t, pool)
+	ch := testChannel(t, pool, user, "early")
+	deal := testDeal(t, pool, ch, user, models.DealStatusAwaitingPayment)
+	repo := NewEscrowRepo(pool)
+	memo := ton.DealMemo(deal.ID)
+	now := time.Now()
+
+	// The payment lands before the escrow is opened
+	lt := uint64(rand.Int64N(1 << 50))
+	if err := repo.RecordPendingPayment(ctx, &models.UnmatchedPayment{
+		TxLT: int64(lt), TxHash: "00", FromAddress: "EQpayer", Amount: "1000000000",
+		Currency: models.EscrowCurrencyTON, Memo: memo,
+	}, now.Add(time.Minute)); err != nil {
+		t.Fatalf("RecordPendingPayment: %v", err)
+	}
+	if _, err := repo.EscalatePendingPayments(ctx, now); err != nil {
+		t.Fatalf("EscalatePendingPayments: %v", err)
+	}
+	var paymentID uuid.UUID
+	if err := pool.QueryRow(ctx, `SELECT id FROM unmatched_payments WHERE tx_lt = $1 AND status = 'pending'`, int64(lt)).Scan(&paymentID); err != nil {
+		t.Fatalf("payment within grace window is no longer pending: %v", err)
+	}
+
+	escrow := &models.EscrowLedger{
+		DealID: deal.ID, DepositExpectedTON: "1", Currency: models.EscrowCurrencyTON,
+		DepositAddress: "EQtest", DepositMemo: memo, Status: models.EscrowStatusAwaiting,
+	}
+	if err := repo.Create(ctx, escrow); err != nil {
+		t.Fatalf("create escrow: %v", err)
+	}
+	if err := repo.FundFromUnmatchedPayment(ctx, paymentID, deal.ID, ""); err != nil {
+		t.Fatalf("FundFromUnmatchedPayment: %v", err)
+	}
+	got, err := repo.GetByDealID(ctx, deal.ID)
+	if err != nil {
+		t.Fatalf("GetByDealID: %v", err)
+	}
+	if got.Status != models.EscrowStatusFunded {
+		t.Errorf("escrow status = %s, want funded", got.Status)
+	}
+	var status string
+	if err := pool.QueryRow(ctx, `SELECT status FROM unmatched_payments WHERE id = $1`, paymentID).Scan(&status); err != nil {
+		t.Fatalf("read payment: %v", err)
+	}
+	if status != models.UnmatchedPaymentStatusMatched {
+		t.Errorf("payment status = %s, want matched", status)
+	}
+
+	// A pending payment whose escrow never shows up is escalated after its deadline
+	staleLT := uint64(rand.Int64N(1 << 50))
+	if err := repo.RecordPendingPayment(ctx, &models.UnmatchedPayment{
+		TxLT: int64(staleLT), TxHash: "01", FromAddress: "EQpayer", Amount: "1000000000",
+		Currency: models.EscrowCurrencyTON, Memo: ton.DealMemo(uuid.New()),
+	}, now.Add(-time.Second)); err != nil {
+		t.Fatalf("RecordPendingPayment: %v", err)
+	}
+	if _, err := repo.EscalatePendingPayments(ctx, now); err != nil {
+		t.Fatalf("EscalatePendingPayments: %v", err)
+	}
+	if err := pool.QueryRow(ctx, `SELECT status FROM unmatched_payments WHERE tx_lt = $1`, int64(staleLT)).Scan(&status); err != nil {
+		t.Fatalf("read payment: %v", err)
+	}
+	if status != models.UnmatchedPaymentStatusUnmatched {
+		t.Errorf("stale payment status = %s, want unmatched", status)
+	}
+}
+
+// TestSetRefundAddressRequiresFunded refuses a refund address override for an escrow that
+// doesn't hold the funds; set TEST_POSTGRES_DSN to enable.
+func TestSetRefundAddressRequiresFunded(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+
+	user := testUser(t, pool)
+	ch := testChannel(t, pool, user, "refundaddr")
+	deal := testDeal(t, pool, ch, user, models.DealStatusAwaitingPayment)
+	repo := NewEscrowRepo(pool)
+	escrow := &models.EscrowLedger{
+		DealID: deal.ID, DepositExpectedTON: "1", Currency: models.EscrowCurrencyTON,
+		DepositAddress: "EQtest", DepositMemo: fmt.Sprintf("deal-%d", rand.Int64N(1<<40)),
+		Status: models.EscrowStatusAwaiting,
+	}
+	if err := repo.Create(ctx, escrow); err != nil {
+		t.Fatalf("create escrow: %v", err)
+	}
+
+	if err := repo.SetRefundAddress(ctx, deal.ID, "EQrefund"); !errors.Is(err, ErrStatusChanged) {
+		t.Fatalf("SetRefundAddress = %v, want ErrStatusChanged", err)
+	}
+	got, err := repo.GetByDealID(ctx, deal.ID)
+	if err != nil {
+		t.Fatalf("GetByDealID: %v", err)
+	}
+	if got.RefundAddress != nil {
+		t.Errorf("refund address = %q, want none", *got.RefundAddress)
+	}
+}
+
+// TestMarkReleasedRequiresFunded credits the owner only together with releasing a funded
+// escrow: never for an unpaid one, and only once; set TEST_POSTGRES_DSN to enable.
+func TestMarkReleasedRequiresFunded(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+
+	user := testUser(t, pool)
+	ch := testChannel(t, pool, user, "release")
+	deal := testDeal(t, pool, ch, user, models.DealStatusAwaitingPayment)
+	repo := NewEscrowRepo(pool)
+	escrow := &models.EscrowLedger{
+		DealID: deal.ID, DepositExpectedTON: "1", Currency: models.EscrowCurrencyTON,
+		DepositAddress: "EQtest", DepositMemo: fmt.Sprintf("deal-%d", rand.Int64N(1<<40)),
+		Status: models.EscrowStatusAwaiting,
+	}
+	if err := repo.Create(ctx, escrow); err != nil {
+		t.Fatalf("create escrow: %v", err)
+	}
+	credited := func(want string) bool {
+		t.Helper()
+		var ok bool
+		if err := pool.QueryRow(ctx, `
+			SELECT COALESCE(SUM(amount_ton), 0) = $2::numeric FROM balance_ledger WHERE user_id = $1
+		`, user.ID, want).Scan(&ok); err != nil {
+			t.Fatalf("read balance: %v", err)
+		}
+		return ok
+	}
+
+	if err := repo.MarkReleased(ctx, deal.ID, user.ID, "0.9", "balance"); !errors.Is(err, ErrStatusChanged) {
+		t.Fatalf("MarkReleased on an unpaid escrow = %v, want ErrStatusChanged", err)
+	}
+	if !credited("0") {
+		t.Fatal("owner credited for an unpaid escrow")
+	}
+
+	lt := uint64(rand.Int64N(1 << 50))
+	wallet := fmt.Sprintf("test-wallet-%d", lt)
+	if err := repo.MarkFundedAtCursor(ctx, deal.ID, fmt.Sprint(lt), "EQpayer", "", wallet, lt, []byte{1}); err != nil {
+		t.Fatalf("MarkFundedAtCursor: %v", err)
+	}
+	if err := repo.MarkReleased(ctx, deal.ID, user.ID, "0.9", "balance"); err != nil {
+		t.Fatalf("MarkReleased: %v", err)
+	}
+	if err := repo.MarkReleased(ctx, deal.ID, user.ID, "0.9", "balance"); !errors.Is(err, ErrStatusChanged) {
+		t.Fatalf("second MarkReleased = %v, want ErrStatusChanged", err)
+	}
+	if !credited("0.9") {
+		t.Error("owner balance is not the release amount credited once")
+	}
+}
+
+// TestListFundingLTsSince lists escrows funded by the indexer or from a matched payment by
+// their funding LT, and leaves out ones funded by hand with a non-LT tx reference. Set
+// TEST_POSTGRES_DSN to enable.
+func TestListFundingLTsSince(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+
+	user := testUser(t, pool)
+	ch := testChannel(t, pool, user, "fundlt")
+	repo := NewEscrowRepo(pool)
+	newEscrow := func() *models.Deal {
+		deal := testDeal(t, pool, ch, user, models.DealStatusAwaitingPayment)
+		if err := repo.Create(ctx, &models.EscrowLedger{
+			DealID: deal.ID, DepositExpectedTON: "1", Currency: models.EscrowCurrencyTON,
+			DepositAddress: "EQtest", DepositMemo: ton.DealMemo(deal.ID), Status: models.EscrowStatusAwaiting,
+		}); err != nil {
+			t.Fatalf("create escrow: %v", err)
+		}
+		return deal
+	}
+
+	since := uint64(rand.Int64N(1<<50)) + 1<<50
+	indexed, matched, manual := newEscrow(), newEscrow(), newEscrow()
+	wallet := fmt.Sprintf("test-wallet-%d", since)
+	if err := repo.MarkFundedAtCursor(ctx, indexed.ID, fmt.Sprint(since), "EQpayer", "", wallet, since, []byte{1}); err != nil {
+		t.Fatalf("MarkFundedAtCursor: %v", err)
+	}
+	if err := repo.RecordUnmatchedPayment(ctx, &models.UnmatchedPayment{
+		TxLT: int64(since + 1), TxHash: "00", FromAddress: "EQpayer", Amount: "1000000000",
+		Currency: models.EscrowCurrencyTON, Memo: ton.DealMemo(matched.ID),
+	}); err != nil {
+		t.Fatalf("RecordUnmatchedPayment: %v", err)
+	}
+	var paymentID uuid.UUID
+	if err := pool.QueryRow(ctx, `SELECT id FROM unmatched_payments WHERE tx_lt = $1`, int64(since+1)).Scan(&paymentID); err != nil {
+		t.Fatalf("find unmatched payment: %v", err)
+	}
+	if err := repo.FundFromUnmatchedPayment(ctx, paymentID, matched.ID, ""); err != nil {
+		t.Fatalf("FundFromUnmatchedPayment: %v", err)
+	}
+	if err := repo.MarkFunded(ctx, manual.ID, "0xdeadbeef", "EQpayer"); err != nil {
+		t.Fatalf("MarkFunded: %v", err)
+	}
+
+	fundings, err := repo.ListFundingLTsSince(ctx, since)
+	if err != nil {
+		t.Fatalf("ListFundingLTsSince: %v", err)
+	}
+	if got := fundings[indexed.ID]; got != since {
+		t.Errorf("indexer funding LT = %d, want %d", got, since)
+	}
+	if got := fundings[matched.ID]; got != since+1 {
+		t.Errorf("matched payment funding LT = %d, want %d", got, since+1)
+	}
+	if got, ok := fundings[manual.ID]; ok {
+		t.Errorf("manual funding listed with LT %d", got)
+	}
+}
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// upsertIndexerCursorSQL never moves the cursor backwards. Args: wallet, lt, hash.
+const upsertIndexerCursorSQL = `
+	INSERT INTO indexer_cursor (wallet_address, lt, hash) VALUES ($1, $2, $3)
+	ON CONFLICT (wallet_address) DO UPDATE SET lt = EXCLUDED.lt, hash = EXCLUDED.hash, updated_at = now()
+	WHERE indexer_cursor.lt <= EXCLUDED.lt
+`
+
+type IndexerCursorRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewIndexerCursorRepo(pool *pgxpool.Pool) *IndexerCursorRepo {
+	return &IndexerCursorRepo{pool: pool}
+}
+
+// Get fails with ErrNotFound if the wallet has no cursor yet.
+func (r *IndexerCursorRepo) Get(ctx context.Context, wallet string) (uint64, []byte, error) {
+	var lt int64
+	var hash []byte
+	err := r.pool.QueryRow(ctx, `SELECT lt, hash FROM indexer_cursor WHERE wallet_address = $1`, wallet).Scan(&lt, &hash)
+	if err != nil {
+		return 0, nil, notFound(err)
+	}
+	return uint64(lt), hash, nil
+}
+
+func (r *IndexerCursorRepo) Save(ctx context.Context, wallet string, lt uint64, hash []byte) error {
+	_, err := r.pool.Exec(ctx, upsertIndexerCursorSQL, wallet, int64(lt), hash)
+	return err
+}
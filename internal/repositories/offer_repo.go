@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OfferRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewOfferRepo(pool *pgxpool.Pool) *OfferRepo {
+	return &OfferRepo{pool: pool}
+}
+
+const offerColumns = `id, channel_id, created_by, advertiser_user_id, ad_format, price_ton, brief, scheduled_at,
+	valid_until, status, deal_id, accepted_by, created_at, accepted_at`
+
+func scanOffer(row pgx.Row) (*models.DealOffer, error) {
+	var o models.DealOffer
+	err := row.Scan(&o.ID, &o.ChannelID, &o.CreatedBy, &o.AdvertiserUserID, &o.AdFormat, &o.PriceTON, &o.Brief, &o.ScheduledAt,
+		&o.ValidUntil, &o.Status, &o.DealID, &o.AcceptedBy, &o.CreatedAt, &o.AcceptedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &o, nil
+}
+
+func (r *OfferRepo) Create(ctx context.Context, o *models.DealOffer) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO deal_offers (channel_id, created_by, advertiser_user_id, ad_format, price_ton, brief, scheduled_at, valid_until, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`, o.ChannelID, o.CreatedBy, o.AdvertiserUserID, o.AdFormat, o.PriceTON, o.Brief, o.ScheduledAt, o.ValidUntil, o.Status,
+	).Scan(&o.ID, &o.CreatedAt)
+}
+
+func (r *OfferRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.DealOffer, error) {
+	return scanOffer(r.pool.QueryRow(ctx, `SELECT `+offerColumns+` FROM deal_offers WHERE id = $1`, id))
+}
+
+// ListByChannel returns all offers of a channel (owner view), newest first.
+func (r *OfferRepo) ListByChannel(ctx context.Context, channelID uuid.UUID, limit, offset int) ([]models.DealOffer, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+offerColumns+` FROM deal_offers
+		WHERE channel_id = $1
+		ORDER BY created_at DESC LIMIT $2 OFFSET $3
+	`, channelID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return collectOffers(rows)
+}
+
+// ListAvailable returns open, unexpired offers addressed to the user or public.
+func (r *OfferRepo) ListAvailable(ctx context.Context, userID uuid.UUID, limit, offset int) ([]models.DealOffer, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+offerColumns+` FROM deal_offers
+		WHERE status = 'open' AND valid_until > now()
+		  AND (advertiser_user_id IS NULL OR advertiser_user_id = $1)
+		  AND created_by <> $1
+		ORDER BY valid_until ASC LIMIT $2 OFFSET $3
+	`, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return collectOffers(rows)
+}
+
+func collectOffers(rows pgx.Rows) ([]models.DealOffer, error) {
+	defer rows.Close()
+	var offers []models.DealOffer
+	for rows.Next() {
+		o, err := scanOffer(rows)
+		if err != nil {
+			return nil, err
+		}
+		offers = append(offers, *o)
+	}
+	return offers, nil
+}
+
+// Claim atomically takes an open, unexpired offer for an advertiser so it can only be
+// accepted once. Fails with ErrNotFound if it is no longer available.
+func (r *OfferRepo) Claim(ctx context.Context, id, userID uuid.UUID) error {
+	var claimedID uuid.UUID
+	err := r.pool.QueryRow(ctx, `
+		UPDATE deal_offers SET status = 'accepted', accepted_by = $1, accepted_at = now()
+		WHERE id = $2 AND status = 'open' AND valid_until > now()
+		  AND (advertiser_user_id IS NULL OR advertiser_user_id = $1)
+		RETURNING id
+	`, userID, id).Scan(&claimedID)
+	return notFound(err)
+}
+
+// Release reopens a claimed offer whose deal could not be created.
+func (r *OfferRepo) Release(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE deal_offers SET status = 'open', accepted_by = NULL, accepted_at = NULL
+		WHERE id = $1 AND status = 'accepted' AND deal_id IS NULL
+	`, id)
+	return err
+}
+
+func (r *OfferRepo) SetDeal(ctx context.Context, id, dealID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `UPDATE deal_offers SET deal_id = $1 WHERE id = $2`, dealID, id)
+	return err
+}
+
+func (r *OfferRepo) Cancel(ctx context.Context, id uuid.UUID) error {
+	var cancelledID uuid.UUID
+	err := r.pool.QueryRow(ctx, `
+		UPDATE deal_offers SET status = 'cancelled' WHERE id = $1 AND status = 'open' RETURNING id
+	`, id).Scan(&cancelledID)
+	return notFound(err)
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor is returned by DecodeCursor for a cursor this API didn't issue.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PageCursor is a keyset position: the (created_at, id) of the last row already returned.
+// Lists paged by cursor are ordered by created_at DESC, id DESC, so rows inserted while a
+// client pages through don't shift the following pages the way OFFSET does.
+type PageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Page is one page of a list plus the cursor of the next page ("" on the last page).
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// EncodeCursor returns the opaque string form of c handed out to clients.
+func EncodeCursor(c PageCursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor.
+func DecodeCursor(s string) (*PageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &PageCursor{CreatedAt: createdAt, ID: uid}, nil
+}
+
+// keysetClause is the condition selecting rows after the cursor bound to $argIdx, $argIdx+1
+// in (createdCol DESC, idCol DESC) order.
+func keysetClause(createdCol, idCol string, argIdx int) string {
+	return fmt.Sprintf("(%s, %s) < ($%d, $%d)", createdCol, idCol, argIdx, argIdx+1)
+}
+
+// newPage builds a page from rows queried with LIMIT limit+1: the extra row only tells
+// that there is a next page, whose cursor points at the last row kept.
+func newPage[T any](rows []T, limit int, key func(T) PageCursor) *Page[T] {
+	p := &Page[T]{Items: rows}
+	if limit > 0 && len(rows) > limit {
+		p.Items = rows[:limit]
+		p.NextCursor = EncodeCursor(key(p.Items[limit-1]))
+	}
+	return p
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := PageCursor{
+		CreatedAt: time.Date(2025, 3, 14, 9, 26, 53, 589793000, time.FixedZone("MSK", 3*3600)),
+		ID:        uuid.MustParse("0b9a4c3e-7f51-4a6b-9d2e-1c8f0e5a7b42"),
+	}
+
+	got, err := DecodeCursor(EncodeCursor(c))
+	if err != nil {
+		t.Fatalf("DecodeCursor: %v", err)
+	}
+	if !got.CreatedAt.Equal(c.CreatedAt) || got.ID != c.ID {
+		t.Errorf("round trip = %+v, want %+v", got, c)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"not base64!",
+		"bm8tc2VwYXJhdG9y",               // "no-separator"
+		"eWVzdGVyZGF5fDBiOWE0YzNl",       // "yesterday|0b9a4c3e"
+		"MjAyNS0wMy0xNFQwOToyNjo1M1p8eA", // "2025-03-14T09:26:53Z|x"
+	}
+
+	for _, in := range tests {
+		if _, err := DecodeCursor(in); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("DecodeCursor(%q) err = %v, want ErrInvalidCursor", in, err)
+		}
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	row := func(i int) PageCursor {
+		return PageCursor{CreatedAt: base.Add(-time.Duration(i) * time.Hour), ID: uuid.New()}
+	}
+	key := func(c PageCursor) PageCursor { return c }
+
+	tests := []struct {
+		name      string
+		rows      int
+		limit     int
+		wantItems int
+		wantNext  bool
+	}{
+		{"empty", 0, 20, 0, false},
+		{"short page", 5, 20, 5, false},
+		{"exactly limit", 20, 20, 20, false},
+		{"one extra row", 21, 20, 20, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := make([]PageCursor, tt.rows)
+			for i := range rows {
+				rows[i] = row(i)
+			}
+
+			p := newPage(rows, tt.limit, key)
+			if len(p.Items) != tt.wantItems {
+				t.Errorf("items = %d, want %d", len(p.Items), tt.wantItems)
+			}
+			if (p.NextCursor != "") != tt.wantNext {
+				t.Fatalf("next cursor = %q, want present = %v", p.NextCursor, tt.wantNext)
+			}
+			if tt.wantNext {
+				next, err := DecodeCursor(p.NextCursor)
+				if err != nil {
+					t.Fatalf("DecodeCursor: %v", err)
+				}
+				if last := p.Items[len(p.Items)-1]; next.ID != last.ID || !next.CreatedAt.Equal(last.CreatedAt) {
+					t.Errorf("next cursor = %+v, want last item %+v", next, last)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RatingRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRatingRepo(pool *pgxpool.Pool) *RatingRepo {
+	return &RatingRepo{pool: pool}
+}
+
+// Create stores a rating. A second rating of the same deal by the same rater fails
+// with a unique violation (see IsUniqueViolation).
+func (r *RatingRepo) Create(ctx context.Context, rt *models.DealRating) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO deal_ratings (deal_id, rater_user_id, ratee_type, stars, comment)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, rt.DealID, rt.RaterUserID, rt.RateeType, rt.Stars, rt.Comment).Scan(&rt.ID, &rt.CreatedAt)
+}
+
+func (r *RatingRepo) ListByDeal(ctx context.Context, dealID uuid.UUID) ([]models.DealRating, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, deal_id, rater_user_id, ratee_type, stars, comment, created_at
+		FROM deal_ratings WHERE deal_id = $1 ORDER BY created_at
+	`, dealID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.DealRating
+	for rows.Next() {
+		var rt models.DealRating
+		if err := rows.Scan(&rt.ID, &rt.DealID, &rt.RaterUserID, &rt.RateeType, &rt.Stars, &rt.Comment, &rt.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, rt)
+	}
+	return list, rows.Err()
+}
+
+// ChannelSummary aggregates the advertisers' ratings of the channel's deals.
+func (r *RatingRepo) ChannelSummary(ctx context.Context, channelID uuid.UUID) (models.RatingSummary, error) {
+	var s models.RatingSummary
+	err := r.pool.QueryRow(ctx, `
+		SELECT ROUND(AVG(dr.stars), 2)::float8, COUNT(*)
+		FROM deal_ratings dr JOIN deals d ON d.id = dr.deal_id
+		WHERE d.channel_id = $1 AND dr.ratee_type = $2
+	`, channelID, models.RateeChannel).Scan(&s.Average, &s.Count)
+	return s, err
+}
+
+// AdvertiserSummary aggregates the channel owners' ratings of the user as an advertiser.
+func (r *RatingRepo) AdvertiserSummary(ctx context.Context, userID uuid.UUID) (models.RatingSummary, error) {
+	var s models.RatingSummary
+	err := r.pool.QueryRow(ctx, `
+		SELECT ROUND(AVG(dr.stars), 2)::float8, COUNT(*)
+		FROM deal_ratings dr JOIN deals d ON d.id = dr.deal_id
+		WHERE d.advertiser_user_id = $1 AND dr.ratee_type = $2
+	`, userID, models.RateeAdvertiser).Scan(&s.Average, &s.Count)
+	return s, err
+}
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ads-marketplace/backend/internal/models"
+)
+
+// TestRatingOncePerRater rates a completed deal, expects a second rating by the same
+// user to be refused and the channel summary to count only the first; set
+// TEST_POSTGRES_DSN to enable.
+func TestRatingOncePerRater(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+
+	user := testUser(t, pool)
+	ch := testChannel(t, pool, user, "rating")
+	deal := testDeal(t, pool, ch, user, models.DealStatusCompleted)
+
+	repo := NewRatingRepo(pool)
+	first := &models.DealRating{DealID: deal.ID, RaterUserID: user.ID, RateeType: models.RateeChannel, Stars: 4}
+	if err := repo.Create(ctx, first); err != nil {
+		t.Fatalf("first rating: %v", err)
+	}
+	second := &models.DealRating{DealID: deal.ID, RaterUserID: user.ID, RateeType: models.RateeChannel, Stars: 1}
+	if err := repo.Create(ctx, second); !IsUniqueViolation(err) {
+		t.Errorf("second rating = %v, want a unique violation", err)
+	}
+
+	summary, err := repo.ChannelSummary(ctx, ch.ID)
+	if err != nil {
+		t.Fatalf("ChannelSummary: %v", err)
+	}
+	if summary.Count != 1 || summary.Average == nil || *summary.Average != 4 {
+		t.Errorf("summary = %+v, want one rating of 4", summary)
+	}
+}
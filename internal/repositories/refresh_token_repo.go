@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RefreshTokenRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRefreshTokenRepo(pool *pgxpool.Pool) *RefreshTokenRepo {
+	return &RefreshTokenRepo{pool: pool}
+}
+
+// Create stores the hash of a refresh token starting a new family (a new login).
+func (r *RefreshTokenRepo) Create(ctx context.Context, userID uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, gen_random_uuid(), $2, $3)
+	`, userID, tokenHash, expiresAt)
+	return err
+}
+
+// Rotate exchanges a live refresh token for newHash in the same family and returns its
+// user. ErrNotFound if the token is unknown or expired; ErrTokenReused (after revoking
+// the whole family) if it was already rotated or revoked.
+func (r *RefreshTokenRepo) Rotate(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (uuid.UUID, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var (
+		id, userID, familyID uuid.UUID
+		oldExpiresAt         time.Time
+		revokedAt            *time.Time
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT id, user_id, family_id, expires_at, revoked_at
+		FROM refresh_tokens WHERE token_hash = $1
+		FOR UPDATE
+	`, oldHash).Scan(&id, &userID, &familyID, &oldExpiresAt, &revokedAt)
+	if err != nil {
+		return uuid.Nil, notFound(err)
+	}
+
+	if revokedAt != nil {
+		if _, err := tx.Exec(ctx, `
+			UPDATE refresh_tokens SET revoked_at = now()
+			WHERE family_id = $1 AND revoked_at IS NULL
+		`, familyID); err != nil {
+			return uuid.Nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return uuid.Nil, err
+		}
+		return uuid.Nil, ErrTokenReused
+	}
+	if !oldExpiresAt.After(time.Now()) {
+		return uuid.Nil, ErrNotFound
+	}
+
+	var newID uuid.UUID
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, userID, familyID, newHash, expiresAt).Scan(&newID); err != nil {
+		return uuid.Nil, err
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE refresh_tokens SET revoked_at = now(), replaced_by = $2 WHERE id = $1
+	`, id, newID); err != nil {
+		return uuid.Nil, err
+	}
+	return userID, tx.Commit(ctx)
+}
+
+// RevokeFamily revokes the token's whole family (logout of that session). Only tokens of
+// userID are touched; unknown tokens are ignored.
+func (r *RefreshTokenRepo) RevokeFamily(ctx context.Context, userID uuid.UUID, tokenHash string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE refresh_tokens SET revoked_at = now()
+		WHERE revoked_at IS NULL AND family_id = (
+			SELECT family_id FROM refresh_tokens WHERE token_hash = $1 AND user_id = $2
+		)
+	`, tokenHash, userID)
+	return err
+}
+
+// RevokeAllForUser revokes every refresh token of the user (logout everywhere).
+func (r *RefreshTokenRepo) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE refresh_tokens SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID)
+	return err
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestRefreshTokenRotation rotates a refresh token, then replays the old one and expects
+// the whole family revoked; set TEST_POSTGRES_DSN to enable.
+func TestRefreshTokenRotation(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+
+	user := testUser(t, pool)
+	repo := NewRefreshTokenRepo(pool)
+	prefix := user.ID.String() + ":"
+	exp := time.Now().Add(time.Hour)
+
+	if err := repo.Create(ctx, user.ID, prefix+"a", exp); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	got, err := repo.Rotate(ctx, prefix+"a", prefix+"b", exp)
+	if err != nil || got != user.ID {
+		t.Fatalf("rotate a->b = %s, %v; want %s", got, err, user.ID)
+	}
+
+	// Replaying a revokes the family, b included
+	if _, err := repo.Rotate(ctx, prefix+"a", prefix+"c", exp); !errors.Is(err, ErrTokenReused) {
+		t.Fatalf("replay a: err = %v, want ErrTokenReused", err)
+	}
+	if _, err := repo.Rotate(ctx, prefix+"b", prefix+"d", exp); !errors.Is(err, ErrTokenReused) {
+		t.Fatalf("rotate b after reuse: err = %v, want ErrTokenReused", err)
+	}
+
+	if _, err := repo.Rotate(ctx, prefix+"unknown", prefix+"e", exp); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("unknown token: err = %v, want ErrNotFound", err)
+	}
+
+	if err := repo.Create(ctx, user.ID, prefix+"old", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("create expired: %v", err)
+	}
+	if _, err := repo.Rotate(ctx, prefix+"old", prefix+"f", exp); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expired token: err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ads-marketplace/backend/internal/db"
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Tests against a real database run only when TEST_POSTGRES_DSN is set. Every test creates
+// its own users and channels with random Telegram IDs and usernames, so runs don't collide.
+
+// testPool connects to TEST_POSTGRES_DSN, skipping the test if it is not set. The pool is
+// closed when the test ends.
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	return testPoolWithTimeout(t, 0)
+}
+
+// testPoolWithTimeout is testPool with a statement timeout.
+func testPoolWithTimeout(t *testing.T, statementTimeout time.Duration) *pgxpool.Pool {
+	t.Helper()
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN not set")
+	}
+	pool, err := db.NewPostgresPool(context.Background(), dsn, statementTimeout, zap.NewNop())
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// testTelegramID returns a random negative Telegram user ID, which no real account has.
+func testTelegramID() int64 {
+	return -rand.Int64N(1<<40) - 1
+}
+
+// testUser creates a user with a random Telegram ID.
+func testUser(t *testing.T, pool *pgxpool.Pool) *models.User {
+	t.Helper()
+	user, err := NewUserRepo(pool).UpsertByTelegramID(context.Background(), testTelegramID(), nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+// testChannel creates a pending channel added by user, named prefix_<random>.
+func testChannel(t *testing.T, pool *pgxpool.Pool, user *models.User, prefix string) *models.Channel {
+	t.Helper()
+	ch := &models.Channel{Username: fmt.Sprintf("%s_%d", prefix, rand.Int64N(1<<40)), AddedByUserID: &user.ID, BotStatus: "pending"}
+	if err := NewChannelRepo(pool).Create(context.Background(), ch); err != nil {
+		t.Fatalf("create channel: %v", err)
+	}
+	return ch
+}
+
+// testDeal creates a 1 TON post deal of advertiser on ch in status.
+func testDeal(t *testing.T, pool *pgxpool.Pool, ch *models.Channel, advertiser *models.User, status string) *models.Deal {
+	t.Helper()
+	deal := &models.Deal{
+		ChannelID: ch.ID, AdvertiserUserID: advertiser.ID, Status: status,
+		AdFormat: models.AdFormatPost, PriceTON: "1", PlatformFeeBPS: 500, HoldPeriodSeconds: 3600,
+	}
+	if err := NewDealRepo(pool).Create(context.Background(), deal); err != nil {
+		t.Fatalf("create deal: %v", err)
+	}
+	return deal
+}
@@ -17,19 +17,25 @@ func NewUserRepo(pool *pgxpool.Pool) *UserRepo {
 	return &UserRepo{pool: pool}
 }
 
-func (r *UserRepo) UpsertByTelegramID(ctx context.Context, telegramID int64, username, firstName, lastName *string) (*models.User, error) {
+// UpsertByTelegramID creates or refreshes a user from Telegram data. Nil fields keep the
+// stored value, so a login without e.g. photo_url doesn't wipe it; a language the user
+// chose in their profile is never replaced.
+func (r *UserRepo) UpsertByTelegramID(ctx context.Context, telegramID int64, username, firstName, lastName, languageCode, photoURL *string) (*models.User, error) {
 	var u models.User
 	err := r.pool.QueryRow(ctx, `
-		INSERT INTO users (telegram_user_id, username, first_name, last_name)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO users (telegram_user_id, username, first_name, last_name, language_code, photo_url)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		ON CONFLICT (telegram_user_id) DO UPDATE SET
 			username = COALESCE(EXCLUDED.username, users.username),
 			first_name = COALESCE(EXCLUDED.first_name, users.first_name),
 			last_name = COALESCE(EXCLUDED.last_name, users.last_name),
+			language_code = CASE WHEN users.language_chosen THEN users.language_code
+				ELSE COALESCE(EXCLUDED.language_code, users.language_code) END,
+			photo_url = COALESCE(EXCLUDED.photo_url, users.photo_url),
 			last_active_at = now()
-		RETURNING id, telegram_user_id, username, first_name, last_name, created_at, last_active_at
-	`, telegramID, username, firstName, lastName).Scan(
-		&u.ID, &u.TelegramUserID, &u.Username, &u.FirstName, &u.LastName, &u.CreatedAt, &u.LastActiveAt,
+		RETURNING id, telegram_user_id, username, first_name, last_name, language_code, photo_url, notifications_enabled, created_at, last_active_at
+	`, telegramID, username, firstName, lastName, languageCode, photoURL).Scan(
+		&u.ID, &u.TelegramUserID, &u.Username, &u.FirstName, &u.LastName, &u.LanguageCode, &u.PhotoURL, &u.NotificationsEnabled, &u.CreatedAt, &u.LastActiveAt,
 	)
 	return &u, err
 }
@@ -37,11 +43,11 @@ func (r *UserRepo) UpsertByTelegramID(ctx context.Context, telegramID int64, use
 func (r *UserRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	var u models.User
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, telegram_user_id, username, first_name, last_name, created_at, last_active_at
-		FROM users WHERE id = $1
-	`, id).Scan(&u.ID, &u.TelegramUserID, &u.Username, &u.FirstName, &u.LastName, &u.CreatedAt, &u.LastActiveAt)
+		SELECT id, telegram_user_id, username, first_name, last_name, language_code, photo_url, notifications_enabled, created_at, last_active_at
+		FROM users WHERE id = $1 AND deleted_at IS NULL
+	`, id).Scan(&u.ID, &u.TelegramUserID, &u.Username, &u.FirstName, &u.LastName, &u.LanguageCode, &u.PhotoURL, &u.NotificationsEnabled, &u.CreatedAt, &u.LastActiveAt)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &u, nil
 }
@@ -49,11 +55,31 @@ func (r *UserRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User, err
 func (r *UserRepo) GetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
 	var u models.User
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, telegram_user_id, username, first_name, last_name, created_at, last_active_at
+		SELECT id, telegram_user_id, username, first_name, last_name, language_code, photo_url, notifications_enabled, created_at, last_active_at
 		FROM users WHERE telegram_user_id = $1
-	`, telegramID).Scan(&u.ID, &u.TelegramUserID, &u.Username, &u.FirstName, &u.LastName, &u.CreatedAt, &u.LastActiveAt)
+	`, telegramID).Scan(&u.ID, &u.TelegramUserID, &u.Username, &u.FirstName, &u.LastName, &u.LanguageCode, &u.PhotoURL, &u.NotificationsEnabled, &u.CreatedAt, &u.LastActiveAt)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
+	}
+	return &u, nil
+}
+
+// UpdateProfile sets the user's preferences; nil fields are left as they are. A language
+// set here sticks over the Telegram one sent at login.
+func (r *UserRepo) UpdateProfile(ctx context.Context, id uuid.UUID, languageCode *string, notificationsEnabled *bool) (*models.User, error) {
+	var u models.User
+	err := r.pool.QueryRow(ctx, `
+		UPDATE users SET
+			language_code = COALESCE($2, language_code),
+			language_chosen = language_chosen OR $2::text IS NOT NULL,
+			notifications_enabled = COALESCE($3, notifications_enabled)
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING id, telegram_user_id, username, first_name, last_name, language_code, photo_url, notifications_enabled, created_at, last_active_at
+	`, id, languageCode, notificationsEnabled).Scan(
+		&u.ID, &u.TelegramUserID, &u.Username, &u.FirstName, &u.LastName, &u.LanguageCode, &u.PhotoURL, &u.NotificationsEnabled, &u.CreatedAt, &u.LastActiveAt,
+	)
+	if err != nil {
+		return nil, notFound(err)
 	}
 	return &u, nil
 }
@@ -80,3 +106,14 @@ func (r *UserRepo) GetActiveUserIDs(ctx context.Context, since time.Time) ([]uui
 	}
 	return ids, nil
 }
+
+// Anonymize clears a user's personal data and marks the account deleted. The row is kept
+// because deals, escrow and audit entries reference it.
+func (r *UserRepo) Anonymize(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE users SET telegram_user_id = NULL, username = NULL, first_name = NULL, last_name = NULL,
+		       photo_url = NULL, wallet_address = NULL, deleted_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id)
+	return err
+}
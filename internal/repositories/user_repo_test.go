@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+)
+
+// TestUpsertKeepsTelegramProfile logs a user in with language_code and photo_url, then
+// again without them, and expects both kept; set TEST_POSTGRES_DSN to enable.
+func TestUpsertKeepsTelegramProfile(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+
+	repo := NewUserRepo(pool)
+	telegramID := testTelegramID()
+	lang, photo := "ru", "https://t.me/i/userpic/320/a.jpg"
+
+	if _, err := repo.UpsertByTelegramID(ctx, telegramID, nil, nil, nil, &lang, &photo); err != nil {
+		t.Fatalf("first login: %v", err)
+	}
+	user, err := repo.UpsertByTelegramID(ctx, telegramID, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("second login: %v", err)
+	}
+	if user.LanguageCode == nil || *user.LanguageCode != lang {
+		t.Errorf("language_code = %v, want %q", user.LanguageCode, lang)
+	}
+	if user.PhotoURL == nil || *user.PhotoURL != photo {
+		t.Errorf("photo_url = %v, want %q", user.PhotoURL, photo)
+	}
+}
+
+// TestChosenLanguageSurvivesLogin sets a language and mutes notifications through the
+// profile, then logs in with another Telegram language_code and expects the choice kept;
+// set TEST_POSTGRES_DSN to enable.
+func TestChosenLanguageSurvivesLogin(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+
+	repo := NewUserRepo(pool)
+	telegramID := testTelegramID()
+	telegramLang, chosen, muted := "en", "uk", false
+
+	user, err := repo.UpsertByTelegramID(ctx, telegramID, nil, nil, nil, &telegramLang, nil)
+	if err != nil {
+		t.Fatalf("first login: %v", err)
+	}
+	if !user.NotificationsEnabled {
+		t.Error("notifications must be enabled by default")
+	}
+	if _, err := repo.UpdateProfile(ctx, user.ID, &chosen, &muted); err != nil {
+		t.Fatalf("update profile: %v", err)
+	}
+	user, err = repo.UpsertByTelegramID(ctx, telegramID, nil, nil, nil, &telegramLang, nil)
+	if err != nil {
+		t.Fatalf("second login: %v", err)
+	}
+	if user.LanguageCode == nil || *user.LanguageCode != chosen {
+		t.Errorf("language_code = %v, want %q", user.LanguageCode, chosen)
+	}
+	if user.NotificationsEnabled {
+		t.Error("notifications_enabled reset by login")
+	}
+}
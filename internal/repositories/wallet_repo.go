@@ -48,20 +48,23 @@ func (r *WalletRepo) ConsumeProofPayload(ctx context.Context, payload string) (*
 		RETURNING id, payload, user_id, created_at, expires_at, used
 	`, payload).Scan(&p.ID, &p.Payload, &p.UserID, &p.CreatedAt, &p.ExpiresAt, &p.Used)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &p, nil
 }
 
 // --- User Wallets ---
 
+// ConnectWallet adds (or reconnects) a wallet next to the user's other active ones. It becomes
+// primary only if the user has no primary wallet yet; w.IsPrimary reports the outcome.
 func (r *WalletRepo) ConnectWallet(ctx context.Context, w *models.UserWallet) error {
 	return r.pool.QueryRow(ctx, `
 		INSERT INTO user_wallets (
 			user_id, address, address_friendly, network, public_key,
 			proof_payload, proof_signature, proof_timestamp, proof_domain,
-			verified, is_active
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, true)
+			verified, is_active, label, is_primary
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, true, $11,
+			NOT EXISTS (SELECT 1 FROM user_wallets WHERE user_id = $1 AND is_primary))
 		ON CONFLICT (user_id, address) DO UPDATE SET
 			public_key = EXCLUDED.public_key,
 			proof_payload = EXCLUDED.proof_payload,
@@ -70,46 +73,138 @@ func (r *WalletRepo) ConnectWallet(ctx context.Context, w *models.UserWallet) er
 			proof_domain = EXCLUDED.proof_domain,
 			verified = EXCLUDED.verified,
 			is_active = true,
+			label = COALESCE(EXCLUDED.label, user_wallets.label),
+			is_primary = user_wallets.is_primary OR EXCLUDED.is_primary,
 			disconnected_at = NULL,
 			connected_at = now()
-		RETURNING id, connected_at
+		RETURNING id, connected_at, is_primary, label
 	`, w.UserID, w.Address, w.AddressFriendly, w.Network, w.PublicKey,
 		w.ProofPayload, w.ProofSignature, w.ProofTimestamp, w.ProofDomain,
-		w.Verified,
-	).Scan(&w.ID, &w.ConnectedAt)
+		w.Verified, w.Label,
+	).Scan(&w.ID, &w.ConnectedAt, &w.IsPrimary, &w.Label)
 }
 
 func (r *WalletRepo) DeactivateAllWallets(ctx context.Context, userID uuid.UUID) error {
 	_, err := r.pool.Exec(ctx, `
-		UPDATE user_wallets SET is_active = false, disconnected_at = now()
+		UPDATE user_wallets SET is_active = false, is_primary = false, disconnected_at = now()
 		WHERE user_id = $1 AND is_active = true
 	`, userID)
 	return err
 }
 
+// DisconnectWallet deactivates one wallet. If it was the primary, the most recently
+// connected remaining wallet takes over. Fails with ErrNotFound if the user has no such
+// active wallet.
 func (r *WalletRepo) DisconnectWallet(ctx context.Context, userID uuid.UUID, walletID uuid.UUID) error {
-	_, err := r.pool.Exec(ctx, `
-		UPDATE user_wallets SET is_active = false, disconnected_at = now()
-		WHERE id = $1 AND user_id = $2
-	`, walletID, userID)
-	return err
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, `
+		UPDATE user_wallets SET is_active = false, is_primary = false, disconnected_at = now()
+		WHERE id = $1 AND user_id = $2 AND is_active = true
+		RETURNING id
+	`, walletID, userID).Scan(&id)
+	if err != nil {
+		return notFound(err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE user_wallets SET is_primary = true
+		WHERE id = (
+			SELECT id FROM user_wallets WHERE user_id = $1 AND is_active = true
+			ORDER BY connected_at DESC LIMIT 1
+		) AND NOT EXISTS (SELECT 1 FROM user_wallets WHERE user_id = $1 AND is_primary)
+	`, userID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
+// GetActiveWallet returns the user's primary wallet (falling back to the latest active one).
 func (r *WalletRepo) GetActiveWallet(ctx context.Context, userID uuid.UUID) (*models.UserWallet, error) {
 	var w models.UserWallet
 	err := r.pool.QueryRow(ctx, `
 		SELECT id, user_id, address, address_friendly, network, public_key,
 		       proof_payload, proof_signature, proof_timestamp, proof_domain,
-		       verified, connected_at, disconnected_at, is_active
+		       verified, connected_at, disconnected_at, is_active, is_primary, label
 		FROM user_wallets
 		WHERE user_id = $1 AND is_active = true
-		ORDER BY connected_at DESC LIMIT 1
+		ORDER BY is_primary DESC, connected_at DESC LIMIT 1
 	`, userID).Scan(
 		&w.ID, &w.UserID, &w.Address, &w.AddressFriendly, &w.Network, &w.PublicKey,
 		&w.ProofPayload, &w.ProofSignature, &w.ProofTimestamp, &w.ProofDomain,
-		&w.Verified, &w.ConnectedAt, &w.DisconnectedAt, &w.IsActive,
+		&w.Verified, &w.ConnectedAt, &w.DisconnectedAt, &w.IsActive, &w.IsPrimary, &w.Label,
+	)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &w, nil
+}
+
+// ListWallets returns the user's active wallets, primary first, then newest first.
+func (r *WalletRepo) ListWallets(ctx context.Context, userID uuid.UUID) ([]models.UserWallet, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, user_id, address, address_friendly, network, public_key,
+		       verified, connected_at, disconnected_at, is_active, is_primary, label
+		FROM user_wallets
+		WHERE user_id = $1 AND is_active = true
+		ORDER BY is_primary DESC, connected_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wallets []models.UserWallet
+	for rows.Next() {
+		var w models.UserWallet
+		if err := rows.Scan(
+			&w.ID, &w.UserID, &w.Address, &w.AddressFriendly, &w.Network, &w.PublicKey,
+			&w.Verified, &w.ConnectedAt, &w.DisconnectedAt, &w.IsActive, &w.IsPrimary, &w.Label,
+		); err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, w)
+	}
+	return wallets, rows.Err()
+}
+
+// SetPrimaryWallet makes walletID the user's primary wallet. Fails with ErrNotFound if it
+// isn't one of the user's active wallets.
+func (r *WalletRepo) SetPrimaryWallet(ctx context.Context, userID, walletID uuid.UUID) (*models.UserWallet, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Clear first: the partial unique index allows one primary per user
+	if _, err := tx.Exec(ctx, `
+		UPDATE user_wallets SET is_primary = false
+		WHERE user_id = $1 AND is_primary AND id <> $2
+	`, userID, walletID); err != nil {
+		return nil, err
+	}
+
+	var w models.UserWallet
+	err = tx.QueryRow(ctx, `
+		UPDATE user_wallets SET is_primary = true
+		WHERE id = $1 AND user_id = $2 AND is_active = true
+		RETURNING id, user_id, address, address_friendly, network, public_key,
+		          verified, connected_at, disconnected_at, is_active, is_primary, label
+	`, walletID, userID).Scan(
+		&w.ID, &w.UserID, &w.Address, &w.AddressFriendly, &w.Network, &w.PublicKey,
+		&w.Verified, &w.ConnectedAt, &w.DisconnectedAt, &w.IsActive, &w.IsPrimary, &w.Label,
 	)
 	if err != nil {
+		return nil, notFound(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
 	return &w, nil
@@ -119,14 +214,14 @@ func (r *WalletRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.UserWal
 	var w models.UserWallet
 	err := r.pool.QueryRow(ctx, `
 		SELECT id, user_id, address, address_friendly, network, public_key,
-		       verified, connected_at, disconnected_at, is_active
+		       verified, connected_at, disconnected_at, is_active, is_primary, label
 		FROM user_wallets WHERE id = $1
 	`, id).Scan(
 		&w.ID, &w.UserID, &w.Address, &w.AddressFriendly, &w.Network, &w.PublicKey,
-		&w.Verified, &w.ConnectedAt, &w.DisconnectedAt, &w.IsActive,
+		&w.Verified, &w.ConnectedAt, &w.DisconnectedAt, &w.IsActive, &w.IsPrimary, &w.Label,
 	)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &w, nil
 }
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ads-marketplace/backend/internal/models"
+)
+
+// TestMultipleWallets connects several verified wallets for one user against a real database;
+// set TEST_POSTGRES_DSN to enable.
+func TestMultipleWallets(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+
+	user := testUser(t, pool)
+	repo := NewWalletRepo(pool)
+
+	connect := func(raw, friendly, label string) *models.UserWallet {
+		t.Helper()
+		w := &models.UserWallet{
+			UserID: user.ID, Address: raw, AddressFriendly: friendly, Network: "testnet",
+			PublicKey: "00", ProofPayload: "p", ProofSignature: "s", ProofDomain: "test",
+			Verified: true, IsActive: true, Label: &label,
+		}
+		if err := repo.ConnectWallet(ctx, w); err != nil {
+			t.Fatalf("ConnectWallet(%s): %v", label, err)
+		}
+		return w
+	}
+
+	deposit := connect("0:01", "UQdeposit", "deposit")
+	withdraw := connect("0:02", "UQwithdraw", "withdraw")
+	if !deposit.IsPrimary || withdraw.IsPrimary {
+		t.Fatalf("primary = (%v, %v), want only the first wallet", deposit.IsPrimary, withdraw.IsPrimary)
+	}
+
+	wallets, err := repo.ListWallets(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListWallets: %v", err)
+	}
+	if len(wallets) != 2 || wallets[0].ID != deposit.ID {
+		t.Fatalf("ListWallets = %d wallets, want both with the primary first", len(wallets))
+	}
+	if w := models.FindWallet(wallets, "UQwithdraw"); w == nil || !w.Verified {
+		t.Error("second verified wallet not usable for withdrawals")
+	}
+
+	if _, err := repo.SetPrimaryWallet(ctx, user.ID, withdraw.ID); err != nil {
+		t.Fatalf("SetPrimaryWallet: %v", err)
+	}
+	if active, err := repo.GetActiveWallet(ctx, user.ID); err != nil || active.ID != withdraw.ID {
+		t.Fatalf("GetActiveWallet = %v, %v; want the new primary", active, err)
+	}
+
+	// Reconnecting a secondary wallet must not steal primary
+	connect("0:01", "UQdeposit", "deposit")
+	if active, _ := repo.GetActiveWallet(ctx, user.ID); active == nil || active.ID != withdraw.ID {
+		t.Error("reconnect changed the primary wallet")
+	}
+
+	if err := repo.DisconnectWallet(ctx, user.ID, withdraw.ID); err != nil {
+		t.Fatalf("DisconnectWallet: %v", err)
+	}
+	if active, err := repo.GetActiveWallet(ctx, user.ID); err != nil || active.ID != deposit.ID || !active.IsPrimary {
+		t.Errorf("after disconnecting the primary, GetActiveWallet = %v, %v; want the remaining wallet promoted", active, err)
+	}
+}
@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WebhookRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebhookRepo(pool *pgxpool.Pool) *WebhookRepo {
+	return &WebhookRepo{pool: pool}
+}
+
+const webhookColumns = `id, user_id, url, secret, events, created_at`
+
+func scanWebhook(row pgx.Row) (*models.Webhook, error) {
+	var w models.Webhook
+	if err := row.Scan(&w.ID, &w.UserID, &w.URL, &w.Secret, &w.Events, &w.CreatedAt); err != nil {
+		return nil, notFound(err)
+	}
+	return &w, nil
+}
+
+func collectWebhooks(rows pgx.Rows) ([]models.Webhook, error) {
+	defer rows.Close()
+	var list []models.Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *w)
+	}
+	return list, rows.Err()
+}
+
+func (r *WebhookRepo) Create(ctx context.Context, w *models.Webhook) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO deal_webhooks (user_id, url, secret, events)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, w.UserID, w.URL, w.Secret, w.Events).Scan(&w.ID, &w.CreatedAt)
+}
+
+func (r *WebhookRepo) CountByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	var n int
+	err := r.pool.QueryRow(ctx, `SELECT count(*) FROM deal_webhooks WHERE user_id = $1`, userID).Scan(&n)
+	return n, err
+}
+
+func (r *WebhookRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.Webhook, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+webhookColumns+` FROM deal_webhooks
+		WHERE user_id = $1 ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return collectWebhooks(rows)
+}
+
+// Delete removes the user's webhook; ErrNotFound if it doesn't exist or isn't theirs.
+func (r *WebhookRepo) Delete(ctx context.Context, id, userID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM deal_webhooks WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListForDeal returns the webhooks subscribed to event of the deal's parties: the
+// advertiser and the channel owner.
+func (r *WebhookRepo) ListForDeal(ctx context.Context, dealID uuid.UUID, event string) ([]models.Webhook, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT w.id, w.user_id, w.url, w.secret, w.events, w.created_at
+		FROM deal_webhooks w
+		JOIN deals d ON d.id = $1
+		WHERE $2 = ANY(w.events)
+		  AND (w.user_id = d.advertiser_user_id
+		       OR EXISTS (SELECT 1 FROM channel_members cm
+		                  WHERE cm.channel_id = d.channel_id AND cm.user_id = w.user_id AND cm.role = 'owner'))
+	`, dealID, event)
+	if err != nil {
+		return nil, err
+	}
+	return collectWebhooks(rows)
+}
+
+// QueueDelivery puts an event for the webhook into the outbox, due right away.
+func (r *WebhookRepo) QueueDelivery(ctx context.Context, webhookID, dealID uuid.UUID, event string, payload []byte) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO webhook_outbox (webhook_id, deal_id, event, payload)
+		VALUES ($1, $2, $3, $4)
+	`, webhookID, dealID, event, string(payload))
+	return err
+}
+
+// ClaimDueDeliveries returns up to limit outbox entries due at now, oldest first, and pushes
+// their next attempt to leaseUntil so a worker that dies mid-delivery doesn't lose them.
+// SKIP LOCKED lets two workers run without claiming the same entry.
+func (r *WebhookRepo) ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.QueuedWebhookDelivery, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE webhook_outbox o SET next_attempt_at = $2
+		FROM deal_webhooks w
+		WHERE w.id = o.webhook_id AND o.id IN (
+			SELECT id FROM webhook_outbox WHERE next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING o.id, o.deal_id, o.event, o.payload, o.attempts,
+		          w.id, w.user_id, w.url, w.secret, w.events, w.created_at
+	`, now, leaseUntil, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.QueuedWebhookDelivery
+	for rows.Next() {
+		var d models.QueuedWebhookDelivery
+		var payload string
+		w := &d.Webhook
+		if err := rows.Scan(&d.ID, &d.DealID, &d.Event, &payload, &d.Attempts,
+			&w.ID, &w.UserID, &w.URL, &w.Secret, &w.Events, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		d.Payload = []byte(payload)
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// RescheduleDelivery records a failed attempt of an outbox entry and when to try it again.
+func (r *WebhookRepo) RescheduleDelivery(ctx context.Context, id uuid.UUID, attempts int, next time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE webhook_outbox SET attempts = $2, next_attempt_at = $3 WHERE id = $1
+	`, id, attempts, next)
+	return err
+}
+
+// DeleteQueuedDelivery removes an outbox entry that was delivered or ran out of attempts.
+func (r *WebhookRepo) DeleteQueuedDelivery(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM webhook_outbox WHERE id = $1`, id)
+	return err
+}
+
+func (r *WebhookRepo) RecordDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, deal_id, event, attempt, status_code, error, delivered)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, d.WebhookID, d.DealID, d.Event, d.Attempt, d.StatusCode, d.Error, d.Delivered).Scan(&d.ID, &d.CreatedAt)
+}
+
+// ListDeliveries returns the latest delivery attempts of the user's webhook, newest first.
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, webhookID, userID uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT d.id, d.webhook_id, d.deal_id, d.event, d.attempt, d.status_code, d.error, d.delivered, d.created_at
+		FROM webhook_deliveries d
+		JOIN deal_webhooks w ON w.id = d.webhook_id
+		WHERE d.webhook_id = $1 AND w.user_id = $2
+		ORDER BY d.created_at DESC LIMIT $3
+	`, webhookID, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.DealID, &d.Event, &d.Attempt, &d.StatusCode, &d.Error, &d.Delivered, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
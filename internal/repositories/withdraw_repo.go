@@ -35,7 +35,7 @@ func (r *WithdrawRepo) GetByChannel(ctx context.Context, channelID uuid.UUID) (*
 		FROM withdraw_wallets WHERE channel_id = $1
 	`, channelID).Scan(&w.ID, &w.ChannelID, &w.OwnerUserID, &w.WalletAddress, &w.CreatedAt, &w.UpdatedAt)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &w, nil
 }
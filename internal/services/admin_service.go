@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ads-marketplace/backend/internal/config"
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/ads-marketplace/backend/internal/repositories"
+	"github.com/ads-marketplace/backend/internal/ton"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// AdminService runs high-risk admin operations, optionally behind two-person approval.
+type AdminService struct {
+	dealService     *DealService
+	channelRepo     *repositories.ChannelRepo
+	adminActionRepo *repositories.AdminActionRepo
+	auditRepo       *repositories.AuditRepo
+	rdb             *redis.Client
+	cfg             *config.Config
+	log             *zap.Logger
+}
+
+func NewAdminService(
+	dealService *DealService,
+	channelRepo *repositories.ChannelRepo,
+	adminActionRepo *repositories.AdminActionRepo,
+	auditRepo *repositories.AuditRepo,
+	rdb *redis.Client,
+	cfg *config.Config,
+	log *zap.Logger,
+) *AdminService {
+	return &AdminService{
+		dealService:     dealService,
+		channelRepo:     channelRepo,
+		adminActionRepo: adminActionRepo,
+		auditRepo:       auditRepo,
+		rdb:             rdb,
+		cfg:             cfg,
+		log:             log,
+	}
+}
+
+// RequestDealAction executes an admin action on a deal, or — if the action is in
+// ADMIN_TWO_PERSON_ACTIONS — stores it as pending and returns it for approval.
+// A nil pending action means the action was executed immediately. Either way the deal must
+// exist and the action must apply to its current status.
+func (s *AdminService) RequestDealAction(ctx context.Context, adminID uuid.UUID, action string, dealID uuid.UUID, params map[string]any) (*models.PendingAdminAction, error) {
+	if err := s.dealService.CheckAdminAction(ctx, dealID, action, params); err != nil {
+		return nil, err
+	}
+	if !s.cfg.RequiresTwoPersonApproval(action) {
+		if err := s.executeDealAction(ctx, adminID, action, dealID, params); err != nil {
+			return nil, err
+		}
+		_ = s.auditRepo.Log(ctx, models.AuditLog{
+			ActorUserID: &adminID,
+			ActorType:   "admin",
+			Action:      "admin_" + action,
+			EntityType:  "deal",
+			EntityID:    &dealID,
+			Meta:        params,
+		})
+		return nil, nil
+	}
+
+	pending := &models.PendingAdminAction{
+		Action:      action,
+		EntityType:  "deal",
+		EntityID:    dealID,
+		Params:      params,
+		Status:      models.AdminActionStatusPending,
+		RequestedBy: adminID,
+	}
+	if err := s.adminActionRepo.Create(ctx, pending); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &adminID,
+		ActorType:   "admin",
+		Action:      "admin_action_requested",
+		EntityType:  "deal",
+		EntityID:    &dealID,
+		Meta: map[string]any{
+			"pending_action_id": pending.ID.String(),
+			"action":            action,
+			"requested_by":      adminID.String(),
+			"params":            params,
+		},
+	})
+
+	return pending, nil
+}
+
+// ApproveAction executes a pending action on behalf of a second admin.
+func (s *AdminService) ApproveAction(ctx context.Context, actionID uuid.UUID, approverID uuid.UUID) (*models.PendingAdminAction, error) {
+	pending, err := s.adminActionRepo.GetByID(ctx, actionID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, fmt.Errorf("pending action not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if pending.RequestedBy == approverID {
+		return nil, fmt.Errorf("action must be approved by a different admin")
+	}
+	err = s.adminActionRepo.Claim(ctx, actionID, approverID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, fmt.Errorf("action is no longer pending")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	execErr := s.executeDealAction(ctx, approverID, pending.Action, pending.EntityID, pending.Params)
+
+	status := models.AdminActionStatusExecuted
+	var errMsg *string
+	if execErr != nil {
+		status = models.AdminActionStatusFailed
+		msg := execErr.Error()
+		errMsg = &msg
+	}
+	if err := s.adminActionRepo.UpdateStatus(ctx, actionID, status, errMsg); err != nil {
+		s.log.Error("failed to update admin action status", zap.String("action_id", actionID.String()), zap.Error(err))
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &approverID,
+		ActorType:   "admin",
+		Action:      "admin_action_approved",
+		EntityType:  pending.EntityType,
+		EntityID:    &pending.EntityID,
+		Meta: map[string]any{
+			"pending_action_id": actionID.String(),
+			"action":            pending.Action,
+			"requested_by":      pending.RequestedBy.String(),
+			"approved_by":       approverID.String(),
+			"status":            status,
+		},
+	})
+
+	if execErr != nil {
+		return nil, execErr
+	}
+	return s.adminActionRepo.GetByID(ctx, actionID)
+}
+
+func (s *AdminService) ListPendingActions(ctx context.Context, limit, offset int) ([]models.PendingAdminAction, error) {
+	return s.adminActionRepo.ListByStatus(ctx, models.AdminActionStatusPending, limit, offset)
+}
+
+// SetPayoutFrozen freezes or unfreezes a deal's automatic release; the reason is required
+// and goes to the audit log. Not a fund movement, so never behind two-person approval.
+func (s *AdminService) SetPayoutFrozen(ctx context.Context, adminID, dealID uuid.UUID, frozen bool, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return fmt.Errorf("reason is required")
+	}
+	return s.dealService.SetPayoutFrozen(ctx, dealID, adminID, frozen, reason)
+}
+
+func (s *AdminService) ListCreativesForReview(ctx context.Context, limit, offset int) ([]models.DealCreative, error) {
+	return s.dealService.ListCreativesForReview(ctx, limit, offset)
+}
+
+func (s *AdminService) ResolveCreativeReview(ctx context.Context, creativeID, adminID uuid.UUID, approve bool, reason *string) error {
+	return s.dealService.ResolveCreativeReview(ctx, creativeID, adminID, approve, reason)
+}
+
+func (s *AdminService) ListRefundAddressRequests(ctx context.Context, limit, offset int) ([]models.RefundAddressRequest, error) {
+	return s.dealService.ListRefundAddressRequests(ctx, limit, offset)
+}
+
+// ListUnmatchedPayments lists incoming payments no escrow claimed (status "unmatched" by default).
+// Staff credit one with POST /admin/deals/:id/escrow/match using its tx_lt as tx_hash.
+func (s *AdminService) ListUnmatchedPayments(ctx context.Context, status string, limit, offset int) ([]models.UnmatchedPayment, error) {
+	if status == "" {
+		status = models.UnmatchedPaymentStatusUnmatched
+	}
+	return s.dealService.ListUnmatchedPayments(ctx, status, limit, offset)
+}
+
+func (s *AdminService) ResolveRefundAddressRequest(ctx context.Context, requestID, adminID uuid.UUID, approve bool, reason *string) (*models.RefundAddressRequest, error) {
+	return s.dealService.ResolveRefundAddressRequest(ctx, requestID, adminID, approve, reason)
+}
+
+// IndexerHealth reads the TON indexer heartbeat and reports it dead if older than TON_INDEXER_STALE_SECONDS.
+func (s *AdminService) IndexerHealth(ctx context.Context) (ton.IndexerHealth, error) {
+	hb, err := ton.ReadHeartbeat(ctx, s.rdb)
+	if err != nil {
+		return ton.IndexerHealth{}, err
+	}
+	health := ton.CheckHeartbeat(hb, time.Now(), s.cfg.IndexerStaleAfter)
+	if !health.Alive {
+		s.log.Warn("TON indexer heartbeat is stale", zap.Int64p("age_seconds", health.AgeSeconds))
+	}
+	return health, nil
+}
+
+// MergeChannels folds a duplicate channel row into the surviving one. Refused when upcoming
+// scheduled deals of the two channels would collide once they share one posting timeline.
+func (s *AdminService) MergeChannels(ctx context.Context, adminID, keepID, dupID uuid.UUID) (*repositories.ChannelMergeResult, error) {
+	if keepID == dupID {
+		return nil, fmt.Errorf("cannot merge a channel into itself")
+	}
+	keep, err := s.channelRepo.GetByID(ctx, keepID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, fmt.Errorf("channel %s not found", keepID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	dup, err := s.channelRepo.GetByID(ctx, dupID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, fmt.Errorf("channel %s not found", dupID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if keep.TelegramChatID != nil && dup.TelegramChatID != nil && *keep.TelegramChatID != *dup.TelegramChatID {
+		return nil, fmt.Errorf("channels point to different Telegram chats (%d vs %d)", *keep.TelegramChatID, *dup.TelegramChatID)
+	}
+	if err := s.dealService.checkMergeSlotConflicts(ctx, keepID, dupID); err != nil {
+		return nil, err
+	}
+
+	res, err := s.channelRepo.MergeChannels(ctx, keepID, dupID)
+	if err != nil {
+		return nil, fmt.Errorf("merge failed: %w", err)
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &adminID,
+		ActorType:   "admin",
+		Action:      "channels_merged",
+		EntityType:  "channel",
+		EntityID:    &keepID,
+		Meta: map[string]any{
+			"duplicate_id":       dupID.String(),
+			"duplicate_username": dup.Username,
+			"result":             res,
+		},
+	})
+	s.log.Info("channels merged",
+		zap.String("keep", keep.Username),
+		zap.String("duplicate", dup.Username),
+		zap.Int64("deals_moved", res.DealsMoved),
+	)
+
+	return res, nil
+}
+
+// QueryAudit searches the audit trail across entities, newest first.
+func (s *AdminService) QueryAudit(ctx context.Context, f repositories.AuditFilter) (*repositories.AuditPage, error) {
+	return s.auditRepo.Query(ctx, f)
+}
+
+// ListStatsFailures returns channels whose stats refresh keeps failing (likely dead or renamed).
+func (s *AdminService) ListStatsFailures(ctx context.Context, minFailures, limit, offset int) ([]models.ChannelStatsFailure, error) {
+	return s.channelRepo.ListStatsFailures(ctx, minFailures, limit, offset)
+}
+
+func (s *AdminService) executeDealAction(ctx context.Context, adminID uuid.UUID, action string, dealID uuid.UUID, params map[string]any) error {
+	switch action {
+	case models.AdminActionForceRelease:
+		return s.dealService.ForceRelease(ctx, dealID, adminID)
+	case models.AdminActionForceRefund:
+		return s.dealService.ForceRefund(ctx, dealID, adminID)
+	case models.AdminActionManualEscrowMatch:
+		txHash, _ := params["tx_hash"].(string)
+		payer, _ := params["payer_address"].(string)
+		if txHash == "" || payer == "" {
+			return fmt.Errorf("tx_hash and payer_address are required")
+		}
+		return s.dealService.ManualEscrowMatch(ctx, dealID, adminID, txHash, payer)
+	case models.AdminActionResolveDispute:
+		outcome, _ := params["outcome"].(string)
+		return s.dealService.ResolveDispute(ctx, dealID, adminID, outcome)
+	case models.AdminActionForceStatus:
+		status, _ := params["status"].(string)
+		reason, _ := params["reason"].(string)
+		if status == "" || reason == "" {
+			return fmt.Errorf("status and reason are required")
+		}
+		return s.dealService.ForceStatus(ctx, dealID, adminID, status)
+	default:
+		return fmt.Errorf("unknown admin action %q", action)
+	}
+}
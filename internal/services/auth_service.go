@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ads-marketplace/backend/internal/auth"
+	"github.com/ads-marketplace/backend/internal/config"
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/ads-marketplace/backend/internal/repositories"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TokenPair is what a login or a refresh returns to the client.
+type TokenPair struct {
+	AccessToken  string
+	ExpiresAt    time.Time // of the access token
+	RefreshToken string
+}
+
+// AuthService issues access/refresh token pairs, rotates refresh tokens and revokes both
+// on logout.
+type AuthService struct {
+	userRepo    *repositories.UserRepo
+	refreshRepo *repositories.RefreshTokenRepo
+	denylist    auth.TokenDenylist
+	cfg         *config.Config
+	log         *zap.Logger
+}
+
+func NewAuthService(
+	userRepo *repositories.UserRepo,
+	refreshRepo *repositories.RefreshTokenRepo,
+	denylist auth.TokenDenylist,
+	cfg *config.Config,
+	log *zap.Logger,
+) *AuthService {
+	return &AuthService{
+		userRepo:    userRepo,
+		refreshRepo: refreshRepo,
+		denylist:    denylist,
+		cfg:         cfg,
+		log:         log,
+	}
+}
+
+// accessTTL — в переходном режиме access-токен живёт столько же, сколько раньше.
+func (s *AuthService) accessTTL() time.Duration {
+	if s.cfg.JWTLegacyTokens {
+		return s.cfg.JWTExpiration
+	}
+	return s.cfg.JWTAccessTTL
+}
+
+// IssueTokens starts a new session for the user (after Telegram login).
+func (s *AuthService) IssueTokens(ctx context.Context, user *models.User) (*TokenPair, error) {
+	refresh, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshRepo.Create(ctx, user.ID, auth.HashRefreshToken(refresh), time.Now().Add(s.cfg.RefreshTokenTTL)); err != nil {
+		return nil, err
+	}
+	return s.accessToken(user, refresh)
+}
+
+// Refresh rotates the refresh token and issues a new access token. A reused (already
+// rotated) refresh token revokes its whole session.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, *models.User, error) {
+	if refreshToken == "" {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	next, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, nil, err
+	}
+	userID, err := s.refreshRepo.Rotate(ctx, auth.HashRefreshToken(refreshToken), auth.HashRefreshToken(next), time.Now().Add(s.cfg.RefreshTokenTTL))
+	if errors.Is(err, repositories.ErrTokenReused) {
+		s.log.Warn("refresh token reused, session revoked")
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if errors.Is(err, repositories.ErrNotFound) { // account deleted
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	pair, err := s.accessToken(user, next)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pair, user, nil
+}
+
+// Logout revokes the presented access token and the session of refreshToken; with all,
+// every session of the user.
+func (s *AuthService) Logout(ctx context.Context, userID uuid.UUID, claims *auth.Claims, refreshToken string, all bool) error {
+	if claims != nil && claims.ID != "" && claims.ExpiresAt != nil {
+		if err := s.denylist.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			return err
+		}
+	}
+	if all {
+		return s.refreshRepo.RevokeAllForUser(ctx, userID)
+	}
+	if refreshToken != "" {
+		return s.refreshRepo.RevokeFamily(ctx, userID, auth.HashRefreshToken(refreshToken))
+	}
+	return nil
+}
+
+func (s *AuthService) accessToken(user *models.User, refresh string) (*TokenPair, error) {
+	ttl := s.accessTTL()
+	token, err := auth.GenerateJWT(s.cfg.JWTSecret, user.ID, user.TelegramUserID, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{AccessToken: token, ExpiresAt: time.Now().Add(ttl), RefreshToken: refresh}, nil
+}
@@ -9,19 +9,22 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ads-marketplace/backend/internal/auth"
 	"go.uber.org/zap"
 )
 
 // BotClient communicates with the Python bot internal API.
 type BotClient struct {
 	baseURL    string
+	secret     string // INTERNAL_API_SECRET; requests are unsigned when empty
 	httpClient *http.Client
 	log        *zap.Logger
 }
 
-func NewBotClient(baseURL string, log *zap.Logger) *BotClient {
+func NewBotClient(baseURL, secret string, log *zap.Logger) *BotClient {
 	return &BotClient{
 		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  secret,
 		httpClient: &http.Client{
 			Timeout: 15 * time.Second,
 		},
@@ -29,6 +32,16 @@ func NewBotClient(baseURL string, log *zap.Logger) *BotClient {
 	}
 }
 
+// do signs the request with X-Internal-Signature (see auth.SignInternalRequest) and sends it.
+// body must be what the request sends.
+func (c *BotClient) do(req *http.Request, body []byte) (*http.Response, error) {
+	if c.secret != "" {
+		req.Header.Set(auth.InternalSignatureHeader,
+			auth.SignInternalRequest(c.secret, time.Now(), req.Method, req.URL.RequestURI(), body))
+	}
+	return c.httpClient.Do(req)
+}
+
 type AdminInfo struct {
 	TelegramUserID  int64  `json:"telegram_user_id"`
 	Username        string `json:"username"`
@@ -44,7 +57,7 @@ func (c *BotClient) GetAdmins(ctx context.Context, channelUsername string) ([]Ad
 		return nil, err
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, nil)
 	if err != nil {
 		return nil, fmt.Errorf("bot service unavailable: %w", err)
 	}
@@ -74,7 +87,7 @@ func (c *BotClient) CheckAdmin(ctx context.Context, channelUsername string, tele
 		return nil, err
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, nil)
 	if err != nil {
 		return nil, fmt.Errorf("bot service unavailable: %w", err)
 	}
@@ -93,10 +106,11 @@ func (c *BotClient) CheckAdmin(ctx context.Context, channelUsername string, tele
 }
 
 type PostRequest struct {
-	DealID      string `json:"deal_id"`
-	ChatID      int64  `json:"chat_id"`
-	Text        string `json:"text"`
-	ScheduledAt string `json:"scheduled_at,omitempty"`
+	DealID          string `json:"deal_id"`
+	ChatID          int64  `json:"chat_id"`
+	Text            string `json:"text"`
+	ScheduledAt     string `json:"scheduled_at,omitempty"`
+	ChannelUsername string `json:"channel_username,omitempty"` // for post_url
 }
 
 type PostResult struct {
@@ -118,7 +132,7 @@ func (c *BotClient) PostToDeal(ctx context.Context, req PostRequest) (*PostResul
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.do(httpReq, body)
 	if err != nil {
 		return nil, fmt.Errorf("bot service unavailable: %w", err)
 	}
@@ -136,6 +150,24 @@ func (c *BotClient) PostToDeal(ctx context.Context, req PostRequest) (*PostResul
 	return &result, nil
 }
 
+// Health checks that the bot's internal API answers.
+func (c *BotClient) Health(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/health", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bot health returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
 func (c *BotClient) SendNotification(ctx context.Context, telegramUserID int64, text string) error {
 	body, _ := json.Marshal(map[string]any{
 		"telegram_user_id": telegramUserID,
@@ -149,7 +181,7 @@ func (c *BotClient) SendNotification(ctx context.Context, telegramUserID int64,
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, body)
 	if err != nil {
 		c.log.Warn("failed to send bot notification", zap.Error(err))
 		return err
@@ -2,7 +2,11 @@ package services
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"unicode"
 
 	"github.com/ads-marketplace/backend/internal/models"
 	"github.com/ads-marketplace/backend/internal/repositories"
@@ -12,17 +16,23 @@ import (
 
 type CampaignService struct {
 	campaignRepo *repositories.CampaignRepo
+	channelRepo  *repositories.ChannelRepo
+	dealRepo     *repositories.DealRepo
 	auditRepo    *repositories.AuditRepo
 	log          *zap.Logger
 }
 
 func NewCampaignService(
 	campaignRepo *repositories.CampaignRepo,
+	channelRepo *repositories.ChannelRepo,
+	dealRepo *repositories.DealRepo,
 	auditRepo *repositories.AuditRepo,
 	log *zap.Logger,
 ) *CampaignService {
 	return &CampaignService{
 		campaignRepo: campaignRepo,
+		channelRepo:  channelRepo,
+		dealRepo:     dealRepo,
 		auditRepo:    auditRepo,
 		log:          log,
 	}
@@ -31,7 +41,7 @@ func NewCampaignService(
 func (s *CampaignService) Create(ctx context.Context, userID uuid.UUID, c *models.Campaign) error {
 	c.AdvertiserUserID = userID
 	if c.Status == "" {
-		c.Status = "active"
+		c.Status = models.CampaignStatusActive
 	}
 
 	if err := s.campaignRepo.Create(ctx, c); err != nil {
@@ -55,7 +65,7 @@ func (s *CampaignService) GetByID(ctx context.Context, id uuid.UUID, userID uuid
 		return nil, err
 	}
 	if c.AdvertiserUserID != userID {
-		return nil, fmt.Errorf("campaign not found")
+		return nil, ErrCampaignNotFound
 	}
 	return c, nil
 }
@@ -67,11 +77,14 @@ func (s *CampaignService) List(ctx context.Context, userID uuid.UUID, f reposito
 
 func (s *CampaignService) Update(ctx context.Context, id uuid.UUID, userID uuid.UUID, c *models.Campaign) error {
 	existing, err := s.campaignRepo.GetByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrCampaignNotFound
+	}
 	if err != nil {
-		return fmt.Errorf("campaign not found")
+		return err
 	}
 	if existing.AdvertiserUserID != userID {
-		return fmt.Errorf("campaign not found")
+		return ErrCampaignNotFound
 	}
 
 	c.ID = id
@@ -85,12 +98,225 @@ func (s *CampaignService) Update(ctx context.Context, id uuid.UUID, userID uuid.
 
 func (s *CampaignService) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
 	existing, err := s.campaignRepo.GetByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrCampaignNotFound
+	}
 	if err != nil {
-		return fmt.Errorf("campaign not found")
+		return err
 	}
 	if existing.AdvertiserUserID != userID {
-		return fmt.Errorf("campaign not found")
+		return ErrCampaignNotFound
+	}
+
+	// Soft delete: the campaign is archived, not removed
+	err = s.campaignRepo.Archive(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrCampaignNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &userID,
+		ActorType:   "user",
+		Action:      "campaign_archived",
+		EntityType:  "campaign",
+		EntityID:    &id,
+	})
+	return nil
+}
+
+// CampaignSpend compares a campaign's budget with the deals booked for it. Going over
+// budget is allowed; OverBudget lets the client warn about it.
+type CampaignSpend struct {
+	BudgetTON  string `json:"budget_ton"`
+	SpentTON   string `json:"spent_ton"` // prices of deals that didn't fall through
+	DealCount  int    `json:"deal_count"`
+	OverBudget bool   `json:"over_budget"`
+}
+
+// GetCampaignSpend sums the prices of the campaign's deals against its budget.
+func (s *CampaignService) GetCampaignSpend(ctx context.Context, campaignID, userID uuid.UUID) (*CampaignSpend, error) {
+	c, err := s.GetByID(ctx, campaignID, userID)
+	if err != nil {
+		return nil, err
+	}
+	spent, count, err := s.dealRepo.SumCampaignPrices(ctx, campaignID)
+	if err != nil {
+		return nil, err
 	}
+	return &CampaignSpend{
+		BudgetTON:  c.BudgetTON,
+		SpentTON:   spent,
+		DealCount:  count,
+		OverBudget: exceedsBudget(spent, c.BudgetTON),
+	}, nil
+}
+
+// ListDeals returns a page of the deals linked to the advertiser's campaign.
+func (s *CampaignService) ListDeals(ctx context.Context, campaignID, userID uuid.UUID, f repositories.DealFilter) (*repositories.Page[models.DealWithChannel], error) {
+	if _, err := s.GetByID(ctx, campaignID, userID); err != nil {
+		return nil, err
+	}
+	f.CampaignID = &campaignID
+	f.AdvertiserUserID = &userID
+	return s.dealRepo.ListWithChannel(ctx, f)
+}
+
+// exceedsBudget reports whether spent is above budget, both decimal strings. An
+// unparseable budget is never exceeded.
+func exceedsBudget(spent, budget string) bool {
+	b, ok := new(big.Rat).SetString(budget)
+	if !ok {
+		return false
+	}
+	sp, ok := new(big.Rat).SetString(spent)
+	if !ok {
+		return false
+	}
+	return sp.Cmp(b) > 0
+}
+
+const (
+	// suggestionCandidates is how many explore listings (by trust) are scored per request
+	suggestionCandidates = 100
+	MaxSuggestions       = 50
+)
 
-	return s.campaignRepo.Delete(ctx, id)
+// ChannelSuggestion is an explore channel ranked for a campaign.
+type ChannelSuggestion struct {
+	ExploreChannel
+	Score           float64  `json:"score"`
+	MatchedKeywords []string `json:"matched_keywords"`
+}
+
+// SuggestChannels ranks active listings whose cheapest format fits the campaign
+// budget by how many target-audience keywords they mention, then by price.
+func (s *CampaignService) SuggestChannels(ctx context.Context, campaignID, userID uuid.UUID, limit int) ([]ChannelSuggestion, error) {
+	c, err := s.GetByID(ctx, campaignID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > MaxSuggestions {
+		limit = 10
+	}
+	budget, ok := new(big.Rat).SetString(c.BudgetTON)
+	if !ok || budget.Sign() <= 0 {
+		return []ChannelSuggestion{}, nil
+	}
+
+	rows, err := s.channelRepo.SearchExplore(ctx, repositories.ChannelFilter{
+		MaxPriceTON: &c.BudgetTON,
+		ViewerID:    &userID,
+		SortBy:      repositories.ExploreSortTrust,
+		Limit:       suggestionCandidates,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keywords := audienceKeywords(c.TargetAudience)
+	out := make([]ChannelSuggestion, 0, len(rows))
+	for _, r := range rows {
+		ch := toExploreChannel(r)
+		score, matched, ok := suggestionScore(keywords, budget, ch)
+		if !ok {
+			continue
+		}
+		out = append(out, ChannelSuggestion{ExploreChannel: ch, Score: score, MatchedKeywords: matched})
+	}
+	sortSuggestions(out)
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+var audienceStopwords = map[string]bool{
+	"and": true, "the": true, "for": true, "with": true, "who": true, "are": true,
+	"или": true, "для": true, "кто": true, "все": true, "как": true,
+}
+
+// audienceKeywords splits a free-text target audience into lowercase keywords of
+// at least 3 letters, without stopwords and duplicates, in order of appearance.
+func audienceKeywords(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := make(map[string]bool, len(fields))
+	var out []string
+	for _, f := range fields {
+		if len([]rune(f)) < 3 || audienceStopwords[f] || seen[f] {
+			continue
+		}
+		seen[f] = true
+		out = append(out, f)
+	}
+	return out
+}
+
+// suggestionScore scores a channel for a campaign: 10 points per keyword found in
+// its title, username, category, language or description, plus up to 1 point for
+// the share of the budget left after the cheapest format. ok is false when the
+// channel has no priced format within budget.
+func suggestionScore(keywords []string, budget *big.Rat, ch ExploreChannel) (score float64, matched []string, ok bool) {
+	price := cheapestPrice(ch.Listing)
+	if price == nil || price.Cmp(budget) > 0 {
+		return 0, nil, false
+	}
+
+	var text strings.Builder
+	text.WriteString(strings.ToLower(ch.Username))
+	for _, p := range []*string{ch.Title, ch.Category, ch.Language} {
+		if p != nil {
+			text.WriteString(" " + strings.ToLower(*p))
+		}
+	}
+	if ch.Listing.Description != nil {
+		text.WriteString(" " + strings.ToLower(*ch.Listing.Description))
+	}
+	haystack := text.String()
+
+	matched = []string{}
+	for _, k := range keywords {
+		if strings.Contains(haystack, k) {
+			matched = append(matched, k)
+		}
+	}
+
+	left := new(big.Rat).Quo(new(big.Rat).Sub(budget, price), budget)
+	fit, _ := left.Float64()
+	return float64(len(matched))*10 + fit, matched, true
+}
+
+// cheapestPrice returns the lowest listed format price, nil if none parses.
+func cheapestPrice(l *ExploreChannelListing) *big.Rat {
+	if l == nil {
+		return nil
+	}
+	var min *big.Rat
+	for _, p := range []*string{l.PricePostTON, l.PriceRepostTON, l.PriceStoryTON} {
+		if p == nil {
+			continue
+		}
+		v, ok := new(big.Rat).SetString(*p)
+		if !ok || v.Sign() < 0 {
+			continue
+		}
+		if min == nil || v.Cmp(min) < 0 {
+			min = v
+		}
+	}
+	return min
+}
+
+// sortSuggestions orders by score, ties broken by channel ID so the order is stable.
+func sortSuggestions(s []ChannelSuggestion) {
+	sort.Slice(s, func(i, j int) bool {
+		if s[i].Score != s[j].Score {
+			return s[i].Score > s[j].Score
+		}
+		return s[i].ID.String() < s[j].ID.String()
+	})
 }
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestExceedsBudget(t *testing.T) {
+	tests := []struct {
+		name          string
+		spent, budget string
+		expected      bool
+	}{
+		{"under budget", "40", "100", false},
+		{"exactly the budget", "100.000000000", "100", false},
+		{"over by a nanoton", "100.000000001", "100", true},
+		{"nothing booked", "0", "100", false},
+		{"unparseable budget", "500", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exceedsBudget(tt.spent, tt.budget); got != tt.expected {
+				t.Errorf("exceedsBudget(%q, %q) = %v, want %v", tt.spent, tt.budget, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAudienceKeywords(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"Crypto traders and DeFi fans", []string{"crypto", "traders", "defi", "fans"}},
+		{"crypto, CRYPTO; crypto!", []string{"crypto"}},
+		{"IT и разработчики для стартапов", []string{"разработчики", "стартапов"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := audienceKeywords(tt.text); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("audienceKeywords(%q) = %v, want %v", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSuggestionScore(t *testing.T) {
+	str := func(s string) *string { return &s }
+	budget := big.NewRat(100, 1)
+	keywords := []string{"crypto", "defi"}
+
+	tests := []struct {
+		name        string
+		ch          ExploreChannel
+		expected    float64
+		expectedOK  bool
+		wantMatched []string
+	}{
+		{
+			name: "two keywords, half the budget",
+			ch: ExploreChannel{Username: "cryptonews", Listing: &ExploreChannelListing{
+				PricePostTON: str("50"), Description: str("Daily DeFi digest"),
+			}},
+			expected: 20.5, expectedOK: true, wantMatched: []string{"crypto", "defi"},
+		},
+		{
+			name: "cheapest format decides the fit",
+			ch: ExploreChannel{Username: "cooking", Category: str("crypto"), Listing: &ExploreChannelListing{
+				PricePostTON: str("90"), PriceStoryTON: str("25"),
+			}},
+			expected: 10.75, expectedOK: true, wantMatched: []string{"crypto"},
+		},
+		{
+			name: "no keywords, still within budget",
+			ch: ExploreChannel{Username: "travel", Listing: &ExploreChannelListing{
+				PricePostTON: str("100"),
+			}},
+			expected: 0, expectedOK: true, wantMatched: []string{},
+		},
+		{
+			name: "over budget",
+			ch: ExploreChannel{Username: "cryptowhales", Listing: &ExploreChannelListing{
+				PricePostTON: str("100.5"),
+			}},
+			expectedOK: false,
+		},
+		{
+			name:       "no priced format",
+			ch:         ExploreChannel{Username: "crypto", Listing: &ExploreChannelListing{}},
+			expectedOK: false,
+		},
+		{
+			name:       "no listing",
+			ch:         ExploreChannel{Username: "crypto"},
+			expectedOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, matched, ok := suggestionScore(keywords, budget, tt.ch)
+			if ok != tt.expectedOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.expectedOK)
+			}
+			if !ok {
+				return
+			}
+			if score != tt.expected {
+				t.Errorf("score = %v, want %v", score, tt.expected)
+			}
+			if !reflect.DeepEqual(matched, tt.wantMatched) {
+				t.Errorf("matched = %v, want %v", matched, tt.wantMatched)
+			}
+		})
+	}
+}
+
+func TestSortSuggestionsDeterministic(t *testing.T) {
+	a := uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	b := uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+	c := uuid.MustParse("00000000-0000-0000-0000-00000000000c")
+	s := []ChannelSuggestion{
+		{ExploreChannel: ExploreChannel{ID: c}, Score: 10.5},
+		{ExploreChannel: ExploreChannel{ID: b}, Score: 20},
+		{ExploreChannel: ExploreChannel{ID: a}, Score: 10.5},
+	}
+	sortSuggestions(s)
+
+	want := []uuid.UUID{b, a, c}
+	for i, id := range want {
+		if s[i].ID != id {
+			t.Errorf("position %d = %s, want %s", i, s[i].ID, id)
+		}
+	}
+}
@@ -2,20 +2,36 @@ package services
 
 import (
 	"context"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
+	"time"
 
+	"github.com/ads-marketplace/backend/internal/apperr"
 	"github.com/ads-marketplace/backend/internal/config"
+	"github.com/ads-marketplace/backend/internal/events"
 	"github.com/ads-marketplace/backend/internal/models"
 	"github.com/ads-marketplace/backend/internal/repositories"
+	"github.com/ads-marketplace/backend/internal/statsparser"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
+// ErrChannelExists is returned when the channel is already registered by someone else
+// and the caller could not be verified as one of its admins.
+var ErrChannelExists = apperr.New(apperr.CodeChannelExists)
+
 type ChannelService struct {
 	channelRepo *repositories.ChannelRepo
 	userRepo    *repositories.UserRepo
 	auditRepo   *repositories.AuditRepo
+	ratingRepo  *repositories.RatingRepo
 	botClient   *BotClient
+	publisher   events.Publisher
+	rdb         *redis.Client
 	cfg         *config.Config
 	log         *zap.Logger
 }
@@ -24,7 +40,10 @@ func NewChannelService(
 	channelRepo *repositories.ChannelRepo,
 	userRepo *repositories.UserRepo,
 	auditRepo *repositories.AuditRepo,
+	ratingRepo *repositories.RatingRepo,
 	botClient *BotClient,
+	publisher events.Publisher,
+	rdb *redis.Client,
 	cfg *config.Config,
 	log *zap.Logger,
 ) *ChannelService {
@@ -32,25 +51,39 @@ func NewChannelService(
 		channelRepo: channelRepo,
 		userRepo:    userRepo,
 		auditRepo:   auditRepo,
+		ratingRepo:  ratingRepo,
 		botClient:   botClient,
+		publisher:   publisher,
+		rdb:         rdb,
 		cfg:         cfg,
 		log:         log,
 	}
 }
 
-func (s *ChannelService) CreateChannel(ctx context.Context, username string, creatorUserID uuid.UUID) (*models.Channel, error) {
+// CreateChannel registers a channel. If the username is already taken, the existing channel
+// is returned (created=false) when the creator is a member or a verified admin of it;
+// verified admins are added as members. Otherwise ErrChannelExists.
+func (s *ChannelService) CreateChannel(ctx context.Context, username string, creatorUserID uuid.UUID) (*models.Channel, bool, error) {
 	username = repositories.NormalizeUsername(username)
 	if username == "" {
-		return nil, fmt.Errorf("username is required")
+		return nil, false, fmt.Errorf("username is required")
 	}
 
 	ch := &models.Channel{
-		Username:  username,
-		BotStatus: "pending",
+		Username:      username,
+		AddedByUserID: &creatorUserID,
+		BotStatus:     "pending",
 	}
 
 	if err := s.channelRepo.Create(ctx, ch); err != nil {
-		return nil, err
+		if !repositories.IsUniqueViolation(err) {
+			return nil, false, err
+		}
+		existing, err := s.joinExistingChannel(ctx, username, creatorUserID)
+		if err != nil {
+			return nil, false, err
+		}
+		return existing, false, nil
 	}
 
 	_ = s.auditRepo.Log(ctx, models.AuditLog{
@@ -61,17 +94,153 @@ func (s *ChannelService) CreateChannel(ctx context.Context, username string, cre
 		EntityID:    &ch.ID,
 	})
 
-	return ch, nil
+	return ch, true, nil
+}
+
+// joinExistingChannel handles a duplicate CreateChannel: members get the channel back as is,
+// channel admins confirmed by the bot are added as members, everyone else gets ErrChannelExists.
+func (s *ChannelService) joinExistingChannel(ctx context.Context, username string, userID uuid.UUID) (*models.Channel, error) {
+	existing, err := s.channelRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if existing.AddedByUserID != nil && *existing.AddedByUserID == userID {
+		return existing, nil
+	}
+	if _, err := s.channelRepo.GetMemberByUserAndChannel(ctx, existing.ID, userID); err == nil {
+		return existing, nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrChannelExists
+	}
+	admins, err := s.botClient.GetAdmins(ctx, existing.Username)
+	if err != nil {
+		s.log.Warn("duplicate channel: admin check failed", zap.String("channel", existing.Username), zap.Error(err))
+		return nil, ErrChannelExists
+	}
+
+	for _, a := range admins {
+		if a.TelegramUserID != user.TelegramUserID {
+			continue
+		}
+		members, err := s.channelRepo.GetMembers(ctx, existing.ID)
+		if err != nil {
+			return nil, err
+		}
+		role, ok := duplicateJoinRole(a.IsOwner, members)
+		if !ok {
+			return nil, ErrChannelExists
+		}
+		m := &models.ChannelMember{
+			ChannelID: existing.ID,
+			UserID:    userID,
+			Role:      role,
+			CanPost:   a.CanPostMessages,
+		}
+		if err := s.channelRepo.AddMember(ctx, m); err != nil {
+			return nil, err
+		}
+		_ = s.auditRepo.Log(ctx, models.AuditLog{
+			ActorUserID: &userID,
+			ActorType:   "user",
+			Action:      "channel_member_joined",
+			EntityType:  "channel",
+			EntityID:    &existing.ID,
+			Meta:        map[string]any{"role": role, "via": "duplicate_create"},
+		})
+		return existing, nil
+	}
+
+	return nil, ErrChannelExists
+}
+
+// duplicateJoinRole picks the role of a verified channel admin joining through a duplicate
+// create, or false if there is no room. A channel has one owner: the Telegram owner takes
+// an unowned channel and otherwise joins as a manager, and ownership moves only through
+// TransferOwnership. Other admins join as managers while the channel has fewer than 3 members.
+func duplicateJoinRole(isTelegramOwner bool, members []models.ChannelMember) (string, bool) {
+	hasOwner := false
+	for _, m := range members {
+		if m.Role == "owner" {
+			hasOwner = true
+		}
+	}
+	if isTelegramOwner {
+		if !hasOwner {
+			return "owner", true
+		}
+		return "manager", true
+	}
+	if len(members) >= 3 {
+		return "", false
+	}
+	return "manager", true
+}
+
+// UsernameCheck tells the add-channel form whether a username is already in the marketplace.
+// Deliberately carries no member or owner identities.
+type UsernameCheck struct {
+	Username         string `json:"username"`
+	Exists           bool   `json:"exists"`
+	HasActiveListing bool   `json:"has_active_listing"`
+	IsManaged        bool   `json:"is_managed"`
+}
+
+func (s *ChannelService) CheckUsername(ctx context.Context, username string) (*UsernameCheck, error) {
+	res := &UsernameCheck{Username: repositories.NormalizeUsername(username)}
+
+	ch, err := s.channelRepo.GetByUsername(ctx, res.Username)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	res.Exists = true
+
+	members, err := s.channelRepo.CountMembers(ctx, ch.ID)
+	if err != nil {
+		return nil, err
+	}
+	res.IsManaged = members > 0
+
+	listing, err := s.channelRepo.GetListing(ctx, ch.ID)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return nil, err
+	}
+	res.HasActiveListing = listing != nil && listing.Status == "active"
+
+	return res, nil
 }
 
 func (s *ChannelService) GetChannel(ctx context.Context, id uuid.UUID) (*models.Channel, error) {
 	return s.channelRepo.GetByID(ctx, id)
 }
 
+// GetChannelRating aggregates the advertisers' ratings of the channel's completed deals.
+func (s *ChannelService) GetChannelRating(ctx context.Context, channelID uuid.UUID) (models.RatingSummary, error) {
+	if _, err := s.channelRepo.GetByID(ctx, channelID); err != nil {
+		return models.RatingSummary{}, err
+	}
+	return s.ratingRepo.ChannelSummary(ctx, channelID)
+}
+
 func (s *ChannelService) SearchChannels(ctx context.Context, f repositories.ChannelFilter) ([]models.Channel, error) {
+	ctx, cancel := s.searchContext(ctx)
+	defer cancel()
 	return s.channelRepo.Search(ctx, f)
 }
 
+// searchContext bounds the heavy explore/search joins by DB_SEARCH_TIMEOUT_MS.
+func (s *ChannelService) searchContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.cfg.DBSearchTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.cfg.DBSearchTimeout)
+}
+
 func (s *ChannelService) GetMyChannels(ctx context.Context, userID uuid.UUID) ([]models.Channel, error) {
 	return s.channelRepo.GetByUserID(ctx, userID)
 }
@@ -103,7 +272,7 @@ func (s *ChannelService) AddManager(ctx context.Context, channelID uuid.UUID, ac
 	}
 
 	// Get or create manager user
-	managerUser, err := s.userRepo.UpsertByTelegramID(ctx, managerTelegramID, nil, nil, nil)
+	managerUser, err := s.userRepo.UpsertByTelegramID(ctx, managerTelegramID, nil, nil, nil, nil, nil)
 	if err != nil {
 		return err
 	}
@@ -132,6 +301,111 @@ func (s *ChannelService) AddManager(ctx context.Context, channelID uuid.UUID, ac
 	return s.channelRepo.AddMember(ctx, m)
 }
 
+// RemoveManager revokes a manager's access. Only the owner can do it, and the owner
+// cannot be removed this way.
+func (s *ChannelService) RemoveManager(ctx context.Context, channelID, actorID, managerUserID uuid.UUID) error {
+	actor, err := s.channelRepo.GetMemberByUserAndChannel(ctx, channelID, actorID)
+	if err != nil || actor.Role != "owner" {
+		return ErrNotChannelOwner
+	}
+
+	target, err := s.channelRepo.GetMemberByUserAndChannel(ctx, channelID, managerUserID)
+	if err != nil {
+		return err
+	}
+	if target.Role == "owner" {
+		return ErrCannotRemoveOwner
+	}
+
+	if err := s.channelRepo.RemoveMember(ctx, channelID, managerUserID); err != nil {
+		return err
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &actorID,
+		ActorType:   "user",
+		Action:      "channel_manager_removed",
+		EntityType:  "channel",
+		EntityID:    &channelID,
+		Meta:        map[string]any{"user_id": managerUserID.String()},
+	})
+
+	ev := events.ManagerRemoved{ChannelID: channelID.String(), UserID: managerUserID.String()}
+	if ch, err := s.channelRepo.GetByID(ctx, channelID); err == nil {
+		ev.ChannelUsername = ch.Username
+	}
+	if u, err := s.userRepo.GetByID(ctx, managerUserID); err == nil && ev.ChannelUsername != "" && u.NotificationsEnabled {
+		ev.TelegramID = u.TelegramUserID
+		if u.LanguageCode != nil {
+			ev.Language = *u.LanguageCode
+		}
+	}
+	_ = s.publisher.Publish(ctx, "events:deal", events.NewManagerRemovedEvent(ev))
+
+	return nil
+}
+
+// TransferOwnership hands the channel to another Telegram admin (e.g. after a sale).
+// The current owner stays on as a manager; the withdraw wallet is cleared so the new
+// owner sets their own before the next payout.
+func (s *ChannelService) TransferOwnership(ctx context.Context, channelID, currentOwnerID uuid.UUID, newOwnerTelegramID int64) error {
+	actor, err := s.channelRepo.GetMemberByUserAndChannel(ctx, channelID, currentOwnerID)
+	if err != nil || actor.Role != "owner" {
+		return ErrNotChannelOwner
+	}
+
+	ch, err := s.channelRepo.GetByID(ctx, channelID)
+	if err != nil {
+		return err
+	}
+	result, err := s.botClient.CheckAdmin(ctx, ch.Username, newOwnerTelegramID)
+	if err != nil {
+		return fmt.Errorf("failed to verify admin: %w", err)
+	}
+	if !result.IsAdmin {
+		return fmt.Errorf("user %d is not an admin of channel @%s", newOwnerTelegramID, ch.Username)
+	}
+
+	// Only a verified admin gets a user row: the ID comes straight from the request
+	newOwner, err := s.userRepo.UpsertByTelegramID(ctx, newOwnerTelegramID, nil, nil, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	if newOwner.ID == currentOwnerID {
+		return fmt.Errorf("you already own this channel")
+	}
+
+	// The previous owner stays as a manager, so a new member must still fit the limit
+	_, err = s.channelRepo.GetMemberByUserAndChannel(ctx, channelID, newOwner.ID)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		count, err := s.channelRepo.CountMembers(ctx, channelID)
+		if err != nil {
+			return err
+		}
+		if count >= 3 {
+			return fmt.Errorf("maximum 3 members (owner + 2 managers) allowed, remove a manager first")
+		}
+	}
+
+	if err := s.channelRepo.TransferOwnership(ctx, channelID, currentOwnerID, newOwner.ID, result.CanPostMessages); err != nil {
+		return err
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &currentOwnerID,
+		ActorType:   "user",
+		Action:      "channel_ownership_transferred",
+		EntityType:  "channel",
+		EntityID:    &channelID,
+		Meta:        map[string]any{"from_user_id": currentOwnerID.String(), "to_user_id": newOwner.ID.String()},
+	})
+
+	return nil
+}
+
 func (s *ChannelService) GetAdmins(ctx context.Context, channelID uuid.UUID) ([]AdminInfo, error) {
 	ch, err := s.channelRepo.GetByID(ctx, channelID)
 	if err != nil {
@@ -148,13 +422,65 @@ func (s *ChannelService) UpsertListing(ctx context.Context, channelID uuid.UUID,
 	// Check actor is owner or manager
 	_, err := s.channelRepo.GetMemberByUserAndChannel(ctx, channelID, actorID)
 	if err != nil {
-		return fmt.Errorf("user is not a member of this channel")
+		return ErrNotChannelMember
+	}
+
+	if err := checkListingLeadTimes(listing); err != nil {
+		return err
 	}
 
 	listing.ChannelID = channelID
 	return s.channelRepo.UpsertListing(ctx, listing)
 }
 
+// checkListingLeadTimes rejects negative lead times, the general one and the per-format
+// overrides alike.
+func checkListingLeadTimes(listing *models.ChannelListing) error {
+	if listing.MinLeadTimeMinutes < 0 {
+		return fmt.Errorf("min_lead_time_minutes must not be negative")
+	}
+	perFormat := []struct {
+		field string
+		v     *int
+	}{
+		{"min_lead_post_minutes", listing.MinLeadPostMinutes},
+		{"min_lead_repost_minutes", listing.MinLeadRepostMinutes},
+		{"min_lead_story_minutes", listing.MinLeadStoryMinutes},
+	}
+	for _, f := range perFormat {
+		if f.v != nil && *f.v < 0 {
+			return fmt.Errorf("%s must not be negative", f.field)
+		}
+	}
+	return nil
+}
+
+// SetListingStatus lets an owner or manager pause/resume a listing (e.g. while on vacation)
+// without touching its pricing. Paused and draft listings are hidden from search and explore.
+func (s *ChannelService) SetListingStatus(ctx context.Context, channelID, actorID uuid.UUID, status string) (*models.ChannelListing, error) {
+	if _, err := s.channelRepo.GetMemberByUserAndChannel(ctx, channelID, actorID); err != nil {
+		return nil, ErrNotChannelMember
+	}
+	if !models.IsValidListingStatus(status) {
+		return nil, ErrInvalidListingStatus
+	}
+
+	if err := s.channelRepo.SetListingStatus(ctx, channelID, status); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &actorID,
+		ActorType:   "user",
+		Action:      "listing_status_changed",
+		EntityType:  "channel",
+		EntityID:    &channelID,
+		Meta:        map[string]any{"status": status},
+	})
+
+	return s.channelRepo.GetListing(ctx, channelID)
+}
+
 func (s *ChannelService) GetListing(ctx context.Context, channelID uuid.UUID) (*models.ChannelListing, error) {
 	return s.channelRepo.GetListing(ctx, channelID)
 }
@@ -163,6 +489,86 @@ func (s *ChannelService) GetLatestStats(ctx context.Context, channelID uuid.UUID
 	return s.channelRepo.GetLatestStats(ctx, channelID)
 }
 
+// Stats history (chart) bounds: the window is clamped to 1..365 days and the series to
+// statsHistoryMaxPoints most recent snapshots.
+const (
+	statsHistoryMaxDays   = 365
+	statsHistoryMaxPoints = 1000
+)
+
+// RefreshStats has the stats fetcher refresh the channel right now, outside its schedule,
+// and returns the fresh stats. Owner only; once per STATS_FORCE_REFRESH_COOLDOWN_MINUTES per
+// channel, counted even when the fetch fails so it can't be used to hammer t.me.
+func (s *ChannelService) RefreshStats(ctx context.Context, channelID, actorID uuid.UUID) (*ChannelStatsResponse, error) {
+	member, err := s.channelRepo.GetMemberByUserAndChannel(ctx, channelID, actorID)
+	if err != nil || member.Role != "owner" {
+		return nil, ErrNotChannelOwner
+	}
+
+	cooldownKey := fmt.Sprintf("stats:force_refresh:%s", channelID)
+	ok, err := s.rdb.SetNX(ctx, cooldownKey, "1", s.cfg.StatsForceCooldown).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrStatsRefreshCooldown
+	}
+
+	res, err := statsparser.RequestRefresh(ctx, s.rdb, channelID, s.cfg.StatsForceTimeout)
+	if errors.Is(err, statsparser.ErrRefreshTimeout) {
+		return nil, ErrStatsRefreshTimeout
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !res.OK {
+		s.log.Warn("forced stats refresh failed", zap.String("channel_id", channelID.String()), zap.String("error", res.Error))
+		return nil, ErrStatsRefreshFailed
+	}
+
+	return s.GetChannelStats(ctx, channelID)
+}
+
+// GetStatsHistory returns the channel's snapshots over the last days days, oldest-first.
+func (s *ChannelService) GetStatsHistory(ctx context.Context, channelID uuid.UUID, days int) ([]models.StatsHistoryPoint, error) {
+	days = min(max(days, 1), statsHistoryMaxDays)
+	since := time.Now().AddDate(0, 0, -days)
+	return s.channelRepo.GetStatsHistory(ctx, channelID, since, statsHistoryMaxPoints)
+}
+
+// AuthorizeStatsExport allows the raw stats history only to the channel's owner/managers.
+func (s *ChannelService) AuthorizeStatsExport(ctx context.Context, channelID, userID uuid.UUID) error {
+	if _, err := s.channelRepo.GetMemberByUserAndChannel(ctx, channelID, userID); err != nil {
+		return fmt.Errorf("only channel members can export stats")
+	}
+	return nil
+}
+
+// WriteStatsHistoryCSV streams the snapshot rows in [from, to) as CSV, flushing as it goes.
+func (s *ChannelService) WriteStatsHistoryCSV(ctx context.Context, channelID uuid.UUID, from, to time.Time, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(models.StatsHistoryCSVHeader); err != nil {
+		return err
+	}
+
+	n := 0
+	err := s.channelRepo.EachStatsHistory(ctx, channelID, from, to, func(p models.StatsHistoryPoint) error {
+		if err := cw.Write(p.CSVRecord()); err != nil {
+			return err
+		}
+		if n++; n%500 == 0 {
+			cw.Flush()
+			return cw.Error()
+		}
+		return nil
+	})
+	cw.Flush()
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
 // ChannelStatsResponse is a frontend-friendly stats representation.
 type ChannelStatsResponse struct {
 	Subscribers                 *int     `json:"subscribers"`
@@ -179,6 +585,10 @@ type ChannelStatsResponse struct {
 	SharesPerPost               *float64 `json:"shares_per_post,omitempty"`
 	EnabledNotificationsPercent *float64 `json:"enabled_notifications_percent,omitempty"`
 	ERPercent                   *float64 `json:"er_percent,omitempty"`
+	PostFrequency               *float64 `json:"post_frequency,omitempty"`
+	HasPinnedPost               *bool    `json:"has_pinned_post,omitempty"`
+	ScamBadge                   bool     `json:"scam_badge"`
+	FakeBadge                   bool     `json:"fake_badge"`
 }
 
 func (s *ChannelService) GetChannelStats(ctx context.Context, channelID uuid.UUID) (*ChannelStatsResponse, error) {
@@ -203,6 +613,10 @@ func (s *ChannelService) GetChannelStats(ctx context.Context, channelID uuid.UUI
 		SharesPerPost:               stats.SharesPerPost,
 		EnabledNotificationsPercent: stats.EnabledNotificationsPercent,
 		ERPercent:                   stats.ERPercent,
+		PostFrequency:               stats.PostFrequency,
+		HasPinnedPost:               stats.HasPinnedPost,
+		ScamBadge:                   stats.ScamBadge,
+		FakeBadge:                   stats.FakeBadge,
 	}
 	return resp, nil
 }
@@ -216,8 +630,14 @@ type ExploreChannel struct {
 	Subscribers *int                   `json:"subscribers,omitempty"`
 	AvgViews    *int                   `json:"avg_views,omitempty"`
 	ERPercent   *float64               `json:"er_percent,omitempty"`
+	PostFreq    *float64               `json:"post_frequency,omitempty"`
 	Category    *string                `json:"category,omitempty"`
 	Language    *string                `json:"language,omitempty"`
+	TrustScore  *float64               `json:"trust_score,omitempty"` // 0..100, see models.TrustScore
+	PhotoURL    *string                `json:"photo_url,omitempty"`
+	ScamBadge   bool                   `json:"scam_badge"` // Telegram's scam/fake labels — warn before buying
+	FakeBadge   bool                   `json:"fake_badge"`
+	Rating      models.RatingSummary   `json:"rating"` // advertisers' ratings after completed deals
 	Listing     *ExploreChannelListing `json:"listing,omitempty"`
 }
 
@@ -227,9 +647,12 @@ type ExploreChannelListing struct {
 	PriceRepostTON *string `json:"price_repost_ton,omitempty"`
 	PriceStoryTON  *string `json:"price_story_ton,omitempty"`
 	Description    *string `json:"description,omitempty"`
+	RefundOnEdit   bool    `json:"refund_on_edit"` // edited post during the hold is refunded
 }
 
 func (s *ChannelService) ExploreChannels(ctx context.Context, f repositories.ChannelFilter) ([]ExploreChannel, error) {
+	ctx, cancel := s.searchContext(ctx)
+	defer cancel()
 	rows, err := s.channelRepo.SearchExplore(ctx, f)
 	if err != nil {
 		return nil, err
@@ -237,27 +660,131 @@ func (s *ChannelService) ExploreChannels(ctx context.Context, f repositories.Cha
 
 	result := make([]ExploreChannel, 0, len(rows))
 	for _, r := range rows {
-		ec := ExploreChannel{
-			ID:          r.ID,
-			Username:    r.Username,
-			Title:       r.Title,
-			BotStatus:   r.BotStatus,
-			Subscribers: r.Subscribers,
-			ERPercent:   r.ERPercent,
-			AvgViews:    r.AvgViews,
-			Category:    r.Category,
-			Language:    r.Language,
+		result = append(result, toExploreChannel(r))
+	}
+	return result, nil
+}
+
+func toExploreChannel(r repositories.ExploreChannelRow) ExploreChannel {
+	ec := ExploreChannel{
+		ID:          r.ID,
+		Username:    r.Username,
+		Title:       r.Title,
+		BotStatus:   r.BotStatus,
+		Subscribers: r.Subscribers,
+		ERPercent:   r.ERPercent,
+		AvgViews:    r.AvgViews,
+		PostFreq:    r.PostFrequency,
+		Category:    r.Category,
+		Language:    r.Language,
+		TrustScore:  r.TrustScore,
+		PhotoURL:    r.PhotoURL,
+		ScamBadge:   r.ScamBadge,
+		FakeBadge:   r.FakeBadge,
+		Rating:      models.RatingSummary{Average: r.RatingAvg, Count: r.RatingCount},
+	}
+	if r.ListingStatus != nil {
+		ec.Listing = &ExploreChannelListing{
+			Status:         *r.ListingStatus,
+			PricePostTON:   r.PricePostTON,
+			PriceRepostTON: r.PriceRepostTON,
+			PriceStoryTON:  r.PriceStoryTON,
+			Description:    r.Description,
+			RefundOnEdit:   r.RefundOnEdit,
+		}
+		// Owner hasn't written one — fall back to the channel's own Telegram description
+		if ec.Listing.Description == nil || strings.TrimSpace(*ec.Listing.Description) == "" {
+			ec.Listing.Description = r.ChannelDescription
 		}
-		if r.ListingStatus != nil {
-			ec.Listing = &ExploreChannelListing{
-				Status:         *r.ListingStatus,
-				PricePostTON:   r.PricePostTON,
-				PriceRepostTON: r.PriceRepostTON,
-				PriceStoryTON:  r.PriceStoryTON,
-				Description:    r.Description,
+	}
+	return ec
+}
+
+// MaxCompareChannels caps POST /explore/compare.
+const MaxCompareChannels = 5
+
+// CompareChannel is one column of the side-by-side comparison.
+type CompareChannel struct {
+	ExploreChannel
+	Stats *ChannelStatsResponse `json:"stats,omitempty"`
+	Terms []CompareFormatTerms  `json:"terms"`
+}
+
+// CompareFormatTerms is the price breakdown for one enabled ad format.
+type CompareFormatTerms struct {
+	AdFormat           string `json:"ad_format"`
+	PriceTON           string `json:"price_ton"`
+	PlatformFeeTON     string `json:"platform_fee_ton"`
+	OwnerNetTON        string `json:"owner_net_ton"`
+	HoldHours          int    `json:"hold_hours"`
+	MinLeadTimeMinutes int    `json:"min_lead_time_minutes"`
+}
+
+// CompareChannels returns up to MaxCompareChannels explore channels (deduped, in request
+// order) enriched with latest stats and per-format terms. Unknown or unlisted IDs are skipped.
+func (s *ChannelService) CompareChannels(ctx context.Context, ids []uuid.UUID) ([]CompareChannel, error) {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("channel_ids is required")
+	}
+	if len(unique) > MaxCompareChannels {
+		return nil, fmt.Errorf("can compare at most %d channels", MaxCompareChannels)
+	}
+
+	searchCtx, cancel := s.searchContext(ctx)
+	defer cancel()
+	rows, err := s.channelRepo.SearchExplore(searchCtx, repositories.ChannelFilter{IDs: unique, Limit: len(unique)})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]repositories.ExploreChannelRow, len(rows))
+	for _, r := range rows {
+		byID[r.ID] = r
+	}
+
+	result := make([]CompareChannel, 0, len(rows))
+	for _, id := range unique {
+		r, ok := byID[id]
+		if !ok {
+			continue
+		}
+		cc := CompareChannel{ExploreChannel: toExploreChannel(r), Terms: []CompareFormatTerms{}}
+
+		if stats, err := s.GetChannelStats(ctx, id); err == nil {
+			cc.Stats = stats
+		}
+
+		listing, err := s.channelRepo.GetListing(ctx, id)
+		if err == nil {
+			for _, format := range listing.FormatsEnabled {
+				price := listing.GetPriceForFormat(format)
+				if price == nil || *price == "" {
+					continue
+				}
+				net, fee, err := CalculateRelease(*price, s.cfg.PlatformFeeBPS)
+				if err != nil {
+					s.log.Warn("bad listing price", zap.String("channel_id", id.String()), zap.Error(err))
+					continue
+				}
+				cc.Terms = append(cc.Terms, CompareFormatTerms{
+					AdFormat:           format,
+					PriceTON:           *price,
+					PlatformFeeTON:     fee,
+					OwnerNetTON:        net,
+					HoldHours:          listing.GetHoldHoursForFormat(format),
+					MinLeadTimeMinutes: listing.GetMinLeadForFormat(format),
+				})
 			}
 		}
-		result = append(result, ec)
+
+		result = append(result, cc)
 	}
 	return result, nil
 }
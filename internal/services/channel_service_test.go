@@ -0,0 +1,68 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ads-marketplace/backend/internal/models"
+)
+
+func TestDuplicateJoinRole(t *testing.T) {
+	owner := models.ChannelMember{Role: "owner"}
+	manager := models.ChannelMember{Role: "manager"}
+
+	tests := []struct {
+		name            string
+		isTelegramOwner bool
+		members         []models.ChannelMember
+		role            string
+		ok              bool
+	}{
+		{"telegram owner takes an unowned channel", true, []models.ChannelMember{manager}, "owner", true},
+		{"telegram owner never becomes a second owner", true, []models.ChannelMember{owner}, "manager", true},
+		{"telegram owner joins a full channel", true, []models.ChannelMember{owner, manager, manager}, "manager", true},
+		{"admin joins as manager", false, []models.ChannelMember{owner}, "manager", true},
+		{"admin of an unowned channel stays a manager", false, nil, "manager", true},
+		{"channel is full", false, []models.ChannelMember{owner, manager, manager}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role, ok := duplicateJoinRole(tt.isTelegramOwner, tt.members)
+			if role != tt.role || ok != tt.ok {
+				t.Errorf("duplicateJoinRole() = %q, %v, want %q, %v", role, ok, tt.role, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCheckListingLeadTimes(t *testing.T) {
+	zero, positive, negative := 0, 30, -1
+
+	tests := []struct {
+		name    string
+		listing models.ChannelListing
+		wantMsg string // substring of the error, "" if valid
+	}{
+		{"defaults", models.ChannelListing{}, ""},
+		{"all set", models.ChannelListing{MinLeadTimeMinutes: 60, MinLeadPostMinutes: &zero, MinLeadRepostMinutes: &positive, MinLeadStoryMinutes: &positive}, ""},
+		{"negative general", models.ChannelListing{MinLeadTimeMinutes: -5}, "min_lead_time_minutes"},
+		{"negative post", models.ChannelListing{MinLeadPostMinutes: &negative}, "min_lead_post_minutes"},
+		{"negative repost", models.ChannelListing{MinLeadRepostMinutes: &negative}, "min_lead_repost_minutes"},
+		{"negative story", models.ChannelListing{MinLeadStoryMinutes: &negative}, "min_lead_story_minutes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkListingLeadTimes(&tt.listing)
+			if tt.wantMsg == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Fatalf("err = %v, want message containing %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
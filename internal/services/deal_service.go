@@ -3,26 +3,46 @@ package services
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/ads-marketplace/backend/internal/clock"
 	"github.com/ads-marketplace/backend/internal/config"
 	"github.com/ads-marketplace/backend/internal/events"
 	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/ads-marketplace/backend/internal/moderation"
 	"github.com/ads-marketplace/backend/internal/repositories"
+	"github.com/ads-marketplace/backend/internal/statsparser"
+	"github.com/ads-marketplace/backend/internal/ton"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+// releasedToBalance marks release_tx_hash for escrows credited to the owner's balance
+// rather than sent on-chain per deal.
+const releasedToBalance = "balance"
+
 type DealService struct {
 	dealRepo     *repositories.DealRepo
 	channelRepo  *repositories.ChannelRepo
+	campaignRepo *repositories.CampaignRepo
 	escrowRepo   *repositories.EscrowRepo
 	auditRepo    *repositories.AuditRepo
 	withdrawRepo *repositories.WithdrawRepo
 	walletRepo   *repositories.WalletRepo
+	ratingRepo   *repositories.RatingRepo
+	blockRepo    *repositories.BlockRepo
 	botClient    *BotClient
+	posts        statsparser.PostFetcher // t.me post pages, to read a manually posted ad
+	sender       TONSender               // hot wallet transfers; nil in the API
+	moderator    *moderation.Moderator
 	publisher    events.Publisher
+	clock        clock.Clock
 	cfg          *config.Config
 	log          *zap.Logger
 }
@@ -30,24 +50,38 @@ type DealService struct {
 func NewDealService(
 	dealRepo *repositories.DealRepo,
 	channelRepo *repositories.ChannelRepo,
+	campaignRepo *repositories.CampaignRepo,
 	escrowRepo *repositories.EscrowRepo,
 	auditRepo *repositories.AuditRepo,
 	withdrawRepo *repositories.WithdrawRepo,
 	walletRepo *repositories.WalletRepo,
+	ratingRepo *repositories.RatingRepo,
+	blockRepo *repositories.BlockRepo,
 	botClient *BotClient,
+	posts statsparser.PostFetcher,
+	sender TONSender,
+	moderator *moderation.Moderator,
 	publisher events.Publisher,
+	clk clock.Clock,
 	cfg *config.Config,
 	log *zap.Logger,
 ) *DealService {
 	return &DealService{
 		dealRepo:     dealRepo,
 		channelRepo:  channelRepo,
+		campaignRepo: campaignRepo,
 		escrowRepo:   escrowRepo,
 		auditRepo:    auditRepo,
 		withdrawRepo: withdrawRepo,
 		walletRepo:   walletRepo,
+		ratingRepo:   ratingRepo,
+		blockRepo:    blockRepo,
 		botClient:    botClient,
+		posts:        posts,
+		sender:       sender,
+		moderator:    moderator,
 		publisher:    publisher,
+		clock:        clk,
 		cfg:          cfg,
 		log:          log,
 	}
@@ -58,13 +92,29 @@ func (s *DealService) transition(ctx context.Context, deal *models.Deal, newStat
 	if !models.IsValidTransition(deal.Status, newStatus) {
 		return fmt.Errorf("invalid transition from %s to %s", deal.Status, newStatus)
 	}
+	return s.setStatus(ctx, deal, newStatus, actorID, actorType)
+}
 
+// setStatus moves the deal to newStatus without checking the transition rules, with the
+// usual audit entry and event. Only transition and admin overrides call it.
+func (s *DealService) setStatus(ctx context.Context, deal *models.Deal, newStatus string, actorID *uuid.UUID, actorType string) error {
 	oldStatus := deal.Status
-	if err := s.dealRepo.UpdateStatus(ctx, deal.ID, newStatus); err != nil {
+	err := s.dealRepo.UpdateStatusIf(ctx, deal.ID, oldStatus, newStatus)
+	if errors.Is(err, repositories.ErrStatusChanged) {
+		return ErrDealChanged
+	}
+	if err != nil {
 		return err
 	}
 	deal.Status = newStatus
 
+	// A dead deal's escrow must not take late payments: the indexer records them as unmatched
+	if newStatus == models.DealStatusCancelled || newStatus == models.DealStatusRefunded {
+		if err := s.escrowRepo.MarkExpired(ctx, deal.ID); err != nil {
+			s.log.Error("failed to expire escrow", zap.String("deal_id", deal.ID.String()), zap.Error(err))
+		}
+	}
+
 	// Audit log
 	_ = s.auditRepo.Log(ctx, models.AuditLog{
 		ActorUserID: actorID,
@@ -76,19 +126,16 @@ func (s *DealService) transition(ctx context.Context, deal *models.Deal, newStat
 	})
 
 	// Publish event
-	_ = s.publisher.Publish(ctx, "events:deal", events.Event{
-		Type: events.EventDealStatusChanged,
-		Payload: map[string]any{
-			"deal_id":    deal.ID.String(),
-			"old_status": oldStatus,
-			"new_status": newStatus,
-		},
-	})
+	_ = s.publisher.Publish(ctx, "events:deal", events.New(events.EventDealStatusChanged, events.DealStatusChangedPayload{
+		DealID:    deal.ID.String(),
+		OldStatus: oldStatus,
+		NewStatus: newStatus,
+	}))
 
 	return nil
 }
 
-func (s *DealService) CreateDeal(ctx context.Context, advertiserID, channelID uuid.UUID, adFormat string, brief *string, priceTON string, scheduledAt *time.Time) (*models.Deal, error) {
+func (s *DealService) CreateDeal(ctx context.Context, advertiserID, channelID uuid.UUID, adFormat string, brief *string, priceTON string, scheduledAt *time.Time, skipCreativeApproval bool, campaignID *uuid.UUID) (*models.Deal, error) {
 	// 1. Валидация формата
 	if !models.IsValidAdFormat(adFormat) {
 		return nil, fmt.Errorf("invalid ad format %q, must be one of: post, repost, story", adFormat)
@@ -100,9 +147,14 @@ func (s *DealService) CreateDeal(ctx context.Context, advertiserID, channelID uu
 		return nil, fmt.Errorf("channel listing not found: %w", err)
 	}
 
-	// 3. Проверяем, что формат включён в листинге
-	if !listing.IsFormatEnabled(adFormat) {
-		return nil, fmt.Errorf("ad format %q is not enabled for this channel (available: %v)", adFormat, listing.FormatsEnabled)
+	// 3. Листинг принимает сделки и формат в нём включён
+	if err := checkListingAcceptsDeal(listing, adFormat); err != nil {
+		return nil, err
+	}
+
+	// Рекламодатель и участник канала заблокировали друг друга — сделка невозможна
+	if err := s.checkNotBlocked(ctx, channelID, advertiserID); err != nil {
+		return nil, err
 	}
 
 	// 4. Если цена не указана — берём из листинга
@@ -120,6 +172,29 @@ func (s *DealService) CreateDeal(ctx context.Context, advertiserID, channelID uu
 		holdSeconds = s.cfg.HoldPeriodSeconds
 	}
 
+	// 6. Пропуск согласования креатива — только если владелец разрешил его в листинге
+	if skipCreativeApproval && !listing.AllowSkipCreativeApproval {
+		return nil, ErrSkipApprovalNotAllowed
+	}
+
+	// 7. Время публикации — не раньше lead time листинга, слот не пересекается с другими сделками канала
+	if scheduledAt != nil {
+		if err := checkLeadTime(listing, adFormat, *scheduledAt, s.clock.Now().UTC()); err != nil {
+			return nil, err
+		}
+		if err := s.checkSlotFree(ctx, channelID, *scheduledAt, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	// 8. Кампания — только своя и не в архиве
+	if campaignID != nil {
+		campaign, err := s.campaignRepo.GetByID(ctx, *campaignID)
+		if err != nil || campaign.AdvertiserUserID != advertiserID || campaign.Status == models.CampaignStatusArchived {
+			return nil, ErrCampaignNotFound
+		}
+	}
+
 	deal := &models.Deal{
 		ChannelID:         channelID,
 		AdvertiserUserID:  advertiserID,
@@ -128,8 +203,12 @@ func (s *DealService) CreateDeal(ctx context.Context, advertiserID, channelID uu
 		Brief:             brief,
 		ScheduledAt:       scheduledAt,
 		PriceTON:          priceTON,
+		Currency:          s.cfg.DefaultCurrency,
 		PlatformFeeBPS:    s.cfg.PlatformFeeBPS,
 		HoldPeriodSeconds: holdSeconds,
+
+		SkipCreativeApproval: skipCreativeApproval,
+		CampaignID:           campaignID,
 	}
 
 	if err := s.dealRepo.Create(ctx, deal); err != nil {
@@ -142,12 +221,20 @@ func (s *DealService) CreateDeal(ctx context.Context, advertiserID, channelID uu
 		Action:      "deal_created",
 		EntityType:  "deal",
 		EntityID:    &deal.ID,
-		Meta:        map[string]any{"ad_format": adFormat, "price_ton": priceTON},
+		Meta:        dealCreatedMeta(deal),
 	})
 
 	return deal, nil
 }
 
+func dealCreatedMeta(d *models.Deal) map[string]any {
+	meta := map[string]any{"ad_format": d.AdFormat, "price_ton": d.PriceTON, "skip_creative_approval": d.SkipCreativeApproval}
+	if d.CampaignID != nil {
+		meta["campaign_id"] = d.CampaignID.String()
+	}
+	return meta
+}
+
 func (s *DealService) SubmitDeal(ctx context.Context, dealID uuid.UUID, actorID uuid.UUID) error {
 	deal, err := s.dealRepo.GetByID(ctx, dealID)
 	if err != nil {
@@ -156,7 +243,40 @@ func (s *DealService) SubmitDeal(ctx context.Context, dealID uuid.UUID, actorID
 	if deal.AdvertiserUserID != actorID {
 		return fmt.Errorf("only advertiser can submit deal")
 	}
-	return s.transition(ctx, deal, models.DealStatusSubmitted, &actorID, "user")
+	// The block may have come after the draft was created; an auto-accept listing would
+	// accept the deal right away
+	if err := s.checkNotBlocked(ctx, deal.ChannelID, deal.AdvertiserUserID); err != nil {
+		return err
+	}
+
+	listing, err := s.channelRepo.GetListing(ctx, deal.ChannelID)
+	if err != nil {
+		return fmt.Errorf("channel listing not found: %w", err)
+	}
+
+	// Листинг с auto_accept: сделка сразу принимается системой и ждёт оплату
+	actor := &actorID
+	actorType := "user"
+	for _, status := range models.SubmitPath(listing.AutoAccept) {
+		if status == models.DealStatusAccepted {
+			actor, actorType = nil, "system"
+		}
+		if err := s.transition(ctx, deal, status, actor, actorType); err != nil {
+			return err
+		}
+	}
+	if deal.Status != models.DealStatusAccepted {
+		return nil
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorType:  "system",
+		Action:     "deal_auto_accepted",
+		EntityType: "deal",
+		EntityID:   &dealID,
+		Meta:       map[string]any{"channel_id": deal.ChannelID.String()},
+	})
+	return s.startPayment(ctx, deal, nil)
 }
 
 func (s *DealService) AcceptDeal(ctx context.Context, dealID uuid.UUID, actorID uuid.UUID) error {
@@ -167,20 +287,29 @@ func (s *DealService) AcceptDeal(ctx context.Context, dealID uuid.UUID, actorID
 	if err := s.checkChannelRole(ctx, deal.ChannelID, actorID, false); err != nil {
 		return err
 	}
+	// The block may have come after the deal was submitted
+	if err := s.checkNotBlocked(ctx, deal.ChannelID, deal.AdvertiserUserID); err != nil {
+		return err
+	}
 
 	if err := s.transition(ctx, deal, models.DealStatusAccepted, &actorID, "user"); err != nil {
 		return err
 	}
+	return s.startPayment(ctx, deal, &actorID)
+}
 
-	// Auto-transition to awaiting_payment + create escrow
-	if err := s.transition(ctx, deal, models.DealStatusAwaitingPayment, &actorID, "system"); err != nil {
+// startPayment moves an accepted deal to awaiting_payment and opens its escrow.
+// actorID is whoever accepted the deal, nil when it was accepted automatically.
+func (s *DealService) startPayment(ctx context.Context, deal *models.Deal, actorID *uuid.UUID) error {
+	if err := s.transition(ctx, deal, models.DealStatusAwaitingPayment, actorID, "system"); err != nil {
 		return err
 	}
 
-	memo := fmt.Sprintf("deal:%s", deal.ID.String())
+	memo := ton.DealMemo(deal.ID)
 	escrow := &models.EscrowLedger{
 		DealID:             deal.ID,
 		DepositExpectedTON: deal.PriceTON,
+		Currency:           s.cfg.DefaultCurrency,
 		DepositAddress:     s.cfg.TONHotWalletAddress,
 		DepositMemo:        memo,
 		Status:             models.EscrowStatusAwaiting,
@@ -188,6 +317,34 @@ func (s *DealService) AcceptDeal(ctx context.Context, dealID uuid.UUID, actorID
 	return s.escrowRepo.Create(ctx, escrow)
 }
 
+// CreateDealFromOffer instantiates a deal with the offer's terms. The owner agreed to them
+// when publishing the offer, so the deal goes straight to awaiting_payment. If that fails
+// part way the deal is cancelled, so only the offer (reopened by the caller) is left.
+func (s *DealService) CreateDealFromOffer(ctx context.Context, offer *models.DealOffer, advertiserID uuid.UUID) (*models.Deal, error) {
+	deal, err := s.CreateDeal(ctx, advertiserID, offer.ChannelID, offer.AdFormat, offer.Brief, offer.PriceTON, offer.ScheduledAt, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.acceptOfferDeal(ctx, deal, offer, advertiserID); err != nil {
+		if cancelErr := s.setStatus(ctx, deal, models.DealStatusCancelled, nil, "system"); cancelErr != nil {
+			s.log.Error("failed to cancel partial offer deal", zap.String("deal_id", deal.ID.String()), zap.Error(cancelErr))
+		}
+		return nil, err
+	}
+	return deal, nil
+}
+
+// acceptOfferDeal walks a new offer deal through submitted and accepted to awaiting_payment.
+func (s *DealService) acceptOfferDeal(ctx context.Context, deal *models.Deal, offer *models.DealOffer, advertiserID uuid.UUID) error {
+	if err := s.transition(ctx, deal, models.DealStatusSubmitted, &advertiserID, "user"); err != nil {
+		return err
+	}
+	if err := s.transition(ctx, deal, models.DealStatusAccepted, &offer.CreatedBy, "user"); err != nil {
+		return err
+	}
+	return s.startPayment(ctx, deal, &offer.CreatedBy)
+}
+
 func (s *DealService) RejectDeal(ctx context.Context, dealID uuid.UUID, actorID uuid.UUID) error {
 	deal, err := s.dealRepo.GetByID(ctx, dealID)
 	if err != nil {
@@ -199,6 +356,151 @@ func (s *DealService) RejectDeal(ctx context.Context, dealID uuid.UUID, actorID
 	return s.transition(ctx, deal, models.DealStatusRejected, &actorID, "user")
 }
 
+// Counteroffer lets the channel owner/manager propose a different price for a submitted deal.
+// The deal stays submitted; the advertiser accepts (AcceptCounteroffer), rejects, or cancels.
+// A new proposal supersedes the pending one.
+func (s *DealService) Counteroffer(ctx context.Context, dealID, actorID uuid.UUID, priceTON string) (*models.DealCounteroffer, error) {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkChannelRole(ctx, deal.ChannelID, actorID, false); err != nil {
+		return nil, err
+	}
+	if deal.Status != models.DealStatusSubmitted {
+		return nil, fmt.Errorf("counteroffers are only possible on submitted deals, deal is %s", deal.Status)
+	}
+	// The deal is paid in the default currency: the price must be a positive amount of it
+	price, err := ton.ParseUnits(priceTON, models.CurrencyDecimals(s.cfg.DefaultCurrency))
+	if err != nil || price.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid price_ton %q", priceTON)
+	}
+
+	offer := &models.DealCounteroffer{DealID: dealID, ProposedBy: actorID, PriceTON: priceTON}
+	if err := s.dealRepo.CreateCounteroffer(ctx, offer); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &actorID,
+		ActorType:   "user",
+		Action:      "counteroffer_proposed",
+		EntityType:  "deal",
+		EntityID:    &dealID,
+		Meta:        map[string]any{"counteroffer_id": offer.ID.String(), "price_ton": offer.PriceTON, "deal_price_ton": deal.PriceTON},
+	})
+	advertiser, _ := s.dealRepo.GetAdvertiserRecipient(ctx, dealID)
+	s.publishCounteroffer(ctx, offer, advertiser)
+	return offer, nil
+}
+
+// AcceptCounteroffer takes the pending counteroffer's price and accepts the deal at it.
+func (s *DealService) AcceptCounteroffer(ctx context.Context, dealID, advertiserID uuid.UUID) error {
+	deal, offer, err := s.pendingCounteroffer(ctx, dealID, advertiserID)
+	if err != nil {
+		return err
+	}
+	// The block may have come after the counteroffer was made
+	if err := s.checkNotBlocked(ctx, deal.ChannelID, deal.AdvertiserUserID); err != nil {
+		return err
+	}
+	err = s.dealRepo.AcceptCounteroffer(ctx, offer.ID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrNoPendingCounteroffer
+	}
+	if errors.Is(err, repositories.ErrStatusChanged) {
+		return ErrDealChanged
+	}
+	if err != nil {
+		return err
+	}
+	offer.Status = models.CounterofferStatusAccepted
+	deal.PriceTON = offer.PriceTON
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &advertiserID,
+		ActorType:   "user",
+		Action:      "counteroffer_accepted",
+		EntityType:  "deal",
+		EntityID:    &dealID,
+		Meta:        map[string]any{"counteroffer_id": offer.ID.String(), "price_ton": offer.PriceTON},
+	})
+	owner, _ := s.dealRepo.GetChannelOwnerRecipient(ctx, dealID)
+	s.publishCounteroffer(ctx, offer, owner)
+
+	if err := s.transition(ctx, deal, models.DealStatusAccepted, &advertiserID, "user"); err != nil {
+		return err
+	}
+	return s.startPayment(ctx, deal, &advertiserID)
+}
+
+// RejectCounteroffer declines the pending counteroffer. The deal stays submitted at the
+// advertiser's price: the channel may accept it, reject it or propose again.
+func (s *DealService) RejectCounteroffer(ctx context.Context, dealID, advertiserID uuid.UUID) error {
+	_, offer, err := s.pendingCounteroffer(ctx, dealID, advertiserID)
+	if err != nil {
+		return err
+	}
+	err = s.dealRepo.RejectCounteroffer(ctx, offer.ID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrNoPendingCounteroffer
+	}
+	if err != nil {
+		return err
+	}
+	offer.Status = models.CounterofferStatusRejected
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &advertiserID,
+		ActorType:   "user",
+		Action:      "counteroffer_rejected",
+		EntityType:  "deal",
+		EntityID:    &dealID,
+		Meta:        map[string]any{"counteroffer_id": offer.ID.String(), "price_ton": offer.PriceTON},
+	})
+	owner, _ := s.dealRepo.GetChannelOwnerRecipient(ctx, dealID)
+	s.publishCounteroffer(ctx, offer, owner)
+	return nil
+}
+
+func (s *DealService) ListCounteroffers(ctx context.Context, dealID uuid.UUID) ([]models.DealCounteroffer, error) {
+	return s.dealRepo.ListCounteroffers(ctx, dealID)
+}
+
+// pendingCounteroffer loads a submitted deal of the advertiser and its pending counteroffer.
+func (s *DealService) pendingCounteroffer(ctx context.Context, dealID, advertiserID uuid.UUID) (*models.Deal, *models.DealCounteroffer, error) {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if deal.AdvertiserUserID != advertiserID {
+		return nil, nil, fmt.Errorf("only advertiser can answer a counteroffer")
+	}
+	if deal.Status != models.DealStatusSubmitted {
+		return nil, nil, ErrNoPendingCounteroffer
+	}
+	offer, err := s.dealRepo.GetPendingCounteroffer(ctx, dealID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, nil, ErrNoPendingCounteroffer
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return deal, offer, nil
+}
+
+func (s *DealService) publishCounteroffer(ctx context.Context, offer *models.DealCounteroffer, notify models.Recipient) {
+	_ = s.publisher.Publish(ctx, "events:deal", events.NewCounterofferEvent(events.Counteroffer{
+		DealID:           offer.DealID.String(),
+		CounterofferID:   offer.ID.String(),
+		PriceTON:         offer.PriceTON,
+		Currency:         s.cfg.DefaultCurrency,
+		Status:           offer.Status,
+		NotifyTelegramID: notify.NotifyTelegramID(),
+		NotifyLanguage:   notify.LanguageCode,
+	}))
+}
+
 func (s *DealService) CancelDeal(ctx context.Context, dealID uuid.UUID, actorID uuid.UUID) error {
 	deal, err := s.dealRepo.GetByID(ctx, dealID)
 	if err != nil {
@@ -217,7 +519,32 @@ type SubmitCreativeInput struct {
 	Text          string
 	RepostFromURL *string
 	MediaURLs     []string
-	ButtonsJSON   any
+	Buttons       []models.CreativeButton
+}
+
+// validateCreativeInput checks the creative's media and buttons: within the limits and
+// only http(s) links. The error names the offending field.
+func validateCreativeInput(input SubmitCreativeInput) error {
+	if len(input.MediaURLs) > models.MaxCreativeMedia {
+		return ErrTooManyMedia.WithField("media_urls")
+	}
+	for i, u := range input.MediaURLs {
+		if !models.ValidLinkURL(u) {
+			return ErrInvalidMediaURL.WithField(fmt.Sprintf("media_urls[%d]", i))
+		}
+	}
+	if len(input.Buttons) > models.MaxCreativeButtons {
+		return ErrTooManyButtons.WithField("buttons_json")
+	}
+	for i, b := range input.Buttons {
+		if strings.TrimSpace(b.Text) == "" {
+			return ErrInvalidButton.WithField(fmt.Sprintf("buttons_json[%d].text", i))
+		}
+		if !models.ValidLinkURL(b.URL) {
+			return ErrInvalidButton.WithField(fmt.Sprintf("buttons_json[%d].url", i))
+		}
+	}
+	return nil
 }
 
 func (s *DealService) SubmitCreative(ctx context.Context, dealID uuid.UUID, actorID uuid.UUID, input SubmitCreativeInput) error {
@@ -231,7 +558,10 @@ func (s *DealService) SubmitCreative(ctx context.Context, dealID uuid.UUID, acto
 
 	// Валидация формат-специфичных данных
 	if deal.AdFormat == models.AdFormatRepost && (input.RepostFromURL == nil || *input.RepostFromURL == "") {
-		return fmt.Errorf("repost format requires repost_from_url")
+		return ErrRepostURLRequired
+	}
+	if err := validateCreativeInput(input); err != nil {
+		return err
 	}
 
 	// Must be in creative_pending or creative_changes_requested
@@ -246,6 +576,20 @@ func (s *DealService) SubmitCreative(ctx context.Context, dealID uuid.UUID, acto
 		}
 	}
 
+	// Модерация: блок-лист ключевых слов/доменов + опциональный внешний webhook
+	verdict := s.moderator.Check(ctx, creativeModerationContent(input))
+	if verdict.Decision == moderation.DecisionReject {
+		_ = s.auditRepo.Log(ctx, models.AuditLog{
+			ActorUserID: &actorID,
+			ActorType:   "system",
+			Action:      "creative_rejected_by_moderation",
+			EntityType:  "deal",
+			EntityID:    &deal.ID,
+			Meta:        map[string]any{"reason": verdict.Reason},
+		})
+		return fmt.Errorf("creative rejected by moderation: %s", verdict.Reason)
+	}
+
 	maxV, _ := s.dealRepo.GetCreativeMaxVersion(ctx, dealID)
 	creative := &models.DealCreative{
 		DealID:            dealID,
@@ -254,17 +598,116 @@ func (s *DealService) SubmitCreative(ctx context.Context, dealID uuid.UUID, acto
 		RepostFromURL:     input.RepostFromURL,
 		Status:            "submitted",
 	}
+	if verdict.Decision == moderation.DecisionReview {
+		creative.Status = "pending_review"
+		creative.ModerationReason = &verdict.Reason
+	}
 	if input.MediaURLs != nil {
 		creative.MediaURLs = input.MediaURLs
 	}
-	if input.ButtonsJSON != nil {
-		creative.ButtonsJSON = input.ButtonsJSON
+	if input.Buttons != nil {
+		creative.ButtonsJSON = input.Buttons
 	}
 	if err := s.dealRepo.CreateCreative(ctx, creative); err != nil {
 		return err
 	}
 
-	return s.transition(ctx, deal, models.DealStatusCreativeSubmitted, &actorID, "user")
+	// Flagged creative waits in the admin review queue; the deal stays where it is
+	if creative.Status == "pending_review" {
+		_ = s.auditRepo.Log(ctx, models.AuditLog{
+			ActorUserID: &actorID,
+			ActorType:   "system",
+			Action:      "creative_flagged_for_review",
+			EntityType:  "deal",
+			EntityID:    &deal.ID,
+			Meta:        map[string]any{"creative_id": creative.ID.String(), "reason": verdict.Reason},
+		})
+		return nil
+	}
+
+	return s.advanceSubmittedCreative(ctx, deal, creative.ID, &actorID, "user")
+}
+
+// advanceSubmittedCreative moves the deal to creative_submitted and, when both parties opted
+// out of approval, auto-approves the creative on the advertiser's behalf.
+func (s *DealService) advanceSubmittedCreative(ctx context.Context, deal *models.Deal, creativeID uuid.UUID, actorID *uuid.UUID, actorType string) error {
+	for _, status := range models.CreativeSubmitPath(deal.SkipCreativeApproval) {
+		if status == models.DealStatusCreativeApproved {
+			if err := s.dealRepo.UpdateCreativeStatus(ctx, creativeID, "approved"); err != nil {
+				return err
+			}
+			actorID, actorType = nil, "system"
+		}
+		if err := s.transition(ctx, deal, status, actorID, actorType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveCreativeReview lets an admin clear a flagged creative (it then goes to the
+// advertiser as a normal submission) or reject it (the owner has to submit a new version).
+func (s *DealService) ResolveCreativeReview(ctx context.Context, creativeID uuid.UUID, adminID uuid.UUID, approve bool, reason *string) error {
+	creative, err := s.dealRepo.GetCreativeByID(ctx, creativeID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrCreativeNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if creative.Status != "pending_review" {
+		return ErrCreativeNotPending
+	}
+	if latest, err := s.dealRepo.GetLatestCreative(ctx, creative.DealID); err == nil && latest.ID != creative.ID {
+		return fmt.Errorf("creative was superseded by version %d", latest.Version)
+	}
+	deal, err := s.dealRepo.GetByID(ctx, creative.DealID)
+	if err != nil {
+		return err
+	}
+
+	action := "creative_review_rejected"
+	if approve {
+		action = "creative_review_approved"
+	}
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &adminID,
+		ActorType:   "admin",
+		Action:      action,
+		EntityType:  "deal",
+		EntityID:    &deal.ID,
+		Meta:        map[string]any{"creative_id": creativeID.String(), "reason": reason},
+	})
+
+	if !approve {
+		if reason == nil {
+			reason = creative.ModerationReason
+		}
+		return s.dealRepo.UpdateCreativeModeration(ctx, creativeID, "rejected", reason)
+	}
+
+	if err := s.dealRepo.UpdateCreativeModeration(ctx, creativeID, "submitted", creative.ModerationReason); err != nil {
+		return err
+	}
+	return s.advanceSubmittedCreative(ctx, deal, creativeID, &adminID, "admin")
+}
+
+func (s *DealService) ListCreativesForReview(ctx context.Context, limit, offset int) ([]models.DealCreative, error) {
+	return s.dealRepo.ListCreativesByStatus(ctx, "pending_review", limit, offset)
+}
+
+// creativeModerationContent flattens a creative (text, button labels/links, media, repost source) for moderation.
+func creativeModerationContent(input SubmitCreativeInput) moderation.Content {
+	c := moderation.Content{Text: input.Text}
+	c.URLs = append(c.URLs, input.MediaURLs...)
+	if input.RepostFromURL != nil {
+		c.URLs = append(c.URLs, *input.RepostFromURL)
+	}
+	for _, b := range input.Buttons {
+		c.Text += "\n" + b.Text
+		c.URLs = append(c.URLs, b.URL)
+	}
+	return c
 }
 
 func (s *DealService) ApproveCreative(ctx context.Context, dealID uuid.UUID, actorID uuid.UUID) error {
@@ -330,15 +773,48 @@ func (s *DealService) MarkManualPost(ctx context.Context, dealID uuid.UUID, acto
 		return fmt.Errorf("deal must be creative_approved or scheduled to mark post")
 	}
 
-	// Create content hash from URL (placeholder)
-	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(postURL)))
-
-	now := time.Now()
+	now := s.clock.Now()
 	post := &models.DealPost{
-		DealID:      dealID,
-		PostURL:     &postURL,
-		ContentHash: &hash,
-		PostedAt:    &now,
+		DealID:   dealID,
+		PostURL:  &postURL,
+		PostedAt: &now,
+	}
+	var postedText *string
+	if deal.AdFormat != models.AdFormatStory {
+		// The monitor diffs the post page by message id against the hash of what was posted
+		username, messageID, ok := models.ParsePostURL(postURL)
+		if !ok {
+			return fmt.Errorf("post link must look like https://t.me/<channel>/<id>")
+		}
+		channel, err := s.channelRepo.GetByID(ctx, deal.ChannelID)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(username, channel.Username) {
+			return ErrPostChannelMismatch
+		}
+		text, exists, err := s.posts.FetchPostContent(ctx, username, messageID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch the post: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("post %s not found", postURL)
+		}
+		hash := fmt.Sprintf("%x", sha256.Sum256([]byte(text)))
+		post.TelegramMessageID = &messageID
+		post.TelegramChatID = channel.TelegramChatID
+		post.ContentHash = &hash
+		postedText = &text
+	} else {
+		// Стори живёт 24ч: воркер проверяет её через userbot по id до истечения
+		storyID, ok := models.ParseStoryURL(postURL)
+		if !ok {
+			return fmt.Errorf("story link must look like https://t.me/<channel>/s/<id>")
+		}
+		expiresAt := now.Add(models.StoryLifetime)
+		post.AdFormat = &deal.AdFormat
+		post.StoryID = &storyID
+		post.StoryExpiresAt = &expiresAt
 	}
 	if err := s.dealRepo.UpsertPost(ctx, post); err != nil {
 		return err
@@ -347,92 +823,1379 @@ func (s *DealService) MarkManualPost(ctx context.Context, dealID uuid.UUID, acto
 	if err := s.transition(ctx, deal, models.DealStatusPosted, &actorID, "user"); err != nil {
 		return err
 	}
+
+	// Опубликовано не то, что одобрил рекламодатель: вместо холда — спор для админа
+	if deal.AdFormat == models.AdFormatPost && postedText != nil {
+		if similarity, ok := s.contentMismatch(ctx, dealID, *postedText); ok {
+			if err := s.transition(ctx, deal, models.DealStatusDisputed, nil, "system"); err != nil {
+				return err
+			}
+			_ = s.auditRepo.Log(ctx, models.AuditLog{
+				ActorUserID: &actorID,
+				ActorType:   "system",
+				Action:      "post_content_mismatch",
+				EntityType:  "deal",
+				EntityID:    &dealID,
+				Meta:        map[string]any{"post_url": postURL, "similarity": similarity},
+			})
+			s.log.Warn("posted content doesn't match the approved creative",
+				zap.String("deal_id", dealID.String()),
+				zap.Float64("similarity", similarity),
+			)
+			return nil
+		}
+	}
 	return s.transition(ctx, deal, models.DealStatusHoldVerification, &actorID, "system")
 }
 
-func (s *DealService) SetWithdrawWallet(ctx context.Context, dealID uuid.UUID, actorID uuid.UUID, walletAddress string) error {
+// contentMismatch compares posted text with the approved creative and reports its similarity
+// and whether it is below ContentMatchThreshold. Without an approved text there is nothing to
+// compare against and the post isn't flagged.
+func (s *DealService) contentMismatch(ctx context.Context, dealID uuid.UUID, posted string) (float64, bool) {
+	approved, err := s.approvedPostText(ctx, dealID)
+	if err != nil {
+		s.log.Warn("no approved creative to match the post against", zap.String("deal_id", dealID.String()), zap.Error(err))
+		return 0, false
+	}
+	similarity := statsparser.TextSimilarity(approved, posted)
+	return similarity, similarity < s.cfg.ContentMatchThreshold
+}
+
+// SchedulePost hands a creative_approved deal to the bot: the deal moves to scheduled and
+// the worker posts the approved creative at scheduled_at (PublishScheduledPost). Only
+// regular posts can be published by the bot; reposts and stories are marked manually.
+// The bot sends the approved text as is, so there is no content match to check.
+func (s *DealService) SchedulePost(ctx context.Context, dealID, actorID uuid.UUID) error {
 	deal, err := s.dealRepo.GetByID(ctx, dealID)
 	if err != nil {
 		return err
 	}
-
-	// Must be owner — re-check admin via bot
-	if err := s.recheckOwnerAdmin(ctx, deal.ChannelID, actorID); err != nil {
+	if err := s.checkChannelRole(ctx, deal.ChannelID, actorID, false); err != nil {
 		return err
 	}
-
-	// Проверяем, что у пользователя есть верифицированный кошелёк и адрес совпадает
-	userWallet, err := s.walletRepo.GetActiveWallet(ctx, actorID)
+	if deal.Status != models.DealStatusCreativeApproved {
+		return fmt.Errorf("deal must be creative_approved to schedule the post, deal is %s", deal.Status)
+	}
+	if deal.AdFormat != models.AdFormatPost {
+		return fmt.Errorf("the bot can only publish posts; mark the %s manually", deal.AdFormat)
+	}
+	if deal.ScheduledAt == nil {
+		return fmt.Errorf("deal has no scheduled_at; reschedule it first")
+	}
+	channel, err := s.channelRepo.GetByID(ctx, deal.ChannelID)
 	if err != nil {
-		return fmt.Errorf("no verified wallet connected — connect your wallet via TON Connect first")
+		return err
 	}
-	if !userWallet.Verified {
-		return fmt.Errorf("connected wallet is not verified")
+	if channel.TelegramChatID == nil {
+		return fmt.Errorf("the bot is not in the channel, it can't publish the post")
 	}
-	// Адрес для вывода должен совпадать с подключённым (или быть тем же)
-	if walletAddress != userWallet.Address && walletAddress != userWallet.AddressFriendly {
-		return fmt.Errorf("withdraw address must match your connected verified wallet (%s)", userWallet.AddressFriendly)
+	if _, err := s.approvedPostText(ctx, dealID); err != nil {
+		return err
 	}
 
-	wallet := &models.WithdrawWallet{
-		ChannelID:     deal.ChannelID,
-		OwnerUserID:   actorID,
-		WalletAddress: userWallet.AddressFriendly,
-	}
-	return s.withdrawRepo.Upsert(ctx, wallet)
+	return s.transition(ctx, deal, models.DealStatusScheduled, &actorID, "user")
 }
 
-func (s *DealService) ReleaseFunds(ctx context.Context, dealID uuid.UUID) error {
+// PublishScheduledPost posts a due scheduled deal through the bot, stores the message and
+// moves the deal to hold verification. On a bot failure the deal stays scheduled and the
+// attempt is recorded for the worker's backoff.
+func (s *DealService) PublishScheduledPost(ctx context.Context, dealID uuid.UUID) error {
 	deal, err := s.dealRepo.GetByID(ctx, dealID)
 	if err != nil {
 		return err
 	}
-	if deal.Status != models.DealStatusHoldVerification {
-		return fmt.Errorf("deal is not in hold_verification")
+	if deal.Status != models.DealStatusScheduled {
+		return nil // posted manually or cancelled since the query
 	}
-
-	// Check post not deleted
-	post, err := s.dealRepo.GetPost(ctx, dealID)
+	channel, err := s.channelRepo.GetByID(ctx, deal.ChannelID)
 	if err != nil {
 		return err
 	}
-	if post.IsDeleted {
-		return s.transition(ctx, deal, models.DealStatusHoldVerificationFailed, nil, "system")
+	text, err := s.approvedPostText(ctx, dealID)
+	if err == nil && channel.TelegramChatID == nil {
+		err = fmt.Errorf("channel has no telegram chat id")
+	}
+	var result *PostResult
+	if err == nil {
+		result, err = s.botClient.PostToDeal(ctx, PostRequest{
+			DealID:          dealID.String(),
+			ChatID:          *channel.TelegramChatID,
+			Text:            text,
+			ChannelUsername: channel.Username,
+		})
+	}
+	if err != nil {
+		attempts, recErr := s.dealRepo.RecordPostAttempt(ctx, dealID, s.clock.Now(), err.Error())
+		if recErr != nil {
+			return recErr
+		}
+		s.log.Warn("bot post failed, will retry",
+			zap.String("deal_id", dealID.String()),
+			zap.Int("attempts", attempts),
+			zap.Duration("retry_in", models.ReleaseRetryDelay(attempts, s.cfg.PostRetryBase, s.cfg.PostRetryMax)),
+			zap.Error(err),
+		)
+		return nil
 	}
 
-	// Calculate release amount
-	feeBPS := deal.PlatformFeeBPS
-	// This is a simplified calculation — in production use big decimal
-	_ = feeBPS
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(text)))
+	now := s.clock.Now()
+	post := &models.DealPost{
+		DealID:            dealID,
+		TelegramMessageID: &result.MessageID,
+		TelegramChatID:    channel.TelegramChatID,
+		ContentHash:       &hash,
+		PostedAt:          &now,
+	}
+	if result.PostURL != "" {
+		post.PostURL = &result.PostURL
+	}
+	if err := s.dealRepo.UpsertPost(ctx, post); err != nil {
+		return err
+	}
 
-	if err := s.transition(ctx, deal, models.DealStatusCompleted, nil, "system"); err != nil {
+	if err := s.transition(ctx, deal, models.DealStatusPosted, nil, "system"); err != nil {
 		return err
 	}
+	return s.transition(ctx, deal, models.DealStatusHoldVerification, nil, "system")
+}
 
-	// Mark escrow released (tx_hash will be filled by actual TON send)
-	return s.escrowRepo.MarkReleased(ctx, dealID, deal.PriceTON, "pending_send")
+// approvedPostText is the text of the deal's approved creative: the owner's composition,
+// or the advertiser's materials as sent.
+func (s *DealService) approvedPostText(ctx context.Context, dealID uuid.UUID) (string, error) {
+	creative, err := s.dealRepo.GetLatestCreative(ctx, dealID)
+	if err != nil {
+		return "", fmt.Errorf("deal has no creative: %w", err)
+	}
+	if creative.Status != "approved" {
+		return "", fmt.Errorf("latest creative is %s, not approved", creative.Status)
+	}
+	for _, t := range []*string{creative.OwnerComposedText, creative.AdvertiserMaterialsText} {
+		if t != nil && strings.TrimSpace(*t) != "" {
+			return *t, nil
+		}
+	}
+	return "", fmt.Errorf("approved creative has no text to post")
 }
 
-func (s *DealService) RefundDeal(ctx context.Context, dealID uuid.UUID) error {
+func (s *DealService) SetWithdrawWallet(ctx context.Context, dealID uuid.UUID, actorID uuid.UUID, walletAddress string) error {
 	deal, err := s.dealRepo.GetByID(ctx, dealID)
 	if err != nil {
 		return err
 	}
-	return s.transition(ctx, deal, models.DealStatusRefunded, nil, "system")
-}
 
-func (s *DealService) GetDeal(ctx context.Context, id uuid.UUID) (*models.DealWithChannel, error) {
-	return s.dealRepo.GetByIDWithChannel(ctx, id)
+	// Must be owner — re-check admin via bot
+	if err := s.recheckOwnerAdmin(ctx, deal.ChannelID, actorID); err != nil {
+		return err
+	}
+
+	// Адрес для вывода должен быть одним из подключённых кошельков пользователя и верифицирован
+	wallets, err := s.walletRepo.ListWallets(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if len(wallets) == 0 {
+		return ErrWalletNotConnected
+	}
+	// Клиент может прислать адрес в любой форме — сравниваем по raw
+	if raw, err := ton.NormalizeAddress(walletAddress); err == nil {
+		walletAddress = raw
+	}
+	userWallet := models.FindWallet(wallets, walletAddress)
+	if userWallet == nil {
+		return ErrWithdrawWalletNotOwned
+	}
+	if !userWallet.Verified {
+		return ErrWalletNotVerified
+	}
+
+	wallet := &models.WithdrawWallet{
+		ChannelID:     deal.ChannelID,
+		OwnerUserID:   actorID,
+		WalletAddress: userWallet.AddressFriendly,
+	}
+	return s.withdrawRepo.Upsert(ctx, wallet)
+}
+
+func (s *DealService) ReleaseFunds(ctx context.Context, dealID uuid.UUID) error {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if deal.Status != models.DealStatusHoldVerification {
+		return ErrDealNotInHold
+	}
+	if !deal.AutoReleasable() {
+		return ErrPayoutFrozen
+	}
+
+	// Check post not deleted
+	post, err := s.dealRepo.GetPost(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if post.IsDeleted {
+		return s.transition(ctx, deal, models.DealStatusHoldVerificationFailed, nil, "system")
+	}
+
+	// Credit first: if it fails the deal stays in hold_verification and the next run retries
+	if err := s.releaseToBalance(ctx, deal); err != nil {
+		return err
+	}
+	return s.transition(ctx, deal, models.DealStatusCompleted, nil, "system")
+}
+
+// RecordReleaseFailure counts a failed automatic release. Until RELEASE_MAX_ATTEMPTS the
+// hold-release job retries with backoff; then the deal moves to release_failed, waiting
+// for an admin to force-release or force-refund it.
+func (s *DealService) RecordReleaseFailure(ctx context.Context, dealID uuid.UUID, cause error) error {
+	attempts, err := s.escrowRepo.RecordReleaseAttempt(ctx, dealID, s.clock.Now())
+	if err != nil {
+		return err
+	}
+	if attempts < s.cfg.ReleaseMaxAttempts {
+		s.log.Warn("fund release failed, will retry",
+			zap.String("deal_id", dealID.String()),
+			zap.Int("attempts", attempts),
+			zap.Duration("retry_in", models.ReleaseRetryDelay(attempts, s.cfg.ReleaseRetryBase, s.cfg.ReleaseRetryMax)),
+			zap.Error(cause),
+		)
+		return nil
+	}
+
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if err := s.transition(ctx, deal, models.DealStatusReleaseFailed, nil, "system"); err != nil {
+		return err
+	}
+	s.log.Error("fund release failed permanently, needs an admin",
+		zap.String("deal_id", dealID.String()),
+		zap.Int("attempts", attempts),
+		zap.Error(cause),
+	)
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorType:  "system",
+		Action:     "release_failed",
+		EntityType: "deal",
+		EntityID:   &dealID,
+		Meta:       map[string]any{"attempts": attempts, "error": cause.Error()},
+	})
+	_ = s.publisher.Publish(ctx, "events:deal", events.New(events.EventReleaseFailed, events.ReleaseFailedPayload{
+		DealID:   dealID.String(),
+		Attempts: attempts,
+		Error:    cause.Error(),
+	}))
+	return nil
+}
+
+// releaseToBalance credits the channel owner's balance in the escrow's currency with the deal
+// price minus the platform fee and marks the escrow released, only while the escrow holds the
+// funds. Safe to repeat: an escrow already released to the balance is left as is. The payout itself is sent on withdrawal (EarningsService.ProcessWithdrawals),
+// once the balance exceeds the currency's minimum payout.
+func (s *DealService) releaseToBalance(ctx context.Context, deal *models.Deal) error {
+	escrow, err := s.escrowRepo.GetByDealID(ctx, deal.ID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrEscrowNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if escrow.Status == models.EscrowStatusReleased && escrow.ReleaseTxHash != nil && *escrow.ReleaseTxHash == releasedToBalance {
+		return nil // credited by an earlier run that failed before completing the deal
+	}
+	if escrow.Status != models.EscrowStatusFunded || escrow.RefundStatus != nil {
+		return repositories.ErrStatusChanged
+	}
+	if models.CurrencyDecimals(escrow.Currency) < 0 {
+		return fmt.Errorf("escrow currency %q cannot be credited", escrow.Currency)
+	}
+	net, _, err := CalculateRelease(deal.PriceTON, deal.PlatformFeeBPS)
+	if err != nil {
+		return err
+	}
+
+	members, err := s.channelRepo.GetMembers(ctx, deal.ChannelID)
+	if err != nil {
+		return err
+	}
+	var ownerID *uuid.UUID
+	for _, m := range members {
+		if m.Role == "owner" {
+			ownerID = &m.UserID
+			break
+		}
+	}
+	if ownerID == nil {
+		return fmt.Errorf("channel has no owner to credit")
+	}
+
+	if err := s.escrowRepo.MarkReleased(ctx, deal.ID, *ownerID, net, releasedToBalance); err != nil {
+		return fmt.Errorf("credit owner balance: %w", err)
+	}
+	return nil
+}
+
+// FailHoldVerification ends the hold of a deal whose post broke the terms (deleted, or
+// edited on a refund_on_edit listing): hold_verification_failed, then refunded.
+func (s *DealService) FailHoldVerification(ctx context.Context, dealID uuid.UUID, reason string) error {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if deal.Status != models.DealStatusHoldVerification {
+		return ErrDealNotInHold
+	}
+
+	escrow, err := s.queueEscrowRefund(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if err := s.transition(ctx, deal, models.DealStatusHoldVerificationFailed, nil, "system"); err != nil {
+		return err
+	}
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorType:  "system",
+		Action:     "hold_verification_failed",
+		EntityType: "deal",
+		EntityID:   &dealID,
+		Meta:       map[string]any{"reason": reason},
+	})
+	if err := s.transition(ctx, deal, models.DealStatusRefunded, nil, "system"); err != nil {
+		return err
+	}
+	s.sendEscrowRefund(ctx, escrow)
+	return nil
+}
+
+// RefundDeal moves the deal to refunded and returns a funded escrow to the payer; an escrow
+// that was never funded just expires. Safe to retry: an already refunded deal only gets its
+// escrow refund (re)queued, which never sends twice.
+func (s *DealService) RefundDeal(ctx context.Context, dealID uuid.UUID) error {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	escrow, err := s.queueEscrowRefund(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if deal.Status != models.DealStatusRefunded {
+		if err := s.transition(ctx, deal, models.DealStatusRefunded, nil, "system"); err != nil {
+			return err
+		}
+	}
+	s.sendEscrowRefund(ctx, escrow)
+	return nil
+}
+
+// RefundEscrow sends a queued escrow refund to the payer (or the admin-approved refund
+// address) from the hot wallet. Idempotent: the refund is claimed before the transfer is
+// signed, so a retry never refunds twice; a refund that is not queued, or whose deal is not
+// refunded yet, is a no-op.
+func (s *DealService) RefundEscrow(ctx context.Context, dealID uuid.UUID) error {
+	if s.sender == nil {
+		return fmt.Errorf("hot wallet sender is not configured")
+	}
+	escrow, err := s.escrowRepo.GetByDealID(ctx, dealID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrEscrowNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if escrow.Status != models.EscrowStatusFunded || escrow.RefundStatus == nil || *escrow.RefundStatus != models.EscrowRefundPending {
+		return nil
+	}
+	if escrow.Currency != models.EscrowCurrencyTON {
+		return fmt.Errorf("%s refunds are sent by support", escrow.Currency)
+	}
+	to := escrow.RefundDestination()
+	if to == "" {
+		return fmt.Errorf("escrow has no payer address to refund")
+	}
+	amount, err := ton.ParseUnits(escrow.DepositExpectedTON, models.CurrencyDecimals(escrow.Currency))
+	if err != nil {
+		return fmt.Errorf("invalid escrow amount: %w", err)
+	}
+	comment, err := ton.TransferComment(s.cfg.TONSendComment, "refund", "deal", dealID.String())
+	if err != nil {
+		return err
+	}
+
+	claimed, err := s.escrowRepo.ClaimRefund(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	meta := map[string]any{
+		"amount":     escrow.DepositExpectedTON,
+		"to":         to,
+		"tx_comment": comment,
+	}
+	txHash, err := s.sender.SendTON(ctx, to, amount, comment)
+	if err != nil {
+		if err := s.escrowRepo.MarkRefundFailed(ctx, dealID); err != nil {
+			s.log.Error("failed to mark escrow refund failed", zap.String("deal_id", dealID.String()), zap.Error(err))
+		}
+		meta["error"] = err.Error()
+		_ = s.auditRepo.Log(ctx, models.AuditLog{
+			ActorType:  "system",
+			Action:     "escrow_refund_failed",
+			EntityType: "deal",
+			EntityID:   &dealID,
+			Meta:       meta,
+		})
+		return fmt.Errorf("refund escrow: %w", err)
+	}
+
+	if err := s.escrowRepo.MarkRefunded(ctx, dealID, txHash); err != nil {
+		s.log.Error("escrow refund sent but not recorded",
+			zap.String("deal_id", dealID.String()),
+			zap.String("tx_hash", txHash),
+			zap.Error(err),
+		)
+	}
+	meta["tx_hash"] = txHash
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorType:  "system",
+		Action:     "escrow_refunded",
+		EntityType: "deal",
+		EntityID:   &dealID,
+		Meta:       meta,
+	})
+
+	advertiser, err := s.dealRepo.GetAdvertiserRecipient(ctx, dealID)
+	if err != nil {
+		s.log.Warn("failed to resolve advertiser for refund notification", zap.String("deal_id", dealID.String()), zap.Error(err))
+	}
+	_ = s.publisher.Publish(ctx, "events:deal", events.NewRefundedEvent(events.Refunded{
+		DealID:               dealID.String(),
+		AdvertiserTelegramID: advertiser.NotifyTelegramID(),
+		Amount:               escrow.DepositExpectedTON,
+		Currency:             escrow.Currency,
+		To:                   to,
+		TxHash:               txHash,
+		Language:             advertiser.LanguageCode,
+	}))
+	s.log.Info("escrow refunded",
+		zap.String("deal_id", dealID.String()),
+		zap.String("amount", escrow.DepositExpectedTON),
+		zap.String("tx_hash", txHash),
+	)
+	return nil
+}
+
+// RefundOverpayment returns the excess of a TON funding payment to the payer address
+// captured when the escrow was funded. Idempotent: the refund is claimed under the funding
+// tx LT before the transfer is signed, so a retry never refunds the same overpayment twice.
+func (s *DealService) RefundOverpayment(ctx context.Context, dealID uuid.UUID) error {
+	if s.sender == nil {
+		return fmt.Errorf("hot wallet sender is not configured")
+	}
+	escrow, err := s.escrowRepo.GetByDealID(ctx, dealID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrEscrowNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if escrow.OverpaidNano == nil {
+		return nil
+	}
+	if escrow.Currency != models.EscrowCurrencyTON {
+		return fmt.Errorf("%s overpayments are returned by support", escrow.Currency)
+	}
+	if escrow.PayerAddress == nil || escrow.FundingTxHash == nil {
+		return fmt.Errorf("escrow has no payer address to refund")
+	}
+	// The indexer records the funding tx by its LT
+	fundingLT, err := strconv.ParseUint(*escrow.FundingTxHash, 10, 64)
+	if err != nil {
+		return fmt.Errorf("funding tx %q was not matched by the indexer", *escrow.FundingTxHash)
+	}
+	amount, ok := new(big.Int).SetString(*escrow.OverpaidNano, 10)
+	if !ok || amount.Sign() <= 0 {
+		return fmt.Errorf("invalid overpaid amount: %s", *escrow.OverpaidNano)
+	}
+	comment, err := ton.TransferComment(s.cfg.TONSendComment, "refund", "deal", dealID.String())
+	if err != nil {
+		return err
+	}
+
+	claimed, err := s.escrowRepo.ClaimOverpaymentRefund(ctx, fundingLT, dealID, amount.String(), *escrow.PayerAddress, comment)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	meta := map[string]any{
+		"funding_lt":  fundingLT,
+		"amount_nano": amount.String(),
+		"to":          *escrow.PayerAddress,
+		"tx_comment":  comment,
+	}
+	txHash, err := s.sender.SendTON(ctx, *escrow.PayerAddress, amount, comment)
+	if err != nil {
+		if err := s.escrowRepo.MarkOverpaymentRefundFailed(ctx, fundingLT); err != nil {
+			s.log.Error("failed to mark overpayment refund failed", zap.String("deal_id", dealID.String()), zap.Error(err))
+		}
+		meta["error"] = err.Error()
+		_ = s.auditRepo.Log(ctx, models.AuditLog{
+			ActorType:  "system",
+			Action:     "overpayment_refund_failed",
+			EntityType: "deal",
+			EntityID:   &dealID,
+			Meta:       meta,
+		})
+		return fmt.Errorf("refund overpayment: %w", err)
+	}
+
+	if err := s.escrowRepo.MarkOverpaymentRefundSent(ctx, fundingLT, txHash); err != nil {
+		s.log.Error("overpayment refund sent but not recorded",
+			zap.String("deal_id", dealID.String()),
+			zap.String("tx_hash", txHash),
+			zap.Error(err),
+		)
+	}
+	meta["tx_hash"] = txHash
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorType:  "system",
+		Action:     "overpayment_refunded",
+		EntityType: "deal",
+		EntityID:   &dealID,
+		Meta:       meta,
+	})
+	s.log.Info("overpayment refunded",
+		zap.String("deal_id", dealID.String()),
+		zap.String("amount_nano", amount.String()),
+		zap.String("tx_hash", txHash),
+	)
+	return nil
+}
+
+// --- admin operations ---
+
+// ForceRelease completes a posted deal and releases escrow without waiting for the hold period.
+func (s *DealService) ForceRelease(ctx context.Context, dealID uuid.UUID, adminID uuid.UUID) error {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if err := checkForceRelease(deal.Status); err != nil {
+		return err
+	}
+	if deal.Status == models.DealStatusPosted {
+		if err := s.transition(ctx, deal, models.DealStatusHoldVerification, &adminID, "admin"); err != nil {
+			return err
+		}
+	}
+	if err := s.releaseToBalance(ctx, deal); err != nil {
+		return err
+	}
+	return s.transition(ctx, deal, models.DealStatusCompleted, &adminID, "admin")
+}
+
+// SetPayoutFrozen freezes (or unfreezes) a deal's automatic release pending investigation.
+// A frozen deal stays in hold_verification; ForceRelease still works for an admin.
+func (s *DealService) SetPayoutFrozen(ctx context.Context, dealID uuid.UUID, adminID uuid.UUID, frozen bool, reason string) error {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if frozen && deal.Status == models.DealStatusCompleted {
+		return fmt.Errorf("deal is already completed, funds were released")
+	}
+	if deal.PayoutFrozen == frozen {
+		return nil
+	}
+	if err := s.dealRepo.SetPayoutFrozen(ctx, dealID, frozen); err != nil {
+		return err
+	}
+
+	action := "payout_unfrozen"
+	if frozen {
+		action = "payout_frozen"
+	}
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &adminID,
+		ActorType:   "admin",
+		Action:      action,
+		EntityType:  "deal",
+		EntityID:    &dealID,
+		Meta:        map[string]any{"reason": reason, "status": deal.Status},
+	})
+	s.log.Warn("deal "+action,
+		zap.String("deal_id", dealID.String()),
+		zap.String("admin_id", adminID.String()),
+		zap.String("reason", reason),
+	)
+	return nil
+}
+
+// ForceRefund cancels (if still possible) and refunds a deal regardless of timeouts.
+func (s *DealService) ForceRefund(ctx context.Context, dealID uuid.UUID, adminID uuid.UUID) error {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if err := checkForceRefund(deal.Status); err != nil {
+		return err
+	}
+	escrow, err := s.queueEscrowRefund(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if !models.IsValidTransition(deal.Status, models.DealStatusRefunded) &&
+		models.IsValidTransition(deal.Status, models.DealStatusCancelled) {
+		if err := s.transition(ctx, deal, models.DealStatusCancelled, &adminID, "admin"); err != nil {
+			return err
+		}
+	}
+	// Wedged mid-flow (creative stage, posted, hold): the admin refunds regardless of the rules
+	if err := s.setStatus(ctx, deal, models.DealStatusRefunded, &adminID, "admin"); err != nil {
+		return err
+	}
+	s.sendEscrowRefund(ctx, escrow)
+	return nil
+}
+
+// ForceStatus sets a deal status, bypassing the transition rules, for support to unwedge
+// a stuck deal. No money moves, so completed and refunded are refused: they settle the
+// escrow, which only ForceRelease/ForceRefund do. A paid status needs a funded escrow, or
+// the hold worker would release money the platform doesn't hold. The caller audits the
+// override with its reason.
+func (s *DealService) ForceStatus(ctx context.Context, dealID uuid.UUID, adminID uuid.UUID, status string) error {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	funded, err := s.escrowHeld(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if err := checkForceStatus(deal.Status, status, funded); err != nil {
+		return err
+	}
+	return s.setStatus(ctx, deal, status, &adminID, "admin")
+}
+
+// escrowHeld reports whether the deal's escrow holds the funds: funded, with no refund queued.
+func (s *DealService) escrowHeld(ctx context.Context, dealID uuid.UUID) (bool, error) {
+	escrow, err := s.escrowRepo.GetByDealID(ctx, dealID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return escrow.Status == models.EscrowStatusFunded && escrow.RefundStatus == nil, nil
+}
+
+// checkForceStatus reports why ForceStatus can't move a deal from current to target;
+// escrowFunded tells whether the deal's escrow still holds the funds.
+func checkForceStatus(current, target string, escrowFunded bool) error {
+	if !models.IsKnownDealStatus(target) {
+		return fmt.Errorf("unknown deal status %q", target)
+	}
+	switch target {
+	case models.DealStatusCompleted:
+		return fmt.Errorf("cannot force status %s: use force-release, which credits the owner", target)
+	case models.DealStatusRefunded:
+		return fmt.Errorf("cannot force status %s: use force-refund, which returns the escrow", target)
+	}
+	if current == models.DealStatusCompleted || current == models.DealStatusRefunded {
+		return fmt.Errorf("cannot force the status of a %s deal: its escrow is settled", current)
+	}
+	if current == target {
+		return fmt.Errorf("deal is already %s", target)
+	}
+	if slices.Contains(models.DealStatusesPaid, target) && !escrowFunded {
+		return fmt.Errorf("cannot force status %s: the deal's escrow is not funded", target)
+	}
+	return nil
+}
+
+// checkForceRelease reports why ForceRelease can't complete a deal in status. A posted deal
+// goes through hold_verification first.
+func checkForceRelease(status string) error {
+	if status == models.DealStatusPosted {
+		return nil
+	}
+	if !models.IsValidTransition(status, models.DealStatusCompleted) {
+		return fmt.Errorf("invalid transition from %s to %s", status, models.DealStatusCompleted)
+	}
+	return nil
+}
+
+// checkForceRefund reports why ForceRefund can't refund a deal in status.
+func checkForceRefund(status string) error {
+	switch status {
+	case models.DealStatusCompleted, models.DealStatusRefunded:
+		// Completed: the owner was already paid; refunding too would pay the deal out twice
+		return fmt.Errorf("deal in status %s cannot be refunded", status)
+	}
+	return nil
+}
+
+// CheckAdminAction reports why an admin action can't run on the deal as it is now, so a
+// request that could never execute is refused up front instead of waiting for approval.
+// The action itself checks again when it runs.
+func (s *DealService) CheckAdminAction(ctx context.Context, dealID uuid.UUID, action string, params map[string]any) error {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	funded, err := s.escrowHeld(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	return checkAdminAction(deal.Status, funded, action, params)
+}
+
+// checkAdminAction applies the preconditions of the admin deal actions to a deal in status;
+// escrowFunded tells whether its escrow still holds the funds.
+func checkAdminAction(status string, escrowFunded bool, action string, params map[string]any) error {
+	switch action {
+	case models.AdminActionForceRelease:
+		return checkForceRelease(status)
+	case models.AdminActionForceRefund:
+		return checkForceRefund(status)
+	case models.AdminActionManualEscrowMatch:
+		txHash, _ := params["tx_hash"].(string)
+		payer, _ := params["payer_address"].(string)
+		if txHash == "" || payer == "" {
+			return fmt.Errorf("tx_hash and payer_address are required")
+		}
+		if !models.IsValidTransition(status, models.DealStatusFunded) {
+			return fmt.Errorf("invalid transition from %s to %s", status, models.DealStatusFunded)
+		}
+		return nil
+	case models.AdminActionResolveDispute:
+		outcome, _ := params["outcome"].(string)
+		if outcome != models.DisputeOutcomeRelease && outcome != models.DisputeOutcomeRefund {
+			return fmt.Errorf("invalid outcome %q, must be release or refund", outcome)
+		}
+		if status != models.DealStatusDisputed {
+			return ErrDealNotDisputed
+		}
+		return nil
+	case models.AdminActionForceStatus:
+		target, _ := params["status"].(string)
+		reason, _ := params["reason"].(string)
+		if target == "" || reason == "" {
+			return fmt.Errorf("status and reason are required")
+		}
+		return checkForceStatus(status, target, escrowFunded)
+	default:
+		return fmt.Errorf("unknown admin action %q", action)
+	}
+}
+
+// queueEscrowRefund queues the refund of a funded escrow to the payer. Callers queue before
+// moving the deal to refunded, so a deal never ends up refunded with its escrow left behind;
+// the refund is only claimed once the deal is refunded. Returns a nil escrow if it was never
+// funded: there is nothing to return.
+func (s *DealService) queueEscrowRefund(ctx context.Context, dealID uuid.UUID) (*models.EscrowLedger, error) {
+	escrow, err := s.escrowRepo.GetByDealID(ctx, dealID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get escrow: %w", err)
+	}
+	if escrow.Status != models.EscrowStatusFunded {
+		return nil, nil
+	}
+	if err := s.escrowRepo.QueueRefund(ctx, dealID); err != nil {
+		return nil, fmt.Errorf("queue escrow refund: %w", err)
+	}
+	s.log.Info("escrow refund queued",
+		zap.String("deal_id", dealID.String()),
+		zap.String("to", escrow.RefundDestination()),
+	)
+	return escrow, nil
+}
+
+// sendEscrowRefund sends a refund queued by queueEscrowRefund right away where the hot wallet
+// is available (worker); otherwise the worker sends it.
+func (s *DealService) sendEscrowRefund(ctx context.Context, escrow *models.EscrowLedger) {
+	if escrow == nil || s.sender == nil || escrow.Currency != models.EscrowCurrencyTON {
+		return
+	}
+	// The deal is already refunded: a failed send stays with the escrow for support
+	if err := s.RefundEscrow(ctx, escrow.DealID); err != nil {
+		s.log.Error("failed to send escrow refund", zap.String("deal_id", escrow.DealID.String()), zap.Error(err))
+	}
+}
+
+// checkListingAcceptsDeal rejects a deal for a listing that isn't live or doesn't offer the
+// format. Draft and paused listings are hidden from search but still reachable by a direct link.
+func checkListingAcceptsDeal(listing *models.ChannelListing, format string) error {
+	if !listing.AcceptsDeals() {
+		return ErrListingNotActive
+	}
+	if !listing.IsFormatEnabled(format) {
+		return fmt.Errorf("ad format %q is not enabled for this channel (available: %v)", format, listing.FormatsEnabled)
+	}
+	return nil
+}
+
+// checkLeadTime rejects a posting time in the past or sooner than the listing's lead time
+// for the format, naming the lead time and the earliest acceptable time.
+func checkLeadTime(listing *models.ChannelListing, format string, scheduledAt, now time.Time) error {
+	if !scheduledAt.After(now) {
+		return ErrScheduledAtInPast
+	}
+	if earliest := listing.EarliestScheduleAt(format, now); scheduledAt.Before(earliest) {
+		return fmt.Errorf("channel requires at least %d minutes lead time for %s, earliest is %s",
+			listing.GetMinLeadForFormat(format), format, earliest.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// RescheduleDeal moves the posting time of a deal that isn't posted yet. The new time must
+// respect the listing's minimum lead time for the deal's format and a free slot.
+func (s *DealService) RescheduleDeal(ctx context.Context, dealID, actorID uuid.UUID, scheduledAt time.Time) error {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if deal.AdvertiserUserID != actorID {
+		return fmt.Errorf("only advertiser can reschedule the deal")
+	}
+	if deal.Status != models.DealStatusCreativeApproved && deal.Status != models.DealStatusScheduled {
+		return fmt.Errorf("deal in status %s cannot be rescheduled", deal.Status)
+	}
+
+	listing, err := s.channelRepo.GetListing(ctx, deal.ChannelID)
+	if err != nil {
+		return fmt.Errorf("channel listing not found: %w", err)
+	}
+	if err := checkLeadTime(listing, deal.AdFormat, scheduledAt, s.clock.Now().UTC()); err != nil {
+		return err
+	}
+	if err := s.checkSlotFree(ctx, deal.ChannelID, scheduledAt, &deal.ID); err != nil {
+		return err
+	}
+
+	previous := deal.ScheduledAt
+	deal.ScheduledAt = &scheduledAt
+	if err := s.dealRepo.UpdateScheduledAt(ctx, deal.ID, deal); err != nil {
+		return err
+	}
+
+	meta := map[string]any{"scheduled_at": scheduledAt.UTC().Format(time.RFC3339)}
+	if previous != nil {
+		meta["previous_scheduled_at"] = previous.UTC().Format(time.RFC3339)
+	}
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &actorID,
+		ActorType:   "user",
+		Action:      "deal_rescheduled",
+		EntityType:  "deal",
+		EntityID:    &dealID,
+		Meta:        meta,
+	})
+	return nil
+}
+
+// OpenDispute lets the advertiser contest a live post (e.g. it doesn't match the approved
+// creative). The deal leaves hold_verification, so the hold-release job no longer pays it out;
+// an admin settles it with ResolveDispute.
+func (s *DealService) OpenDispute(ctx context.Context, dealID, actorID uuid.UUID, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return fmt.Errorf("reason is required")
+	}
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if deal.AdvertiserUserID != actorID {
+		return fmt.Errorf("only advertiser can open a dispute")
+	}
+	if !models.IsValidTransition(deal.Status, models.DealStatusDisputed) {
+		return ErrDealNotDisputable
+	}
+
+	if err := s.transition(ctx, deal, models.DealStatusDisputed, &actorID, "user"); err != nil {
+		return err
+	}
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &actorID,
+		ActorType:   "user",
+		Action:      "dispute_opened",
+		EntityType:  "deal",
+		EntityID:    &dealID,
+		Meta:        map[string]any{"reason": reason},
+	})
+	s.log.Warn("deal disputed", zap.String("deal_id", dealID.String()), zap.String("reason", reason))
+	return nil
+}
+
+// ResolveDispute settles a disputed deal: "release" credits the channel owner and completes it,
+// "refund" refunds the advertiser.
+func (s *DealService) ResolveDispute(ctx context.Context, dealID, adminID uuid.UUID, outcome string) error {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if deal.Status != models.DealStatusDisputed {
+		return ErrDealNotDisputed
+	}
+
+	switch outcome {
+	case models.DisputeOutcomeRelease:
+		// Credit first: if it fails the deal stays disputed and the admin can retry
+		if err := s.releaseToBalance(ctx, deal); err != nil {
+			return err
+		}
+		if err := s.transition(ctx, deal, models.DealStatusCompleted, &adminID, "admin"); err != nil {
+			return err
+		}
+	case models.DisputeOutcomeRefund:
+		// Queue first: if it fails the deal stays disputed and the admin can retry
+		escrow, err := s.queueEscrowRefund(ctx, dealID)
+		if err != nil {
+			return err
+		}
+		if err := s.transition(ctx, deal, models.DealStatusRefunded, &adminID, "admin"); err != nil {
+			return err
+		}
+		s.sendEscrowRefund(ctx, escrow)
+	default:
+		return fmt.Errorf("invalid outcome %q, must be release or refund", outcome)
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &adminID,
+		ActorType:   "admin",
+		Action:      "dispute_resolved",
+		EntityType:  "deal",
+		EntityID:    &dealID,
+		Meta:        map[string]any{"outcome": outcome},
+	})
+	return nil
+}
+
+// ManualEscrowMatch marks a deal funded from a payment the indexer could not match (e.g. wrong memo).
+func (s *DealService) ManualEscrowMatch(ctx context.Context, dealID uuid.UUID, adminID uuid.UUID, txHash, payerAddress string) error {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if !models.IsValidTransition(deal.Status, models.DealStatusFunded) {
+		return fmt.Errorf("invalid transition from %s to %s", deal.Status, models.DealStatusFunded)
+	}
+	escrow, err := s.escrowRepo.GetByDealID(ctx, dealID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrEscrowNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if escrow.Status != models.EscrowStatusAwaiting {
+		return fmt.Errorf("escrow is not awaiting payment: %s", escrow.Status)
+	}
+	if err := s.escrowRepo.MarkFunded(ctx, dealID, txHash, payerAddress); err != nil {
+		return err
+	}
+	// Crediting a payment from the unmatched list closes it there
+	if err := s.escrowRepo.MarkUnmatchedPaymentMatched(ctx, txHash, dealID, adminID); err != nil {
+		s.log.Warn("failed to close unmatched payment", zap.String("tx", txHash), zap.Error(err))
+	}
+	return s.transition(ctx, deal, models.DealStatusFunded, &adminID, "admin")
+}
+
+// ReconcileUnmatchedPayments retries matching payments the indexer could not attribute to an
+// escrow, e.g. sent right before the deal was accepted. A payment is credited only when its memo
+// now names an awaiting escrow of an awaiting_payment deal and currency and amount fit; anything
+// else stays in the list for staff. Returns the number of deals funded.
+func (s *DealService) ReconcileUnmatchedPayments(ctx context.Context, limit int) (int, error) {
+	payments, err := s.escrowRepo.FindUnmatchedPayments(ctx, models.UnmatchedPaymentStatusUnmatched, limit, 0)
+	if err != nil {
+		return 0, err
+	}
+	funded := 0
+	for i := range payments {
+		p := &payments[i]
+		escrow, err := s.escrowRepo.GetByMemo(ctx, p.Memo)
+		if errors.Is(err, repositories.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return funded, err
+		}
+		overpaid, ok := UnmatchedPaymentExcess(escrow, p)
+		if !ok {
+			continue
+		}
+		deal, err := s.dealRepo.GetByID(ctx, escrow.DealID)
+		if err != nil {
+			return funded, err
+		}
+		if deal.Status != models.DealStatusAwaitingPayment {
+			continue
+		}
+		err = s.escrowRepo.FundFromUnmatchedPayment(ctx, p.ID, deal.ID, overpaid)
+		if errors.Is(err, repositories.ErrNotFound) {
+			continue // funded or matched concurrently
+		}
+		if err != nil {
+			return funded, err
+		}
+		if err := s.transition(ctx, deal, models.DealStatusFunded, nil, "system"); err != nil {
+			return funded, err
+		}
+		s.log.Info("unmatched payment reconciled",
+			zap.String("deal_id", deal.ID.String()),
+			zap.Int64("lt", p.TxLT),
+			zap.String("amount", p.Amount),
+			zap.String("currency", p.Currency),
+		)
+		funded++
+	}
+	return funded, nil
+}
+
+// UnmatchedPaymentExcess reports whether payment can fund the awaiting escrow: same currency
+// and at least the expected amount. excess is the overpaid part in smallest units, "" if none.
+// Shared with the indexer, which retries pending payments the same way.
+func UnmatchedPaymentExcess(escrow *models.EscrowLedger, p *models.UnmatchedPayment) (excess string, ok bool) {
+	if escrow.Status != models.EscrowStatusAwaiting || p.Currency != escrow.Currency {
+		return "", false
+	}
+	expected, err := ton.ParseUnits(escrow.DepositExpectedTON, models.CurrencyDecimals(escrow.Currency))
+	if err != nil {
+		return "", false
+	}
+	amount, isInt := new(big.Int).SetString(p.Amount, 10)
+	if !isInt || amount.Cmp(expected) < 0 {
+		return "", false
+	}
+	if diff := new(big.Int).Sub(amount, expected); diff.Sign() > 0 {
+		return diff.String(), true
+	}
+	return "", true
+}
+
+// RequestRefundAddress asks to refund a funded deal to the advertiser's connected wallet instead
+// of the payer address. Ownership is proven by TON Proof at connect time; an admin must approve.
+func (s *DealService) RequestRefundAddress(ctx context.Context, dealID uuid.UUID, actorID uuid.UUID) (*models.RefundAddressRequest, error) {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return nil, err
+	}
+	if deal.AdvertiserUserID != actorID {
+		return nil, fmt.Errorf("only advertiser can request a refund address")
+	}
+
+	escrow, err := s.escrowRepo.GetByDealID(ctx, dealID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrEscrowNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if escrow.Status != models.EscrowStatusFunded {
+		return nil, ErrEscrowNotFunded
+	}
+
+	userWallet, err := s.walletRepo.GetActiveWallet(ctx, actorID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrWalletNotConnected
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !userWallet.Verified {
+		return nil, ErrWalletNotVerified
+	}
+	if userWallet.Network != s.cfg.TONNetwork {
+		return nil, fmt.Errorf("connected wallet is on %s, expected %s", userWallet.Network, s.cfg.TONNetwork)
+	}
+	if escrow.PayerAddress != nil && ton.SameAddress(*escrow.PayerAddress, userWallet.Address) {
+		return nil, fmt.Errorf("connected wallet is already the payer address")
+	}
+
+	req := &models.RefundAddressRequest{
+		DealID:      dealID,
+		RequestedBy: actorID,
+		WalletID:    userWallet.ID,
+		Address:     userWallet.AddressFriendly,
+		Status:      models.RefundAddressStatusPending,
+	}
+	if err := s.escrowRepo.CreateRefundAddressRequest(ctx, req); err != nil {
+		return nil, ErrRefundRequestPending
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &actorID,
+		ActorType:   "user",
+		Action:      "refund_address_requested",
+		EntityType:  "deal",
+		EntityID:    &dealID,
+		Meta: map[string]any{
+			"request_id":    req.ID.String(),
+			"address":       req.Address,
+			"payer_address": escrow.PayerAddress,
+		},
+	})
+
+	return req, nil
+}
+
+// ResolveRefundAddressRequest approves or rejects a refund address request. On approval the
+// escrow refund destination is overridden; the requester's wallet must still be the one proven.
+func (s *DealService) ResolveRefundAddressRequest(ctx context.Context, requestID uuid.UUID, adminID uuid.UUID, approve bool, reason *string) (*models.RefundAddressRequest, error) {
+	req, err := s.escrowRepo.GetRefundAddressRequest(ctx, requestID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrRefundRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != models.RefundAddressStatusPending {
+		return nil, fmt.Errorf("refund address request is not pending")
+	}
+	if req.RequestedBy == adminID {
+		return nil, fmt.Errorf("refund address request must be reviewed by someone other than the requester")
+	}
+
+	escrow, err := s.escrowRepo.GetByDealID(ctx, req.DealID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrEscrowNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status := models.RefundAddressStatusRejected
+	if approve {
+		if escrow.Status != models.EscrowStatusFunded {
+			return nil, ErrEscrowNotFunded
+		}
+		// Кошелёк мог быть отключён или заменён после подачи заявки
+		userWallet, err := s.walletRepo.GetActiveWallet(ctx, req.RequestedBy)
+		if err != nil || userWallet.ID != req.WalletID || !userWallet.Verified {
+			return nil, fmt.Errorf("requester's verified wallet has changed — a new request is required")
+		}
+		status = models.RefundAddressStatusApproved
+	}
+
+	err = s.escrowRepo.ResolveRefundAddressRequest(ctx, requestID, adminID, status, reason)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, fmt.Errorf("refund address request is no longer pending")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if approve {
+		err := s.escrowRepo.SetRefundAddress(ctx, req.DealID, req.Address)
+		if errors.Is(err, repositories.ErrStatusChanged) {
+			// Released or refunded since the check above: the approval stored no address
+			return nil, ErrEscrowNotFunded
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &adminID,
+		ActorType:   "admin",
+		Action:      "refund_address_" + status,
+		EntityType:  "deal",
+		EntityID:    &req.DealID,
+		Meta: map[string]any{
+			"request_id":    requestID.String(),
+			"requested_by":  req.RequestedBy.String(),
+			"address":       req.Address,
+			"payer_address": escrow.PayerAddress,
+			"reason":        reason,
+		},
+	})
+
+	return s.escrowRepo.GetRefundAddressRequest(ctx, requestID)
+}
+
+func (s *DealService) ListRefundAddressRequests(ctx context.Context, limit, offset int) ([]models.RefundAddressRequest, error) {
+	return s.escrowRepo.ListRefundAddressRequests(ctx, models.RefundAddressStatusPending, limit, offset)
+}
+
+func (s *DealService) ListUnmatchedPayments(ctx context.Context, status string, limit, offset int) ([]models.UnmatchedPayment, error) {
+	return s.escrowRepo.FindUnmatchedPayments(ctx, status, limit, offset)
+}
+
+// DealParticipants returns the advertiser and the channel members of a deal — the users
+// who may see its WebSocket events.
+func (s *DealService) DealParticipants(ctx context.Context, dealID uuid.UUID) ([]uuid.UUID, error) {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.channelRepo.GetMembers(ctx, deal.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+
+	users := []uuid.UUID{deal.AdvertiserUserID}
+	for _, m := range members {
+		if m.UserID != deal.AdvertiserUserID {
+			users = append(users, m.UserID)
+		}
+	}
+	return users, nil
+}
+
+func (s *DealService) GetDeal(ctx context.Context, id uuid.UUID) (*models.DealWithChannel, error) {
+	deal, err := s.dealRepo.GetByIDWithChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	deal.Currency = s.cfg.DefaultCurrency
+	return deal, nil
 }
 
-func (s *DealService) ListDeals(ctx context.Context, f repositories.DealFilter) ([]models.DealWithChannel, error) {
-	return s.dealRepo.ListWithChannel(ctx, f)
+func (s *DealService) ListDeals(ctx context.Context, f repositories.DealFilter) (*repositories.Page[models.DealWithChannel], error) {
+	page, err := s.dealRepo.ListWithChannel(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Items {
+		page.Items[i].Currency = s.cfg.DefaultCurrency
+	}
+	return page, nil
+}
+
+// MaxDealStatusBatch caps POST /deals/status.
+const MaxDealStatusBatch = 100
+
+// GetDealStatuses returns {deal_id: status} for the deals the user is a party to.
+func (s *DealService) GetDealStatuses(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("deal_ids is required")
+	}
+	if len(ids) > MaxDealStatusBatch {
+		return nil, fmt.Errorf("at most %d deal ids per request", MaxDealStatusBatch)
+	}
+	return s.dealRepo.GetStatusesForUser(ctx, userID, ids)
 }
 
 func (s *DealService) GetLatestCreative(ctx context.Context, dealID uuid.UUID) (*models.DealCreative, error) {
 	return s.dealRepo.GetLatestCreative(ctx, dealID)
 }
 
+// GetCreatives returns all creative versions of the deal, oldest first, to the advertiser
+// or a member of the deal's channel.
+func (s *DealService) GetCreatives(ctx context.Context, dealID, actorID uuid.UUID) ([]models.DealCreative, error) {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return nil, err
+	}
+	if deal.AdvertiserUserID != actorID {
+		if err := s.checkChannelRole(ctx, deal.ChannelID, actorID, false); err != nil {
+			return nil, err
+		}
+	}
+	return s.dealRepo.ListCreatives(ctx, dealID)
+}
+
+// RateDeal records the actor's rating of the other side of a completed deal: the
+// advertiser rates the channel, the channel owner rates the advertiser. Once per deal.
+func (s *DealService) RateDeal(ctx context.Context, dealID, actorID uuid.UUID, stars int, comment *string) (*models.DealRating, error) {
+	comment, err := validateRating(stars, comment)
+	if err != nil {
+		return nil, err
+	}
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return nil, err
+	}
+	if deal.Status != models.DealStatusCompleted {
+		return nil, ErrDealNotRatable
+	}
+
+	ratee := models.RateeChannel
+	if deal.AdvertiserUserID != actorID {
+		if err := s.checkChannelRole(ctx, deal.ChannelID, actorID, true); err != nil {
+			return nil, err
+		}
+		ratee = models.RateeAdvertiser
+	}
+
+	rating := &models.DealRating{
+		DealID:      dealID,
+		RaterUserID: actorID,
+		RateeType:   ratee,
+		Stars:       stars,
+		Comment:     comment,
+	}
+	if err := s.ratingRepo.Create(ctx, rating); err != nil {
+		if repositories.IsUniqueViolation(err) {
+			return nil, ErrDealAlreadyRated
+		}
+		return nil, err
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &actorID,
+		ActorType:   "user",
+		Action:      "deal_rated",
+		EntityType:  "deal",
+		EntityID:    &dealID,
+		Meta:        map[string]any{"ratee_type": ratee, "stars": stars},
+	})
+	return rating, nil
+}
+
+// validateRating checks the stars and returns the trimmed comment, nil if blank.
+func validateRating(stars int, comment *string) (*string, error) {
+	if !models.ValidRatingStars(stars) {
+		return nil, ErrInvalidRating
+	}
+	if comment == nil {
+		return nil, nil
+	}
+	text := strings.TrimSpace(*comment)
+	if text == "" {
+		return nil, nil
+	}
+	if len([]rune(text)) > models.MaxRatingCommentLen {
+		return nil, fmt.Errorf("comment must be at most %d characters", models.MaxRatingCommentLen)
+	}
+	return &text, nil
+}
+
+// GetDealRatings returns the ratings left on the deal to the advertiser or a member of
+// the deal's channel.
+func (s *DealService) GetDealRatings(ctx context.Context, dealID, actorID uuid.UUID) ([]models.DealRating, error) {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return nil, err
+	}
+	if deal.AdvertiserUserID != actorID {
+		if err := s.checkChannelRole(ctx, deal.ChannelID, actorID, false); err != nil {
+			return nil, err
+		}
+	}
+	return s.ratingRepo.ListByDeal(ctx, dealID)
+}
+
+// GetAdvertiserRating aggregates channel owners' ratings of the user's completed deals,
+// so owners can vet an advertiser before accepting.
+func (s *DealService) GetAdvertiserRating(ctx context.Context, userID uuid.UUID) (models.RatingSummary, error) {
+	return s.ratingRepo.AdvertiserSummary(ctx, userID)
+}
+
 func (s *DealService) GetDealEvents(ctx context.Context, dealID uuid.UUID) ([]models.AuditLog, error) {
 	return s.auditRepo.GetByEntity(ctx, "deal", dealID, 100, 0)
 }
@@ -441,12 +2204,144 @@ func (s *DealService) GetPaymentInfo(ctx context.Context, dealID uuid.UUID) (*mo
 	return s.escrowRepo.GetByDealID(ctx, dealID)
 }
 
+// ResendPaymentInstructions sends the escrow's payment details with a ton:// deeplink
+// to the advertiser's Telegram chat. Only while payment is still awaited.
+func (s *DealService) ResendPaymentInstructions(ctx context.Context, dealID, actorID uuid.UUID) error {
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	if deal.AdvertiserUserID != actorID {
+		return fmt.Errorf("only advertiser can request payment instructions")
+	}
+
+	escrow, err := s.escrowRepo.GetByDealID(ctx, dealID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrEscrowNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if escrow.Status != models.EscrowStatusAwaiting {
+		return ErrPaymentNotAwaited
+	}
+
+	units, err := ton.ParseUnits(escrow.DepositExpectedTON, models.CurrencyDecimals(escrow.Currency))
+	if err != nil {
+		return fmt.Errorf("invalid escrow amount: %w", err)
+	}
+	var jettonMaster string
+	if escrow.Currency == models.EscrowCurrencyUSDT {
+		jettonMaster = s.cfg.USDTJettonMaster
+	}
+	link := ton.TransferLink(escrow.DepositAddress, units, escrow.DepositMemo, jettonMaster)
+
+	telegramID, err := s.dealRepo.GetAdvertiserTelegramID(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	text := ton.PaymentInstructionsText(escrow.DepositExpectedTON, escrow.Currency, escrow.DepositAddress, escrow.DepositMemo, link)
+	if err := s.botClient.SendNotification(ctx, telegramID, text); err != nil {
+		return fmt.Errorf("could not deliver the message, please try again later")
+	}
+	return nil
+}
+
 // --- helpers ---
 
+// ChannelAvailability describes when a channel can take the next ad.
+type ChannelAvailability struct {
+	ChannelID           uuid.UUID   `json:"channel_id"`
+	AdFormat            string      `json:"ad_format"`
+	EarliestAvailableAt time.Time   `json:"earliest_available_at"`
+	MinLeadTimeMinutes  int         `json:"min_lead_time_minutes"`
+	SlotMinutes         int         `json:"slot_minutes"`
+	TakenSlots          []time.Time `json:"taken_slots"`
+}
+
+// GetChannelAvailability returns the earliest free posting slot for the format:
+// now + the format's min lead time, pushed past any slot already held by another
+// scheduled deal on the channel.
+func (s *DealService) GetChannelAvailability(ctx context.Context, channelID uuid.UUID, adFormat string) (*ChannelAvailability, error) {
+	if !models.IsValidAdFormat(adFormat) {
+		return nil, fmt.Errorf("invalid ad format %q, must be one of: post, repost, story", adFormat)
+	}
+
+	// Only ErrNotFound means there is no listing; anything else is a failed lookup
+	listing, err := s.channelRepo.GetListing(ctx, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("get listing: %w", err)
+	}
+
+	earliest := listing.EarliestScheduleAt(adFormat, s.clock.Now().UTC())
+	taken, err := s.dealRepo.ListTakenSlots(ctx, channelID, earliest.Add(-s.cfg.PostingSlot), nil)
+	if err != nil {
+		return nil, err
+	}
+	if taken == nil {
+		taken = []time.Time{}
+	}
+
+	return &ChannelAvailability{
+		ChannelID:           channelID,
+		EarliestAvailableAt: models.NextAvailableSlot(earliest, taken, s.cfg.PostingSlot),
+		AdFormat:            adFormat,
+		MinLeadTimeMinutes:  listing.GetMinLeadForFormat(adFormat),
+		SlotMinutes:         int(s.cfg.PostingSlot / time.Minute),
+		TakenSlots:          taken,
+	}, nil
+}
+
+// checkSlotFree rejects a scheduled time that collides with another deal's slot on the channel.
+func (s *DealService) checkSlotFree(ctx context.Context, channelID uuid.UUID, at time.Time, excludeDealID *uuid.UUID) error {
+	taken, err := s.dealRepo.ListTakenSlots(ctx, channelID, at.Add(-s.cfg.PostingSlot), excludeDealID)
+	if err != nil {
+		return err
+	}
+	if models.SlotConflicts(at, taken, s.cfg.PostingSlot) {
+		next := models.NextAvailableSlot(at, taken, s.cfg.PostingSlot)
+		return fmt.Errorf("posting slot is already taken, next available at %s", next.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// checkMergeSlotConflicts fails if upcoming scheduled deals of two channels overlap,
+// which would double-book the slot after the channels are merged.
+func (s *DealService) checkMergeSlotConflicts(ctx context.Context, keepID, dupID uuid.UUID) error {
+	now := s.clock.Now()
+	keepSlots, err := s.dealRepo.ListTakenSlots(ctx, keepID, now, nil)
+	if err != nil {
+		return err
+	}
+	dupSlots, err := s.dealRepo.ListTakenSlots(ctx, dupID, now, nil)
+	if err != nil {
+		return err
+	}
+	for _, t := range dupSlots {
+		if models.SlotConflicts(t, keepSlots, s.cfg.PostingSlot) {
+			return fmt.Errorf("active deals conflict: both channels have a post scheduled around %s", t.UTC().Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// checkNotBlocked returns ErrUserBlocked if the advertiser and a member of the channel
+// have blocked each other.
+func (s *DealService) checkNotBlocked(ctx context.Context, channelID, advertiserID uuid.UUID) error {
+	blocked, err := s.blockRepo.ChannelBlocked(ctx, channelID, advertiserID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrUserBlocked
+	}
+	return nil
+}
+
 func (s *DealService) checkChannelRole(ctx context.Context, channelID, userID uuid.UUID, ownerOnly bool) error {
 	member, err := s.channelRepo.GetMemberByUserAndChannel(ctx, channelID, userID)
 	if err != nil {
-		return fmt.Errorf("user is not a member of this channel")
+		return ErrNotChannelMember
 	}
 	if ownerOnly && member.Role != "owner" {
 		return fmt.Errorf("only owner can perform this action")
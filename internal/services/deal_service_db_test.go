@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"testing"
+
+	"github.com/ads-marketplace/backend/internal/config"
+	"github.com/ads-marketplace/backend/internal/db"
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/ads-marketplace/backend/internal/repositories"
+	"go.uber.org/zap"
+)
+
+// blockedDealFixture is a deal on an auto-accept listing whose channel owner has blocked the
+// advertiser. It needs TEST_POSTGRES_DSN and skips the test when it is not set.
+type blockedDealFixture struct {
+	svc        *DealService
+	deal       *models.Deal
+	advertiser *models.User
+	owner      *models.User
+}
+
+// newBlockedDealFixture creates the fixture with the deal in status.
+func newBlockedDealFixture(t *testing.T, status string) *blockedDealFixture {
+	t.Helper()
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN not set")
+	}
+	ctx := context.Background()
+	pool, err := db.NewPostgresPool(ctx, dsn, 0, zap.NewNop())
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	userRepo := repositories.NewUserRepo(pool)
+	channelRepo := repositories.NewChannelRepo(pool)
+	dealRepo := repositories.NewDealRepo(pool)
+	blockRepo := repositories.NewBlockRepo(pool)
+
+	newUser := func() *models.User {
+		u, err := userRepo.UpsertByTelegramID(ctx, -rand.Int64N(1<<40)-1, nil, nil, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		return u
+	}
+	owner, advertiser := newUser(), newUser()
+
+	ch := &models.Channel{Username: fmt.Sprintf("blocked_%d", rand.Int64N(1<<40)), AddedByUserID: &owner.ID, BotStatus: "pending"}
+	if err := channelRepo.Create(ctx, ch); err != nil {
+		t.Fatalf("create channel: %v", err)
+	}
+	if err := channelRepo.AddMember(ctx, &models.ChannelMember{ChannelID: ch.ID, UserID: owner.ID, Role: "owner"}); err != nil {
+		t.Fatalf("add owner: %v", err)
+	}
+	if err := channelRepo.UpsertListing(ctx, &models.ChannelListing{
+		ChannelID: ch.ID, Status: models.ListingStatusActive, FormatsEnabled: []string{models.AdFormatPost},
+		HoldHoursPost: 24, HoldHoursRepost: 24, AutoAccept: true,
+	}); err != nil {
+		t.Fatalf("create listing: %v", err)
+	}
+	deal := &models.Deal{
+		ChannelID: ch.ID, AdvertiserUserID: advertiser.ID, Status: status,
+		AdFormat: models.AdFormatPost, PriceTON: "1", PlatformFeeBPS: 500, HoldPeriodSeconds: 3600,
+	}
+	if err := dealRepo.Create(ctx, deal); err != nil {
+		t.Fatalf("create deal: %v", err)
+	}
+	if err := blockRepo.Block(ctx, owner.ID, advertiser.ID); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+
+	// Only the repositories the refused paths reach are wired
+	svc := NewDealService(dealRepo, channelRepo, nil, nil, nil, nil, nil, nil, blockRepo, nil, nil, nil, nil, nil, nil, &config.Config{}, zap.NewNop())
+	return &blockedDealFixture{svc: svc, deal: deal, advertiser: advertiser, owner: owner}
+}
+
+func (f *blockedDealFixture) status(t *testing.T) string {
+	t.Helper()
+	deal, err := f.svc.dealRepo.GetByID(context.Background(), f.deal.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	return deal.Status
+}
+
+// TestSubmitDealBlocked refuses to submit, and so to auto-accept, a deal whose advertiser
+// the channel owner blocked after the draft was created.
+func TestSubmitDealBlocked(t *testing.T) {
+	f := newBlockedDealFixture(t, models.DealStatusDraft)
+
+	err := f.svc.SubmitDeal(context.Background(), f.deal.ID, f.advertiser.ID)
+	if !errors.Is(err, ErrUserBlocked) {
+		t.Fatalf("SubmitDeal = %v, want ErrUserBlocked", err)
+	}
+	if got := f.status(t); got != models.DealStatusDraft {
+		t.Errorf("deal status = %s, want draft", got)
+	}
+}
+
+// TestAcceptCounterofferBlocked refuses to accept a counteroffer once the channel owner has
+// blocked the advertiser.
+func TestAcceptCounterofferBlocked(t *testing.T) {
+	f := newBlockedDealFixture(t, models.DealStatusSubmitted)
+	ctx := context.Background()
+	offer := &models.DealCounteroffer{DealID: f.deal.ID, ProposedBy: f.owner.ID, PriceTON: "2"}
+	if err := f.svc.dealRepo.CreateCounteroffer(ctx, offer); err != nil {
+		t.Fatalf("CreateCounteroffer: %v", err)
+	}
+
+	err := f.svc.AcceptCounteroffer(ctx, f.deal.ID, f.advertiser.ID)
+	if !errors.Is(err, ErrUserBlocked) {
+		t.Fatalf("AcceptCounteroffer = %v, want ErrUserBlocked", err)
+	}
+	if got := f.status(t); got != models.DealStatusSubmitted {
+		t.Errorf("deal status = %s, want submitted", got)
+	}
+	if _, err := f.svc.dealRepo.GetPendingCounteroffer(ctx, f.deal.ID); err != nil {
+		t.Errorf("counteroffer no longer pending: %v", err)
+	}
+}
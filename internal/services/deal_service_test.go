@@ -0,0 +1,289 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ads-marketplace/backend/internal/apperr"
+	"github.com/ads-marketplace/backend/internal/models"
+)
+
+func TestCheckLeadTime(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	story := 30
+	listing := &models.ChannelListing{MinLeadTimeMinutes: 360, MinLeadStoryMinutes: &story}
+
+	tests := []struct {
+		name        string
+		format      string
+		scheduledAt time.Time
+		wantErr     error  // sentinel, if any
+		wantMsg     string // substring of a lead-time error
+	}{
+		{"in the past", models.AdFormatPost, now.Add(-time.Minute), ErrScheduledAtInPast, ""},
+		{"right now", models.AdFormatPost, now, ErrScheduledAtInPast, ""},
+		{"30 seconds ahead, 6h lead", models.AdFormatPost, now.Add(30 * time.Second), nil, "at least 360 minutes lead time for post"},
+		{"one minute short", models.AdFormatPost, now.Add(359 * time.Minute), nil, "earliest is 2026-03-01T18:00:00Z"},
+		{"exactly the lead time", models.AdFormatPost, now.Add(6 * time.Hour), nil, ""},
+		{"well ahead", models.AdFormatPost, now.Add(48 * time.Hour), nil, ""},
+		{"format-specific lead time", models.AdFormatStory, now.Add(45 * time.Minute), nil, ""},
+		{"format-specific too soon", models.AdFormatStory, now.Add(10 * time.Minute), nil, "at least 30 minutes lead time for story"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkLeadTime(listing, tt.format, tt.scheduledAt, now)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantMsg != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantMsg) {
+					t.Fatalf("err = %v, want message containing %q", err, tt.wantMsg)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckListingAcceptsDeal(t *testing.T) {
+	formats := []string{models.AdFormatPost}
+
+	tests := []struct {
+		name    string
+		status  string
+		format  string
+		wantErr error  // sentinel, if any
+		wantMsg string // substring of a format error
+	}{
+		{"active listing", models.ListingStatusActive, models.AdFormatPost, nil, ""},
+		{"paused listing", models.ListingStatusPaused, models.AdFormatPost, ErrListingNotActive, ""},
+		{"draft listing", models.ListingStatusDraft, models.AdFormatPost, ErrListingNotActive, ""},
+		{"paused listing, format disabled", models.ListingStatusPaused, models.AdFormatStory, ErrListingNotActive, ""},
+		{"format disabled", models.ListingStatusActive, models.AdFormatStory, nil, `ad format "story" is not enabled`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listing := &models.ChannelListing{Status: tt.status, FormatsEnabled: formats}
+			err := checkListingAcceptsDeal(listing, tt.format)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantMsg != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantMsg) {
+					t.Fatalf("err = %v, want message containing %q", err, tt.wantMsg)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateCreativeInput(t *testing.T) {
+	manyMedia := make([]string, models.MaxCreativeMedia+1)
+	for i := range manyMedia {
+		manyMedia[i] = "https://example.com/a.jpg"
+	}
+	manyButtons := make([]models.CreativeButton, models.MaxCreativeButtons+1)
+	for i := range manyButtons {
+		manyButtons[i] = models.CreativeButton{Text: "Open", URL: "https://t.me/news"}
+	}
+
+	tests := []struct {
+		name    string
+		input   SubmitCreativeInput
+		wantErr error
+		field   string
+	}{
+		{"text only", SubmitCreativeInput{Text: "hi"}, nil, ""},
+		{"valid media and buttons", SubmitCreativeInput{
+			MediaURLs: []string{"https://example.com/a.jpg"},
+			Buttons:   []models.CreativeButton{{Text: "Join", URL: "https://t.me/news"}},
+		}, nil, ""},
+		{"too many media", SubmitCreativeInput{MediaURLs: manyMedia}, ErrTooManyMedia, "media_urls"},
+		{"bad media url", SubmitCreativeInput{MediaURLs: []string{"https://example.com/a.jpg", "ftp://x/y"}}, ErrInvalidMediaURL, "media_urls[1]"},
+		{"too many buttons", SubmitCreativeInput{Buttons: manyButtons}, ErrTooManyButtons, "buttons_json"},
+		{"button without text", SubmitCreativeInput{Buttons: []models.CreativeButton{{Text: " ", URL: "https://t.me/news"}}}, ErrInvalidButton, "buttons_json[0].text"},
+		{"javascript button", SubmitCreativeInput{Buttons: []models.CreativeButton{{Text: "Go", URL: "javascript:alert(1)"}}}, ErrInvalidButton, "buttons_json[0].url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCreativeInput(tt.input)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("validateCreativeInput() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("validateCreativeInput() = %v, want %v", err, tt.wantErr)
+			}
+			if got := apperr.FieldOf(err); got != tt.field {
+				t.Errorf("field = %q, want %q", got, tt.field)
+			}
+		})
+	}
+}
+
+func TestUnmatchedPaymentExcess(t *testing.T) {
+	tonEscrow := &models.EscrowLedger{Status: models.EscrowStatusAwaiting, Currency: models.EscrowCurrencyTON, DepositExpectedTON: "1.5"}
+	usdtEscrow := &models.EscrowLedger{Status: models.EscrowStatusAwaiting, Currency: models.EscrowCurrencyUSDT, DepositExpectedTON: "10"}
+	fundedEscrow := &models.EscrowLedger{Status: models.EscrowStatusFunded, Currency: models.EscrowCurrencyTON, DepositExpectedTON: "1.5"}
+	expiredEscrow := &models.EscrowLedger{Status: models.EscrowStatusExpired, Currency: models.EscrowCurrencyTON, DepositExpectedTON: "1.5"}
+
+	tests := []struct {
+		name       string
+		escrow     *models.EscrowLedger
+		currency   string
+		amount     string
+		wantExcess string
+		wantOK     bool
+	}{
+		{"exact TON", tonEscrow, "TON", "1500000000", "", true},
+		{"overpaid TON", tonEscrow, "TON", "1600000000", "100000000", true},
+		{"underpaid TON", tonEscrow, "TON", "1499999999", "", false},
+		{"exact USDT", usdtEscrow, "USDT", "10000000", "", true},
+		{"currency mismatch", usdtEscrow, "TON", "10000000000", "", false},
+		{"escrow already funded", fundedEscrow, "TON", "1500000000", "", false},
+		{"escrow expired", expiredEscrow, "TON", "1500000000", "", false},
+		{"garbage amount", tonEscrow, "TON", "1.5", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &models.UnmatchedPayment{Currency: tt.currency, Amount: tt.amount}
+			excess, ok := UnmatchedPaymentExcess(tt.escrow, p)
+			if excess != tt.wantExcess || ok != tt.wantOK {
+				t.Errorf("UnmatchedPaymentExcess() = (%q, %v), want (%q, %v)", excess, ok, tt.wantExcess, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidateRating(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name        string
+		stars       int
+		comment     *string
+		wantComment *string
+		wantErr     error  // sentinel, if any
+		wantMsg     string // substring of another error
+	}{
+		{"stars only", 5, nil, nil, nil, ""},
+		{"comment trimmed", 4, str("  fast and polite \n"), str("fast and polite"), nil, ""},
+		{"blank comment dropped", 3, str("   "), nil, nil, ""},
+		{"zero stars", 0, nil, nil, ErrInvalidRating, ""},
+		{"six stars", 6, str("great"), nil, ErrInvalidRating, ""},
+		{"comment too long", 2, str(strings.Repeat("я", models.MaxRatingCommentLen+1)), nil, nil, "at most 1000 characters"},
+		{"comment at the limit", 2, str(strings.Repeat("я", models.MaxRatingCommentLen)), str(strings.Repeat("я", models.MaxRatingCommentLen)), nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateRating(tt.stars, tt.comment)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			case tt.wantMsg != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantMsg) {
+					t.Fatalf("err = %v, want it to mention %q", err, tt.wantMsg)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (got == nil) != (tt.wantComment == nil) || (got != nil && *got != *tt.wantComment) {
+				t.Errorf("comment = %v, want %v", got, tt.wantComment)
+			}
+		})
+	}
+}
+
+func TestCheckForceStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		target  string
+		funded  bool
+		wantErr bool
+	}{
+		{"unwedge a stuck deal", models.DealStatusPosted, models.DealStatusScheduled, true, false},
+		{"cancel", models.DealStatusCreativeSubmitted, models.DealStatusCancelled, true, false},
+		{"cancel an unpaid deal", models.DealStatusAwaitingPayment, models.DealStatusCancelled, false, false},
+		{"completed settles the escrow", models.DealStatusHoldVerification, models.DealStatusCompleted, true, true},
+		{"refunded settles the escrow", models.DealStatusFunded, models.DealStatusRefunded, true, true},
+		{"same status", models.DealStatusPosted, models.DealStatusPosted, true, true},
+		{"unknown status", models.DealStatusPosted, "paid", true, true},
+		{"unpaid deal into posted", models.DealStatusAwaitingPayment, models.DealStatusPosted, false, true},
+		{"unpaid deal into hold", models.DealStatusAccepted, models.DealStatusHoldVerification, false, true},
+		{"unfunded deal into release_failed", models.DealStatusPosted, models.DealStatusReleaseFailed, false, true},
+		{"unfunded deal into disputed", models.DealStatusScheduled, models.DealStatusDisputed, false, true},
+		{"funded deal into hold", models.DealStatusPosted, models.DealStatusHoldVerification, true, false},
+		{"out of refunded", models.DealStatusRefunded, models.DealStatusPosted, false, true},
+		{"out of refunded, unpaid target", models.DealStatusRefunded, models.DealStatusCancelled, false, true},
+		{"out of completed", models.DealStatusCompleted, models.DealStatusHoldVerification, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkForceStatus(tt.current, tt.target, tt.funded); (err != nil) != tt.wantErr {
+				t.Errorf("checkForceStatus(%s, %s, %v) = %v, wantErr %v", tt.current, tt.target, tt.funded, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckAdminAction(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		action  string
+		params  map[string]any
+		wantErr bool
+	}{
+		{"release a posted deal", models.DealStatusPosted, models.AdminActionForceRelease, nil, false},
+		{"release a held deal", models.DealStatusHoldVerification, models.AdminActionForceRelease, nil, false},
+		{"release an unpaid deal", models.DealStatusAwaitingPayment, models.AdminActionForceRelease, nil, true},
+		{"refund a funded deal", models.DealStatusFunded, models.AdminActionForceRefund, nil, false},
+		{"refund a completed deal", models.DealStatusCompleted, models.AdminActionForceRefund, nil, true},
+		{"refund twice", models.DealStatusRefunded, models.AdminActionForceRefund, nil, true},
+		{"match an awaiting payment", models.DealStatusAwaitingPayment, models.AdminActionManualEscrowMatch,
+			map[string]any{"tx_hash": "abc", "payer_address": "EQ..."}, false},
+		{"match a funded deal", models.DealStatusFunded, models.AdminActionManualEscrowMatch,
+			map[string]any{"tx_hash": "abc", "payer_address": "EQ..."}, true},
+		{"match without tx", models.DealStatusAwaitingPayment, models.AdminActionManualEscrowMatch, nil, true},
+		{"resolve a dispute", models.DealStatusDisputed, models.AdminActionResolveDispute,
+			map[string]any{"outcome": models.DisputeOutcomeRefund}, false},
+		{"resolve an undisputed deal", models.DealStatusPosted, models.AdminActionResolveDispute,
+			map[string]any{"outcome": models.DisputeOutcomeRefund}, true},
+		{"resolve with a bad outcome", models.DealStatusDisputed, models.AdminActionResolveDispute,
+			map[string]any{"outcome": "split"}, true},
+		{"force a status", models.DealStatusPosted, models.AdminActionForceStatus,
+			map[string]any{"status": models.DealStatusScheduled, "reason": "stuck"}, false},
+		{"force completed", models.DealStatusPosted, models.AdminActionForceStatus,
+			map[string]any{"status": models.DealStatusCompleted, "reason": "stuck"}, true},
+		{"force without reason", models.DealStatusPosted, models.AdminActionForceStatus,
+			map[string]any{"status": models.DealStatusScheduled}, true},
+		{"unknown action", models.DealStatusPosted, "delete", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkAdminAction(tt.status, true, tt.action, tt.params); (err != nil) != tt.wantErr {
+				t.Errorf("checkAdminAction(%s, %s) = %v, wantErr %v", tt.status, tt.action, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ads-marketplace/backend/internal/config"
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/ads-marketplace/backend/internal/repositories"
+	"github.com/ads-marketplace/backend/internal/ton"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// withdrawalBatchSize caps the transfers one payout run sends: each waits for its transaction.
+const withdrawalBatchSize = 10
+
+// TONSender sends TON and jettons from the hot wallet (ton.LiteClient).
+type TONSender interface {
+	SendTON(ctx context.Context, toAddress string, amountNano *big.Int, comment string) (string, error)
+	SendJetton(ctx context.Context, jettonMaster, toAddress string, units *big.Int, comment string) (string, error)
+}
+
+// EarningsService exposes the owner balance (credited on deal completion) and batches payouts.
+type EarningsService struct {
+	balanceRepo *repositories.BalanceRepo
+	dealRepo    *repositories.DealRepo
+	walletRepo  *repositories.WalletRepo
+	auditRepo   *repositories.AuditRepo
+	sender      TONSender // nil in the API: payouts are sent by the worker
+	cfg         *config.Config
+	log         *zap.Logger
+}
+
+func NewEarningsService(
+	balanceRepo *repositories.BalanceRepo,
+	dealRepo *repositories.DealRepo,
+	walletRepo *repositories.WalletRepo,
+	auditRepo *repositories.AuditRepo,
+	sender TONSender,
+	cfg *config.Config,
+	log *zap.Logger,
+) *EarningsService {
+	return &EarningsService{
+		balanceRepo: balanceRepo,
+		dealRepo:    dealRepo,
+		walletRepo:  walletRepo,
+		auditRepo:   auditRepo,
+		sender:      sender,
+		cfg:         cfg,
+		log:         log,
+	}
+}
+
+// balanceCurrency validates the currency of a balance request; "" means TON.
+func balanceCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return models.EscrowCurrencyTON, nil
+	}
+	if models.CurrencyDecimals(currency) < 0 {
+		return "", ErrUnknownCurrency
+	}
+	return currency, nil
+}
+
+// GetEarnings returns the owner's balance in currency ("" for TON) and whether it can be withdrawn.
+func (s *EarningsService) GetEarnings(ctx context.Context, userID uuid.UUID, currency string) (*models.Earnings, error) {
+	currency, err := balanceCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := s.balanceRepo.GetBalance(ctx, userID, currency)
+	if err != nil {
+		return nil, err
+	}
+	minPayout := s.cfg.MinPayout(currency)
+	return &models.Earnings{
+		BalanceTON:   balance,
+		MinPayoutTON: minPayout,
+		CanWithdraw:  models.BalanceExceedsMinPayout(balance, minPayout),
+		Currency:     currency,
+	}, nil
+}
+
+// GetBalance returns the owner's earned, withdrawn and available amounts in currency ("" for
+// TON), and what funded deals in that currency on their channels will add once completed.
+func (s *EarningsService) GetBalance(ctx context.Context, userID uuid.UUID, currency string) (*models.Balance, error) {
+	currency, err := balanceCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	earned, withdrawn, err := s.balanceRepo.GetLedgerTotals(ctx, userID, currency)
+	if err != nil {
+		return nil, err
+	}
+	byStatus, err := s.dealRepo.GetOwnerEarnings(ctx, userID, currency)
+	if err != nil {
+		return nil, err
+	}
+	pending := byStatus[models.EscrowStatusFunded]
+	if pending == "" {
+		pending = "0"
+	}
+	return CalculateBalance(earned, withdrawn, pending, currency)
+}
+
+// RequestWithdrawal queues a payout of amountTON ("" for the whole balance) of the currency
+// balance ("" for TON) to the user's primary verified wallet. Refused unless the amount
+// exceeds the currency's minimum payout and is covered by the balance.
+func (s *EarningsService) RequestWithdrawal(ctx context.Context, userID uuid.UUID, amountTON, currency string) (*models.Withdrawal, error) {
+	currency, err := balanceCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	if currency == models.EscrowCurrencyUSDT && s.cfg.USDTJettonMaster == "" {
+		return nil, fmt.Errorf("USDT payouts are not configured")
+	}
+	amountTON = strings.TrimSpace(amountTON)
+	if amountTON != "" {
+		units, err := ton.ParseUnits(amountTON, models.CurrencyDecimals(currency))
+		if err != nil || units.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid amount_ton %q", amountTON)
+		}
+	}
+
+	userWallet, err := s.walletRepo.GetActiveWallet(ctx, userID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrWalletNotConnected
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !userWallet.Verified {
+		return nil, ErrWalletNotVerified
+	}
+	if userWallet.Network != s.cfg.TONNetwork {
+		return nil, fmt.Errorf("connected wallet is on %s, expected %s", userWallet.Network, s.cfg.TONNetwork)
+	}
+
+	minPayout := s.cfg.MinPayout(currency)
+	w, err := s.balanceRepo.Withdraw(ctx, userID, userWallet.AddressFriendly, amountTON, minPayout, currency)
+	if errors.Is(err, repositories.ErrNotFound) && amountTON == "" {
+		return nil, fmt.Errorf("balance must exceed the minimum payout of %s %s", minPayout, currency)
+	}
+	if errors.Is(err, repositories.ErrNotFound) {
+		balance, _ := s.balanceRepo.GetBalance(ctx, userID, currency)
+		return nil, fmt.Errorf("amount must exceed the minimum payout of %s %s and not exceed the balance of %s",
+			minPayout, currency, balance)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &userID,
+		ActorType:   "user",
+		Action:      "withdrawal_requested",
+		EntityType:  "withdrawal",
+		EntityID:    &w.ID,
+		Meta: map[string]any{
+			"amount_ton":     w.AmountTON,
+			"currency":       w.Currency,
+			"wallet_address": w.WalletAddress,
+		},
+	})
+	s.log.Info("withdrawal requested",
+		zap.String("user_id", userID.String()),
+		zap.String("amount_ton", w.AmountTON),
+		zap.String("currency", w.Currency),
+	)
+
+	return w, nil
+}
+
+// ListWithdrawals returns the user's withdrawals, newest first.
+func (s *EarningsService) ListWithdrawals(ctx context.Context, userID uuid.UUID, limit int) ([]models.Withdrawal, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	return s.balanceRepo.ListWithdrawals(ctx, userID, limit)
+}
+
+// ProcessWithdrawals sends pending withdrawals from the hot wallet. Each is claimed
+// ('sending') before the transfer is signed, so a crash mid-send never pays it twice.
+func (s *EarningsService) ProcessWithdrawals(ctx context.Context) error {
+	if s.sender == nil {
+		return fmt.Errorf("hot wallet sender is not configured")
+	}
+	list, err := s.balanceRepo.ClaimPendingWithdrawals(ctx, withdrawalBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range list {
+		s.sendWithdrawal(ctx, w)
+	}
+	return nil
+}
+
+// sendWithdrawalTransfer records the transfer comment on the withdrawal, then sends it:
+// native TON, or a jetton transfer of the USDT master for a USDT balance.
+func (s *EarningsService) sendWithdrawalTransfer(ctx context.Context, w models.Withdrawal, meta map[string]any) (string, error) {
+	units, err := ton.ParseUnits(w.AmountTON, models.CurrencyDecimals(w.Currency))
+	if err != nil {
+		return "", err
+	}
+	if w.Currency == models.EscrowCurrencyUSDT && s.cfg.USDTJettonMaster == "" {
+		return "", fmt.Errorf("USDT_JETTON_MASTER is not configured")
+	}
+	comment, err := ton.TransferComment(s.cfg.TONSendComment, "payout", "withdrawal", w.ID.String())
+	if err != nil {
+		return "", err
+	}
+	if err := s.balanceRepo.SetWithdrawalComment(ctx, w.ID, comment); err != nil {
+		return "", fmt.Errorf("record transfer comment: %w", err)
+	}
+	meta["tx_comment"] = comment
+	if w.Currency == models.EscrowCurrencyUSDT {
+		return s.sender.SendJetton(ctx, s.cfg.USDTJettonMaster, w.WalletAddress, units, comment)
+	}
+	return s.sender.SendTON(ctx, w.WalletAddress, units, comment)
+}
+
+func (s *EarningsService) sendWithdrawal(ctx context.Context, w models.Withdrawal) {
+	meta := map[string]any{
+		"amount_ton":     w.AmountTON,
+		"currency":       w.Currency,
+		"wallet_address": w.WalletAddress,
+	}
+
+	txHash, err := s.sendWithdrawalTransfer(ctx, w, meta)
+	if err != nil {
+		s.log.Error("withdrawal payout failed",
+			zap.String("withdrawal_id", w.ID.String()),
+			zap.String("amount_ton", w.AmountTON),
+			zap.Error(err),
+		)
+		if err := s.balanceRepo.MarkWithdrawalFailed(ctx, w.ID, err.Error()); err != nil {
+			s.log.Error("failed to mark withdrawal failed", zap.String("withdrawal_id", w.ID.String()), zap.Error(err))
+		}
+		meta["error"] = err.Error()
+		_ = s.auditRepo.Log(ctx, models.AuditLog{
+			ActorType:  "system",
+			Action:     "withdrawal_failed",
+			EntityType: "withdrawal",
+			EntityID:   &w.ID,
+			Meta:       meta,
+		})
+		return
+	}
+
+	if err := s.balanceRepo.MarkWithdrawalSent(ctx, w.ID, txHash); err != nil {
+		// The funds are gone: leave it in 'sending' rather than risk a second transfer
+		s.log.Error("withdrawal sent but not recorded",
+			zap.String("withdrawal_id", w.ID.String()),
+			zap.String("tx_hash", txHash),
+			zap.Error(err),
+		)
+	}
+	meta["tx_hash"] = txHash
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorType:  "system",
+		Action:     "withdrawal_sent",
+		EntityType: "withdrawal",
+		EntityID:   &w.ID,
+		Meta:       meta,
+	})
+	s.log.Info("withdrawal sent",
+		zap.String("withdrawal_id", w.ID.String()),
+		zap.String("amount_ton", w.AmountTON),
+		zap.String("tx_hash", txHash),
+	)
+}
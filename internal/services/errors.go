@@ -0,0 +1,57 @@
+package services
+
+import "github.com/ads-marketplace/backend/internal/apperr"
+
+// User-facing errors with stable codes; handlers localize them per Accept-Language.
+var (
+	ErrNotChannelMember       = apperr.New(apperr.CodeNotChannelMember)
+	ErrRepostURLRequired      = apperr.New(apperr.CodeRepostURLRequired)
+	ErrSkipApprovalNotAllowed = apperr.New(apperr.CodeSkipApprovalNotAllowed)
+	ErrCreativeNotFound       = apperr.New(apperr.CodeCreativeNotFound)
+	ErrCreativeNotPending     = apperr.New(apperr.CodeCreativeNotPending)
+	ErrDealNotInHold          = apperr.New(apperr.CodeDealNotInHold)
+	ErrWalletNotConnected     = apperr.New(apperr.CodeWalletNotConnected)
+	ErrWalletNotVerified      = apperr.New(apperr.CodeWalletNotVerified)
+	ErrWalletConnectLocked    = apperr.New(apperr.CodeWalletConnectLocked)
+	ErrTooManyAttempts        = apperr.New(apperr.CodeTooManyAttempts)
+	ErrEscrowNotFound         = apperr.New(apperr.CodeEscrowNotFound)
+	ErrPaymentNotAwaited      = apperr.New(apperr.CodePaymentNotAwaited)
+	ErrRefundRequestNotFound  = apperr.New(apperr.CodeRefundRequestNotFound)
+	ErrRefundRequestPending   = apperr.New(apperr.CodeRefundRequestPending)
+	ErrCampaignNotFound       = apperr.New(apperr.CodeCampaignNotFound)
+	ErrOfferNotFound          = apperr.New(apperr.CodeOfferNotFound)
+	ErrOfferUnavailable       = apperr.New(apperr.CodeOfferUnavailable)
+	ErrOfferToSelf            = apperr.New(apperr.CodeOfferToSelf)
+	ErrValidUntilInPast       = apperr.New(apperr.CodeValidUntilInPast)
+	ErrScheduledAtInPast      = apperr.New(apperr.CodeScheduledAtInPast)
+	ErrPayoutFrozen           = apperr.New(apperr.CodePayoutFrozen)
+	ErrDealNotDisputable      = apperr.New(apperr.CodeDealNotDisputable)
+	ErrDealNotDisputed        = apperr.New(apperr.CodeDealNotDisputed)
+	ErrNoPendingCounteroffer  = apperr.New(apperr.CodeNoPendingCounteroffer)
+	ErrInvalidListingStatus   = apperr.New(apperr.CodeInvalidListingStatus)
+	ErrListingNotActive       = apperr.New(apperr.CodeListingNotActive)
+	ErrNotChannelOwner        = apperr.New(apperr.CodeNotChannelOwner)
+	ErrCannotRemoveOwner      = apperr.New(apperr.CodeCannotRemoveOwner)
+	ErrStatsRefreshCooldown   = apperr.New(apperr.CodeStatsRefreshCooldown)
+	ErrStatsRefreshTimeout    = apperr.New(apperr.CodeStatsRefreshTimeout)
+	ErrStatsRefreshFailed     = apperr.New(apperr.CodeStatsRefreshFailed)
+	ErrWithdrawWalletNotOwned = apperr.New(apperr.CodeWithdrawWalletNotOwned)
+	ErrDealChanged            = apperr.New(apperr.CodeDealChanged)
+	ErrWebhookNotFound        = apperr.New(apperr.CodeWebhookNotFound)
+	ErrInvalidWebhookURL      = apperr.New(apperr.CodeInvalidWebhookURL)
+	ErrInvalidWebhookEvents   = apperr.New(apperr.CodeInvalidWebhookEvents)
+	ErrWebhookLimit           = apperr.New(apperr.CodeWebhookLimit)
+	ErrInvalidRefreshToken    = apperr.New(apperr.CodeInvalidRefreshToken)
+	ErrPostChannelMismatch    = apperr.New(apperr.CodePostChannelMismatch)
+	ErrTooManyMedia           = apperr.New(apperr.CodeTooManyMedia)
+	ErrInvalidMediaURL        = apperr.New(apperr.CodeInvalidMediaURL)
+	ErrTooManyButtons         = apperr.New(apperr.CodeTooManyButtons)
+	ErrInvalidButton          = apperr.New(apperr.CodeInvalidButton)
+	ErrDealNotRatable         = apperr.New(apperr.CodeDealNotRatable)
+	ErrDealAlreadyRated       = apperr.New(apperr.CodeDealAlreadyRated)
+	ErrInvalidRating          = apperr.New(apperr.CodeInvalidRating)
+	ErrUserBlocked            = apperr.New(apperr.CodeUserBlocked)
+	ErrCannotBlockSelf        = apperr.New(apperr.CodeCannotBlockSelf)
+	ErrUnknownCurrency        = apperr.New(apperr.CodeUnknownCurrency)
+	ErrEscrowNotFunded        = apperr.New(apperr.CodeEscrowNotFunded)
+)
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/ads-marketplace/backend/internal/ton"
+)
+
+// CalculateRelease splits a deal price into the owner's net and the platform fee, both as
+// decimal TON strings with 9 places. The net is price × (10000 − feeBPS) / 10000 rounded
+// down to whole nanoTON; the fee takes the remainder, so net + fee always equals the price.
+func CalculateRelease(priceTON string, feeBPS int) (netTON, feeTON string, err error) {
+	if feeBPS < 0 || feeBPS > 10000 {
+		return "", "", fmt.Errorf("invalid platform fee: %d bps", feeBPS)
+	}
+	decimals := models.CurrencyDecimals(models.EscrowCurrencyTON)
+	nano, err := ton.ParseUnits(priceTON, decimals)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid TON amount %q: %w", priceTON, err)
+	}
+	if nano.Sign() < 0 {
+		return "", "", fmt.Errorf("invalid TON amount: %s", priceTON)
+	}
+
+	netNano := new(big.Int).Mul(nano, big.NewInt(int64(10000-feeBPS)))
+	netNano.Quo(netNano, big.NewInt(10000))
+	feeNano := new(big.Int).Sub(nano, netNano)
+
+	return formatTON(netNano), formatTON(feeNano), nil
+}
+
+// CalculateBalance builds the owner's balance in currency from ledger totals: available is
+// earned minus withdrawn, computed in the currency's smallest units so no rounding creeps
+// into the sum.
+func CalculateBalance(earned, withdrawn, pending, currency string) (*models.Balance, error) {
+	decimals := models.CurrencyDecimals(currency)
+	if decimals < 0 {
+		return nil, fmt.Errorf("unknown currency %q", currency)
+	}
+	units := make([]*big.Int, 3)
+	for i, amount := range []string{earned, withdrawn, pending} {
+		n, err := ton.ParseUnits(amount, decimals)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s amount %q: %w", currency, amount, err)
+		}
+		units[i] = n
+	}
+	earnedUnits, withdrawnUnits, pendingUnits := units[0], units[1], units[2]
+
+	return &models.Balance{
+		TotalEarned: formatUnits(earnedUnits, decimals),
+		Withdrawn:   formatUnits(withdrawnUnits, decimals),
+		Available:   formatUnits(new(big.Int).Sub(earnedUnits, withdrawnUnits), decimals),
+		Pending:     formatUnits(pendingUnits, decimals),
+		Currency:    currency,
+	}, nil
+}
+
+// formatTON renders nanoTON as a decimal TON string with 9 places.
+func formatTON(nano *big.Int) string {
+	return formatUnits(nano, models.CurrencyDecimals(models.EscrowCurrencyTON))
+}
+
+// formatUnits renders an amount in smallest units as a decimal string with decimals places.
+func formatUnits(units *big.Int, decimals int) string {
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return new(big.Rat).SetFrac(units, unit).FloatString(decimals)
+}
@@ -0,0 +1,78 @@
+package services
+
+import "testing"
+
+func TestCalculateRelease(t *testing.T) {
+	tests := []struct {
+		name    string
+		price   string
+		feeBPS  int
+		wantNet string
+		wantFee string
+		wantErr bool
+	}{
+		{"5% of 10 TON", "10", 500, "9.500000000", "0.500000000", false},
+		{"no fee", "3.25", 0, "3.250000000", "0.000000000", false},
+		{"whole price is fee", "1", 10000, "0.000000000", "1.000000000", false},
+		{"one nanoTON: net rounds down to zero", "0.000000001", 500, "0.000000000", "0.000000001", false},
+		{"net rounds down, fee keeps the remainder", "0.000000019", 500, "0.000000018", "0.000000001", false},
+		{"sub-nano digits truncated", "1.0000000019", 1000, "0.900000000", "0.100000001", false},
+		{"zero price", "0", 500, "0.000000000", "0.000000000", false},
+		{"negative price", "-1", 500, "", "", true},
+		{"not a number", "abc", 500, "", "", true},
+		{"fee above 100%", "1", 10001, "", "", true},
+		{"negative fee", "1", -1, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net, fee, err := CalculateRelease(tt.price, tt.feeBPS)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if net != tt.wantNet || fee != tt.wantFee {
+				t.Errorf("CalculateRelease(%q, %d) = (%s, %s), want (%s, %s)", tt.price, tt.feeBPS, net, fee, tt.wantNet, tt.wantFee)
+			}
+		})
+	}
+}
+
+func TestCalculateBalance(t *testing.T) {
+	tests := []struct {
+		name          string
+		earned        string
+		withdrawn     string
+		pending       string
+		currency      string
+		wantAvailable string
+		wantErr       bool
+	}{
+		{"nothing yet", "0", "0", "0", "TON", "0.000000000", false},
+		{"partly withdrawn", "12.5", "10", "3", "TON", "2.500000000", false},
+		{"all withdrawn", "9.500000000", "9.500000000", "0", "TON", "0.000000000", false},
+		{"nano precision kept", "0.300000001", "0.1", "0", "TON", "0.200000001", false},
+		{"large sums", "123456789.123456789", "0.000000001", "0", "TON", "123456789.123456788", false},
+		{"USDT has 6 decimals", "12.5", "2.000001", "0", "USDT", "10.499999", false},
+		{"unknown currency", "1", "0", "0", "BTC", "", true},
+		{"invalid earned", "abc", "0", "0", "TON", "", true},
+		{"invalid pending", "1", "0", "", "TON", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := CalculateBalance(tt.earned, tt.withdrawn, tt.pending, tt.currency)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if b.Available != tt.wantAvailable {
+				t.Errorf("Available = %s, want %s", b.Available, tt.wantAvailable)
+			}
+			if b.Currency != tt.currency {
+				t.Errorf("Currency = %s, want %s", b.Currency, tt.currency)
+			}
+		})
+	}
+}
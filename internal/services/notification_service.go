@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+
+	"github.com/ads-marketplace/backend/internal/config"
+	"github.com/ads-marketplace/backend/internal/events"
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/ads-marketplace/backend/internal/repositories"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Parties of a deal that hear about a status change in the bot.
+const (
+	notifyAdvertiser = 1 << iota
+	notifyOwner
+	notifyBoth = notifyAdvertiser | notifyOwner
+)
+
+// dealStatusParties returns who is told in the bot that a deal entered status. Statuses
+// nobody needs to act on (follow-ups of another change, internal ones) return 0.
+func dealStatusParties(status string) int {
+	switch status {
+	case models.DealStatusSubmitted,
+		models.DealStatusFunded,
+		models.DealStatusCreativeChangesRequested,
+		models.DealStatusCreativeApproved:
+		return notifyOwner
+	case models.DealStatusAccepted,
+		models.DealStatusRejected,
+		models.DealStatusCreativeSubmitted,
+		models.DealStatusPosted:
+		return notifyAdvertiser
+	case models.DealStatusDisputed,
+		models.DealStatusCompleted,
+		models.DealStatusRefunded,
+		models.DealStatusCancelled:
+		return notifyBoth
+	}
+	return 0
+}
+
+// NotificationService turns deal status changes into bot notifications for the deal's
+// advertiser and channel owner. They are published to events:bot for the bot bridge.
+type NotificationService struct {
+	dealRepo  *repositories.DealRepo
+	publisher events.Publisher
+	cfg       *config.Config
+	log       *zap.Logger
+}
+
+func NewNotificationService(dealRepo *repositories.DealRepo, publisher events.Publisher, cfg *config.Config, log *zap.Logger) *NotificationService {
+	return &NotificationService{dealRepo: dealRepo, publisher: publisher, cfg: cfg, log: log}
+}
+
+// HandleDealEvent notifies the parties of a deal_status_changed event; other events
+// already carry their own recipient.
+func (s *NotificationService) HandleDealEvent(ctx context.Context, event events.Event) {
+	if event.Type != events.EventDealStatusChanged {
+		return
+	}
+	var payload events.DealStatusChangedPayload
+	if err := event.Decode(&payload); err != nil {
+		s.log.Warn("malformed deal event", zap.Error(err))
+		return
+	}
+	parties := dealStatusParties(payload.NewStatus)
+	if parties == 0 {
+		return
+	}
+	dealID, err := uuid.Parse(payload.DealID)
+	if err != nil {
+		return
+	}
+
+	key := events.MsgDealStatusChangedBase + payload.NewStatus
+	if parties&notifyAdvertiser != 0 {
+		s.notify(ctx, dealID, "advertiser", s.dealRepo.GetAdvertiserRecipient, key)
+	}
+	if parties&notifyOwner != 0 {
+		s.notify(ctx, dealID, "owner", s.dealRepo.GetChannelOwnerRecipient, key)
+	}
+}
+
+// notify renders the message in the recipient's language and hands it to the bot bridge.
+func (s *NotificationService) notify(ctx context.Context, dealID uuid.UUID, party string, resolve func(context.Context, uuid.UUID) (models.Recipient, error), key string) {
+	rcpt, err := resolve(ctx, dealID)
+	if err != nil {
+		s.log.Warn("failed to resolve deal notification recipient",
+			zap.String("deal_id", dealID.String()),
+			zap.String("party", party),
+			zap.Error(err),
+		)
+		return
+	}
+	if rcpt.NotifyTelegramID() == 0 {
+		return
+	}
+	text := events.Message(rcpt.LanguageCode, key, shortDealID(dealID))
+	if s.cfg.WebAppURL != "" {
+		text += "\n" + s.cfg.WebAppURL + "/deals/" + dealID.String()
+	}
+	_ = s.publisher.Publish(ctx, "events:bot", events.New(events.EventBotNotification, events.Notification{
+		TelegramUserID: rcpt.TelegramID,
+		Text:           text,
+	}))
+}
+
+// shortDealID trims a deal id to its first block for human-readable messages.
+func shortDealID(id uuid.UUID) string {
+	return id.String()[:8]
+}
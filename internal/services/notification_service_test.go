@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/ads-marketplace/backend/internal/events"
+	"github.com/ads-marketplace/backend/internal/models"
+)
+
+func TestDealStatusParties(t *testing.T) {
+	tests := []struct {
+		status  string
+		parties int
+	}{
+		{models.DealStatusSubmitted, notifyOwner},
+		{models.DealStatusAccepted, notifyAdvertiser},
+		{models.DealStatusRejected, notifyAdvertiser},
+		{models.DealStatusFunded, notifyOwner},
+		{models.DealStatusCreativeSubmitted, notifyAdvertiser},
+		{models.DealStatusCreativeChangesRequested, notifyOwner},
+		{models.DealStatusCreativeApproved, notifyOwner},
+		{models.DealStatusPosted, notifyAdvertiser},
+		{models.DealStatusDisputed, notifyBoth},
+		{models.DealStatusCompleted, notifyBoth},
+		{models.DealStatusRefunded, notifyBoth},
+		{models.DealStatusCancelled, notifyBoth},
+		// Follow-ups of another notified change, or internal states
+		{models.DealStatusAwaitingPayment, 0},
+		{models.DealStatusHoldVerification, 0},
+		{models.DealStatusReleaseFailed, 0},
+		{"unknown", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			parties := dealStatusParties(tt.status)
+			if parties != tt.parties {
+				t.Errorf("parties = %b, want %b", parties, tt.parties)
+			}
+			if parties != 0 && !events.HasMessage(events.MsgDealStatusChangedBase+tt.status) {
+				t.Errorf("no message for notified status %q", tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ads-marketplace/backend/internal/clock"
+	"github.com/ads-marketplace/backend/internal/config"
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/ads-marketplace/backend/internal/repositories"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// OfferService handles owner-initiated offers; accepted offers become deals via DealService.
+type OfferService struct {
+	offerRepo   *repositories.OfferRepo
+	channelRepo *repositories.ChannelRepo
+	auditRepo   *repositories.AuditRepo
+	dealService *DealService
+	clock       clock.Clock
+	cfg         *config.Config
+	log         *zap.Logger
+}
+
+func NewOfferService(
+	offerRepo *repositories.OfferRepo,
+	channelRepo *repositories.ChannelRepo,
+	auditRepo *repositories.AuditRepo,
+	dealService *DealService,
+	clk clock.Clock,
+	cfg *config.Config,
+	log *zap.Logger,
+) *OfferService {
+	return &OfferService{
+		offerRepo:   offerRepo,
+		channelRepo: channelRepo,
+		auditRepo:   auditRepo,
+		dealService: dealService,
+		clock:       clk,
+		cfg:         cfg,
+		log:         log,
+	}
+}
+
+type CreateOfferInput struct {
+	ChannelID        uuid.UUID
+	AdvertiserUserID *uuid.UUID // nil = public offer
+	AdFormat         string
+	PriceTON         string
+	Brief            *string
+	ScheduledAt      *time.Time
+	ValidUntil       time.Time
+}
+
+// CreateOffer publishes an offer on behalf of a channel member (owner or manager).
+func (s *OfferService) CreateOffer(ctx context.Context, actorID uuid.UUID, input CreateOfferInput) (*models.DealOffer, error) {
+	if err := s.dealService.checkChannelRole(ctx, input.ChannelID, actorID, false); err != nil {
+		return nil, err
+	}
+	if !models.IsValidAdFormat(input.AdFormat) {
+		return nil, fmt.Errorf("invalid ad format %q, must be one of: post, repost, story", input.AdFormat)
+	}
+	listing, err := s.channelRepo.GetListing(ctx, input.ChannelID)
+	if err != nil {
+		return nil, fmt.Errorf("channel listing not found: %w", err)
+	}
+	if !listing.IsFormatEnabled(input.AdFormat) {
+		return nil, fmt.Errorf("ad format %q is not enabled for this channel (available: %v)", input.AdFormat, listing.FormatsEnabled)
+	}
+	price, ok := new(big.Rat).SetString(input.PriceTON)
+	if !ok || price.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid price_ton %q", input.PriceTON)
+	}
+
+	now := s.clock.Now()
+	if !input.ValidUntil.After(now) {
+		return nil, ErrValidUntilInPast
+	}
+	if input.AdvertiserUserID != nil && *input.AdvertiserUserID == actorID {
+		return nil, ErrOfferToSelf
+	}
+	if input.ScheduledAt != nil {
+		if !input.ScheduledAt.After(now) {
+			return nil, ErrScheduledAtInPast
+		}
+		if err := s.dealService.checkSlotFree(ctx, input.ChannelID, *input.ScheduledAt, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	offer := &models.DealOffer{
+		ChannelID:        input.ChannelID,
+		CreatedBy:        actorID,
+		AdvertiserUserID: input.AdvertiserUserID,
+		AdFormat:         input.AdFormat,
+		PriceTON:         input.PriceTON,
+		Brief:            input.Brief,
+		ScheduledAt:      input.ScheduledAt,
+		ValidUntil:       input.ValidUntil,
+		Status:           models.OfferStatusOpen,
+	}
+	if err := s.offerRepo.Create(ctx, offer); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &actorID,
+		ActorType:   "user",
+		Action:      "offer_created",
+		EntityType:  "channel",
+		EntityID:    &offer.ChannelID,
+		Meta: map[string]any{
+			"offer_id":    offer.ID.String(),
+			"ad_format":   offer.AdFormat,
+			"price_ton":   offer.PriceTON,
+			"valid_until": offer.ValidUntil,
+			"public":      offer.AdvertiserUserID == nil,
+		},
+	})
+
+	return offer, nil
+}
+
+func (s *OfferService) ListChannelOffers(ctx context.Context, channelID, actorID uuid.UUID, limit, offset int) ([]models.DealOffer, error) {
+	if err := s.dealService.checkChannelRole(ctx, channelID, actorID, false); err != nil {
+		return nil, err
+	}
+	return s.offerRepo.ListByChannel(ctx, channelID, limit, offset)
+}
+
+// ListAvailableOffers returns open offers the user can accept (addressed to them or public).
+func (s *OfferService) ListAvailableOffers(ctx context.Context, userID uuid.UUID, limit, offset int) ([]models.DealOffer, error) {
+	return s.offerRepo.ListAvailable(ctx, userID, limit, offset)
+}
+
+func (s *OfferService) CancelOffer(ctx context.Context, offerID, actorID uuid.UUID) error {
+	offer, err := s.offerRepo.GetByID(ctx, offerID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrOfferNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.dealService.checkChannelRole(ctx, offer.ChannelID, actorID, false); err != nil {
+		return err
+	}
+	err = s.offerRepo.Cancel(ctx, offerID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return fmt.Errorf("offer is %s", offer.Status)
+	}
+	if err != nil {
+		return err
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &actorID,
+		ActorType:   "user",
+		Action:      "offer_cancelled",
+		EntityType:  "channel",
+		EntityID:    &offer.ChannelID,
+		Meta:        map[string]any{"offer_id": offerID.String()},
+	})
+	return nil
+}
+
+// AcceptOffer claims the offer and creates a deal with its terms (awaiting payment).
+// If the deal cannot be created the offer is reopened.
+func (s *OfferService) AcceptOffer(ctx context.Context, offerID, advertiserID uuid.UUID) (*models.Deal, error) {
+	offer, err := s.offerRepo.GetByID(ctx, offerID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrOfferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := offer.CanBeAcceptedBy(advertiserID, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	err = s.offerRepo.Claim(ctx, offerID, advertiserID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrOfferUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	deal, err := s.dealService.CreateDealFromOffer(ctx, offer, advertiserID)
+	if err != nil {
+		if relErr := s.offerRepo.Release(ctx, offerID); relErr != nil {
+			s.log.Error("failed to reopen offer", zap.String("offer_id", offerID.String()), zap.Error(relErr))
+		}
+		return nil, err
+	}
+	if err := s.offerRepo.SetDeal(ctx, offerID, deal.ID); err != nil {
+		s.log.Error("failed to link offer to deal", zap.String("offer_id", offerID.String()), zap.Error(err))
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &advertiserID,
+		ActorType:   "user",
+		Action:      "offer_accepted",
+		EntityType:  "deal",
+		EntityID:    &deal.ID,
+		Meta:        map[string]any{"offer_id": offerID.String(), "price_ton": offer.PriceTON},
+	})
+
+	return deal, nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/ads-marketplace/backend/internal/repositories"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type UserService struct {
+	userRepo   *repositories.UserRepo
+	dealRepo   *repositories.DealRepo
+	walletRepo *repositories.WalletRepo
+	auditRepo  *repositories.AuditRepo
+	blockRepo  *repositories.BlockRepo
+	log        *zap.Logger
+}
+
+func NewUserService(
+	userRepo *repositories.UserRepo,
+	dealRepo *repositories.DealRepo,
+	walletRepo *repositories.WalletRepo,
+	auditRepo *repositories.AuditRepo,
+	blockRepo *repositories.BlockRepo,
+	log *zap.Logger,
+) *UserService {
+	return &UserService{
+		userRepo:   userRepo,
+		dealRepo:   dealRepo,
+		walletRepo: walletRepo,
+		auditRepo:  auditRepo,
+		blockRepo:  blockRepo,
+		log:        log,
+	}
+}
+
+// DeleteAccount удаляет аккаунт: профиль анонимизируется, кошельки отключаются,
+// audit trail псевдонимизируется. Сделки и escrow остаются привязаны по deal id.
+// Нельзя удалить аккаунт, пока есть незавершённые сделки или средства в escrow.
+func (s *UserService) DeleteAccount(ctx context.Context, userID uuid.UUID) error {
+	open, err := s.dealRepo.CountOpenDealsForUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if open > 0 {
+		return fmt.Errorf("account has %d open deal(s) — complete or cancel them before deleting the account", open)
+	}
+
+	if err := s.walletRepo.DeactivateAllWallets(ctx, userID); err != nil {
+		return err
+	}
+
+	// Audit redaction reads the telegram id, so it must run before the profile is anonymized
+	redacted, err := s.auditRepo.RedactUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to redact audit log: %w", err)
+	}
+	if err := s.userRepo.Anonymize(ctx, userID); err != nil {
+		return err
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorType:  "user",
+		Action:     "account_deleted",
+		EntityType: "user",
+		Meta:       map[string]any{"redacted_entries": redacted},
+	})
+
+	s.log.Info("account deleted", zap.Int("redacted_audit_entries", redacted))
+	return nil
+}
+
+// UpdateProfile сохраняет настройки пользователя (язык уведомлений, отказ от уведомлений).
+// Событий не публикует — только запись в audit log с изменёнными полями.
+func (s *UserService) UpdateProfile(ctx context.Context, userID uuid.UUID, languageCode *string, notificationsEnabled *bool) (*models.User, error) {
+	user, err := s.userRepo.UpdateProfile(ctx, userID, languageCode, notificationsEnabled)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := map[string]any{}
+	if languageCode != nil {
+		meta["language_code"] = *languageCode
+	}
+	if notificationsEnabled != nil {
+		meta["notifications_enabled"] = *notificationsEnabled
+	}
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &userID,
+		ActorType:   "user",
+		Action:      "profile_updated",
+		EntityType:  "user",
+		EntityID:    &userID,
+		Meta:        meta,
+	})
+	return user, nil
+}
+
+// BlockUser блокирует пользователя: новые сделки между ним и каналами блокирующего
+// невозможны, а их каналы пропадают из explore друг у друга. Уже идущие сделки не трогаем.
+func (s *UserService) BlockUser(ctx context.Context, userID, targetID uuid.UUID) error {
+	if userID == targetID {
+		return ErrCannotBlockSelf
+	}
+	if _, err := s.userRepo.GetByID(ctx, targetID); err != nil {
+		return err
+	}
+	if err := s.blockRepo.Block(ctx, userID, targetID); err != nil {
+		return err
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &userID,
+		ActorType:   "user",
+		Action:      "user_blocked",
+		EntityType:  "user",
+		EntityID:    &targetID,
+	})
+	return nil
+}
+
+// UnblockUser снимает блокировку; repositories.ErrNotFound, если её не было.
+func (s *UserService) UnblockUser(ctx context.Context, userID, targetID uuid.UUID) error {
+	if err := s.blockRepo.Unblock(ctx, userID, targetID); err != nil {
+		return err
+	}
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &userID,
+		ActorType:   "user",
+		Action:      "user_unblocked",
+		EntityType:  "user",
+		EntityID:    &targetID,
+	})
+	return nil
+}
+
+func (s *UserService) ListBlocks(ctx context.Context, userID uuid.UUID) ([]models.UserBlock, error) {
+	return s.blockRepo.ListByBlocker(ctx, userID)
+}
@@ -112,6 +112,39 @@ func (c *UserbotClient) GetMe(ctx context.Context) (*UserbotMe, error) {
 	return &me, nil
 }
 
+// UserbotStory is what the userbot sees of a channel story.
+type UserbotStory struct {
+	Exists bool `json:"exists"`
+	Views  *int `json:"views"`
+}
+
+// GetStory looks up a story of the channel by id. Exists is false once the story is
+// deleted or expired.
+func (c *UserbotClient) GetStory(ctx context.Context, username string, storyID int64) (*UserbotStory, error) {
+	url := fmt.Sprintf("%s/internal/stories/by-username/%s/%d", c.baseURL, username, storyID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("userbot service unavailable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("userbot returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var story UserbotStory
+	if err := json.NewDecoder(resp.Body).Decode(&story); err != nil {
+		return nil, err
+	}
+	return &story, nil
+}
+
 // GetStatsByUsername collects channel stats via the userbot by username.
 func (c *UserbotClient) GetStatsByUsername(ctx context.Context, username string) (*UserbotStats, error) {
 	url := fmt.Sprintf("%s/internal/stats/by-username/%s", c.baseURL, username)
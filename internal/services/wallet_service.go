@@ -1,21 +1,32 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/ads-marketplace/backend/internal/auth"
+	"github.com/ads-marketplace/backend/internal/clock"
 	"github.com/ads-marketplace/backend/internal/config"
 	"github.com/ads-marketplace/backend/internal/models"
 	"github.com/ads-marketplace/backend/internal/repositories"
 	"github.com/ads-marketplace/backend/internal/ton"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
 type WalletService struct {
 	walletRepo *repositories.WalletRepo
 	auditRepo  *repositories.AuditRepo
+	verifier   *ton.ProofVerifier
+	chain      ton.AccountKeyReader // nil — проверяем владение только по state_init
+	attempts   auth.AttemptStore
+	lockout    *auth.Lockout // after repeated proof failures
 	cfg        *config.Config
 	log        *zap.Logger
 }
@@ -23,20 +34,97 @@ type WalletService struct {
 func NewWalletService(
 	walletRepo *repositories.WalletRepo,
 	auditRepo *repositories.AuditRepo,
+	rdb *redis.Client,
+	chain ton.AccountKeyReader,
+	clk clock.Clock,
 	cfg *config.Config,
 	log *zap.Logger,
 ) *WalletService {
+	attempts := auth.NewRedisAttemptStore(rdb)
 	return &WalletService{
 		walletRepo: walletRepo,
 		auditRepo:  auditRepo,
+		verifier:   ton.NewProofVerifier(cfg.TONProofAllowedDomains, clk),
+		chain:      chain,
+		attempts:   attempts,
+		lockout:    auth.NewLockout(attempts, "wallet_connect", cfg.WalletMaxProofFailures, cfg.WalletLockout),
 		cfg:        cfg,
 		log:        log,
 	}
 }
 
+// allow applies a per-user limit per minute; Redis errors fail open like the HTTP rate limiter.
+func (s *WalletService) allow(ctx context.Context, action string, userID uuid.UUID, perMinute int) error {
+	ok, _, err := auth.Allow(ctx, s.attempts, "wallet_"+action+":"+userID.String(), perMinute, time.Minute)
+	if err != nil {
+		s.log.Warn("wallet rate limit check failed", zap.Error(err))
+		return nil
+	}
+	if !ok {
+		return ErrTooManyAttempts
+	}
+	return nil
+}
+
+// proofFailed counts a failed connect attempt and logs a security event on lockout.
+func (s *WalletService) proofFailed(ctx context.Context, userID uuid.UUID, reason error) {
+	locked, err := s.lockout.Fail(ctx, userID.String())
+	if err != nil {
+		s.log.Warn("failed to record wallet proof failure", zap.Error(err))
+		return
+	}
+	if !locked {
+		return
+	}
+	s.log.Warn("security: wallet connect locked out after repeated proof failures",
+		zap.String("user_id", userID.String()),
+		zap.Int("max_failures", s.cfg.WalletMaxProofFailures),
+		zap.Duration("cooldown", s.cfg.WalletLockout),
+		zap.NamedError("last_error", reason),
+	)
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &userID,
+		ActorType:   "user",
+		Action:      "wallet_connect_locked",
+		EntityType:  "user",
+		EntityID:    &userID,
+		Meta:        map[string]any{"cooldown_seconds": int(s.cfg.WalletLockout.Seconds()), "last_error": reason.Error()},
+	})
+}
+
+// verifyOwnership проверяет, что pubKeyHex — ключ кошелька по адресу. Для задеплоенного
+// кошелька ключ берём из блокчейна, для незадеплоенного — пересчитываем адрес из state_init.
+func (s *WalletService) verifyOwnership(ctx context.Context, workchain int32, addrHash []byte, pubKeyHex, stateInit string) error {
+	pubKey, err := hex.DecodeString(pubKeyHex)
+	if err != nil {
+		return fmt.Errorf("invalid public key hex: %w", err)
+	}
+
+	if s.chain != nil {
+		key, err := s.chain.AccountPublicKey(ctx, workchain, addrHash)
+		switch {
+		case err == nil:
+			if !bytes.Equal(key, pubKey) {
+				return ton.ErrPublicKeyMismatch
+			}
+			return nil
+		case errors.Is(err, ton.ErrAccountNotDeployed):
+		default:
+			// Lite server недоступен или контракт нестандартный — остаётся state_init
+			s.log.Warn("failed to read wallet public key from chain", zap.Error(err))
+		}
+	}
+	return ton.VerifyAddressOwnership(addrHash, workchain, pubKey, stateInit)
+}
+
 // GeneratePayload создаёт nonce для TON Proof.
 // Клиент передаёт его в tonconnect при подключении кошелька.
 func (s *WalletService) GeneratePayload(ctx context.Context, userID *uuid.UUID) (string, error) {
+	if userID != nil {
+		if err := s.allow(ctx, "payload", *userID, s.cfg.WalletPayloadPerMinute); err != nil {
+			return "", err
+		}
+	}
 	ttl := 5 * time.Minute
 	p, err := s.walletRepo.CreateProofPayload(ctx, userID, ttl)
 	if err != nil {
@@ -52,20 +140,40 @@ type ConnectWalletRequest struct {
 	Network         string    `json:"network"`          // "mainnet" / "testnet"
 	PublicKey       string    `json:"public_key"`       // hex
 	Proof           ton.Proof `json:"proof"`
+	StateInit       string    `json:"state_init"` // base64 BOC из TON Connect (walletStateInit)
+	Label           string    `json:"label"`      // необязательная подпись, например "withdraw"
 }
 
 func (s *WalletService) ConnectWallet(ctx context.Context, userID uuid.UUID, req ConnectWalletRequest) (*models.UserWallet, error) {
+	label := strings.TrimSpace(req.Label)
+	if len([]rune(label)) > models.MaxWalletLabelLength {
+		return nil, fmt.Errorf("label must be at most %d characters", models.MaxWalletLabelLength)
+	}
+
+	// 0. Лимиты: блокировка после серии неудачных проверок, затем частота попыток
+	if d, err := s.lockout.Locked(ctx, userID.String()); err == nil && d > 0 {
+		return nil, ErrWalletConnectLocked
+	}
+	if err := s.allow(ctx, "connect", userID, s.cfg.WalletConnectPerMinute); err != nil {
+		return nil, err
+	}
+
 	// 1. Consume payload (nonce) — защита от replay
 	_, err := s.walletRepo.ConsumeProofPayload(ctx, req.Proof.Payload)
 	if err != nil {
-		return nil, fmt.Errorf("invalid or expired proof payload (nonce): %w", err)
+		err = fmt.Errorf("invalid or expired proof payload (nonce): %w", err)
+		s.proofFailed(ctx, userID, err)
+		return nil, err
 	}
 
-	// 2. Парсим raw address
+	// 2. Парсим raw address и приводим к каноничной форме
 	workchain, addrHash, err := ton.ParseRawAddress(req.Address)
 	if err != nil {
 		return nil, fmt.Errorf("invalid TON address: %w", err)
 	}
+	if req.Address, err = ton.NormalizeAddress(req.Address); err != nil {
+		return nil, fmt.Errorf("invalid TON address: %w", err)
+	}
 
 	// 3. Проверяем network
 	expectedNetwork := s.cfg.TONNetwork
@@ -73,18 +181,34 @@ func (s *WalletService) ConnectWallet(ctx context.Context, userID uuid.UUID, req
 		return nil, fmt.Errorf("network mismatch: expected %s, got %s", expectedNetwork, req.Network)
 	}
 
+	// Friendly-форма нужна для выплат и отображения: если клиент её не прислал — считаем сами
+	// (non-bounceable, как у кошельков), иначе проверяем, что это тот же аккаунт
+	if req.AddressFriendly == "" || req.AddressFriendly == req.Address || strings.Contains(req.AddressFriendly, ":") {
+		req.AddressFriendly, err = ton.RawToFriendly(workchain, addrHash, false, expectedNetwork == "testnet")
+		if err != nil {
+			return nil, fmt.Errorf("invalid TON address: %w", err)
+		}
+	} else if !ton.SameAddress(req.AddressFriendly, req.Address) {
+		return nil, fmt.Errorf("address_friendly does not match address")
+	}
+
 	// 4. Верифицируем TON Proof подпись
-	err = ton.VerifyProof(req.PublicKey, addrHash, workchain, req.Proof, s.cfg.TONProofAllowedDomains)
+	err = s.verifier.Verify(req.PublicKey, addrHash, workchain, req.Proof)
 	if err != nil {
-		return nil, fmt.Errorf("TON Proof verification failed: %w", err)
+		err = fmt.Errorf("TON Proof verification failed: %w", err)
+		s.proofFailed(ctx, userID, err)
+		return nil, err
 	}
 
-	// 5. Деактивируем предыдущие кошельки этого юзера
-	if err := s.walletRepo.DeactivateAllWallets(ctx, userID); err != nil {
-		s.log.Warn("failed to deactivate old wallets", zap.Error(err))
+	// 4a. Подпись доказывает только владение ключом — проверяем, что ключ от этого адреса
+	if err := s.verifyOwnership(ctx, workchain, addrHash, req.PublicKey, req.StateInit); err != nil {
+		err = fmt.Errorf("wallet ownership verification failed: %w", err)
+		s.proofFailed(ctx, userID, err)
+		return nil, err
 	}
+	_ = s.lockout.Reset(ctx, userID.String())
 
-	// 6. Сохраняем новый кошелёк
+	// 5. Сохраняем кошелёк рядом с уже подключёнными; первый становится основным
 	wallet := &models.UserWallet{
 		UserID:          userID,
 		Address:         req.Address,
@@ -98,15 +222,20 @@ func (s *WalletService) ConnectWallet(ctx context.Context, userID uuid.UUID, req
 		Verified:        true,
 		IsActive:        true,
 	}
+	if label != "" {
+		wallet.Label = &label
+	}
 
 	if err := s.walletRepo.ConnectWallet(ctx, wallet); err != nil {
 		return nil, fmt.Errorf("failed to save wallet: %w", err)
 	}
 
-	// 7. Обновляем кеш в users.wallet_address
-	_ = s.walletRepo.UpdateUserWalletAddress(ctx, userID, req.AddressFriendly)
+	// 6. Обновляем кеш в users.wallet_address (там всегда основной кошелёк)
+	if wallet.IsPrimary {
+		_ = s.walletRepo.UpdateUserWalletAddress(ctx, userID, req.AddressFriendly)
+	}
 
-	// 8. Audit log
+	// 7. Audit log
 	_ = s.auditRepo.Log(ctx, models.AuditLog{
 		ActorUserID: &userID,
 		ActorType:   "user",
@@ -124,7 +253,7 @@ func (s *WalletService) ConnectWallet(ctx context.Context, userID uuid.UUID, req
 	return wallet, nil
 }
 
-// DisconnectWallet отключает активный кошелёк пользователя.
+// DisconnectWallet отключает все кошельки пользователя.
 func (s *WalletService) DisconnectWallet(ctx context.Context, userID uuid.UUID) error {
 	if err := s.walletRepo.DeactivateAllWallets(ctx, userID); err != nil {
 		return err
@@ -142,7 +271,63 @@ func (s *WalletService) DisconnectWallet(ctx context.Context, userID uuid.UUID)
 	return nil
 }
 
-// GetActiveWallet возвращает текущий активный кошелёк.
+// DisconnectWalletByID отключает один кошелёк; если он был основным, основным становится
+// последний подключённый из оставшихся.
+func (s *WalletService) DisconnectWalletByID(ctx context.Context, userID, walletID uuid.UUID) error {
+	if err := s.walletRepo.DisconnectWallet(ctx, userID, walletID); err != nil {
+		return err
+	}
+	s.syncWalletAddress(ctx, userID)
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &userID,
+		ActorType:   "user",
+		Action:      "wallet_disconnected",
+		EntityType:  "user_wallet",
+		EntityID:    &walletID,
+	})
+	return nil
+}
+
+// GetActiveWallet возвращает основной кошелёк.
 func (s *WalletService) GetActiveWallet(ctx context.Context, userID uuid.UUID) (*models.UserWallet, error) {
 	return s.walletRepo.GetActiveWallet(ctx, userID)
 }
+
+// ListWallets возвращает все подключённые кошельки, основной первым.
+func (s *WalletService) ListWallets(ctx context.Context, userID uuid.UUID) ([]models.UserWallet, error) {
+	return s.walletRepo.ListWallets(ctx, userID)
+}
+
+// SetPrimaryWallet делает кошелёк основным.
+func (s *WalletService) SetPrimaryWallet(ctx context.Context, userID, walletID uuid.UUID) (*models.UserWallet, error) {
+	wallet, err := s.walletRepo.SetPrimaryWallet(ctx, userID, walletID)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.walletRepo.UpdateUserWalletAddress(ctx, userID, wallet.AddressFriendly)
+
+	_ = s.auditRepo.Log(ctx, models.AuditLog{
+		ActorUserID: &userID,
+		ActorType:   "user",
+		Action:      "wallet_primary_changed",
+		EntityType:  "user_wallet",
+		EntityID:    &walletID,
+		Meta:        map[string]any{"address": wallet.AddressFriendly},
+	})
+	return wallet, nil
+}
+
+// syncWalletAddress приводит кеш users.wallet_address к текущему основному кошельку.
+func (s *WalletService) syncWalletAddress(ctx context.Context, userID uuid.UUID) {
+	primary, err := s.walletRepo.GetActiveWallet(ctx, userID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		_ = s.walletRepo.ClearUserWalletAddress(ctx, userID)
+		return
+	}
+	if err != nil {
+		s.log.Warn("failed to sync wallet address", zap.Error(err))
+		return
+	}
+	_ = s.walletRepo.UpdateUserWalletAddress(ctx, userID, primary.AddressFriendly)
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ads-marketplace/backend/internal/config"
+	"github.com/ads-marketplace/backend/internal/events"
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/ads-marketplace/backend/internal/repositories"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Deliveries carry "X-Webhook-Timestamp: <unix seconds>" and
+// "X-Webhook-Signature: sha256=<hex HMAC-SHA256>" keyed by the webhook secret over
+// "<timestamp>.<body>"; receivers should also reject stale timestamps.
+const (
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+
+	maxWebhooksPerUser = 10
+
+	// webhookBatchSize caps the deliveries one worker run sends; the rest wait for the next tick.
+	webhookBatchSize = 50
+)
+
+var errWebhookPrivateAddress = errors.New("webhook host resolves to a non-public address")
+
+// WebhookService manages users' deal webhooks and delivers terminal deal events to them.
+type WebhookService struct {
+	webhookRepo *repositories.WebhookRepo
+	dealRepo    *repositories.DealRepo
+	escrowRepo  *repositories.EscrowRepo
+	httpClient  *http.Client
+	cfg         *config.Config
+	log         *zap.Logger
+}
+
+func NewWebhookService(
+	webhookRepo *repositories.WebhookRepo,
+	dealRepo *repositories.DealRepo,
+	escrowRepo *repositories.EscrowRepo,
+	cfg *config.Config,
+	log *zap.Logger,
+) *WebhookService {
+	// Only public addresses: the URL is user-supplied and checked again after DNS resolution
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !models.PublicIP(ip) {
+				return errWebhookPrivateAddress
+			}
+			return nil
+		},
+	}
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		dealRepo:    dealRepo,
+		escrowRepo:  escrowRepo,
+		httpClient: &http.Client{
+			Timeout:   cfg.WebhookTimeout,
+			Transport: &http.Transport{DialContext: dialer.DialContext},
+			// A redirect is a failed delivery, not a way around the address check
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		cfg: cfg,
+		log: log,
+	}
+}
+
+// Register stores a webhook for the given events (none = all terminal statuses) and
+// returns it with its secret; the secret isn't shown again.
+func (s *WebhookService) Register(ctx context.Context, userID uuid.UUID, url string, eventNames []string) (*models.Webhook, error) {
+	url = strings.TrimSpace(url)
+	if !models.ValidWebhookURL(url) {
+		return nil, ErrInvalidWebhookURL
+	}
+	evts, ok := models.NormalizeWebhookEvents(eventNames)
+	if !ok {
+		return nil, ErrInvalidWebhookEvents
+	}
+	n, err := s.webhookRepo.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if n >= maxWebhooksPerUser {
+		return nil, ErrWebhookLimit
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	w := &models.Webhook{
+		UserID: userID,
+		URL:    url,
+		Secret: hex.EncodeToString(secret),
+		Events: evts,
+	}
+	if err := s.webhookRepo.Create(ctx, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// List returns the user's webhooks without their secrets.
+func (s *WebhookService) List(ctx context.Context, userID uuid.UUID) ([]models.Webhook, error) {
+	list, err := s.webhookRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Secret = ""
+	}
+	return list, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	err := s.webhookRepo.Delete(ctx, id, userID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrWebhookNotFound
+	}
+	return err
+}
+
+// ListDeliveries returns recent delivery attempts of the user's webhook.
+func (s *WebhookService) ListDeliveries(ctx context.Context, userID, id uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	return s.webhookRepo.ListDeliveries(ctx, id, userID, limit)
+}
+
+// webhookPayload is the JSON body POSTed to a webhook.
+type webhookPayload struct {
+	Event          string    `json:"event"` // deal.completed / deal.refunded / deal.cancelled
+	DealID         string    `json:"deal_id"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status"`
+	ChannelID      string    `json:"channel_id"`
+	PriceTON       string    `json:"price_ton"`
+	Currency       string    `json:"currency"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+// HandleDealEvent queues a deal_status_changed event that moved a deal into a terminal
+// status for the parties' webhooks; the worker delivers it (ProcessDeliveries).
+func (s *WebhookService) HandleDealEvent(ctx context.Context, event events.Event) {
+	if event.Type != events.EventDealStatusChanged {
+		return
+	}
+	var payload events.DealStatusChangedPayload
+	if err := event.Decode(&payload); err != nil {
+		s.log.Warn("malformed deal event", zap.Error(err))
+		return
+	}
+	status := payload.NewStatus
+	if !slices.Contains(models.WebhookEvents, status) {
+		return
+	}
+	dealID, err := uuid.Parse(payload.DealID)
+	if err != nil {
+		return
+	}
+
+	hooks, err := s.webhookRepo.ListForDeal(ctx, dealID, status)
+	if err != nil {
+		s.log.Error("failed to list webhooks", zap.String("deal_id", dealID.String()), zap.Error(err))
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+	deal, err := s.dealRepo.GetByID(ctx, dealID)
+	if err != nil {
+		s.log.Error("failed to load deal for webhook", zap.String("deal_id", dealID.String()), zap.Error(err))
+		return
+	}
+	// The deal is paid in its escrow's currency; one cancelled before payment has no escrow
+	// and would have been paid in the default currency
+	currency := s.cfg.DefaultCurrency
+	escrow, err := s.escrowRepo.GetByDealID(ctx, dealID)
+	if err == nil {
+		currency = escrow.Currency
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		s.log.Error("failed to load escrow for webhook", zap.String("deal_id", dealID.String()), zap.Error(err))
+		return
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		Event:          "deal." + status,
+		DealID:         deal.ID.String(),
+		Status:         status,
+		PreviousStatus: payload.OldStatus,
+		ChannelID:      deal.ChannelID.String(),
+		PriceTON:       deal.PriceTON,
+		Currency:       currency,
+		OccurredAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		return
+	}
+	for _, hook := range hooks {
+		if err := s.webhookRepo.QueueDelivery(ctx, hook.ID, dealID, status, body); err != nil {
+			s.log.Error("failed to queue webhook delivery", zap.String("webhook_id", hook.ID.String()), zap.Error(err))
+		}
+	}
+}
+
+// ProcessDeliveries sends the due webhook deliveries of the outbox, concurrently. A failed
+// delivery is retried after models.ReleaseRetryDelay until WEBHOOK_MAX_ATTEMPTS.
+func (s *WebhookService) ProcessDeliveries(ctx context.Context) error {
+	now := time.Now()
+	// A claimed delivery comes back after the request timeout and a margin if this run dies
+	queued, err := s.webhookRepo.ClaimDueDeliveries(ctx, now, now.Add(s.cfg.WebhookTimeout+time.Minute), webhookBatchSize)
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	for _, d := range queued {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.deliver(ctx, d)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// deliver makes one attempt at a queued delivery and records it. The delivery leaves the
+// outbox once it succeeds or runs out of attempts; otherwise it is rescheduled.
+func (s *WebhookService) deliver(ctx context.Context, d models.QueuedWebhookDelivery) {
+	attempt := d.Attempts + 1
+	code, err := s.post(ctx, d.Webhook, d.Payload)
+	if ctx.Err() != nil {
+		// Shutting down: not the receiver's fault, the claim expires and it is tried again
+		return
+	}
+	// Bookkeeping must not be lost to a shutdown that starts right now
+	ctx = context.WithoutCancel(ctx)
+
+	rec := &models.WebhookDelivery{
+		WebhookID: d.Webhook.ID,
+		DealID:    d.DealID,
+		Event:     d.Event,
+		Attempt:   attempt,
+		Delivered: err == nil,
+	}
+	if code != 0 {
+		rec.StatusCode = &code
+	}
+	if err != nil {
+		msg := err.Error()
+		rec.Error = &msg
+	}
+	if recErr := s.webhookRepo.RecordDelivery(ctx, rec); recErr != nil {
+		s.log.Warn("failed to record webhook delivery", zap.String("webhook_id", d.Webhook.ID.String()), zap.Error(recErr))
+	}
+
+	if err != nil {
+		s.log.Warn("webhook delivery failed",
+			zap.String("webhook_id", d.Webhook.ID.String()),
+			zap.String("deal_id", d.DealID.String()),
+			zap.Int("attempt", attempt),
+			zap.Error(err),
+		)
+	}
+	if err != nil && attempt < s.cfg.WebhookMaxAttempts {
+		next := time.Now().Add(models.ReleaseRetryDelay(attempt, s.cfg.WebhookRetryBase, s.cfg.WebhookRetryMax))
+		if err := s.webhookRepo.RescheduleDelivery(ctx, d.ID, attempt, next); err != nil {
+			s.log.Error("failed to reschedule webhook delivery", zap.String("id", d.ID.String()), zap.Error(err))
+		}
+		return
+	}
+	if err := s.webhookRepo.DeleteQueuedDelivery(ctx, d.ID); err != nil {
+		s.log.Error("failed to remove webhook delivery from the outbox", zap.String("id", d.ID.String()), zap.Error(err))
+	}
+}
+
+// post sends one signed delivery; returns the response status (0 if none) and an error
+// unless it was 2xx.
+func (s *WebhookService) post(ctx context.Context, hook models.Webhook, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	ts := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(ts.Unix(), 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(hook.Secret, ts, body))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload returns the WebhookSignatureHeader value for body sent at ts.
+func SignWebhookPayload(secret string, ts time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(ts.Unix(), 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	body := []byte(`{"event":"deal.completed"}`)
+
+	// Computed independently: HMAC-SHA256("whsec", "1700000000." + body)
+	want := "sha256=ebaa64e3d2027a239e46933b0a69847573bcc3fb0c4966ae8ed2c0ca019e7857"
+	if got := SignWebhookPayload("whsec", ts, body); got != want {
+		t.Errorf("SignWebhookPayload = %s, want %s", got, want)
+	}
+
+	if SignWebhookPayload("other", ts, body) == want {
+		t.Error("signature does not depend on the secret")
+	}
+	if SignWebhookPayload("whsec", ts.Add(time.Second), body) == want {
+		t.Error("signature does not depend on the timestamp")
+	}
+	if SignWebhookPayload("whsec", ts, []byte(`{"event":"deal.refunded"}`)) == want {
+		t.Error("signature does not depend on the body")
+	}
+}
@@ -0,0 +1,104 @@
+package statsparser
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// PostFetcher is the part of Parser the post monitor needs.
+type PostFetcher interface {
+	FetchPostContent(ctx context.Context, username string, messageID int64) (string, bool, error)
+}
+
+// PostCheck is one post to re-fetch from t.me.
+type PostCheck struct {
+	Username  string
+	MessageID int64
+}
+
+// PostResult is what FetchPostContent returned for a PostCheck.
+type PostResult struct {
+	Text   string
+	Exists bool
+	Err    error
+}
+
+// CheckPosts fetches checks with at most concurrency requests in flight, and starts at most
+// one request per channel every perChannel so a busy channel doesn't get us rate-limited
+// by t.me. handle is called from the worker goroutines with the index of the check, so it
+// must be safe for concurrent use. Returns once every started check is handled, or early
+// (skipping the rest) when ctx is cancelled.
+func CheckPosts(ctx context.Context, f PostFetcher, checks []PostCheck, concurrency int, perChannel time.Duration, handle func(i int, r PostResult)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	limiter := newChannelLimiter(perChannel)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				c := checks[i]
+				if err := limiter.wait(ctx, c.Username); err != nil {
+					return
+				}
+				text, exists, err := f.FetchPostContent(ctx, c.Username, c.MessageID)
+				if ctx.Err() != nil {
+					return
+				}
+				handle(i, PostResult{Text: text, Exists: exists, Err: err})
+			}
+		}()
+	}
+
+dispatch:
+	for i := range checks {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// channelLimiter is a per-channel token bucket holding a single token: each request
+// reserves the next free slot for its channel and sleeps until then.
+type channelLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     map[string]time.Time
+}
+
+func newChannelLimiter(interval time.Duration) *channelLimiter {
+	return &channelLimiter{interval: interval, next: make(map[string]time.Time)}
+}
+
+func (l *channelLimiter) wait(ctx context.Context, channel string) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next[channel]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[channel] = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
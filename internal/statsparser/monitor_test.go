@@ -0,0 +1,137 @@
+package statsparser
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeFetcher records how many fetches overlap and when each channel was hit.
+type fakeFetcher struct {
+	delay    time.Duration
+	inFlight atomic.Int32
+	maxSeen  atomic.Int32
+
+	mu     sync.Mutex
+	starts map[string][]time.Time
+}
+
+func (f *fakeFetcher) FetchPostContent(ctx context.Context, username string, messageID int64) (string, bool, error) {
+	n := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	for {
+		m := f.maxSeen.Load()
+		if n <= m || f.maxSeen.CompareAndSwap(m, n) {
+			break
+		}
+	}
+
+	f.mu.Lock()
+	if f.starts == nil {
+		f.starts = make(map[string][]time.Time)
+	}
+	f.starts[username] = append(f.starts[username], time.Now())
+	f.mu.Unlock()
+
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+	}
+	return fmt.Sprintf("%s/%d", username, messageID), true, nil
+}
+
+func TestCheckPostsBoundedConcurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		checks      int
+	}{
+		{"single worker", 1, 6},
+		{"three workers", 3, 12},
+		{"more workers than checks", 10, 4},
+		{"zero falls back to one", 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeFetcher{delay: 10 * time.Millisecond}
+			checks := make([]PostCheck, tt.checks)
+			for i := range checks {
+				checks[i] = PostCheck{Username: fmt.Sprintf("ch%d", i), MessageID: int64(i)}
+			}
+
+			var handled atomic.Int32
+			CheckPosts(context.Background(), f, checks, tt.concurrency, 0, func(i int, r PostResult) {
+				if want := fmt.Sprintf("ch%d/%d", i, i); r.Text != want {
+					t.Errorf("result %d text = %q, want %q", i, r.Text, want)
+				}
+				handled.Add(1)
+			})
+
+			limit := max(tt.concurrency, 1)
+			if got := int(f.maxSeen.Load()); got > limit {
+				t.Errorf("max in flight = %d, want <= %d", got, limit)
+			}
+			if got := int(handled.Load()); got != tt.checks {
+				t.Errorf("handled = %d, want %d", got, tt.checks)
+			}
+		})
+	}
+}
+
+func TestCheckPostsPerChannelSpacing(t *testing.T) {
+	const interval = 30 * time.Millisecond
+	f := &fakeFetcher{}
+	checks := []PostCheck{
+		{Username: "a", MessageID: 1},
+		{Username: "a", MessageID: 2},
+		{Username: "a", MessageID: 3},
+		{Username: "b", MessageID: 1},
+	}
+
+	CheckPosts(context.Background(), f, checks, 4, interval, func(int, PostResult) {})
+
+	starts := f.starts["a"]
+	if len(starts) != 3 {
+		t.Fatalf("channel a fetched %d times, want 3", len(starts))
+	}
+	for i := 1; i < len(starts); i++ {
+		// Allow a little timer slack
+		if gap := starts[i].Sub(starts[i-1]); gap < interval-5*time.Millisecond {
+			t.Errorf("gap between fetches %d and %d of channel a = %v, want >= %v", i-1, i, gap, interval)
+		}
+	}
+	if len(f.starts["b"]) != 1 {
+		t.Errorf("channel b fetched %d times, want 1", len(f.starts["b"]))
+	}
+}
+
+func TestCheckPostsStopsOnCancel(t *testing.T) {
+	f := &fakeFetcher{delay: 20 * time.Millisecond}
+	checks := make([]PostCheck, 50)
+	for i := range checks {
+		checks[i] = PostCheck{Username: "same", MessageID: int64(i)}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan struct{})
+	var handled atomic.Int32
+	go func() {
+		CheckPosts(ctx, f, checks, 5, 10*time.Millisecond, func(int, PostResult) { handled.Add(1) })
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("CheckPosts did not return after cancellation")
+	}
+	if int(handled.Load()) == len(checks) {
+		t.Error("all checks handled despite cancellation")
+	}
+}
@@ -2,7 +2,10 @@ package statsparser
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand/v2"
 	"net/http"
 	"regexp"
 	"strconv"
@@ -26,17 +29,40 @@ type ChannelStats struct {
 	Username      string     `json:"username"`
 	Subscribers   *int       `json:"subscribers,omitempty"`
 	VerifiedBadge bool       `json:"verified_badge"`
+	ScamBadge     bool       `json:"scam_badge"`
+	FakeBadge     bool       `json:"fake_badge"`
 	LastPosts     []PostStat `json:"last_posts"`
 	AvgViewsLast20 *int      `json:"avg_views_last_20,omitempty"`
+	PostsPerDay   *float64   `json:"posts_per_day,omitempty"`
+	HasPinnedPost bool       `json:"has_pinned_post"`
 	LangGuess     string     `json:"lang_guess"`
+	Description   *string    `json:"description,omitempty"`
+	PhotoURL      *string    `json:"photo_url,omitempty"`
 	FetchedAt     time.Time  `json:"fetched_at"`
 }
 
+// ErrRateLimited is returned when t.me still answers 429 after all retries (or asks us to
+// wait longer than we're willing to); callers should skip the channel for now rather than
+// count it as a failure.
+var ErrRateLimited = errors.New("t.me rate limited")
+
+// Retry schedule: base × 2^attempt capped at retryMaxDelay, ±retryJitter so channels that
+// failed together don't retry together. A 429 Retry-After overrides it up to retryAfterMax.
+const (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 8 * time.Second
+	retryJitter    = 0.2
+	retryAfterMax  = 30 * time.Second
+)
+
 type Parser struct {
 	httpClient *http.Client
 	log        *zap.Logger
 	timeout    time.Duration
 	maxRetries int
+	baseURL    string
+	retryBase  time.Duration
+	retryMax   time.Duration
 }
 
 func NewParser(timeoutMS, maxRetries int, log *zap.Logger) *Parser {
@@ -47,16 +73,27 @@ func NewParser(timeoutMS, maxRetries int, log *zap.Logger) *Parser {
 		log:        log,
 		timeout:    time.Duration(timeoutMS) * time.Millisecond,
 		maxRetries: maxRetries,
+		baseURL:    "https://t.me",
+		retryBase:  retryBaseDelay,
+		retryMax:   retryMaxDelay,
 	}
 }
 
 func (p *Parser) FetchAndParse(ctx context.Context, username string) (*ChannelStats, error) {
-	url := fmt.Sprintf("https://t.me/s/%s", username)
+	url := fmt.Sprintf("%s/s/%s", p.baseURL, username)
 
 	var doc *goquery.Document
 	var lastErr error
+	var wait time.Duration
 
 	for attempt := 0; attempt <= p.maxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepCtx(ctx, wait); err != nil {
+				return nil, err
+			}
+		}
+		wait = backoffDelay(attempt, p.retryBase, p.retryMax)
+
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 		if err != nil {
 			return nil, err
@@ -67,14 +104,24 @@ func (p *Parser) FetchAndParse(ctx context.Context, username string) (*ChannelSt
 		resp, err := p.httpClient.Do(req)
 		if err != nil {
 			lastErr = err
-			time.Sleep(time.Duration(attempt+1) * 500 * time.Millisecond)
+			continue
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			resp.Body.Close()
+			lastErr = fmt.Errorf("%w: HTTP 429 for %s", ErrRateLimited, url)
+			if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				if d > retryAfterMax {
+					break
+				}
+				wait = d
+			}
 			continue
 		}
 
 		if resp.StatusCode != http.StatusOK {
 			resp.Body.Close()
 			lastErr = fmt.Errorf("HTTP %d for %s", resp.StatusCode, url)
-			time.Sleep(time.Duration(attempt+1) * 500 * time.Millisecond)
 			continue
 		}
 
@@ -127,6 +174,12 @@ func (p *Parser) FetchAndParse(ctx context.Context, username string) (*ChannelSt
 	stats.VerifiedBadge = doc.Find(".tgme_channel_info_header_title .verified-icon").Length() > 0 ||
 		doc.Find(".tgme_channel_info_header_title i.verified-icon").Length() > 0
 
+	// Scam / fake labels
+	stats.ScamBadge, stats.FakeBadge = parseLabels(doc)
+
+	// Description + avatar
+	stats.Description, stats.PhotoURL = parseChannelInfo(doc)
+
 	// Parse posts
 	var allText strings.Builder
 	doc.Find(".tgme_widget_message_wrap").Each(func(i int, s *goquery.Selection) {
@@ -199,6 +252,13 @@ func (p *Parser) FetchAndParse(ctx context.Context, username string) (*ChannelSt
 		// The last post is the most recent
 	}
 
+	// Posting cadence + pinned post
+	stats.PostsPerDay = computePostFrequency(stats.LastPosts, stats.FetchedAt)
+	stats.HasPinnedPost = doc.Find(".tgme_channel_pinned_message, .tgme_widget_message_pinned").Length() > 0 ||
+		doc.Find(".tgme_widget_message.service_message .tgme_widget_message_text").FilterFunction(func(_ int, s *goquery.Selection) bool {
+			return strings.Contains(strings.ToLower(s.Text()), "pinned")
+		}).Length() > 0
+
 	// Language guess
 	stats.LangGuess = guessLanguage(allText.String())
 
@@ -207,7 +267,7 @@ func (p *Parser) FetchAndParse(ctx context.Context, username string) (*ChannelSt
 
 // FetchPostContent fetches a specific post page and returns its text content + hash.
 func (p *Parser) FetchPostContent(ctx context.Context, username string, messageID int64) (string, bool, error) {
-	url := fmt.Sprintf("https://t.me/%s/%d?embed=1", username, messageID)
+	url := fmt.Sprintf("%s/%s/%d?embed=1", p.baseURL, username, messageID)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return "", false, err
@@ -243,6 +303,104 @@ func (p *Parser) FetchPostContent(ctx context.Context, username string, messageI
 	return text, true, nil
 }
 
+// backoffDelay is the pause before retry attempt+1: base × 2^attempt, capped at maxDelay,
+// with ±retryJitter applied.
+func backoffDelay(attempt int, base, maxDelay time.Duration) time.Duration {
+	d := maxDelay
+	if attempt < 30 && base<<attempt < maxDelay {
+		d = base << attempt
+	}
+	return time.Duration(float64(d) * (1 + retryJitter*(2*rand.Float64()-1)))
+}
+
+// parseRetryAfter reads a Retry-After header, either delay-seconds or an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// parseLabels reports whether Telegram marks the channel with the "scam" or "fake" label,
+// shown next to the title on the channel page.
+func parseLabels(doc *goquery.Document) (scam, fake bool) {
+	labels := doc.Find(".tgme_channel_info_header_labels, .tgme_channel_info_header_title")
+	return labels.Find(".scam").Length() > 0, labels.Find(".fake").Length() > 0
+}
+
+// parseChannelInfo reads the channel description and avatar from the /s/ page header.
+// Either is nil when the channel has none: no description block, or the default
+// avatar, which t.me renders as a coloured letter instead of an <img>.
+func parseChannelInfo(doc *goquery.Document) (description, photoURL *string) {
+	desc := doc.Find(".tgme_channel_info_description").First()
+	desc.Find("br").ReplaceWithHtml("\n")
+	if text := strings.TrimSpace(desc.Text()); text != "" {
+		description = &text
+	}
+
+	src, _ := doc.Find(".tgme_page_photo_image img").First().Attr("src")
+	src = strings.TrimSpace(src)
+	if strings.HasPrefix(src, "https://") && !strings.Contains(src, "telegram.org/img/") {
+		photoURL = &src
+	}
+	return description, photoURL
+}
+
+// minPostsForFrequency — below this the cadence estimate is too noisy to report.
+const minPostsForFrequency = 3
+
+// computePostFrequency returns posts per day over the window covered by the dated posts
+// (oldest visible post → now). The window is clamped to at least one day so a burst of
+// posts published within a few hours doesn't produce an inflated rate.
+func computePostFrequency(posts []PostStat, now time.Time) *float64 {
+	var oldest time.Time
+	count := 0
+	for _, p := range posts {
+		if p.Date.IsZero() {
+			continue
+		}
+		count++
+		if oldest.IsZero() || p.Date.Before(oldest) {
+			oldest = p.Date
+		}
+	}
+	if count < minPostsForFrequency {
+		return nil
+	}
+
+	days := now.Sub(oldest).Hours() / 24
+	if days < 1 {
+		days = 1
+	}
+	freq := math.Round(float64(count)/days*100) / 100
+	return &freq
+}
+
 var viewCountRE = regexp.MustCompile(`[\d,.]+[KkMm]?`)
 
 func parseCount(text string) int {
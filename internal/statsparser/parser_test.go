@@ -1,6 +1,19 @@
 package statsparser
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"go.uber.org/zap"
+)
 
 func TestParseCount(t *testing.T) {
 	tests := []struct {
@@ -55,3 +68,263 @@ func TestGuessLanguage(t *testing.T) {
 		})
 	}
 }
+
+func TestComputePostFrequency(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(d float64) PostStat {
+		return PostStat{MessageID: 1, Date: now.Add(-time.Duration(d * float64(24*time.Hour)))}
+	}
+
+	tests := []struct {
+		name     string
+		posts    []PostStat
+		expected *float64
+	}{
+		{"no posts", nil, nil},
+		{"too few posts", []PostStat{daysAgo(1), daysAgo(0.5)}, nil},
+		{"undated posts ignored", []PostStat{{MessageID: 1}, {MessageID: 2}, daysAgo(1), daysAgo(0)}, nil},
+		{"one per day over 10 days", []PostStat{daysAgo(10), daysAgo(8), daysAgo(6), daysAgo(4), daysAgo(2)}, floatPtr(0.5)},
+		{"burst within a day clamps window", []PostStat{daysAgo(0.1), daysAgo(0.2), daysAgo(0.3), daysAgo(0.4)}, floatPtr(4)},
+		{"three posts over 2 days", []PostStat{daysAgo(2), daysAgo(1), daysAgo(0)}, floatPtr(1.5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := computePostFrequency(tt.posts, now)
+			switch {
+			case tt.expected == nil && result != nil:
+				t.Errorf("computePostFrequency() = %v, want nil", *result)
+			case tt.expected != nil && result == nil:
+				t.Errorf("computePostFrequency() = nil, want %v", *tt.expected)
+			case tt.expected != nil && *result != *tt.expected:
+				t.Errorf("computePostFrequency() = %v, want %v", *result, *tt.expected)
+			}
+		})
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func TestParseChannelInfo(t *testing.T) {
+	const header = `<div class="tgme_channel_info"><div class="tgme_channel_info_header">%s</div>%s</div>`
+	tests := []struct {
+		name      string
+		photo     string
+		desc      string
+		wantDesc  string
+		wantPhoto string
+	}{
+		{
+			name:      "description and avatar",
+			photo:     `<i class="tgme_page_photo_image"><img src="https://cdn4.telesco.pe/file/abc.jpg"></i>`,
+			desc:      `<div class="tgme_channel_info_description">Daily news<br/>Ads: @owner</div>`,
+			wantDesc:  "Daily news\nAds: @owner",
+			wantPhoto: "https://cdn4.telesco.pe/file/abc.jpg",
+		},
+		{
+			name:  "default letter avatar, no description",
+			photo: `<i class="tgme_page_photo_image bgcolor1" data-content="N"></i>`,
+		},
+		{
+			name:     "blank description",
+			desc:     `<div class="tgme_channel_info_description">   </div>`,
+			wantDesc: "",
+		},
+		{
+			name:  "telegram placeholder image",
+			photo: `<i class="tgme_page_photo_image"><img src="https://telegram.org/img/t_logo.png"></i>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := fmt.Sprintf(header, tt.photo, tt.desc)
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			desc, photo := parseChannelInfo(doc)
+			if got := deref(desc); got != tt.wantDesc {
+				t.Errorf("description = %q, want %q", got, tt.wantDesc)
+			}
+			if (desc == nil) != (tt.wantDesc == "") {
+				t.Errorf("description nil = %v, want nil for empty", desc == nil)
+			}
+			if got := deref(photo); got != tt.wantPhoto {
+				t.Errorf("photo = %q, want %q", got, tt.wantPhoto)
+			}
+		})
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		wantScam bool
+		wantFake bool
+	}{
+		{"no labels", `<div class="tgme_channel_info_header_title"><span>News</span></div>`, false, false},
+		{"verified only", `<div class="tgme_channel_info_header_title"><span>News</span><i class="verified-icon"></i></div>`, false, false},
+		{"scam", `<div class="tgme_channel_info_header_labels"><span class="tgme_channel_info_header_label scam">scam</span></div>`, true, false},
+		{"fake", `<div class="tgme_channel_info_header_labels"><span class="tgme_channel_info_header_label fake">fake</span></div>`, false, true},
+		{"label in title", `<div class="tgme_channel_info_header_title"><span>News</span><span class="scam">scam</span></div>`, true, false},
+		{"scam word in post text", `<div class="tgme_widget_message_text"><span class="scam">scam</span></div>`, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			scam, fake := parseLabels(doc)
+			if scam != tt.wantScam || fake != tt.wantFake {
+				t.Errorf("parseLabels = (%v, %v), want (%v, %v)", scam, fake, tt.wantScam, tt.wantFake)
+			}
+		})
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	const base, maxDelay = 500 * time.Millisecond, 8 * time.Second
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 500 * time.Millisecond},
+		{1, time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 8 * time.Second},
+		{64, 8 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempt %d", tt.attempt), func(t *testing.T) {
+			lo := time.Duration(float64(tt.want) * (1 - retryJitter))
+			hi := time.Duration(float64(tt.want) * (1 + retryJitter))
+			for range 50 {
+				if got := backoffDelay(tt.attempt, base, maxDelay); got < lo || got > hi {
+					t.Fatalf("backoffDelay = %v, want within [%v, %v]", got, lo, hi)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"empty", "", 0, false},
+		{"seconds", "5", 5 * time.Second, true},
+		{"zero", "0", 0, true},
+		{"negative", "-3", 0, false},
+		{"http date", now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{"date in the past", now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"garbage", "soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.header, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseRetryAfter(%q) = (%v, %v), want (%v, %v)", tt.header, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+const channelPage = `<div class="tgme_channel_info_counter"><span class="counter_value">1.2K</span><span class="counter_type">subscribers</span></div>`
+
+func newTestParser(url string, maxRetries int) *Parser {
+	p := NewParser(2000, maxRetries, zap.NewNop())
+	p.baseURL = url
+	p.retryBase = time.Millisecond
+	p.retryMax = 5 * time.Millisecond
+	return p
+}
+
+func TestFetchAndParseRetriesAfter429(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, channelPage)
+	}))
+	defer srv.Close()
+
+	stats, err := newTestParser(srv.URL, 3).FetchAndParse(context.Background(), "news")
+	if err != nil {
+		t.Fatalf("FetchAndParse: %v", err)
+	}
+	if stats.Subscribers == nil || *stats.Subscribers != 1200 {
+		t.Errorf("subscribers = %v, want 1200", stats.Subscribers)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestFetchAndParseRateLimited(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		wantHits   int32
+	}{
+		{"retries exhausted", "0", 3},
+		{"no header", "", 3},
+		{"retry-after too long", "3600", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hits.Add(1)
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(http.StatusTooManyRequests)
+			}))
+			defer srv.Close()
+
+			_, err := newTestParser(srv.URL, 2).FetchAndParse(context.Background(), "news")
+			if !errors.Is(err, ErrRateLimited) {
+				t.Fatalf("err = %v, want ErrRateLimited", err)
+			}
+			if got := hits.Load(); got != tt.wantHits {
+				t.Errorf("requests = %d, want %d", got, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestFetchAndParseServerErrorNotRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	_, err := newTestParser(srv.URL, 1).FetchAndParse(context.Background(), "news")
+	if err == nil || errors.Is(err, ErrRateLimited) {
+		t.Errorf("err = %v, want a non-rate-limit error", err)
+	}
+}
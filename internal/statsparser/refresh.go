@@ -0,0 +1,90 @@
+package statsparser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RefreshQueueKey is the Redis list of forced refreshes: the API pushes, the stats fetcher pops.
+const RefreshQueueKey = "stats:refresh_queue"
+
+// resultTTL keeps an unclaimed result around a little longer than any API request waits.
+const resultTTL = 2 * time.Minute
+
+// ErrRefreshTimeout is returned by RequestRefresh when the stats fetcher didn't answer in time.
+// The request stays queued and will still be served.
+var ErrRefreshTimeout = errors.New("stats refresh timed out")
+
+type RefreshRequest struct {
+	ID        string    `json:"id"`
+	ChannelID uuid.UUID `json:"channel_id"`
+}
+
+type RefreshResult struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+func refreshResultKey(id string) string {
+	return fmt.Sprintf("stats:refresh_result:%s", id)
+}
+
+// RequestRefresh queues a forced refresh of the channel and waits up to timeout for the
+// stats fetcher to report back.
+func RequestRefresh(ctx context.Context, rdb *redis.Client, channelID uuid.UUID, timeout time.Duration) (*RefreshResult, error) {
+	req := RefreshRequest{ID: uuid.NewString(), ChannelID: channelID}
+	data, _ := json.Marshal(req)
+	if err := rdb.RPush(ctx, RefreshQueueKey, data).Err(); err != nil {
+		return nil, err
+	}
+
+	vals, err := rdb.BLPop(ctx, timeout, refreshResultKey(req.ID)).Result()
+	if err == redis.Nil {
+		return nil, ErrRefreshTimeout
+	}
+	if err != nil {
+		return nil, err
+	}
+	var res RefreshResult
+	if err := json.Unmarshal([]byte(vals[1]), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// NextRefresh blocks up to wait for the next queued refresh. Returns nil (no error) if none arrived.
+func NextRefresh(ctx context.Context, rdb *redis.Client, wait time.Duration) (*RefreshRequest, error) {
+	vals, err := rdb.BLPop(ctx, wait, RefreshQueueKey).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var req RefreshRequest
+	if err := json.Unmarshal([]byte(vals[1]), &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// CompleteRefresh hands the outcome back to the API request waiting on it.
+func CompleteRefresh(ctx context.Context, rdb *redis.Client, id string, fetchErr error) error {
+	res := RefreshResult{OK: fetchErr == nil}
+	if fetchErr != nil {
+		res.Error = fetchErr.Error()
+	}
+	data, _ := json.Marshal(res)
+	key := refreshResultKey(id)
+	pipe := rdb.TxPipeline()
+	pipe.RPush(ctx, key, data)
+	pipe.Expire(ctx, key, resultTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,41 @@
+package statsparser
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeText lowercases s and reduces it to words: every run of characters that aren't
+// letters or digits (punctuation, emoji, line breaks) becomes a single space.
+func NormalizeText(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// TextSimilarity is the token overlap of two texts after NormalizeText: the Dice
+// coefficient over their word multisets, from 0 (nothing in common) to 1 (same words).
+// Word order is ignored, so reflowed or lightly reformatted posts still match.
+func TextSimilarity(a, b string) float64 {
+	ta := strings.Fields(NormalizeText(a))
+	tb := strings.Fields(NormalizeText(b))
+	if len(ta) == 0 && len(tb) == 0 {
+		return 1
+	}
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	counts := make(map[string]int, len(ta))
+	for _, t := range ta {
+		counts[t]++
+	}
+	common := 0
+	for _, t := range tb {
+		if counts[t] > 0 {
+			counts[t]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(ta)+len(tb))
+}
@@ -0,0 +1,48 @@
+package statsparser
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Hello, World!", "hello world"},
+		{"  Новый   канал 🔥\n\nПодписывайтесь: t.me/news  ", "новый канал подписывайтесь t me news"},
+		{"TON-2024", "ton 2024"},
+		{"!!!", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeText(tt.in); got != tt.want {
+			t.Errorf("NormalizeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTextSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{"identical", "Buy TON now", "Buy TON now", 1},
+		{"formatting only", "Buy TON now!", "buy\n\nton — NOW", 1},
+		{"word order ignored", "ton buy now", "now buy ton", 1},
+		{"half overlap", "a b c d", "a b x y", 0.5},
+		{"repeated words counted once each", "spam spam spam", "spam", 0.5},
+		{"unrelated", "crypto exchange promo", "cat pictures daily", 0},
+		{"one empty", "some text", "", 0},
+		{"both empty", "", "🔥", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TextSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("TextSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package ton
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// User-friendly адрес: 36 байт в base64url (48 символов) —
+// tag (1) | workchain (1, int8) | hash (32) | CRC16-XMODEM первых 34 байт (2, big-endian).
+// https://docs.ton.org/learn/overviews/addresses#user-friendly-address
+const (
+	friendlyTagBounceable    = 0x11
+	friendlyTagNonBounceable = 0x51
+	friendlyTagTestnet       = 0x80
+
+	friendlyAddressLen = 36
+)
+
+// RawToFriendly кодирует workchain + hash в user-friendly форму (EQ.../UQ... на mainnet,
+// kQ.../0Q... на testnet).
+func RawToFriendly(workchain int32, hash []byte, bounceable, testnet bool) (string, error) {
+	if len(hash) != 32 {
+		return "", fmt.Errorf("address hash must be 32 bytes, got %d", len(hash))
+	}
+	if workchain < -128 || workchain > 127 {
+		return "", fmt.Errorf("workchain %d does not fit the user-friendly format", workchain)
+	}
+
+	buf := make([]byte, friendlyAddressLen)
+	buf[0] = friendlyTagNonBounceable
+	if bounceable {
+		buf[0] = friendlyTagBounceable
+	}
+	if testnet {
+		buf[0] |= friendlyTagTestnet
+	}
+	buf[1] = byte(int8(workchain))
+	copy(buf[2:34], hash)
+	crc := crc16(buf[:34])
+	buf[34], buf[35] = byte(crc>>8), byte(crc)
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// FriendlyToRaw разбирает user-friendly адрес (base64url или обычный base64) и проверяет CRC.
+func FriendlyToRaw(friendly string) (workchain int32, hash []byte, bounceable, testnet bool, err error) {
+	s := strings.TrimRight(strings.TrimSpace(friendly), "=")
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(s)
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, nil, false, false, fmt.Errorf("invalid user-friendly address: %w", err)
+	}
+	if len(buf) != friendlyAddressLen {
+		return 0, nil, false, false, fmt.Errorf("user-friendly address must be %d bytes, got %d", friendlyAddressLen, len(buf))
+	}
+	if crc := crc16(buf[:34]); buf[34] != byte(crc>>8) || buf[35] != byte(crc) {
+		return 0, nil, false, false, fmt.Errorf("user-friendly address checksum mismatch")
+	}
+
+	tag := buf[0]
+	testnet = tag&friendlyTagTestnet != 0
+	switch tag &^ friendlyTagTestnet {
+	case friendlyTagBounceable:
+		bounceable = true
+	case friendlyTagNonBounceable:
+	default:
+		return 0, nil, false, false, fmt.Errorf("unknown address tag 0x%02x", tag)
+	}
+
+	hash = make([]byte, 32)
+	copy(hash, buf[2:34])
+	return int32(int8(buf[1])), hash, bounceable, testnet, nil
+}
+
+// NormalizeAddress приводит адрес в любой форме (raw "0:hex" или user-friendly) к
+// каноничной raw-форме "<workchain>:<hex в нижнем регистре>" — её и сравниваем.
+func NormalizeAddress(addr string) (string, error) {
+	addr = strings.TrimSpace(addr)
+	var workchain int32
+	var hash []byte
+	var err error
+	if strings.Contains(addr, ":") {
+		workchain, hash, err = ParseRawAddress(addr)
+	} else {
+		workchain, hash, _, _, err = FriendlyToRaw(addr)
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(hash) != 32 {
+		return "", fmt.Errorf("address hash must be 32 bytes, got %d", len(hash))
+	}
+	return fmt.Sprintf("%d:%s", workchain, hex.EncodeToString(hash)), nil
+}
+
+// SameAddress сообщает, указывают ли a и b на один аккаунт, независимо от формы записи
+// и флагов bounceable/testnet. Неразбираемые адреса сравниваются как строки.
+func SameAddress(a, b string) bool {
+	ra, errA := NormalizeAddress(a)
+	rb, errB := NormalizeAddress(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return ra == rb
+}
+
+// crc16 — CRC-16/XMODEM (poly 0x1021, init 0), как в TON.
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
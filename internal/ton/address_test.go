@@ -0,0 +1,116 @@
+package ton
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestRawToFriendly(t *testing.T) {
+	const (
+		basechain   = "83dfd552e63729b472fcbcc8c45ebcc6691702558b68ec7527e1ba403a0f31a8"
+		masterchain = "3333333333333333333333333333333333333333333333333333333333333333"
+	)
+	tests := []struct {
+		name       string
+		workchain  int32
+		hash       string
+		bounceable bool
+		testnet    bool
+		expected   string
+	}{
+		{"mainnet bounceable", 0, basechain, true, false, "EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N"},
+		{"mainnet non-bounceable", 0, basechain, false, false, "UQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqEBI"},
+		{"testnet bounceable", 0, basechain, true, true, "kQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqKYH"},
+		{"testnet non-bounceable", 0, basechain, false, true, "0QCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqPvC"},
+		{"masterchain", -1, masterchain, true, false, "Ef8zMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzM0vF"},
+		{"masterchain testnet", -1, masterchain, true, true, "kf8zMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzM_BP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, _ := hex.DecodeString(tt.hash)
+			got, err := RawToFriendly(tt.workchain, hash, tt.bounceable, tt.testnet)
+			if err != nil {
+				t.Fatalf("RawToFriendly: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("RawToFriendly = %s, want %s", got, tt.expected)
+			}
+
+			wc, h, bounceable, testnet, err := FriendlyToRaw(got)
+			if err != nil {
+				t.Fatalf("FriendlyToRaw: %v", err)
+			}
+			if wc != tt.workchain || hex.EncodeToString(h) != tt.hash || bounceable != tt.bounceable || testnet != tt.testnet {
+				t.Errorf("FriendlyToRaw = (%d, %x, %v, %v), want (%d, %s, %v, %v)",
+					wc, h, bounceable, testnet, tt.workchain, tt.hash, tt.bounceable, tt.testnet)
+			}
+		})
+	}
+}
+
+func TestRawToFriendlyInvalid(t *testing.T) {
+	if _, err := RawToFriendly(0, make([]byte, 31), true, false); err == nil {
+		t.Error("expected error for short hash")
+	}
+	if _, err := RawToFriendly(300, make([]byte, 32), true, false); err == nil {
+		t.Error("expected error for workchain out of int8 range")
+	}
+}
+
+func TestFriendlyToRawInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+	}{
+		{"bad checksum", "EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2O"},
+		{"too short", "EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8x"},
+		{"not base64", "EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xq!!!"},
+		{"unknown tag", "AACD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N"},
+		{"empty", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, _, err := FriendlyToRaw(tt.address); err == nil {
+				t.Errorf("FriendlyToRaw(%q) expected error", tt.address)
+			}
+		})
+	}
+}
+
+func TestFriendlyToRawStdBase64(t *testing.T) {
+	// Some wallets hand out the standard-alphabet form
+	wc, h, _, _, err := FriendlyToRaw("kf8zMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzM/BP")
+	if err != nil {
+		t.Fatalf("FriendlyToRaw: %v", err)
+	}
+	if wc != -1 || h[0] != 0x33 {
+		t.Errorf("FriendlyToRaw = (%d, %x), want masterchain 0x33...", wc, h)
+	}
+}
+
+func TestSameAddress(t *testing.T) {
+	const raw = "0:83dfd552e63729b472fcbcc8c45ebcc6691702558b68ec7527e1ba403a0f31a8"
+	tests := []struct {
+		name     string
+		a, b     string
+		expected bool
+	}{
+		{"raw vs bounceable", raw, "EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N", true},
+		{"bounceable vs non-bounceable", "EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N", "UQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqEBI", true},
+		{"raw upper vs lower case", "0:83DFD552E63729B472FCBCC8C45EBCC6691702558B68EC7527E1BA403A0F31A8", raw, true},
+		{"different workchain", "-1:83dfd552e63729b472fcbcc8c45ebcc6691702558b68ec7527e1ba403a0f31a8", raw, false},
+		{"different account", "Ef8zMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzM0vF", raw, false},
+		{"unparsable equal strings", "garbage", "garbage", true},
+		{"unparsable vs valid", "garbage", raw, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SameAddress(tt.a, tt.b); got != tt.expected {
+				t.Errorf("SameAddress(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.expected)
+			}
+		})
+	}
+}
@@ -1,53 +1,234 @@
 package ton
 
-// TON Lite Server client placeholder.
-// In production, use a TON SDK (tonutils-go or similar) to:
-// 1. Connect to lite server
-// 2. Scan blocks for transactions to hot wallet address
-// 3. Parse comments/payloads from internal messages
-// 4. Return parsed Transaction structs
+// Lite server access and hot wallet payouts.
+// Incoming payments are scanned by cmd/ton-indexer; outgoing transfers
+// (payouts, refunds) are signed here with the hot wallet key.
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
 	"time"
+
+	"github.com/ads-marketplace/backend/internal/config"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/liteclient"
+	"github.com/xssnick/tonutils-go/tlb"
+	tonapi "github.com/xssnick/tonutils-go/ton"
+	"github.com/xssnick/tonutils-go/ton/jetton"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+	"go.uber.org/zap"
 )
 
+// Connect establishes a connection to the TON network.
+// If LITE_SERVER_HOST + LITE_SERVER_KEY are set, connects to a specific lite server.
+// Otherwise, auto-discovers lite servers from the global TON config based on TON_NETWORK.
+func Connect(ctx context.Context, cfg *config.Config, log *zap.Logger) (tonapi.APIClientWrapped, error) {
+	client := liteclient.NewConnectionPool()
+
+	if cfg.LiteServerHost != "" && cfg.LiteServerKey != "" {
+		addr := fmt.Sprintf("%s:%d", cfg.LiteServerHost, cfg.LiteServerPort)
+		log.Info("connecting to lite server", zap.String("addr", addr))
+		if err := client.AddConnection(ctx, addr, cfg.LiteServerKey); err != nil {
+			return nil, fmt.Errorf("connect to lite server %s: %w", addr, err)
+		}
+	} else {
+		var configURL string
+		switch strings.ToLower(cfg.TONNetwork) {
+		case "mainnet":
+			configURL = "https://ton.org/global.config.json"
+		default:
+			configURL = "https://ton.org/testnet-global.config.json"
+		}
+		log.Info("connecting via global config", zap.String("url", configURL), zap.String("network", cfg.TONNetwork))
+		if err := client.AddConnectionsFromConfigUrl(ctx, configURL); err != nil {
+			return nil, fmt.Errorf("connect via config %s: %w", configURL, err)
+		}
+	}
+
+	proofPolicy := tonapi.ProofCheckPolicyFast
+	if strings.ToLower(cfg.TONNetwork) == "mainnet" {
+		proofPolicy = tonapi.ProofCheckPolicySecure
+	}
+
+	api := tonapi.NewAPIClient(client, proofPolicy).WithRetry()
+	return api, nil
+}
+
+// WalletSender signs and broadcasts transfers from the hot wallet.
+type WalletSender interface {
+	// Prepare signs a transfer with the wallet's current seqno.
+	Prepare(ctx context.Context, to *address.Address, amount tlb.Coins, comment string) (*tlb.ExternalMessage, error)
+	// PrepareJetton signs a transfer of amount jetton units of master from the hot wallet's jetton wallet.
+	PrepareJetton(ctx context.Context, master, to *address.Address, amount *big.Int, comment string) (*tlb.ExternalMessage, error)
+	// Broadcast sends a signed message and waits for its transaction, returning the tx hash.
+	// Sending the same message twice is safe: the wallet contract accepts a seqno only once.
+	Broadcast(ctx context.Context, msg *tlb.ExternalMessage) ([]byte, error)
+}
+
+// LiteClient sends TON and jettons from the hot wallet, retrying transient failures.
 type LiteClient struct {
-	host string
-	port int
-	key  string
-}
-
-type TxInfo struct {
-	Hash        string
-	FromAddr    string
-	ToAddr      string
-	AmountNano  int64
-	Comment     string
-	BlockTime   time.Time
-	Lt          uint64
-}
-
-func NewLiteClient(host string, port int, key string) *LiteClient {
-	return &LiteClient{host: host, port: port, key: key}
-}
-
-// GetTransactions returns new incoming transactions to the given address
-// since the last known logical time (lt).
-// TODO: Implement actual lite server communication.
-func (c *LiteClient) GetTransactions(ctx context.Context, address string, lastLt uint64) ([]TxInfo, error) {
-	// Placeholder — in production:
-	// 1. tonutils-go / liteclient connect
-	// 2. GetAccountState to get latest lt
-	// 3. GetTransactions from lastLt to latest
-	// 4. Filter incoming, parse comments
-	return nil, nil
-}
-
-// SendTON sends TON from hot wallet to destination.
-// Used for payouts and refunds.
-// TODO: Implement actual sending via lite client or toncenter API.
-func (c *LiteClient) SendTON(ctx context.Context, fromSecret, toAddress string, amountNano int64, comment string) (string, error) {
-	// Placeholder
-	return "", nil
+	sender     WalletSender
+	retries    int
+	retryDelay time.Duration
+}
+
+func NewLiteClient(sender WalletSender, retries int, retryDelay time.Duration) *LiteClient {
+	if retries < 1 {
+		retries = 1
+	}
+	return &LiteClient{sender: sender, retries: retries, retryDelay: retryDelay}
+}
+
+// SendTON sends amountNano to the destination friendly address with a text comment
+// and returns the hex hash of the hot wallet transaction. Used for payouts and refunds.
+// The transfer is signed once and the same message is re-broadcast on retry,
+// so a retry after a lost confirmation can't pay twice.
+func (c *LiteClient) SendTON(ctx context.Context, toAddress string, amountNano *big.Int, comment string) (string, error) {
+	to, err := address.ParseAddr(toAddress)
+	if err != nil {
+		return "", fmt.Errorf("invalid destination address %s: %w", toAddress, err)
+	}
+	if amountNano == nil || amountNano.Sign() <= 0 {
+		return "", fmt.Errorf("invalid amount: %v", amountNano)
+	}
+	amount := tlb.FromNanoTON(amountNano)
+
+	return c.send(ctx, func() (*tlb.ExternalMessage, error) {
+		return c.sender.Prepare(ctx, to, amount, comment)
+	}, fmt.Sprintf("%s nanoTON", amountNano), toAddress)
+}
+
+// SendJetton sends units (the jetton's smallest units) of the jettonMaster jetton to the
+// destination friendly address with a text comment, from the hot wallet's jetton wallet.
+// Used for USDT payouts; retried the same way as SendTON.
+func (c *LiteClient) SendJetton(ctx context.Context, jettonMaster, toAddress string, units *big.Int, comment string) (string, error) {
+	master, err := address.ParseAddr(jettonMaster)
+	if err != nil {
+		return "", fmt.Errorf("invalid jetton master %s: %w", jettonMaster, err)
+	}
+	to, err := address.ParseAddr(toAddress)
+	if err != nil {
+		return "", fmt.Errorf("invalid destination address %s: %w", toAddress, err)
+	}
+	if units == nil || units.Sign() <= 0 {
+		return "", fmt.Errorf("invalid amount: %v", units)
+	}
+
+	return c.send(ctx, func() (*tlb.ExternalMessage, error) {
+		return c.sender.PrepareJetton(ctx, master, to, units, comment)
+	}, fmt.Sprintf("%s units of jetton %s", units, jettonMaster), toAddress)
+}
+
+// send signs a transfer once with prepare and broadcasts it until it lands or the retries
+// run out; what and toAddress only describe the transfer in the error.
+func (c *LiteClient) send(ctx context.Context, prepare func() (*tlb.ExternalMessage, error), what, toAddress string) (string, error) {
+	var (
+		msg *tlb.ExternalMessage
+		err error
+	)
+	for attempt := 1; ; attempt++ {
+		if msg == nil {
+			msg, err = prepare()
+		}
+		if msg != nil {
+			var hash []byte
+			hash, err = c.sender.Broadcast(ctx, msg)
+			if err == nil {
+				return hex.EncodeToString(hash), nil
+			}
+		}
+		if attempt >= c.retries {
+			return "", fmt.Errorf("send %s to %s: %w", what, toAddress, err)
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(c.retryDelay):
+		}
+	}
+}
+
+// hotWallet is the WalletSender backed by a v4r2 wallet restored from its mnemonic.
+type hotWallet struct {
+	api tonapi.APIClientWrapped
+	w   *wallet.Wallet
+}
+
+// NewHotWallet restores the hot wallet from its 24-word mnemonic. Fails if the derived
+// address differs from expectedAddr, so payouts never come from an unexpected wallet.
+func NewHotWallet(api tonapi.APIClientWrapped, mnemonic, expectedAddr string) (WalletSender, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("hot wallet mnemonic is not configured")
+	}
+	w, err := wallet.FromSeed(api, words, wallet.V4R2)
+	if err != nil {
+		return nil, fmt.Errorf("restore hot wallet: %w", err)
+	}
+	if expectedAddr != "" {
+		expected, err := address.ParseAddr(expectedAddr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hot wallet address %s: %w", expectedAddr, err)
+		}
+		if !expected.Equals(w.WalletAddress()) {
+			return nil, fmt.Errorf("mnemonic derives %s, expected hot wallet %s", w.WalletAddress(), expected)
+		}
+	}
+	return &hotWallet{api: api, w: w}, nil
+}
+
+func (h *hotWallet) Prepare(ctx context.Context, to *address.Address, amount tlb.Coins, comment string) (*tlb.ExternalMessage, error) {
+	transfer, err := h.w.BuildTransfer(to, amount, to.IsBounceable(), comment)
+	if err != nil {
+		return nil, fmt.Errorf("build transfer: %w", err)
+	}
+	return h.w.BuildExternalMessage(ctx, transfer)
+}
+
+// Jetton transfers attach TON for the jetton wallets' gas (the excess returns to the hot
+// wallet) and forward 1 nanoTON so the recipient's wallet is notified with the comment.
+var (
+	jettonTransferGas   = tlb.MustFromTON("0.05")
+	jettonForwardAmount = tlb.FromNanoTONU(1)
+)
+
+func (h *hotWallet) PrepareJetton(ctx context.Context, master, to *address.Address, amount *big.Int, comment string) (*tlb.ExternalMessage, error) {
+	jw, err := jetton.NewJettonMasterClient(h.api, master).GetJettonWallet(ctx, h.w.WalletAddress())
+	if err != nil {
+		return nil, fmt.Errorf("get hot wallet jetton wallet: %w", err)
+	}
+	var forward *cell.Cell
+	if comment != "" {
+		if forward, err = wallet.CreateCommentCell(comment); err != nil {
+			return nil, fmt.Errorf("build comment: %w", err)
+		}
+	}
+	// Coins only carry the raw units here: the jetton's decimals don't matter on the wire
+	payload, err := jw.BuildTransferPayloadV2(to, h.w.WalletAddress(), tlb.FromNanoTON(amount), jettonForwardAmount, forward, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build jetton transfer: %w", err)
+	}
+	return h.w.BuildExternalMessage(ctx, wallet.SimpleMessage(jw.Address(), jettonTransferGas, payload))
+}
+
+func (h *hotWallet) Broadcast(ctx context.Context, msg *tlb.ExternalMessage) ([]byte, error) {
+	// A previous attempt may have landed even though we never saw the confirmation
+	tx, err := h.api.FindLastTransactionByInMsgHash(ctx, h.w.WalletAddress(), msg.Body.Hash())
+	if err == nil {
+		return tx.Hash, nil
+	}
+	if !errors.Is(err, tonapi.ErrTxWasNotFound) {
+		return nil, fmt.Errorf("look up previous attempt: %w", err)
+	}
+
+	tx, _, _, err = h.api.SendExternalMessageWaitTransaction(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	return tx.Hash, nil
 }
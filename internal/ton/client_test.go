@@ -0,0 +1,168 @@
+package ton
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+const (
+	testDest   = "EQDtFpEwcFAEcRe5mLVh2N6C0x-_hJEM7W61_JLnSF74p4q2"
+	testMaster = "EQCxE6mUtQJKFnGfaROTKOt1lZbDiiX1kCixRv7Nw2Id_sDs"
+)
+
+// mockSender records what SendTON asked for; the first failBroadcasts broadcasts fail.
+type mockSender struct {
+	failPrepares   int
+	failBroadcasts int
+
+	prepares   int
+	broadcasts []*tlb.ExternalMessage
+	master     *address.Address // set for jetton transfers
+	to         *address.Address
+	amount     tlb.Coins
+	units      *big.Int
+	comment    string
+}
+
+func (m *mockSender) Prepare(_ context.Context, to *address.Address, amount tlb.Coins, comment string) (*tlb.ExternalMessage, error) {
+	m.prepares++
+	if m.prepares <= m.failPrepares {
+		return nil, errors.New("lite server unavailable")
+	}
+	m.to, m.amount, m.comment = to, amount, comment
+	return &tlb.ExternalMessage{Body: cell.BeginCell().MustStoreUInt(uint64(m.prepares), 32).EndCell()}, nil
+}
+
+func (m *mockSender) PrepareJetton(_ context.Context, master, to *address.Address, amount *big.Int, comment string) (*tlb.ExternalMessage, error) {
+	m.prepares++
+	if m.prepares <= m.failPrepares {
+		return nil, errors.New("lite server unavailable")
+	}
+	m.master, m.to, m.units, m.comment = master, to, amount, comment
+	return &tlb.ExternalMessage{Body: cell.BeginCell().MustStoreUInt(uint64(m.prepares), 32).EndCell()}, nil
+}
+
+func (m *mockSender) Broadcast(_ context.Context, msg *tlb.ExternalMessage) ([]byte, error) {
+	m.broadcasts = append(m.broadcasts, msg)
+	if len(m.broadcasts) <= m.failBroadcasts {
+		return nil, errors.New("confirmation timeout")
+	}
+	return []byte{0xde, 0xad, 0xbe, 0xef}, nil
+}
+
+func TestSendTON(t *testing.T) {
+	ctx := context.Background()
+	amount := big.NewInt(1_500_000_000)
+
+	tests := []struct {
+		name           string
+		retries        int
+		failPrepares   int
+		failBroadcasts int
+		wantErr        bool
+		wantPrepares   int
+		wantBroadcasts int
+	}{
+		{"first try", 3, 0, 0, false, 1, 1},
+		{"broadcast retried with the same message", 3, 0, 2, false, 1, 3},
+		{"prepare retried", 3, 1, 0, false, 2, 1},
+		{"gives up after retries", 3, 0, 3, true, 1, 3},
+		{"no retries configured", 0, 0, 1, true, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &mockSender{failPrepares: tt.failPrepares, failBroadcasts: tt.failBroadcasts}
+			client := NewLiteClient(sender, tt.retries, 0)
+
+			hash, err := client.SendTON(ctx, testDest, amount, "payout")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if sender.prepares != tt.wantPrepares {
+				t.Errorf("prepares = %d, want %d", sender.prepares, tt.wantPrepares)
+			}
+			if len(sender.broadcasts) != tt.wantBroadcasts {
+				t.Errorf("broadcasts = %d, want %d", len(sender.broadcasts), tt.wantBroadcasts)
+			}
+			for _, msg := range sender.broadcasts[1:] {
+				if msg != sender.broadcasts[0] {
+					t.Errorf("retry re-signed the transfer; must re-broadcast the same message")
+				}
+			}
+			if tt.wantErr {
+				return
+			}
+			if hash != "deadbeef" {
+				t.Errorf("hash = %q, want deadbeef", hash)
+			}
+			if sender.to.String() != testDest {
+				t.Errorf("to = %s, want %s", sender.to, testDest)
+			}
+			if sender.amount.Nano().Cmp(amount) != 0 {
+				t.Errorf("amount = %s, want %s", sender.amount.Nano(), amount)
+			}
+			if sender.comment != "payout" {
+				t.Errorf("comment = %q, want payout", sender.comment)
+			}
+		})
+	}
+}
+
+func TestSendTONRejectsBadInput(t *testing.T) {
+	ctx := context.Background()
+	sender := &mockSender{}
+	client := NewLiteClient(sender, 3, 0)
+
+	if _, err := client.SendTON(ctx, "not-an-address", big.NewInt(1), ""); err == nil {
+		t.Error("expected error for invalid address")
+	}
+	if _, err := client.SendTON(ctx, testDest, big.NewInt(0), ""); err == nil {
+		t.Error("expected error for zero amount")
+	}
+	if sender.prepares != 0 {
+		t.Errorf("nothing should be signed for bad input, got %d prepares", sender.prepares)
+	}
+}
+
+func TestSendJetton(t *testing.T) {
+	ctx := context.Background()
+	units := big.NewInt(12_500_000)
+	sender := &mockSender{failBroadcasts: 1}
+	client := NewLiteClient(sender, 3, 0)
+
+	hash, err := client.SendJetton(ctx, testMaster, testDest, units, "payout")
+	if err != nil {
+		t.Fatalf("SendJetton: %v", err)
+	}
+	if hash != "deadbeef" {
+		t.Errorf("hash = %q, want deadbeef", hash)
+	}
+	if sender.prepares != 1 || len(sender.broadcasts) != 2 {
+		t.Errorf("prepares = %d, broadcasts = %d; want the signed transfer re-broadcast once", sender.prepares, len(sender.broadcasts))
+	}
+	if sender.master.String() != testMaster || sender.to.String() != testDest {
+		t.Errorf("master = %s, to = %s; want %s, %s", sender.master, sender.to, testMaster, testDest)
+	}
+	if sender.units.Cmp(units) != 0 || sender.comment != "payout" {
+		t.Errorf("units = %s, comment = %q; want %s, payout", sender.units, sender.comment, units)
+	}
+
+	bad := &mockSender{}
+	client = NewLiteClient(bad, 3, 0)
+	if _, err := client.SendJetton(ctx, "not-an-address", testDest, units, ""); err == nil {
+		t.Error("expected error for invalid jetton master")
+	}
+	if _, err := client.SendJetton(ctx, testMaster, testDest, big.NewInt(0), ""); err == nil {
+		t.Error("expected error for zero amount")
+	}
+	if bad.prepares != 0 {
+		t.Errorf("nothing should be signed for bad input, got %d prepares", bad.prepares)
+	}
+}
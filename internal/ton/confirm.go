@@ -0,0 +1,26 @@
+package ton
+
+// ConfirmedSeqno — masterchain-блок, отстающий от tip на confirmations. ok=false, если
+// цепочка ещё короче (свежая сеть): тогда подтверждённых транзакций нет.
+func ConfirmedSeqno(tip uint32, confirmations int) (uint32, bool) {
+	if confirmations <= 0 {
+		return tip, true
+	}
+	if uint32(confirmations) > tip {
+		return 0, false
+	}
+	return tip - uint32(confirmations), true
+}
+
+// ConfirmedCount returns how many of the transactions newer than the cursor, oldest first,
+// are deep enough to act on. confirmedLT is the account's last transaction LT as seen at
+// the ConfirmedSeqno block: everything up to it was committed at least that many masterchain
+// blocks ago. The rest are deferred and re-fetched on a later poll.
+func ConfirmedCount(txs []TxLink, confirmedLT uint64) int {
+	for i, tx := range txs {
+		if tx.LT > confirmedLT {
+			return i
+		}
+	}
+	return len(txs)
+}
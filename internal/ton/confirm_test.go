@@ -0,0 +1,50 @@
+package ton
+
+import "testing"
+
+func TestConfirmedSeqno(t *testing.T) {
+	tests := []struct {
+		tip           uint32
+		confirmations int
+		want          uint32
+		ok            bool
+	}{
+		{1000, 0, 1000, true},
+		{1000, 1, 999, true},
+		{1000, 5, 995, true},
+		{5, 5, 0, true},
+		{4, 5, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ConfirmedSeqno(tt.tip, tt.confirmations)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ConfirmedSeqno(%d, %d) = %d, %v; want %d, %v", tt.tip, tt.confirmations, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestConfirmedCount(t *testing.T) {
+	txs := []TxLink{{LT: 100}, {LT: 200}, {LT: 300}}
+
+	tests := []struct {
+		name        string
+		txs         []TxLink
+		confirmedLT uint64
+		want        int
+	}{
+		{"all confirmed", txs, 300, 3},
+		{"confirmed LT past the newest tx", txs, 500, 3},
+		{"newest not yet confirmed", txs, 200, 2},
+		{"confirmed LT between txs", txs, 150, 1},
+		{"none confirmed", txs, 50, 0},
+		{"account not yet active at the confirmed block", txs, 0, 0},
+		{"no txs", nil, 300, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConfirmedCount(tt.txs, tt.confirmedLT); got != tt.want {
+				t.Errorf("ConfirmedCount(confirmedLT=%d) = %d, want %d", tt.confirmedLT, got, tt.want)
+			}
+		})
+	}
+}
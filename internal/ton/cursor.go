@@ -0,0 +1,125 @@
+package ton
+
+import (
+	"context"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis keys of the cached indexer cursor.
+const (
+	CursorLTKey   = "ton-indexer:cursor:lt"
+	CursorHashKey = "ton-indexer:cursor:hash"
+)
+
+// Cursor is the indexer position: the last processed transaction of the hot wallet.
+type Cursor struct {
+	LT   uint64
+	Hash []byte
+}
+
+// CursorBackend persists the cursor. LoadCursor returns nil, nil when nothing is stored.
+type CursorBackend interface {
+	LoadCursor(ctx context.Context) (*Cursor, error)
+	SaveCursor(ctx context.Context, c Cursor) error
+}
+
+// CursorStore keeps the cursor in Postgres (source of truth) with Redis as a fast-path cache.
+// Losing Redis costs one DB read — the indexer never jumps to the chain tip and skips payments.
+type CursorStore struct {
+	db    CursorBackend
+	cache CursorBackend
+}
+
+func NewCursorStore(db, cache CursorBackend) *CursorStore {
+	return &CursorStore{db: db, cache: cache}
+}
+
+// Restore runs on startup: the DB cursor wins over whatever the cache holds. Without a DB row,
+// a cursor left in the cache by a Redis-only deployment is adopted and persisted.
+// Returns nil on first run.
+func (s *CursorStore) Restore(ctx context.Context) (*Cursor, error) {
+	c, err := s.db.LoadCursor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c != nil {
+		_ = s.cache.SaveCursor(ctx, *c)
+		return c, nil
+	}
+
+	c, err = s.cache.LoadCursor(ctx)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	if err := s.db.SaveCursor(ctx, *c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Load returns the current cursor from the cache, falling back to the DB (and re-warming
+// the cache) on a miss.
+func (s *CursorStore) Load(ctx context.Context) (*Cursor, error) {
+	if c, err := s.cache.LoadCursor(ctx); err == nil && c != nil {
+		return c, nil
+	}
+	c, err := s.db.LoadCursor(ctx)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	_ = s.cache.SaveCursor(ctx, *c)
+	return c, nil
+}
+
+// Save writes the DB first, so the cache never gets ahead of it.
+func (s *CursorStore) Save(ctx context.Context, c Cursor) error {
+	if err := s.db.SaveCursor(ctx, c); err != nil {
+		return err
+	}
+	return s.cache.SaveCursor(ctx, c)
+}
+
+// Cache updates only the cache, for a cursor already written to the DB
+// (e.g. in the same transaction that funded an escrow).
+func (s *CursorStore) Cache(ctx context.Context, c Cursor) error {
+	return s.cache.SaveCursor(ctx, c)
+}
+
+// RedisCursorCache is the Redis side of CursorStore.
+type RedisCursorCache struct {
+	rdb *redis.Client
+}
+
+func NewRedisCursorCache(rdb *redis.Client) *RedisCursorCache {
+	return &RedisCursorCache{rdb: rdb}
+}
+
+func (r *RedisCursorCache) LoadCursor(ctx context.Context) (*Cursor, error) {
+	val, err := r.rdb.Get(ctx, CursorLTKey).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	lt, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		return nil, nil // garbage in the cache is a miss
+	}
+	c := &Cursor{LT: lt}
+	if h, err := r.rdb.Get(ctx, CursorHashKey).Result(); err == nil {
+		c.Hash, _ = hex.DecodeString(h)
+	}
+	return c, nil
+}
+
+func (r *RedisCursorCache) SaveCursor(ctx context.Context, c Cursor) error {
+	pipe := r.rdb.TxPipeline()
+	pipe.Set(ctx, CursorLTKey, strconv.FormatUint(c.LT, 10), 0)
+	pipe.Set(ctx, CursorHashKey, hex.EncodeToString(c.Hash), 0)
+	_, err := pipe.Exec(ctx)
+	return err
+}
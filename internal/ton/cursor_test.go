@@ -0,0 +1,110 @@
+package ton
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// memCursor is an in-memory CursorBackend; wipe() simulates a Redis flush.
+type memCursor struct {
+	c       *Cursor
+	saveErr error
+}
+
+func (m *memCursor) LoadCursor(context.Context) (*Cursor, error) {
+	if m.c == nil {
+		return nil, nil
+	}
+	c := *m.c
+	return &c, nil
+}
+
+func (m *memCursor) SaveCursor(_ context.Context, c Cursor) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.c = &c
+	return nil
+}
+
+func (m *memCursor) wipe() { m.c = nil }
+
+func ltOf(c *Cursor) uint64 {
+	if c == nil {
+		return 0
+	}
+	return c.LT
+}
+
+func TestCursorStoreSurvivesCacheLoss(t *testing.T) {
+	ctx := context.Background()
+	db, cache := &memCursor{}, &memCursor{}
+	store := NewCursorStore(db, cache)
+
+	if err := store.Save(ctx, Cursor{LT: 500, Hash: []byte{0xab}}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	cache.wipe()
+
+	c, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if ltOf(c) != 500 {
+		t.Errorf("Load() after cache loss = %d, want 500 from DB", ltOf(c))
+	}
+	if ltOf(cache.c) != 500 {
+		t.Errorf("cache not re-warmed: %d", ltOf(cache.c))
+	}
+}
+
+func TestCursorStoreRestore(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		db        *Cursor
+		cache     *Cursor
+		wantLT    uint64
+		wantDBLT  uint64
+		wantFound bool
+	}{
+		{"first run", nil, nil, 0, 0, false},
+		{"cache lost", &Cursor{LT: 700}, nil, 700, 700, true},
+		{"stale cache loses to DB", &Cursor{LT: 700}, &Cursor{LT: 900}, 700, 700, true},
+		{"legacy redis-only cursor adopted", nil, &Cursor{LT: 300}, 300, 300, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, cache := &memCursor{c: tt.db}, &memCursor{c: tt.cache}
+			c, err := NewCursorStore(db, cache).Restore(ctx)
+			if err != nil {
+				t.Fatalf("Restore: %v", err)
+			}
+			if (c != nil) != tt.wantFound || ltOf(c) != tt.wantLT {
+				t.Errorf("Restore() = %v, want lt %d (found %v)", c, tt.wantLT, tt.wantFound)
+			}
+			if ltOf(db.c) != tt.wantDBLT {
+				t.Errorf("DB cursor = %d, want %d", ltOf(db.c), tt.wantDBLT)
+			}
+			if tt.wantFound && ltOf(cache.c) != tt.wantLT {
+				t.Errorf("cache cursor = %d, want %d", ltOf(cache.c), tt.wantLT)
+			}
+		})
+	}
+}
+
+func TestCursorStoreSaveDoesNotAdvanceCacheOnDBError(t *testing.T) {
+	ctx := context.Background()
+	db, cache := &memCursor{c: &Cursor{LT: 100}}, &memCursor{c: &Cursor{LT: 100}}
+	db.saveErr = errors.New("db down")
+
+	if err := NewCursorStore(db, cache).Save(ctx, Cursor{LT: 200}); err == nil {
+		t.Fatal("Save() = nil, want error")
+	}
+	if ltOf(cache.c) != 100 {
+		t.Errorf("cache advanced to %d despite DB failure", ltOf(cache.c))
+	}
+}
@@ -0,0 +1,62 @@
+package ton
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// HeartbeatKey is written by the TON indexer after every successful poll cycle.
+const HeartbeatKey = "ton-indexer:heartbeat"
+
+type Heartbeat struct {
+	At       time.Time `json:"at"`
+	CursorLT uint64    `json:"cursor_lt"`
+}
+
+// IndexerHealth is the liveness view of the indexer served by the API.
+type IndexerHealth struct {
+	Alive         bool       `json:"alive"`
+	LastHeartbeat *time.Time `json:"last_heartbeat,omitempty"`
+	CursorLT      uint64     `json:"cursor_lt"`
+	AgeSeconds    *int64     `json:"age_seconds,omitempty"`
+	StaleAfter    int64      `json:"stale_after_seconds"`
+}
+
+func WriteHeartbeat(ctx context.Context, rdb *redis.Client, cursorLT uint64) error {
+	data, _ := json.Marshal(Heartbeat{At: time.Now().UTC(), CursorLT: cursorLT})
+	return rdb.Set(ctx, HeartbeatKey, data, 0).Err()
+}
+
+// ReadHeartbeat returns nil (no error) if the indexer never wrote one.
+func ReadHeartbeat(ctx context.Context, rdb *redis.Client) (*Heartbeat, error) {
+	data, err := rdb.Get(ctx, HeartbeatKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var hb Heartbeat
+	if err := json.Unmarshal(data, &hb); err != nil {
+		return nil, err
+	}
+	return &hb, nil
+}
+
+// CheckHeartbeat reports the indexer dead if there is no heartbeat or it is older than staleAfter.
+func CheckHeartbeat(hb *Heartbeat, now time.Time, staleAfter time.Duration) IndexerHealth {
+	h := IndexerHealth{StaleAfter: int64(staleAfter / time.Second)}
+	if hb == nil {
+		return h
+	}
+	age := int64(now.Sub(hb.At) / time.Second)
+	at := hb.At
+	h.LastHeartbeat = &at
+	h.CursorLT = hb.CursorLT
+	h.AgeSeconds = &age
+	h.Alive = now.Sub(hb.At) <= staleAfter
+	return h
+}
@@ -0,0 +1,34 @@
+package ton
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckHeartbeat(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	stale := 60 * time.Second
+
+	tests := []struct {
+		name  string
+		hb    *Heartbeat
+		alive bool
+	}{
+		{"never written", nil, false},
+		{"fresh", &Heartbeat{At: now.Add(-5 * time.Second), CursorLT: 42}, true},
+		{"at threshold", &Heartbeat{At: now.Add(-stale)}, true},
+		{"stale", &Heartbeat{At: now.Add(-stale - time.Second)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := CheckHeartbeat(tt.hb, now, stale)
+			if h.Alive != tt.alive {
+				t.Errorf("CheckHeartbeat().Alive = %v, want %v", h.Alive, tt.alive)
+			}
+			if tt.hb != nil && h.CursorLT != tt.hb.CursorLT {
+				t.Errorf("CheckHeartbeat().CursorLT = %d, want %d", h.CursorLT, tt.hb.CursorLT)
+			}
+		})
+	}
+}
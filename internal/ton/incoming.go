@@ -0,0 +1,98 @@
+package ton
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ads-marketplace/backend/internal/models"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton/jetton"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// OpJettonTransferNotification is sent by our jetton wallet when it receives jettons.
+const OpJettonTransferNotification = 0x7362d09c
+
+// IncomingPayment is a decoded transfer to the hot wallet: native TON or a USDT jetton.
+type IncomingPayment struct {
+	Currency string
+	Amount   *big.Int // in the currency's smallest units
+	Display  string   // human-readable amount
+	From     string   // payer: message source for TON, jetton sender for USDT
+	Comment  string
+}
+
+// DecodeIncomingPayment extracts the payment from an incoming internal message.
+// Jetton notifications are accepted only from usdtWallet, the hot wallet's USDT jetton
+// wallet (nil disables USDT): anyone can deploy a jetton that sends the same notification.
+func DecodeIncomingPayment(inMsg *tlb.InternalMessage, usdtWallet *address.Address) (*IncomingPayment, bool) {
+	if inMsg.Body != nil {
+		slice := inMsg.Body.BeginParse()
+		if op, err := slice.LoadUInt(32); err == nil && op == OpJettonTransferNotification {
+			if usdtWallet == nil || inMsg.SrcAddr == nil || !inMsg.SrcAddr.Equals(usdtWallet) {
+				return nil, false
+			}
+			var n jetton.TransferNotification
+			if err := tlb.LoadFromCell(&n, inMsg.Body.BeginParse()); err != nil {
+				return nil, false
+			}
+			amount := n.Amount.Nano()
+			if amount.Sign() <= 0 {
+				return nil, false
+			}
+			var from string
+			if n.Sender != nil {
+				from = n.Sender.String()
+			}
+			return &IncomingPayment{
+				Currency: models.EscrowCurrencyUSDT,
+				Amount:   amount,
+				Display:  tlb.MustFromNano(amount, models.CurrencyDecimals(models.EscrowCurrencyUSDT)).String(),
+				From:     from,
+				Comment:  ExtractComment(n.ForwardPayload),
+			}, true
+		}
+	}
+
+	if inMsg.Amount.Nano().Sign() <= 0 {
+		return nil, false
+	}
+	return &IncomingPayment{
+		Currency: models.EscrowCurrencyTON,
+		Amount:   inMsg.Amount.Nano(),
+		Display:  inMsg.Amount.String(),
+		From:     inMsg.SrcAddr.String(),
+		Comment:  ExtractComment(inMsg.Body),
+	}, true
+}
+
+// ExtractComment parses a text comment from a message body or a jetton forward_payload.
+// TON text comments have opcode 0x00000000 followed by UTF-8 text.
+func ExtractComment(body *cell.Cell) string {
+	if body == nil {
+		return ""
+	}
+
+	slice := body.BeginParse()
+	if slice.BitsLeft() < 32 {
+		return ""
+	}
+
+	op, err := slice.LoadUInt(32)
+	if err != nil || op != 0 {
+		return ""
+	}
+
+	remaining := slice.BitsLeft()
+	if remaining < 8 {
+		return ""
+	}
+
+	data, err := slice.LoadSlice(remaining)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(data))
+}
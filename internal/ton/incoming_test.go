@@ -0,0 +1,148 @@
+package ton
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+func testAddr(b byte) *address.Address {
+	return address.NewAddress(0, 0, bytes.Repeat([]byte{b}, 32))
+}
+
+// jettonNotification builds the transfer_notification our jetton wallet sends, with the
+// forward_payload stored in a ref.
+func jettonNotification(t *testing.T, amount int64, sender *address.Address, comment string) *cell.Cell {
+	t.Helper()
+	b := cell.BeginCell().
+		MustStoreUInt(OpJettonTransferNotification, 32).
+		MustStoreUInt(7, 64).
+		MustStoreBigCoins(big.NewInt(amount)).
+		MustStoreAddr(sender)
+	if comment == "" {
+		return b.MustStoreBoolBit(false).EndCell()
+	}
+	payload, err := wallet.CreateCommentCell(comment)
+	if err != nil {
+		t.Fatalf("CreateCommentCell: %v", err)
+	}
+	return b.MustStoreBoolBit(true).MustStoreRef(payload).EndCell()
+}
+
+func TestDecodeIncomingPaymentJetton(t *testing.T) {
+	usdtWallet := testAddr(1)
+	payer := testAddr(2)
+
+	tests := []struct {
+		name       string
+		src        *address.Address
+		usdtWallet *address.Address
+		amount     int64
+		ok         bool
+	}{
+		{"from our USDT wallet", usdtWallet, usdtWallet, 12_500_000, true},
+		{"from another jetton wallet", testAddr(3), usdtWallet, 12_500_000, false},
+		{"USDT not configured", usdtWallet, nil, 12_500_000, false},
+		{"zero amount", usdtWallet, usdtWallet, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &tlb.InternalMessage{
+				SrcAddr: tt.src,
+				Amount:  tlb.MustFromTON("0.05"),
+				Body:    jettonNotification(t, tt.amount, payer, "deal:42"),
+			}
+			p, ok := DecodeIncomingPayment(msg, tt.usdtWallet)
+			if ok != tt.ok {
+				t.Fatalf("DecodeIncomingPayment() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if p.Currency != "USDT" {
+				t.Errorf("Currency = %q, want USDT", p.Currency)
+			}
+			if p.Amount.Int64() != tt.amount {
+				t.Errorf("Amount = %s, want %d", p.Amount, tt.amount)
+			}
+			if p.Display != "12.5" {
+				t.Errorf("Display = %q, want 12.5", p.Display)
+			}
+			if p.From != payer.String() {
+				t.Errorf("From = %q, want the jetton sender %q", p.From, payer.String())
+			}
+			if p.Comment != "deal:42" {
+				t.Errorf("Comment = %q, want deal:42", p.Comment)
+			}
+		})
+	}
+}
+
+func TestDecodeIncomingPaymentJettonWithoutComment(t *testing.T) {
+	usdtWallet := testAddr(1)
+	msg := &tlb.InternalMessage{
+		SrcAddr: usdtWallet,
+		Amount:  tlb.MustFromTON("0.05"),
+		Body:    jettonNotification(t, 1_000_000, testAddr(2), ""),
+	}
+	p, ok := DecodeIncomingPayment(msg, usdtWallet)
+	if !ok {
+		t.Fatal("jetton transfer without forward_payload was rejected")
+	}
+	if p.Comment != "" {
+		t.Errorf("Comment = %q, want empty", p.Comment)
+	}
+}
+
+func TestDecodeIncomingPaymentTON(t *testing.T) {
+	payer := testAddr(2)
+	body, err := wallet.CreateCommentCell("deal:42")
+	if err != nil {
+		t.Fatalf("CreateCommentCell: %v", err)
+	}
+
+	p, ok := DecodeIncomingPayment(&tlb.InternalMessage{
+		SrcAddr: payer,
+		Amount:  tlb.MustFromTON("5.5"),
+		Body:    body,
+	}, testAddr(1))
+	if !ok {
+		t.Fatal("TON transfer was rejected")
+	}
+	if p.Currency != "TON" || p.Amount.String() != "5500000000" || p.From != payer.String() || p.Comment != "deal:42" {
+		t.Errorf("DecodeIncomingPayment() = %+v", p)
+	}
+
+	if _, ok := DecodeIncomingPayment(&tlb.InternalMessage{SrcAddr: payer, Amount: tlb.ZeroCoins}, nil); ok {
+		t.Error("zero TON transfer was accepted")
+	}
+}
+
+func TestExtractComment(t *testing.T) {
+	comment, _ := wallet.CreateCommentCell("  deal:42 ")
+
+	tests := []struct {
+		name string
+		body *cell.Cell
+		want string
+	}{
+		{"text comment", comment, "deal:42"},
+		{"nil body", nil, ""},
+		{"other opcode", cell.BeginCell().MustStoreUInt(OpJettonTransferNotification, 32).MustStoreUInt(1, 64).EndCell(), ""},
+		{"opcode only", cell.BeginCell().MustStoreUInt(0, 32).EndCell(), ""},
+		{"too short", cell.BeginCell().MustStoreUInt(0, 16).EndCell(), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractComment(tt.body); got != tt.want {
+				t.Errorf("ExtractComment() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package ton
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	tonapi "github.com/xssnick/tonutils-go/ton"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+var (
+	// ErrStateInitRequired — адрес нельзя проверить без state_init (кошелёк не задеплоен
+	// или блокчейн недоступен).
+	ErrStateInitRequired = errors.New("wallet state_init is required to verify address ownership")
+
+	// ErrAccountNotDeployed — по адресу нет активного контракта.
+	ErrAccountNotDeployed = errors.New("account is not deployed")
+
+	// ErrPublicKeyMismatch — публичный ключ не принадлежит кошельку по этому адресу.
+	ErrPublicKeyMismatch = errors.New("public key does not belong to the wallet address")
+)
+
+// VerifyAddressOwnership проверяет, что pubKey — ключ кошелька по адресу workchain:address.
+// TON Proof подписывается ключом клиента, но сам по себе не связывает ключ с адресом:
+// для этого пересчитываем адрес из state_init (hash(code+data)) и достаём ключ из data.
+// Адрес кошелька — хеш его исходного state_init, поэтому проверка верна и для
+// уже задеплоенных кошельков.
+func VerifyAddressOwnership(address []byte, workchain int32, pubKey []byte, stateInitBOC string) error {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key size: %d", len(pubKey))
+	}
+	if strings.TrimSpace(stateInitBOC) == "" {
+		return ErrStateInitRequired
+	}
+
+	boc, err := base64.StdEncoding.DecodeString(strings.TrimSpace(stateInitBOC))
+	if err != nil {
+		return fmt.Errorf("invalid state_init base64: %w", err)
+	}
+	root, err := cell.FromBOC(boc)
+	if err != nil {
+		return fmt.Errorf("invalid state_init BOC: %w", err)
+	}
+	var si tlb.StateInit
+	if err := tlb.LoadFromCell(&si, root.BeginParse()); err != nil {
+		return fmt.Errorf("invalid state_init: %w", err)
+	}
+
+	if !bytes.Equal(root.Hash(), address) {
+		return fmt.Errorf("state_init does not match address %d:%x", workchain, address)
+	}
+
+	key, err := publicKeyFromState(si.Code, si.Data)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(key, pubKey) {
+		return ErrPublicKeyMismatch
+	}
+	return nil
+}
+
+// walletKeyOffsets — смещение (в битах) публичного ключа в data стандартных кошельков.
+// Раскладка data см. wallet.GetStateInit в tonutils-go.
+var walletKeyOffsets = map[wallet.Version]uint{
+	wallet.V1R1: 32, wallet.V1R2: 32, wallet.V1R3: 32, // seqno
+	wallet.V2R1: 32, wallet.V2R2: 32,
+	wallet.V3R1: 64, wallet.V3R2: 64, // seqno, subwallet
+	wallet.V4R1: 64, wallet.V4R2: 64,
+	wallet.V5R1Beta:           113, // seqno(33), network, workchain, version, subwallet
+	wallet.V5R1Final:          65,  // signature_allowed, seqno, wallet_id
+	wallet.HighloadV2R2:       96,  // subwallet, last_cleaned
+	wallet.HighloadV2Verified: 96,
+	wallet.HighloadV3:         0,
+}
+
+// publicKeyFromState определяет версию кошелька по хешу кода и читает ключ из data.
+func publicKeyFromState(code, data *cell.Cell) ([]byte, error) {
+	if code == nil || data == nil {
+		return nil, fmt.Errorf("state_init has no code or data")
+	}
+	ver := wallet.GetWalletVersion(&tlb.Account{
+		IsActive: true,
+		State:    &tlb.AccountState{AccountStorage: tlb.AccountStorage{Status: tlb.AccountStatusActive}},
+		Code:     code,
+	})
+	offset, ok := walletKeyOffsets[ver]
+	if !ok {
+		return nil, fmt.Errorf("unsupported wallet contract (code hash %x)", code.Hash())
+	}
+
+	s := data.BeginParse()
+	if offset > 0 {
+		if _, err := s.LoadSlice(offset); err != nil {
+			return nil, fmt.Errorf("read wallet data: %w", err)
+		}
+	}
+	key, err := s.LoadSlice(256)
+	if err != nil {
+		return nil, fmt.Errorf("read wallet public key: %w", err)
+	}
+	return key, nil
+}
+
+// AccountKeyReader достаёт публичный ключ задеплоенного кошелька из блокчейна.
+type AccountKeyReader interface {
+	// AccountPublicKey возвращает ErrAccountNotDeployed, если по адресу нет активного контракта.
+	AccountPublicKey(ctx context.Context, workchain int32, hash []byte) ([]byte, error)
+}
+
+type chainKeyReader struct {
+	api tonapi.APIClientWrapped
+}
+
+// NewAccountKeyReader читает ключи кошельков через lite server.
+func NewAccountKeyReader(api tonapi.APIClientWrapped) AccountKeyReader {
+	return &chainKeyReader{api: api}
+}
+
+func (r *chainKeyReader) AccountPublicKey(ctx context.Context, workchain int32, hash []byte) ([]byte, error) {
+	master, err := r.api.CurrentMasterchainInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get masterchain info: %w", err)
+	}
+	addr := address.NewAddress(0, byte(workchain), hash)
+	acc, err := r.api.GetAccount(ctx, master, addr)
+	if err != nil {
+		return nil, fmt.Errorf("get account %s: %w", addr, err)
+	}
+	if !acc.IsActive || acc.State == nil || acc.State.Status != tlb.AccountStatusActive {
+		return nil, ErrAccountNotDeployed
+	}
+
+	// get_public_key есть у v3+; у старых кошельков читаем ключ из текущей data
+	if key, err := wallet.GetPublicKey(ctx, r.api, addr); err == nil {
+		return key, nil
+	}
+	return publicKeyFromState(acc.Code, acc.Data)
+}
@@ -0,0 +1,77 @@
+package ton
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+)
+
+// testStateInit returns the base64 state_init BOC and address hash of a wallet for pub.
+func testStateInit(t *testing.T, pub ed25519.PublicKey, ver wallet.VersionConfig) (string, []byte) {
+	t.Helper()
+	si, err := wallet.GetStateInit(pub, ver, wallet.DefaultSubwallet)
+	if err != nil {
+		t.Fatalf("GetStateInit: %v", err)
+	}
+	c, err := tlb.ToCell(si)
+	if err != nil {
+		t.Fatalf("ToCell: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(c.ToBOC()), c.Hash()
+}
+
+func TestVerifyAddressOwnership(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	other, _, _ := ed25519.GenerateKey(nil)
+
+	versions := []struct {
+		name string
+		ver  wallet.VersionConfig
+	}{
+		{"v3r2", wallet.V3R2},
+		{"v4r2", wallet.V4R2},
+		{"v5r1", wallet.ConfigV5R1Final{NetworkGlobalID: wallet.MainnetGlobalID}},
+		{"highload v2r2", wallet.HighloadV2R2},
+	}
+	for _, v := range versions {
+		t.Run(v.name, func(t *testing.T) {
+			boc, addr := testStateInit(t, pub, v.ver)
+			if err := VerifyAddressOwnership(addr, 0, pub, boc); err != nil {
+				t.Errorf("VerifyAddressOwnership: %v", err)
+			}
+		})
+	}
+
+	boc, addr := testStateInit(t, pub, wallet.V4R2)
+	otherBOC, otherAddr := testStateInit(t, other, wallet.V4R2)
+
+	tests := []struct {
+		name    string
+		addr    []byte
+		pubKey  []byte
+		boc     string
+		wantErr error
+	}{
+		{"key of another wallet", addr, other, boc, ErrPublicKeyMismatch},
+		{"own state_init, someone else's address", addr, other, otherBOC, nil},
+		{"claimed address not derived from state_init", otherAddr, pub, boc, nil},
+		{"missing state_init", addr, pub, "", ErrStateInitRequired},
+		{"garbage state_init", addr, pub, "not-a-boc", nil},
+		{"short public key", addr, pub[:31], boc, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyAddressOwnership(tt.addr, 0, tt.pubKey, tt.boc)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package ton
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// dealMemoPrefix starts the comment an escrow deposit must carry: "deal:<deal id>".
+const dealMemoPrefix = "deal:"
+
+// DealMemo is the payment comment that attributes a deposit to the deal's escrow.
+func DealMemo(dealID uuid.UUID) string {
+	return dealMemoPrefix + dealID.String()
+}
+
+// ParseDealMemo returns the deal a payment comment names, if it is exactly a DealMemo
+// (canonical lowercase id, as escrows store it). Such a payment may simply have arrived
+// before the deal's escrow was opened.
+func ParseDealMemo(memo string) (uuid.UUID, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(memo), dealMemoPrefix)
+	if !ok {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(rest)
+	if err != nil || id.String() != rest {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// ParseUnits converts a decimal amount string (e.g. "5.5") to the currency's smallest
+// units: 9 decimals for TON (nanoTON), 6 for the USDT jetton. Extra digits are truncated.
+func ParseUnits(amount string, decimals int) (*big.Int, error) {
+	amount = strings.TrimSpace(amount)
+	if amount == "" {
+		return nil, fmt.Errorf("empty amount")
+	}
+	if decimals < 0 {
+		return nil, fmt.Errorf("unknown currency decimals")
+	}
+
+	parts := strings.Split(amount, ".")
+	if len(parts) > 2 {
+		return nil, fmt.Errorf("invalid amount: %s", amount)
+	}
+
+	whole := parts[0]
+	frac := ""
+	if len(parts) == 2 {
+		frac = parts[1]
+	}
+
+	if len(frac) > decimals {
+		frac = frac[:decimals]
+	}
+	for len(frac) < decimals {
+		frac += "0"
+	}
+
+	units, ok := new(big.Int).SetString(whole+frac, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount: %s", amount)
+	}
+	return units, nil
+}
+
+// TransferLink builds a ton://transfer deeplink that opens the wallet with the payment
+// prefilled. units is in the currency's smallest units; with jettonMaster set the link
+// requests a jetton transfer instead of native TON.
+func TransferLink(to string, units *big.Int, memo, jettonMaster string) string {
+	q := url.Values{}
+	q.Set("amount", units.String())
+	if jettonMaster != "" {
+		q.Set("jetton", jettonMaster)
+	}
+	if memo != "" {
+		q.Set("text", memo)
+	}
+	return "ton://transfer/" + to + "?" + q.Encode()
+}
+
+// PaymentInstructionsText is the bot message with everything needed to fund an escrow.
+func PaymentInstructionsText(amount, currency, address, memo, link string) string {
+	return fmt.Sprintf(
+		"Payment instructions\n\nAmount: %s %s\nAddress: %s\nComment (required): %s\n\nPay in one tap: %s",
+		amount, currency, address, memo, link,
+	)
+}
+
+// MaxCommentBytes is the longest text comment that fits in the message body cell next to
+// the 32-bit comment opcode (1023 bits). Longer comments need snake encoding, which some
+// wallets and explorers don't display, so outgoing comments are kept within one cell.
+const MaxCommentBytes = 123
+
+// TransferComment renders the comment attached to an outgoing transfer from template,
+// replacing {kind} (payout / refund), {entity} (withdrawal / deal) and {id}.
+// Fails if the result does not fit in a single cell.
+func TransferComment(template, kind, entity, id string) (string, error) {
+	comment := strings.NewReplacer(
+		"{kind}", kind,
+		"{entity}", entity,
+		"{id}", id,
+	).Replace(template)
+	if len(comment) > MaxCommentBytes {
+		return "", fmt.Errorf("transfer comment is %d bytes, max %d: %q", len(comment), MaxCommentBytes, comment)
+	}
+	return comment, nil
+}
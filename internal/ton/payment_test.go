@@ -0,0 +1,152 @@
+package ton
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+)
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals int
+		expected string
+		wantErr  bool
+	}{
+		{"5.5", 9, "5500000000", false},
+		{"1", 9, "1000000000", false},
+		{"0.000000001", 9, "1", false},
+		{"0.0000000019", 9, "1", false}, // truncated
+		{"12.5", 6, "12500000", false},
+		{" 3 ", 6, "3000000", false},
+		{"", 9, "", true},
+		{"1.2.3", 9, "", true},
+		{"abc", 9, "", true},
+		{"1", -1, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			got, err := ParseUnits(tt.amount, tt.decimals)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUnits(%q) error = %v, wantErr %v", tt.amount, err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.expected {
+				t.Errorf("ParseUnits(%q) = %s, want %s", tt.amount, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTransferLink(t *testing.T) {
+	const addr = "EQDhotwallet"
+
+	tests := []struct {
+		name     string
+		units    int64
+		memo     string
+		jetton   string
+		expected string
+	}{
+		{"native TON", 5500000000, "deal:42", "", "ton://transfer/EQDhotwallet?amount=5500000000&text=deal%3A42"},
+		{"jetton", 12500000, "deal:42", "EQusdt", "ton://transfer/EQDhotwallet?amount=12500000&jetton=EQusdt&text=deal%3A42"},
+		{"no memo", 1, "", "", "ton://transfer/EQDhotwallet?amount=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TransferLink(addr, big.NewInt(tt.units), tt.memo, tt.jetton); got != tt.expected {
+				t.Errorf("TransferLink() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPaymentInstructionsText(t *testing.T) {
+	got := PaymentInstructionsText("5.5", "TON", "EQDhotwallet", "deal:42", "ton://transfer/EQDhotwallet?amount=5500000000&text=deal%3A42")
+	expected := "Payment instructions\n\nAmount: 5.5 TON\nAddress: EQDhotwallet\nComment (required): deal:42\n\n" +
+		"Pay in one tap: ton://transfer/EQDhotwallet?amount=5500000000&text=deal%3A42"
+	if got != expected {
+		t.Errorf("PaymentInstructionsText() = %q, want %q", got, expected)
+	}
+}
+
+func TestTransferComment(t *testing.T) {
+	const id = "3f1c2a9e-7b4d-4e2a-9c1b-5d6e7f8a9b0c"
+
+	tests := []struct {
+		name     string
+		template string
+		kind     string
+		entity   string
+		want     string
+		wantErr  bool
+	}{
+		{"default template", "{kind}:{entity}:{id}", "payout", "withdrawal", "payout:withdrawal:" + id, false},
+		{"refund", "{kind}:{entity}:{id}", "refund", "deal", "refund:deal:" + id, false},
+		{"custom text", "Ads Marketplace {kind} #{id}", "payout", "withdrawal", "Ads Marketplace payout #" + id, false},
+		{"no placeholders", "thanks", "payout", "withdrawal", "thanks", false},
+		{"exactly one cell", strings.Repeat("x", MaxCommentBytes-len(id)) + "{id}", "payout", "withdrawal", strings.Repeat("x", MaxCommentBytes-len(id)) + id, false},
+		{"too long for one cell", strings.Repeat("x", MaxCommentBytes-len(id)+1) + "{id}", "payout", "withdrawal", "", true},
+		{"multi-byte runes count as bytes", strings.Repeat("я", 50) + "{id}", "payout", "withdrawal", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TransferComment(tt.template, tt.kind, tt.entity, id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("TransferComment() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxCommentFitsOneCell(t *testing.T) {
+	c, err := wallet.CreateCommentCell(strings.Repeat("x", MaxCommentBytes))
+	if err != nil {
+		t.Fatalf("CreateCommentCell: %v", err)
+	}
+	if c.RefsNum() != 0 {
+		t.Errorf("%d-byte comment spilled into %d snake refs", MaxCommentBytes, c.RefsNum())
+	}
+
+	c, _ = wallet.CreateCommentCell(strings.Repeat("x", MaxCommentBytes+1))
+	if c.RefsNum() == 0 {
+		t.Errorf("MaxCommentBytes is not the single-cell limit: %d bytes still fit", MaxCommentBytes+1)
+	}
+}
+
+func TestParseDealMemo(t *testing.T) {
+	id := uuid.MustParse("3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b")
+	tests := []struct {
+		name string
+		memo string
+		ok   bool
+	}{
+		{"canonical", DealMemo(id), true},
+		{"surrounding spaces", " " + DealMemo(id) + " ", true},
+		{"uppercase id", "deal:" + strings.ToUpper(id.String()), false},
+		{"braced id", "deal:{" + id.String() + "}", false},
+		{"no prefix", id.String(), false},
+		{"truncated id", DealMemo(id)[:20], false},
+		{"trailing text", DealMemo(id) + " thanks", false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseDealMemo(tt.memo)
+			if ok != tt.ok {
+				t.Fatalf("ParseDealMemo(%q) ok = %v, want %v", tt.memo, ok, tt.ok)
+			}
+			if ok && got != id {
+				t.Errorf("ParseDealMemo(%q) = %s, want %s", tt.memo, got, id)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package ton
+
+import "fmt"
+
+// ProcessedTxPrefix prefixes the indexer's Redis idempotency keys of handled transactions.
+const ProcessedTxPrefix = "ton-indexer:tx:"
+
+// ProcessedTxKey is the idempotency key of a handled transaction. LT alone is not unique
+// (a reorg can put another transaction at the same LT), so the key includes the hash.
+func ProcessedTxKey(lt uint64, hash []byte) string {
+	return fmt.Sprintf("%s%d:%x", ProcessedTxPrefix, lt, hash)
+}
+
+// LegacyProcessedTxKey is the LT-only key written before the hash was part of the key.
+// Such keys expire with the indexer's TTL; until then they still mark a transaction handled.
+func LegacyProcessedTxKey(lt uint64) string {
+	return fmt.Sprintf("%s%d", ProcessedTxPrefix, lt)
+}
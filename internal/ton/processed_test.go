@@ -0,0 +1,21 @@
+package ton
+
+import "testing"
+
+func TestProcessedTxKey(t *testing.T) {
+	a := ProcessedTxKey(100, []byte{0xab, 0xcd})
+	b := ProcessedTxKey(100, []byte{0xab, 0xce})
+
+	if a != "ton-indexer:tx:100:abcd" {
+		t.Errorf("ProcessedTxKey = %q, want %q", a, "ton-indexer:tx:100:abcd")
+	}
+	if a == b {
+		t.Errorf("two transactions at the same LT share the key %q", a)
+	}
+	if a == LegacyProcessedTxKey(100) {
+		t.Errorf("hashed key collides with the legacy LT-only key")
+	}
+	if got := ProcessedTxKey(100, []byte{0xab, 0xcd}); got != a {
+		t.Errorf("ProcessedTxKey is not stable: %q != %q", got, a)
+	}
+}
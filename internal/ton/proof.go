@@ -7,6 +7,8 @@ import (
 	"encoding/hex"
 	"fmt"
 	"time"
+
+	"github.com/ads-marketplace/backend/internal/clock"
 )
 
 const (
@@ -19,6 +21,9 @@ const (
 
 	// MaxProofAge — максимальный возраст proof (защита от replay).
 	MaxProofAge = 5 * time.Minute
+
+	// MaxProofClockSkew — насколько timestamp proof может опережать наши часы.
+	MaxProofClockSkew = 1 * time.Minute
 )
 
 // ProofData содержит данные из TON Connect ton_proof.
@@ -43,25 +48,44 @@ type ProofDomain struct {
 	Value       string `json:"value"`
 }
 
-// VerifyProof проверяет TON Proof подпись.
+// ProofVerifier проверяет TON Proof относительно списка доменов и источника времени.
+type ProofVerifier struct {
+	allowedDomains []string
+	clock          clock.Clock
+}
+
+func NewProofVerifier(allowedDomains []string, clk clock.Clock) *ProofVerifier {
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	return &ProofVerifier{allowedDomains: allowedDomains, clock: clk}
+}
+
+// VerifyProof проверяет TON Proof по реальным часам.
+func VerifyProof(pubKeyHex string, address []byte, workchain int32, proof Proof, allowedDomains []string) error {
+	return NewProofVerifier(allowedDomains, clock.Real{}).Verify(pubKeyHex, address, workchain, proof)
+}
+
+// Verify проверяет TON Proof подпись.
 //
 // Алгоритм (по спецификации TON Connect):
 // 1. message = "ton-proof-item-v2/" ++ address_workchain(4 bytes) ++ address_hash(32 bytes)
 //              ++ domain_len(4 bytes LE) ++ domain ++ timestamp(8 bytes LE) ++ payload
 // 2. signature_message = 0xffff ++ "ton-connect" ++ sha256(message)
 // 3. Verify Ed25519(public_key, sha256(signature_message), signature)
-func VerifyProof(pubKeyHex string, address []byte, workchain int32, proof Proof, allowedDomains []string) error {
+func (v *ProofVerifier) Verify(pubKeyHex string, address []byte, workchain int32, proof Proof) error {
 	// 1. Проверяем timestamp
+	now := v.clock.Now()
 	proofTime := time.Unix(proof.Timestamp, 0)
-	if time.Since(proofTime) > MaxProofAge {
-		return fmt.Errorf("proof expired: %s old", time.Since(proofTime).Round(time.Second))
+	if age := now.Sub(proofTime); age > MaxProofAge {
+		return fmt.Errorf("proof expired: %s old", age.Round(time.Second))
 	}
-	if proofTime.After(time.Now().Add(1 * time.Minute)) {
+	if proofTime.After(now.Add(MaxProofClockSkew)) {
 		return fmt.Errorf("proof timestamp is in the future")
 	}
 
 	// 2. Проверяем domain
-	if !isDomainAllowed(proof.Domain.Value, allowedDomains) {
+	if !isDomainAllowed(proof.Domain.Value, v.allowedDomains) {
 		return fmt.Errorf("domain %q not in allowed list", proof.Domain.Value)
 	}
 
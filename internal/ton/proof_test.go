@@ -7,6 +7,8 @@ import (
 	"encoding/hex"
 	"testing"
 	"time"
+
+	"github.com/ads-marketplace/backend/internal/clock"
 )
 
 func TestVerifyProof_ValidSignature(t *testing.T) {
@@ -152,3 +154,58 @@ func TestParseRawAddress(t *testing.T) {
 		})
 	}
 }
+
+// signTestProof signs proof for workchain 0 and a zero address hash.
+func signTestProof(privKey ed25519.PrivateKey, proof Proof) string {
+	message := []byte(TonProofPrefix)
+	message = append(message, make([]byte, 4)...) // workchain 0
+	message = append(message, make([]byte, 32)...)
+	domainLenBytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(domainLenBytes, uint32(proof.Domain.LengthBytes))
+	message = append(message, domainLenBytes...)
+	message = append(message, []byte(proof.Domain.Value)...)
+	tsBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(tsBytes, uint64(proof.Timestamp))
+	message = append(message, tsBytes...)
+	message = append(message, []byte(proof.Payload)...)
+
+	msgHash := sha256.Sum256(message)
+	signatureMessage := append([]byte{0xff, 0xff}, []byte(TonConnectPrefix)...)
+	signatureMessage = append(signatureMessage, msgHash[:]...)
+	finalHash := sha256.Sum256(signatureMessage)
+	return hex.EncodeToString(ed25519.Sign(privKey, finalHash[:]))
+}
+
+func TestProofVerifier_TimestampBoundaries(t *testing.T) {
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	signedAt := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	proof := Proof{
+		Timestamp: signedAt.Unix(),
+		Domain:    ProofDomain{LengthBytes: len("app.example.com"), Value: "app.example.com"},
+		Payload:   "nonce",
+	}
+	proof.Signature = signTestProof(privKey, proof)
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		wantErr bool
+	}{
+		{"just signed", signedAt, false},
+		{"exactly max age", signedAt.Add(MaxProofAge), false},
+		{"one second past max age", signedAt.Add(MaxProofAge + time.Second), true},
+		{"our clock behind within skew", signedAt.Add(-MaxProofClockSkew), false},
+		{"our clock behind beyond skew", signedAt.Add(-MaxProofClockSkew - time.Second), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewProofVerifier([]string{"app.example.com"}, clock.NewFake(tt.now))
+			err := v.Verify(hex.EncodeToString(pubKey), make([]byte, 32), 0, proof)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package ton
+
+import "bytes"
+
+// TxLink — позиция транзакции в цепочке аккаунта: каждая ссылается на предыдущую по (LT, hash).
+type TxLink struct {
+	LT       uint64
+	Hash     []byte
+	PrevLT   uint64
+	PrevHash []byte
+}
+
+// CursorOnChain reports whether the cursor transaction is still in the account's chain,
+// given the account's last transaction and the transactions newer than the cursor, oldest
+// first. False means a reorg replaced the cursor tx (or rolled it back): the indexer may have
+// acted on a transaction that no longer exists. A cursor without a hash can't be checked and
+// counts as on chain; so does a chain not fetched back far enough to reach it.
+func CursorOnChain(cursor Cursor, last TxLink, newer []TxLink) bool {
+	if len(cursor.Hash) == 0 {
+		return true
+	}
+	switch {
+	case last.LT < cursor.LT:
+		return false
+	case last.LT == cursor.LT:
+		return bytes.Equal(last.Hash, cursor.Hash)
+	case len(newer) == 0:
+		return true
+	}
+
+	oldest := newer[0]
+	switch {
+	case oldest.PrevLT == cursor.LT:
+		return bytes.Equal(oldest.PrevHash, cursor.Hash)
+	case oldest.PrevLT < cursor.LT:
+		// The chain jumps over the cursor LT: the cursor tx is gone
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,76 @@
+package ton
+
+import "testing"
+
+func TestCursorOnChain(t *testing.T) {
+	h := func(s string) []byte { return []byte(s) }
+	cursor := Cursor{LT: 100, Hash: h("c")}
+
+	tests := []struct {
+		name   string
+		cursor Cursor
+		last   TxLink
+		newer  []TxLink
+		want   bool
+	}{
+		{
+			name:   "chain extends the cursor",
+			cursor: cursor,
+			last:   TxLink{LT: 300, Hash: h("b")},
+			newer:  []TxLink{{LT: 200, Hash: h("a"), PrevLT: 100, PrevHash: h("c")}, {LT: 300, Hash: h("b"), PrevLT: 200, PrevHash: h("a")}},
+			want:   true,
+		},
+		{
+			name:   "forked: cursor LT replaced by another tx",
+			cursor: cursor,
+			last:   TxLink{LT: 300, Hash: h("b2")},
+			newer:  []TxLink{{LT: 200, Hash: h("a2"), PrevLT: 100, PrevHash: h("x")}, {LT: 300, Hash: h("b2"), PrevLT: 200, PrevHash: h("a2")}},
+			want:   false,
+		},
+		{
+			name:   "forked: chain skips the cursor LT",
+			cursor: cursor,
+			last:   TxLink{LT: 250, Hash: h("d")},
+			newer:  []TxLink{{LT: 250, Hash: h("d"), PrevLT: 90, PrevHash: h("e")}},
+			want:   false,
+		},
+		{
+			name:   "no new txs, same last tx",
+			cursor: cursor,
+			last:   TxLink{LT: 100, Hash: h("c")},
+			want:   true,
+		},
+		{
+			name:   "no new txs, last tx replaced",
+			cursor: cursor,
+			last:   TxLink{LT: 100, Hash: h("z")},
+			want:   false,
+		},
+		{
+			name:   "rolled back behind the cursor",
+			cursor: cursor,
+			last:   TxLink{LT: 80, Hash: h("y")},
+			want:   false,
+		},
+		{
+			name:   "fetched chain doesn't reach the cursor",
+			cursor: cursor,
+			last:   TxLink{LT: 500, Hash: h("f")},
+			newer:  []TxLink{{LT: 500, Hash: h("f"), PrevLT: 400, PrevHash: h("g")}},
+			want:   true,
+		},
+		{
+			name:   "cursor without hash",
+			cursor: Cursor{LT: 100},
+			last:   TxLink{LT: 80, Hash: h("y")},
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CursorOnChain(tt.cursor, tt.last, tt.newer); got != tt.want {
+				t.Errorf("CursorOnChain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,3 @@
+-- 008_pending_admin_actions.down.sql
+
+DROP TABLE IF EXISTS pending_admin_actions;
@@ -0,0 +1,22 @@
+-- 008_pending_admin_actions.up.sql
+-- Two-person approval for high-risk admin operations (fund movements)
+
+CREATE TABLE pending_admin_actions (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    action          TEXT NOT NULL,              -- force_release / force_refund / manual_escrow_match
+    entity_type     TEXT NOT NULL,
+    entity_id       UUID NOT NULL,
+    params          JSONB NOT NULL DEFAULT '{}',
+    status          TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'executed', 'failed')),
+    requested_by    UUID NOT NULL REFERENCES users(id),
+    approved_by     UUID REFERENCES users(id),
+    error           TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    approved_at     TIMESTAMPTZ,
+
+    CHECK (approved_by IS NULL OR approved_by <> requested_by)
+);
+
+CREATE INDEX idx_pending_admin_actions_status ON pending_admin_actions(status, created_at DESC);
+CREATE INDEX idx_pending_admin_actions_entity ON pending_admin_actions(entity_type, entity_id);
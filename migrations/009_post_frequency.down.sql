@@ -0,0 +1,5 @@
+-- 009_post_frequency.down.sql
+
+ALTER TABLE channel_stats_snapshots
+    DROP COLUMN IF EXISTS post_frequency,
+    DROP COLUMN IF EXISTS has_pinned_post;
@@ -0,0 +1,6 @@
+-- 009_post_frequency.up.sql
+-- Posting cadence and pinned post flag derived from the t.me public page
+
+ALTER TABLE channel_stats_snapshots
+    ADD COLUMN post_frequency DOUBLE PRECISION,
+    ADD COLUMN has_pinned_post BOOLEAN;
@@ -0,0 +1,6 @@
+-- 010_per_format_lead_time.down.sql
+
+ALTER TABLE channel_listings
+    DROP COLUMN IF EXISTS min_lead_post_minutes,
+    DROP COLUMN IF EXISTS min_lead_repost_minutes,
+    DROP COLUMN IF EXISTS min_lead_story_minutes;
@@ -0,0 +1,13 @@
+-- 010_per_format_lead_time.up.sql
+-- Per-format minimum lead time; NULL falls back to min_lead_time_minutes
+
+ALTER TABLE channel_listings
+    ADD COLUMN min_lead_post_minutes   INT,
+    ADD COLUMN min_lead_repost_minutes INT,
+    ADD COLUMN min_lead_story_minutes  INT;
+
+-- Переносим существующее единое значение во все форматы
+UPDATE channel_listings
+SET min_lead_post_minutes   = min_lead_time_minutes,
+    min_lead_repost_minutes = min_lead_time_minutes,
+    min_lead_story_minutes  = min_lead_time_minutes;
@@ -0,0 +1,3 @@
+-- 011_channel_stats_failures.down.sql
+
+DROP TABLE IF EXISTS channel_stats_failures;
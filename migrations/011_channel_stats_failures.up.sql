@@ -0,0 +1,12 @@
+-- 011_channel_stats_failures.up.sql
+-- Track stats refresh failures per channel to back off and surface dead/renamed channels
+
+CREATE TABLE channel_stats_failures (
+    channel_id           UUID PRIMARY KEY REFERENCES channels(id) ON DELETE CASCADE,
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    last_error           TEXT,
+    last_failed_at       TIMESTAMPTZ,
+    next_attempt_at      TIMESTAMPTZ
+);
+
+CREATE INDEX idx_channel_stats_failures_count ON channel_stats_failures(consecutive_failures DESC);
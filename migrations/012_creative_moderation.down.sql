@@ -0,0 +1,12 @@
+-- 012_creative_moderation.down.sql
+
+DROP INDEX IF EXISTS idx_deal_creatives_review;
+
+ALTER TABLE deal_creatives DROP COLUMN IF EXISTS moderation_reason;
+
+UPDATE deal_creatives SET status = 'pending' WHERE status = 'pending_review';
+UPDATE deal_creatives SET status = 'changes_requested' WHERE status = 'rejected';
+
+ALTER TABLE deal_creatives DROP CONSTRAINT IF EXISTS deal_creatives_status_check;
+ALTER TABLE deal_creatives ADD CONSTRAINT deal_creatives_status_check
+    CHECK (status IN ('pending', 'submitted', 'changes_requested', 'approved'));
@@ -0,0 +1,11 @@
+-- 012_creative_moderation.up.sql
+-- Moderation of creatives: pending_review queue for admins, rejected by blocklist/webhook
+
+ALTER TABLE deal_creatives DROP CONSTRAINT IF EXISTS deal_creatives_status_check;
+ALTER TABLE deal_creatives ADD CONSTRAINT deal_creatives_status_check
+    CHECK (status IN ('pending', 'submitted', 'changes_requested', 'approved', 'pending_review', 'rejected'));
+
+ALTER TABLE deal_creatives
+    ADD COLUMN moderation_reason TEXT;
+
+CREATE INDEX idx_deal_creatives_review ON deal_creatives(created_at) WHERE status = 'pending_review';
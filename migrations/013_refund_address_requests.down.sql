@@ -0,0 +1,4 @@
+-- 013_refund_address_requests.down.sql
+
+ALTER TABLE escrow_ledger DROP COLUMN IF EXISTS refund_address;
+DROP TABLE IF EXISTS refund_address_requests;
@@ -0,0 +1,25 @@
+-- 013_refund_address_requests.up.sql
+-- Advertiser-requested refund to an alternate (TON Proof verified) address, approved by an admin
+
+CREATE TABLE refund_address_requests (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    deal_id         UUID NOT NULL REFERENCES deals(id) ON DELETE CASCADE,
+    requested_by    UUID NOT NULL REFERENCES users(id),
+    wallet_id       UUID NOT NULL REFERENCES user_wallets(id),
+    address         TEXT NOT NULL,
+    status          TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected')),
+    reviewed_by     UUID REFERENCES users(id),
+    reason          TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    reviewed_at     TIMESTAMPTZ,
+
+    CHECK (reviewed_by IS NULL OR reviewed_by <> requested_by)
+);
+
+-- Не более одной открытой заявки на сделку
+CREATE UNIQUE INDEX idx_refund_address_requests_pending ON refund_address_requests(deal_id) WHERE status = 'pending';
+CREATE INDEX idx_refund_address_requests_status ON refund_address_requests(status, created_at DESC);
+
+-- Approved override; NULL = refund to payer_address
+ALTER TABLE escrow_ledger ADD COLUMN refund_address TEXT;
@@ -0,0 +1,9 @@
+-- 014_account_deletion.down.sql
+
+-- Anonymized rows get negative placeholder ids so NOT NULL can be restored
+UPDATE users u SET telegram_user_id = -x.n
+FROM (SELECT id, row_number() OVER (ORDER BY id) AS n FROM users WHERE telegram_user_id IS NULL) x
+WHERE u.id = x.id;
+
+ALTER TABLE users ALTER COLUMN telegram_user_id SET NOT NULL;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
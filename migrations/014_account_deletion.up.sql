@@ -0,0 +1,7 @@
+-- 014_account_deletion.up.sql
+-- Account deletion: the users row is kept (deal/escrow FKs) but anonymized
+
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- Освобождаем telegram_user_id, чтобы повторный вход создавал новый аккаунт
+ALTER TABLE users ALTER COLUMN telegram_user_id DROP NOT NULL;
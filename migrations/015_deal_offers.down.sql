@@ -0,0 +1,3 @@
+-- 015_deal_offers.down.sql
+
+DROP TABLE IF EXISTS deal_offers;
@@ -0,0 +1,25 @@
+-- 015_deal_offers.up.sql
+-- Owner-initiated offers: a channel offers a slot at fixed terms, an advertiser accepts to get a deal
+
+CREATE TABLE deal_offers (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    channel_id          UUID NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    created_by          UUID NOT NULL REFERENCES users(id),
+    advertiser_user_id  UUID REFERENCES users(id),          -- NULL = public offer
+    ad_format           TEXT NOT NULL DEFAULT 'post'
+        CHECK (ad_format IN ('post', 'repost', 'story')),
+    price_ton           NUMERIC(30, 9) NOT NULL CHECK (price_ton > 0),
+    brief               TEXT,
+    scheduled_at        TIMESTAMPTZ,
+    valid_until         TIMESTAMPTZ NOT NULL,
+    status              TEXT NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'accepted', 'cancelled')),
+    deal_id             UUID REFERENCES deals(id),
+    accepted_by         UUID REFERENCES users(id),
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    accepted_at         TIMESTAMPTZ
+);
+
+CREATE INDEX idx_deal_offers_channel ON deal_offers(channel_id, created_at DESC);
+CREATE INDEX idx_deal_offers_open ON deal_offers(valid_until) WHERE status = 'open';
+CREATE INDEX idx_deal_offers_advertiser ON deal_offers(advertiser_user_id) WHERE status = 'open';
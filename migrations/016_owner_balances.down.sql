@@ -0,0 +1,4 @@
+-- 016_owner_balances.down.sql
+
+DROP TABLE IF EXISTS balance_ledger;
+DROP TABLE IF EXISTS withdrawals;
@@ -0,0 +1,31 @@
+-- 016_owner_balances.up.sql
+-- Per-owner balance: completed deals credit the channel owner's net payout, withdrawals debit it.
+-- Small payouts accumulate until the balance exceeds MIN_PAYOUT_TON instead of being sent per deal.
+
+CREATE TABLE withdrawals (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id         UUID NOT NULL REFERENCES users(id),
+    amount_ton      NUMERIC(30, 9) NOT NULL CHECK (amount_ton > 0),
+    wallet_address  TEXT NOT NULL,
+    status          TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'sent', 'failed')),
+    tx_hash         TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_withdrawals_user ON withdrawals(user_id, created_at DESC);
+
+CREATE TABLE balance_ledger (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id         UUID NOT NULL REFERENCES users(id),
+    amount_ton      NUMERIC(30, 9) NOT NULL,   -- > 0 credit, < 0 debit
+    deal_id         UUID REFERENCES deals(id),
+    withdrawal_id   UUID REFERENCES withdrawals(id),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK ((deal_id IS NULL) <> (withdrawal_id IS NULL))
+);
+
+CREATE INDEX idx_balance_ledger_user ON balance_ledger(user_id);
+-- A deal is credited at most once (release may be retried / forced by admin)
+CREATE UNIQUE INDEX idx_balance_ledger_deal ON balance_ledger(deal_id) WHERE deal_id IS NOT NULL;
@@ -0,0 +1,4 @@
+-- 017_skip_creative_approval.down.sql
+
+ALTER TABLE deals DROP COLUMN IF EXISTS skip_creative_approval;
+ALTER TABLE channel_listings DROP COLUMN IF EXISTS allow_skip_creative_approval;
@@ -0,0 +1,9 @@
+-- 017_skip_creative_approval.up.sql
+-- Opt-out of the creative approval loop for trusted repeat collaborations.
+-- The owner allows it on the listing, the advertiser opts in per deal; only both together skip approval.
+
+ALTER TABLE channel_listings
+    ADD COLUMN allow_skip_creative_approval BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE deals
+    ADD COLUMN skip_creative_approval BOOLEAN NOT NULL DEFAULT false;
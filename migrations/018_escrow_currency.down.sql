@@ -0,0 +1,3 @@
+-- 018_escrow_currency.down.sql
+
+ALTER TABLE escrow_ledger DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,5 @@
+-- 018_escrow_currency.up.sql
+-- Escrow asset: native TON or the USDT jetton (6 decimals). Existing escrows are TON.
+
+ALTER TABLE escrow_ledger
+    ADD COLUMN currency TEXT NOT NULL DEFAULT 'TON' CHECK (currency IN ('TON', 'USDT'));
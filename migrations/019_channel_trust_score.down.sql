@@ -0,0 +1,6 @@
+-- 019_channel_trust_score.down.sql
+
+DROP INDEX IF EXISTS idx_channels_trust_score;
+ALTER TABLE channels
+    DROP COLUMN IF EXISTS trust_score_updated_at,
+    DROP COLUMN IF EXISTS trust_score;
@@ -0,0 +1,8 @@
+-- 019_channel_trust_score.up.sql
+-- Computed channel quality indicator (0..100), refreshed by the worker; see models.TrustScore.
+
+ALTER TABLE channels
+    ADD COLUMN trust_score            DOUBLE PRECISION,
+    ADD COLUMN trust_score_updated_at TIMESTAMPTZ;
+
+CREATE INDEX idx_channels_trust_score ON channels(trust_score DESC NULLS LAST);
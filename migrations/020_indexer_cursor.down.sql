@@ -0,0 +1,3 @@
+-- 020_indexer_cursor.down.sql
+
+DROP TABLE IF EXISTS indexer_cursor;
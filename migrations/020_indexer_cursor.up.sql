@@ -0,0 +1,10 @@
+-- 020_indexer_cursor.up.sql
+-- TON indexer position, one row per hot wallet. Source of truth; Redis only caches it.
+-- Advanced in the same transaction that funds an escrow, so it never passes an unprocessed payment.
+
+CREATE TABLE indexer_cursor (
+    wallet_address  TEXT PRIMARY KEY,
+    lt              BIGINT NOT NULL,
+    hash            BYTEA,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,7 @@
+-- 021_withdrawal_sending.down.sql
+
+DROP INDEX IF EXISTS idx_withdrawals_pending;
+UPDATE withdrawals SET status = 'failed' WHERE status = 'sending';
+ALTER TABLE withdrawals DROP CONSTRAINT withdrawals_status_check;
+ALTER TABLE withdrawals ADD CONSTRAINT withdrawals_status_check
+    CHECK (status IN ('pending', 'sent', 'failed'));
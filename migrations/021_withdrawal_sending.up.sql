@@ -0,0 +1,9 @@
+-- 021_withdrawal_sending.up.sql
+-- The worker claims a pending withdrawal ('sending') before signing the transfer.
+-- A withdrawal left in 'sending' after a crash is never re-sent automatically: check the chain first.
+
+ALTER TABLE withdrawals DROP CONSTRAINT withdrawals_status_check;
+ALTER TABLE withdrawals ADD CONSTRAINT withdrawals_status_check
+    CHECK (status IN ('pending', 'sending', 'sent', 'failed'));
+
+CREATE INDEX idx_withdrawals_pending ON withdrawals(created_at) WHERE status = 'pending';
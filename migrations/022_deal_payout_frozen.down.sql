@@ -0,0 +1,3 @@
+-- 022_deal_payout_frozen.down.sql
+
+ALTER TABLE deals DROP COLUMN IF EXISTS payout_frozen;
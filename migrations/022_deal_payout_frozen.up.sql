@@ -0,0 +1,5 @@
+-- 022_deal_payout_frozen.up.sql
+-- Support can freeze a suspicious deal's automatic release without opening a dispute.
+-- The deal stays in hold_verification until unfrozen; the reason lives in audit_logs.
+
+ALTER TABLE deals ADD COLUMN payout_frozen BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,3 @@
+-- 023_withdrawal_tx_comment.down.sql
+
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS tx_comment;
@@ -0,0 +1,5 @@
+-- 023_withdrawal_tx_comment.up.sql
+-- Comment attached to the payout transfer (TON_SEND_COMMENT_TEMPLATE). Written before the
+-- transfer is signed, so a withdrawal stuck in 'sending' can still be found on-chain.
+
+ALTER TABLE withdrawals ADD COLUMN tx_comment TEXT;
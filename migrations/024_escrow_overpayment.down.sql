@@ -0,0 +1,5 @@
+-- 024_escrow_overpayment.down.sql
+
+DROP TABLE IF EXISTS overpayment_refunds;
+DROP INDEX IF EXISTS idx_escrow_overpaid;
+ALTER TABLE escrow_ledger DROP COLUMN IF EXISTS overpaid_nano;
@@ -0,0 +1,21 @@
+-- 024_escrow_overpayment.up.sql
+-- Excess of the funding payment over deposit_expected_ton, in the currency's smallest units.
+-- Refunds are keyed on the funding tx LT: the row is claimed ('sending') before the transfer
+-- is signed, so a worker retry never refunds the same overpayment twice.
+
+ALTER TABLE escrow_ledger ADD COLUMN overpaid_nano NUMERIC(40, 0) CHECK (overpaid_nano > 0);
+
+CREATE TABLE overpayment_refunds (
+    funding_lt      BIGINT PRIMARY KEY,
+    deal_id         UUID NOT NULL REFERENCES deals(id),
+    amount_nano     NUMERIC(40, 0) NOT NULL CHECK (amount_nano > 0),
+    to_address      TEXT NOT NULL,
+    status          TEXT NOT NULL DEFAULT 'sending'
+        CHECK (status IN ('sending', 'sent', 'failed')),
+    tx_hash         TEXT,
+    tx_comment      TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_escrow_overpaid ON escrow_ledger(deal_id) WHERE overpaid_nano IS NOT NULL;
@@ -0,0 +1,14 @@
+-- 025_deal_disputed.down.sql
+
+UPDATE deals SET status = 'hold_verification' WHERE status = 'disputed';
+ALTER TABLE deals DROP CONSTRAINT deals_status_check;
+ALTER TABLE deals ADD CONSTRAINT deals_status_check
+    CHECK (status IN (
+        'draft', 'submitted', 'rejected', 'accepted',
+        'awaiting_payment', 'funded',
+        'creative_pending', 'creative_submitted',
+        'creative_changes_requested', 'creative_approved',
+        'scheduled', 'posted',
+        'hold_verification', 'hold_verification_failed',
+        'completed', 'refunded', 'cancelled'
+    ));
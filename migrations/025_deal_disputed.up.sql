@@ -0,0 +1,15 @@
+-- 025_deal_disputed.up.sql
+-- An advertiser can contest a live post; the deal waits in 'disputed' until an admin
+-- releases or refunds it. The hold-release job only picks up hold_verification deals.
+
+ALTER TABLE deals DROP CONSTRAINT deals_status_check;
+ALTER TABLE deals ADD CONSTRAINT deals_status_check
+    CHECK (status IN (
+        'draft', 'submitted', 'rejected', 'accepted',
+        'awaiting_payment', 'funded',
+        'creative_pending', 'creative_submitted',
+        'creative_changes_requested', 'creative_approved',
+        'scheduled', 'posted',
+        'hold_verification', 'hold_verification_failed', 'disputed',
+        'completed', 'refunded', 'cancelled'
+    ));
@@ -0,0 +1,3 @@
+-- 026_deal_counteroffers.down.sql
+
+DROP TABLE IF EXISTS deal_counteroffers;
@@ -0,0 +1,18 @@
+-- 026_deal_counteroffers.up.sql
+-- Price negotiation on submitted deals. The channel side proposes a price; at most one
+-- proposal per deal is pending, a newer one supersedes it. Accepting it sets deals.price_ton.
+-- (deal_offers is taken by owner-initiated offers.)
+
+CREATE TABLE deal_counteroffers (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    deal_id     UUID NOT NULL REFERENCES deals(id),
+    proposed_by UUID NOT NULL REFERENCES users(id),
+    price_ton   NUMERIC(30, 9) NOT NULL CHECK (price_ton > 0),
+    status      TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'accepted', 'rejected', 'superseded')),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_deal_counteroffers_deal ON deal_counteroffers(deal_id, created_at);
+CREATE UNIQUE INDEX idx_deal_counteroffers_pending ON deal_counteroffers(deal_id) WHERE status = 'pending';
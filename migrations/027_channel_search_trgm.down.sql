@@ -0,0 +1,5 @@
+-- 027_channel_search_trgm.down.sql
+
+DROP INDEX IF EXISTS idx_channel_listings_description_trgm;
+DROP INDEX IF EXISTS idx_channels_username_trgm;
+DROP INDEX IF EXISTS idx_channels_title_trgm;
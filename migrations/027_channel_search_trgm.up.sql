@@ -0,0 +1,9 @@
+-- 027_channel_search_trgm.up.sql
+-- Keyword search (?q=) matches ILIKE '%…%' on channel title/username and listing description;
+-- trigram GIN indexes keep it off a sequential scan as the catalog grows.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_channels_title_trgm ON channels USING gin (title gin_trgm_ops);
+CREATE INDEX idx_channels_username_trgm ON channels USING gin (username gin_trgm_ops);
+CREATE INDEX idx_channel_listings_description_trgm ON channel_listings USING gin (description gin_trgm_ops);
@@ -0,0 +1,5 @@
+-- 028_deal_post_story.down.sql
+
+ALTER TABLE deal_posts
+    DROP COLUMN IF EXISTS story_views,
+    DROP COLUMN IF EXISTS story_id;
@@ -0,0 +1,7 @@
+-- 028_deal_post_story.up.sql
+-- Stories vanish after 24h by design: the worker checks them via the userbot (by story id)
+-- until story_expires_at and records the view count it saw.
+
+ALTER TABLE deal_posts
+    ADD COLUMN story_id    BIGINT,
+    ADD COLUMN story_views INT;
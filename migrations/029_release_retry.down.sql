@@ -0,0 +1,18 @@
+-- 029_release_retry.down.sql
+
+UPDATE deals SET status = 'hold_verification' WHERE status = 'release_failed';
+ALTER TABLE deals DROP CONSTRAINT deals_status_check;
+ALTER TABLE deals ADD CONSTRAINT deals_status_check
+    CHECK (status IN (
+        'draft', 'submitted', 'rejected', 'accepted',
+        'awaiting_payment', 'funded',
+        'creative_pending', 'creative_submitted',
+        'creative_changes_requested', 'creative_approved',
+        'scheduled', 'posted',
+        'hold_verification', 'hold_verification_failed', 'disputed',
+        'completed', 'refunded', 'cancelled'
+    ));
+
+ALTER TABLE escrow_ledger
+    DROP COLUMN IF EXISTS last_release_attempt_at,
+    DROP COLUMN IF EXISTS release_attempts;
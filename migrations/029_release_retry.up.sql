@@ -0,0 +1,19 @@
+-- 029_release_retry.up.sql
+-- Failed fund releases back off exponentially; after RELEASE_MAX_ATTEMPTS the deal moves to
+-- 'release_failed' and waits for an admin (force-release / force-refund).
+
+ALTER TABLE escrow_ledger
+    ADD COLUMN release_attempts        INT NOT NULL DEFAULT 0,
+    ADD COLUMN last_release_attempt_at TIMESTAMPTZ;
+
+ALTER TABLE deals DROP CONSTRAINT deals_status_check;
+ALTER TABLE deals ADD CONSTRAINT deals_status_check
+    CHECK (status IN (
+        'draft', 'submitted', 'rejected', 'accepted',
+        'awaiting_payment', 'funded',
+        'creative_pending', 'creative_submitted',
+        'creative_changes_requested', 'creative_approved',
+        'scheduled', 'posted',
+        'hold_verification', 'hold_verification_failed', 'disputed', 'release_failed',
+        'completed', 'refunded', 'cancelled'
+    ));
@@ -0,0 +1,5 @@
+-- 030_stats_scam_fake.down.sql
+
+ALTER TABLE channel_stats_snapshots
+    DROP COLUMN IF EXISTS fake_badge,
+    DROP COLUMN IF EXISTS scam_badge;
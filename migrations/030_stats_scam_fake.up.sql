@@ -0,0 +1,7 @@
+-- 030_stats_scam_fake.up.sql
+-- Telegram's "scam" / "fake" labels from the channel page; either one zeroes the trust score
+-- and is shown on explore so advertisers don't buy into flagged channels.
+
+ALTER TABLE channel_stats_snapshots
+    ADD COLUMN scam_badge BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN fake_badge BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,4 @@
+-- 031_deals_keyset.down.sql
+
+DROP INDEX IF EXISTS idx_deals_channel_created;
+DROP INDEX IF EXISTS idx_deals_advertiser_created;
@@ -0,0 +1,5 @@
+-- 031_deals_keyset.up.sql
+-- Cursor pagination of deal lists walks (created_at, id) newest-first per party.
+
+CREATE INDEX idx_deals_advertiser_created ON deals (advertiser_user_id, created_at DESC, id DESC);
+CREATE INDEX idx_deals_channel_created ON deals (channel_id, created_at DESC, id DESC);
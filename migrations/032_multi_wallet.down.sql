@@ -0,0 +1,10 @@
+-- 032_multi_wallet.down.sql
+
+-- Back to a single active wallet per user: keep the primary one
+UPDATE user_wallets SET is_active = false, disconnected_at = now()
+WHERE is_active AND NOT is_primary;
+
+DROP INDEX IF EXISTS idx_user_wallets_primary;
+ALTER TABLE user_wallets
+    DROP COLUMN IF EXISTS is_primary,
+    DROP COLUMN IF EXISTS label;
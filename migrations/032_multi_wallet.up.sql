@@ -0,0 +1,17 @@
+-- 032_multi_wallet.up.sql
+-- A user may keep several wallets connected (e.g. separate deposit and withdraw wallets).
+-- Exactly one active wallet is primary: the one GET /me/wallet and payouts default to.
+
+ALTER TABLE user_wallets
+    ADD COLUMN label      TEXT,
+    ADD COLUMN is_primary BOOLEAN NOT NULL DEFAULT false;
+
+-- Until now the latest active wallet was the only one
+UPDATE user_wallets w SET is_primary = true
+WHERE w.is_active AND w.id = (
+    SELECT id FROM user_wallets
+    WHERE user_id = w.user_id AND is_active
+    ORDER BY connected_at DESC LIMIT 1
+);
+
+CREATE UNIQUE INDEX idx_user_wallets_primary ON user_wallets(user_id) WHERE is_primary;
@@ -0,0 +1,3 @@
+-- 033_withdrawal_requests.down.sql
+
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS failure_reason;
@@ -0,0 +1,4 @@
+-- 033_withdrawal_requests.up.sql
+-- Withdrawals of part of the balance, and why a payout failed (shown to the owner and support).
+
+ALTER TABLE withdrawals ADD COLUMN failure_reason TEXT;
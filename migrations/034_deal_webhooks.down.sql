@@ -0,0 +1,4 @@
+-- 034_deal_webhooks.down.sql
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS deal_webhooks;
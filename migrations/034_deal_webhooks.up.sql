@@ -0,0 +1,27 @@
+-- 034_deal_webhooks.up.sql
+-- Webhooks called when a deal reaches a terminal state, and a log of every delivery attempt
+
+CREATE TABLE deal_webhooks (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url         TEXT NOT NULL,
+    secret      TEXT NOT NULL,                 -- HMAC key for the payload signature
+    events      TEXT[] NOT NULL,               -- deal statuses to notify about
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_deal_webhooks_user ON deal_webhooks(user_id);
+
+CREATE TABLE webhook_deliveries (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id   UUID NOT NULL REFERENCES deal_webhooks(id) ON DELETE CASCADE,
+    deal_id      UUID NOT NULL REFERENCES deals(id) ON DELETE CASCADE,
+    event        TEXT NOT NULL,
+    attempt      INT NOT NULL,
+    status_code  INT,                          -- NULL when no response was received
+    error        TEXT,
+    delivered    BOOLEAN NOT NULL DEFAULT false,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at DESC);
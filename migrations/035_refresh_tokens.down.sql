@@ -0,0 +1,3 @@
+-- 035_refresh_tokens.down.sql
+
+DROP TABLE IF EXISTS refresh_tokens;
@@ -0,0 +1,17 @@
+-- 035_refresh_tokens.up.sql
+-- Refresh tokens for short-lived access JWTs. Only the sha256 of a token is stored; each
+-- use rotates it, and tokens of one login share a family so a replayed one revokes them all.
+
+CREATE TABLE refresh_tokens (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id    UUID NOT NULL,
+    token_hash   TEXT NOT NULL UNIQUE,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at   TIMESTAMPTZ,
+    replaced_by  UUID REFERENCES refresh_tokens(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_refresh_tokens_user ON refresh_tokens(user_id);
+CREATE INDEX idx_refresh_tokens_family ON refresh_tokens(family_id) WHERE revoked_at IS NULL;
@@ -0,0 +1,7 @@
+-- 036_bot_post_retry.down.sql
+
+DROP INDEX IF EXISTS idx_deals_scheduled_due;
+ALTER TABLE deals
+    DROP COLUMN IF EXISTS post_attempts,
+    DROP COLUMN IF EXISTS last_post_attempt_at,
+    DROP COLUMN IF EXISTS last_post_error;
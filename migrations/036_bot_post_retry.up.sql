@@ -0,0 +1,10 @@
+-- 036_bot_post_retry.up.sql
+-- Scheduled deals are posted by the bot; a failed attempt keeps the deal scheduled and is
+-- retried with exponential backoff.
+
+ALTER TABLE deals
+    ADD COLUMN post_attempts        INT NOT NULL DEFAULT 0,
+    ADD COLUMN last_post_attempt_at TIMESTAMPTZ,
+    ADD COLUMN last_post_error      TEXT;
+
+CREATE INDEX idx_deals_scheduled_due ON deals(scheduled_at) WHERE status = 'scheduled';
@@ -0,0 +1,3 @@
+-- 037_listing_refund_on_edit.down.sql
+
+ALTER TABLE channel_listings DROP COLUMN IF EXISTS refund_on_edit;
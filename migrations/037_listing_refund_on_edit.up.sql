@@ -0,0 +1,6 @@
+-- 037_listing_refund_on_edit.up.sql
+-- Per-listing policy: a post edited during the hold fails verification and the deal is refunded.
+-- Off by default, an edit is then only flagged (deal_posts.is_edited).
+
+ALTER TABLE channel_listings
+    ADD COLUMN refund_on_edit BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,3 @@
+-- 038_audit_action_index.down.sql
+
+DROP INDEX IF EXISTS idx_audit_action;
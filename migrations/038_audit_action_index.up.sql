@@ -0,0 +1,4 @@
+-- 038_audit_action_index.up.sql
+-- Admin audit search filters by action prefix (action LIKE 'wallet_%'), newest first.
+
+CREATE INDEX IF NOT EXISTS idx_audit_action ON audit_log(action text_pattern_ops, created_at DESC);
@@ -0,0 +1,3 @@
+-- 039_unmatched_payments.down.sql
+
+DROP TABLE IF EXISTS unmatched_payments;
@@ -0,0 +1,20 @@
+-- 039_unmatched_payments.up.sql
+-- Incoming payments whose memo matched no escrow (typo, or the payment landed before the
+-- escrow was created). The worker retries matching; staff credit the rest manually.
+
+CREATE TABLE IF NOT EXISTS unmatched_payments (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tx_lt         BIGINT NOT NULL UNIQUE,
+    tx_hash       TEXT NOT NULL,
+    from_address  TEXT NOT NULL,
+    amount        NUMERIC(40, 0) NOT NULL, -- smallest units of currency
+    currency      TEXT NOT NULL,
+    memo          TEXT NOT NULL,
+    status        TEXT NOT NULL DEFAULT 'unmatched', -- unmatched / matched
+    deal_id       UUID REFERENCES deals(id),
+    matched_by    UUID REFERENCES users(id),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    matched_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_unmatched_payments_status ON unmatched_payments(status, created_at);
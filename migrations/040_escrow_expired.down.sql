@@ -0,0 +1,6 @@
+-- 040_escrow_expired.down.sql
+
+UPDATE escrow_ledger SET status = 'awaiting' WHERE status = 'expired';
+ALTER TABLE escrow_ledger DROP CONSTRAINT escrow_ledger_status_check;
+ALTER TABLE escrow_ledger ADD CONSTRAINT escrow_ledger_status_check
+    CHECK (status IN ('awaiting', 'funded', 'released', 'refunded'));
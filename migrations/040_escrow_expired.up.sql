@@ -0,0 +1,7 @@
+-- 040_escrow_expired.up.sql
+-- An awaiting escrow whose deal was cancelled (or timed out) expires: a late payment is no
+-- longer credited to it and goes to unmatched_payments for staff instead.
+
+ALTER TABLE escrow_ledger DROP CONSTRAINT escrow_ledger_status_check;
+ALTER TABLE escrow_ledger ADD CONSTRAINT escrow_ledger_status_check
+    CHECK (status IN ('awaiting', 'funded', 'released', 'refunded', 'expired'));
@@ -0,0 +1,6 @@
+-- 041_escrow_refund_send.down.sql
+
+DROP INDEX IF EXISTS idx_escrow_refund_pending;
+UPDATE escrow_ledger SET status = 'refunded', refunded_at = now(), refund_tx_hash = 'pending_send'
+WHERE status = 'funded' AND refund_status = 'pending';
+ALTER TABLE escrow_ledger DROP COLUMN refund_status;
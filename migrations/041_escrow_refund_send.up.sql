@@ -0,0 +1,13 @@
+-- 041_escrow_refund_send.up.sql
+-- Refunds of a funded escrow are sent from the hot wallet: queued ('pending'), claimed
+-- ('sending') before the transfer is signed so a retry never refunds twice, then 'sent' with
+-- the escrow 'refunded'. A 'failed' refund keeps its claim: support checks the chain first.
+
+ALTER TABLE escrow_ledger ADD COLUMN refund_status TEXT
+    CHECK (refund_status IN ('pending', 'sending', 'sent', 'failed'));
+
+-- Refunds queued before this migration were only marked, never sent
+UPDATE escrow_ledger SET status = 'funded', refund_status = 'pending', refunded_at = NULL, refund_tx_hash = NULL
+WHERE status = 'refunded' AND refund_tx_hash = 'pending_send';
+
+CREATE INDEX idx_escrow_refund_pending ON escrow_ledger(deal_id) WHERE refund_status = 'pending';
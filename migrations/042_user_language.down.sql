@@ -0,0 +1,3 @@
+-- 042_user_language.down.sql
+
+ALTER TABLE users DROP COLUMN language_code;
@@ -0,0 +1,4 @@
+-- 042_user_language.up.sql
+-- Telegram language_code of the user (e.g. "en", "ru", "pt-br"); picks the language of bot notifications.
+
+ALTER TABLE users ADD COLUMN language_code TEXT;
@@ -0,0 +1,3 @@
+-- 043_user_photo_url.down.sql
+
+ALTER TABLE users DROP COLUMN photo_url;
@@ -0,0 +1,4 @@
+-- 043_user_photo_url.up.sql
+-- Telegram avatar URL from Mini App initData, so the frontend can show it without asking the bot.
+
+ALTER TABLE users ADD COLUMN photo_url TEXT;
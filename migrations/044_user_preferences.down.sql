@@ -0,0 +1,5 @@
+-- 044_user_preferences.down.sql
+
+ALTER TABLE users
+    DROP COLUMN language_chosen,
+    DROP COLUMN notifications_enabled;
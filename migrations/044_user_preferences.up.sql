@@ -0,0 +1,7 @@
+-- 044_user_preferences.up.sql
+-- Profile preferences set through PATCH /me. A language chosen there is kept over the
+-- Telegram language_code sent at each login.
+
+ALTER TABLE users
+    ADD COLUMN notifications_enabled BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN language_chosen BOOLEAN NOT NULL DEFAULT false;
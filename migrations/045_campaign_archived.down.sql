@@ -0,0 +1,6 @@
+-- 045_campaign_archived.down.sql
+
+UPDATE campaigns SET status = 'cancelled' WHERE status = 'archived';
+ALTER TABLE campaigns DROP CONSTRAINT campaigns_status_check;
+ALTER TABLE campaigns ADD CONSTRAINT campaigns_status_check
+    CHECK (status IN ('active', 'paused', 'completed', 'cancelled'));
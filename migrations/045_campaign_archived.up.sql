@@ -0,0 +1,7 @@
+-- 045_campaign_archived.up.sql
+-- DELETE /campaigns/:id archives the campaign instead of removing the row, so the
+-- advertiser's history and references to it survive.
+
+ALTER TABLE campaigns DROP CONSTRAINT campaigns_status_check;
+ALTER TABLE campaigns ADD CONSTRAINT campaigns_status_check
+    CHECK (status IN ('active', 'paused', 'completed', 'cancelled', 'archived'));
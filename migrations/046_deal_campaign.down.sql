@@ -0,0 +1,4 @@
+-- 046_deal_campaign.down.sql
+
+DROP INDEX IF EXISTS idx_deals_campaign;
+ALTER TABLE deals DROP COLUMN campaign_id;
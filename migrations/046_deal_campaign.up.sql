@@ -0,0 +1,5 @@
+-- 046_deal_campaign.up.sql
+-- Deals created to fulfil a campaign point at it, so spend can be tracked against its budget.
+
+ALTER TABLE deals ADD COLUMN campaign_id UUID REFERENCES campaigns(id);
+CREATE INDEX idx_deals_campaign ON deals(campaign_id) WHERE campaign_id IS NOT NULL;
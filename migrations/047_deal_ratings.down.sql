@@ -0,0 +1,3 @@
+-- 047_deal_ratings.down.sql
+
+DROP TABLE IF EXISTS deal_ratings;
@@ -0,0 +1,16 @@
+-- 047_deal_ratings.up.sql
+-- After a completed deal each side rates the other: the advertiser rates the channel,
+-- the channel owner rates the advertiser. One rating per deal per rater.
+
+CREATE TABLE deal_ratings (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    deal_id       UUID NOT NULL REFERENCES deals(id) ON DELETE CASCADE,
+    rater_user_id UUID NOT NULL REFERENCES users(id),
+    ratee_type    TEXT NOT NULL CHECK (ratee_type IN ('channel', 'advertiser')),
+    stars         SMALLINT NOT NULL CHECK (stars BETWEEN 1 AND 5),
+    comment       TEXT,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (deal_id, rater_user_id)
+);
+
+CREATE INDEX idx_deal_ratings_ratee ON deal_ratings(ratee_type, deal_id);
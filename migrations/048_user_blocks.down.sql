@@ -0,0 +1,3 @@
+-- 048_user_blocks.down.sql
+
+DROP TABLE IF EXISTS user_blocks;
@@ -0,0 +1,14 @@
+-- 048_user_blocks.up.sql
+-- Hard block between users: no new deals between a blocked advertiser and any channel
+-- the blocker is a member of, and such channels drop out of each other's explore results.
+
+CREATE TABLE user_blocks (
+    blocker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id)
+);
+
+-- The primary key serves lookups by blocker; this one the reverse direction
+CREATE INDEX idx_user_blocks_blocked ON user_blocks(blocked_id, blocker_id);
@@ -0,0 +1,5 @@
+-- 049_unmatched_pending.down.sql
+
+DROP INDEX IF EXISTS idx_unmatched_payments_pending;
+UPDATE unmatched_payments SET status = 'unmatched' WHERE status = 'pending';
+ALTER TABLE unmatched_payments DROP COLUMN match_deadline;
@@ -0,0 +1,9 @@
+-- 049_unmatched_pending.up.sql
+-- A payment with a well-formed deal memo but no escrow yet (paid before the deal was
+-- accepted) is parked as 'pending': the indexer retries it every poll cycle until
+-- match_deadline, then escalates it to 'unmatched' for the worker and staff.
+
+ALTER TABLE unmatched_payments ADD COLUMN match_deadline TIMESTAMPTZ;
+COMMENT ON COLUMN unmatched_payments.status IS 'pending / unmatched / matched';
+
+CREATE INDEX idx_unmatched_payments_pending ON unmatched_payments(match_deadline) WHERE status = 'pending';
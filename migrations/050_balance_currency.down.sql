@@ -0,0 +1,7 @@
+-- 050_balance_currency.down.sql
+
+DROP INDEX IF EXISTS idx_balance_ledger_user;
+CREATE INDEX idx_balance_ledger_user ON balance_ledger(user_id);
+
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS currency;
+ALTER TABLE balance_ledger DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,11 @@
+-- 050_balance_currency.up.sql
+-- Owner balances are kept per escrow currency: a USDT deal credits USDT and is paid out as a
+-- USDT jetton transfer, never as TON at face value. Existing entries are TON.
+
+ALTER TABLE balance_ledger
+    ADD COLUMN currency TEXT NOT NULL DEFAULT 'TON' CHECK (currency IN ('TON', 'USDT'));
+ALTER TABLE withdrawals
+    ADD COLUMN currency TEXT NOT NULL DEFAULT 'TON' CHECK (currency IN ('TON', 'USDT'));
+
+DROP INDEX IF EXISTS idx_balance_ledger_user;
+CREATE INDEX idx_balance_ledger_user ON balance_ledger(user_id, currency);
@@ -0,0 +1,3 @@
+-- 051_webhook_outbox.down.sql
+
+DROP TABLE IF EXISTS webhook_outbox;
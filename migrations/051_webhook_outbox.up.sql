@@ -0,0 +1,17 @@
+-- 051_webhook_outbox.up.sql
+-- Webhook events waiting to be (re)delivered. The worker polls the due rows and deletes a row
+-- once it is delivered or out of attempts, so deliveries survive restarts; every attempt is
+-- still logged in webhook_deliveries.
+
+CREATE TABLE webhook_outbox (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id      UUID NOT NULL REFERENCES deal_webhooks(id) ON DELETE CASCADE,
+    deal_id         UUID NOT NULL REFERENCES deals(id) ON DELETE CASCADE,
+    event           TEXT NOT NULL,
+    payload         TEXT NOT NULL,                 -- exact JSON body, signed on each attempt
+    attempts        INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_webhook_outbox_due ON webhook_outbox(next_attempt_at);
@@ -0,0 +1,4 @@
+-- 052_escrow_funding_lt.down.sql
+
+DROP INDEX IF EXISTS idx_escrow_funding_lt;
+ALTER TABLE escrow_ledger DROP COLUMN IF EXISTS funding_lt;
@@ -0,0 +1,12 @@
+-- 052_escrow_funding_lt.up.sql
+-- LT of the indexed transaction that funded the escrow, so the indexer's reorg check can look
+-- fundings up by LT through an index instead of casting funding_tx_hash on every row.
+-- Existing fundings by the indexer or from a matched payment carry the LT as funding_tx_hash.
+
+ALTER TABLE escrow_ledger ADD COLUMN funding_lt BIGINT;
+
+UPDATE escrow_ledger
+SET funding_lt = CASE WHEN funding_tx_hash ~ '^[0-9]{1,18}$' THEN funding_tx_hash::bigint END
+WHERE funding_tx_hash IS NOT NULL;
+
+CREATE INDEX idx_escrow_funding_lt ON escrow_ledger(funding_lt) WHERE funding_lt IS NOT NULL;
@@ -9,6 +9,7 @@ from typing import Optional
 
 from fastapi import FastAPI, HTTPException
 from pydantic import BaseModel
+from pyrogram.raw import functions as raw_functions
 
 from userbot.client import start_client, stop_client, get_client
 from userbot.stats import collect_channel_stats
@@ -93,6 +94,33 @@ async def get_stats_by_chat_id(chat_id: int):
         raise HTTPException(status_code=500, detail=str(e))
 
 
+@app.get("/internal/stories/by-username/{username}/{story_id}")
+async def get_story(username: str, story_id: int):
+    """Check whether a channel story is still up and return its view count."""
+    client = get_client()
+    if not client or not client.is_connected:
+        raise HTTPException(status_code=503, detail="Userbot not connected")
+
+    # Stories need a newer MTProto layer than some Pyrogram builds ship
+    stories_api = getattr(raw_functions, "stories", None)
+    if stories_api is None or not hasattr(stories_api, "GetStoriesByID"):
+        raise HTTPException(status_code=501, detail="Stories are not supported by this Pyrogram build")
+
+    try:
+        peer = await client.resolve_peer(f"@{username}")
+        result = await client.invoke(stories_api.GetStoriesByID(peer=peer, id=[story_id]))
+    except Exception as e:
+        logger.error(f"Story lookup failed for @{username}/s/{story_id}: {e}")
+        raise HTTPException(status_code=500, detail=str(e))
+
+    for story in getattr(result, "stories", []):
+        # Deleted/expired stories come back as StoryItemDeleted without views
+        if getattr(story, "id", None) == story_id and type(story).__name__ == "StoryItem":
+            views = getattr(getattr(story, "views", None), "views_count", None)
+            return {"exists": True, "views": views}
+    return {"exists": False, "views": None}
+
+
 class JoinChannelRequest(BaseModel):
     username: str
 